@@ -0,0 +1,16 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package v1alpha1
+
+// Hub marks v1alpha1 as the version every other version of the Cluster
+// converts to and from. Following versions implement conversion.Convertible
+// against it; the webhook registered by SetupWebhookWithManager then serves
+// the /convert endpoint and the conversion patch in config/crd enables it.
+func (*Cluster) Hub() {}
@@ -11,11 +11,15 @@ package v1alpha1
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"time"
 
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // ClusterSpec defines the desired state of Cluster
@@ -27,13 +31,156 @@ type ClusterSpec struct {
 	Image string `json:"image,omitempty"`
 	// Version is the Redpanda container tag
 	Version string `json:"version,omitempty"`
+	// AllowDowngrade accepts a Version lower than the highest version the
+	// cluster ran. Redpanda does not support downgrades, the brokers may not
+	// start with the data written by the newer version. Once applied the
+	// lower version is recorded as the highest one.
+	// +optional
+	AllowDowngrade bool `json:"allowDowngrade,omitempty"`
+	// ContainerName is the name of the Redpanda container in the broker
+	// Pods, it defaults to redpanda. It cannot be changed after the Cluster
+	// is created.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	ContainerName string `json:"containerName,omitempty"`
 	// Replicas determine how big the cluster will be.
 	// +kubebuilder:validation:Minimum=0
 	Replicas *int32 `json:"replicas,omitempty"`
+	// ScaleUpStep caps the number of brokers added at once when Replicas
+	// grows. The next step is taken once all brokers are ready. All brokers
+	// are added at once when not set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ScaleUpStep *int32 `json:"scaleUpStep,omitempty"`
+	// MinReadySeconds is the time a restarted broker has to stay ready before
+	// the rolling update continues with the next broker, giving it time to
+	// catch up on replication. Defaults to 10 seconds.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinReadySeconds *int32 `json:"minReadySeconds,omitempty"`
+	// RevisionHistoryLimit is the number of StatefulSet revisions kept for
+	// rollback. Defaults to the Kubernetes default of 10. The operator keeps
+	// a single ConfigMap per cluster, so there are no ConfigMap revisions to
+	// clean up.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+	// ImmutableFieldChanges selects how changes of immutable StatefulSet
+	// fields, e.g. of the storage capacity or class in the volume claim
+	// template, are applied. Defaults to Block.
+	// +optional
+	ImmutableFieldChanges ImmutableFieldChangePolicy `json:"immutableFieldChanges,omitempty"`
 	// Resources used by each Redpanda container
 	// To calculate overall resource consumption one need to
 	// multiply replicas against limits
 	Resources corev1.ResourceRequirements `json:"resources"`
+	// MemoryReservation is the part of the memory limit left to the
+	// operating system and the page cache. Redpanda is started with the rest
+	// of the limit as --memory. When it is not set, Redpanda takes everything
+	// except ReserveMemoryString.
+	// +optional
+	MemoryReservation *MemoryReservation `json:"memoryReservation,omitempty"`
+	// MemoryLocking keeps the memory of Redpanda resident, so page reclaim
+	// under memory pressure does not add to the tail latency
+	// +optional
+	MemoryLocking *MemoryLocking `json:"memoryLocking,omitempty"`
+	// CPUPinning runs each broker on dedicated CPUs with the static CPU
+	// manager policy of the kubelet. It requires an integer CPU limit and
+	// Guaranteed QoS, so the requests of the broker Pods are set to the
+	// limits and Redpanda starts one shard per CPU.
+	// +optional
+	CPUPinning *CPUPinning `json:"cpuPinning,omitempty"`
+	// Reactor selects the reactor backend of Redpanda and the properties of
+	// the disks the IO scheduler is tuned for, e.g. for kernels without
+	// support of the default backend
+	// +optional
+	Reactor *ReactorTuning `json:"reactor,omitempty"`
+	// NodeTuning runs a DaemonSet that applies node level tuning, e.g.
+	// network buffers and the disk scheduler, once on each node selected
+	// for the brokers. It requires the NodeTuning feature gate.
+	// +optional
+	NodeTuning *NodeTuning `json:"nodeTuning,omitempty"`
+	// LivenessProbe enables a liveness probe on the Redpanda container. The
+	// readiness probe asks the Admin API whether the broker is serving and
+	// a member of the cluster, while the liveness probe only checks that the
+	// process accepts connections on the RPC port, so an Admin API outage
+	// across the cluster does not restart every broker at once. Brokers are
+	// only restarted when the process exits when it is not set.
+	// +optional
+	LivenessProbe *LivenessProbe `json:"livenessProbe,omitempty"`
+	// GracefulShutdown drains the leadership of a broker in a preStop hook
+	// before Redpanda receives SIGTERM, so clients move to the other brokers
+	// before the connections are closed. It applies to every termination,
+	// e.g. node drains, not only to restarts driven by the operator. The
+	// hook calls the Admin API with curl, which has to be in the image, and
+	// is not supported with Admin API mutual TLS. A PodDisruptionBudget
+	// limits how many brokers a node drain evicts at a time. Evictions
+	// delete the Pod, which runs the preStop hook, so no eviction webhook
+	// is needed.
+	// +optional
+	GracefulShutdown *GracefulShutdown `json:"gracefulShutdown,omitempty"`
+	// Lifecycle adds custom hooks to the shutdown of the brokers
+	// +optional
+	Lifecycle *BrokerLifecycle `json:"lifecycle,omitempty"`
+	// BrokerFailure configures when a broker that stays down is reported as
+	// failed and whether the operator decommissions it
+	// +optional
+	BrokerFailure *BrokerFailurePolicy `json:"brokerFailure,omitempty"`
+	// DecommissionDrain drains the client connections of brokers that are
+	// decommissioned while their Pods run. It adds a readiness gate to the
+	// brokers, enabling it restarts them once.
+	// +optional
+	DecommissionDrain *DecommissionDrain `json:"decommissionDrain,omitempty"`
+	// ReadinessStabilization holds back the readiness of a broker until its
+	// containers stayed ready for a window, so a broker that a restart or an
+	// online configuration change briefly perturbs does not flap in and out
+	// of the endpoints. It adds a readiness gate to the brokers, enabling it
+	// restarts them once.
+	// +optional
+	ReadinessStabilization *ReadinessStabilization `json:"readinessStabilization,omitempty"`
+	// CrashLoopRecovery recreates the Pods of crash looping brokers, which
+	// renders their configuration again
+	// +optional
+	CrashLoopRecovery *CrashLoopRecovery `json:"crashLoopRecovery,omitempty"`
+	// Backup keeps the data in the cloud storage bucket current to a
+	// recovery point objective and records the recovery points in the
+	// status. It requires cloud storage.
+	// +optional
+	Backup *Backup `json:"backup,omitempty"`
+	// ResourceRecommendation samples the CPU and memory the brokers use and
+	// recommends their resources in the status. The resources of the
+	// brokers are not changed.
+	// +optional
+	ResourceRecommendation *ResourceRecommendation `json:"resourceRecommendation,omitempty"`
+	// RolloutEndpoints keep the brokers clients bootstrap from available
+	// while the operator restarts brokers for an upgrade
+	// +optional
+	RolloutEndpoints *RolloutEndpoints `json:"rolloutEndpoints,omitempty"`
+	// ConsumerGroupCoordination delays the restart of the next broker of an
+	// upgrade while consumer groups rebalance, so the restarts do not
+	// trigger rebalances on top of each other. No coordination when not
+	// set.
+	// +optional
+	ConsumerGroupCoordination *ConsumerGroupCoordination `json:"consumerGroupCoordination,omitempty"`
+	// RestartOrder is the order the operator restarts the brokers in for an
+	// upgrade or a configuration change. FewestLeadershipsFirst requires
+	// GracefulShutdown, which moves the leadership off each broker before it
+	// stops. Defaults to Ordinal.
+	// +kubebuilder:validation:Enum=Ordinal;FewestLeadershipsFirst
+	// +optional
+	RestartOrder RestartOrder `json:"restartOrder,omitempty"`
+	// RolloutRollback reverts the StatefulSet to its last completed revision
+	// when a broker restarted for an upgrade or a configuration change does
+	// not become ready in time. No rollback when not set.
+	// +optional
+	RolloutRollback *RolloutRollback `json:"rolloutRollback,omitempty"`
+	// ImagePrePull pulls the new image on the nodes of the brokers with a
+	// DaemonSet before the first broker is restarted for an upgrade, so no
+	// broker waits for the image while it is down. The DaemonSet is deleted
+	// once every broker runs the new image. No pre-pull when not set.
+	// +optional
+	ImagePrePull *ImagePrePull `json:"imagePrePull,omitempty"`
 	// Configuration represent redpanda specific configuration
 	Configuration RedpandaConfig `json:"configuration,omitempty"`
 	// If specified, Redpanda Pod tolerations
@@ -41,6 +188,144 @@ type ClusterSpec struct {
 	// If specified, Redpanda Pod node selectors. For reference please visit
 	// https://kubernetes.io/docs/concepts/scheduling-eviction/assign-pod-node
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// If specified, Redpanda Pod affinity rules used to co-locate brokers
+	// with other workloads. The anti-affinity that spreads brokers across
+	// nodes is always managed by the operator.
+	PodAffinity *corev1.PodAffinity `json:"podAffinity,omitempty"`
+	// If specified, Redpanda Pod node affinity rules, e.g. to run the
+	// brokers on the larger nodes of a mixed node pool. The StatefulSet
+	// applies one Pod template, so every broker requests the same
+	// Resources and brokers can not be sized per ordinal. Size Resources for
+	// the nodes the brokers are pinned to.
+	// +optional
+	NodeAffinity *corev1.NodeAffinity `json:"nodeAffinity,omitempty"`
+	// FailureDomainSpread selects how strictly the brokers are spread
+	// across the zones, the topology.kubernetes.io/zone label of the nodes.
+	// Brokers always run on nodes of their own. With Soft, the default, the
+	// scheduler prefers balanced zones but places a broker in any zone when
+	// the balanced ones lack capacity. With Strict a broker stays Pending
+	// rather than skew the zones by more than one broker, the reason is
+	// reported in the BrokersUnschedulable condition. Changing it restarts
+	// every broker.
+	// +optional
+	FailureDomainSpread FailureDomainSpreadMode `json:"failureDomainSpread,omitempty"`
+	// DedicatedNodes runs the brokers on a dedicated node pool, whose nodes
+	// are tainted and labeled with the same key and value. The toleration
+	// of the taint and the node selector of the label are added to the
+	// Tolerations and NodeSelector. Changing it restarts every broker.
+	// +optional
+	DedicatedNodes *DedicatedNodes `json:"dedicatedNodes,omitempty"`
+	// ZoneBalancedStartup holds back the readiness of brokers, so the zones
+	// become ready in turns instead of one zone first and the replicas
+	// placed while the cluster comes up spread across the zones. The zone
+	// of a broker is the topology.kubernetes.io/zone label of its node, the
+	// key the operator spreads the brokers by, or its rack when the rack
+	// awareness is enabled. A broker is held back for at most five minutes.
+	// Changing it restarts every broker.
+	// +optional
+	ZoneBalancedStartup bool `json:"zoneBalancedStartup,omitempty"`
+	// TopologyAwareHints asks Kubernetes to route in cluster clients that
+	// bootstrap from the <cluster>-cluster Service to brokers in their own
+	// zone (service.kubernetes.io/topology-aware-hints). It needs the
+	// EndpointSlice API discovery.k8s.io/v1 and the TopologyAwareHints
+	// feature gate, which is on by default since Kubernetes 1.24. The
+	// annotation is left out when the API is not served. Kubernetes skips
+	// the hints while the ready brokers are not spread evenly across the
+	// zones. Only the bootstrap connection is routed: clients then connect
+	// to the advertised addresses of the partition leaders.
+	// +optional
+	TopologyAwareHints bool `json:"topologyAwareHints,omitempty"`
+	// PerBrokerConfig overrides redpanda.yaml properties of single brokers,
+	// keyed by the Pod ordinal, e.g. to enable tiered storage uploads on the
+	// broker with the larger disk. Only node level properties can be
+	// overridden and values are parsed as YAML. Changes restart every broker.
+	// +optional
+	PerBrokerConfig map[string]map[string]string `json:"perBrokerConfig,omitempty"`
+	// AdditionalCommandLineArguments are appended to the arguments the
+	// operator passes to Redpanda, e.g. --unsafe-bypass-fsync. Flags managed
+	// by the operator can not be repeated.
+	AdditionalCommandLineArguments []string `json:"additionalCommandLineArguments,omitempty"`
+	// Env adds environment variables to the Redpanda container. Variables
+	// set by the operator can not be overridden.
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// InitContainers are added to the init containers of the operator,
+	// which render redpanda.yaml and fix the ownership of the data directory
+	// in that order. Each one runs before or after them, keeping the order
+	// of the list. They can mount the volumes of the broker Pod, e.g.
+	// datadir or config-dir.
+	// +optional
+	InitContainers []InitContainer `json:"initContainers,omitempty"`
+	// Durability selects whether brokers fsync writes. Relaxed durability
+	// bypasses fsync and can lose acknowledged writes, so it is meant for
+	// ephemeral development and CI clusters only. Defaults to durable.
+	// +optional
+	Durability DurabilityPolicy `json:"durability,omitempty"`
+	// ResyncPeriod makes the operator reconcile the cluster periodically. A
+	// deterministic jitter of up to 10% derived from the cluster UID spreads
+	// the reconciles of many clusters. Disabled when not set.
+	// +optional
+	ResyncPeriod *metav1.Duration `json:"resyncPeriod,omitempty"`
+	// FeatureGates enable or disable operator features per cluster.
+	// Experimental features are disabled unless they are enabled here.
+	// +optional
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+	// Mode selects how the operator manages the cluster after it is formed.
+	// In bootstrap-once mode the operator stops reconciling the cluster once
+	// it is healthy and resumes only when the spec changes. Defaults to
+	// managed.
+	// +optional
+	Mode ManagementMode `json:"mode,omitempty"`
+	// PostBootstrapJob runs once the cluster is ready, e.g. to create topics
+	// or ACLs. It runs again only when the job spec changes.
+	// +optional
+	PostBootstrapJob *PostBootstrapJob `json:"postBootstrapJob,omitempty"`
+	// TopicWarmup creates topics once every broker is ready and waits for
+	// each of their partitions to elect a leader, so the first produce
+	// request does not wait for the topic to be created. The cluster is
+	// reported ready once the topics are warm.
+	// +optional
+	TopicWarmup *TopicWarmup `json:"topicWarmup,omitempty"`
+	// DebugBundle configures the diagnostics bundles requested with the
+	// DebugBundleAnnotation
+	// +optional
+	DebugBundle *DebugBundle `json:"debugBundle,omitempty"`
+	// Console deploys Redpanda Console configured with the Kafka and Admin
+	// API endpoints and credentials of the cluster
+	// +optional
+	Console *Console `json:"console,omitempty"`
+	// DiscoveryServices are ExternalName Services in other namespaces that
+	// resolve to the headless Service of the cluster, giving applications
+	// there a stable bootstrap name. Owner references cannot cross
+	// namespaces, so the Services are left behind when the Cluster is
+	// deleted.
+	// +optional
+	DiscoveryServices []DiscoveryService `json:"discoveryServices,omitempty"`
+	// ClientConfig publishes the connection settings of the internal Kafka
+	// API listener in a ConfigMap that applications can mount or reference
+	// in environment variables
+	// +optional
+	ClientConfig *ClientConfig `json:"clientConfig,omitempty"`
+	// ServiceCatalog publishes the endpoints of the cluster in a ConfigMap
+	// with a stable schema for service discovery tools
+	// +optional
+	ServiceCatalog *ServiceCatalog `json:"serviceCatalog,omitempty"`
+	// AdoptServices makes the operator take over a headless or bootstrap
+	// Service with the name of a generated one that already exists without
+	// being managed by the cluster, e.g. from a hand written manifest. The
+	// Service gets the controller reference and the labels of the cluster
+	// and is managed like a generated one afterwards. Services of another
+	// controller or of another type are not adopted. Unmanaged Services are
+	// left untouched when it is not set.
+	// +optional
+	AdoptServices bool `json:"adoptServices,omitempty"`
+	// NetworkPolicy restricts the traffic that reaches the brokers
+	// +optional
+	NetworkPolicy *NetworkPolicy `json:"networkPolicy,omitempty"`
+	// ManagementNodePort exposes the Admin API on a fixed port of every node
+	// for rpk and operations access from outside the Kubernetes cluster,
+	// independent of the external connectivity of the clients
+	// +optional
+	ManagementNodePort *ManagementNodePort `json:"managementNodePort,omitempty"`
 
 	// ExternalConnectivity enables user to expose Redpanda
 	// nodes outside of a Kubernetes cluster. For more
@@ -48,12 +333,796 @@ type ClusterSpec struct {
 	ExternalConnectivity ExternalConnectivityConfig `json:"externalConnectivity,omitempty"`
 	// Storage spec for cluster
 	Storage StorageSpec `json:"storage,omitempty"`
+	// DNS configures the DNS records of the brokers
+	// +optional
+	DNS *DNSConfig `json:"dns,omitempty"`
+	// Autoscaling makes the brokers work with the cluster autoscaler and the
+	// vertical pod autoscaler. A HorizontalPodAutoscaler has to target the
+	// Cluster through its scale subresource rather than the StatefulSet.
+	// +optional
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty"`
+	// PodMonitor creates a Prometheus operator PodMonitor that scrapes the
+	// metrics of every broker from its Admin API, for Prometheus setups that
+	// scrape Pods rather than Services. It is deleted when unset.
+	// +optional
+	PodMonitor *PodMonitor `json:"podMonitor,omitempty"`
+	// Replication runs MirrorMaker 2, replicating the topics of another
+	// Cluster of the operator into this one for an active-passive setup.
+	// The replicated topics are prefixed with the name of the source
+	// Cluster. It is deleted when unset.
+	// +optional
+	Replication *Replication `json:"replication,omitempty"`
 	// Cloud storage configuration for cluster
 	CloudStorage CloudStorageConfig `json:"cloudStorage,omitempty"`
 	// List of superusers
 	Superusers []Superuser `json:"superUsers,omitempty"`
+	// SuperusersFrom references the key of a ConfigMap in the namespace of
+	// the cluster listing further superusers, one user name per line, so
+	// long lists do not grow the Cluster object. They are added to
+	// Superusers.
+	// +optional
+	SuperusersFrom *corev1.ConfigMapKeySelector `json:"superUsersFrom,omitempty"`
+	// BootstrapUser is created by Redpanda when the cluster forms, before
+	// it serves the Admin API, and added to the superusers. With Admin API
+	// authentication the operator authenticates with it from the start,
+	// instead of creating the bootstrap superuser through an API that is
+	// open until then. Redpanda ignores it once the cluster formed, so it
+	// can not be changed afterwards.
+	// +optional
+	BootstrapUser *BootstrapUser `json:"bootstrapUser,omitempty"`
 	// SASL enablement flag
 	EnableSASL bool `json:"enableSasl,omitempty"`
+	// InternalTopicReplication raises the replication factor of internal
+	// topics after the cluster is scaled up
+	InternalTopicReplication InternalTopicReplication `json:"internalTopicReplication,omitempty"`
+}
+
+// MemoryReservation defines the memory that Redpanda leaves unused, either as
+// an absolute amount or as a percentage of the memory limit
+type MemoryReservation struct {
+	// Reserved is the amount of memory left unused
+	// +optional
+	Reserved *resource.Quantity `json:"reserved,omitempty"`
+	// Percent of the memory limit left unused
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=90
+	// +optional
+	Percent int `json:"percent,omitempty"`
+}
+
+// MemoryLocking defines how Redpanda holds its memory
+type MemoryLocking struct {
+	// LockMemory starts Redpanda with --lock-memory, so its memory can not be
+	// swapped out or reclaimed. The Redpanda container gets the IPC_LOCK
+	// capability.
+	// +optional
+	LockMemory bool `json:"lockMemory,omitempty"`
+	// HugePages backs the memory of Redpanda with huge pages
+	// +optional
+	HugePages *HugePages `json:"hugePages,omitempty"`
+}
+
+// HugePages defines the huge pages requested by every broker. The nodes have
+// to pre-allocate huge pages of the page size, the kubelet reports them in the
+// allocatable hugepages-<pageSize> resource.
+type HugePages struct {
+	// PageSize of the huge pages
+	// +kubebuilder:validation:Enum=2Mi;1Gi
+	PageSize string `json:"pageSize"`
+	// Size of the huge pages requested per broker. It has to be a multiple of
+	// the page size and cover the memory of Redpanda.
+	Size resource.Quantity `json:"size"`
+}
+
+// ResourceName returns the name of the huge pages resource of the page size
+func (h *HugePages) ResourceName() corev1.ResourceName {
+	return corev1.ResourceName(corev1.ResourceHugePagesPrefix + h.PageSize)
+}
+
+// CPUPinning defines how brokers are pinned to CPUs
+type CPUPinning struct {
+	// Enabled pins the brokers to as many CPUs as the CPU limit
+	Enabled bool `json:"enabled,omitempty"`
+	// CPUSet is the list of CPUs passed to Redpanda as --cpuset, e.g.
+	// 0-3,8. It must name as many CPUs as the CPU limit. When it is not set
+	// Redpanda runs on the CPUs the kubelet assigned to the container.
+	// +optional
+	CPUSet string `json:"cpuset,omitempty"`
+}
+
+// ReactorBackend is the backend of the Seastar reactor
+type ReactorBackend string
+
+const (
+	// ReactorBackendLinuxAIO polls the Linux AIO interface, the default of
+	// Redpanda
+	ReactorBackendLinuxAIO ReactorBackend = "linux-aio"
+	// ReactorBackendEpoll polls with epoll, for kernels without the AIO
+	// features the default backend needs
+	ReactorBackendEpoll ReactorBackend = "epoll"
+)
+
+// NodeTuning configures the DaemonSet tuning the nodes of the brokers. The
+// tuning is applied when its Pod starts on a node, i.e. once per node and
+// again after a reboot or a change of the tuning. Removed settings are not
+// reverted on the nodes.
+type NodeTuning struct {
+	// Privileged acknowledges that the tuning runs in a privileged container
+	// on the host network, which it needs to change the kernel parameters
+	// and block devices of the node. It has to be set.
+	Privileged bool `json:"privileged"`
+	// NodeSelector selects the nodes that are tuned. Defaults to the node
+	// selector of the brokers, one of them is required.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Sysctls are the kernel parameters set on the nodes, e.g.
+	// net.core.rmem_max
+	// +optional
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+	// DiskScheduler is the I/O scheduler set on the Devices
+	// +kubebuilder:validation:Enum=none;mq-deadline;kyber;bfq
+	// +optional
+	DiskScheduler string `json:"diskScheduler,omitempty"`
+	// Devices are the block devices of the nodes the disk scheduler is set
+	// on, e.g. nvme0n1
+	// +optional
+	Devices []string `json:"devices,omitempty"`
+	// Resources of the tuning containers
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// ReactorTuning defines the reactor and IO scheduler options of Redpanda
+type ReactorTuning struct {
+	// Backend is passed to Redpanda as --reactor-backend. Redpanda chooses
+	// the backend when it is not set.
+	// +kubebuilder:validation:Enum=linux-aio;epoll
+	// +optional
+	Backend ReactorBackend `json:"backend,omitempty"`
+	// IOProperties are the disk properties the IO scheduler is tuned for,
+	// in the format of the io-config.yaml written by rpk iotune. They are
+	// stored in the ConfigMap of the cluster, changes restart the brokers.
+	// +optional
+	IOProperties string `json:"ioProperties,omitempty"`
+	// IOPropertiesFrom references the key of a ConfigMap holding the disk
+	// properties, e.g. the results of an iotune run on the nodes of the
+	// cluster. Brokers pick up changes when they restart.
+	// +optional
+	IOPropertiesFrom *corev1.ConfigMapKeySelector `json:"ioPropertiesFrom,omitempty"`
+}
+
+// IOPropertiesSet is true when the disk properties are provided
+func (r *ReactorTuning) IOPropertiesSet() bool {
+	return r != nil && (r.IOProperties != "" || r.IOPropertiesFrom != nil)
+}
+
+// BrokerFailurePolicy defines when a broker that is down is a candidate for
+// replacement or decommission
+type BrokerFailurePolicy struct {
+	// GracePeriod is how long a broker may stay down before it is reported
+	// as failed, so restarts of its Pod are not. Defaults to 10m.
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+	// Decommission makes the operator decommission a failed broker, which
+	// moves its partitions to the other brokers. Only one broker is
+	// decommissioned at a time and none while several brokers failed.
+	// +optional
+	Decommission bool `json:"decommission,omitempty"`
+}
+
+// ImmutableFieldChangePolicy selects how changes of immutable StatefulSet
+// fields are applied
+// +kubebuilder:validation:Enum=Block;Recreate
+type ImmutableFieldChangePolicy string
+
+const (
+	// ImmutableFieldChangeBlock leaves the StatefulSet as it is and reports
+	// the changed fields in the StatefulSetUpdateBlocked condition
+	ImmutableFieldChangeBlock ImmutableFieldChangePolicy = "Block"
+	// ImmutableFieldChangeRecreate deletes the StatefulSet with orphan
+	// propagation and creates it again with the new spec. The Pods and
+	// their volumes are kept and adopted by the new StatefulSet, existing
+	// volumes keep the claim of the old template.
+	ImmutableFieldChangeRecreate ImmutableFieldChangePolicy = "Recreate"
+)
+
+// DecommissionDrain defines how a broker that is decommissioned is drained.
+// The readiness gate of its Pod is turned off when the decommissioning
+// starts, so the Pod leaves the Service endpoints and clients bootstrap from
+// the other brokers. The Pod is removed by a scale down only once Redpanda
+// removed the broker and the timeout passed. The Admin API of this Redpanda
+// version does not list the brokers, so no broker is seen decommissioned and
+// the readiness gate stays on.
+type DecommissionDrain struct {
+	// Timeout is the shortest time between the start of the decommissioning
+	// and the removal of the Pod, which gives clients time to reconnect to
+	// the other brokers. Defaults to 1m.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// ReadinessStabilization defines how long a broker has to stay healthy
+// before it is ready. The operator sets the readiness gate of a broker once
+// its containers have been ready for the window and clears it when they
+// became ready again within the window, which a restart or a failing
+// readiness probe causes.
+type ReadinessStabilization struct {
+	// Window the containers of a broker have to stay ready for
+	Window metav1.Duration `json:"window"`
+}
+
+// CrashLoopRecovery defines when the Pod of a crash looping broker is
+// recreated. The configuration of a broker lives in an emptyDir volume that
+// the configurator renders when the Pod starts, recreating the Pod resets it
+// while the data volume is kept. Only one Pod is recreated per interval, so
+// a crash loop for another reason is reported instead of masked.
+type CrashLoopRecovery struct {
+	// RestartThreshold is the number of restarts of the Redpanda container
+	// after which a broker in CrashLoopBackOff is recovered. Defaults to 5.
+	// +kubebuilder:validation:Minimum=2
+	// +optional
+	RestartThreshold *int32 `json:"restartThreshold,omitempty"`
+	// MinInterval is the shortest time between two recoveries. Defaults to
+	// 30m.
+	// +optional
+	MinInterval *metav1.Duration `json:"minInterval,omitempty"`
+}
+
+// Backup configures operator coordinated backups to the cloud storage
+// bucket. Redpanda uploads segments to the bucket once they are closed, at
+// the reconciliation interval of the archiver
+// (cloud_storage_reconciliation_interval_ms), the open segment of a
+// partition stays local only. The operator confirms a recovery point every
+// interval while the archiver runs at least as often and every broker is up
+// with all partitions having a leader, the operator does not list the
+// bucket.
+type Backup struct {
+	// Interval is the recovery point objective, a recovery point is
+	// confirmed once per interval. Defaults to 1h.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// ResourceRecommendation configures the recommendation of the resources of
+// the brokers. The operator reads the reactor utilization and the allocated
+// memory of every broker from its metrics every 5 minutes and recommends the
+// peak of a window plus the headroom. The CPU is rounded up to whole cores,
+// Redpanda runs one shard per core. Redpanda grows its batch cache into free
+// memory, the memory recommendation is at most the memory of the brokers.
+type ResourceRecommendation struct {
+	// Window is how long the brokers are sampled for a recommendation.
+	// Defaults to 24h.
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+	// HeadroomPercent is added to the peak usage of the window. Defaults to
+	// 30.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	HeadroomPercent *int32 `json:"headroomPercent,omitempty"`
+}
+
+// TopicWarmup configures the Job creating topics once every broker of the
+// cluster is ready. The Job runs rpk of the Redpanda image of the cluster and
+// creates the topics that do not exist yet, existing topics are kept as they
+// are. The operator then waits for every partition of the topics to have a
+// leader. No records are written to the topics. The Job connects to the
+// Kafka API without TLS and credentials.
+type TopicWarmup struct {
+	// Topics created and warmed
+	// +kubebuilder:validation:MinItems=1
+	Topics []WarmupTopic `json:"topics"`
+	// ServiceAccountName of the Job Pod
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// WarmupTopic is a topic created by the topic warmup
+type WarmupTopic struct {
+	// Name of the topic
+	Name string `json:"name"`
+	// Partitions of the topic
+	// +kubebuilder:validation:Minimum=1
+	Partitions int32 `json:"partitions"`
+	// ReplicationFactor of the topic, it has to be odd and at most the
+	// number of brokers. Defaults to the default_topic_replications cluster
+	// property.
+	// +optional
+	ReplicationFactor *int32 `json:"replicationFactor,omitempty"`
+}
+
+// BrokerLifecycle defines custom actions around the lifecycle of a broker
+type BrokerLifecycle struct {
+	// PreStop runs a command in the Redpanda container before Redpanda
+	// receives SIGTERM, e.g. to trigger a snapshot or notify an external
+	// system. With GracefulShutdown it runs before or after the drain of the
+	// leadership, otherwise it is the only preStop hook.
+	// +optional
+	PreStop *PreStopHook `json:"preStop,omitempty"`
+	// Shutdown sizes the time Redpanda has to stop after SIGTERM, before the
+	// kubelet sends SIGKILL. It is added to the drain and preStop hook
+	// timeouts in the termination grace period of the Pods, 30 seconds are
+	// left when it is not set.
+	// +optional
+	Shutdown *BrokerShutdown `json:"shutdown,omitempty"`
+}
+
+// BrokerShutdown defines how long Redpanda may take to stop. Redpanda stops
+// on SIGTERM, the stop signal of its image, and flushes its partitions before
+// it exits, which takes longer the more data a broker holds. The kubelet
+// counts the termination grace period from the start of the preStop hook, so
+// a slow drain or hook shortens the shutdown unless they are accounted for.
+type BrokerShutdown struct {
+	// TimeoutSeconds is the time Redpanda has to stop regardless of its data.
+	// Defaults to 30 seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// SecondsPerGiB adds time for each GiB of the data volume capacity
+	// (Storage.Capacity). The capacity of an emptyDir without a size limit
+	// adds nothing.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SecondsPerGiB *int32 `json:"secondsPerGiB,omitempty"`
+	// MaxTimeoutSeconds caps the time scaled with the capacity. Defaults to
+	// 600 seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxTimeoutSeconds *int32 `json:"maxTimeoutSeconds,omitempty"`
+}
+
+// PreStopHookOrder places the custom preStop hook relative to the drain
+// +kubebuilder:validation:Enum=BeforeDrain;AfterDrain
+type PreStopHookOrder string
+
+const (
+	// PreStopHookBeforeDrain runs the hook while the broker still leads its
+	// partitions
+	PreStopHookBeforeDrain PreStopHookOrder = "BeforeDrain"
+	// PreStopHookAfterDrain runs the hook after the leadership moved away
+	// or the drain timed out
+	PreStopHookAfterDrain PreStopHookOrder = "AfterDrain"
+)
+
+// PreStopHook is a custom command run before a broker is stopped. The
+// command is stopped after its timeout and a failure is logged, neither
+// skips the drain nor keeps Redpanda from being stopped. The termination
+// grace period of the Pod is extended by the timeout.
+type PreStopHook struct {
+	// Command is executed in the Redpanda container, it is not run in a
+	// shell. It is bounded with timeout, which has to be in the image.
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+	// Order of the hook and the drain of GracefulShutdown. Defaults to
+	// AfterDrain.
+	// +optional
+	Order PreStopHookOrder `json:"order,omitempty"`
+	// TimeoutSeconds is the longest time the command runs. Defaults to 30
+	// seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=600
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// GracefulShutdown defines how long a terminating broker drains
+type GracefulShutdown struct {
+	// DrainTimeoutSeconds is the longest time the preStop hook waits for
+	// the leadership to move away before Redpanda is stopped. The Pod
+	// termination grace period is extended accordingly. Defaults to 60
+	// seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DrainTimeoutSeconds *int32 `json:"drainTimeoutSeconds,omitempty"`
+	// MaxUnavailable is the number of brokers that may be evicted at the
+	// same time. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
+}
+
+// RestartOrder is the order the brokers are restarted in one at a time
+type RestartOrder string
+
+const (
+	// RestartOrderOrdinal restarts the brokers from the highest Pod ordinal
+	// down
+	RestartOrderOrdinal RestartOrder = "Ordinal"
+	// RestartOrderFewestLeadershipsFirst restarts the brokers leading the
+	// fewest partitions first, so the leaderships of the busiest brokers move
+	// once most of the cluster already runs the update. The order is taken
+	// from the Admin API when the restart starts and kept until it ends. The
+	// StatefulSet uses the OnDelete update strategy and the operator deletes
+	// the Pods in that order.
+	RestartOrderFewestLeadershipsFirst RestartOrder = "FewestLeadershipsFirst"
+)
+
+// ConsumerGroupCoordination configures how restarts wait for the consumer
+// groups. The groups are described through the Kafka API, the restarts are
+// not delayed when the operator can not reach it.
+type ConsumerGroupCoordination struct {
+	// StabilizationSeconds is how long the consumer groups get to rebalance
+	// after the restarted broker became ready, before the groups are
+	// checked and the next broker restarts. Defaults to 30 seconds.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StabilizationSeconds *int32 `json:"stabilizationSeconds,omitempty"`
+	// MaxRebalancingGroups is the number of rebalancing consumer groups up
+	// to which the next broker restarts. Defaults to 0, no group may be
+	// rebalancing.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRebalancingGroups *int32 `json:"maxRebalancingGroups,omitempty"`
+}
+
+// RolloutRollback configures the rollback of a failed rolling update
+type RolloutRollback struct {
+	// ProgressDeadlineSeconds is how long a broker restarted on the new
+	// revision has to become ready. The StatefulSet is then reverted to the
+	// revision every broker ran before the update and the broker is
+	// restarted on it. The reverted StatefulSet is kept until the spec of
+	// the cluster changes the Pod template again. Defaults to 600 seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// ImagePrePull configures the pre-pull of the image of an upgrade
+type ImagePrePull struct {
+	// TimeoutSeconds is how long the upgrade waits for the nodes to pull
+	// the image. The brokers are then restarted regardless, those on nodes
+	// that did not finish pull the image on their own. Defaults to 600
+	// seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// RolloutEndpoints configures how the endpoints of the headless Service behave
+// during rolling upgrades
+type RolloutEndpoints struct {
+	// WaitForReadyBrokers restarts the next broker only while fewer brokers
+	// than the MaxUnavailable of GracefulShutdown (1 by default) are not
+	// ready, so an upgrade does not add to a node drain or a crash that
+	// already took brokers away. The upgrade then pauses until those
+	// brokers are back, also when the upgrade is meant to fix them.
+	// +optional
+	WaitForReadyBrokers bool `json:"waitForReadyBrokers,omitempty"`
+	// PublishNotReadyAddresses publishes the records of every broker in the
+	// headless Service while an upgrade is in progress, so the addresses
+	// clients bootstrap from do not disappear and reappear with each
+	// restart. A client that picks the restarting broker fails to connect
+	// and has to retry with another address, so it helps clients that
+	// bootstrap from several addresses and hurts clients that use one.
+	// +optional
+	PublishNotReadyAddresses bool `json:"publishNotReadyAddresses,omitempty"`
+}
+
+// LivenessProbe defines the thresholds of the broker liveness probe
+type LivenessProbe struct {
+	// InitialDelaySeconds before the first check, giving the broker time
+	// to replay its log after a restart. Defaults to 30 seconds.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	// PeriodSeconds between two checks. Defaults to 10 seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+	// FailureThreshold is the number of consecutive failed checks after
+	// which the container is restarted. Defaults to 6.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// DurabilityPolicy defines how brokers persist writes
+// +kubebuilder:validation:Enum=durable;relaxed
+type DurabilityPolicy string
+
+const (
+	// DurabilityDurable fsyncs writes before acknowledging them
+	DurabilityDurable DurabilityPolicy = "durable"
+	// DurabilityRelaxed bypasses fsync, trading durability for speed
+	DurabilityRelaxed DurabilityPolicy = "relaxed"
+)
+
+// IsRelaxed returns true when the cluster bypasses fsync
+func (d DurabilityPolicy) IsRelaxed() bool {
+	return d == DurabilityRelaxed
+}
+
+// InitContainerPosition places an init container relative to the init
+// containers of the operator
+// +kubebuilder:validation:Enum=BeforeOperator;AfterOperator
+type InitContainerPosition string
+
+const (
+	// InitContainerBeforeOperator runs the container before redpanda.yaml is
+	// rendered, e.g. to tune the node
+	InitContainerBeforeOperator InitContainerPosition = "BeforeOperator"
+	// InitContainerAfterOperator runs the container right before Redpanda
+	// starts, e.g. to adjust the rendered configuration of the broker
+	InitContainerAfterOperator InitContainerPosition = "AfterOperator"
+)
+
+// InitContainer is an init container of the broker Pods added by the user
+type InitContainer struct {
+	// Name of the container. It must differ from the containers of the
+	// operator and the other init containers.
+	Name string `json:"name"`
+	// Image of the container
+	Image string `json:"image"`
+	// Command overrides the entrypoint of the image
+	// +optional
+	Command []string `json:"command,omitempty"`
+	// Args passed to the command
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// Env adds environment variables to the container
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// Resources of the container
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// VolumeMounts mount the volumes of the broker Pod
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// Position of the container, defaults to AfterOperator
+	// +optional
+	Position InitContainerPosition `json:"position,omitempty"`
+}
+
+// PostBootstrapJob describes the container of a one-shot Job run against the
+// cluster. The operator injects the connection details as the
+// REDPANDA_BROKERS and REDPANDA_ADMIN_API environment variables.
+type PostBootstrapJob struct {
+	// Image of the job container
+	Image string `json:"image"`
+	// Command overrides the entrypoint of the image
+	// +optional
+	Command []string `json:"command,omitempty"`
+	// Args passed to the command
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// Env adds environment variables to the job container
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// Resources of the job container
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// ServiceAccountName of the job Pod
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// BackoffLimit is the number of retries before the job fails
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+}
+
+// DebugBundle configures the Job collecting an rpk debug bundle. The Job runs
+// rpk of the Redpanda image of the cluster, which has to provide the debug
+// bundle command, and uploads the bundle to a pre-signed URL of an object
+// store, e.g. of an S3 or GCS bucket of the support case.
+type DebugBundle struct {
+	// UploadURLSecretRef selects the key of a Secret in the namespace of the
+	// cluster holding the URL the bundle is uploaded to with an HTTP PUT
+	UploadURLSecretRef corev1.SecretKeySelector `json:"uploadURLSecretRef"`
+	// ServiceAccountName of the Job Pod. rpk collects the Kubernetes
+	// resources and logs of the namespace that it is allowed to read.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// MinInterval is the shortest time between the start of two bundles,
+	// later requests wait for it. Defaults to 1h.
+	// +optional
+	MinInterval *metav1.Duration `json:"minInterval,omitempty"`
+}
+
+// ClientConfig defines the ConfigMap with the client connection settings.
+// The ConfigMap has the keys brokers, tls, ca.crt when TLS is enabled and
+// sasl_mechanism when SASL is enabled. Credentials are not part of it.
+type ClientConfig struct {
+	// Name of the ConfigMap, defaults to <cluster>-client
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// FailureDomainSpreadMode defines how strictly brokers are spread across
+// the zones
+// +kubebuilder:validation:Enum=Soft;Strict
+type FailureDomainSpreadMode string
+
+const (
+	// FailureDomainSpreadSoft prefers balanced zones
+	FailureDomainSpreadSoft FailureDomainSpreadMode = "Soft"
+	// FailureDomainSpreadStrict does not schedule brokers that would skew
+	// the zones
+	FailureDomainSpreadStrict FailureDomainSpreadMode = "Strict"
+)
+
+// StrictFailureDomainSpread returns true when brokers are not scheduled
+// when they would skew the zones
+func (r *Cluster) StrictFailureDomainSpread() bool {
+	return r.Spec.FailureDomainSpread == FailureDomainSpreadStrict
+}
+
+// DedicatedNodes defines the taint and the label of the dedicated nodes
+type DedicatedNodes struct {
+	// Key of the taint and of the label
+	Key string `json:"key"`
+	// Value of the taint and of the label
+	Value string `json:"value"`
+	// Effect of the taint, every effect is tolerated when not set
+	// +kubebuilder:validation:Enum=NoSchedule;PreferNoSchedule;NoExecute
+	// +optional
+	Effect corev1.TaintEffect `json:"effect,omitempty"`
+}
+
+// BrokerTolerations returns the tolerations of the brokers, including the
+// toleration of the dedicated nodes taint
+func (r *Cluster) BrokerTolerations() []corev1.Toleration {
+	dedicated := r.Spec.DedicatedNodes
+	if dedicated == nil {
+		return r.Spec.Tolerations
+	}
+	tolerations := make([]corev1.Toleration, 0, len(r.Spec.Tolerations)+1)
+	tolerations = append(tolerations, r.Spec.Tolerations...)
+	return append(tolerations, corev1.Toleration{
+		Key:      dedicated.Key,
+		Operator: corev1.TolerationOpEqual,
+		Value:    dedicated.Value,
+		Effect:   dedicated.Effect,
+	})
+}
+
+// BrokerNodeSelector returns the node selector of the brokers, including
+// the label of the dedicated nodes
+func (r *Cluster) BrokerNodeSelector() map[string]string {
+	dedicated := r.Spec.DedicatedNodes
+	if dedicated == nil {
+		return r.Spec.NodeSelector
+	}
+	selector := make(map[string]string, len(r.Spec.NodeSelector)+1)
+	for k, v := range r.Spec.NodeSelector {
+		selector[k] = v
+	}
+	selector[dedicated.Key] = dedicated.Value
+	return selector
+}
+
+// ServiceCatalog defines the ConfigMap that documents the endpoints of the
+// cluster for service discovery tools. It is updated whenever the endpoints
+// change. All values are strings, keys are only added in later schema
+// versions:
+//
+// - schema_version: version of the schema, 1
+// - cluster: namespace/name of the Cluster
+// - version: Redpanda version of the brokers
+// - kafka_bootstrap: bootstrap servers of the internal Kafka API listener
+// - kafka_tls, kafka_sasl: true or false for the internal listener
+// - kafka_external_bootstrap, kafka_external_tls, kafka_external_sasl: the
+// same for the external listener, once the brokers advertise it
+// - admin_url: URL of the Admin API through the headless Service
+// - admin_tls: true or false, and admin_ca_secret, the Secret with the
+// ca.crt of the Admin API, when TLS is enabled
+// - kafka_ca_secret: the Secret with the ca.crt of the Kafka API, when TLS
+// is enabled on a Kafka listener, and kafka_external_ca_secret when the
+// external listener has a certificate of its own
+// - pandaproxy_url: URL of the internal Pandaproxy listener, when enabled
+//
+// The operator does not configure the Schema Registry, so no URL of it is
+// published. Credentials are not part of it.
+type ServiceCatalog struct {
+	// Name of the ConfigMap, defaults to <cluster>-service-catalog
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Labels added to the ConfigMap, for the catalog to select the ones it
+	// indexes
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// ManagementNodePort defines the NodePort Service of the Admin API. The
+// Service keeps the client address, so a node only forwards to the broker
+// running on it and has to be addressed by a host of a broker.
+type ManagementNodePort struct {
+	// NodePort is the port of the Admin API on every node. It has to be in
+	// the node port range of Kubernetes, 30000-32767 by default, and unused
+	// by other Services.
+	// +kubebuilder:validation:Minimum=30000
+	// +kubebuilder:validation:Maximum=32767
+	NodePort int32 `json:"nodePort"`
+	// SourceRanges are the CIDRs allowed to reach the port. They are
+	// enforced by the NetworkPolicy of the brokers, which is required to
+	// set them. Any source is allowed when they are empty.
+	// +optional
+	SourceRanges []string `json:"sourceRanges,omitempty"`
+}
+
+// NetworkPolicy defines the NetworkPolicy of the brokers. The brokers accept
+// any traffic from each other, the Kafka API from the Redpanda Console of the
+// cluster and from the allowed client namespaces, the Admin API from the
+// Console and the namespace of the operator, the external Kafka API from
+// anywhere when external connectivity is enabled, and the Admin API from the
+// source ranges of the management node port. Everything else is denied,
+// including the external Admin API.
+type NetworkPolicy struct {
+	// Enabled creates the NetworkPolicy. It is deleted again when disabled.
+	Enabled bool `json:"enabled,omitempty"`
+	// AllowedClientNamespaces are the names of the namespaces whose Pods
+	// may connect to the Kafka API. Namespaces are matched by their
+	// kubernetes.io/metadata.name label, which requires Kubernetes 1.21.
+	// +optional
+	AllowedClientNamespaces []string `json:"allowedClientNamespaces,omitempty"`
+	// AllowedClientSelectors select further namespaces whose Pods may
+	// connect to the Kafka API by their labels
+	// +optional
+	AllowedClientSelectors []metav1.LabelSelector `json:"allowedClientSelectors,omitempty"`
+}
+
+// DiscoveryService defines an ExternalName Service pointing at the cluster
+type DiscoveryService struct {
+	// Namespace of the Service, it has to exist
+	Namespace string `json:"namespace"`
+	// Name of the Service, defaults to the name of the Cluster
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// Console defines the Redpanda Console deployment of the cluster
+type Console struct {
+	// Image is the fully qualified name of the Console container
+	Image string `json:"image"`
+	// Replicas of the Console Deployment. Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Resources of the Console container
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Ingress exposes Console outside of the Kubernetes cluster
+	// +optional
+	Ingress *ConsoleIngress `json:"ingress,omitempty"`
+}
+
+// ConsoleIngress defines the Ingress routing to the Console Service
+type ConsoleIngress struct {
+	// Host name served by the Ingress
+	Host string `json:"host"`
+	// IngressClassName selects the Ingress controller
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// TLSSecretName is the Secret with the certificate of the host. TLS is
+	// not terminated by the Ingress when it is not set.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+}
+
+// ManagementMode defines whether the operator keeps reconciling the cluster
+// +kubebuilder:validation:Enum=managed;bootstrap-once
+type ManagementMode string
+
+const (
+	// ManagementModeManaged reconciles the cluster continuously
+	ManagementModeManaged ManagementMode = "managed"
+	// ManagementModeBootstrapOnce stops reconciling once the cluster is
+	// healthy until the spec changes
+	ManagementModeBootstrapOnce ManagementMode = "bootstrap-once"
+)
+
+// InternalTopicReplication configures how the operator keeps the replication
+// factor of internal topics (e.g. consumer offsets or schemas) in line with
+// the number of brokers.
+type InternalTopicReplication struct {
+	// Enabled turns the reconciliation on. It is disabled by default because
+	// adding replicas moves data between brokers.
+	Enabled bool `json:"enabled,omitempty"`
+	// ReplicationFactor is the target replication factor (default 3). It is
+	// never raised above the number of brokers.
+	// +kubebuilder:validation:Minimum=1
+	ReplicationFactor int `json:"replicationFactor,omitempty"`
+	// Topics lists the managed internal topics in the NAMESPACE/TOPIC form.
+	// Defaults to kafka_internal/group and kafka/_schemas.
+	Topics []string `json:"topics,omitempty"`
 }
 
 // Superuser has full access to the Redpanda cluster
@@ -61,6 +1130,26 @@ type Superuser struct {
 	Username string `json:"username"`
 }
 
+// BootstrapUser defines the first user of the cluster
+type BootstrapUser struct {
+	// SecretRef is a Secret in the namespace of the cluster with the
+	// username and password keys of the user
+	SecretRef corev1.LocalObjectReference `json:"secretRef"`
+	// Mechanism is the SCRAM mechanism of the user, defaults to
+	// SCRAM-SHA-256
+	// +kubebuilder:validation:Enum=SCRAM-SHA-256;SCRAM-SHA-512
+	// +optional
+	Mechanism string `json:"mechanism,omitempty"`
+}
+
+// BootstrapUserMechanism returns the SCRAM mechanism of the bootstrap user
+func (r *Cluster) BootstrapUserMechanism() string {
+	if user := r.Spec.BootstrapUser; user != nil && user.Mechanism != "" {
+		return user.Mechanism
+	}
+	return "SCRAM-SHA-256"
+}
+
 // CloudStorageConfig configures the Data Archiving feature in Redpanda
 // https://vectorized.io/docs/data-archiving
 type CloudStorageConfig struct {
@@ -91,12 +1180,233 @@ type CloudStorageConfig struct {
 	APIEndpointPort int `json:"apiEndpointPort,omitempty"`
 }
 
-// StorageSpec defines the storage specification of the Cluster
+// StorageSpec defines the storage specification of the Cluster. Redpanda
+// keeps its data in a single data directory, so each broker gets one data
+// volume. To use several disks of a node, aggregate them below Kubernetes,
+// e.g. as an LVM or RAID0 volume exposed by a local volume provisioner, and
+// select that storage class.
 type StorageSpec struct {
+	// Type of the data directory volume. An emptyDir is not durable: the
+	// data of a broker is lost whenever its Pod is deleted. It is meant for
+	// disposable CI clusters and is rejected unless the operator runs with
+	// --allow-emptydir-storage. The type can not be changed.
+	// +kubebuilder:validation:Enum=persistentVolumeClaim;emptyDir
+	// +optional
+	Type StorageType `json:"type,omitempty"`
+	// EmptyDir configures the data directory when Type is emptyDir
+	// +optional
+	EmptyDir *EmptyDirStorage `json:"emptyDir,omitempty"`
 	// Storage capacity requested
 	Capacity resource.Quantity `json:"capacity,omitempty"`
 	// Storage class name - https://kubernetes.io/docs/concepts/storage/storage-classes/
 	StorageClassName string `json:"storageClassName,omitempty"`
+	// PVCRetentionPolicy decides whether the PersistentVolumeClaims of the
+	// brokers are deleted with the cluster and when it scales down. The
+	// claims are retained when it is not set.
+	PVCRetentionPolicy *PVCRetentionPolicy `json:"pvcRetentionPolicy,omitempty"`
+	// FixDataDirOwnership runs an init container as root that hands the data
+	// directory to the Redpanda user before the broker starts. It is meant for
+	// CSI drivers that ignore the fsGroup of the Pod.
+	FixDataDirOwnership bool `json:"fixDataDirOwnership,omitempty"`
+	// Hints are the filesystem, mount options and I/O scheduler the data
+	// directory should have. The operator does not manage StorageClasses,
+	// the mount options have to be listed in the mountOptions of the
+	// StorageClass, which the provisioner copies to the volumes, and the
+	// scheduler is set by the node tuning. The operator checks the
+	// StorageClass and the volumes, and each broker checks its mounted data
+	// directory before it starts, deviations are reported by the
+	// StorageSuboptimal condition. Brokers start regardless, changing the
+	// hints restarts them.
+	// +optional
+	Hints *StorageHints `json:"hints,omitempty"`
+}
+
+// IsEmptyDir returns true when the data directory is an emptyDir
+func (s StorageSpec) IsEmptyDir() bool {
+	return s.Type == StorageTypeEmptyDir
+}
+
+// DNSConfig configures how fast clients see changes of the broker records.
+// The TTL of the in cluster records is set by the kubernetes plugin of CoreDNS
+// (5 seconds by default), the operator can not change it.
+type DNSConfig struct {
+	// PublishNotReadyAddresses publishes the records of brokers in the
+	// headless Service before they are ready, so a new broker resolves as
+	// soon as its Pod has an IP
+	PublishNotReadyAddresses bool `json:"publishNotReadyAddresses,omitempty"`
+	// ExternalRecordTTL is the TTL in seconds of the records external-dns
+	// creates for the external subdomains
+	// (external-dns.alpha.kubernetes.io/ttl)
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ExternalRecordTTL *int32 `json:"externalRecordTTL,omitempty"`
+}
+
+// Autoscaling configures the signals the brokers give to autoscalers. The
+// requests of the broker Pods are always set, so the cluster autoscaler can
+// plan with them.
+//
+// A HorizontalPodAutoscaler scales the brokers through the scale subresource
+// of the Cluster, whose selector matches the broker Pods, so their resource
+// and custom metrics can be used. A broker has to be decommissioned before
+// its Pod is removed, and the operator does not decommission brokers when the
+// replicas are lowered. With DecommissionDrain the StatefulSet is scaled down
+// to the lowest ordinal whose brokers above are drained, otherwise the
+// brokers are kept and only scaling up is applied. The minReplicas of the
+// autoscaler should be raised together with the replicas it scaled to.
+type Autoscaling struct {
+	// ClusterAutoscalerAnnotations marks the broker Pods as not safe to evict
+	// (cluster-autoscaler.kubernetes.io/safe-to-evict), so the cluster
+	// autoscaler does not remove a node to move a broker away from its data
+	ClusterAutoscalerAnnotations bool `json:"clusterAutoscalerAnnotations,omitempty"`
+	// VerticalPodAutoscaler creates a VerticalPodAutoscaler for the brokers
+	// in recommendation only mode (updateMode Off). Redpanda sizes its memory
+	// with the --memory argument, so resizes of the VerticalPodAutoscaler must
+	// not be applied to the brokers. No other VerticalPodAutoscaler may target
+	// them.
+	VerticalPodAutoscaler bool `json:"verticalPodAutoscaler,omitempty"`
+}
+
+// PodMonitor defines the PodMonitor of the brokers
+type PodMonitor struct {
+	// Interval between two scrapes, defaults to the interval of Prometheus
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Path of the metrics, defaults to /metrics
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Labels of the PodMonitor, e.g. to match the podMonitorSelector of
+	// Prometheus. The labels of the cluster take precedence.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Replication defines the MirrorMaker 2 Deployment replicating the topics of
+// the source Cluster. MirrorMaker connects to the internal Kafka API
+// listeners of both clusters with their superuser and client certificates.
+// The certificates are verified against the CA of the clusters, not the
+// names of the brokers.
+type Replication struct {
+	// Image is the fully qualified name of a Kafka image shipping
+	// MirrorMaker 2, version 2.7 or later
+	Image string `json:"image"`
+	// Command starts MirrorMaker 2, the path of its configuration is
+	// appended. Defaults to /opt/kafka/bin/connect-mirror-maker.sh.
+	// +optional
+	Command []string `json:"command,omitempty"`
+	// Source is the Cluster the topics are replicated from
+	Source ReplicationSource `json:"source"`
+	// Topics is a comma separated list of regular expressions matching the
+	// replicated topics. Defaults to every topic.
+	// +optional
+	Topics string `json:"topics,omitempty"`
+	// TopicsExclude is a comma separated list of regular expressions
+	// matching topics that are not replicated. Defaults to the internal
+	// topics.
+	// +optional
+	TopicsExclude string `json:"topicsExclude,omitempty"`
+	// SyncGroupOffsets translates the committed offsets of the consumer
+	// groups of the source into this cluster, so consumers can fail over
+	// +optional
+	SyncGroupOffsets bool `json:"syncGroupOffsets,omitempty"`
+	// Replicas of the MirrorMaker Deployment. Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Resources of the MirrorMaker container
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// ReplicationSource references the Cluster the topics are replicated from
+type ReplicationSource struct {
+	// Name of the source Cluster
+	Name string `json:"name"`
+	// Namespace of the source Cluster, defaults to the namespace of this
+	// cluster
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// DefaultReplicationTopicsExclude are the topics MirrorMaker 2 does not
+// replicate by default, the internal topics of Kafka, Redpanda and
+// MirrorMaker
+const DefaultReplicationTopicsExclude = `.*[\-\.]internal,.*\.replica,__.*,_schemas`
+
+// StorageType is the kind of volume holding the data directory
+type StorageType string
+
+const (
+	// StorageTypePersistentVolumeClaim keeps the data directory on a
+	// PersistentVolumeClaim per broker
+	StorageTypePersistentVolumeClaim StorageType = "persistentVolumeClaim"
+	// StorageTypeEmptyDir keeps the data directory on an emptyDir that is
+	// deleted with the Pod
+	StorageTypeEmptyDir StorageType = "emptyDir"
+)
+
+// StorageHints describe the storage the data directory of the brokers
+// should have
+type StorageHints struct {
+	// Filesystem of the data directory. Defaults to xfs, which Redpanda is
+	// tuned for.
+	// +kubebuilder:validation:Enum=xfs;ext4
+	// +optional
+	Filesystem string `json:"filesystem,omitempty"`
+	// MountOptions the data directory should be mounted with, e.g. noatime
+	// +optional
+	MountOptions []string `json:"mountOptions,omitempty"`
+	// DiskScheduler the block device of the data directory should use. It
+	// is not checked when it is not set.
+	// +kubebuilder:validation:Enum=none;mq-deadline;kyber;bfq
+	// +optional
+	DiskScheduler string `json:"diskScheduler,omitempty"`
+}
+
+// DefaultStorageFilesystem is the filesystem the data directory is expected
+// to have when the storage hints do not name one
+const DefaultStorageFilesystem = "xfs"
+
+// ExpectedFilesystem returns the filesystem of the hints or the default one
+func (h *StorageHints) ExpectedFilesystem() string {
+	if h.Filesystem == "" {
+		return DefaultStorageFilesystem
+	}
+	return h.Filesystem
+}
+
+// EmptyDirStorage configures the emptyDir holding the data directory
+type EmptyDirStorage struct {
+	// Medium of the emptyDir, Memory backs it with tmpfs. Memory backed data
+	// counts against the memory limit of the broker container.
+	// +kubebuilder:validation:Enum="";Memory
+	// +optional
+	Medium corev1.StorageMedium `json:"medium,omitempty"`
+	// SizeLimit of the emptyDir
+	// +optional
+	SizeLimit *resource.Quantity `json:"sizeLimit,omitempty"`
+}
+
+// PVCRetentionPolicyType defines what happens to the PersistentVolumeClaim of
+// a broker
+// +kubebuilder:validation:Enum=Retain;Delete
+type PVCRetentionPolicyType string
+
+const (
+	// PVCRetentionPolicyRetain keeps the PersistentVolumeClaim
+	PVCRetentionPolicyRetain PVCRetentionPolicyType = "Retain"
+	// PVCRetentionPolicyDelete deletes the PersistentVolumeClaim
+	PVCRetentionPolicyDelete PVCRetentionPolicyType = "Delete"
+)
+
+// PVCRetentionPolicy mirrors the persistentVolumeClaimRetentionPolicy of
+// StatefulSets. The operator applies it itself, so it works on Kubernetes
+// versions without the StatefulSet field.
+type PVCRetentionPolicy struct {
+	// WhenDeleted applies when the cluster is deleted
+	WhenDeleted PVCRetentionPolicyType `json:"whenDeleted,omitempty"`
+	// WhenScaled applies to the claims of the brokers removed by a scale down
+	WhenScaled PVCRetentionPolicyType `json:"whenScaled,omitempty"`
 }
 
 // ExternalConnectivityConfig adds listener that can be reached outside
@@ -121,6 +1431,90 @@ type ExternalConnectivityConfig struct {
 	// If TLS is enabled then this subdomain will be requested
 	// as a subject alternative name.
 	Subdomain string `json:"subdomain,omitempty"`
+	// Subdomains are additional DNS names under which the brokers are
+	// reachable, e.g. one per tenant. Each broker gets a record of the form
+	// HOSTNAME_OF_A_POD.SUBDOMAIN for every subdomain and, if TLS is
+	// enabled, every subdomain is requested as a subject alternative name.
+	// A Kafka listener advertises a single address, so brokers keep
+	// advertising themselves under Subdomain, which is required when
+	// Subdomains are set.
+	Subdomains []string `json:"subdomains,omitempty"`
+	// ClusterScopedRecords publishes the brokers under
+	// <cluster>.<namespace>.SUBDOMAIN instead of SUBDOMAIN, so each broker is
+	// reachable as ORDINAL.<cluster>.<namespace>.SUBDOMAIN. It lets clusters
+	// in different namespaces share a subdomain (e.g. an external-dns zone)
+	// without colliding records. Enabling it changes the advertised
+	// addresses of a running cluster.
+	// +optional
+	ClusterScopedRecords bool `json:"clusterScopedRecords,omitempty"`
+	// AdvertisedPort is the port the brokers advertise on the external
+	// Kafka API listener instead of their node port, for clients that
+	// connect through a load balancer or NAT that listens on another port.
+	// The address in front of every broker has to forward it to the node
+	// port of the broker.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	AdvertisedPort int `json:"advertisedPort,omitempty"`
+	// MembershipReadiness adds a readiness gate that the operator sets once
+	// the broker is an active and alive member of the cluster, so the
+	// external Service only routes clients to brokers that joined instead of
+	// brokers that merely opened their ports. The headless Service then
+	// publishes brokers before they are ready, the brokers reach each other
+	// through it while they join. Changing it restarts every broker.
+	// +optional
+	MembershipReadiness bool `json:"membershipReadiness,omitempty"`
+	// NodeAddressAnnotation is the annotation of the nodes holding the
+	// address the brokers advertise when Subdomain is empty, e.g. published
+	// by a DaemonSet on bare metal nodes without an ExternalIP address.
+	// Nodes without the annotation fall back to their ExternalIP address, a
+	// broker on a node with neither does not start. Changing it restarts
+	// every broker.
+	// +optional
+	NodeAddressAnnotation string `json:"nodeAddressAnnotation,omitempty"`
+	// AdvertisedAddressesFrom references the ConfigMap or Secret holding the
+	// addresses the brokers advertise on their external listeners instead
+	// of the computed ones, e.g. assigned by an IP address management
+	// system. The key of the broker ordinal holds the address of every
+	// external listener of the broker, the keys <ordinal>.kafka and
+	// <ordinal>.pandaproxy override it for one listener. The StatefulSet is
+	// not updated while a broker has no address. Brokers pick up changes
+	// when they restart.
+	// +optional
+	AdvertisedAddressesFrom *AdvertisedAddressesSource `json:"advertisedAddressesFrom,omitempty"`
+	// ExternalDNS adds annotations for external-dns to the headless Service
+	// next to the hostname annotation set by the operator, e.g. for the
+	// settings of a DNS provider
+	// +optional
+	ExternalDNS *ExternalDNSConfig `json:"externalDNS,omitempty"`
+}
+
+// AdvertisedAddressesSource is the ConfigMap or Secret holding the advertised
+// external addresses of the brokers, exactly one of them is set
+type AdvertisedAddressesSource struct {
+	// ConfigMapName is the name of the ConfigMap holding the addresses
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// SecretName is the name of the Secret holding the addresses
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// ExternalDNSConfig configures the records external-dns creates for the
+// external subdomains
+type ExternalDNSConfig struct {
+	// TTL is the TTL in seconds of the records
+	// (external-dns.alpha.kubernetes.io/ttl). It takes precedence over
+	// dns.externalRecordTTL.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TTL *int32 `json:"ttl,omitempty"`
+	// Annotations are added to the headless Service, e.g.
+	// external-dns.alpha.kubernetes.io/cloudflare-proxied or
+	// external-dns.alpha.kubernetes.io/aws-weight. The hostname annotation
+	// is set by the operator and can not be overridden.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // ClusterStatus defines the observed state of Cluster
@@ -131,12 +1525,530 @@ type ClusterStatus struct {
 	// Replicas show how many nodes are working in the cluster
 	// +optional
 	Replicas int32 `json:"replicas"`
+	// Selector is the label selector of the broker Pods, used by the scale
+	// subresource
+	// +optional
+	Selector string `json:"selector,omitempty"`
 	// Nodes of the provisioned redpanda nodes
 	// +optional
 	Nodes NodesList `json:"nodes,omitempty"`
-	// Indicates cluster is upgrading
+	// Indicates cluster is upgrading, or restarting its brokers one at a
+	// time to apply configuration that Redpanda only reads on startup
 	// +optional
 	Upgrading bool `json:"upgrading"`
+	// RestartOrder are the Pod ordinals of the brokers in the order they are
+	// restarted while upgrading with the FewestLeadershipsFirst restart
+	// order
+	// +optional
+	RestartOrder []int32 `json:"restartOrder,omitempty"`
+	// RolloutFailure is the rolling update that was rolled back, set while
+	// the StatefulSet is kept on the reverted revision
+	// +optional
+	RolloutFailure *RolloutFailure `json:"rolloutFailure,omitempty"`
+	// The lowest replication factor across the managed internal topic
+	// partitions
+	// +optional
+	InternalTopicReplicationFactor int `json:"internalTopicReplicationFactor,omitempty"`
+	// Bootstrap lists ready to use bootstrap servers for every Kafka API
+	// listener
+	// +optional
+	Bootstrap []BootstrapListener `json:"bootstrap,omitempty"`
+	// Conditions reported by the operator checks
+	// +optional
+	Conditions []ClusterCondition `json:"conditions,omitempty"`
+	// UnboundVolumes are the PersistentVolumeClaims of brokers that did not
+	// bind in time. The brokers can not start until they are bound.
+	// +optional
+	UnboundVolumes []UnboundVolume `json:"unboundVolumes,omitempty"`
+	// SuperuserSecret is the name of the Secret with the credentials of the
+	// SASL superuser created by the operator. It is set once the user exists.
+	// +optional
+	SuperuserSecret string `json:"superuserSecret,omitempty"`
+	// SuperuserPasswordRotated is when the password of the bootstrap
+	// superuser was last rotated, or created
+	// +optional
+	SuperuserPasswordRotated *metav1.Time `json:"superuserPasswordRotated,omitempty"`
+	// DefaultTopicPartitions is the default partition count of new topics
+	// applied to the running cluster
+	// +optional
+	DefaultTopicPartitions int `json:"defaultTopicPartitions,omitempty"`
+	// ControllerID is the node ID of the broker leading the controller
+	// partition, as reported by the Admin API
+	// +optional
+	ControllerID *int `json:"controllerId,omitempty"`
+	// ControllerLeader is the Pod of the broker leading the controller
+	// partition
+	// +optional
+	ControllerLeader string `json:"controllerLeader,omitempty"`
+	// DownBrokers are the brokers of the Pod ordinals that the cluster
+	// reports as not alive, with the time they were first seen down
+	// +optional
+	DownBrokers []DownBroker `json:"downBrokers,omitempty"`
+	// DrainingBrokers are the brokers of the Pod ordinals that are
+	// decommissioned, with the progress of their draining
+	// +optional
+	DrainingBrokers []DrainingBroker `json:"drainingBrokers,omitempty"`
+	// CapacityCheckedDecommissions are the node IDs of the brokers whose
+	// decommissioning passed the disk capacity check, they are not checked
+	// again while their replicas move
+	// +optional
+	CapacityCheckedDecommissions []int `json:"capacityCheckedDecommissions,omitempty"`
+	// PendingRestarts are the ordinals of the brokers waiting for a restart
+	// requested with the RestartAnnotation
+	// +optional
+	PendingRestarts []int32 `json:"pendingRestarts,omitempty"`
+	// BrokerRestart is the requested restart of a broker that is in progress
+	// +optional
+	BrokerRestart *BrokerRestart `json:"brokerRestart,omitempty"`
+	// LastCrashLoopRecovery is when the operator last recreated the Pod of a
+	// crash looping broker
+	// +optional
+	LastCrashLoopRecovery *metav1.Time `json:"lastCrashLoopRecovery,omitempty"`
+	// BrokerRacks are the racks the brokers run with when rack awareness is
+	// enabled
+	// +optional
+	BrokerRacks []BrokerRack `json:"brokerRacks,omitempty"`
+	// Backup reports the last recovery point confirmed in the cloud storage
+	// bucket when backups are configured
+	// +optional
+	Backup *BackupStatus `json:"backup,omitempty"`
+	// ResourceRecommendation is the recommended resources of the brokers
+	// and the samples of the current window when the resource
+	// recommendation is configured
+	// +optional
+	ResourceRecommendation *ResourceRecommendationStatus `json:"resourceRecommendation,omitempty"`
+	// WarmTopics are the topics of the topic warmup that exist with a leader
+	// for every partition
+	// +optional
+	WarmTopics []string `json:"warmTopics,omitempty"`
+	// DebugBundle reports the last requested debug bundle
+	// +optional
+	DebugBundle *DebugBundleStatus `json:"debugBundle,omitempty"`
+	// Replication reports how far the topics replicated from the source
+	// Cluster are behind
+	// +optional
+	Replication *ReplicationStatus `json:"replication,omitempty"`
+	// AppliedProperties are the JSON encoded values of the cluster
+	// properties the operator applied to the running cluster. A live value
+	// that differs from the applied one was changed out of band.
+	// +optional
+	AppliedProperties map[string]string `json:"appliedProperties,omitempty"`
+	// UnknownProperties are the cluster properties the configuration schema
+	// of the running version does not list, they were not applied
+	// +optional
+	UnknownProperties []string `json:"unknownProperties,omitempty"`
+	// ClusterUUID is the UUID of the Redpanda cluster, as reported by the
+	// Admin API. Releases without the endpoint leave it empty.
+	// +optional
+	ClusterUUID string `json:"clusterUUID,omitempty"`
+	// HighestVersion is the highest Version applied to the brokers, lower
+	// versions are rejected unless AllowDowngrade is set
+	// +optional
+	HighestVersion string `json:"highestVersion,omitempty"`
+	// Version is the Redpanda version run by every broker. It is empty
+	// while the brokers run different versions, e.g. mid upgrade.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// BrokerVersions are the Redpanda versions run by the brokers
+	// +optional
+	BrokerVersions []BrokerVersion `json:"brokerVersions,omitempty"`
+	// CABundle is the name of the ConfigMap that holds the CA certificates
+	// of the TLS listeners, for clients to mount. It is empty when no
+	// listener uses TLS.
+	// +optional
+	CABundle string `json:"caBundle,omitempty"`
+	// CurrentOperation is what the operator is doing to the cluster, e.g.
+	// an upgrade, and the step it is waiting for. It is cleared when the
+	// cluster is idle.
+	// +optional
+	CurrentOperation *ClusterOperation `json:"currentOperation,omitempty"`
+}
+
+// ClusterOperationType is the kind of operation the operator runs on a Cluster
+type ClusterOperationType string
+
+const (
+	// ClusterOperationProvision creates the resources of the cluster and
+	// waits for them, e.g. for certificates
+	ClusterOperationProvision ClusterOperationType = "Provision"
+	// ClusterOperationScaleUp adds brokers
+	ClusterOperationScaleUp ClusterOperationType = "ScaleUp"
+	// ClusterOperationUpgrade restarts the brokers one by one on a new image
+	ClusterOperationUpgrade ClusterOperationType = "Upgrade"
+)
+
+// ClusterOperation describes the operation the operator is running
+type ClusterOperation struct {
+	// Type of the operation
+	Type ClusterOperationType `json:"type"`
+	// Description of the operation, e.g. scaling 3 to 5 brokers
+	Description string `json:"description"`
+	// Phase is the step the operation waits for, e.g. the restart of a broker
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// StartTime is when the operator started the operation
+	StartTime metav1.Time `json:"startTime"`
+}
+
+// BrokerVersion is the Redpanda version run by a broker
+type BrokerVersion struct {
+	// NodeID of the broker
+	NodeID int `json:"nodeId"`
+	// Version as reported by the Admin API
+	Version string `json:"version"`
+}
+
+// BrokerRestartPhase is the step of a requested broker restart
+type BrokerRestartPhase string
+
+const (
+	// BrokerRestartMaintenance waits until the broker in maintenance mode
+	// handed its leadership over to the other brokers
+	BrokerRestartMaintenance BrokerRestartPhase = "Maintenance"
+	// BrokerRestartRestarting waits until the recreated Pod of the broker is
+	// ready, its maintenance mode is disabled afterwards
+	BrokerRestartRestarting BrokerRestartPhase = "Restarting"
+)
+
+// BrokerRestart is the progress of a restart requested with the
+// RestartAnnotation
+type BrokerRestart struct {
+	// Ordinal is the Pod ordinal of the broker
+	Ordinal int32 `json:"ordinal"`
+	// Phase is the step the restart is in
+	Phase BrokerRestartPhase `json:"phase"`
+	// PodUID is the UID of the Pod that was deleted, the Pod recreated by
+	// the StatefulSet has another one
+	// +optional
+	PodUID types.UID `json:"podUID,omitempty"`
+	// Since is when the restart entered the phase
+	Since metav1.Time `json:"since"`
+}
+
+// ClusterConditionType is the type of a Cluster condition
+type ClusterConditionType string
+
+// ClusterAdvertisedAddressesReachable is true when the operator can connect to
+// every advertised external Kafka API address
+const ClusterAdvertisedAddressesReachable ClusterConditionType = "AdvertisedAddressesReachable"
+
+// ClusterStatefulSetUpdateBlocked is true when the StatefulSet is not
+// updated because immutable fields changed
+const ClusterStatefulSetUpdateBlocked ClusterConditionType = "StatefulSetUpdateBlocked"
+
+// ClusterRolloutFailed is true when a rolling update was rolled back
+// because a restarted broker did not become ready
+const ClusterRolloutFailed ClusterConditionType = "RolloutFailed"
+
+// ClusterPostBootstrapJobComplete is true when the post bootstrap job of the
+// current job spec succeeded
+const ClusterPostBootstrapJobComplete ClusterConditionType = "PostBootstrapJobComplete"
+
+// ClusterBootstrapComplete is true when a bootstrap-once cluster is healthy
+// and the operator stopped reconciling its observed generation
+const ClusterBootstrapComplete ClusterConditionType = "BootstrapComplete"
+
+// ClusterReady is true when every broker is ready and the Admin API reports a
+// healthy cluster without leaderless or under replicated partitions
+const ClusterReady ClusterConditionType = "ClusterReady"
+
+// ClusterNodeIDsConsistent is false when the brokers known to the cluster do
+// not match the node IDs derived from the Pod ordinals
+const ClusterNodeIDsConsistent ClusterConditionType = "NodeIDsConsistent"
+
+// ClusterConfigDrift is true when cluster properties managed by the operator
+// were changed on the running cluster, e.g. with rpk cluster config set
+const ClusterConfigDrift ClusterConditionType = "ConfigDrift"
+
+// ClusterUnknownProperties is true when cluster properties are not applied
+// because the configuration schema of the running version does not list them
+const ClusterUnknownProperties ClusterConditionType = "UnknownProperties"
+
+// ClusterVersionSkew is true when the brokers run different Redpanda
+// versions, e.g. during an upgrade or when an upgrade got stuck
+const ClusterVersionSkew ClusterConditionType = "VersionSkew"
+
+// ClusterAdminAPIUnavailable is true when the steps that act on the running
+// cluster through the Admin API were skipped, the Kubernetes objects are
+// still reconciled
+const ClusterAdminAPIUnavailable ClusterConditionType = "AdminAPIUnavailable"
+
+// ClusterAdminAPIUnsupported is true when steps need Admin API endpoints the
+// running Redpanda version does not serve, the message lists the steps and
+// the endpoints. The steps fall back to what the operator can tell without
+// the endpoints or are skipped.
+const ClusterAdminAPIUnsupported ClusterConditionType = "AdminAPIUnsupported"
+
+// ClusterStorageProvisioningFailed is true when the PersistentVolumeClaims
+// of brokers stay unbound, e.g. because their zone has no capacity left
+const ClusterStorageProvisioningFailed ClusterConditionType = "StorageProvisioningFailed"
+
+// ClusterStorageSuboptimal is true when the StorageClass, the volumes or the
+// mounted data directories of the brokers deviate from the storage hints
+const ClusterStorageSuboptimal ClusterConditionType = "StorageSuboptimal"
+
+// ClusterInsufficientCapacity is true when fewer nodes than brokers have the
+// CPU and memory requested by a broker allocatable, the brokers that do not
+// fit stay Pending
+const ClusterInsufficientCapacity ClusterConditionType = "InsufficientCapacity"
+
+// ClusterQuotaExceeded is true when the ResourceQuotas or LimitRanges of the
+// namespace would reject the brokers or their claims
+const ClusterQuotaExceeded ClusterConditionType = "QuotaExceeded"
+
+// ClusterBrokersUnschedulable is true while the scheduler can not place
+// brokers, e.g. because of the strict failure domain spread
+const ClusterBrokersUnschedulable ClusterConditionType = "BrokersUnschedulable"
+
+// ClusterBrokersFailed is true when brokers stayed down longer than the
+// grace period of the broker failure policy
+const ClusterBrokersFailed ClusterConditionType = "BrokersFailed"
+
+// ClusterBrokersCrashLooping is true while the Redpanda container of brokers
+// restarted more often than the crash loop recovery threshold
+const ClusterBrokersCrashLooping ClusterConditionType = "BrokersCrashLooping"
+
+// ClusterBrokerRacksDrifted is true while brokers run with another rack than
+// the rack label of their node
+const ClusterBrokerRacksDrifted ClusterConditionType = "BrokerRacksDrifted"
+
+// ClusterBackupOverdue is true while the operator can not confirm a recovery
+// point in the cloud storage bucket, unknown when the running version does
+// not report the cluster health the confirmation needs
+const ClusterBackupOverdue ClusterConditionType = "BackupOverdue"
+
+// ClusterTopicsWarm is true when every topic of the topic warmup exists and
+// each of its partitions has a leader
+const ClusterTopicsWarm ClusterConditionType = "TopicsWarm"
+
+// ClusterBrokersStabilizing is true while brokers wait for the readiness
+// stabilization window
+const ClusterBrokersStabilizing ClusterConditionType = "BrokersStabilizing"
+
+// ClusterReplicating is true while the lag of the replication from the
+// source Cluster is measured
+const ClusterReplicating ClusterConditionType = "Replicating"
+
+// RolloutFailure is a rolled back rolling update
+type RolloutFailure struct {
+	// TemplateHash is the hash of the Pod template that failed to roll out
+	TemplateHash string `json:"templateHash"`
+	// Revision is the StatefulSet revision the brokers were reverted to
+	Revision string `json:"revision"`
+	// Pod is the broker Pod that did not become ready
+	Pod string `json:"pod"`
+	// FailedAt is when the update was rolled back
+	FailedAt metav1.Time `json:"failedAt"`
+}
+
+// BackupStatus is the last recovery point of the cluster in the cloud
+// storage bucket
+type BackupStatus struct {
+	// LastBackupTime is when the recovery point was confirmed
+	LastBackupTime metav1.Time `json:"lastBackupTime"`
+	// RecoveryPoint is the time up to which the closed segments of the
+	// cluster are in the bucket, one interval before the confirmation
+	RecoveryPoint metav1.Time `json:"recoveryPoint"`
+}
+
+// ResourceRecommendationStatus is the recommended resources of the brokers
+// and the peak usage of the current window
+type ResourceRecommendationStatus struct {
+	// CPU recommended for each broker, set once the first window completed
+	// +optional
+	CPU *resource.Quantity `json:"cpu,omitempty"`
+	// Memory recommended for each broker, set once the first window
+	// completed
+	// +optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+	// RecommendedAt is when the window of the recommendation completed
+	// +optional
+	RecommendedAt *metav1.Time `json:"recommendedAt,omitempty"`
+	// WindowStart is when the current window started
+	WindowStart metav1.Time `json:"windowStart"`
+	// LastSampleTime is when the brokers were last sampled
+	// +optional
+	LastSampleTime *metav1.Time `json:"lastSampleTime,omitempty"`
+	// Samples of the current window
+	Samples int32 `json:"samples"`
+	// PeakCPU is the most CPU a broker used in the current window
+	PeakCPU resource.Quantity `json:"peakCPU"`
+	// PeakMemory is the most memory a broker used in the current window
+	PeakMemory resource.Quantity `json:"peakMemory"`
+}
+
+// DownBroker is a broker that the cluster reports as not alive
+type DownBroker struct {
+	// NodeID of the broker
+	NodeID int `json:"nodeId"`
+	// Since is when the operator first saw the broker down
+	Since metav1.Time `json:"since"`
+}
+
+// DrainPhase is the progress of the draining of a decommissioned broker
+type DrainPhase string
+
+const (
+	// DrainPhaseDraining brokers are decommissioned by Redpanda or wait for
+	// the drain timeout
+	DrainPhaseDraining DrainPhase = "Draining"
+	// DrainPhaseDrained brokers were removed by Redpanda and the drain
+	// timeout passed, their Pods can be removed
+	DrainPhaseDrained DrainPhase = "Drained"
+)
+
+// DrainingBroker is a decommissioned broker whose Pod still runs
+type DrainingBroker struct {
+	// NodeID of the broker
+	NodeID int `json:"nodeId"`
+	// Since is when the operator first saw the broker decommissioned
+	Since metav1.Time `json:"since"`
+	// Phase is the progress of the draining
+	Phase DrainPhase `json:"phase"`
+}
+
+// UnboundVolume is the PersistentVolumeClaim of a broker that is not bound
+type UnboundVolume struct {
+	// Ordinal of the broker Pod
+	Ordinal int `json:"ordinal"`
+	// ClaimName is the name of the PersistentVolumeClaim
+	ClaimName string `json:"claimName"`
+	// Reason of the last event of the claim, e.g. ProvisioningFailed
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message of the last event of the claim
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// DebugBundlePhase is the progress of a debug bundle
+type DebugBundlePhase string
+
+const (
+	// DebugBundlePending waits for the minimal interval since the last bundle
+	DebugBundlePending DebugBundlePhase = "Pending"
+	// DebugBundleRunning is collected and uploaded by its Job
+	DebugBundleRunning DebugBundlePhase = "Running"
+	// DebugBundleSucceeded was uploaded
+	DebugBundleSucceeded DebugBundlePhase = "Succeeded"
+	// DebugBundleFailed could not be collected or uploaded
+	DebugBundleFailed DebugBundlePhase = "Failed"
+)
+
+// ReplicationStatus is the lag of the replication. The lag of a partition is
+// the difference of the high watermarks of the source and the replicated
+// partition. MirrorMaker does not preserve the offsets, the lag is an
+// estimate that also counts the records the source deleted before they
+// were replicated.
+type ReplicationStatus struct {
+	// Lag is the sum of the lag of the replicated topics
+	Lag int64 `json:"lag"`
+	// Topics are the replicated topics of the source Cluster with their lag
+	// +optional
+	Topics []ReplicatedTopic `json:"topics,omitempty"`
+	// ObservedAt is when the lag was measured
+	ObservedAt metav1.Time `json:"observedAt"`
+}
+
+// ReplicatedTopic is a topic of the source Cluster and its lag
+type ReplicatedTopic struct {
+	// Name of the topic in the source Cluster
+	Name string `json:"name"`
+	// Lag is the number of records the replicated topic is behind
+	Lag int64 `json:"lag"`
+}
+
+// DebugBundleStatus is the progress of the last requested debug bundle
+type DebugBundleStatus struct {
+	// Request is the value of the DebugBundleAnnotation the bundle was
+	// collected for
+	Request string `json:"request"`
+	// Phase of the bundle
+	Phase DebugBundlePhase `json:"phase"`
+	// JobName of the Job collecting the bundle
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+	// StartTime is when the Job was created
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is when the Job finished
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// ClusterCondition describes the state of the Cluster at a certain point
+type ClusterCondition struct {
+	// Type of the condition
+	Type ClusterConditionType `json:"type"`
+	// Status of the condition, one of True, False or Unknown
+	Status corev1.ConditionStatus `json:"status"`
+	// Last time the condition changed its status
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason of the last transition in CamelCase
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human readable details of the last transition
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Generation of the Cluster that the condition was set for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// GetCondition returns the condition of the given type or nil
+func (s *ClusterStatus) GetCondition(
+	conditionType ClusterConditionType,
+) *ClusterCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or updates the condition and returns true when anything
+// changed. The transition time is kept while the status stays the same.
+func (s *ClusterStatus) SetCondition(
+	conditionType ClusterConditionType,
+	status corev1.ConditionStatus,
+	reason, message string,
+) bool {
+	condition := s.GetCondition(conditionType)
+	if condition == nil {
+		s.Conditions = append(s.Conditions, ClusterCondition{
+			Type:               conditionType,
+			Status:             status,
+			LastTransitionTime: metav1.Now(),
+			Reason:             reason,
+			Message:            message,
+		})
+		return true
+	}
+	if condition.Status == status && condition.Reason == reason && condition.Message == message {
+		return false
+	}
+	if condition.Status != status {
+		condition.LastTransitionTime = metav1.Now()
+	}
+	condition.Status = status
+	condition.Reason = reason
+	condition.Message = message
+	return true
+}
+
+// BootstrapListener describes how clients connect to a Kafka API listener
+type BootstrapListener struct {
+	// Name of the listener, either internal or external
+	Name string `json:"name"`
+	// Servers is a comma separated list of addresses that can be passed to
+	// Kafka clients as bootstrap servers
+	Servers string `json:"servers"`
+	// TLS is true when the listener requires TLS
+	TLS bool `json:"tls,omitempty"`
+	// SASL is true when clients have to authenticate with SASL
+	SASL bool `json:"sasl,omitempty"`
 }
 
 // NodesList shows where client can find Redpanda brokers
@@ -148,6 +2060,8 @@ type NodesList struct {
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+//+kubebuilder:storageversion
 
 // Cluster is the Schema for the clusters API
 type Cluster struct {
@@ -170,12 +2084,314 @@ type ClusterList struct {
 // RedpandaConfig is the definition of the main configuration
 type RedpandaConfig struct {
 	RPCServer     SocketAddress `json:"rpcServer,omitempty"`
-	KafkaAPI      SocketAddress `json:"kafkaApi,omitempty"`
+	KafkaAPI      KafkaAPI      `json:"kafkaApi,omitempty"`
 	AdminAPI      SocketAddress `json:"admin,omitempty"`
 	DeveloperMode bool          `json:"developerMode,omitempty"`
 	TLS           TLSConfig     `json:"tls,omitempty"`
 	// Number of partitions in the internal group membership topic
+	// (group_topic_partitions), which holds the consumer offsets. Redpanda
+	// creates the topic with it when the cluster bootstraps, so it sizes the
+	// topic up front, and it cannot be decreased on an existing cluster.
+	// +kubebuilder:validation:Minimum=0
 	GroupTopicPartitions int `json:"groupTopicPartitions,omitempty"`
+	// Limits enforced on Kafka API clients
+	KafkaClientLimits KafkaClientLimits `json:"kafkaClientLimits,omitempty"`
+	// BindToPodIP makes the Kafka API listeners listen on the Pod IP instead
+	// of all interfaces (0.0.0.0). Advertised addresses keep working as the
+	// Pod DNS name and the host port both resolve to the Pod IP.
+	BindToPodIP bool `json:"bindToPodIP,omitempty"`
+	// AdminAPIBindNetwork is the CIDR of a management network, e.g. attached
+	// as a secondary interface with Multus. The Admin API, which also serves
+	// the metrics, listens on the address of the Pod in that network instead
+	// of all interfaces. The readiness probe and the operator follow that
+	// address, the operator reads it from the
+	// k8s.v1.cni.cncf.io/network-status annotation of the Pods and has to be
+	// attached to the same network. With Admin API TLS the certificate has to
+	// be valid for the management addresses.
+	// +optional
+	AdminAPIBindNetwork string `json:"adminApiBindNetwork,omitempty"`
+	// SuperuserPasswordRotation replaces the password of the bootstrap
+	// superuser on an interval
+	// +optional
+	SuperuserPasswordRotation *PasswordRotation `json:"superuserPasswordRotation,omitempty"`
+	// Raft tuning for clusters running on high latency networks
+	Raft RaftTuning `json:"raft,omitempty"`
+	// RPCServer tuning of the internal RPC connections between brokers
+	// +optional
+	RPCServerTuning RPCServerTuning `json:"rpcServerTuning,omitempty"`
+	// Defaults of topics created automatically by Kafka clients
+	TopicDefaults TopicDefaults `json:"topicDefaults,omitempty"`
+	// Retention of the topics that do not set their own
+	// +optional
+	Retention Retention `json:"retention,omitempty"`
+	// Compaction of the topics with the compact cleanup policy
+	// +optional
+	Compaction Compaction `json:"compaction,omitempty"`
+	// Kafka protocol behaviors pinned for older client fleets
+	// +optional
+	KafkaCompatibility KafkaCompatibility `json:"kafkaCompatibility,omitempty"`
+	// Compression defaults of topics and of the traffic between brokers
+	// +optional
+	Compression Compression `json:"compression,omitempty"`
+	// RackAwareness assigns the brokers to racks
+	// +optional
+	RackAwareness *RackAwareness `json:"rackAwareness,omitempty"`
+	// PandaproxyAPI enables Pandaproxy, the HTTP API to produce and consume
+	// messages, on every broker
+	// +optional
+	PandaproxyAPI *PandaproxyAPI `json:"pandaproxyApi,omitempty"`
+	// SchemaValidation checks the cluster properties against the
+	// configuration schema of the running Redpanda version before they are
+	// applied through the Admin API, so a property the version does not know
+	// does not fail the whole update. No validation when not set.
+	// +optional
+	SchemaValidation *ConfigSchemaValidation `json:"schemaValidation,omitempty"`
+}
+
+// ConfigSchemaValidation configures how cluster properties unknown to the
+// running Redpanda version are handled. The unknown properties are reported
+// in the status and the UnknownProperties condition.
+type ConfigSchemaValidation struct {
+	// OnUnknownProperty is Skip to apply the known properties and leave the
+	// unknown ones out, or Reject to apply no property until every property
+	// is known, e.g. after the brokers were upgraded. Defaults to Skip.
+	// +kubebuilder:validation:Enum=Skip;Reject
+	// +optional
+	OnUnknownProperty UnknownPropertyPolicy `json:"onUnknownProperty,omitempty"`
+}
+
+// UnknownPropertyPolicy defines how cluster properties unknown to the
+// running version are handled
+type UnknownPropertyPolicy string
+
+const (
+	// UnknownPropertySkip applies the known properties only
+	UnknownPropertySkip UnknownPropertyPolicy = "Skip"
+	// UnknownPropertyReject applies no property while one is unknown
+	UnknownPropertyReject UnknownPropertyPolicy = "Reject"
+)
+
+// RackAwareness configures the racks of the brokers. The rack of a broker
+// is the value of a label of its node, read by the configurator when the
+// broker starts, so enabling it or changing the label restarts the brokers.
+type RackAwareness struct {
+	// Enabled assigns every broker the rack of its node (redpanda.rack)
+	Enabled bool `json:"enabled,omitempty"`
+	// NodeLabel is the node label holding the rack. Defaults to
+	// topology.kubernetes.io/zone.
+	// +optional
+	NodeLabel string `json:"nodeLabel,omitempty"`
+	// OnRackChange is what the operator does when the rack label of the
+	// node of a broker no longer matches the rack the broker runs with,
+	// e.g. after the node was relabeled or the Pod moved to another zone.
+	// Report only sets the BrokerRacksDrifted condition. Restart recreates
+	// the Pods of the drifted brokers one at a time while all brokers are
+	// ready, the data volumes are kept. Defaults to Report.
+	// +kubebuilder:validation:Enum=Report;Restart
+	// +optional
+	OnRackChange RackChangePolicy `json:"onRackChange,omitempty"`
+}
+
+// RackChangePolicy defines how brokers with a stale rack are handled
+type RackChangePolicy string
+
+const (
+	// RackChangeReport reports brokers with a stale rack
+	RackChangeReport RackChangePolicy = "Report"
+	// RackChangeRestart restarts brokers with a stale rack so the
+	// configurator renders the rack of their node
+	RackChangeRestart RackChangePolicy = "Restart"
+)
+
+// BrokerRack is the rack a broker started with
+type BrokerRack struct {
+	NodeID int `json:"nodeId"`
+	// Rack read from the node label when the broker started, empty when
+	// the node had no label
+	// +optional
+	Rack string `json:"rack,omitempty"`
+	// StartedAt is the start of the Redpanda container running with the
+	// rack
+	StartedAt metav1.Time `json:"startedAt"`
+}
+
+// MessageTimestampType is the timestamp Redpanda stores with every message
+// +kubebuilder:validation:Enum=CreateTime;LogAppendTime
+type MessageTimestampType string
+
+// KafkaCompatibility configures Kafka protocol behaviors that older clients
+// depend on. Redpanda negotiates API versions with every client and does not
+// support pinning them, so only the behaviors below can be configured.
+// Changing any of them restarts the brokers.
+type KafkaCompatibility struct {
+	// MessageTimestampType of new topics (log_message_timestamp_type).
+	// Clients that do not set timestamps need LogAppendTime.
+	// +optional
+	MessageTimestampType MessageTimestampType `json:"messageTimestampType,omitempty"`
+	// EnableIdempotence allows idempotent producers (enable_idempotence).
+	// Older clients that do not support it probe for it and fall back.
+	// +optional
+	EnableIdempotence *bool `json:"enableIdempotence,omitempty"`
+	// EnableTransactions allows transactional producers
+	// (enable_transactions). It requires idempotence.
+	// +optional
+	EnableTransactions *bool `json:"enableTransactions,omitempty"`
+}
+
+// Retention configures how long and how much data topics keep. Segments are
+// deleted once either the time or the size limit is exceeded, whichever is
+// reached first. Changing the properties restarts the brokers, -1 disables a
+// limit.
+type Retention struct {
+	// TimeMs is how long data is kept (delete_retention_ms)
+	// +kubebuilder:validation:Minimum=-1
+	// +optional
+	TimeMs *int64 `json:"timeMs,omitempty"`
+	// Bytes is how much data a partition keeps (retention_bytes)
+	// +kubebuilder:validation:Minimum=-1
+	// +optional
+	Bytes *int64 `json:"bytes,omitempty"`
+}
+
+// Compaction configures how compacted topics are cleaned up. Changing the
+// properties restarts the brokers.
+type Compaction struct {
+	// IntervalMs is how often the brokers compact the segments
+	// (log_compaction_interval_ms)
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	IntervalMs *int64 `json:"intervalMs,omitempty"`
+	// CleanupPolicy of the topics that do not set their own
+	// (log_cleanup_policy), one of delete, compact or compact,delete
+	// +optional
+	CleanupPolicy string `json:"cleanupPolicy,omitempty"`
+}
+
+// TopicDefaults configures automatic topic creation. They are cluster
+// properties, changing them restarts the brokers.
+type TopicDefaults struct {
+	// AutoCreateTopics allows Kafka clients to create topics on first use
+	// (auto_create_topics_enabled)
+	// +optional
+	AutoCreateTopics *bool `json:"autoCreateTopics,omitempty"`
+	// Number of partitions of topics created without an explicit partition
+	// count (default_topic_partitions)
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Partitions int `json:"partitions,omitempty"`
+}
+
+// RaftTuning configures Raft timeouts and batching. The heartbeat interval
+// and the election timeout are read when a broker starts, so changing them,
+// or the replicate batch window, restarts the brokers.
+type RaftTuning struct {
+	// Interval between Raft heartbeats sent by leaders
+	// (raft_heartbeat_interval_ms). It has to be lower than the election
+	// timeout.
+	// +kubebuilder:validation:Minimum=0
+	HeartbeatIntervalMs int `json:"heartbeatIntervalMs,omitempty"`
+	// Time after which a follower that did not hear from the leader starts
+	// an election (election_timeout_ms)
+	// +kubebuilder:validation:Minimum=0
+	ElectionTimeoutMs int `json:"electionTimeoutMs,omitempty"`
+	// Maximum size in bytes of the batches replicated to followers at once
+	// (raft_replicate_batch_window_size)
+	// +kubebuilder:validation:Minimum=0
+	ReplicateBatchWindowSize int `json:"replicateBatchWindowSize,omitempty"`
+}
+
+// RPCServerTuning configures the internal RPC connections between brokers on
+// lossy networks. Both are read when a broker starts, so changing them
+// restarts the brokers.
+type RPCServerTuning struct {
+	// Time after which a Raft heartbeat RPC to a follower times out
+	// (raft_heartbeat_timeout_ms). It has to be longer than the heartbeat
+	// interval.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=60000
+	// +optional
+	HeartbeatTimeoutMs int `json:"heartbeatTimeoutMs,omitempty"`
+	// TCPKeepalive detects broken connections of the brokers. The RPC
+	// server enables keepalive on its connections, the probes are
+	// configured with sysctls of the network namespace of the Pod. The
+	// net.ipv4.tcp_keepalive sysctls are safe since Kubernetes 1.29, older
+	// kubelets have to allow them as unsafe sysctls.
+	// +optional
+	TCPKeepalive *TCPKeepalive `json:"tcpKeepalive,omitempty"`
+}
+
+// TCPKeepalive configures the keepalive probes of the connections of a
+// broker Pod
+type TCPKeepalive struct {
+	// Idle time before the first probe (net.ipv4.tcp_keepalive_time)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32767
+	// +optional
+	TimeSeconds *int32 `json:"timeSeconds,omitempty"`
+	// Time between probes (net.ipv4.tcp_keepalive_intvl)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32767
+	// +optional
+	IntervalSeconds *int32 `json:"intervalSeconds,omitempty"`
+	// Number of unanswered probes after which the connection is closed
+	// (net.ipv4.tcp_keepalive_probes)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=127
+	// +optional
+	Probes *int32 `json:"probes,omitempty"`
+}
+
+// CompressionType is a compression codec supported by Redpanda
+// +kubebuilder:validation:Enum=none;gzip;snappy;lz4;zstd;producer
+type CompressionType string
+
+const (
+	// CompressionNone stores batches uncompressed
+	CompressionNone CompressionType = "none"
+	// CompressionGzip compresses batches with gzip
+	CompressionGzip CompressionType = "gzip"
+	// CompressionSnappy compresses batches with snappy
+	CompressionSnappy CompressionType = "snappy"
+	// CompressionLZ4 compresses batches with lz4
+	CompressionLZ4 CompressionType = "lz4"
+	// CompressionZstd compresses batches with zstd
+	CompressionZstd CompressionType = "zstd"
+	// CompressionProducer keeps the compression chosen by the producer
+	CompressionProducer CompressionType = "producer"
+)
+
+// Compression configures the compression defaults of the cluster. Changing
+// them restarts the brokers, topics that set compression.type keep their own
+// codec.
+type Compression struct {
+	// TopicDefault is the compression of topics that do not set one
+	// (log_compression_type). Redpanda keeps the compression of the
+	// producers by default.
+	// +optional
+	TopicDefault CompressionType `json:"topicDefault,omitempty"`
+}
+
+// KafkaClientLimits configures limits enforced on Kafka API clients. They are
+// cluster properties, changing them restarts the brokers.
+type KafkaClientLimits struct {
+	// Default throughput quota in bytes per second of a single client on a
+	// shard (target_quota_byte_rate). Clients above it are throttled.
+	// +kubebuilder:validation:Minimum=0
+	TargetQuotaByteRate int `json:"targetQuotaByteRate,omitempty"`
+}
+
+// PasswordRotation configures the rotation of the bootstrap superuser
+// password. The operator stores the new password in the superuser Secret
+// under the pending-password key before it changes the user through the
+// Admin API, then moves it to the password key, so an interrupted rotation
+// is completed on the next reconcile. Redpanda keeps one password per SCRAM
+// user, there is no window in which both passwords authenticate. Clients
+// have to read the Secret again when they fail to authenticate, Console is
+// restarted.
+type PasswordRotation struct {
+	// Interval between two rotations, counted from the creation of the user
+	// or the last rotation
+	Interval metav1.Duration `json:"interval"`
 }
 
 // TLSConfig configures TLS for Redpanda APIs
@@ -184,6 +2400,9 @@ type TLSConfig struct {
 	KafkaAPI KafkaAPITLS `json:"kafkaApi,omitempty"`
 	// Configuration of TLS for Admin API
 	AdminAPI AdminAPITLS `json:"adminApi,omitempty"`
+	// Configuration of TLS for the internal RPC between the brokers
+	// +optional
+	RPC RPCTLS `json:"rpc,omitempty"`
 }
 
 // KafkaAPITLS configures TLS for redpanda Kafka API
@@ -246,6 +2465,283 @@ type AdminAPITLS struct {
 	RequireClientAuth bool `json:"requireClientAuth,omitempty"`
 }
 
+// RPCTLS configures TLS for the internal RPC of Redpanda, used by the brokers
+// to replicate data and to join the cluster through the seed servers
+//
+// If Enabled is set to true, a node certificate valid for the per Pod
+// addresses of the headless Service is issued and every broker verifies the
+// certificate of its peers, on both ends of the connection, against its CA
+// certificate. The certificate is used by the RPC server and by the client
+// connecting to other brokers, so it authenticates the brokers to each
+// other.
+//
+// Enabling or disabling RPC TLS restarts every broker at the same time,
+// brokers with different settings can not talk to each other.
+type RPCTLS struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// References cert-manager Issuer or ClusterIssuer. When provided, this
+	// issuer will be used to issue the RPC node certificate instead of a
+	// generated self-signed one.
+	// +optional
+	IssuerRef *cmmeta.ObjectReference `json:"issuerRef,omitempty"`
+	// If provided, operator uses certificate in this secret instead of
+	// issuing its own RPC node certificate. The secret has to be in the
+	// namespace of the cluster and provide the keys 'ca.crt', 'tls.key' and
+	// 'tls.crt'. The certificate has to be valid for
+	// *.<cluster>.<namespace>.svc.cluster.local.
+	// +optional
+	NodeSecretRef *corev1.ObjectReference `json:"nodeSecretRef,omitempty"`
+}
+
+// KafkaAPI configures the Kafka API listener
+type KafkaAPI struct {
+	Port int `json:"port,omitempty"`
+	// AuthenticationMethod is the way Kafka clients authenticate. When it is
+	// not set, it is derived from EnableSASL and the Kafka API TLS client
+	// authentication.
+	// +optional
+	AuthenticationMethod KafkaAuthenticationMethod `json:"authenticationMethod,omitempty"`
+	// InternalListenerName is the name of the Kafka listener that brokers
+	// advertise under their headless Service address. Defaults to Internal.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z][A-Za-z0-9_-]*$`
+	// +optional
+	InternalListenerName string `json:"internalListenerName,omitempty"`
+	// ExternalListenerName is the name of the Kafka listener that brokers
+	// advertise under their external address when external connectivity is
+	// enabled. Defaults to External.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z][A-Za-z0-9_-]*$`
+	// +optional
+	ExternalListenerName string `json:"externalListenerName,omitempty"`
+	// ExternalListener gives the external listener TLS settings of its own,
+	// for clients that do not share the trust of the internal ones. When it
+	// is set, TLS.KafkaAPI only applies to the internal listener.
+	// +optional
+	ExternalListener *KafkaExternalListener `json:"externalListener,omitempty"`
+}
+
+// KafkaExternalListener configures the external Kafka API listener
+// independently of the internal one
+type KafkaExternalListener struct {
+	// AuthenticationMethod is the way the external clients authenticate.
+	// Defaults to mtls when TLS requires client authentication, otherwise
+	// to none. Redpanda applies one authentication method to all Kafka API
+	// listeners, so the webhook rejects a method that differs from the one
+	// of the internal listener.
+	// +optional
+	AuthenticationMethod KafkaAuthenticationMethod `json:"authenticationMethod,omitempty"`
+	// +optional
+	TLS KafkaExternalListenerTLS `json:"tls,omitempty"`
+}
+
+// KafkaExternalListenerTLS configures TLS for the external Kafka API listener
+//
+// If Enabled is set to true, a node certificate for the external subdomains
+// is issued by a self-signed CA of its own, or by IssuerRef, and stored in
+// the Secret named '<redpanda-cluster-name>-kafka-external-node'. 'ca.crt'
+// must be used by a client as a truststore.
+//
+// If RequireClientAuth is set to true, the listener verifies the client
+// certificates against the CA of the node certificate, or against the
+// 'ca.crt' of TruststoreSecretRef. Without a truststore a client certificate
+// is generated, which can be retrieved from the Secret named
+// '<redpanda-cluster-name>-kafka-external-client'.
+type KafkaExternalListenerTLS struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// References cert-manager Issuer or ClusterIssuer. When provided, this
+	// issuer will be used to issue the node certificate instead of a
+	// generated self-signed one.
+	// +optional
+	IssuerRef         *cmmeta.ObjectReference `json:"issuerRef,omitempty"`
+	RequireClientAuth bool                    `json:"requireClientAuth,omitempty"`
+	// TruststoreSecretRef references a Secret in the namespace of the
+	// cluster whose 'ca.crt' holds the CAs the client certificates are
+	// verified against, e.g. the ones of partner organizations.
+	// +optional
+	TruststoreSecretRef *corev1.LocalObjectReference `json:"truststoreSecretRef,omitempty"`
+}
+
+const (
+	// DefaultInternalListenerName is the name of the internal Kafka listener
+	DefaultInternalListenerName = "Internal"
+	// DefaultExternalListenerName is the name of the external Kafka listener
+	DefaultExternalListenerName = "External"
+)
+
+// InternalListenerName returns the name of the internal Kafka listener
+func (r *Cluster) InternalListenerName() string {
+	if name := r.Spec.Configuration.KafkaAPI.InternalListenerName; name != "" {
+		return name
+	}
+	return DefaultInternalListenerName
+}
+
+// ExternalListenerName returns the name of the external Kafka listener
+func (r *Cluster) ExternalListenerName() string {
+	if name := r.Spec.Configuration.KafkaAPI.ExternalListenerName; name != "" {
+		return name
+	}
+	return DefaultExternalListenerName
+}
+
+// PandaproxyAPI configures the Pandaproxy listener. Pandaproxy reaches the
+// brokers through the internal Kafka API listener. It runs inside the
+// redpanda process from the pandaproxy section of redpanda.yaml, there is no
+// binary to run it in a container of its own, so it shares the probes,
+// resources and restarts of the broker.
+type PandaproxyAPI struct {
+	// Port of the internal listener
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65534
+	Port int `json:"port"`
+	// External exposes the listener outside of the Kubernetes cluster, on a
+	// node port of its own next to the Kafka API. Inside the container
+	// Port + 1 is used as the external listener.
+	// +optional
+	External ExternalListener `json:"external,omitempty"`
+	// TLS of the external listener, or of the internal one when the
+	// listener is not exposed
+	// +optional
+	TLS PandaproxyAPITLS `json:"tls,omitempty"`
+}
+
+// ExternalListener exposes a listener outside of the Kubernetes cluster when
+// external connectivity is enabled
+type ExternalListener struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// Subdomain under which each broker advertises the listener as
+	// HOSTNAME_OF_A_POD.SUBDOMAIN:NODE_PORT. Defaults to the subdomain of the
+	// external connectivity, when both are empty the brokers advertise the
+	// external IP of their node. If TLS is enabled then this subdomain will
+	// be requested as a subject alternative name.
+	// +optional
+	Subdomain string `json:"subdomain,omitempty"`
+}
+
+// PandaproxyAPITLS configures TLS for the Pandaproxy listener
+//
+// If Enabled is set to true, a node certificate is issued by a self-signed
+// CA, or by IssuerRef, and stored in the Secret named
+// '<redpanda-cluster-name>-proxy-api-node'. 'ca.crt' must be used by a
+// client as a truststore.
+//
+// If RequireClientAuth is set to true, two-way TLS verification is enabled.
+// In that case, a client certificate is generated, which can be retrieved
+// from the Secret named '<redpanda-cluster-name>-proxy-api-client'.
+type PandaproxyAPITLS struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// References cert-manager Issuer or ClusterIssuer. When provided, this
+	// issuer will be used to issue the node certificate instead of a
+	// generated self-signed one.
+	// +optional
+	IssuerRef         *cmmeta.ObjectReference `json:"issuerRef,omitempty"`
+	RequireClientAuth bool                    `json:"requireClientAuth,omitempty"`
+}
+
+const (
+	// PandaproxyInternalListenerName is the name of the internal Pandaproxy
+	// listener
+	PandaproxyInternalListenerName = "proxy"
+	// PandaproxyExternalListenerName is the name of the external Pandaproxy
+	// listener
+	PandaproxyExternalListenerName = "proxy-external"
+)
+
+// PandaproxyExternal returns true when the Pandaproxy listener is exposed
+// outside of the Kubernetes cluster
+func (r *Cluster) PandaproxyExternal() bool {
+	proxy := r.Spec.Configuration.PandaproxyAPI
+	return proxy != nil && proxy.External.Enabled && r.Spec.ExternalConnectivity.Enabled
+}
+
+// PandaproxyExternalSubdomain returns the subdomain under which brokers
+// advertise the external Pandaproxy listener
+func (r *Cluster) PandaproxyExternalSubdomain() string {
+	if !r.PandaproxyExternal() {
+		return ""
+	}
+	if subdomain := r.Spec.Configuration.PandaproxyAPI.External.Subdomain; subdomain != "" {
+		return r.scopeSubdomain(subdomain)
+	}
+	return r.ExternalSubdomain()
+}
+
+// KafkaAuthenticationMethod defines how Kafka clients authenticate
+// +kubebuilder:validation:Enum=none;sasl;mtls
+type KafkaAuthenticationMethod string
+
+const (
+	// KafkaAuthenticationNone lets clients connect without authentication
+	KafkaAuthenticationNone KafkaAuthenticationMethod = "none"
+	// KafkaAuthenticationSASL requires clients to authenticate with SASL
+	KafkaAuthenticationSASL KafkaAuthenticationMethod = "sasl"
+	// KafkaAuthenticationMTLS requires clients to present a TLS certificate
+	// signed by the cluster CA
+	KafkaAuthenticationMTLS KafkaAuthenticationMethod = "mtls"
+)
+
+// KafkaAuthenticationMethod returns the configured Kafka API authentication
+// method or the one implied by EnableSASL and the TLS settings
+func (r *Cluster) KafkaAuthenticationMethod() KafkaAuthenticationMethod {
+	if method := r.Spec.Configuration.KafkaAPI.AuthenticationMethod; method != "" {
+		return method
+	}
+	switch {
+	case r.Spec.EnableSASL:
+		return KafkaAuthenticationSASL
+	case r.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth:
+		return KafkaAuthenticationMTLS
+	default:
+		return KafkaAuthenticationNone
+	}
+}
+
+// KafkaExternalListener returns the independent settings of the external
+// Kafka API listener, nil when the listener shares the Kafka API settings
+func (r *Cluster) KafkaExternalListener() *KafkaExternalListener {
+	if !r.Spec.ExternalConnectivity.Enabled {
+		return nil
+	}
+	return r.Spec.Configuration.KafkaAPI.ExternalListener
+}
+
+// InternalKafkaTLS returns true when the Kafka API TLS settings apply to the
+// internal listener. Without independent external settings they apply to
+// the external listener when it exists.
+func (r *Cluster) InternalKafkaTLS() bool {
+	if !r.Spec.Configuration.TLS.KafkaAPI.Enabled {
+		return false
+	}
+	return !r.Spec.ExternalConnectivity.Enabled || r.KafkaExternalListener() != nil
+}
+
+// ExternalKafkaTLS returns true when the external Kafka API listener uses
+// TLS
+func (r *Cluster) ExternalKafkaTLS() bool {
+	if !r.Spec.ExternalConnectivity.Enabled {
+		return false
+	}
+	if listener := r.KafkaExternalListener(); listener != nil {
+		return listener.TLS.Enabled
+	}
+	return r.Spec.Configuration.TLS.KafkaAPI.Enabled
+}
+
+// ExternalKafkaAuthenticationMethod returns the authentication method of the
+// external Kafka API listener
+func (r *Cluster) ExternalKafkaAuthenticationMethod() KafkaAuthenticationMethod {
+	listener := r.KafkaExternalListener()
+	switch {
+	case listener == nil:
+		return r.KafkaAuthenticationMethod()
+	case listener.AuthenticationMethod != "":
+		return listener.AuthenticationMethod
+	case listener.TLS.RequireClientAuth:
+		return KafkaAuthenticationMTLS
+	default:
+		return KafkaAuthenticationNone
+	}
+}
+
 // SocketAddress provide the way to configure the port
 type SocketAddress struct {
 	Port int `json:"port,omitempty"`
@@ -255,7 +2751,566 @@ func init() {
 	SchemeBuilder.Register(&Cluster{}, &ClusterList{})
 }
 
+// AllSubdomains returns the advertised subdomain followed by the additional
+// ones without duplicates
+func (e ExternalConnectivityConfig) AllSubdomains() []string {
+	var subdomains []string
+	seen := map[string]bool{}
+	for _, s := range append([]string{e.Subdomain}, e.Subdomains...) {
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		subdomains = append(subdomains, s)
+	}
+	return subdomains
+}
+
+// ExternalSubdomain returns the subdomain under which brokers advertise the
+// external Kafka API
+func (r *Cluster) ExternalSubdomain() string {
+	return r.scopeSubdomain(r.Spec.ExternalConnectivity.Subdomain)
+}
+
+// ExternalSubdomains returns the advertised subdomain followed by the
+// additional ones, all of them scoped to the cluster when
+// ClusterScopedRecords is enabled
+func (r *Cluster) ExternalSubdomains() []string {
+	subdomains := r.Spec.ExternalConnectivity.AllSubdomains()
+	for i := range subdomains {
+		subdomains[i] = r.scopeSubdomain(subdomains[i])
+	}
+	return subdomains
+}
+
+// ExternalDNSHostnames returns the subdomains of all external listeners,
+// external-dns creates the per broker records for each of them
+func (r *Cluster) ExternalDNSHostnames() []string {
+	hostnames := r.ExternalSubdomains()
+	if proxy := r.PandaproxyExternalSubdomain(); proxy != "" && !contains(hostnames, proxy) {
+		hostnames = append(hostnames, proxy)
+	}
+	return hostnames
+}
+
+func (r *Cluster) scopeSubdomain(subdomain string) string {
+	if subdomain == "" || !r.Spec.ExternalConnectivity.ClusterScopedRecords {
+		return subdomain
+	}
+	return fmt.Sprintf("%s.%s.%s", r.Name, r.Namespace, subdomain)
+}
+
+// SuperuserSecretName returns the name of the Secret with the credentials of
+// the bootstrap superuser
+func (r *Cluster) SuperuserSecretName() string {
+	return r.Name + "-superuser"
+}
+
+// BootstrapSuperuserRequired returns true when the operator creates the
+// bootstrap superuser for SASL clients
+func (r *Cluster) BootstrapSuperuserRequired() bool {
+	return r.KafkaAuthenticationMethod() == KafkaAuthenticationSASL ||
+		r.ExternalKafkaAuthenticationMethod() == KafkaAuthenticationSASL
+}
+
+// NextSuperuserPasswordRotation returns when the password of the bootstrap
+// superuser is rotated next, false when it is not rotated or the user was
+// not created yet
+func (r *Cluster) NextSuperuserPasswordRotation() (time.Time, bool) {
+	rotation := r.Spec.Configuration.SuperuserPasswordRotation
+	if rotation == nil || r.Status.SuperuserPasswordRotated == nil {
+		return time.Time{}, false
+	}
+	return r.Status.SuperuserPasswordRotated.Add(rotation.Interval.Duration), true
+}
+
+// MembershipReadinessGated returns true when the readiness of the brokers
+// waits for their cluster membership
+func (r *Cluster) MembershipReadinessGated() bool {
+	return r.Spec.ExternalConnectivity.Enabled && r.Spec.ExternalConnectivity.MembershipReadiness
+}
+
+// PreStopHook returns the custom preStop hook or nil
+func (r *Cluster) PreStopHook() *PreStopHook {
+	if r.Spec.Lifecycle == nil {
+		return nil
+	}
+	return r.Spec.Lifecycle.PreStop
+}
+
+// BrokerShutdown returns how long Redpanda may take to stop or nil
+func (r *Cluster) BrokerShutdown() *BrokerShutdown {
+	if r.Spec.Lifecycle == nil {
+		return nil
+	}
+	return r.Spec.Lifecycle.Shutdown
+}
+
+// DefaultBrokerFailureGracePeriod is how long a broker may stay down before
+// it is reported as failed when the policy does not set it
+const DefaultBrokerFailureGracePeriod = 10 * time.Minute
+
+// BrokerFailureGracePeriod returns how long a broker may stay down before it
+// is reported as failed
+func (r *Cluster) BrokerFailureGracePeriod() time.Duration {
+	if r.Spec.BrokerFailure == nil || r.Spec.BrokerFailure.GracePeriod == nil {
+		return DefaultBrokerFailureGracePeriod
+	}
+	return r.Spec.BrokerFailure.GracePeriod.Duration
+}
+
+// FailedBrokerDecommission returns true when failed brokers are
+// decommissioned by the operator
+func (r *Cluster) FailedBrokerDecommission() bool {
+	return r.Spec.BrokerFailure != nil && r.Spec.BrokerFailure.Decommission
+}
+
+// DefaultDecommissionDrainTimeout is the shortest time between the start of
+// the decommissioning of a broker and the removal of its Pod
+const DefaultDecommissionDrainTimeout = time.Minute
+
+// DecommissionDrainTimeout returns the shortest time between the start of
+// the decommissioning of a broker and the removal of its Pod
+func (r *Cluster) DecommissionDrainTimeout() time.Duration {
+	if r.Spec.DecommissionDrain == nil || r.Spec.DecommissionDrain.Timeout == nil {
+		return DefaultDecommissionDrainTimeout
+	}
+	return r.Spec.DecommissionDrain.Timeout.Duration
+}
+
+// BrokerDrained returns true when the decommissioned broker was drained, so
+// its Pod can be removed
+func (r *Cluster) BrokerDrained(nodeID int) bool {
+	for _, b := range r.Status.DrainingBrokers {
+		if b.NodeID == nodeID {
+			return b.Phase == DrainPhaseDrained
+		}
+	}
+	return false
+}
+
+const (
+	// DefaultCrashLoopRestartThreshold is the number of container restarts
+	// after which a crash looping broker is recovered
+	DefaultCrashLoopRestartThreshold = 5
+	// DefaultCrashLoopRecoveryInterval is the shortest time between two
+	// crash loop recoveries
+	DefaultCrashLoopRecoveryInterval = 30 * time.Minute
+	// MinCrashLoopRecoveryInterval is the shortest accepted interval between
+	// two crash loop recoveries
+	MinCrashLoopRecoveryInterval = 5 * time.Minute
+)
+
+// CrashLoopRestartThreshold returns the number of container restarts after
+// which a crash looping broker is recovered
+func (r *Cluster) CrashLoopRestartThreshold() int32 {
+	if r.Spec.CrashLoopRecovery == nil || r.Spec.CrashLoopRecovery.RestartThreshold == nil {
+		return DefaultCrashLoopRestartThreshold
+	}
+	return *r.Spec.CrashLoopRecovery.RestartThreshold
+}
+
+// CrashLoopRecoveryInterval returns the shortest time between two crash loop
+// recoveries
+func (r *Cluster) CrashLoopRecoveryInterval() time.Duration {
+	if r.Spec.CrashLoopRecovery == nil || r.Spec.CrashLoopRecovery.MinInterval == nil {
+		return DefaultCrashLoopRecoveryInterval
+	}
+	return r.Spec.CrashLoopRecovery.MinInterval.Duration
+}
+
+const (
+	// DefaultBackupInterval is the recovery point objective when the backup
+	// does not set it
+	DefaultBackupInterval = time.Hour
+	// DefaultRolloutProgressDeadline is how long a restarted broker has to
+	// become ready when the rollback does not set it
+	DefaultRolloutProgressDeadline = 10 * time.Minute
+	// DefaultImagePrePullTimeout is how long an upgrade waits for the image
+	// to be pulled when the pre-pull does not set it
+	DefaultImagePrePullTimeout = 10 * time.Minute
+	// DefaultConsumerGroupStabilization is how long the consumer groups get
+	// to rebalance after a restart when the coordination does not set it
+	DefaultConsumerGroupStabilization = 30 * time.Second
+	// MinBackupInterval is the shortest accepted backup interval, every
+	// interval reads the configuration and health of the cluster
+	MinBackupInterval = time.Minute
+)
+
+const (
+	// DefaultRecommendationWindow is how long the brokers are sampled when
+	// the resource recommendation does not set it
+	DefaultRecommendationWindow = 24 * time.Hour
+	// MinRecommendationWindow is the shortest accepted window, it spans a
+	// few samples
+	MinRecommendationWindow = time.Hour
+	// DefaultRecommendationHeadroomPercent is added to the peak usage when
+	// the resource recommendation does not set it
+	DefaultRecommendationHeadroomPercent = 30
+)
+
+// RecommendationWindow returns how long the brokers are sampled for a
+// resource recommendation
+func (r *Cluster) RecommendationWindow() time.Duration {
+	if r.Spec.ResourceRecommendation == nil || r.Spec.ResourceRecommendation.Window == nil {
+		return DefaultRecommendationWindow
+	}
+	return r.Spec.ResourceRecommendation.Window.Duration
+}
+
+// RecommendationHeadroomPercent returns the headroom added to the peak usage
+// of the brokers
+func (r *Cluster) RecommendationHeadroomPercent() int32 {
+	if r.Spec.ResourceRecommendation == nil || r.Spec.ResourceRecommendation.HeadroomPercent == nil {
+		return DefaultRecommendationHeadroomPercent
+	}
+	return *r.Spec.ResourceRecommendation.HeadroomPercent
+}
+
+// WarmingTopics returns true while a topic of the topic warmup is not warm
+// yet
+func (r *Cluster) WarmingTopics() bool {
+	if r.Spec.TopicWarmup == nil {
+		return false
+	}
+	warm := make(map[string]bool, len(r.Status.WarmTopics))
+	for _, t := range r.Status.WarmTopics {
+		warm[t] = true
+	}
+	for _, t := range r.Spec.TopicWarmup.Topics {
+		if !warm[t.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// AdvertisedAddressesFromSource returns true when the brokers advertise the
+// external addresses of a ConfigMap or Secret
+func (r *Cluster) AdvertisedAddressesFromSource() bool {
+	extConn := r.Spec.ExternalConnectivity
+	return extConn.Enabled && extConn.AdvertisedAddressesFrom != nil
+}
+
+// RestartsByLeadership returns true when the operator restarts the brokers
+// leading the fewest partitions first
+func (r *Cluster) RestartsByLeadership() bool {
+	return r.Spec.RestartOrder == RestartOrderFewestLeadershipsFirst
+}
+
+// ConsumerGroupStabilization returns how long the consumer groups get to
+// rebalance after a restart
+func (r *Cluster) ConsumerGroupStabilization() time.Duration {
+	if r.Spec.ConsumerGroupCoordination == nil || r.Spec.ConsumerGroupCoordination.StabilizationSeconds == nil {
+		return DefaultConsumerGroupStabilization
+	}
+	return time.Duration(*r.Spec.ConsumerGroupCoordination.StabilizationSeconds) * time.Second
+}
+
+// RolloutProgressDeadline returns how long a restarted broker has to become
+// ready before the rolling update is rolled back
+func (r *Cluster) RolloutProgressDeadline() time.Duration {
+	if r.Spec.RolloutRollback == nil || r.Spec.RolloutRollback.ProgressDeadlineSeconds == nil {
+		return DefaultRolloutProgressDeadline
+	}
+	return time.Duration(*r.Spec.RolloutRollback.ProgressDeadlineSeconds) * time.Second
+}
+
+// ImagePrePullTimeout returns how long an upgrade waits for the nodes of
+// the brokers to pull the new image
+func (r *Cluster) ImagePrePullTimeout() time.Duration {
+	if r.Spec.ImagePrePull == nil || r.Spec.ImagePrePull.TimeoutSeconds == nil {
+		return DefaultImagePrePullTimeout
+	}
+	return time.Duration(*r.Spec.ImagePrePull.TimeoutSeconds) * time.Second
+}
+
+// BackupInterval returns the recovery point objective of the backup
+func (r *Cluster) BackupInterval() time.Duration {
+	if r.Spec.Backup == nil || r.Spec.Backup.Interval == nil {
+		return DefaultBackupInterval
+	}
+	return r.Spec.Backup.Interval.Duration
+}
+
+// DefaultRackNodeLabel is the node label holding the rack of a broker when
+// the rack awareness does not set one
+const DefaultRackNodeLabel = "topology.kubernetes.io/zone"
+
+// RackAwarenessEnabled returns true when the brokers are assigned to racks
+func (r *Cluster) RackAwarenessEnabled() bool {
+	return r.Spec.Configuration.RackAwareness != nil && r.Spec.Configuration.RackAwareness.Enabled
+}
+
+// RackChangePolicy returns how brokers with a stale rack are handled
+func (r *Cluster) RackChangePolicy() RackChangePolicy {
+	if r.Spec.Configuration.RackAwareness == nil || r.Spec.Configuration.RackAwareness.OnRackChange == "" {
+		return RackChangeReport
+	}
+	return r.Spec.Configuration.RackAwareness.OnRackChange
+}
+
+// UnknownPropertyPolicy returns how cluster properties unknown to the
+// running version are handled
+func (r *Cluster) UnknownPropertyPolicy() UnknownPropertyPolicy {
+	validation := r.Spec.Configuration.SchemaValidation
+	if validation == nil || validation.OnUnknownProperty == "" {
+		return UnknownPropertySkip
+	}
+	return validation.OnUnknownProperty
+}
+
+// RackNodeLabel returns the node label holding the rack of a broker
+func (r *Cluster) RackNodeLabel() string {
+	if r.Spec.Configuration.RackAwareness == nil || r.Spec.Configuration.RackAwareness.NodeLabel == "" {
+		return DefaultRackNodeLabel
+	}
+	return r.Spec.Configuration.RackAwareness.NodeLabel
+}
+
+// ConfiguratorReadsNode returns true when the configurator reads the node of
+// its broker, for the external address or the rack, so it needs a service
+// account allowed to get nodes
+func (r *Cluster) ConfiguratorReadsNode() bool {
+	return r.Spec.ExternalConnectivity.Enabled || r.RackAwarenessEnabled()
+}
+
 // FullImageName returns image name including version
 func (r *Cluster) FullImageName() string {
 	return fmt.Sprintf("%s:%s", r.Spec.Image, r.Spec.Version)
 }
+
+// RedpandaVersion is a Redpanda release parsed from a container tag
+type RedpandaVersion struct {
+	Major, Minor, Patch int
+	// PreRelease is the alpha, beta or rc suffix of a pre-release
+	PreRelease string
+}
+
+// redpandaVersionPattern matches tags like v21.4.1, 21.4.1-beta2 or
+// v21.4.1-rc1-amd64
+var redpandaVersionPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(?:-((?:alpha|beta|rc)\d*))?(?:[-.+][0-9A-Za-z.-]*)?$`)
+
+// ParseRedpandaVersion parses the version of a container tag. Tags that
+// name no release, e.g. latest or dev, return false.
+func ParseRedpandaVersion(tag string) (RedpandaVersion, bool) {
+	m := redpandaVersionPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return RedpandaVersion{}, false
+	}
+	var v RedpandaVersion
+	var err error
+	for i, n := range []*int{&v.Major, &v.Minor, &v.Patch} {
+		if *n, err = strconv.Atoi(m[i+1]); err != nil {
+			return RedpandaVersion{}, false
+		}
+	}
+	v.PreRelease = m[4]
+	return v, true
+}
+
+// Less returns true when v is an earlier release than o. A pre-release is
+// earlier than the release.
+func (v RedpandaVersion) Less(o RedpandaVersion) bool {
+	switch {
+	case v.Major != o.Major:
+		return v.Major < o.Major
+	case v.Minor != o.Minor:
+		return v.Minor < o.Minor
+	case v.Patch != o.Patch:
+		return v.Patch < o.Patch
+	case v.PreRelease == o.PreRelease:
+		return false
+	case v.PreRelease == "":
+		return false
+	case o.PreRelease == "":
+		return true
+	}
+	return v.PreRelease < o.PreRelease
+}
+
+// RedpandaMemory returns the memory in bytes passed to Redpanda with
+// --memory. It returns false when the memory reservation is not configured
+// and Redpanda reserves ReserveMemoryString by itself.
+func (r *Cluster) RedpandaMemory() (int64, bool) {
+	reservation := r.Spec.MemoryReservation
+	if reservation == nil {
+		return 0, false
+	}
+	limit := r.Spec.Resources.Limits.Memory().Value()
+	reserved := limit / 100 * int64(reservation.Percent)
+	if reservation.Reserved != nil {
+		reserved = reservation.Reserved.Value()
+	}
+	return limit - reserved, true
+}
+
+// ReplicationSourceKey returns the namespace and name of the source Cluster
+// of the replication
+func (r *Cluster) ReplicationSourceKey() types.NamespacedName {
+	source := r.Spec.Replication.Source
+	if source.Namespace == "" {
+		return types.NamespacedName{Name: source.Name, Namespace: r.Namespace}
+	}
+	return types.NamespacedName{Name: source.Name, Namespace: source.Namespace}
+}
+
+// ReplicationTopicsExclude returns the topics that are not replicated
+func (r *Cluster) ReplicationTopicsExclude() string {
+	if r.Spec.Replication.TopicsExclude == "" {
+		return DefaultReplicationTopicsExclude
+	}
+	return r.Spec.Replication.TopicsExclude
+}
+
+// ReplicatesTopic returns true when the replication replicates the topic of
+// the source Cluster. Invalid patterns do not match.
+func (r *Cluster) ReplicatesTopic(topic string) bool {
+	topics := r.Spec.Replication.Topics
+	if topics == "" {
+		topics = ".*"
+	}
+	include, err := compileTopicPatterns(topics)
+	if err != nil {
+		return false
+	}
+	exclude, err := compileTopicPatterns(r.ReplicationTopicsExclude())
+	if err != nil {
+		return false
+	}
+	for _, re := range exclude {
+		if re.MatchString(topic) {
+			return false
+		}
+	}
+	for _, re := range include {
+		if re.MatchString(topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// PinnedCPUs returns the number of CPUs each broker is pinned to. It returns
+// false when CPU pinning is not enabled.
+func (r *Cluster) PinnedCPUs() (int64, bool) {
+	if r.Spec.CPUPinning == nil || !r.Spec.CPUPinning.Enabled {
+		return 0, false
+	}
+	return r.Spec.Resources.Limits.Cpu().Value(), true
+}
+
+// DefaultContainerName is the name of the Redpanda container when the
+// Cluster does not override it
+const DefaultContainerName = "redpanda"
+
+// RedpandaContainerName returns the name of the Redpanda container in the
+// broker Pods
+func (r *Cluster) RedpandaContainerName() string {
+	if r.Spec.ContainerName == "" {
+		return DefaultContainerName
+	}
+	return r.Spec.ContainerName
+}
+
+const (
+	// SkipOwnerReferencesAnnotation set to "true" on a Cluster stops the
+	// operator from making the Cluster the owner of the objects it
+	// generates, e.g. when a GitOps tool prunes them itself. The objects
+	// are deleted through the CleanupFinalizer and their labels instead of
+	// the garbage collector, and changes to them are only picked up on the
+	// next resync.
+	SkipOwnerReferencesAnnotation = "redpanda.vectorized.io/skip-owner-references"
+	// CleanupFinalizer deletes the generated objects of a Cluster without
+	// owner references when the Cluster is deleted
+	CleanupFinalizer = "redpanda.vectorized.io/cleanup"
+)
+
+// OwnerReferencesDisabled returns true when the generated objects of the
+// Cluster do not get an owner reference
+func (r *Cluster) OwnerReferencesDisabled() bool {
+	return r.Annotations[SkipOwnerReferencesAnnotation] == "true"
+}
+
+const (
+	// RequeuePeriodAnnotation overrides for one Cluster how long the operator
+	// waits before it checks again on a resource that is not ready, e.g. "30s"
+	RequeuePeriodAnnotation = "redpanda.vectorized.io/requeue-period"
+	// MinRequeuePeriod is the shortest accepted requeue period
+	MinRequeuePeriod = time.Second
+	// MinResyncPeriod is the shortest accepted period of the periodic
+	// reconcile
+	MinResyncPeriod = 30 * time.Second
+)
+
+const (
+	// RestartAnnotation requests a restart of brokers. On the Cluster the
+	// value is the ordinal of a broker or RestartAllBrokers, on the Pod of a
+	// broker any value restarts that broker. The operator removes the
+	// annotation once it queued the request and restarts the brokers one at
+	// a time while the cluster is healthy, each in maintenance mode.
+	RestartAnnotation = "redpanda.vectorized.io/restart"
+	// RestartAllBrokers as the value of the RestartAnnotation of the Cluster
+	// restarts every broker
+	RestartAllBrokers = "all"
+)
+
+// RequestedRestarts returns the ordinals of the brokers whose restart the
+// RestartAnnotation of the Cluster requests
+func (r *Cluster) RequestedRestarts() ([]int32, error) {
+	value, ok := r.Annotations[RestartAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var replicas int32
+	if r.Spec.Replicas != nil {
+		replicas = *r.Spec.Replicas
+	}
+	if value == RestartAllBrokers {
+		ordinals := make([]int32, 0, replicas)
+		for i := int32(0); i < replicas; i++ {
+			ordinals = append(ordinals, i)
+		}
+		return ordinals, nil
+	}
+	ordinal, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || ordinal < 0 || int32(ordinal) >= replicas {
+		return nil, fmt.Errorf("%q is neither %q nor the ordinal of one of the %d brokers", value, RestartAllBrokers, replicas)
+	}
+	return []int32{int32(ordinal)}, nil
+}
+
+const (
+	// DebugBundleAnnotation requests a debug bundle of the cluster. Every new
+	// value, e.g. the ID of a support case, starts a bundle once the minimal
+	// interval of the debug bundle configuration passed.
+	DebugBundleAnnotation = "redpanda.vectorized.io/debug-bundle"
+	// DefaultDebugBundleMinInterval is the shortest time between two bundles
+	// when the configuration does not set it
+	DefaultDebugBundleMinInterval = time.Hour
+	// MinDebugBundleInterval is the shortest accepted interval between two
+	// bundles, collecting a bundle loads the brokers
+	MinDebugBundleInterval = 5 * time.Minute
+)
+
+// DebugBundleMinInterval returns the shortest time between two bundles
+func (r *Cluster) DebugBundleMinInterval() time.Duration {
+	if r.Spec.DebugBundle == nil || r.Spec.DebugBundle.MinInterval == nil {
+		return DefaultDebugBundleMinInterval
+	}
+	return r.Spec.DebugBundle.MinInterval.Duration
+}
+
+// RequeuePeriodOverride returns the requeue period of the
+// RequeuePeriodAnnotation or zero when it is not set or invalid
+func (r *Cluster) RequeuePeriodOverride() time.Duration {
+	value, ok := r.Annotations[RequeuePeriodAnnotation]
+	if !ok {
+		return 0
+	}
+	period, err := time.ParseDuration(value)
+	if err != nil || period < MinRequeuePeriod {
+		return 0
+	}
+	return period
+}
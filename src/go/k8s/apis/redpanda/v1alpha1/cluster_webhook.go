@@ -10,11 +10,27 @@
 package v1alpha1
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -23,13 +39,132 @@ const (
 	kb = 1024
 	mb = 1024 * kb
 	gb = 1024 * mb
+
+	// Redpanda defaults used when only one of the Raft timeouts is set
+	defaultRaftHeartbeatIntervalMs = 150
+	defaultRaftElectionTimeoutMs   = 1500
+
+	// maxRPCHeartbeatTimeoutMs keeps failed followers from going unnoticed
+	maxRPCHeartbeatTimeoutMs = 60000
+	// kernel limits of the TCP keepalive sysctls
+	maxTCPKeepaliveSeconds = 32767
+	maxTCPKeepaliveProbes  = 127
+
+	// maxPreStopHookTimeoutSeconds bounds the extension of the termination
+	// grace period by a custom preStop hook
+	maxPreStopHookTimeoutSeconds = 600
+	// defaultShutdownTimeoutSeconds is the time Redpanda has to stop when
+	// the shutdown does not set it
+	defaultShutdownTimeoutSeconds = 30
+
+	// annotations of external-dns set by the operator on the headless Service
+	externalDNSHostnameAnnotation = "external-dns.alpha.kubernetes.io/hostname"
+	externalDNSTTLAnnotation      = "external-dns.alpha.kubernetes.io/ttl"
+
+	// minBrokerFailureGracePeriod keeps the restart of a broker Pod from
+	// being reported as a failure
+	minBrokerFailureGracePeriod = time.Minute
+
+	// the default node port range of Kubernetes
+	minNodePort = 30000
+	maxNodePort = 32767
+
+	// managementServiceSuffix names the management Service of a cluster,
+	// keep in sync with the resources package
+	managementServiceSuffix = "-management"
+
+	// maxKafkaTopicNameLength is the longest topic name Kafka accepts
+	maxKafkaTopicNameLength = 249
+)
+
+var (
+	// managedFlags are the Redpanda flags set by the operator or derived from
+	// the container resources, keep in sync with the StatefulSet resource
+	managedFlags = map[string]bool{
+		"--check":              true,
+		"--smp":                true,
+		"--memory":             true,
+		"--reserve-memory":     true,
+		"--advertise-rpc-addr": true,
+		"--default-log-level":  true,
+	}
+	// managedInitContainers are the init containers of the operator, keep
+	// in sync with the StatefulSet resource
+	managedInitContainers = map[string]bool{
+		"redpanda-configurator":       true,
+		"redpanda-data-dir-ownership": true,
+		"redpanda-storage-check":      true,
+	}
+	// managedEnv are the environment variables set by the operator on the
+	// Redpanda container
+	managedEnv = map[string]bool{
+		"REDPANDA_ENVIRONMENT": true,
+		"POD_NAME":             true,
+		"POD_NAMESPACE":        true,
+		"POD_IP":               true,
+	}
+	// nodeLevelProperties are the redpanda.yaml properties that may differ
+	// between brokers of a cluster. Everything else has to be the same on
+	// every broker or is managed by the operator.
+	nodeLevelProperties = map[string]bool{
+		"cloud_storage_enabled":                    true,
+		"cloud_storage_max_connections":            true,
+		"cloud_storage_reconciliation_interval_ms": true,
+		"disable_batch_cache":                      true,
+		"disable_metrics":                          true,
+		"fetch_reads_debounce_timeout":             true,
+		"kvstore_flush_interval":                   true,
+		"kvstore_max_segment_size":                 true,
+		"reclaim_growth_window":                    true,
+		"reclaim_max_size":                         true,
+		"reclaim_min_size":                         true,
+		"reclaim_stable_window":                    true,
+		"release_cache_on_segment_roll":            true,
+		"segment_appender_flush_timeout_ms":        true,
+		"use_scheduling_groups":                    true,
+	}
+	// dangerousFlags can lose data and are meant for test clusters only
+	dangerousFlags = map[string]bool{
+		unsafeBypassFsyncFlag: true,
+	}
+	// kafkaTopicNamePattern matches the characters of a Kafka topic name
+	kafkaTopicNamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+	// sysctlKeyPattern matches the dotted name of a kernel parameter
+	sysctlKeyPattern = regexp.MustCompile(`^[a-z0-9_]+(\.[a-z0-9_-]+)+$`)
+	// sysctlValuePattern matches the values of kernel parameters, which are
+	// written by a shell script
+	sysctlValuePattern = regexp.MustCompile(`^[a-zA-Z0-9 ._:,-]+$`)
+	// blockDevicePattern matches the name of a block device under /sys/block
+	blockDevicePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+	// mountOptionPattern matches a single mount option, e.g. noatime or
+	// logbsize=256k
+	mountOptionPattern = regexp.MustCompile(`^[a-zA-Z0-9_.:/=-]+$`)
+)
+
+const (
+	// unsafeBypassFsyncFlag is set by the operator for relaxed durability
+	unsafeBypassFsyncFlag = "--unsafe-bypass-fsync"
+
+	// flags set by the operator from the reactor configuration
+	reactorBackendFlag   = "--reactor-backend"
+	ioPropertiesFlag     = "--io-properties"
+	ioPropertiesFileFlag = "--io-properties-file"
 )
 
 // log is for logging in this package.
 var log = logf.Log.WithName("cluster-resource")
 
+// clusterReader lists the existing clusters for validations that span
+// clusters. It is nil when the webhook is not set up.
+var clusterReader client.Reader
+
+// AllowEmptyDirStorage lets clusters keep their data directory on an
+// emptyDir. It is meant for disposable development and CI clusters.
+var AllowEmptyDirStorage bool
+
 // SetupWebhookWithManager autogenerated function by kubebuilder
 func (r *Cluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	clusterReader = mgr.GetClient()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
@@ -54,15 +189,7 @@ var _ webhook.Validator = &Cluster{}
 func (r *Cluster) ValidateCreate() error {
 	log.Info("validate create", "name", r.Name)
 
-	var allErrs field.ErrorList
-
-	allErrs = append(allErrs, r.checkCollidingPorts()...)
-
-	allErrs = append(allErrs, r.validateMemory()...)
-
-	allErrs = append(allErrs, r.validateTLS()...)
-
-	allErrs = append(allErrs, r.validateArchivalStorage()...)
+	allErrs := r.validateSpec()
 
 	if len(allErrs) == 0 {
 		return nil
@@ -79,20 +206,39 @@ func (r *Cluster) ValidateUpdate(old runtime.Object) error {
 	oldCluster := old.(*Cluster)
 	var allErrs field.ErrorList
 
-	if r.Spec.Replicas != nil && oldCluster.Spec.Replicas != nil && *r.Spec.Replicas < *oldCluster.Spec.Replicas {
+	if r.Spec.Replicas != nil && oldCluster.Spec.Replicas != nil && *r.Spec.Replicas < *oldCluster.Spec.Replicas &&
+		!r.decommissioned(oldCluster, *r.Spec.Replicas, *oldCluster.Spec.Replicas) {
 		allErrs = append(allErrs,
 			field.Invalid(field.NewPath("spec").Child("replicas"),
 				r.Spec.Replicas,
-				"scaling down is not supported"))
+				"scaling down is only supported for decommissioned brokers with decommission draining"))
 	}
 
-	allErrs = append(allErrs, r.checkCollidingPorts()...)
+	if r.RedpandaContainerName() != oldCluster.RedpandaContainerName() {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("containerName"),
+				r.Spec.ContainerName,
+				"container name cannot be changed"))
+	}
 
-	allErrs = append(allErrs, r.validateMemory()...)
+	if r.Spec.Storage.IsEmptyDir() != oldCluster.Spec.Storage.IsEmptyDir() {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("storage").Child("type"),
+				r.Spec.Storage.Type,
+				"storage type cannot be changed"))
+	}
 
-	allErrs = append(allErrs, r.validateTLS()...)
+	allErrs = append(allErrs, r.validateDowngrade(oldCluster)...)
 
-	allErrs = append(allErrs, r.validateArchivalStorage()...)
+	if !reflect.DeepEqual(r.Spec.BootstrapUser, oldCluster.Spec.BootstrapUser) {
+		allErrs = append(allErrs,
+			field.Forbidden(field.NewPath("spec").Child("bootstrapUser"),
+				"the bootstrap user is only created when the cluster forms and cannot be changed"))
+	}
+
+	allErrs = append(allErrs, r.validateInternalTopicPartitionsUpdate(oldCluster)...)
+
+	allErrs = append(allErrs, r.validateSpec()...)
 
 	if len(allErrs) == 0 {
 		return nil
@@ -103,6 +249,123 @@ func (r *Cluster) ValidateUpdate(old runtime.Object) error {
 		r.Name, allErrs)
 }
 
+// validateSpec runs the checks shared by ValidateCreate and ValidateUpdate
+func (r *Cluster) validateSpec() field.ErrorList {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, r.checkCollidingPorts()...)
+
+	allErrs = append(allErrs, r.validateMemory()...)
+
+	allErrs = append(allErrs, r.validateCPUPinning()...)
+
+	allErrs = append(allErrs, r.validateTLS()...)
+
+	allErrs = append(allErrs, r.validateGracefulShutdown()...)
+
+	allErrs = append(allErrs, r.validatePreStopHook()...)
+
+	allErrs = append(allErrs, r.validateRestartOrder()...)
+
+	allErrs = append(allErrs, r.validateConsumerGroupCoordination()...)
+
+	allErrs = append(allErrs, r.validateBrokerShutdown()...)
+
+	allErrs = append(allErrs, r.validateBrokerFailure()...)
+
+	allErrs = append(allErrs, r.validateDebugBundle()...)
+
+	allErrs = append(allErrs, r.validateRestartAnnotation()...)
+
+	allErrs = append(allErrs, r.validateBackup()...)
+
+	allErrs = append(allErrs, r.validateResourceRecommendation()...)
+
+	allErrs = append(allErrs, r.validateTopicWarmup()...)
+
+	allErrs = append(allErrs, r.validateDecommissionDrain()...)
+
+	allErrs = append(allErrs, r.validateReadinessStabilization()...)
+
+	allErrs = append(allErrs, r.validateCrashLoopRecovery()...)
+
+	allErrs = append(allErrs, r.validateBootstrapUser()...)
+
+	allErrs = append(allErrs, r.validateSuperuserPasswordRotation()...)
+
+	allErrs = append(allErrs, r.validateAdminAPIBindNetwork()...)
+
+	allErrs = append(allErrs, r.validateArchivalStorage()...)
+
+	allErrs = append(allErrs, r.validateCompaction()...)
+
+	allErrs = append(allErrs, r.validateKafkaQuotas()...)
+
+	allErrs = append(allErrs, r.validateExternalSubdomains()...)
+
+	allErrs = append(allErrs, r.validateExternalDNS()...)
+
+	allErrs = append(allErrs, r.validateUniqueExternalSubdomains()...)
+
+	allErrs = append(allErrs, r.validateExternalAdvertisedPort()...)
+
+	allErrs = append(allErrs, r.validatePandaproxyAPI()...)
+
+	allErrs = append(allErrs, r.validatePodAffinity()...)
+
+	allErrs = append(allErrs, r.validateDedicatedNodes()...)
+
+	allErrs = append(allErrs, r.validatePodMonitor()...)
+
+	allErrs = append(allErrs, r.validateReplication()...)
+
+	allErrs = append(allErrs, r.validateAdditionalArguments()...)
+
+	allErrs = append(allErrs, r.validatePerBrokerConfig()...)
+
+	allErrs = append(allErrs, r.validateKafkaAuthentication()...)
+
+	allErrs = append(allErrs, r.validateListenerNames()...)
+
+	allErrs = append(allErrs, r.validateKafkaExternalListener()...)
+
+	allErrs = append(allErrs, r.validateRaftTuning()...)
+
+	allErrs = append(allErrs, r.validateRPCServerTuning()...)
+
+	allErrs = append(allErrs, r.validateCompression()...)
+
+	allErrs = append(allErrs, r.validateRackAwareness()...)
+
+	allErrs = append(allErrs, r.validateKafkaCompatibility()...)
+
+	allErrs = append(allErrs, r.validateDiscoveryServices()...)
+
+	allErrs = append(allErrs, r.validateNetworkPolicy()...)
+
+	allErrs = append(allErrs, r.validateManagementNodePort()...)
+
+	allErrs = append(allErrs, r.validateFeatureGates()...)
+
+	allErrs = append(allErrs, r.validateReconcilePeriods()...)
+
+	allErrs = append(allErrs, r.validateStorage()...)
+
+	allErrs = append(allErrs, r.validateMemoryLocking()...)
+
+	allErrs = append(allErrs, r.validateReactor()...)
+
+	allErrs = append(allErrs, r.validateNodeTuning()...)
+
+	allErrs = append(allErrs, r.validateInitContainers()...)
+
+	allErrs = append(allErrs, r.validateSuperusers()...)
+
+	allErrs = append(allErrs, r.validateConfigMapReferences()...)
+
+	return allErrs
+}
+
 // ReserveMemoryString is amount of memory that we reserve for other processes than redpanda in the container
 const ReserveMemoryString = "1M"
 
@@ -110,6 +373,9 @@ const ReserveMemoryString = "1M"
 // which is 1GB per core
 // to verify this, we need to subtract the 1M we reserve currently for other processes
 func (r *Cluster) validateMemory() field.ErrorList {
+	if r.Spec.MemoryReservation != nil {
+		return r.validateMemoryReservation()
+	}
 	var allErrs field.ErrorList
 	quantity := resource.MustParse(ReserveMemoryString)
 	if !r.Spec.Configuration.DeveloperMode && (r.Spec.Resources.Limits.Memory().Value()-quantity.Value()) < gb {
@@ -122,21 +388,1852 @@ func (r *Cluster) validateMemory() field.ErrorList {
 	return allErrs
 }
 
-func (r *Cluster) validateTLS() field.ErrorList {
+// MinMemoryReservationString is the minimal amount of memory left to other
+// processes than Redpanda when the memory reservation is configured
+const MinMemoryReservationString = "100M"
+
+// validateMemoryReservation verifies that the memory reservation leaves
+// enough memory both to Redpanda and to the other processes in the container
+func (r *Cluster) validateMemoryReservation() field.ErrorList {
 	var allErrs field.ErrorList
-	if r.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth && !r.Spec.Configuration.TLS.KafkaAPI.Enabled {
+	path := field.NewPath("spec").Child("memoryReservation")
+	reservation := r.Spec.MemoryReservation
+	if reservation.Reserved != nil && reservation.Percent != 0 {
+		return append(allErrs,
+			field.Invalid(path,
+				reservation,
+				"reserved and percent are mutually exclusive"))
+	}
+
+	memory, _ := r.RedpandaMemory()
+	limit := r.Spec.Resources.Limits.Memory().Value()
+	minReservation := resource.MustParse(MinMemoryReservationString)
+	if limit-memory < minReservation.Value() {
 		allErrs = append(allErrs,
-			field.Invalid(
-				field.NewPath("spec").Child("configuration").Child("tls").Child("requireclientauth"),
-				r.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth,
-				"Enabled has to be set to true for RequireClientAuth to be allowed to be true"))
+			field.Invalid(path,
+				reservation,
+				"need to reserve at least "+MinMemoryReservationString+" of memory for other processes"))
 	}
-	if r.Spec.Configuration.TLS.KafkaAPI.IssuerRef != nil && r.Spec.Configuration.TLS.KafkaAPI.NodeSecretRef != nil {
+	if memory <= 0 || (!r.Spec.Configuration.DeveloperMode && memory < gb) {
 		allErrs = append(allErrs,
-			field.Invalid(
-				field.NewPath("spec").Child("configuration").Child("tls").Child("nodeSecretRef"),
-				r.Spec.Configuration.TLS.KafkaAPI.NodeSecretRef,
-				"Cannot provide both IssuerRef and NodeSecretRef"))
+			field.Invalid(path,
+				reservation,
+				"need minimum of 1GB of memory per node left to redpanda"))
+	}
+	return allErrs
+}
+
+// validateCPUPinning verifies that pinned brokers get Guaranteed QoS with an
+// integer number of CPUs, which the static CPU manager policy requires to
+// assign exclusive CPUs
+func (r *Cluster) validateCPUPinning() field.ErrorList {
+	var allErrs field.ErrorList
+	cpus, ok := r.PinnedCPUs()
+	if !ok {
+		return allErrs
+	}
+	resourcesPath := field.NewPath("spec").Child("resources")
+	limits, requests := r.Spec.Resources.Limits, r.Spec.Resources.Requests
+	cpuLimit := limits.Cpu()
+	if cpuLimit.IsZero() || cpuLimit.MilliValue()%1000 != 0 {
+		allErrs = append(allErrs,
+			field.Invalid(resourcesPath.Child("limits").Child("cpu"),
+				cpuLimit.String(),
+				"CPU pinning requires an integer CPU limit"))
+	}
+	if cpu, set := requests[corev1.ResourceCPU]; set && cpu.Cmp(*cpuLimit) != 0 {
+		allErrs = append(allErrs,
+			field.Invalid(resourcesPath.Child("requests").Child("cpu"),
+				cpu.String(),
+				"CPU pinning requires the CPU request to equal the limit"))
+	}
+	memoryLimit := limits.Memory()
+	if memory, set := requests[corev1.ResourceMemory]; set && memory.Cmp(*memoryLimit) != 0 {
+		allErrs = append(allErrs,
+			field.Invalid(resourcesPath.Child("requests").Child("memory"),
+				memory.String(),
+				"CPU pinning requires the memory request to equal the limit"))
+	}
+
+	pinningPath := field.NewPath("spec").Child("cpuPinning").Child("cpuset")
+	if cpuset := r.Spec.CPUPinning.CPUSet; cpuset != "" {
+		size, err := cpusetSize(cpuset)
+		switch {
+		case err != nil:
+			allErrs = append(allErrs, field.Invalid(pinningPath, cpuset, err.Error()))
+		case int64(size) != cpus:
+			allErrs = append(allErrs,
+				field.Invalid(pinningPath,
+					cpuset,
+					fmt.Sprintf("cpuset has %d CPUs but the CPU limit is %d", size, cpus)))
+		}
+	}
+	for i, arg := range r.Spec.AdditionalCommandLineArguments {
+		if flagName(arg) == cpusetFlag {
+			allErrs = append(allErrs,
+				field.Invalid(field.NewPath("spec").Child("additionalCommandLineArguments").Index(i),
+					arg,
+					fmt.Sprintf("%s is managed by the operator when CPU pinning is enabled", cpusetFlag)))
+		}
+	}
+	return allErrs
+}
+
+// cpusetFlag is set by the operator when CPU pinning has a cpuset
+const cpusetFlag = "--cpuset"
+
+// cpusetSize returns the number of CPUs in a list such as 0-3,8
+func cpusetSize(cpuset string) (int, error) {
+	cpus := make(map[int]bool)
+	for _, part := range strings.Split(cpuset, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil || first < 0 {
+			return 0, fmt.Errorf("invalid CPU %q", part)
+		}
+		last := first
+		if len(bounds) == 2 {
+			last, err = strconv.Atoi(bounds[1])
+			if err != nil || last < first {
+				return 0, fmt.Errorf("invalid CPU range %q", part)
+			}
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			cpus[cpu] = true
+		}
+	}
+	return len(cpus), nil
+}
+
+// validateGracefulShutdown rejects the drain hook when the Admin API
+// requires client certificates, which the hook cannot present
+func (r *Cluster) validateGracefulShutdown() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.GracefulShutdown != nil && r.Spec.Configuration.TLS.AdminAPI.RequireClientAuth {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("gracefulShutdown"),
+				r.Spec.GracefulShutdown,
+				"graceful shutdown is not supported with Admin API mutual TLS"))
+	}
+	return allErrs
+}
+
+// validateRestartOrder verifies the restart order. The leadership order
+// relies on the drain of graceful shutdown to move the leadership off each
+// broker before it is restarted.
+func (r *Cluster) validateRestartOrder() field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec").Child("restartOrder")
+	switch r.Spec.RestartOrder {
+	case "", RestartOrderOrdinal:
+	case RestartOrderFewestLeadershipsFirst:
+		if r.Spec.GracefulShutdown == nil {
+			allErrs = append(allErrs,
+				field.Forbidden(path,
+					"restarting the brokers in leadership order requires gracefulShutdown"))
+		}
+	default:
+		allErrs = append(allErrs,
+			field.NotSupported(path, r.Spec.RestartOrder, []string{
+				string(RestartOrderOrdinal),
+				string(RestartOrderFewestLeadershipsFirst),
+			}))
+	}
+	return allErrs
+}
+
+// validateConsumerGroupCoordination verifies the consumer group coordination.
+// The operator describes the groups through the Kafka API without
+// credentials.
+func (r *Cluster) validateConsumerGroupCoordination() field.ErrorList {
+	var allErrs field.ErrorList
+	coordination := r.Spec.ConsumerGroupCoordination
+	if coordination == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("consumerGroupCoordination")
+	if r.KafkaAuthenticationMethod() == KafkaAuthenticationSASL {
+		allErrs = append(allErrs,
+			field.Forbidden(path, "the operator describes the consumer groups without SASL credentials"))
+	}
+	if s := coordination.StabilizationSeconds; s != nil && *s < 0 {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("stabilizationSeconds"), *s, "must not be negative"))
+	}
+	if m := coordination.MaxRebalancingGroups; m != nil && *m < 0 {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("maxRebalancingGroups"), *m, "must not be negative"))
+	}
+	return allErrs
+}
+
+// validatePreStopHook verifies the custom preStop command. Its order is
+// relative to the drain, so it requires graceful shutdown.
+func (r *Cluster) validatePreStopHook() field.ErrorList {
+	var allErrs field.ErrorList
+	hook := r.PreStopHook()
+	if hook == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("lifecycle").Child("preStop")
+	if len(hook.Command) == 0 || hook.Command[0] == "" {
+		allErrs = append(allErrs,
+			field.Required(path.Child("command"), "the command of the preStop hook is required"))
+	}
+	switch hook.Order {
+	case "":
+	case PreStopHookBeforeDrain, PreStopHookAfterDrain:
+		if r.Spec.GracefulShutdown == nil {
+			allErrs = append(allErrs,
+				field.Forbidden(path.Child("order"),
+					"the order relative to the drain requires gracefulShutdown"))
+		}
+	default:
+		allErrs = append(allErrs,
+			field.NotSupported(path.Child("order"), hook.Order, []string{
+				string(PreStopHookBeforeDrain),
+				string(PreStopHookAfterDrain),
+			}))
+	}
+	if t := hook.TimeoutSeconds; t != nil && (*t < 1 || *t > maxPreStopHookTimeoutSeconds) {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("timeoutSeconds"),
+				*t,
+				fmt.Sprintf("has to be between 1 and %d seconds", maxPreStopHookTimeoutSeconds)))
+	}
+	return allErrs
+}
+
+// validateBrokerShutdown verifies that the scaled shutdown time is not capped
+// below its base time
+func (r *Cluster) validateBrokerShutdown() field.ErrorList {
+	var allErrs field.ErrorList
+	stop := r.BrokerShutdown()
+	if stop == nil || stop.MaxTimeoutSeconds == nil {
+		return allErrs
+	}
+	timeout := int32(defaultShutdownTimeoutSeconds)
+	if stop.TimeoutSeconds != nil {
+		timeout = *stop.TimeoutSeconds
+	}
+	if *stop.MaxTimeoutSeconds < timeout {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("lifecycle").Child("shutdown").Child("maxTimeoutSeconds"),
+				*stop.MaxTimeoutSeconds,
+				fmt.Sprintf("has to be at least the shutdown timeout of %d seconds", timeout)))
+	}
+	return allErrs
+}
+
+// validateBrokerFailure rejects grace periods too short to tell a failed
+// broker from a restarting one
+func (r *Cluster) validateBrokerFailure() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.BrokerFailure == nil {
+		return allErrs
+	}
+	if period := r.Spec.BrokerFailure.GracePeriod; period != nil && period.Duration < minBrokerFailureGracePeriod {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("brokerFailure").Child("gracePeriod"),
+				period.Duration.String(),
+				fmt.Sprintf("must be at least %s", minBrokerFailureGracePeriod)))
+	}
+	return allErrs
+}
+
+// decommissioned returns true when decommission draining is enabled and the
+// brokers of the ordinals from replicas to previous are decommissioned
+func (r *Cluster) decommissioned(oldCluster *Cluster, replicas, previous int32) bool {
+	if r.Spec.DecommissionDrain == nil {
+		return false
+	}
+	draining := make(map[int]bool, len(oldCluster.Status.DrainingBrokers))
+	for _, b := range oldCluster.Status.DrainingBrokers {
+		draining[b.NodeID] = true
+	}
+	for id := replicas; id < previous; id++ {
+		if !draining[int(id)] {
+			return false
+		}
+	}
+	return true
+}
+
+// validateReadinessStabilization rejects stabilization windows that are not
+// positive
+func (r *Cluster) validateReadinessStabilization() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.ReadinessStabilization == nil {
+		return allErrs
+	}
+	if window := r.Spec.ReadinessStabilization.Window; window.Duration <= 0 {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("readinessStabilization").Child("window"),
+				window.Duration.String(),
+				"must be positive"))
+	}
+	return allErrs
+}
+
+// validateDecommissionDrain rejects negative drain timeouts
+func (r *Cluster) validateDecommissionDrain() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.DecommissionDrain == nil {
+		return allErrs
+	}
+	if timeout := r.Spec.DecommissionDrain.Timeout; timeout != nil && timeout.Duration < 0 {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("decommissionDrain").Child("timeout"),
+				timeout.Duration.String(),
+				"must not be negative"))
+	}
+	return allErrs
+}
+
+// validateCrashLoopRecovery rejects intervals that would let the recovery
+// mask a persistent crash loop
+func (r *Cluster) validateCrashLoopRecovery() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.CrashLoopRecovery == nil {
+		return allErrs
+	}
+	if interval := r.Spec.CrashLoopRecovery.MinInterval; interval != nil && interval.Duration < MinCrashLoopRecoveryInterval {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("crashLoopRecovery").Child("minInterval"),
+				interval.Duration.String(),
+				fmt.Sprintf("must be at least %s", MinCrashLoopRecoveryInterval)))
+	}
+	return allErrs
+}
+
+// validateRestartAnnotation rejects restart requests for brokers that do
+// not exist
+func (r *Cluster) validateRestartAnnotation() field.ErrorList {
+	var allErrs field.ErrorList
+	if _, err := r.RequestedRestarts(); err != nil {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("metadata").Child("annotations").Key(RestartAnnotation),
+				r.Annotations[RestartAnnotation],
+				err.Error()))
+	}
+	return allErrs
+}
+
+// validateDebugBundle rejects bundle requests without a bundle configuration
+// and intervals that would let repeated requests load the brokers
+func (r *Cluster) validateDebugBundle() field.ErrorList {
+	var allErrs field.ErrorList
+	bundle := r.Spec.DebugBundle
+	if bundle == nil {
+		if r.Annotations[DebugBundleAnnotation] != "" {
+			allErrs = append(allErrs,
+				field.Forbidden(field.NewPath("metadata").Child("annotations").Key(DebugBundleAnnotation),
+					"requesting a debug bundle requires spec.debugBundle"))
+		}
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("debugBundle")
+	if bundle.UploadURLSecretRef.Name == "" {
+		allErrs = append(allErrs,
+			field.Required(path.Child("uploadURLSecretRef").Child("name"), "the Secret holding the upload URL is required"))
+	}
+	if bundle.UploadURLSecretRef.Key == "" {
+		allErrs = append(allErrs,
+			field.Required(path.Child("uploadURLSecretRef").Child("key"), "the key of the upload URL is required"))
+	}
+	if bundle.MinInterval != nil && bundle.MinInterval.Duration < MinDebugBundleInterval {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("minInterval"),
+				bundle.MinInterval.Duration.String(),
+				fmt.Sprintf("must be at least %s", MinDebugBundleInterval)))
+	}
+	return allErrs
+}
+
+// validateBackup rejects backups without cloud storage, the recovery points
+// are in its bucket, and intervals below MinBackupInterval
+func (r *Cluster) validateBackup() field.ErrorList {
+	var allErrs field.ErrorList
+	backup := r.Spec.Backup
+	if backup == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("backup")
+	if !r.Spec.CloudStorage.Enabled {
+		allErrs = append(allErrs,
+			field.Forbidden(path, "backups require spec.cloudStorage.enabled"))
+	}
+	if backup.Interval != nil && backup.Interval.Duration < MinBackupInterval {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("interval"),
+				backup.Interval.Duration.String(),
+				fmt.Sprintf("must be at least %s", MinBackupInterval)))
+	}
+	return allErrs
+}
+
+// validateResourceRecommendation rejects windows spanning too few samples
+func (r *Cluster) validateResourceRecommendation() field.ErrorList {
+	var allErrs field.ErrorList
+	recommendation := r.Spec.ResourceRecommendation
+	if recommendation == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("resourceRecommendation")
+	if recommendation.Window != nil && recommendation.Window.Duration < MinRecommendationWindow {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("window"),
+				recommendation.Window.Duration.String(),
+				fmt.Sprintf("must be at least %s", MinRecommendationWindow)))
+	}
+	if recommendation.HeadroomPercent != nil && *recommendation.HeadroomPercent < 0 {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("headroomPercent"), *recommendation.HeadroomPercent, "must not be negative"))
+	}
+	return allErrs
+}
+
+// validateTopicWarmup rejects topics Kafka does not accept, replication
+// factors the brokers can not place and listeners the warmup Job can not
+// connect to
+func (r *Cluster) validateTopicWarmup() field.ErrorList {
+	var allErrs field.ErrorList
+	warmup := r.Spec.TopicWarmup
+	if warmup == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("topicWarmup")
+	if r.KafkaAuthenticationMethod() != KafkaAuthenticationNone || r.Spec.Configuration.TLS.KafkaAPI.Enabled {
+		allErrs = append(allErrs,
+			field.Forbidden(path, "the warmup Job connects to the Kafka API without TLS and credentials"))
+	}
+	if len(warmup.Topics) == 0 {
+		allErrs = append(allErrs,
+			field.Required(path.Child("topics"), "at least one topic has to be warmed up"))
+	}
+	seen := map[string]bool{}
+	for i, topic := range warmup.Topics {
+		topicPath := path.Child("topics").Index(i)
+		switch {
+		case len(topic.Name) > maxKafkaTopicNameLength, !kafkaTopicNamePattern.MatchString(topic.Name),
+			topic.Name == ".", topic.Name == "..":
+			allErrs = append(allErrs,
+				field.Invalid(topicPath.Child("name"), topic.Name,
+					fmt.Sprintf("must be at most %d characters of letters, digits, '.', '_' and '-'", maxKafkaTopicNameLength)))
+		case seen[topic.Name]:
+			allErrs = append(allErrs,
+				field.Duplicate(topicPath.Child("name"), topic.Name))
+		}
+		seen[topic.Name] = true
+		if topic.Partitions < 1 {
+			allErrs = append(allErrs,
+				field.Invalid(topicPath.Child("partitions"), topic.Partitions, "must be at least 1"))
+		}
+		if rf := topic.ReplicationFactor; rf != nil {
+			if *rf < 1 || *rf%2 == 0 {
+				allErrs = append(allErrs,
+					field.Invalid(topicPath.Child("replicationFactor"), *rf, "must be odd"))
+			} else if r.Spec.Replicas != nil && *rf > *r.Spec.Replicas {
+				allErrs = append(allErrs,
+					field.Invalid(topicPath.Child("replicationFactor"), *rf,
+						fmt.Sprintf("must be at most the %d replicas of the cluster", *r.Spec.Replicas)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateDowngrade rejects changing the version to one lower than the
+// highest version the cluster ran, unless downgrades are allowed. Tags that
+// name no release are not compared.
+func (r *Cluster) validateDowngrade(old *Cluster) field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.AllowDowngrade || r.Spec.Version == old.Spec.Version {
+		return allErrs
+	}
+	highest, ok := ParseRedpandaVersion(old.Status.HighestVersion)
+	if !ok {
+		return allErrs
+	}
+	if version, ok := ParseRedpandaVersion(r.Spec.Version); ok && version.Less(highest) {
+		allErrs = append(allErrs,
+			field.Forbidden(field.NewPath("spec").Child("version"),
+				fmt.Sprintf("the cluster ran %s, Redpanda does not support downgrades to %s unless allowDowngrade is set",
+					old.Status.HighestVersion, r.Spec.Version)))
+	}
+	return allErrs
+}
+
+// validateSuperuserPasswordRotation verifies that there is a bootstrap
+// superuser to rotate the password of
+func (r *Cluster) validateSuperuserPasswordRotation() field.ErrorList {
+	var allErrs field.ErrorList
+	rotation := r.Spec.Configuration.SuperuserPasswordRotation
+	if rotation == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("configuration").Child("superuserPasswordRotation")
+	if !r.BootstrapSuperuserRequired() {
+		allErrs = append(allErrs,
+			field.Forbidden(path, "the operator creates no bootstrap superuser without SASL authentication"))
+	}
+	if rotation.Interval.Duration < time.Hour {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("interval"), rotation.Interval.Duration.String(), "must be at least 1h"))
+	}
+	return allErrs
+}
+
+// validateBootstrapUser verifies the Secret holding the credentials of the
+// bootstrap user
+func (r *Cluster) validateBootstrapUser() field.ErrorList {
+	var allErrs field.ErrorList
+	user := r.Spec.BootstrapUser
+	if user == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("bootstrapUser").Child("secretRef").Child("name")
+	if user.SecretRef.Name == "" {
+		return append(allErrs,
+			field.Required(path, "the Secret with the credentials is required"))
+	}
+	if clusterReader == nil {
+		return allErrs
+	}
+	var secret corev1.Secret
+	err := clusterReader.Get(context.Background(), client.ObjectKey{Name: user.SecretRef.Name, Namespace: r.Namespace}, &secret)
+	switch {
+	case apierrors.IsNotFound(err):
+		allErrs = append(allErrs, field.NotFound(path, user.SecretRef.Name))
+	case err != nil:
+		allErrs = append(allErrs,
+			field.InternalError(path, fmt.Errorf("unable to get secret: %w", err)))
+	case len(secret.Data[corev1.BasicAuthUsernameKey]) == 0 || len(secret.Data[corev1.BasicAuthPasswordKey]) == 0:
+		allErrs = append(allErrs,
+			field.Invalid(path,
+				user.SecretRef.Name,
+				fmt.Sprintf("the Secret has to hold the %s and %s keys", corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey)))
+	}
+	return allErrs
+}
+
+// validateAdminAPIBindNetwork verifies the management network CIDR. The
+// readiness probe of a broker bound to it runs curl in the container, which
+// cannot present a client certificate, so Admin API mutual TLS is rejected.
+func (r *Cluster) validateAdminAPIBindNetwork() field.ErrorList {
+	var allErrs field.ErrorList
+	network := r.Spec.Configuration.AdminAPIBindNetwork
+	if network == "" {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("configuration").Child("adminApiBindNetwork")
+	if _, _, err := net.ParseCIDR(network); err != nil {
+		allErrs = append(allErrs,
+			field.Invalid(path, network, "must be a CIDR, e.g. 10.20.0.0/16"))
+	}
+	if r.Spec.Configuration.TLS.AdminAPI.RequireClientAuth {
+		allErrs = append(allErrs,
+			field.Invalid(path, network,
+				"the readiness probe cannot reach an Admin API bound to a management network with mutual TLS"))
+	}
+	return allErrs
+}
+
+// validateKafkaQuotas verifies that the default quota is positive
+func (r *Cluster) validateKafkaQuotas() field.ErrorList {
+	var allErrs field.ErrorList
+	limits := r.Spec.Configuration.KafkaClientLimits
+	path := field.NewPath("spec").Child("configuration").Child("kafkaClientLimits")
+	if limits.TargetQuotaByteRate < 0 {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("targetQuotaByteRate"), limits.TargetQuotaByteRate,
+				"quota has to be positive"))
+	}
+	return allErrs
+}
+
+// validateInternalTopicPartitionsUpdate rejects decreasing the partitions of
+// the internal group topic, a Kafka topic can only gain partitions. Removing
+// the count is a decrease to the default of Redpanda, which may be lower.
+func (r *Cluster) validateInternalTopicPartitionsUpdate(
+	oldCluster *Cluster,
+) field.ErrorList {
+	var allErrs field.ErrorList
+	partitions := r.Spec.Configuration.GroupTopicPartitions
+	oldPartitions := oldCluster.Spec.Configuration.GroupTopicPartitions
+	if oldPartitions != 0 && partitions < oldPartitions {
+		allErrs = append(allErrs,
+			field.Forbidden(field.NewPath("spec").Child("configuration").Child("groupTopicPartitions"),
+				fmt.Sprintf("the partitions of the internal topic cannot be decreased from %d, topics can only gain partitions", oldPartitions)))
+	}
+	return allErrs
+}
+
+// validateRackAwareness verifies the rack label and that restarts on rack
+// changes have racks to compare
+func (r *Cluster) validateRackAwareness() field.ErrorList {
+	var allErrs field.ErrorList
+	rack := r.Spec.Configuration.RackAwareness
+	if rack == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("configuration").Child("rackAwareness")
+	if rack.NodeLabel != "" {
+		for _, msg := range validation.IsQualifiedName(rack.NodeLabel) {
+			allErrs = append(allErrs, field.Invalid(path.Child("nodeLabel"), rack.NodeLabel, msg))
+		}
+	}
+	if rack.OnRackChange == RackChangeRestart && !rack.Enabled {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("onRackChange"), "restarting brokers with a stale rack requires rack awareness to be enabled"))
+	}
+	return allErrs
+}
+
+// validateKafkaCompatibility verifies that transactions are not enabled
+// without idempotence
+func (r *Cluster) validateKafkaCompatibility() field.ErrorList {
+	var allErrs field.ErrorList
+	compatibility := r.Spec.Configuration.KafkaCompatibility
+	if compatibility.EnableTransactions != nil && *compatibility.EnableTransactions &&
+		compatibility.EnableIdempotence != nil && !*compatibility.EnableIdempotence {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("configuration").Child("kafkaCompatibility").Child("enableTransactions"),
+				*compatibility.EnableTransactions,
+				"transactions require idempotence"))
+	}
+	return allErrs
+}
+
+// validateNetworkPolicy verifies the allowed client namespaces and selectors
+func (r *Cluster) validateNetworkPolicy() field.ErrorList {
+	var allErrs field.ErrorList
+	policy := r.Spec.NetworkPolicy
+	if policy == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("networkPolicy")
+	for i, ns := range policy.AllowedClientNamespaces {
+		for _, msg := range validation.IsDNS1123Label(ns) {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("allowedClientNamespaces").Index(i), ns, msg))
+		}
+	}
+	for i := range policy.AllowedClientSelectors {
+		if _, err := metav1.LabelSelectorAsSelector(&policy.AllowedClientSelectors[i]); err != nil {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("allowedClientSelectors").Index(i),
+					policy.AllowedClientSelectors[i],
+					err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validateManagementNodePort verifies that the management node port is in
+// the default node port range and not used by another Service, and that its
+// source ranges can be enforced
+func (r *Cluster) validateManagementNodePort() field.ErrorList {
+	var allErrs field.ErrorList
+	management := r.Spec.ManagementNodePort
+	if management == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("managementNodePort")
+	if management.NodePort < minNodePort || management.NodePort > maxNodePort {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("nodePort"),
+				management.NodePort,
+				fmt.Sprintf("has to be in the node port range %d-%d", minNodePort, maxNodePort)))
+	}
+	for i, cidr := range management.SourceRanges {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("sourceRanges").Index(i), cidr, "must be a CIDR, e.g. 10.20.0.0/16"))
+		}
+	}
+	if len(management.SourceRanges) > 0 && (r.Spec.NetworkPolicy == nil || !r.Spec.NetworkPolicy.Enabled) {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("sourceRanges"),
+				"the source ranges are enforced by the NetworkPolicy, which has to be enabled"))
+	}
+	if r.Spec.Configuration.AdminAPIBindNetwork != "" {
+		allErrs = append(allErrs,
+			field.Forbidden(path,
+				"the Service cannot reach an Admin API bound to a management network"))
+	}
+
+	if clusterReader == nil {
+		return allErrs
+	}
+	var services corev1.ServiceList
+	if err := clusterReader.List(context.Background(), &services); err != nil {
+		return append(allErrs,
+			field.InternalError(path.Child("nodePort"),
+				fmt.Errorf("unable to list services: %w", err)))
+	}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if svc.Namespace == r.Namespace && svc.Name == r.Name+managementServiceSuffix {
+			continue
+		}
+		for _, port := range svc.Spec.Ports {
+			if port.NodePort == management.NodePort {
+				allErrs = append(allErrs,
+					field.Invalid(path.Child("nodePort"),
+						management.NodePort,
+						fmt.Sprintf("node port is used by service %s/%s", svc.Namespace, svc.Name)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateDiscoveryServices verifies that the discovery Services are not
+// duplicated and that their namespaces exist
+func (r *Cluster) validateDiscoveryServices() field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec").Child("discoveryServices")
+	seen := map[string]bool{}
+	for i, svc := range r.Spec.DiscoveryServices {
+		key := svc.Namespace + "/" + svc.Name
+		if svc.Name == "" {
+			key = svc.Namespace + "/" + r.Name
+		}
+		if seen[key] {
+			allErrs = append(allErrs,
+				field.Duplicate(path.Index(i), key))
+			continue
+		}
+		seen[key] = true
+		if key == r.Namespace+"/"+r.Name {
+			allErrs = append(allErrs,
+				field.Invalid(path.Index(i).Child("name"),
+					svc.Name,
+					"name is used by the headless service of the cluster"))
+		}
+
+		if clusterReader == nil {
+			continue
+		}
+		var ns corev1.Namespace
+		err := clusterReader.Get(context.Background(), client.ObjectKey{Name: svc.Namespace}, &ns)
+		switch {
+		case apierrors.IsNotFound(err):
+			allErrs = append(allErrs,
+				field.NotFound(path.Index(i).Child("namespace"), svc.Namespace))
+		case err != nil:
+			allErrs = append(allErrs,
+				field.InternalError(path.Index(i).Child("namespace"),
+					fmt.Errorf("unable to get namespace: %w", err)))
+		}
+	}
+	return allErrs
+}
+
+// validateRaftTuning verifies that leaders send heartbeats more often than
+// followers time out, otherwise the cluster keeps electing new leaders
+func (r *Cluster) validateRaftTuning() field.ErrorList {
+	var allErrs field.ErrorList
+	raft := r.Spec.Configuration.Raft
+	if raft.HeartbeatIntervalMs == 0 && raft.ElectionTimeoutMs == 0 {
+		return allErrs
+	}
+	heartbeat := raft.HeartbeatIntervalMs
+	if heartbeat == 0 {
+		heartbeat = defaultRaftHeartbeatIntervalMs
+	}
+	election := raft.ElectionTimeoutMs
+	if election == 0 {
+		election = defaultRaftElectionTimeoutMs
+	}
+	if heartbeat >= election {
+		allErrs = append(allErrs,
+			field.Invalid(
+				field.NewPath("spec").Child("configuration").Child("raft").Child("heartbeatIntervalMs"),
+				heartbeat,
+				fmt.Sprintf("heartbeat interval has to be lower than the election timeout (%d ms)", election)))
+	}
+	return allErrs
+}
+
+// validateRPCServerTuning verifies that heartbeats time out after they are
+// sent again and that the keepalive probes are within the kernel limits
+func (r *Cluster) validateRPCServerTuning() field.ErrorList {
+	var allErrs field.ErrorList
+	tuning := r.Spec.Configuration.RPCServerTuning
+	path := field.NewPath("spec").Child("configuration").Child("rpcServerTuning")
+	if tuning.HeartbeatTimeoutMs != 0 {
+		heartbeat := r.Spec.Configuration.Raft.HeartbeatIntervalMs
+		if heartbeat == 0 {
+			heartbeat = defaultRaftHeartbeatIntervalMs
+		}
+		if tuning.HeartbeatTimeoutMs <= heartbeat || tuning.HeartbeatTimeoutMs > maxRPCHeartbeatTimeoutMs {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("heartbeatTimeoutMs"), tuning.HeartbeatTimeoutMs,
+					fmt.Sprintf("has to be longer than the heartbeat interval (%d ms) and at most %d ms", heartbeat, maxRPCHeartbeatTimeoutMs)))
+		}
+	}
+	if keepalive := tuning.TCPKeepalive; keepalive != nil {
+		for _, l := range []struct {
+			name  string
+			value *int32
+			max   int32
+		}{
+			{"timeSeconds", keepalive.TimeSeconds, maxTCPKeepaliveSeconds},
+			{"intervalSeconds", keepalive.IntervalSeconds, maxTCPKeepaliveSeconds},
+			{"probes", keepalive.Probes, maxTCPKeepaliveProbes},
+		} {
+			if l.value != nil && (*l.value < 1 || *l.value > l.max) {
+				allErrs = append(allErrs,
+					field.Invalid(path.Child("tcpKeepalive").Child(l.name), *l.value,
+						fmt.Sprintf("has to be between 1 and %d", l.max)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateCompression rejects codecs that Redpanda does not support
+func (r *Cluster) validateCompression() field.ErrorList {
+	var allErrs field.ErrorList
+	switch codec := r.Spec.Configuration.Compression.TopicDefault; codec {
+	case "", CompressionNone, CompressionGzip, CompressionSnappy, CompressionLZ4, CompressionZstd, CompressionProducer:
+	default:
+		allErrs = append(allErrs,
+			field.NotSupported(
+				field.NewPath("spec").Child("configuration").Child("compression").Child("topicDefault"),
+				codec,
+				[]string{
+					string(CompressionNone),
+					string(CompressionGzip),
+					string(CompressionSnappy),
+					string(CompressionLZ4),
+					string(CompressionZstd),
+					string(CompressionProducer),
+				}))
+	}
+	return allErrs
+}
+
+// validateFeatureGates rejects unknown feature gates, they are most likely
+// typos or gates removed from the operator
+func (r *Cluster) validateFeatureGates() field.ErrorList {
+	var allErrs field.ErrorList
+	for gate := range r.Spec.FeatureGates {
+		if _, ok := featureGateDefaults[gate]; !ok {
+			allErrs = append(allErrs,
+				field.NotSupported(field.NewPath("spec").Child("featureGates"),
+					gate,
+					sortedFeatureGates()))
+		}
+	}
+	if r.FeatureGateEnabled(FeatureGateOnlineConfiguration) {
+		allErrs = append(allErrs,
+			field.Forbidden(field.NewPath("spec").Child("featureGates").Key(FeatureGateOnlineConfiguration),
+				"the Admin API of this Redpanda version does not serve the cluster configuration, the brokers restart to apply it"))
+	}
+	return allErrs
+}
+
+// validateReconcilePeriods rejects periods short enough to overload the API
+// server and the Admin API of the brokers
+func (r *Cluster) validateReconcilePeriods() field.ErrorList {
+	var allErrs field.ErrorList
+	if period := r.Spec.ResyncPeriod; period != nil && period.Duration > 0 && period.Duration < MinResyncPeriod {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("resyncPeriod"),
+				period.Duration.String(),
+				fmt.Sprintf("must be at least %s", MinResyncPeriod)))
+	}
+	value, ok := r.Annotations[RequeuePeriodAnnotation]
+	if !ok {
+		return allErrs
+	}
+	path := field.NewPath("metadata").Child("annotations").Key(RequeuePeriodAnnotation)
+	period, err := time.ParseDuration(value)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(path, value, err.Error()))
+	} else if period < MinRequeuePeriod {
+		allErrs = append(allErrs,
+			field.Invalid(path, value, fmt.Sprintf("must be at least %s", MinRequeuePeriod)))
+	}
+	return allErrs
+}
+
+// validateStorage rejects emptyDir data directories unless the operator
+// allows them, their data is lost with every Pod, and storage hints that can
+// not be met
+func (r *Cluster) validateStorage() field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec").Child("storage")
+	storage := r.Spec.Storage
+	if storage.EmptyDir != nil && !storage.IsEmptyDir() {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("emptyDir"),
+				storage.EmptyDir,
+				"requires the emptyDir storage type"))
+	}
+	if storage.IsEmptyDir() && !AllowEmptyDirStorage {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("type"),
+				"emptyDir storage is not durable and has to be allowed with the --allow-emptydir-storage flag of the operator"))
+	}
+	if storage.IsEmptyDir() && storage.PVCRetentionPolicy != nil {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("pvcRetentionPolicy"),
+				storage.PVCRetentionPolicy,
+				"emptyDir storage has no PersistentVolumeClaims"))
+	}
+	if hints := storage.Hints; hints != nil {
+		if storage.IsEmptyDir() && len(hints.MountOptions) > 0 {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("hints").Child("mountOptions"),
+					hints.MountOptions,
+					"emptyDir storage is mounted by the kubelet without mount options"))
+		}
+		for i, option := range hints.MountOptions {
+			if !mountOptionPattern.MatchString(option) {
+				allErrs = append(allErrs,
+					field.Invalid(path.Child("hints").Child("mountOptions").Index(i), option,
+						"must be a single mount option, e.g. noatime"))
+			}
+		}
+		if tuning := r.Spec.NodeTuning; tuning != nil && tuning.DiskScheduler != "" &&
+			hints.DiskScheduler != "" && hints.DiskScheduler != tuning.DiskScheduler {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("hints").Child("diskScheduler"),
+					hints.DiskScheduler,
+					fmt.Sprintf("the node tuning sets the %s scheduler", tuning.DiskScheduler)))
+		}
+	}
+	return allErrs
+}
+
+// validateSuperusers rejects empty and repeated superuser names
+func (r *Cluster) validateSuperusers() field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec").Child("superUsers")
+	seen := make(map[string]bool, len(r.Spec.Superusers))
+	for i, user := range r.Spec.Superusers {
+		name := strings.TrimSpace(user.Username)
+		switch {
+		case name == "":
+			allErrs = append(allErrs, field.Required(path.Index(i).Child("username"), "the superuser needs a name"))
+		case seen[name]:
+			allErrs = append(allErrs, field.Duplicate(path.Index(i).Child("username"), user.Username))
+		}
+		seen[name] = true
+	}
+	return allErrs
+}
+
+// validateConfigMapReferences verifies that the ConfigMaps referenced by the
+// cluster exist and hold the referenced keys, so a typo is reported before
+// the reconciliation fails
+func (r *Cluster) validateConfigMapReferences() field.ErrorList {
+	var allErrs field.ErrorList
+	if clusterReader == nil {
+		return allErrs
+	}
+	type reference struct {
+		path *field.Path
+		ref  *corev1.ConfigMapKeySelector
+	}
+	var refs []reference
+	if r.Spec.SuperusersFrom != nil {
+		refs = append(refs, reference{field.NewPath("spec").Child("superUsersFrom"), r.Spec.SuperusersFrom})
+	}
+	if r.Spec.Reactor != nil && r.Spec.Reactor.IOPropertiesFrom != nil {
+		refs = append(refs, reference{field.NewPath("spec").Child("reactor").Child("ioPropertiesFrom"), r.Spec.Reactor.IOPropertiesFrom})
+	}
+	for _, reference := range refs {
+		path, ref := reference.path, reference.ref
+		var cm corev1.ConfigMap
+		err := clusterReader.Get(context.Background(), client.ObjectKey{Name: ref.Name, Namespace: r.Namespace}, &cm)
+		switch {
+		case apierrors.IsNotFound(err):
+			allErrs = append(allErrs,
+				field.NotFound(path.Child("name"), ref.Name))
+		case err != nil:
+			allErrs = append(allErrs,
+				field.InternalError(path.Child("name"),
+					fmt.Errorf("unable to get configmap: %w", err)))
+		default:
+			if _, ok := cm.Data[ref.Key]; !ok {
+				allErrs = append(allErrs,
+					field.Invalid(path.Child("key"),
+						ref.Key,
+						fmt.Sprintf("configmap %s has no key %s", ref.Name, ref.Key)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateReactor verifies the reactor backend and the disk properties and
+// rejects additional arguments setting them as well
+func (r *Cluster) validateReactor() field.ErrorList {
+	var allErrs field.ErrorList
+	reactor := r.Spec.Reactor
+	if reactor == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("reactor")
+	switch reactor.Backend {
+	case "", ReactorBackendLinuxAIO, ReactorBackendEpoll:
+	default:
+		allErrs = append(allErrs,
+			field.NotSupported(path.Child("backend"), reactor.Backend, []string{
+				string(ReactorBackendLinuxAIO),
+				string(ReactorBackendEpoll),
+			}))
+	}
+	if reactor.IOProperties != "" && reactor.IOPropertiesFrom != nil {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("ioPropertiesFrom"),
+				"ioProperties and ioPropertiesFrom cannot be set at the same time"))
+	}
+	if reactor.IOProperties != "" {
+		var props map[string]interface{}
+		if err := yaml.Unmarshal([]byte(reactor.IOProperties), &props); err != nil {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("ioProperties"), reactor.IOProperties, err.Error()))
+		} else if _, ok := props["disks"]; !ok {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("ioProperties"),
+					reactor.IOProperties,
+					"has to list the disks in the format of rpk iotune"))
+		}
+	}
+	for i, arg := range r.Spec.AdditionalCommandLineArguments {
+		flag := flagName(arg)
+		if (flag == reactorBackendFlag && reactor.Backend != "") ||
+			((flag == ioPropertiesFlag || flag == ioPropertiesFileFlag) && reactor.IOPropertiesSet()) {
+			allErrs = append(allErrs,
+				field.Invalid(field.NewPath("spec").Child("additionalCommandLineArguments").Index(i),
+					arg,
+					fmt.Sprintf("%s is already set by the reactor configuration", flag)))
+		}
+	}
+	return allErrs
+}
+
+// validateNodeTuning requires the feature gate, the privileged opt-in and a
+// node selector, so the tuning never runs on every node of the cluster, and
+// rejects kernel parameters and devices the tuning script can not write
+func (r *Cluster) validateNodeTuning() field.ErrorList {
+	var allErrs field.ErrorList
+	tuning := r.Spec.NodeTuning
+	if tuning == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("nodeTuning")
+	if !r.FeatureGateEnabled(FeatureGateNodeTuning) {
+		allErrs = append(allErrs,
+			field.Forbidden(path, "node tuning requires the "+FeatureGateNodeTuning+" feature gate"))
+	}
+	if !tuning.Privileged {
+		allErrs = append(allErrs,
+			field.Required(path.Child("privileged"),
+				"the tuning runs privileged containers on the host network of the nodes"))
+	}
+	if len(tuning.NodeSelector) == 0 && len(r.BrokerNodeSelector()) == 0 {
+		allErrs = append(allErrs,
+			field.Required(path.Child("nodeSelector"),
+				"either the node tuning or the brokers have to select the nodes"))
+	}
+	if len(tuning.Sysctls) == 0 && tuning.DiskScheduler == "" {
+		allErrs = append(allErrs,
+			field.Required(path, "at least one sysctl or the disk scheduler has to be set"))
+	}
+	for key, value := range tuning.Sysctls {
+		if !sysctlKeyPattern.MatchString(key) {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("sysctls").Key(key), key,
+					"must be a dotted kernel parameter, e.g. net.core.rmem_max"))
+		}
+		if !sysctlValuePattern.MatchString(value) {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("sysctls").Key(key), value,
+					"must be letters, digits, spaces, '.', '_', ':', ',' and '-'"))
+		}
+	}
+	if tuning.DiskScheduler != "" && len(tuning.Devices) == 0 {
+		allErrs = append(allErrs,
+			field.Required(path.Child("devices"), "the disk scheduler is set on the devices"))
+	}
+	if tuning.DiskScheduler == "" && len(tuning.Devices) > 0 {
+		allErrs = append(allErrs,
+			field.Required(path.Child("diskScheduler"), "the devices are only tuned by the disk scheduler"))
+	}
+	for i, device := range tuning.Devices {
+		if !blockDevicePattern.MatchString(device) {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("devices").Index(i), device,
+					"must be the name of a block device, e.g. nvme0n1"))
+		}
+	}
+	return allErrs
+}
+
+// validateInitContainers rejects init containers whose name collides with
+// another container of the broker Pods and unknown positions
+func (r *Cluster) validateInitContainers() field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec").Child("initContainers")
+	names := map[string]bool{}
+	for i, c := range r.Spec.InitContainers {
+		switch {
+		case managedInitContainers[c.Name] || c.Name == r.RedpandaContainerName():
+			allErrs = append(allErrs,
+				field.Invalid(path.Index(i).Child("name"), c.Name,
+					"the name is used by a container of the operator"))
+		case names[c.Name]:
+			allErrs = append(allErrs, field.Duplicate(path.Index(i).Child("name"), c.Name))
+		}
+		names[c.Name] = true
+		switch c.Position {
+		case "", InitContainerBeforeOperator, InitContainerAfterOperator:
+		default:
+			allErrs = append(allErrs,
+				field.NotSupported(path.Index(i).Child("position"), c.Position, []string{
+					string(InitContainerBeforeOperator),
+					string(InitContainerAfterOperator),
+				}))
+		}
+	}
+	return allErrs
+}
+
+// validateMemoryLocking verifies that the huge pages hold the memory of
+// Redpanda and that enough nodes provide them, one broker runs per node
+func (r *Cluster) validateMemoryLocking() field.ErrorList {
+	var allErrs field.ErrorList
+	locking := r.Spec.MemoryLocking
+	if locking == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("memoryLocking")
+	for i, arg := range r.Spec.AdditionalCommandLineArguments {
+		flag := flagName(arg)
+		if (flag == "--lock-memory" && locking.LockMemory) || (flag == "--hugepages" && locking.HugePages != nil) {
+			allErrs = append(allErrs,
+				field.Invalid(field.NewPath("spec").Child("additionalCommandLineArguments").Index(i),
+					arg,
+					fmt.Sprintf("%s is already set by memory locking", flag)))
+		}
+	}
+
+	hugePages := locking.HugePages
+	if hugePages == nil {
+		return allErrs
+	}
+	hugePagesPath := path.Child("hugePages")
+	pageSize, err := resource.ParseQuantity(hugePages.PageSize)
+	if err != nil {
+		return append(allErrs,
+			field.Invalid(hugePagesPath.Child("pageSize"), hugePages.PageSize, err.Error()))
+	}
+	size := hugePages.Size.Value()
+	if size <= 0 || size%pageSize.Value() != 0 {
+		return append(allErrs,
+			field.Invalid(hugePagesPath.Child("size"),
+				hugePages.Size.String(),
+				fmt.Sprintf("has to be a positive multiple of the page size %s", hugePages.PageSize)))
+	}
+	memory, ok := r.RedpandaMemory()
+	if !ok {
+		reserved := resource.MustParse(ReserveMemoryString)
+		memory = r.Spec.Resources.Limits.Memory().Value() - reserved.Value()
+	}
+	if size < memory {
+		allErrs = append(allErrs,
+			field.Invalid(hugePagesPath.Child("size"),
+				hugePages.Size.String(),
+				fmt.Sprintf("has to cover the %dM of memory of Redpanda", memory/mb)))
+	}
+	return append(allErrs, r.validateHugePagesNodes()...)
+}
+
+// validateHugePagesNodes rejects huge pages when fewer nodes than brokers
+// have them allocatable, the brokers would not be scheduled
+func (r *Cluster) validateHugePagesNodes() field.ErrorList {
+	var allErrs field.ErrorList
+	if clusterReader == nil || r.Spec.Replicas == nil {
+		return allErrs
+	}
+	hugePages := r.Spec.MemoryLocking.HugePages
+	path := field.NewPath("spec").Child("memoryLocking").Child("hugePages")
+
+	var nodes corev1.NodeList
+	err := clusterReader.List(context.Background(), &nodes, client.MatchingLabels(r.BrokerNodeSelector()))
+	if err != nil {
+		return append(allErrs,
+			field.InternalError(path, fmt.Errorf("unable to list nodes: %w", err)))
+	}
+	available := 0
+	for i := range nodes.Items {
+		allocatable, ok := nodes.Items[i].Status.Allocatable[hugePages.ResourceName()]
+		if ok && allocatable.Cmp(hugePages.Size) >= 0 {
+			available++
+		}
+	}
+	if available < int(*r.Spec.Replicas) {
+		allErrs = append(allErrs,
+			field.Invalid(path,
+				hugePages.Size.String(),
+				fmt.Sprintf("only %d nodes have %s of allocatable %s for %d brokers",
+					available, hugePages.Size.String(), hugePages.ResourceName(), *r.Spec.Replicas)))
+	}
+	return allErrs
+}
+
+// validateUniqueExternalSubdomains rejects external subdomains used by another
+// cluster, their per broker DNS records would collide
+func (r *Cluster) validateUniqueExternalSubdomains() field.ErrorList {
+	var allErrs field.ErrorList
+	if clusterReader == nil || !r.Spec.ExternalConnectivity.Enabled {
+		return allErrs
+	}
+	subdomains := r.ExternalDNSHostnames()
+	if len(subdomains) == 0 {
+		return allErrs
+	}
+
+	var clusters ClusterList
+	if err := clusterReader.List(context.Background(), &clusters); err != nil {
+		return append(allErrs,
+			field.InternalError(field.NewPath("spec").Child("externalConnectivity"),
+				fmt.Errorf("unable to list clusters: %w", err)))
+	}
+	path := field.NewPath("spec").Child("externalConnectivity").Child("subdomain")
+	for i := range clusters.Items {
+		other := &clusters.Items[i]
+		if (other.Name == r.Name && other.Namespace == r.Namespace) || !other.Spec.ExternalConnectivity.Enabled {
+			continue
+		}
+		for _, subdomain := range other.ExternalDNSHostnames() {
+			if contains(subdomains, subdomain) {
+				allErrs = append(allErrs,
+					field.Invalid(path,
+						subdomain,
+						fmt.Sprintf("subdomain is already used by cluster %s/%s, enable clusterScopedRecords to share it", other.Namespace, other.Name)))
+			}
+		}
+	}
+	return allErrs
+}
+
+func (r *Cluster) validateExternalSubdomains() field.ErrorList {
+	var allErrs field.ErrorList
+	extConn := r.Spec.ExternalConnectivity
+	path := field.NewPath("spec").Child("externalConnectivity").Child("subdomains")
+	if len(extConn.Subdomains) == 0 {
+		return allErrs
+	}
+	if !extConn.Enabled || extConn.Subdomain == "" {
+		allErrs = append(allErrs,
+			field.Invalid(path,
+				extConn.Subdomains,
+				"additional subdomains require enabled external connectivity with the advertised subdomain set"))
+	}
+	seen := map[string]bool{extConn.Subdomain: true}
+	for i, subdomain := range extConn.Subdomains {
+		for _, msg := range validation.IsDNS1123Subdomain(subdomain) {
+			allErrs = append(allErrs, field.Invalid(path.Index(i), subdomain, msg))
+		}
+		if seen[subdomain] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(i), subdomain))
+		}
+		seen[subdomain] = true
+	}
+	return allErrs
+}
+
+// validateExternalDNS verifies the external-dns annotations, the hostname is
+// managed by the operator and the TTL has to be a positive number of seconds
+func (r *Cluster) validateExternalDNS() field.ErrorList {
+	var allErrs field.ErrorList
+	extConn := r.Spec.ExternalConnectivity
+	if extConn.ExternalDNS == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("externalConnectivity").Child("externalDNS")
+	if !extConn.Enabled && extConn.Subdomain == "" {
+		allErrs = append(allErrs,
+			field.Forbidden(path, "external-dns annotations require external connectivity"))
+	}
+	if ttl := extConn.ExternalDNS.TTL; ttl != nil && *ttl < 1 {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("ttl"), *ttl, "must be a positive number of seconds"))
+	}
+	keys := make([]string, 0, len(extConn.ExternalDNS.Annotations))
+	for k := range extConn.ExternalDNS.Annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		keyPath := path.Child("annotations").Key(k)
+		for _, msg := range validation.IsQualifiedName(k) {
+			allErrs = append(allErrs, field.Invalid(keyPath, k, msg))
+		}
+		switch k {
+		case externalDNSHostnameAnnotation:
+			allErrs = append(allErrs,
+				field.Forbidden(keyPath, "the hostname is set by the operator from the subdomains"))
+		case externalDNSTTLAnnotation:
+			v := extConn.ExternalDNS.Annotations[k]
+			if ttl, err := strconv.Atoi(v); err != nil || ttl < 1 {
+				allErrs = append(allErrs,
+					field.Invalid(keyPath, v, "must be a positive number of seconds"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateExternalAdvertisedPort rejects an advertised port, node address
+// annotation or advertised addresses source without an external listener to
+// advertise them on. The addresses of the source replace the subdomain and the
+// node addresses.
+func (r *Cluster) validateExternalAdvertisedPort() field.ErrorList {
+	var allErrs field.ErrorList
+	extConn := r.Spec.ExternalConnectivity
+	if extConn.AdvertisedPort != 0 && !extConn.Enabled {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("externalConnectivity").Child("advertisedPort"),
+				extConn.AdvertisedPort,
+				"the advertised port requires enabled external connectivity"))
+	}
+	if annotation := extConn.NodeAddressAnnotation; annotation != "" {
+		path := field.NewPath("spec").Child("externalConnectivity").Child("nodeAddressAnnotation")
+		if !extConn.Enabled {
+			allErrs = append(allErrs,
+				field.Invalid(path, annotation, "the node address annotation requires enabled external connectivity"))
+		}
+		for _, msg := range validation.IsQualifiedName(annotation) {
+			allErrs = append(allErrs, field.Invalid(path, annotation, msg))
+		}
+	}
+	if from := extConn.AdvertisedAddressesFrom; from != nil {
+		path := field.NewPath("spec").Child("externalConnectivity").Child("advertisedAddressesFrom")
+		if !extConn.Enabled {
+			allErrs = append(allErrs,
+				field.Forbidden(path, "advertised addresses require enabled external connectivity"))
+		}
+		if (from.ConfigMapName == "") == (from.SecretName == "") {
+			allErrs = append(allErrs,
+				field.Invalid(path, *from, "exactly one of configMapName and secretName has to be set"))
+		}
+		if from.ConfigMapName != "" {
+			for _, msg := range validation.IsDNS1123Subdomain(from.ConfigMapName) {
+				allErrs = append(allErrs, field.Invalid(path.Child("configMapName"), from.ConfigMapName, msg))
+			}
+		}
+		if from.SecretName != "" {
+			for _, msg := range validation.IsDNS1123Subdomain(from.SecretName) {
+				allErrs = append(allErrs, field.Invalid(path.Child("secretName"), from.SecretName, msg))
+			}
+		}
+		if extConn.Subdomain != "" || extConn.NodeAddressAnnotation != "" || r.PandaproxyExternalSubdomain() != "" {
+			allErrs = append(allErrs,
+				field.Forbidden(path, "the advertised addresses replace the subdomains and the node address annotation"))
+		}
+	}
+	return allErrs
+}
+
+// validatePandaproxyAPI verifies the Pandaproxy listeners. The ports must not
+// collide with the other listeners, the external listener needs external
+// connectivity and its certificate can only be issued for a subdomain.
+// Pandaproxy connects to the internal Kafka API listener without TLS or SASL.
+func (r *Cluster) validatePandaproxyAPI() field.ErrorList {
+	var allErrs field.ErrorList
+	proxy := r.Spec.Configuration.PandaproxyAPI
+	if proxy == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("configuration").Child("pandaproxyApi")
+	conf := r.Spec.Configuration
+
+	used := map[int]string{
+		conf.KafkaAPI.Port:  "Spec.Configuration.KafkaAPI.Port",
+		conf.AdminAPI.Port:  "Spec.Configuration.AdminAPI.Port",
+		conf.RPCServer.Port: "Spec.Configuration.RPCServer.Port",
+	}
+	if r.Spec.ExternalConnectivity.Enabled {
+		used[conf.KafkaAPI.Port+1] = "the external Kafka API that is not visible in the Cluster CR"
+	}
+	if other, ok := used[proxy.Port]; ok {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("port"), proxy.Port, "pandaproxy port collide with "+other))
+	}
+	if other, ok := used[proxy.Port+1]; ok && r.PandaproxyExternal() {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("port"), proxy.Port,
+				"external pandaproxy port that is not visible in the Cluster CR collide with "+other))
+	}
+
+	external := proxy.External
+	if external.Enabled && !r.Spec.ExternalConnectivity.Enabled {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("external").Child("enabled"),
+				"the external listener requires externalConnectivity to be enabled"))
+	}
+	if external.Subdomain != "" {
+		if !external.Enabled {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("external").Child("subdomain"), external.Subdomain,
+					"the subdomain requires the external listener to be enabled"))
+		}
+		for _, msg := range validation.IsDNS1123Subdomain(external.Subdomain) {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("external").Child("subdomain"), external.Subdomain, msg))
+		}
+	}
+
+	if proxy.TLS.RequireClientAuth && !proxy.TLS.Enabled {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("tls").Child("requireClientAuth"), proxy.TLS.RequireClientAuth,
+				"Enabled has to be set to true for RequireClientAuth to be allowed to be true"))
+	}
+	if proxy.TLS.Enabled && r.PandaproxyExternal() && r.PandaproxyExternalSubdomain() == "" {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("tls").Child("enabled"),
+				"the certificate of the external listener requires a subdomain, the node addresses can not be requested"))
+	}
+
+	if r.KafkaAuthenticationMethod() == KafkaAuthenticationSASL {
+		allErrs = append(allErrs,
+			field.Forbidden(path, "the Pandaproxy client does not support SASL authentication"))
+	}
+	if r.InternalKafkaTLS() {
+		allErrs = append(allErrs,
+			field.Forbidden(path, "Pandaproxy requires the internal Kafka API listener without TLS"))
+	}
+	return allErrs
+}
+
+// validateListenerNames verifies that the Kafka listeners are named apart,
+// the format of the names is checked by the CRD schema
+func (r *Cluster) validateListenerNames() field.ErrorList {
+	var allErrs field.ErrorList
+	kafkaAPI := r.Spec.Configuration.KafkaAPI
+	path := field.NewPath("spec").Child("configuration").Child("kafkaApi")
+	if kafkaAPI.ExternalListenerName != "" && !r.Spec.ExternalConnectivity.Enabled {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("externalListenerName"),
+				"the external listener requires externalConnectivity to be enabled"))
+	}
+	if r.Spec.ExternalConnectivity.Enabled &&
+		strings.EqualFold(r.InternalListenerName(), r.ExternalListenerName()) {
+		allErrs = append(allErrs,
+			field.Duplicate(path.Child("externalListenerName"), r.ExternalListenerName()))
+	}
+	return allErrs
+}
+
+// validateKafkaAuthentication verifies that the explicit authentication
+// method agrees with the SASL and TLS settings
+func (r *Cluster) validateKafkaAuthentication() field.ErrorList {
+	var allErrs field.ErrorList
+	method := r.Spec.Configuration.KafkaAPI.AuthenticationMethod
+	path := field.NewPath("spec").Child("configuration").Child("kafkaApi").Child("authenticationMethod")
+	tls := r.Spec.Configuration.TLS.KafkaAPI
+	switch method {
+	case "", KafkaAuthenticationSASL:
+	case KafkaAuthenticationNone:
+		if r.Spec.EnableSASL {
+			allErrs = append(allErrs,
+				field.Invalid(path, method, "enableSasl requires the sasl authentication method"))
+		}
+		if tls.RequireClientAuth {
+			allErrs = append(allErrs,
+				field.Invalid(path, method, "TLS client authentication requires the mtls authentication method"))
+		}
+	case KafkaAuthenticationMTLS:
+		if !tls.Enabled || !tls.RequireClientAuth {
+			allErrs = append(allErrs,
+				field.Invalid(path, method, "mtls requires Kafka API TLS with requireClientAuth enabled"))
+		}
+		if r.Spec.EnableSASL {
+			allErrs = append(allErrs,
+				field.Invalid(path, method, "enableSasl requires the sasl authentication method"))
+		}
+	default:
+		allErrs = append(allErrs,
+			field.NotSupported(path, method, []string{
+				string(KafkaAuthenticationNone),
+				string(KafkaAuthenticationSASL),
+				string(KafkaAuthenticationMTLS),
+			}))
+	}
+	return allErrs
+}
+
+// validateKafkaExternalListener verifies that the TLS and authentication
+// settings of the independent external listener agree with each other and
+// that it authenticates like the internal listener
+func (r *Cluster) validateKafkaExternalListener() field.ErrorList {
+	var allErrs field.ErrorList
+	listener := r.Spec.Configuration.KafkaAPI.ExternalListener
+	if listener == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("configuration").Child("kafkaApi").Child("externalListener")
+	if !r.Spec.ExternalConnectivity.Enabled {
+		allErrs = append(allErrs,
+			field.Forbidden(path, "the external listener requires externalConnectivity to be enabled"))
+	}
+
+	tls := listener.TLS
+	tlsPath := path.Child("tls")
+	if tls.RequireClientAuth && !tls.Enabled {
+		allErrs = append(allErrs,
+			field.Invalid(tlsPath.Child("requireClientAuth"), tls.RequireClientAuth,
+				"Enabled has to be set to true for RequireClientAuth to be allowed to be true"))
+	}
+	if tls.IssuerRef != nil && !tls.Enabled {
+		allErrs = append(allErrs,
+			field.Invalid(tlsPath.Child("issuerRef"), tls.IssuerRef,
+				"Enabled has to be set to true for the issuer to be used"))
+	}
+	if tls.TruststoreSecretRef != nil && !tls.RequireClientAuth {
+		allErrs = append(allErrs,
+			field.Invalid(tlsPath.Child("truststoreSecretRef"), tls.TruststoreSecretRef.Name,
+				"the truststore verifies client certificates, requireClientAuth has to be set to true"))
+	}
+	if tls.Enabled && r.Spec.ExternalConnectivity.Subdomain == "" {
+		allErrs = append(allErrs,
+			field.Forbidden(tlsPath.Child("enabled"),
+				"the certificate of the external listener requires a subdomain, the node addresses can not be requested"))
+	}
+
+	method := listener.AuthenticationMethod
+	methodPath := path.Child("authenticationMethod")
+	switch method {
+	case "":
+	case KafkaAuthenticationNone, KafkaAuthenticationSASL:
+		if tls.RequireClientAuth {
+			allErrs = append(allErrs,
+				field.Invalid(methodPath, method, "TLS client authentication requires the mtls authentication method"))
+		}
+	case KafkaAuthenticationMTLS:
+		if !tls.Enabled || !tls.RequireClientAuth {
+			allErrs = append(allErrs,
+				field.Invalid(methodPath, method, "mtls requires TLS with requireClientAuth enabled"))
+		}
+	default:
+		allErrs = append(allErrs,
+			field.NotSupported(methodPath, method, []string{
+				string(KafkaAuthenticationNone),
+				string(KafkaAuthenticationSASL),
+				string(KafkaAuthenticationMTLS),
+			}))
+		return allErrs
+	}
+	// the brokers read no authentication method per listener, SASL is
+	// enabled for all of them
+	if external, internal := r.ExternalKafkaAuthenticationMethod(), r.KafkaAuthenticationMethod(); r.KafkaExternalListener() != nil && external != internal {
+		allErrs = append(allErrs,
+			field.Invalid(methodPath, external,
+				fmt.Sprintf("Redpanda applies one authentication method to all Kafka API listeners, it has to match the %s method of the internal listener", internal)))
+	}
+	return allErrs
+}
+
+// validateAdditionalArguments rejects arguments and environment variables
+// that collide with the ones managed by the operator
+func (r *Cluster) validateAdditionalArguments() field.ErrorList {
+	var allErrs field.ErrorList
+	argsPath := field.NewPath("spec").Child("additionalCommandLineArguments")
+	for i, arg := range r.Spec.AdditionalCommandLineArguments {
+		flag := flagName(arg)
+		if managedFlags[flag] {
+			allErrs = append(allErrs,
+				field.Invalid(argsPath.Index(i),
+					arg,
+					fmt.Sprintf("%s is managed by the operator", flag)))
+		}
+		if flag == unsafeBypassFsyncFlag && r.Spec.Durability.IsRelaxed() {
+			allErrs = append(allErrs,
+				field.Invalid(argsPath.Index(i),
+					arg,
+					fmt.Sprintf("%s is already set by relaxed durability", flag)))
+		}
+		if dangerousFlags[flag] {
+			log.Info("Cluster uses a flag that is not safe for production", "name", r.Name, "flag", flag)
+		}
+	}
+	if r.Spec.Durability.IsRelaxed() {
+		log.Info("WARNING: Cluster uses relaxed durability, acknowledged writes can be lost on failures. Do not use it in production", "name", r.Name)
+	}
+	envPath := field.NewPath("spec").Child("env")
+	for i, env := range r.Spec.Env {
+		if managedEnv[env.Name] {
+			allErrs = append(allErrs,
+				field.Invalid(envPath.Index(i).Child("name"),
+					env.Name,
+					"environment variable is managed by the operator"))
+		}
+	}
+	return allErrs
+}
+
+// validatePerBrokerConfig verifies that overrides target existing brokers
+// and node level properties only
+func (r *Cluster) validatePerBrokerConfig() field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec").Child("perBrokerConfig")
+	var replicas int32
+	if r.Spec.Replicas != nil {
+		replicas = *r.Spec.Replicas
+	}
+	for ordinal, overrides := range r.Spec.PerBrokerConfig {
+		i, err := strconv.Atoi(ordinal)
+		if err != nil || i < 0 || int32(i) >= replicas {
+			allErrs = append(allErrs,
+				field.Invalid(path.Key(ordinal),
+					ordinal,
+					fmt.Sprintf("must be the ordinal of a broker between 0 and %d", replicas-1)))
+		}
+		for key := range overrides {
+			if !nodeLevelProperties[key] {
+				allErrs = append(allErrs,
+					field.Invalid(path.Key(ordinal).Key(key),
+						key,
+						"only node level properties can be set per broker"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// flagName returns the flag of arguments in the --flag, --flag=value or
+// --flag value form
+func flagName(arg string) string {
+	fields := strings.FieldsFunc(arg, func(c rune) bool {
+		return c == '=' || c == ' '
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// validatePodMonitor verifies the scrape interval and the metrics path
+func (r *Cluster) validatePodMonitor() field.ErrorList {
+	var allErrs field.ErrorList
+	podMonitor := r.Spec.PodMonitor
+	if podMonitor == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("podMonitor")
+	if podMonitor.Interval != nil && podMonitor.Interval.Duration < time.Second {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("interval"), podMonitor.Interval.Duration.String(), "must be at least 1s"))
+	}
+	if podMonitor.Path != "" && !strings.HasPrefix(podMonitor.Path, "/") {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("path"), podMonitor.Path, "must be an absolute path"))
+	}
+	return allErrs
+}
+
+// validateReplication verifies the source Cluster and the topic patterns of
+// the replication. MirrorMaker names the clusters after the Clusters, the
+// source needs another name than this cluster.
+func (r *Cluster) validateReplication() field.ErrorList {
+	var allErrs field.ErrorList
+	replication := r.Spec.Replication
+	if replication == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("replication")
+	if replication.Image == "" {
+		allErrs = append(allErrs,
+			field.Required(path.Child("image"), "the MirrorMaker image is required"))
+	}
+	switch replication.Source.Name {
+	case "":
+		allErrs = append(allErrs,
+			field.Required(path.Child("source").Child("name"), "the source Cluster is required"))
+	case r.Name:
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("source").Child("name"),
+				replication.Source.Name,
+				"the source Cluster needs another name than this cluster"))
+	}
+	for _, patterns := range []struct {
+		name  string
+		value string
+	}{
+		{"topics", replication.Topics},
+		{"topicsExclude", replication.TopicsExclude},
+	} {
+		if _, err := compileTopicPatterns(patterns.value); err != nil {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child(patterns.name), patterns.value, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// compileTopicPatterns compiles a comma separated list of regular
+// expressions, each matching whole topic names like the patterns of
+// MirrorMaker
+func compileTopicPatterns(patterns string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// validateDedicatedNodes verifies the taint and label of the dedicated nodes
+// and that the node selector does not require another value of the label
+func (r *Cluster) validateDedicatedNodes() field.ErrorList {
+	var allErrs field.ErrorList
+	dedicated := r.Spec.DedicatedNodes
+	if dedicated == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("dedicatedNodes")
+	if dedicated.Key == "" {
+		allErrs = append(allErrs,
+			field.Required(path.Child("key"), "the key of the taint and label of the dedicated nodes is required"))
+	} else {
+		for _, msg := range validation.IsQualifiedName(dedicated.Key) {
+			allErrs = append(allErrs, field.Invalid(path.Child("key"), dedicated.Key, msg))
+		}
+	}
+	if dedicated.Value == "" {
+		allErrs = append(allErrs,
+			field.Required(path.Child("value"), "the value of the taint and label of the dedicated nodes is required"))
+	} else {
+		for _, msg := range validation.IsValidLabelValue(dedicated.Value) {
+			allErrs = append(allErrs, field.Invalid(path.Child("value"), dedicated.Value, msg))
+		}
+	}
+	if v, ok := r.Spec.NodeSelector[dedicated.Key]; ok && v != dedicated.Value {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("nodeSelector").Key(dedicated.Key), v,
+				"the node selector requires another value than the dedicated nodes"))
+	}
+	return allErrs
+}
+
+// validatePodAffinity rejects required pod affinity terms that would force
+// brokers onto the same node, which contradicts the anti-affinity that
+// spreads them across nodes
+func (r *Cluster) validatePodAffinity() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.PodAffinity == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("podAffinity").Child("requiredDuringSchedulingIgnoredDuringExecution")
+	for i, term := range r.Spec.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if term.TopologyKey == "" {
+			allErrs = append(allErrs, field.Required(path.Index(i).Child("topologyKey"), "topologyKey must not be empty"))
+			continue
+		}
+		if term.TopologyKey != corev1.LabelHostname || !r.selectsBrokers(term) {
+			continue
+		}
+		allErrs = append(allErrs,
+			field.Invalid(path.Index(i),
+				term.LabelSelector,
+				"pod affinity must not require co-location with other brokers of the cluster"))
+	}
+	return allErrs
+}
+
+// selectsBrokers returns true when the affinity term matches the Redpanda
+// Pods of this cluster
+func (r *Cluster) selectsBrokers(term corev1.PodAffinityTerm) bool {
+	if len(term.Namespaces) > 0 && !contains(term.Namespaces, r.Namespace) {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+	if err != nil {
+		return false
+	}
+	// keep in sync with the selector labels in pkg/labels
+	return selector.Matches(labels.Set{
+		"app.kubernetes.io/name":      "redpanda",
+		"app.kubernetes.io/instance":  r.Name,
+		"app.kubernetes.io/component": "redpanda",
+	})
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Cluster) validateTLS() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth && !r.Spec.Configuration.TLS.KafkaAPI.Enabled {
+		allErrs = append(allErrs,
+			field.Invalid(
+				field.NewPath("spec").Child("configuration").Child("tls").Child("requireclientauth"),
+				r.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth,
+				"Enabled has to be set to true for RequireClientAuth to be allowed to be true"))
+	}
+	if r.Spec.Configuration.TLS.KafkaAPI.IssuerRef != nil && r.Spec.Configuration.TLS.KafkaAPI.NodeSecretRef != nil {
+		allErrs = append(allErrs,
+			field.Invalid(
+				field.NewPath("spec").Child("configuration").Child("tls").Child("nodeSecretRef"),
+				r.Spec.Configuration.TLS.KafkaAPI.NodeSecretRef,
+				"Cannot provide both IssuerRef and NodeSecretRef"))
+	}
+	rpcTLS := r.Spec.Configuration.TLS.RPC
+	rpcPath := field.NewPath("spec").Child("configuration").Child("tls").Child("rpc")
+	if rpcTLS.IssuerRef != nil && rpcTLS.NodeSecretRef != nil {
+		allErrs = append(allErrs,
+			field.Invalid(rpcPath.Child("nodeSecretRef"),
+				rpcTLS.NodeSecretRef,
+				"Cannot provide both IssuerRef and NodeSecretRef"))
+	}
+	if rpcTLS.NodeSecretRef != nil && rpcTLS.NodeSecretRef.Namespace != "" && rpcTLS.NodeSecretRef.Namespace != r.Namespace {
+		allErrs = append(allErrs,
+			field.Invalid(rpcPath.Child("nodeSecretRef").Child("namespace"),
+				rpcTLS.NodeSecretRef.Namespace,
+				"the RPC node certificate has to be in the namespace of the cluster"))
+	}
+	return allErrs
+}
+
+// cleanupPolicies are the values of the log_cleanup_policy property
+var cleanupPolicies = []string{"delete", "compact", "compact,delete"}
+
+// validateCompaction verifies the cleanup policy
+func (r *Cluster) validateCompaction() field.ErrorList {
+	var allErrs field.ErrorList
+	compaction := r.Spec.Configuration.Compaction
+	path := field.NewPath("spec").Child("configuration").Child("compaction")
+	if policy := compaction.CleanupPolicy; policy != "" {
+		supported := false
+		for _, p := range cleanupPolicies {
+			supported = supported || p == policy
+		}
+		if !supported {
+			allErrs = append(allErrs,
+				field.NotSupported(path.Child("cleanupPolicy"), policy, cleanupPolicies))
+		}
 	}
 	return allErrs
 }
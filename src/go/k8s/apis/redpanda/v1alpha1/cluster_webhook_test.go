@@ -10,7 +10,9 @@
 package v1alpha1_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	"github.com/stretchr/testify/assert"
@@ -35,7 +37,7 @@ func TestValidateUpdate(t *testing.T) {
 		Spec: v1alpha1.ClusterSpec{
 			Replicas: pointer.Int32Ptr(replicas2),
 			Configuration: v1alpha1.RedpandaConfig{
-				KafkaAPI: v1alpha1.SocketAddress{Port: 123},
+				KafkaAPI: v1alpha1.KafkaAPI{Port: 123},
 			},
 			Resources: corev1.ResourceRequirements{
 				Limits: corev1.ResourceList{
@@ -52,7 +54,7 @@ func TestValidateUpdate(t *testing.T) {
 	updatedCluster := redpandaCluster.DeepCopy()
 	updatedCluster.Spec.Replicas = &replicas1
 	updatedCluster.Spec.Configuration = v1alpha1.RedpandaConfig{
-		KafkaAPI: v1alpha1.SocketAddress{Port: 1234},
+		KafkaAPI: v1alpha1.KafkaAPI{Port: 1234},
 		TLS: v1alpha1.TLSConfig{
 			KafkaAPI: v1alpha1.KafkaAPITLS{
 				RequireClientAuth: true,
@@ -106,7 +108,7 @@ func TestValidateUpdate_NoError(t *testing.T) {
 		Spec: v1alpha1.ClusterSpec{
 			Replicas: pointer.Int32Ptr(replicas2),
 			Configuration: v1alpha1.RedpandaConfig{
-				KafkaAPI:  v1alpha1.SocketAddress{Port: 123},
+				KafkaAPI:  v1alpha1.KafkaAPI{Port: 123},
 				AdminAPI:  v1alpha1.SocketAddress{Port: 125},
 				RPCServer: v1alpha1.SocketAddress{Port: 126},
 			},
@@ -127,6 +129,78 @@ func TestValidateUpdate_NoError(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("scale down of decommissioned brokers", func(t *testing.T) {
+		drained := redpandaCluster.DeepCopy()
+		drained.Spec.Replicas = pointer.Int32Ptr(3)
+		drained.Spec.DecommissionDrain = &v1alpha1.DecommissionDrain{}
+		drained.Status.DrainingBrokers = []v1alpha1.DrainingBroker{
+			{NodeID: 2, Phase: v1alpha1.DrainPhaseDraining},
+		}
+		scaleDown := drained.DeepCopy()
+		scaleDown.Spec.Replicas = pointer.Int32Ptr(2)
+		err := scaleDown.ValidateUpdate(drained)
+		assert.NoError(t, err)
+
+		scaleDown.Spec.Replicas = pointer.Int32Ptr(1)
+		err = scaleDown.ValidateUpdate(drained)
+		assert.Error(t, err)
+
+		// refused while decommission draining is disabled
+		scaleDown.Spec.Replicas = pointer.Int32Ptr(2)
+		scaleDown.Spec.DecommissionDrain = nil
+		err = scaleDown.ValidateUpdate(drained)
+		assert.Error(t, err)
+	})
+
+	t.Run("downgrades below the highest version", func(t *testing.T) {
+		upgraded := redpandaCluster.DeepCopy()
+		upgraded.Spec.Version = "v21.6.1"
+		upgraded.Status.HighestVersion = "v21.6.1"
+
+		reverted := upgraded.DeepCopy()
+		reverted.Spec.Version = "21.4.2-amd64"
+		err := reverted.ValidateUpdate(upgraded)
+		assert.Error(t, err)
+
+		reverted.Spec.Version = "v21.6.1-rc2"
+		err = reverted.ValidateUpdate(upgraded)
+		assert.Error(t, err)
+
+		reverted.Spec.AllowDowngrade = true
+		err = reverted.ValidateUpdate(upgraded)
+		assert.NoError(t, err)
+
+		patched := upgraded.DeepCopy()
+		patched.Spec.Version = "v21.6.2"
+		err = patched.ValidateUpdate(upgraded)
+		assert.NoError(t, err)
+
+		dev := upgraded.DeepCopy()
+		dev.Spec.Version = "latest"
+		err = dev.ValidateUpdate(upgraded)
+		assert.NoError(t, err)
+	})
+
+	t.Run("internal topic partitions cannot be decreased", func(t *testing.T) {
+		existing := redpandaCluster.DeepCopy()
+		existing.Spec.Configuration.GroupTopicPartitions = 32
+
+		grown := existing.DeepCopy()
+		grown.Spec.Configuration.GroupTopicPartitions = 64
+		err := grown.ValidateUpdate(existing)
+		assert.NoError(t, err)
+
+		shrunk := existing.DeepCopy()
+		shrunk.Spec.Configuration.GroupTopicPartitions = 16
+		err = shrunk.ValidateUpdate(existing)
+		assert.Error(t, err)
+
+		removed := existing.DeepCopy()
+		removed.Spec.Configuration.GroupTopicPartitions = 0
+		err = removed.ValidateUpdate(existing)
+		assert.Error(t, err)
+	})
+
 	t.Run("scale up", func(t *testing.T) {
 		var scaleUp int32 = *redpandaCluster.Spec.Replicas + 1
 		updatedScaleUp := redpandaCluster.DeepCopy()
@@ -183,6 +257,17 @@ func TestValidateUpdate_NoError(t *testing.T) {
 		err := tls.ValidateUpdate(redpandaCluster)
 		assert.NoError(t, err)
 	})
+
+	t.Run("container name cannot be changed", func(t *testing.T) {
+		renamed := redpandaCluster.DeepCopy()
+		renamed.Spec.ContainerName = "broker"
+		err := renamed.ValidateUpdate(redpandaCluster)
+		assert.Error(t, err)
+
+		renamed.Spec.ContainerName = v1alpha1.DefaultContainerName
+		err = renamed.ValidateUpdate(redpandaCluster)
+		assert.NoError(t, err)
+	})
 }
 
 func TestCreation(t *testing.T) {
@@ -193,7 +278,7 @@ func TestCreation(t *testing.T) {
 		},
 		Spec: v1alpha1.ClusterSpec{
 			Configuration: v1alpha1.RedpandaConfig{
-				KafkaAPI:  v1alpha1.SocketAddress{Port: 123},
+				KafkaAPI:  v1alpha1.KafkaAPI{Port: 123},
 				AdminAPI:  v1alpha1.SocketAddress{Port: 125},
 				RPCServer: v1alpha1.SocketAddress{Port: 126},
 			},
@@ -278,4 +363,1129 @@ func TestCreation(t *testing.T) {
 		err := tls.ValidateCreate()
 		assert.Error(t, err)
 	})
+
+	t.Run("rpc node certificate in another namespace", func(t *testing.T) {
+		tls := redpandaCluster.DeepCopy()
+		tls.Spec.Configuration.TLS.RPC.Enabled = true
+		tls.Spec.Configuration.TLS.RPC.NodeSecretRef = &corev1.ObjectReference{Name: "rpc", Namespace: tls.Namespace}
+
+		err := tls.ValidateCreate()
+		assert.NoError(t, err)
+
+		tls.Spec.Configuration.TLS.RPC.NodeSecretRef.Namespace = "other"
+		err = tls.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("kafka quotas", func(t *testing.T) {
+		quotas := redpandaCluster.DeepCopy()
+		quotas.Spec.Configuration.KafkaClientLimits.TargetQuotaByteRate = 2 * 1024 * 1024
+		assert.NoError(t, quotas.ValidateCreate())
+
+		quotas.Spec.Configuration.KafkaClientLimits.TargetQuotaByteRate = -1
+		assert.Error(t, quotas.ValidateCreate())
+	})
+
+	t.Run("additional external subdomains", func(t *testing.T) {
+		extConn := redpandaCluster.DeepCopy()
+		extConn.Spec.ExternalConnectivity.Enabled = true
+		extConn.Spec.ExternalConnectivity.Subdomain = "redpanda.example.com"
+		extConn.Spec.ExternalConnectivity.Subdomains = []string{"tenant-a.example.com", "tenant-b.example.com"}
+
+		err := extConn.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("additional external subdomains without the advertised one", func(t *testing.T) {
+		extConn := redpandaCluster.DeepCopy()
+		extConn.Spec.ExternalConnectivity.Enabled = true
+		extConn.Spec.ExternalConnectivity.Subdomains = []string{"tenant-a.example.com"}
+
+		err := extConn.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("duplicated external subdomain", func(t *testing.T) {
+		extConn := redpandaCluster.DeepCopy()
+		extConn.Spec.ExternalConnectivity.Enabled = true
+		extConn.Spec.ExternalConnectivity.Subdomain = "redpanda.example.com"
+		extConn.Spec.ExternalConnectivity.Subdomains = []string{"redpanda.example.com"}
+
+		err := extConn.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	cloudStorage := redpandaCluster.DeepCopy()
+	cloudStorage.Spec.CloudStorage = v1alpha1.CloudStorageConfig{
+		Enabled:   true,
+		AccessKey: "key",
+		Bucket:    "bucket",
+		Region:    "region",
+		SecretKeyRef: corev1.ObjectReference{
+			Name:      "secret",
+			Namespace: "default",
+		},
+	}
+
+	t.Run("resource recommendation window", func(t *testing.T) {
+		recommendation := redpandaCluster.DeepCopy()
+		recommendation.Spec.ResourceRecommendation = &v1alpha1.ResourceRecommendation{
+			Window: &metav1.Duration{Duration: 7 * 24 * time.Hour},
+		}
+		err := recommendation.ValidateCreate()
+		assert.NoError(t, err)
+
+		recommendation.Spec.ResourceRecommendation.Window.Duration = 10 * time.Minute
+		err = recommendation.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("backup requires cloud storage", func(t *testing.T) {
+		backup := redpandaCluster.DeepCopy()
+		backup.Spec.Backup = &v1alpha1.Backup{}
+		err := backup.ValidateCreate()
+		assert.Error(t, err)
+
+		backup.Spec.CloudStorage = cloudStorage.Spec.CloudStorage
+		err = backup.ValidateCreate()
+		assert.NoError(t, err)
+
+		backup.Spec.Backup.Interval = &metav1.Duration{Duration: time.Second}
+		err = backup.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("topic warmup", func(t *testing.T) {
+		warmup := redpandaCluster.DeepCopy()
+		warmup.Spec.Replicas = pointer.Int32Ptr(3)
+		warmup.Spec.TopicWarmup = &v1alpha1.TopicWarmup{Topics: []v1alpha1.WarmupTopic{
+			{Name: "orders", Partitions: 12, ReplicationFactor: pointer.Int32Ptr(3)},
+			{Name: "quotes.eu_west-1", Partitions: 1},
+		}}
+		err := warmup.ValidateCreate()
+		assert.NoError(t, err)
+
+		for _, name := range []string{"", "..", "orders/eu", strings.Repeat("a", 250)} {
+			invalid := warmup.DeepCopy()
+			invalid.Spec.TopicWarmup.Topics[1].Name = name
+			err = invalid.ValidateCreate()
+			assert.Error(t, err, name)
+		}
+
+		duplicate := warmup.DeepCopy()
+		duplicate.Spec.TopicWarmup.Topics[1].Name = "orders"
+		err = duplicate.ValidateCreate()
+		assert.Error(t, err)
+
+		empty := warmup.DeepCopy()
+		empty.Spec.TopicWarmup.Topics[1].Partitions = 0
+		err = empty.ValidateCreate()
+		assert.Error(t, err)
+
+		for _, rf := range []int32{2, 5} {
+			replicated := warmup.DeepCopy()
+			replicated.Spec.TopicWarmup.Topics[0].ReplicationFactor = pointer.Int32Ptr(rf)
+			err = replicated.ValidateCreate()
+			assert.Error(t, err, rf)
+		}
+
+		sasl := warmup.DeepCopy()
+		sasl.Spec.EnableSASL = true
+		err = sasl.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		retention := redpandaCluster.DeepCopy()
+		retention.Spec.Configuration.Retention = v1alpha1.Retention{
+			TimeMs: pointer.Int64Ptr(7 * 24 * 3600 * 1000),
+			Bytes:  pointer.Int64Ptr(1 << 30),
+		}
+		err := retention.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("pod affinity to another workload", func(t *testing.T) {
+		affinity := redpandaCluster.DeepCopy()
+		affinity.Spec.PodAffinity = &corev1.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "stream-processor"}},
+				TopologyKey:   corev1.LabelHostname,
+			}},
+		}
+
+		err := affinity.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("pod affinity contradicting broker anti-affinity", func(t *testing.T) {
+		affinity := redpandaCluster.DeepCopy()
+		affinity.Spec.PodAffinity = &corev1.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/instance": affinity.Name}},
+				TopologyKey:   corev1.LabelHostname,
+			}},
+		}
+
+		err := affinity.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("additional command line arguments", func(t *testing.T) {
+		args := redpandaCluster.DeepCopy()
+		args.Spec.AdditionalCommandLineArguments = []string{"--unsafe-bypass-fsync", "--abort-on-seastar-bad-alloc"}
+		args.Spec.Env = []corev1.EnvVar{{Name: "SEASTAR_OPTS", Value: "--poll-mode"}}
+
+		err := args.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("additional command line arguments repeating managed flags", func(t *testing.T) {
+		for _, arg := range []string{"--smp 2", "--smp=2", "--memory=4G"} {
+			args := redpandaCluster.DeepCopy()
+			args.Spec.AdditionalCommandLineArguments = []string{arg}
+
+			err := args.ValidateCreate()
+			assert.Error(t, err, arg)
+		}
+	})
+
+	t.Run("environment variable managed by the operator", func(t *testing.T) {
+		env := redpandaCluster.DeepCopy()
+		env.Spec.Env = []corev1.EnvVar{{Name: "POD_IP", Value: "127.0.0.1"}}
+
+		err := env.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("relaxed durability", func(t *testing.T) {
+		relaxed := redpandaCluster.DeepCopy()
+		relaxed.Spec.Durability = v1alpha1.DurabilityRelaxed
+
+		err := relaxed.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("relaxed durability with bypass fsync argument", func(t *testing.T) {
+		relaxed := redpandaCluster.DeepCopy()
+		relaxed.Spec.Durability = v1alpha1.DurabilityRelaxed
+		relaxed.Spec.AdditionalCommandLineArguments = []string{"--unsafe-bypass-fsync 1"}
+
+		err := relaxed.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("mtls authentication with TLS client authentication", func(t *testing.T) {
+		mtls := redpandaCluster.DeepCopy()
+		mtls.Spec.Configuration.KafkaAPI.AuthenticationMethod = v1alpha1.KafkaAuthenticationMTLS
+		mtls.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+		mtls.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth = true
+
+		err := mtls.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("mtls authentication without TLS client authentication", func(t *testing.T) {
+		mtls := redpandaCluster.DeepCopy()
+		mtls.Spec.Configuration.KafkaAPI.AuthenticationMethod = v1alpha1.KafkaAuthenticationMTLS
+		mtls.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+
+		err := mtls.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("no authentication with SASL enabled", func(t *testing.T) {
+		none := redpandaCluster.DeepCopy()
+		none.Spec.Configuration.KafkaAPI.AuthenticationMethod = v1alpha1.KafkaAuthenticationNone
+		none.Spec.EnableSASL = true
+
+		err := none.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("raft heartbeat has to be lower than the election timeout", func(t *testing.T) {
+		raft := redpandaCluster.DeepCopy()
+		raft.Spec.Configuration.Raft.HeartbeatIntervalMs = 2000
+		err := raft.ValidateCreate()
+		assert.Error(t, err)
+
+		raft.Spec.Configuration.Raft.ElectionTimeoutMs = 5000
+		err = raft.ValidateCreate()
+		assert.NoError(t, err)
+
+		raft.Spec.Configuration.Raft.HeartbeatIntervalMs = 0
+		raft.Spec.Configuration.Raft.ElectionTimeoutMs = 100
+		err = raft.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("rpc server tuning", func(t *testing.T) {
+		rpc := redpandaCluster.DeepCopy()
+		rpc.Spec.Configuration.RPCServerTuning.HeartbeatTimeoutMs = 100
+		err := rpc.ValidateCreate()
+		assert.Error(t, err)
+
+		rpc.Spec.Configuration.RPCServerTuning.HeartbeatTimeoutMs = 3000
+		rpc.Spec.Configuration.RPCServerTuning.TCPKeepalive = &v1alpha1.TCPKeepalive{
+			TimeSeconds: pointer.Int32Ptr(60),
+			Probes:      pointer.Int32Ptr(200),
+		}
+		err = rpc.ValidateCreate()
+		assert.Error(t, err)
+
+		rpc.Spec.Configuration.RPCServerTuning.TCPKeepalive.Probes = pointer.Int32Ptr(5)
+		err = rpc.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("pandaproxy listeners", func(t *testing.T) {
+		proxy := redpandaCluster.DeepCopy()
+		proxy.Spec.Configuration.PandaproxyAPI = &v1alpha1.PandaproxyAPI{
+			Port:     122,
+			External: v1alpha1.ExternalListener{Enabled: true},
+		}
+		err := proxy.ValidateCreate()
+		assert.Error(t, err)
+
+		proxy.Spec.ExternalConnectivity.Enabled = true
+		proxy.Spec.Configuration.PandaproxyAPI.Port = 8082
+		proxy.Spec.Configuration.PandaproxyAPI.TLS.Enabled = true
+		err = proxy.ValidateCreate()
+		assert.Error(t, err)
+
+		proxy.Spec.Configuration.PandaproxyAPI.External.Subdomain = "proxy_example"
+		err = proxy.ValidateCreate()
+		assert.Error(t, err)
+
+		proxy.Spec.Configuration.PandaproxyAPI.External.Subdomain = "proxy.example.com"
+		err = proxy.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("compression codec", func(t *testing.T) {
+		compression := redpandaCluster.DeepCopy()
+		compression.Spec.Configuration.Compression.TopicDefault = v1alpha1.CompressionZstd
+		err := compression.ValidateCreate()
+		assert.NoError(t, err)
+
+		compression.Spec.Configuration.Compression.TopicDefault = "brotli"
+		err = compression.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown feature gate", func(t *testing.T) {
+		gates := redpandaCluster.DeepCopy()
+		gates.Spec.FeatureGates = map[string]bool{"OnlineConfig": false}
+		err := gates.ValidateCreate()
+		assert.Error(t, err)
+
+		gates.Spec.FeatureGates = map[string]bool{v1alpha1.FeatureGateOnlineConfiguration: false}
+		err = gates.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("online configuration is not served", func(t *testing.T) {
+		gates := redpandaCluster.DeepCopy()
+		gates.Spec.FeatureGates = map[string]bool{v1alpha1.FeatureGateOnlineConfiguration: true}
+		err := gates.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("memory reservation", func(t *testing.T) {
+		memory := redpandaCluster.DeepCopy()
+		memory.Spec.MemoryReservation = &v1alpha1.MemoryReservation{Percent: 10}
+		err := memory.ValidateCreate()
+		assert.NoError(t, err)
+
+		memory.Spec.MemoryReservation.Percent = 60
+		err = memory.ValidateCreate()
+		assert.Error(t, err)
+
+		reserved := resource.MustParse("1M")
+		memory.Spec.MemoryReservation = &v1alpha1.MemoryReservation{Reserved: &reserved}
+		err = memory.ValidateCreate()
+		assert.Error(t, err)
+
+		memory.Spec.MemoryReservation.Percent = 10
+		err = memory.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("transactions without idempotence", func(t *testing.T) {
+		compatibility := redpandaCluster.DeepCopy()
+		compatibility.Spec.Configuration.KafkaCompatibility = v1alpha1.KafkaCompatibility{
+			EnableIdempotence:  pointer.BoolPtr(false),
+			EnableTransactions: pointer.BoolPtr(true),
+		}
+		err := compatibility.ValidateCreate()
+		assert.Error(t, err)
+
+		compatibility.Spec.Configuration.KafkaCompatibility.EnableTransactions = pointer.BoolPtr(false)
+		err = compatibility.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("cpu pinning", func(t *testing.T) {
+		pinned := redpandaCluster.DeepCopy()
+		pinned.Spec.CPUPinning = &v1alpha1.CPUPinning{Enabled: true}
+		pinned.Spec.Resources.Limits = corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("2G"),
+		}
+		pinned.Spec.Resources.Requests = nil
+		err := pinned.ValidateCreate()
+		assert.NoError(t, err)
+
+		pinned.Spec.CPUPinning.CPUSet = "0,2"
+		err = pinned.ValidateCreate()
+		assert.NoError(t, err)
+
+		pinned.Spec.CPUPinning.CPUSet = "0-2"
+		err = pinned.ValidateCreate()
+		assert.Error(t, err)
+
+		pinned.Spec.CPUPinning.CPUSet = ""
+		pinned.Spec.Resources.Limits[corev1.ResourceCPU] = resource.MustParse("1500m")
+		err = pinned.ValidateCreate()
+		assert.Error(t, err)
+
+		pinned.Spec.Resources.Limits[corev1.ResourceCPU] = resource.MustParse("2")
+		pinned.Spec.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("1G"),
+		}
+		err = pinned.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("per broker config", func(t *testing.T) {
+		perBroker := redpandaCluster.DeepCopy()
+		perBroker.Spec.Replicas = pointer.Int32Ptr(3)
+		perBroker.Spec.PerBrokerConfig = map[string]map[string]string{
+			"2": {"cloud_storage_enabled": "true", "cloud_storage_reconciliation_interval_ms": "10000"},
+		}
+		err := perBroker.ValidateCreate()
+		assert.NoError(t, err)
+
+		perBroker.Spec.PerBrokerConfig["3"] = map[string]string{"cloud_storage_enabled": "true"}
+		err = perBroker.ValidateCreate()
+		assert.Error(t, err)
+
+		delete(perBroker.Spec.PerBrokerConfig, "3")
+		perBroker.Spec.PerBrokerConfig["0"] = map[string]string{"default_topic_replication": "3"}
+		err = perBroker.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("graceful shutdown requires the admin API without mutual TLS", func(t *testing.T) {
+		shutdown := redpandaCluster.DeepCopy()
+		shutdown.Spec.GracefulShutdown = &v1alpha1.GracefulShutdown{}
+		err := shutdown.ValidateCreate()
+		assert.NoError(t, err)
+
+		shutdown.Spec.Configuration.TLS.AdminAPI = v1alpha1.AdminAPITLS{Enabled: true, RequireClientAuth: true}
+		err = shutdown.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("custom preStop hook", func(t *testing.T) {
+		hook := redpandaCluster.DeepCopy()
+		hook.Spec.Lifecycle = &v1alpha1.BrokerLifecycle{
+			PreStop: &v1alpha1.PreStopHook{Command: []string{"/usr/bin/notify", "stopping"}},
+		}
+		err := hook.ValidateCreate()
+		assert.NoError(t, err)
+
+		hook.Spec.Lifecycle.PreStop.Order = v1alpha1.PreStopHookBeforeDrain
+		err = hook.ValidateCreate()
+		assert.Error(t, err)
+
+		hook.Spec.GracefulShutdown = &v1alpha1.GracefulShutdown{}
+		err = hook.ValidateCreate()
+		assert.NoError(t, err)
+
+		hook.Spec.Lifecycle.PreStop.TimeoutSeconds = pointer.Int32Ptr(3600)
+		err = hook.ValidateCreate()
+		assert.Error(t, err)
+
+		hook.Spec.Lifecycle.PreStop.TimeoutSeconds = nil
+		hook.Spec.Lifecycle.PreStop.Command = nil
+		err = hook.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("restart order by leadership requires graceful shutdown", func(t *testing.T) {
+		order := redpandaCluster.DeepCopy()
+		order.Spec.RestartOrder = v1alpha1.RestartOrderFewestLeadershipsFirst
+		err := order.ValidateCreate()
+		assert.Error(t, err)
+
+		order.Spec.GracefulShutdown = &v1alpha1.GracefulShutdown{}
+		err = order.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("consumer group coordination", func(t *testing.T) {
+		coordinated := redpandaCluster.DeepCopy()
+		coordinated.Spec.ConsumerGroupCoordination = &v1alpha1.ConsumerGroupCoordination{
+			StabilizationSeconds: pointer.Int32Ptr(60),
+			MaxRebalancingGroups: pointer.Int32Ptr(2),
+		}
+		err := coordinated.ValidateCreate()
+		assert.NoError(t, err)
+
+		coordinated.Spec.ConsumerGroupCoordination.MaxRebalancingGroups = pointer.Int32Ptr(-1)
+		err = coordinated.ValidateCreate()
+		assert.Error(t, err)
+
+		coordinated.Spec.ConsumerGroupCoordination.MaxRebalancingGroups = nil
+		coordinated.Spec.EnableSASL = true
+		err = coordinated.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("node tuning", func(t *testing.T) {
+		tuned := redpandaCluster.DeepCopy()
+		tuned.Spec.FeatureGates = map[string]bool{v1alpha1.FeatureGateNodeTuning: true}
+		tuned.Spec.NodeTuning = &v1alpha1.NodeTuning{
+			Privileged:    true,
+			NodeSelector:  map[string]string{"node-role": "redpanda"},
+			Sysctls:       map[string]string{"net.ipv4.tcp_rmem": "4096 87380 16777216"},
+			DiskScheduler: "none",
+			Devices:       []string{"nvme0n1"},
+		}
+		err := tuned.ValidateCreate()
+		assert.NoError(t, err)
+
+		tuned.Spec.NodeTuning.Privileged = false
+		err = tuned.ValidateCreate()
+		assert.Error(t, err)
+
+		tuned.Spec.NodeTuning.Privileged = true
+		tuned.Spec.FeatureGates = nil
+		err = tuned.ValidateCreate()
+		assert.Error(t, err)
+
+		tuned.Spec.FeatureGates = map[string]bool{v1alpha1.FeatureGateNodeTuning: true}
+		tuned.Spec.NodeTuning.NodeSelector = nil
+		err = tuned.ValidateCreate()
+		assert.Error(t, err)
+
+		tuned.Spec.NodeTuning.NodeSelector = map[string]string{"node-role": "redpanda"}
+		tuned.Spec.NodeTuning.Sysctls = map[string]string{"net.core.rmem_max": "1; reboot"}
+		err = tuned.ValidateCreate()
+		assert.Error(t, err)
+
+		tuned.Spec.NodeTuning.Sysctls = nil
+		tuned.Spec.NodeTuning.Devices = []string{"../sda"}
+		err = tuned.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("external Kafka listener", func(t *testing.T) {
+		partner := redpandaCluster.DeepCopy()
+		partner.Spec.ExternalConnectivity.Enabled = true
+		partner.Spec.ExternalConnectivity.Subdomain = "partner.example.com"
+		partner.Spec.Configuration.TLS.KafkaAPI = v1alpha1.KafkaAPITLS{Enabled: true, RequireClientAuth: true}
+		partner.Spec.Configuration.KafkaAPI.ExternalListener = &v1alpha1.KafkaExternalListener{
+			AuthenticationMethod: v1alpha1.KafkaAuthenticationMTLS,
+			TLS: v1alpha1.KafkaExternalListenerTLS{
+				Enabled:             true,
+				RequireClientAuth:   true,
+				TruststoreSecretRef: &corev1.LocalObjectReference{Name: "partner-ca"},
+			},
+		}
+		err := partner.ValidateCreate()
+		assert.NoError(t, err)
+
+		partner.Spec.Configuration.KafkaAPI.ExternalListener.TLS.RequireClientAuth = false
+		err = partner.ValidateCreate()
+		assert.Error(t, err)
+
+		// the listeners can not authenticate differently
+		partner.Spec.Configuration.KafkaAPI.ExternalListener.TLS.TruststoreSecretRef = nil
+		partner.Spec.Configuration.KafkaAPI.ExternalListener.AuthenticationMethod = v1alpha1.KafkaAuthenticationSASL
+		err = partner.ValidateCreate()
+		assert.Error(t, err)
+
+		partner.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth = false
+		partner.Spec.EnableSASL = true
+		err = partner.ValidateCreate()
+		assert.NoError(t, err)
+
+		partner.Spec.ExternalConnectivity.Subdomain = ""
+		err = partner.ValidateCreate()
+		assert.Error(t, err)
+
+		partner.Spec.ExternalConnectivity.Enabled = false
+		partner.Spec.Configuration.KafkaAPI.ExternalListener.TLS.Enabled = false
+		err = partner.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("broker shutdown cap", func(t *testing.T) {
+		stop := redpandaCluster.DeepCopy()
+		stop.Spec.Lifecycle = &v1alpha1.BrokerLifecycle{
+			Shutdown: &v1alpha1.BrokerShutdown{SecondsPerGiB: pointer.Int32Ptr(1), MaxTimeoutSeconds: pointer.Int32Ptr(20)},
+		}
+		err := stop.ValidateCreate()
+		assert.Error(t, err)
+
+		stop.Spec.Lifecycle.Shutdown.TimeoutSeconds = pointer.Int32Ptr(20)
+		err = stop.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("kafka listener names", func(t *testing.T) {
+		listeners := redpandaCluster.DeepCopy()
+		listeners.Spec.Configuration.KafkaAPI.InternalListenerName = "cluster"
+		err := listeners.ValidateCreate()
+		assert.NoError(t, err)
+
+		listeners.Spec.Configuration.KafkaAPI.ExternalListenerName = "clients"
+		err = listeners.ValidateCreate()
+		assert.Error(t, err)
+
+		listeners.Spec.ExternalConnectivity.Enabled = true
+		err = listeners.ValidateCreate()
+		assert.NoError(t, err)
+
+		listeners.Spec.Configuration.KafkaAPI.ExternalListenerName = "Cluster"
+		err = listeners.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("broker failure grace period", func(t *testing.T) {
+		failure := redpandaCluster.DeepCopy()
+		failure.Spec.BrokerFailure = &v1alpha1.BrokerFailurePolicy{Decommission: true}
+		err := failure.ValidateCreate()
+		assert.NoError(t, err)
+
+		failure.Spec.BrokerFailure.GracePeriod = &metav1.Duration{Duration: 30 * time.Second}
+		err = failure.ValidateCreate()
+		assert.Error(t, err)
+
+		failure.Spec.BrokerFailure.GracePeriod.Duration = 15 * time.Minute
+		err = failure.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("decommission drain timeout", func(t *testing.T) {
+		drain := redpandaCluster.DeepCopy()
+		drain.Spec.DecommissionDrain = &v1alpha1.DecommissionDrain{
+			Timeout: &metav1.Duration{Duration: -time.Second},
+		}
+		err := drain.ValidateCreate()
+		assert.Error(t, err)
+
+		drain.Spec.DecommissionDrain.Timeout.Duration = 2 * time.Minute
+		err = drain.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("crash loop recovery interval", func(t *testing.T) {
+		recovery := redpandaCluster.DeepCopy()
+		recovery.Spec.CrashLoopRecovery = &v1alpha1.CrashLoopRecovery{
+			MinInterval: &metav1.Duration{Duration: time.Minute},
+		}
+		err := recovery.ValidateCreate()
+		assert.Error(t, err)
+
+		recovery.Spec.CrashLoopRecovery.MinInterval.Duration = time.Hour
+		err = recovery.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("debug bundle", func(t *testing.T) {
+		bundle := redpandaCluster.DeepCopy()
+		bundle.Annotations = map[string]string{v1alpha1.DebugBundleAnnotation: "case-1234"}
+		err := bundle.ValidateCreate()
+		assert.Error(t, err)
+
+		bundle.Spec.DebugBundle = &v1alpha1.DebugBundle{
+			UploadURLSecretRef: corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "upload"},
+			},
+		}
+		err = bundle.ValidateCreate()
+		assert.Error(t, err)
+
+		bundle.Spec.DebugBundle.UploadURLSecretRef.Key = "url"
+		err = bundle.ValidateCreate()
+		assert.NoError(t, err)
+
+		bundle.Spec.DebugBundle.MinInterval = &metav1.Duration{Duration: time.Minute}
+		err = bundle.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("external-dns annotations", func(t *testing.T) {
+		dns := redpandaCluster.DeepCopy()
+		dns.Spec.ExternalConnectivity.Enabled = true
+		dns.Spec.ExternalConnectivity.ExternalDNS = &v1alpha1.ExternalDNSConfig{
+			TTL: pointer.Int32Ptr(60),
+			Annotations: map[string]string{
+				"external-dns.alpha.kubernetes.io/aws-weight": "10",
+			},
+		}
+		err := dns.ValidateCreate()
+		assert.NoError(t, err)
+
+		dns.Spec.ExternalConnectivity.ExternalDNS.TTL = pointer.Int32Ptr(0)
+		err = dns.ValidateCreate()
+		assert.Error(t, err)
+
+		dns.Spec.ExternalConnectivity.ExternalDNS.TTL = nil
+		dns.Spec.ExternalConnectivity.ExternalDNS.Annotations["external-dns.alpha.kubernetes.io/ttl"] = "1m"
+		err = dns.ValidateCreate()
+		assert.Error(t, err)
+
+		dns.Spec.ExternalConnectivity.ExternalDNS.Annotations["external-dns.alpha.kubernetes.io/ttl"] = "120"
+		err = dns.ValidateCreate()
+		assert.NoError(t, err)
+
+		dns.Spec.ExternalConnectivity.ExternalDNS.Annotations["external-dns.alpha.kubernetes.io/hostname"] = "kafka.example.com"
+		err = dns.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("rack node label must be a qualified name", func(t *testing.T) {
+		rack := redpandaCluster.DeepCopy()
+		rack.Spec.Configuration.RackAwareness = &v1alpha1.RackAwareness{Enabled: true}
+		err := rack.ValidateCreate()
+		assert.NoError(t, err)
+
+		rack.Spec.Configuration.RackAwareness.NodeLabel = "not a label"
+		err = rack.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("restarting brokers on rack change requires rack awareness", func(t *testing.T) {
+		rack := redpandaCluster.DeepCopy()
+		rack.Spec.Configuration.RackAwareness = &v1alpha1.RackAwareness{OnRackChange: v1alpha1.RackChangeRestart}
+		err := rack.ValidateCreate()
+		assert.Error(t, err)
+
+		rack.Spec.Configuration.RackAwareness.Enabled = true
+		err = rack.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("superusers are named once", func(t *testing.T) {
+		users := redpandaCluster.DeepCopy()
+		users.Spec.Superusers = []v1alpha1.Superuser{{Username: "admin"}, {Username: "ops"}}
+		err := users.ValidateCreate()
+		assert.NoError(t, err)
+
+		users.Spec.Superusers = append(users.Spec.Superusers, v1alpha1.Superuser{Username: "admin"})
+		err = users.ValidateCreate()
+		assert.Error(t, err)
+
+		users.Spec.Superusers = []v1alpha1.Superuser{{Username: " "}}
+		err = users.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("superuser password rotation", func(t *testing.T) {
+		rotation := redpandaCluster.DeepCopy()
+		rotation.Spec.Configuration.SuperuserPasswordRotation = &v1alpha1.PasswordRotation{
+			Interval: metav1.Duration{Duration: 30 * 24 * time.Hour},
+		}
+		err := rotation.ValidateCreate()
+		assert.Error(t, err)
+
+		rotation.Spec.EnableSASL = true
+		err = rotation.ValidateCreate()
+		assert.NoError(t, err)
+
+		rotation.Spec.Configuration.SuperuserPasswordRotation.Interval.Duration = time.Minute
+		err = rotation.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("bootstrap user", func(t *testing.T) {
+		bootstrap := redpandaCluster.DeepCopy()
+		bootstrap.Spec.BootstrapUser = &v1alpha1.BootstrapUser{}
+		err := bootstrap.ValidateCreate()
+		assert.Error(t, err)
+
+		bootstrap.Spec.BootstrapUser.SecretRef.Name = "bootstrap-user"
+		err = bootstrap.ValidateCreate()
+		assert.NoError(t, err)
+
+		changed := bootstrap.DeepCopy()
+		changed.Spec.BootstrapUser.SecretRef.Name = "another-user"
+		err = changed.ValidateUpdate(bootstrap)
+		assert.Error(t, err)
+	})
+
+	t.Run("compaction", func(t *testing.T) {
+		compaction := redpandaCluster.DeepCopy()
+		compaction.Spec.Configuration.Compaction = v1alpha1.Compaction{
+			CleanupPolicy: "compact,delete",
+		}
+		err := compaction.ValidateCreate()
+		assert.NoError(t, err)
+
+		compaction.Spec.Configuration.Compaction.CleanupPolicy = "compact;delete"
+		err = compaction.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("pod monitor", func(t *testing.T) {
+		monitored := redpandaCluster.DeepCopy()
+		monitored.Spec.PodMonitor = &v1alpha1.PodMonitor{
+			Interval: &metav1.Duration{Duration: 15 * time.Second},
+			Path:     "/public_metrics",
+		}
+		err := monitored.ValidateCreate()
+		assert.NoError(t, err)
+
+		monitored.Spec.PodMonitor.Path = "metrics"
+		err = monitored.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("storage hints", func(t *testing.T) {
+		hinted := redpandaCluster.DeepCopy()
+		hinted.Spec.Storage.Hints = &v1alpha1.StorageHints{
+			MountOptions:  []string{"noatime", "logbsize=256k"},
+			DiskScheduler: "none",
+		}
+		err := hinted.ValidateCreate()
+		assert.NoError(t, err)
+
+		hinted.Spec.Storage.Hints.MountOptions = []string{"noatime,nodiscard"}
+		err = hinted.ValidateCreate()
+		assert.Error(t, err)
+
+		hinted.Spec.Storage.Hints.MountOptions = nil
+		hinted.Spec.NodeTuning = &v1alpha1.NodeTuning{DiskScheduler: "mq-deadline", Devices: []string{"nvme0n1"}}
+		err = hinted.ValidateCreate()
+		assert.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "hints.diskScheduler"))
+	})
+
+	t.Run("replication", func(t *testing.T) {
+		replicated := redpandaCluster.DeepCopy()
+		replicated.Spec.Replication = &v1alpha1.Replication{
+			Image:  "apache/kafka:3.0.0",
+			Source: v1alpha1.ReplicationSource{Name: "source"},
+			Topics: `orders\..*,payments`,
+		}
+		err := replicated.ValidateCreate()
+		assert.NoError(t, err)
+		assert.True(t, replicated.ReplicatesTopic("orders.eu"))
+		assert.False(t, replicated.ReplicatesTopic("orders.eu-internal"))
+		assert.False(t, replicated.ReplicatesTopic("payments.eu"))
+
+		replicated.Spec.Replication.Source.Name = replicated.Name
+		err = replicated.ValidateCreate()
+		assert.Error(t, err)
+
+		replicated.Spec.Replication.Source.Name = "source"
+		replicated.Spec.Replication.TopicsExclude = "orders("
+		err = replicated.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("dedicated nodes", func(t *testing.T) {
+		dedicated := redpandaCluster.DeepCopy()
+		dedicated.Spec.DedicatedNodes = &v1alpha1.DedicatedNodes{Key: "dedicated"}
+		err := dedicated.ValidateCreate()
+		assert.Error(t, err)
+
+		dedicated.Spec.DedicatedNodes.Value = "redpanda"
+		err = dedicated.ValidateCreate()
+		assert.NoError(t, err)
+
+		dedicated.Spec.NodeSelector = map[string]string{"dedicated": "kafka"}
+		err = dedicated.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("admin API bind network", func(t *testing.T) {
+		bind := redpandaCluster.DeepCopy()
+		bind.Spec.Configuration.AdminAPIBindNetwork = "10.20.0.0/16"
+		err := bind.ValidateCreate()
+		assert.NoError(t, err)
+
+		bind.Spec.Configuration.AdminAPIBindNetwork = "10.20.0.1"
+		err = bind.ValidateCreate()
+		assert.Error(t, err)
+
+		bind.Spec.Configuration.AdminAPIBindNetwork = "10.20.0.0/16"
+		bind.Spec.Configuration.TLS.AdminAPI = v1alpha1.AdminAPITLS{Enabled: true, RequireClientAuth: true}
+		err = bind.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("discovery services", func(t *testing.T) {
+		discovery := redpandaCluster.DeepCopy()
+		discovery.Spec.DiscoveryServices = []v1alpha1.DiscoveryService{{Namespace: "apps"}}
+		err := discovery.ValidateCreate()
+		assert.NoError(t, err)
+
+		discovery.Spec.DiscoveryServices = append(discovery.Spec.DiscoveryServices,
+			v1alpha1.DiscoveryService{Namespace: "apps", Name: discovery.Name})
+		err = discovery.ValidateCreate()
+		assert.Error(t, err)
+
+		discovery.Spec.DiscoveryServices = []v1alpha1.DiscoveryService{{Namespace: discovery.Namespace}}
+		err = discovery.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("external advertised port requires external connectivity", func(t *testing.T) {
+		advertised := redpandaCluster.DeepCopy()
+		advertised.Spec.ExternalConnectivity.AdvertisedPort = 9094
+		err := advertised.ValidateCreate()
+		assert.Error(t, err)
+
+		advertised.Spec.ExternalConnectivity.Enabled = true
+		err = advertised.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("node address annotation", func(t *testing.T) {
+		annotated := redpandaCluster.DeepCopy()
+		annotated.Spec.ExternalConnectivity.NodeAddressAnnotation = "example.com/public-ip"
+		err := annotated.ValidateCreate()
+		assert.Error(t, err)
+
+		annotated.Spec.ExternalConnectivity.Enabled = true
+		err = annotated.ValidateCreate()
+		assert.NoError(t, err)
+
+		annotated.Spec.ExternalConnectivity.NodeAddressAnnotation = "public ip"
+		err = annotated.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("advertised addresses from a ConfigMap", func(t *testing.T) {
+		addresses := redpandaCluster.DeepCopy()
+		addresses.Spec.ExternalConnectivity.AdvertisedAddressesFrom = &v1alpha1.AdvertisedAddressesSource{
+			ConfigMapName: "ipam",
+		}
+		err := addresses.ValidateCreate()
+		assert.Error(t, err)
+
+		addresses.Spec.ExternalConnectivity.Enabled = true
+		err = addresses.ValidateCreate()
+		assert.NoError(t, err)
+
+		addresses.Spec.ExternalConnectivity.AdvertisedAddressesFrom.SecretName = "ipam"
+		err = addresses.ValidateCreate()
+		assert.Error(t, err)
+
+		addresses.Spec.ExternalConnectivity.AdvertisedAddressesFrom.SecretName = ""
+		addresses.Spec.ExternalConnectivity.Subdomain = "example.com"
+		err = addresses.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("network policy", func(t *testing.T) {
+		policy := redpandaCluster.DeepCopy()
+		policy.Spec.NetworkPolicy = &v1alpha1.NetworkPolicy{
+			Enabled:                 true,
+			AllowedClientNamespaces: []string{"apps"},
+		}
+		err := policy.ValidateCreate()
+		assert.NoError(t, err)
+
+		policy.Spec.NetworkPolicy.AllowedClientNamespaces = []string{"Apps"}
+		err = policy.ValidateCreate()
+		assert.Error(t, err)
+
+		policy.Spec.NetworkPolicy.AllowedClientNamespaces = nil
+		policy.Spec.NetworkPolicy.AllowedClientSelectors = []metav1.LabelSelector{{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Unknown"}},
+		}}
+		err = policy.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("management node port", func(t *testing.T) {
+		management := redpandaCluster.DeepCopy()
+		management.Spec.ManagementNodePort = &v1alpha1.ManagementNodePort{NodePort: 31644}
+		err := management.ValidateCreate()
+		assert.NoError(t, err)
+
+		management.Spec.ManagementNodePort.NodePort = 9644
+		err = management.ValidateCreate()
+		assert.Error(t, err)
+
+		// the source ranges are enforced by the NetworkPolicy
+		management.Spec.ManagementNodePort = &v1alpha1.ManagementNodePort{
+			NodePort:     31644,
+			SourceRanges: []string{"10.1.0.0/24"},
+		}
+		err = management.ValidateCreate()
+		assert.Error(t, err)
+
+		management.Spec.NetworkPolicy = &v1alpha1.NetworkPolicy{Enabled: true}
+		err = management.ValidateCreate()
+		assert.NoError(t, err)
+
+		management.Spec.ManagementNodePort.SourceRanges = []string{"10.1.0.0"}
+		err = management.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("restart annotation", func(t *testing.T) {
+		restart := redpandaCluster.DeepCopy()
+		restart.Spec.Replicas = pointer.Int32Ptr(3)
+		restart.Annotations = map[string]string{v1alpha1.RestartAnnotation: "2"}
+		err := restart.ValidateCreate()
+		assert.NoError(t, err)
+
+		restart.Annotations[v1alpha1.RestartAnnotation] = v1alpha1.RestartAllBrokers
+		err = restart.ValidateCreate()
+		assert.NoError(t, err)
+
+		restart.Annotations[v1alpha1.RestartAnnotation] = "3"
+		err = restart.ValidateCreate()
+		assert.Error(t, err)
+
+		restart.Annotations[v1alpha1.RestartAnnotation] = "cluster-1"
+		err = restart.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("reconcile periods", func(t *testing.T) {
+		periods := redpandaCluster.DeepCopy()
+		periods.Annotations = map[string]string{v1alpha1.RequeuePeriodAnnotation: "30s"}
+		periods.Spec.ResyncPeriod = &metav1.Duration{Duration: 5 * time.Minute}
+		err := periods.ValidateCreate()
+		assert.NoError(t, err)
+
+		periods.Annotations[v1alpha1.RequeuePeriodAnnotation] = "100ms"
+		err = periods.ValidateCreate()
+		assert.Error(t, err)
+
+		periods.Annotations[v1alpha1.RequeuePeriodAnnotation] = "soon"
+		err = periods.ValidateCreate()
+		assert.Error(t, err)
+
+		delete(periods.Annotations, v1alpha1.RequeuePeriodAnnotation)
+		periods.Spec.ResyncPeriod = &metav1.Duration{Duration: time.Second}
+		err = periods.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("memory locking with huge pages", func(t *testing.T) {
+		locking := redpandaCluster.DeepCopy()
+		locking.Spec.Resources.Limits[corev1.ResourceMemory] = resource.MustParse("2Gi")
+		locking.Spec.MemoryLocking = &v1alpha1.MemoryLocking{
+			LockMemory: true,
+			HugePages:  &v1alpha1.HugePages{PageSize: "2Mi", Size: resource.MustParse("2Gi")},
+		}
+		err := locking.ValidateCreate()
+		assert.NoError(t, err)
+
+		locking.Spec.MemoryLocking.HugePages.Size = resource.MustParse("1Gi")
+		err = locking.ValidateCreate()
+		assert.Error(t, err)
+
+		locking.Spec.MemoryLocking.HugePages.Size = resource.MustParse("2049Mi")
+		err = locking.ValidateCreate()
+		assert.Error(t, err)
+
+		locking.Spec.MemoryLocking.HugePages = nil
+		locking.Spec.AdditionalCommandLineArguments = []string{"--lock-memory=true"}
+		err = locking.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("reactor backend and io properties", func(t *testing.T) {
+		reactor := redpandaCluster.DeepCopy()
+		reactor.Spec.Reactor = &v1alpha1.ReactorTuning{
+			Backend:      v1alpha1.ReactorBackendEpoll,
+			IOProperties: "disks:\n- mountpoint: /var/lib/redpanda/data\n  read_iops: 1000\n",
+		}
+		err := reactor.ValidateCreate()
+		assert.NoError(t, err)
+
+		reactor.Spec.Reactor.Backend = "io_uring"
+		err = reactor.ValidateCreate()
+		assert.Error(t, err)
+
+		reactor.Spec.Reactor.Backend = v1alpha1.ReactorBackendEpoll
+		reactor.Spec.Reactor.IOPropertiesFrom = &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "iotune"},
+			Key:                  "io-config.yaml",
+		}
+		err = reactor.ValidateCreate()
+		assert.Error(t, err)
+
+		reactor.Spec.Reactor.IOPropertiesFrom = nil
+		reactor.Spec.Reactor.IOProperties = "read_iops: 1000"
+		err = reactor.ValidateCreate()
+		assert.Error(t, err)
+
+		reactor.Spec.Reactor.IOProperties = ""
+		reactor.Spec.AdditionalCommandLineArguments = []string{"--reactor-backend=linux-aio"}
+		err = reactor.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("init container names", func(t *testing.T) {
+		initContainers := redpandaCluster.DeepCopy()
+		initContainers.Spec.InitContainers = []v1alpha1.InitContainer{
+			{Name: "tune", Image: "busybox", Position: v1alpha1.InitContainerBeforeOperator},
+		}
+		err := initContainers.ValidateCreate()
+		assert.NoError(t, err)
+
+		initContainers.Spec.InitContainers = append(initContainers.Spec.InitContainers,
+			v1alpha1.InitContainer{Name: "tune", Image: "busybox"})
+		err = initContainers.ValidateCreate()
+		assert.Error(t, err)
+
+		initContainers.Spec.InitContainers[1].Name = "redpanda-configurator"
+		err = initContainers.ValidateCreate()
+		assert.Error(t, err)
+
+		initContainers.Spec.InitContainers[1].Name = "redpanda"
+		err = initContainers.ValidateCreate()
+		assert.Error(t, err)
+	})
+
+	t.Run("emptyDir storage", func(t *testing.T) {
+		defer func() { v1alpha1.AllowEmptyDirStorage = false }()
+		emptyDir := redpandaCluster.DeepCopy()
+		emptyDir.Spec.Storage.Type = v1alpha1.StorageTypeEmptyDir
+		emptyDir.Spec.Storage.EmptyDir = &v1alpha1.EmptyDirStorage{Medium: corev1.StorageMediumMemory}
+		err := emptyDir.ValidateCreate()
+		assert.Error(t, err)
+
+		v1alpha1.AllowEmptyDirStorage = true
+		err = emptyDir.ValidateCreate()
+		assert.NoError(t, err)
+
+		err = emptyDir.ValidateUpdate(redpandaCluster)
+		assert.Error(t, err)
+
+		emptyDir.Spec.Storage.Type = ""
+		err = emptyDir.ValidateCreate()
+		assert.Error(t, err)
+	})
+}
+
+func TestExternalSubdomains(t *testing.T) {
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "staging"},
+		Spec: v1alpha1.ClusterSpec{
+			ExternalConnectivity: v1alpha1.ExternalConnectivityConfig{
+				Enabled:    true,
+				Subdomain:  "example.com",
+				Subdomains: []string{"tenant.example.com"},
+			},
+		},
+	}
+	assert.Equal(t, "example.com", cluster.ExternalSubdomain())
+	assert.Equal(t, []string{"example.com", "tenant.example.com"}, cluster.ExternalSubdomains())
+
+	cluster.Spec.ExternalConnectivity.ClusterScopedRecords = true
+	assert.Equal(t, "cluster.staging.example.com", cluster.ExternalSubdomain())
+	assert.Equal(t, []string{"cluster.staging.example.com", "cluster.staging.tenant.example.com"}, cluster.ExternalSubdomains())
 }
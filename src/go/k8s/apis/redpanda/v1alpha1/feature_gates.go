@@ -0,0 +1,76 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package v1alpha1
+
+import "sort"
+
+const (
+	// FeatureGateOnlineConfiguration applies the cluster properties
+	// through the Admin API. When it is disabled every configuration change
+	// restarts the brokers. The Admin API of this Redpanda version does not
+	// serve the cluster configuration, so the gate can not be enabled.
+	FeatureGateOnlineConfiguration = "OnlineConfiguration"
+	// FeatureGateConfigDriftCorrection reverts cluster properties that were
+	// changed out of band to the values rendered by the operator. When it is
+	// disabled drift is only reported in the ConfigDrift condition.
+	FeatureGateConfigDriftCorrection = "ConfigDriftCorrection"
+	// FeatureGateCapacityCheck compares the resources requested by a broker
+	// with the allocatable resources of the nodes and reports the
+	// InsufficientCapacity condition when too few nodes fit a broker
+	FeatureGateCapacityCheck = "CapacityCheck"
+	// FeatureGateNodeTuning allows the NodeTuning DaemonSet, which runs
+	// privileged containers on the nodes of the brokers
+	FeatureGateNodeTuning = "NodeTuning"
+	// FeatureGateQuotaCheck compares the resources of the brokers with the
+	// ResourceQuotas and LimitRanges of the namespace and reports the
+	// QuotaExceeded condition when they would reject the brokers
+	FeatureGateQuotaCheck = "QuotaCheck"
+)
+
+// featureGateDefaults lists the known feature gates and whether they are
+// enabled when the Cluster does not set them. Experimental features default
+// to disabled.
+var featureGateDefaults = map[string]bool{
+	FeatureGateOnlineConfiguration:   false,
+	FeatureGateConfigDriftCorrection: false,
+	FeatureGateCapacityCheck:         true,
+	FeatureGateNodeTuning:            false,
+	FeatureGateQuotaCheck:            true,
+}
+
+// FeatureGateEnabled returns true when the feature gate is enabled for the
+// Cluster, either explicitly or by default
+func (r *Cluster) FeatureGateEnabled(gate string) bool {
+	if enabled, ok := r.Spec.FeatureGates[gate]; ok {
+		return enabled
+	}
+	return featureGateDefaults[gate]
+}
+
+// EnabledFeatureGates returns the sorted names of the enabled feature gates
+func (r *Cluster) EnabledFeatureGates() []string {
+	var enabled []string
+	for gate := range featureGateDefaults {
+		if r.FeatureGateEnabled(gate) {
+			enabled = append(enabled, gate)
+		}
+	}
+	sort.Strings(enabled)
+	return enabled
+}
+
+func sortedFeatureGates() []string {
+	gates := make([]string, 0, len(featureGateDefaults))
+	for gate := range featureGateDefaults {
+		gates = append(gates, gate)
+	}
+	sort.Strings(gates)
+	return gates
+}
@@ -15,7 +15,8 @@ package v1alpha1
 
 import (
 	metav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -34,226 +35,2168 @@ func (in *AdminAPITLS) DeepCopy() *AdminAPITLS {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdvertisedAddressesSource) DeepCopyInto(out *AdvertisedAddressesSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvertisedAddressesSource.
+func (in *AdvertisedAddressesSource) DeepCopy() *AdvertisedAddressesSource {
+	if in == nil {
+		return nil
+	}
+	out := new(AdvertisedAddressesSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Autoscaling) DeepCopyInto(out *Autoscaling) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Autoscaling.
+func (in *Autoscaling) DeepCopy() *Autoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(Autoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Backup) DeepCopyInto(out *Backup) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Backup.
+func (in *Backup) DeepCopy() *Backup {
+	if in == nil {
+		return nil
+	}
+	out := new(Backup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
+	*out = *in
+	in.LastBackupTime.DeepCopyInto(&out.LastBackupTime)
+	in.RecoveryPoint.DeepCopyInto(&out.RecoveryPoint)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStatus.
+func (in *BackupStatus) DeepCopy() *BackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapListener) DeepCopyInto(out *BootstrapListener) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapListener.
+func (in *BootstrapListener) DeepCopy() *BootstrapListener {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapListener)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapUser) DeepCopyInto(out *BootstrapUser) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapUser.
+func (in *BootstrapUser) DeepCopy() *BootstrapUser {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerFailurePolicy) DeepCopyInto(out *BrokerFailurePolicy) {
+	*out = *in
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerFailurePolicy.
+func (in *BrokerFailurePolicy) DeepCopy() *BrokerFailurePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerFailurePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerLifecycle) DeepCopyInto(out *BrokerLifecycle) {
+	*out = *in
+	if in.PreStop != nil {
+		in, out := &in.PreStop, &out.PreStop
+		*out = new(PreStopHook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Shutdown != nil {
+		in, out := &in.Shutdown, &out.Shutdown
+		*out = new(BrokerShutdown)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerLifecycle.
+func (in *BrokerLifecycle) DeepCopy() *BrokerLifecycle {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerLifecycle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerRack) DeepCopyInto(out *BrokerRack) {
+	*out = *in
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerRack.
+func (in *BrokerRack) DeepCopy() *BrokerRack {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerRack)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerRestart) DeepCopyInto(out *BrokerRestart) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerRestart.
+func (in *BrokerRestart) DeepCopy() *BrokerRestart {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerRestart)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerShutdown) DeepCopyInto(out *BrokerShutdown) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SecondsPerGiB != nil {
+		in, out := &in.SecondsPerGiB, &out.SecondsPerGiB
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxTimeoutSeconds != nil {
+		in, out := &in.MaxTimeoutSeconds, &out.MaxTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerShutdown.
+func (in *BrokerShutdown) DeepCopy() *BrokerShutdown {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerShutdown)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerVersion) DeepCopyInto(out *BrokerVersion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerVersion.
+func (in *BrokerVersion) DeepCopy() *BrokerVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CPUPinning) DeepCopyInto(out *CPUPinning) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPUPinning.
+func (in *CPUPinning) DeepCopy() *CPUPinning {
+	if in == nil {
+		return nil
+	}
+	out := new(CPUPinning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientConfig) DeepCopyInto(out *ClientConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientConfig.
+func (in *ClientConfig) DeepCopy() *ClientConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudStorageConfig) DeepCopyInto(out *CloudStorageConfig) {
 	*out = *in
 	out.SecretKeyRef = in.SecretKeyRef
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageConfig.
-func (in *CloudStorageConfig) DeepCopy() *CloudStorageConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageConfig.
+func (in *CloudStorageConfig) DeepCopy() *CloudStorageConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStorageConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Cluster) DeepCopyInto(out *Cluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Cluster.
+func (in *Cluster) DeepCopy() *Cluster {
+	if in == nil {
+		return nil
+	}
+	out := new(Cluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Cluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCondition) DeepCopyInto(out *ClusterCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCondition.
+func (in *ClusterCondition) DeepCopy() *ClusterCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterList) DeepCopyInto(out *ClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Cluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterList.
+func (in *ClusterList) DeepCopy() *ClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterOperation) DeepCopyInto(out *ClusterOperation) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterOperation.
+func (in *ClusterOperation) DeepCopy() *ClusterOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ScaleUpStep != nil {
+		in, out := &in.ScaleUpStep, &out.ScaleUpStep
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinReadySeconds != nil {
+		in, out := &in.MinReadySeconds, &out.MinReadySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.MemoryReservation != nil {
+		in, out := &in.MemoryReservation, &out.MemoryReservation
+		*out = new(MemoryReservation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemoryLocking != nil {
+		in, out := &in.MemoryLocking, &out.MemoryLocking
+		*out = new(MemoryLocking)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CPUPinning != nil {
+		in, out := &in.CPUPinning, &out.CPUPinning
+		*out = new(CPUPinning)
+		**out = **in
+	}
+	if in.Reactor != nil {
+		in, out := &in.Reactor, &out.Reactor
+		*out = new(ReactorTuning)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeTuning != nil {
+		in, out := &in.NodeTuning, &out.NodeTuning
+		*out = new(NodeTuning)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(LivenessProbe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GracefulShutdown != nil {
+		in, out := &in.GracefulShutdown, &out.GracefulShutdown
+		*out = new(GracefulShutdown)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(BrokerLifecycle)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BrokerFailure != nil {
+		in, out := &in.BrokerFailure, &out.BrokerFailure
+		*out = new(BrokerFailurePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DecommissionDrain != nil {
+		in, out := &in.DecommissionDrain, &out.DecommissionDrain
+		*out = new(DecommissionDrain)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessStabilization != nil {
+		in, out := &in.ReadinessStabilization, &out.ReadinessStabilization
+		*out = new(ReadinessStabilization)
+		**out = **in
+	}
+	if in.CrashLoopRecovery != nil {
+		in, out := &in.CrashLoopRecovery, &out.CrashLoopRecovery
+		*out = new(CrashLoopRecovery)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(Backup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceRecommendation != nil {
+		in, out := &in.ResourceRecommendation, &out.ResourceRecommendation
+		*out = new(ResourceRecommendation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolloutEndpoints != nil {
+		in, out := &in.RolloutEndpoints, &out.RolloutEndpoints
+		*out = new(RolloutEndpoints)
+		**out = **in
+	}
+	if in.ConsumerGroupCoordination != nil {
+		in, out := &in.ConsumerGroupCoordination, &out.ConsumerGroupCoordination
+		*out = new(ConsumerGroupCoordination)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolloutRollback != nil {
+		in, out := &in.RolloutRollback, &out.RolloutRollback
+		*out = new(RolloutRollback)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePrePull != nil {
+		in, out := &in.ImagePrePull, &out.ImagePrePull
+		*out = new(ImagePrePull)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Configuration.DeepCopyInto(&out.Configuration)
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodAffinity != nil {
+		in, out := &in.PodAffinity, &out.PodAffinity
+		*out = new(corev1.PodAffinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeAffinity != nil {
+		in, out := &in.NodeAffinity, &out.NodeAffinity
+		*out = new(corev1.NodeAffinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DedicatedNodes != nil {
+		in, out := &in.DedicatedNodes, &out.DedicatedNodes
+		*out = new(DedicatedNodes)
+		**out = **in
+	}
+	if in.PerBrokerConfig != nil {
+		in, out := &in.PerBrokerConfig, &out.PerBrokerConfig
+		*out = make(map[string]map[string]string, len(*in))
+		for key, val := range *in {
+			var outVal map[string]string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.AdditionalCommandLineArguments != nil {
+		in, out := &in.AdditionalCommandLineArguments, &out.AdditionalCommandLineArguments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]InitContainer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResyncPeriod != nil {
+		in, out := &in.ResyncPeriod, &out.ResyncPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PostBootstrapJob != nil {
+		in, out := &in.PostBootstrapJob, &out.PostBootstrapJob
+		*out = new(PostBootstrapJob)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopicWarmup != nil {
+		in, out := &in.TopicWarmup, &out.TopicWarmup
+		*out = new(TopicWarmup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DebugBundle != nil {
+		in, out := &in.DebugBundle, &out.DebugBundle
+		*out = new(DebugBundle)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Console != nil {
+		in, out := &in.Console, &out.Console
+		*out = new(Console)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiscoveryServices != nil {
+		in, out := &in.DiscoveryServices, &out.DiscoveryServices
+		*out = make([]DiscoveryService, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClientConfig != nil {
+		in, out := &in.ClientConfig, &out.ClientConfig
+		*out = new(ClientConfig)
+		**out = **in
+	}
+	if in.ServiceCatalog != nil {
+		in, out := &in.ServiceCatalog, &out.ServiceCatalog
+		*out = new(ServiceCatalog)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(NetworkPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ManagementNodePort != nil {
+		in, out := &in.ManagementNodePort, &out.ManagementNodePort
+		*out = new(ManagementNodePort)
+		(*in).DeepCopyInto(*out)
+	}
+	in.ExternalConnectivity.DeepCopyInto(&out.ExternalConnectivity)
+	in.Storage.DeepCopyInto(&out.Storage)
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(Autoscaling)
+		**out = **in
+	}
+	if in.PodMonitor != nil {
+		in, out := &in.PodMonitor, &out.PodMonitor
+		*out = new(PodMonitor)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replication != nil {
+		in, out := &in.Replication, &out.Replication
+		*out = new(Replication)
+		(*in).DeepCopyInto(*out)
+	}
+	out.CloudStorage = in.CloudStorage
+	if in.Superusers != nil {
+		in, out := &in.Superusers, &out.Superusers
+		*out = make([]Superuser, len(*in))
+		copy(*out, *in)
+	}
+	if in.SuperusersFrom != nil {
+		in, out := &in.SuperusersFrom, &out.SuperusersFrom
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BootstrapUser != nil {
+		in, out := &in.BootstrapUser, &out.BootstrapUser
+		*out = new(BootstrapUser)
+		**out = **in
+	}
+	in.InternalTopicReplication.DeepCopyInto(&out.InternalTopicReplication)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpec.
+func (in *ClusterSpec) DeepCopy() *ClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
+	*out = *in
+	in.Nodes.DeepCopyInto(&out.Nodes)
+	if in.RestartOrder != nil {
+		in, out := &in.RestartOrder, &out.RestartOrder
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.RolloutFailure != nil {
+		in, out := &in.RolloutFailure, &out.RolloutFailure
+		*out = new(RolloutFailure)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = make([]BootstrapListener, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ClusterCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UnboundVolumes != nil {
+		in, out := &in.UnboundVolumes, &out.UnboundVolumes
+		*out = make([]UnboundVolume, len(*in))
+		copy(*out, *in)
+	}
+	if in.SuperuserPasswordRotated != nil {
+		in, out := &in.SuperuserPasswordRotated, &out.SuperuserPasswordRotated
+		*out = (*in).DeepCopy()
+	}
+	if in.ControllerID != nil {
+		in, out := &in.ControllerID, &out.ControllerID
+		*out = new(int)
+		**out = **in
+	}
+	if in.DownBrokers != nil {
+		in, out := &in.DownBrokers, &out.DownBrokers
+		*out = make([]DownBroker, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DrainingBrokers != nil {
+		in, out := &in.DrainingBrokers, &out.DrainingBrokers
+		*out = make([]DrainingBroker, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CapacityCheckedDecommissions != nil {
+		in, out := &in.CapacityCheckedDecommissions, &out.CapacityCheckedDecommissions
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingRestarts != nil {
+		in, out := &in.PendingRestarts, &out.PendingRestarts
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.BrokerRestart != nil {
+		in, out := &in.BrokerRestart, &out.BrokerRestart
+		*out = new(BrokerRestart)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastCrashLoopRecovery != nil {
+		in, out := &in.LastCrashLoopRecovery, &out.LastCrashLoopRecovery
+		*out = (*in).DeepCopy()
+	}
+	if in.BrokerRacks != nil {
+		in, out := &in.BrokerRacks, &out.BrokerRacks
+		*out = make([]BrokerRack, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(BackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceRecommendation != nil {
+		in, out := &in.ResourceRecommendation, &out.ResourceRecommendation
+		*out = new(ResourceRecommendationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WarmTopics != nil {
+		in, out := &in.WarmTopics, &out.WarmTopics
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DebugBundle != nil {
+		in, out := &in.DebugBundle, &out.DebugBundle
+		*out = new(DebugBundleStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replication != nil {
+		in, out := &in.Replication, &out.Replication
+		*out = new(ReplicationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AppliedProperties != nil {
+		in, out := &in.AppliedProperties, &out.AppliedProperties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.UnknownProperties != nil {
+		in, out := &in.UnknownProperties, &out.UnknownProperties
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BrokerVersions != nil {
+		in, out := &in.BrokerVersions, &out.BrokerVersions
+		*out = make([]BrokerVersion, len(*in))
+		copy(*out, *in)
+	}
+	if in.CurrentOperation != nil {
+		in, out := &in.CurrentOperation, &out.CurrentOperation
+		*out = new(ClusterOperation)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
+func (in *ClusterStatus) DeepCopy() *ClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Compaction) DeepCopyInto(out *Compaction) {
+	*out = *in
+	if in.IntervalMs != nil {
+		in, out := &in.IntervalMs, &out.IntervalMs
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Compaction.
+func (in *Compaction) DeepCopy() *Compaction {
+	if in == nil {
+		return nil
+	}
+	out := new(Compaction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Compression) DeepCopyInto(out *Compression) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Compression.
+func (in *Compression) DeepCopy() *Compression {
+	if in == nil {
+		return nil
+	}
+	out := new(Compression)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigSchemaValidation) DeepCopyInto(out *ConfigSchemaValidation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigSchemaValidation.
+func (in *ConfigSchemaValidation) DeepCopy() *ConfigSchemaValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigSchemaValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Console) DeepCopyInto(out *Console) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(ConsoleIngress)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Console.
+func (in *Console) DeepCopy() *Console {
+	if in == nil {
+		return nil
+	}
+	out := new(Console)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsoleIngress) DeepCopyInto(out *ConsoleIngress) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsoleIngress.
+func (in *ConsoleIngress) DeepCopy() *ConsoleIngress {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsoleIngress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumerGroupCoordination) DeepCopyInto(out *ConsumerGroupCoordination) {
+	*out = *in
+	if in.StabilizationSeconds != nil {
+		in, out := &in.StabilizationSeconds, &out.StabilizationSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxRebalancingGroups != nil {
+		in, out := &in.MaxRebalancingGroups, &out.MaxRebalancingGroups
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumerGroupCoordination.
+func (in *ConsumerGroupCoordination) DeepCopy() *ConsumerGroupCoordination {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumerGroupCoordination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrashLoopRecovery) DeepCopyInto(out *CrashLoopRecovery) {
+	*out = *in
+	if in.RestartThreshold != nil {
+		in, out := &in.RestartThreshold, &out.RestartThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinInterval != nil {
+		in, out := &in.MinInterval, &out.MinInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrashLoopRecovery.
+func (in *CrashLoopRecovery) DeepCopy() *CrashLoopRecovery {
+	if in == nil {
+		return nil
+	}
+	out := new(CrashLoopRecovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSConfig) DeepCopyInto(out *DNSConfig) {
+	*out = *in
+	if in.ExternalRecordTTL != nil {
+		in, out := &in.ExternalRecordTTL, &out.ExternalRecordTTL
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSConfig.
+func (in *DNSConfig) DeepCopy() *DNSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DebugBundle) DeepCopyInto(out *DebugBundle) {
+	*out = *in
+	in.UploadURLSecretRef.DeepCopyInto(&out.UploadURLSecretRef)
+	if in.MinInterval != nil {
+		in, out := &in.MinInterval, &out.MinInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DebugBundle.
+func (in *DebugBundle) DeepCopy() *DebugBundle {
+	if in == nil {
+		return nil
+	}
+	out := new(DebugBundle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DebugBundleStatus) DeepCopyInto(out *DebugBundleStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DebugBundleStatus.
+func (in *DebugBundleStatus) DeepCopy() *DebugBundleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DebugBundleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DecommissionDrain) DeepCopyInto(out *DecommissionDrain) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecommissionDrain.
+func (in *DecommissionDrain) DeepCopy() *DecommissionDrain {
+	if in == nil {
+		return nil
+	}
+	out := new(DecommissionDrain)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DedicatedNodes) DeepCopyInto(out *DedicatedNodes) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DedicatedNodes.
+func (in *DedicatedNodes) DeepCopy() *DedicatedNodes {
+	if in == nil {
+		return nil
+	}
+	out := new(DedicatedNodes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveryService) DeepCopyInto(out *DiscoveryService) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveryService.
+func (in *DiscoveryService) DeepCopy() *DiscoveryService {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveryService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DownBroker) DeepCopyInto(out *DownBroker) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownBroker.
+func (in *DownBroker) DeepCopy() *DownBroker {
+	if in == nil {
+		return nil
+	}
+	out := new(DownBroker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainingBroker) DeepCopyInto(out *DrainingBroker) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainingBroker.
+func (in *DrainingBroker) DeepCopy() *DrainingBroker {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainingBroker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmptyDirStorage) DeepCopyInto(out *EmptyDirStorage) {
+	*out = *in
+	in.Medium.DeepCopyInto(&out.Medium)
+	if in.SizeLimit != nil {
+		in, out := &in.SizeLimit, &out.SizeLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmptyDirStorage.
+func (in *EmptyDirStorage) DeepCopy() *EmptyDirStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(EmptyDirStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalConnectivityConfig) DeepCopyInto(out *ExternalConnectivityConfig) {
+	*out = *in
+	if in.Subdomains != nil {
+		in, out := &in.Subdomains, &out.Subdomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdvertisedAddressesFrom != nil {
+		in, out := &in.AdvertisedAddressesFrom, &out.AdvertisedAddressesFrom
+		*out = new(AdvertisedAddressesSource)
+		**out = **in
+	}
+	if in.ExternalDNS != nil {
+		in, out := &in.ExternalDNS, &out.ExternalDNS
+		*out = new(ExternalDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalConnectivityConfig.
+func (in *ExternalConnectivityConfig) DeepCopy() *ExternalConnectivityConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalConnectivityConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalDNSConfig) DeepCopyInto(out *ExternalDNSConfig) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDNSConfig.
+func (in *ExternalDNSConfig) DeepCopy() *ExternalDNSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalDNSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalListener) DeepCopyInto(out *ExternalListener) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalListener.
+func (in *ExternalListener) DeepCopy() *ExternalListener {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalListener)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GracefulShutdown) DeepCopyInto(out *GracefulShutdown) {
+	*out = *in
+	if in.DrainTimeoutSeconds != nil {
+		in, out := &in.DrainTimeoutSeconds, &out.DrainTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GracefulShutdown.
+func (in *GracefulShutdown) DeepCopy() *GracefulShutdown {
+	if in == nil {
+		return nil
+	}
+	out := new(GracefulShutdown)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HugePages) DeepCopyInto(out *HugePages) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HugePages.
+func (in *HugePages) DeepCopy() *HugePages {
+	if in == nil {
+		return nil
+	}
+	out := new(HugePages)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePrePull) DeepCopyInto(out *ImagePrePull) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePrePull.
+func (in *ImagePrePull) DeepCopy() *ImagePrePull {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePrePull)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitContainer) DeepCopyInto(out *InitContainer) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]corev1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitContainer.
+func (in *InitContainer) DeepCopy() *InitContainer {
+	if in == nil {
+		return nil
+	}
+	out := new(InitContainer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalTopicReplication) DeepCopyInto(out *InternalTopicReplication) {
+	*out = *in
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalTopicReplication.
+func (in *InternalTopicReplication) DeepCopy() *InternalTopicReplication {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalTopicReplication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaAPI) DeepCopyInto(out *KafkaAPI) {
+	*out = *in
+	if in.ExternalListener != nil {
+		in, out := &in.ExternalListener, &out.ExternalListener
+		*out = new(KafkaExternalListener)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaAPI.
+func (in *KafkaAPI) DeepCopy() *KafkaAPI {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaAPI)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaAPITLS) DeepCopyInto(out *KafkaAPITLS) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(metav1.ObjectReference)
+		**out = **in
+	}
+	if in.NodeSecretRef != nil {
+		in, out := &in.NodeSecretRef, &out.NodeSecretRef
+		*out = new(corev1.ObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaAPITLS.
+func (in *KafkaAPITLS) DeepCopy() *KafkaAPITLS {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaAPITLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaClientLimits) DeepCopyInto(out *KafkaClientLimits) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClientLimits.
+func (in *KafkaClientLimits) DeepCopy() *KafkaClientLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaClientLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaCompatibility) DeepCopyInto(out *KafkaCompatibility) {
+	*out = *in
+	if in.EnableIdempotence != nil {
+		in, out := &in.EnableIdempotence, &out.EnableIdempotence
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnableTransactions != nil {
+		in, out := &in.EnableTransactions, &out.EnableTransactions
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaCompatibility.
+func (in *KafkaCompatibility) DeepCopy() *KafkaCompatibility {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaCompatibility)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaExternalListener) DeepCopyInto(out *KafkaExternalListener) {
+	*out = *in
+	in.TLS.DeepCopyInto(&out.TLS)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaExternalListener.
+func (in *KafkaExternalListener) DeepCopy() *KafkaExternalListener {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaExternalListener)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaExternalListenerTLS) DeepCopyInto(out *KafkaExternalListenerTLS) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(metav1.ObjectReference)
+		**out = **in
+	}
+	if in.TruststoreSecretRef != nil {
+		in, out := &in.TruststoreSecretRef, &out.TruststoreSecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaExternalListenerTLS.
+func (in *KafkaExternalListenerTLS) DeepCopy() *KafkaExternalListenerTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaExternalListenerTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LivenessProbe) DeepCopyInto(out *LivenessProbe) {
+	*out = *in
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LivenessProbe.
+func (in *LivenessProbe) DeepCopy() *LivenessProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(LivenessProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementNodePort) DeepCopyInto(out *ManagementNodePort) {
+	*out = *in
+	if in.SourceRanges != nil {
+		in, out := &in.SourceRanges, &out.SourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementNodePort.
+func (in *ManagementNodePort) DeepCopy() *ManagementNodePort {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementNodePort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryLocking) DeepCopyInto(out *MemoryLocking) {
+	*out = *in
+	if in.HugePages != nil {
+		in, out := &in.HugePages, &out.HugePages
+		*out = new(HugePages)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryLocking.
+func (in *MemoryLocking) DeepCopy() *MemoryLocking {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryLocking)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryReservation) DeepCopyInto(out *MemoryReservation) {
+	*out = *in
+	if in.Reserved != nil {
+		in, out := &in.Reserved, &out.Reserved
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryReservation.
+func (in *MemoryReservation) DeepCopy() *MemoryReservation {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryReservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkPolicy) DeepCopyInto(out *NetworkPolicy) {
+	*out = *in
+	if in.AllowedClientNamespaces != nil {
+		in, out := &in.AllowedClientNamespaces, &out.AllowedClientNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedClientSelectors != nil {
+		in, out := &in.AllowedClientSelectors, &out.AllowedClientSelectors
+		*out = make([]v1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicy.
+func (in *NetworkPolicy) DeepCopy() *NetworkPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeTuning) DeepCopyInto(out *NodeTuning) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Sysctls != nil {
+		in, out := &in.Sysctls, &out.Sysctls
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeTuning.
+func (in *NodeTuning) DeepCopy() *NodeTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeTuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodesList) DeepCopyInto(out *NodesList) {
+	*out = *in
+	if in.Internal != nil {
+		in, out := &in.Internal, &out.Internal
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExternalAdmin != nil {
+		in, out := &in.ExternalAdmin, &out.ExternalAdmin
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodesList.
+func (in *NodesList) DeepCopy() *NodesList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodesList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCRetentionPolicy) DeepCopyInto(out *PVCRetentionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCRetentionPolicy.
+func (in *PVCRetentionPolicy) DeepCopy() *PVCRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PandaproxyAPI) DeepCopyInto(out *PandaproxyAPI) {
+	*out = *in
+	out.External = in.External
+	in.TLS.DeepCopyInto(&out.TLS)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PandaproxyAPI.
+func (in *PandaproxyAPI) DeepCopy() *PandaproxyAPI {
+	if in == nil {
+		return nil
+	}
+	out := new(PandaproxyAPI)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PandaproxyAPITLS) DeepCopyInto(out *PandaproxyAPITLS) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(metav1.ObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PandaproxyAPITLS.
+func (in *PandaproxyAPITLS) DeepCopy() *PandaproxyAPITLS {
+	if in == nil {
+		return nil
+	}
+	out := new(PandaproxyAPITLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordRotation) DeepCopyInto(out *PasswordRotation) {
+	*out = *in
+	out.Interval = in.Interval
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordRotation.
+func (in *PasswordRotation) DeepCopy() *PasswordRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodMonitor) DeepCopyInto(out *PodMonitor) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodMonitor.
+func (in *PodMonitor) DeepCopy() *PodMonitor {
+	if in == nil {
+		return nil
+	}
+	out := new(PodMonitor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostBootstrapJob) DeepCopyInto(out *PostBootstrapJob) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostBootstrapJob.
+func (in *PostBootstrapJob) DeepCopy() *PostBootstrapJob {
+	if in == nil {
+		return nil
+	}
+	out := new(PostBootstrapJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreStopHook) DeepCopyInto(out *PreStopHook) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreStopHook.
+func (in *PreStopHook) DeepCopy() *PreStopHook {
+	if in == nil {
+		return nil
+	}
+	out := new(PreStopHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RPCServerTuning) DeepCopyInto(out *RPCServerTuning) {
+	*out = *in
+	if in.TCPKeepalive != nil {
+		in, out := &in.TCPKeepalive, &out.TCPKeepalive
+		*out = new(TCPKeepalive)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RPCServerTuning.
+func (in *RPCServerTuning) DeepCopy() *RPCServerTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(RPCServerTuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RPCTLS) DeepCopyInto(out *RPCTLS) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(metav1.ObjectReference)
+		**out = **in
+	}
+	if in.NodeSecretRef != nil {
+		in, out := &in.NodeSecretRef, &out.NodeSecretRef
+		*out = new(corev1.ObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RPCTLS.
+func (in *RPCTLS) DeepCopy() *RPCTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(RPCTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RackAwareness) DeepCopyInto(out *RackAwareness) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RackAwareness.
+func (in *RackAwareness) DeepCopy() *RackAwareness {
+	if in == nil {
+		return nil
+	}
+	out := new(RackAwareness)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RaftTuning) DeepCopyInto(out *RaftTuning) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RaftTuning.
+func (in *RaftTuning) DeepCopy() *RaftTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(RaftTuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReactorTuning) DeepCopyInto(out *ReactorTuning) {
+	*out = *in
+	if in.IOPropertiesFrom != nil {
+		in, out := &in.IOPropertiesFrom, &out.IOPropertiesFrom
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReactorTuning.
+func (in *ReactorTuning) DeepCopy() *ReactorTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(ReactorTuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessStabilization) DeepCopyInto(out *ReadinessStabilization) {
+	*out = *in
+	out.Window = in.Window
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessStabilization.
+func (in *ReadinessStabilization) DeepCopy() *ReadinessStabilization {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessStabilization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedpandaConfig) DeepCopyInto(out *RedpandaConfig) {
+	*out = *in
+	out.RPCServer = in.RPCServer
+	in.KafkaAPI.DeepCopyInto(&out.KafkaAPI)
+	out.AdminAPI = in.AdminAPI
+	in.TLS.DeepCopyInto(&out.TLS)
+	out.KafkaClientLimits = in.KafkaClientLimits
+	if in.SuperuserPasswordRotation != nil {
+		in, out := &in.SuperuserPasswordRotation, &out.SuperuserPasswordRotation
+		*out = new(PasswordRotation)
+		**out = **in
+	}
+	out.Raft = in.Raft
+	in.RPCServerTuning.DeepCopyInto(&out.RPCServerTuning)
+	in.TopicDefaults.DeepCopyInto(&out.TopicDefaults)
+	in.Retention.DeepCopyInto(&out.Retention)
+	in.Compaction.DeepCopyInto(&out.Compaction)
+	in.KafkaCompatibility.DeepCopyInto(&out.KafkaCompatibility)
+	out.Compression = in.Compression
+	if in.RackAwareness != nil {
+		in, out := &in.RackAwareness, &out.RackAwareness
+		*out = new(RackAwareness)
+		**out = **in
+	}
+	if in.PandaproxyAPI != nil {
+		in, out := &in.PandaproxyAPI, &out.PandaproxyAPI
+		*out = new(PandaproxyAPI)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SchemaValidation != nil {
+		in, out := &in.SchemaValidation, &out.SchemaValidation
+		*out = new(ConfigSchemaValidation)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedpandaConfig.
+func (in *RedpandaConfig) DeepCopy() *RedpandaConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStorageConfig)
+	out := new(RedpandaConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Cluster) DeepCopyInto(out *Cluster) {
+func (in *RedpandaVersion) DeepCopyInto(out *RedpandaVersion) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Cluster.
-func (in *Cluster) DeepCopy() *Cluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedpandaVersion.
+func (in *RedpandaVersion) DeepCopy() *RedpandaVersion {
 	if in == nil {
 		return nil
 	}
-	out := new(Cluster)
+	out := new(RedpandaVersion)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Cluster) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterList) DeepCopyInto(out *ClusterList) {
+func (in *ReplicatedTopic) DeepCopyInto(out *ReplicatedTopic) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Cluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterList.
-func (in *ClusterList) DeepCopy() *ClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicatedTopic.
+func (in *ReplicatedTopic) DeepCopy() *ReplicatedTopic {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterList)
+	out := new(ReplicatedTopic)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
+func (in *Replication) DeepCopyInto(out *Replication) {
 	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Source = in.Source
 	if in.Replicas != nil {
 		in, out := &in.Replicas, &out.Replicas
 		*out = new(int32)
 		**out = **in
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
-	in.Configuration.DeepCopyInto(&out.Configuration)
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Replication.
+func (in *Replication) DeepCopy() *Replication {
+	if in == nil {
+		return nil
 	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	out := new(Replication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationSource) DeepCopyInto(out *ReplicationSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSource.
+func (in *ReplicationSource) DeepCopy() *ReplicationSource {
+	if in == nil {
+		return nil
 	}
-	out.ExternalConnectivity = in.ExternalConnectivity
-	in.Storage.DeepCopyInto(&out.Storage)
-	out.CloudStorage = in.CloudStorage
-	if in.Superusers != nil {
-		in, out := &in.Superusers, &out.Superusers
-		*out = make([]Superuser, len(*in))
+	out := new(ReplicationSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationStatus) DeepCopyInto(out *ReplicationStatus) {
+	*out = *in
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]ReplicatedTopic, len(*in))
 		copy(*out, *in)
 	}
+	in.ObservedAt.DeepCopyInto(&out.ObservedAt)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpec.
-func (in *ClusterSpec) DeepCopy() *ClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationStatus.
+func (in *ReplicationStatus) DeepCopy() *ReplicationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterSpec)
+	out := new(ReplicationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
+func (in *ResourceRecommendation) DeepCopyInto(out *ResourceRecommendation) {
 	*out = *in
-	in.Nodes.DeepCopyInto(&out.Nodes)
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.HeadroomPercent != nil {
+		in, out := &in.HeadroomPercent, &out.HeadroomPercent
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
-func (in *ClusterStatus) DeepCopy() *ClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRecommendation.
+func (in *ResourceRecommendation) DeepCopy() *ResourceRecommendation {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterStatus)
+	out := new(ResourceRecommendation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExternalConnectivityConfig) DeepCopyInto(out *ExternalConnectivityConfig) {
+func (in *ResourceRecommendationStatus) DeepCopyInto(out *ResourceRecommendationStatus) {
 	*out = *in
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.RecommendedAt != nil {
+		in, out := &in.RecommendedAt, &out.RecommendedAt
+		*out = (*in).DeepCopy()
+	}
+	in.WindowStart.DeepCopyInto(&out.WindowStart)
+	if in.LastSampleTime != nil {
+		in, out := &in.LastSampleTime, &out.LastSampleTime
+		*out = (*in).DeepCopy()
+	}
+	out.PeakCPU = in.PeakCPU.DeepCopy()
+	out.PeakMemory = in.PeakMemory.DeepCopy()
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalConnectivityConfig.
-func (in *ExternalConnectivityConfig) DeepCopy() *ExternalConnectivityConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRecommendationStatus.
+func (in *ResourceRecommendationStatus) DeepCopy() *ResourceRecommendationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ExternalConnectivityConfig)
+	out := new(ResourceRecommendationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaAPITLS) DeepCopyInto(out *KafkaAPITLS) {
+func (in *Retention) DeepCopyInto(out *Retention) {
 	*out = *in
-	if in.IssuerRef != nil {
-		in, out := &in.IssuerRef, &out.IssuerRef
-		*out = new(metav1.ObjectReference)
+	if in.TimeMs != nil {
+		in, out := &in.TimeMs, &out.TimeMs
+		*out = new(int64)
 		**out = **in
 	}
-	if in.NodeSecretRef != nil {
-		in, out := &in.NodeSecretRef, &out.NodeSecretRef
-		*out = new(v1.ObjectReference)
+	if in.Bytes != nil {
+		in, out := &in.Bytes, &out.Bytes
+		*out = new(int64)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaAPITLS.
-func (in *KafkaAPITLS) DeepCopy() *KafkaAPITLS {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Retention.
+func (in *Retention) DeepCopy() *Retention {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaAPITLS)
+	out := new(Retention)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodesList) DeepCopyInto(out *NodesList) {
+func (in *RolloutEndpoints) DeepCopyInto(out *RolloutEndpoints) {
 	*out = *in
-	if in.Internal != nil {
-		in, out := &in.Internal, &out.Internal
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutEndpoints.
+func (in *RolloutEndpoints) DeepCopy() *RolloutEndpoints {
+	if in == nil {
+		return nil
 	}
-	if in.External != nil {
-		in, out := &in.External, &out.External
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out := new(RolloutEndpoints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutFailure) DeepCopyInto(out *RolloutFailure) {
+	*out = *in
+	in.FailedAt.DeepCopyInto(&out.FailedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutFailure.
+func (in *RolloutFailure) DeepCopy() *RolloutFailure {
+	if in == nil {
+		return nil
 	}
-	if in.ExternalAdmin != nil {
-		in, out := &in.ExternalAdmin, &out.ExternalAdmin
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out := new(RolloutFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutRollback) DeepCopyInto(out *RolloutRollback) {
+	*out = *in
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodesList.
-func (in *NodesList) DeepCopy() *NodesList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutRollback.
+func (in *RolloutRollback) DeepCopy() *RolloutRollback {
 	if in == nil {
 		return nil
 	}
-	out := new(NodesList)
+	out := new(RolloutRollback)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedpandaConfig) DeepCopyInto(out *RedpandaConfig) {
+func (in *ServiceCatalog) DeepCopyInto(out *ServiceCatalog) {
 	*out = *in
-	out.RPCServer = in.RPCServer
-	out.KafkaAPI = in.KafkaAPI
-	out.AdminAPI = in.AdminAPI
-	in.TLS.DeepCopyInto(&out.TLS)
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedpandaConfig.
-func (in *RedpandaConfig) DeepCopy() *RedpandaConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceCatalog.
+func (in *ServiceCatalog) DeepCopy() *ServiceCatalog {
 	if in == nil {
 		return nil
 	}
-	out := new(RedpandaConfig)
+	out := new(ServiceCatalog)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -273,10 +2216,45 @@ func (in *SocketAddress) DeepCopy() *SocketAddress {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageHints) DeepCopyInto(out *StorageHints) {
+	*out = *in
+	if in.MountOptions != nil {
+		in, out := &in.MountOptions, &out.MountOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageHints.
+func (in *StorageHints) DeepCopy() *StorageHints {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageHints)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageSpec) DeepCopyInto(out *StorageSpec) {
 	*out = *in
+	if in.EmptyDir != nil {
+		in, out := &in.EmptyDir, &out.EmptyDir
+		*out = new(EmptyDirStorage)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Capacity = in.Capacity.DeepCopy()
+	if in.PVCRetentionPolicy != nil {
+		in, out := &in.PVCRetentionPolicy, &out.PVCRetentionPolicy
+		*out = new(PVCRetentionPolicy)
+		**out = **in
+	}
+	if in.Hints != nil {
+		in, out := &in.Hints, &out.Hints
+		*out = new(StorageHints)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageSpec.
@@ -304,11 +2282,42 @@ func (in *Superuser) DeepCopy() *Superuser {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPKeepalive) DeepCopyInto(out *TCPKeepalive) {
+	*out = *in
+	if in.TimeSeconds != nil {
+		in, out := &in.TimeSeconds, &out.TimeSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IntervalSeconds != nil {
+		in, out := &in.IntervalSeconds, &out.IntervalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPKeepalive.
+func (in *TCPKeepalive) DeepCopy() *TCPKeepalive {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPKeepalive)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
 	*out = *in
 	in.KafkaAPI.DeepCopyInto(&out.KafkaAPI)
 	out.AdminAPI = in.AdminAPI
+	in.RPC.DeepCopyInto(&out.RPC)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
@@ -320,3 +2329,80 @@ func (in *TLSConfig) DeepCopy() *TLSConfig {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopicDefaults) DeepCopyInto(out *TopicDefaults) {
+	*out = *in
+	if in.AutoCreateTopics != nil {
+		in, out := &in.AutoCreateTopics, &out.AutoCreateTopics
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopicDefaults.
+func (in *TopicDefaults) DeepCopy() *TopicDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(TopicDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopicWarmup) DeepCopyInto(out *TopicWarmup) {
+	*out = *in
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]WarmupTopic, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopicWarmup.
+func (in *TopicWarmup) DeepCopy() *TopicWarmup {
+	if in == nil {
+		return nil
+	}
+	out := new(TopicWarmup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnboundVolume) DeepCopyInto(out *UnboundVolume) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnboundVolume.
+func (in *UnboundVolume) DeepCopy() *UnboundVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(UnboundVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmupTopic) DeepCopyInto(out *WarmupTopic) {
+	*out = *in
+	if in.ReplicationFactor != nil {
+		in, out := &in.ReplicationFactor, &out.ReplicationFactor
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmupTopic.
+func (in *WarmupTopic) DeepCopy() *WarmupTopic {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmupTopic)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -11,17 +11,21 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/afero"
+	"github.com/spf13/viper"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
 	"gopkg.in/yaml.v3"
 	corev1 "k8s.io/api/core/v1"
@@ -40,20 +44,52 @@ const (
 	externalConnectivityEnvVar          = "EXTERNAL_CONNECTIVITY"
 	externalConnectivitySubDomainEnvVar = "EXTERNAL_CONNECTIVITY_SUBDOMAIN"
 	hostPortEnvVar                      = "HOST_PORT"
+	podIPEnvVar                         = "POD_IP"
+	perBrokerConfigEnvVar               = "PER_BROKER_CONFIG"
+	adminAPIBindNetworkEnvVar           = "ADMIN_API_BIND_NETWORK"
+	internalListenerNameEnvVar          = "INTERNAL_LISTENER_NAME"
+	externalListenerNameEnvVar          = "EXTERNAL_LISTENER_NAME"
+	rackNodeLabelEnvVar                 = "RACK_NODE_LABEL"
+	proxyHostPortEnvVar                 = "PROXY_HOST_PORT"
+	proxySubdomainEnvVar                = "PROXY_EXTERNAL_SUBDOMAIN"
+	nodeAddressAnnotationEnvVar         = "NODE_ADDRESS_ANNOTATION"
+	advertisedAddressesDirEnvVar        = "ADVERTISED_ADDRESSES_DIR"
+
+	proxyInternalListenerName = "proxy"
+	proxyExternalListenerName = "proxy-external"
+
+	// the keys of the advertised addresses that override the address of
+	// the broker for one listener
+	advertisedAddressKafkaKey      = "kafka"
+	advertisedAddressPandaproxyKey = "pandaproxy"
+
+	// adminAddressFile is written next to the redpanda configuration and
+	// holds the address the Admin API listens on, for the readiness probe
+	adminAddressFile = "admin-address"
 )
 
 type brokerID int
 
 type configuratorConfig struct {
-	hostName             string
-	svcFQDN              string
-	configSourceDir      string
-	configDestination    string
-	nodeName             string
-	subdomain            string
-	externalConnectivity bool
-	redpandaRPCPort      int
-	hostPort             int
+	hostName               string
+	svcFQDN                string
+	configSourceDir        string
+	configDestination      string
+	nodeName               string
+	subdomain              string
+	externalConnectivity   bool
+	redpandaRPCPort        int
+	hostPort               int
+	podIP                  string
+	perBrokerConfig        string
+	adminAPIBindNetwork    string
+	internalListenerName   string
+	externalListenerName   string
+	rackNodeLabel          string
+	proxyHostPort          int
+	proxySubdomain         string
+	nodeAddressAnnotation  string
+	advertisedAddressesDir string
 }
 
 func (c *configuratorConfig) String() string {
@@ -66,7 +102,17 @@ func (c *configuratorConfig) String() string {
 		"externalConnectivity: %t\n"+
 		"externalConnectivitySubdomain: %s\n"+
 		"redpandaRPCPort: %d\n"+
-		"hostPort: %d\n",
+		"hostPort: %d\n"+
+		"podIP: %s\n"+
+		"perBrokerConfig: %s\n"+
+		"adminAPIBindNetwork: %s\n"+
+		"internalListenerName: %s\n"+
+		"externalListenerName: %s\n"+
+		"rackNodeLabel: %s\n"+
+		"proxyHostPort: %d\n"+
+		"proxySubdomain: %s\n"+
+		"nodeAddressAnnotation: %s\n"+
+		"advertisedAddressesDir: %s\n",
 		c.hostName,
 		c.svcFQDN,
 		c.configSourceDir,
@@ -75,11 +121,25 @@ func (c *configuratorConfig) String() string {
 		c.externalConnectivity,
 		c.subdomain,
 		c.redpandaRPCPort,
-		c.hostPort)
+		c.hostPort,
+		c.podIP,
+		c.perBrokerConfig,
+		c.adminAPIBindNetwork,
+		c.internalListenerName,
+		c.externalListenerName,
+		c.rackNodeLabel,
+		c.proxyHostPort,
+		c.proxySubdomain,
+		c.nodeAddressAnnotation,
+		c.advertisedAddressesDir)
 }
 
 var errorMissingEnvironmentVariable = errors.New("missing environment variable")
 
+var errNodeAddressMissing = errors.New("the node has no external address")
+
+var errAdvertisedAddressMissing = errors.New("the broker has no advertised address")
+
 func main() {
 	log.Print("The redpanda configurator is starting")
 
@@ -98,6 +158,17 @@ func main() {
 		log.Fatalf("%s", fmt.Errorf("unable to read the redpanda configuration file: %w", err))
 	}
 
+	hostIndex, err := hostIndex(c.hostName)
+	if err != nil {
+		log.Fatalf("%s", fmt.Errorf("unable to extract host index: %w", err))
+	}
+
+	log.Printf("Host index calculated %d", hostIndex)
+
+	if err = applyBrokerOverrides(v, c.perBrokerConfig, hostIndex); err != nil {
+		log.Fatalf("%s", fmt.Errorf("unable to apply the per broker configuration: %w", err))
+	}
+
 	cfg := &config.Config{}
 	decoderConfig := mapstructure.DecoderConfig{
 		Result: cfg,
@@ -119,22 +190,30 @@ func main() {
 
 	log.Print("Decode done")
 
-	kafkaAPIPort, err := getInternalKafkaAPIPort(cfg)
+	kafkaAPIPort, err := getInternalKafkaAPIPort(cfg, c.internalListenerName)
 	if err != nil {
 		log.Fatal(err)
 	}
-	hostIndex, err := hostIndex(c.hostName)
-	if err != nil {
-		log.Fatalf("%s", fmt.Errorf("unable to extract host index: %w", err))
-	}
-
-	log.Printf("Host index calculated %d", hostIndex)
 
 	err = registerAdvertisedKafkaAPI(&c, cfg, hostIndex, kafkaAPIPort)
 	if err != nil {
 		log.Fatalf("%s", fmt.Errorf("unable to register advertised kafka API: %w", err))
 	}
 
+	if err = registerAdvertisedPandaproxyAPI(&c, cfg, hostIndex); err != nil {
+		log.Fatalf("%s", fmt.Errorf("unable to register advertised pandaproxy API: %w", err))
+	}
+
+	if err = registerRack(&c, cfg); err != nil {
+		log.Fatalf("%s", fmt.Errorf("unable to register the rack: %w", err))
+	}
+
+	bindKafkaAPI(&c, cfg)
+
+	if err = bindAdminAPI(&c, cfg); err != nil {
+		log.Fatalf("%s", fmt.Errorf("unable to bind the admin API: %w", err))
+	}
+
 	cfg.Redpanda.Id = int(hostIndex)
 
 	// First Redpanda node need to have cleared seed servers in order
@@ -155,19 +234,72 @@ func main() {
 	}
 
 	log.Printf("Configuration saved to: %s", c.configDestination)
+
 }
 
 var errInternalPortMissing = errors.New("port configration is missing internal port")
 
-func getInternalKafkaAPIPort(cfg *config.Config) (int, error) {
+func getInternalKafkaAPIPort(cfg *config.Config, name string) (int, error) {
 	for _, l := range cfg.Redpanda.KafkaApi {
-		if l.Name == "Internal" {
+		if l.Name == name {
 			return l.Port, nil
 		}
 	}
 	return 0, fmt.Errorf("%w %v", errInternalPortMissing, cfg.Redpanda.KafkaApi)
 }
 
+// bindKafkaAPI makes the Kafka API listeners listen on the Pod IP when the
+// operator passed it, otherwise they keep listening on all interfaces
+func bindKafkaAPI(c *configuratorConfig, cfg *config.Config) {
+	if c.podIP == "" {
+		return
+	}
+	for i := range cfg.Redpanda.KafkaApi {
+		cfg.Redpanda.KafkaApi[i].Address = c.podIP
+	}
+}
+
+var errNoAddressInNetwork = errors.New("no interface address in network")
+
+// bindAdminAPI makes the Admin API listen on the address of the Pod in the
+// management network and records that address for the readiness probe
+func bindAdminAPI(c *configuratorConfig, cfg *config.Config) error {
+	if c.adminAPIBindNetwork == "" {
+		return nil
+	}
+	_, network, err := net.ParseCIDR(c.adminAPIBindNetwork)
+	if err != nil {
+		return fmt.Errorf("unable to parse %s: %w", adminAPIBindNetworkEnvVar, err)
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return fmt.Errorf("unable to list the interface addresses: %w", err)
+	}
+	address, err := addressInNetwork(addrs, network)
+	if err != nil {
+		return err
+	}
+	log.Printf("Binding the admin API to %s", address)
+	cfg.Redpanda.AdminApi.Address = address
+
+	path := filepath.Join(filepath.Dir(c.configDestination), adminAddressFile)
+	if err := ioutil.WriteFile(path, []byte(address), 0644); err != nil {
+		return fmt.Errorf("unable to write the admin API address: %w", err)
+	}
+	return nil
+}
+
+// addressInNetwork returns the first interface address inside the network
+func addressInNetwork(addrs []net.Addr, network *net.IPNet) (string, error) {
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if ok && network.Contains(ipNet.IP) {
+			return ipNet.IP.String(), nil
+		}
+	}
+	return "", fmt.Errorf("%w %s", errNoAddressInNetwork, network)
+}
+
 func registerAdvertisedKafkaAPI(
 	c *configuratorConfig, cfg *config.Config, index brokerID, kafkaAPIPort int,
 ) error {
@@ -177,7 +309,7 @@ func registerAdvertisedKafkaAPI(
 				Address: c.hostName + "." + c.svcFQDN,
 				Port:    kafkaAPIPort,
 			},
-			Name: "Internal",
+			Name: c.internalListenerName,
 		},
 	}
 
@@ -185,42 +317,180 @@ func registerAdvertisedKafkaAPI(
 		return nil
 	}
 
+	if c.advertisedAddressesDir != "" {
+		address, err := advertisedAddress(c.advertisedAddressesDir, index, advertisedAddressKafkaKey)
+		if err != nil {
+			return err
+		}
+		cfg.Redpanda.AdvertisedKafkaApi = append(cfg.Redpanda.AdvertisedKafkaApi, config.NamedSocketAddress{
+			SocketAddress: config.SocketAddress{
+				Address: address,
+				Port:    c.hostPort,
+			},
+			Name: c.externalListenerName,
+		})
+		return nil
+	}
+
 	if len(c.subdomain) > 0 {
 		cfg.Redpanda.AdvertisedKafkaApi = append(cfg.Redpanda.AdvertisedKafkaApi, config.NamedSocketAddress{
 			SocketAddress: config.SocketAddress{
 				Address: fmt.Sprintf("%d.%s", index, c.subdomain),
 				Port:    c.hostPort,
 			},
-			Name: "External",
+			Name: c.externalListenerName,
+		})
+		return nil
+	}
+
+	node, err := getNode(c.nodeName)
+	if err != nil {
+		return err
+	}
+	address, err := externalAddress(node, c.nodeAddressAnnotation)
+	if err != nil {
+		return err
+	}
+
+	cfg.Redpanda.AdvertisedKafkaApi = append(cfg.Redpanda.AdvertisedKafkaApi, config.NamedSocketAddress{
+		SocketAddress: config.SocketAddress{
+			Address: address,
+			Port:    c.hostPort,
+		},
+		Name: c.externalListenerName,
+	})
+	return nil
+}
+
+// registerAdvertisedPandaproxyAPI advertises the internal Pandaproxy listener
+// under the headless Service address and the external one, when it is
+// exposed, like the external Kafka API listener
+func registerAdvertisedPandaproxyAPI(
+	c *configuratorConfig, cfg *config.Config, index brokerID,
+) error {
+	if cfg.Pandaproxy == nil {
+		return nil
+	}
+	cfg.Pandaproxy.AdvertisedPandaproxyAPI = nil
+	for _, l := range cfg.Pandaproxy.PandaproxyAPI {
+		if l.Name != proxyInternalListenerName {
+			continue
+		}
+		cfg.Pandaproxy.AdvertisedPandaproxyAPI = append(cfg.Pandaproxy.AdvertisedPandaproxyAPI, config.NamedSocketAddress{
+			SocketAddress: config.SocketAddress{
+				Address: c.hostName + "." + c.svcFQDN,
+				Port:    l.Port,
+			},
+			Name: proxyInternalListenerName,
 		})
+	}
+
+	if c.proxyHostPort == 0 {
 		return nil
 	}
 
+	address := fmt.Sprintf("%d.%s", index, c.proxySubdomain)
+	if c.advertisedAddressesDir != "" {
+		var err error
+		if address, err = advertisedAddress(c.advertisedAddressesDir, index, advertisedAddressPandaproxyKey); err != nil {
+			return err
+		}
+	} else if c.proxySubdomain == "" {
+		node, err := getNode(c.nodeName)
+		if err != nil {
+			return err
+		}
+		if address, err = externalAddress(node, c.nodeAddressAnnotation); err != nil {
+			return err
+		}
+	}
+	cfg.Pandaproxy.AdvertisedPandaproxyAPI = append(cfg.Pandaproxy.AdvertisedPandaproxyAPI, config.NamedSocketAddress{
+		SocketAddress: config.SocketAddress{
+			Address: address,
+			Port:    c.proxyHostPort,
+		},
+		Name: proxyExternalListenerName,
+	})
+	return nil
+}
+
+// advertisedAddress returns the address the broker advertises on the
+// listener, read from the mounted ConfigMap or Secret of the advertised
+// addresses. The key of the listener overrides the key of the broker.
+func advertisedAddress(dir string, index brokerID, listener string) (string, error) {
+	for _, key := range []string{fmt.Sprintf("%d.%s", index, listener), strconv.Itoa(int(index))} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, key))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("unable to read the advertised address %s: %w", key, err)
+		}
+		if address := strings.TrimSpace(string(b)); address != "" {
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("neither %d.%s nor %d in %s: %w", index, listener, index, dir, errAdvertisedAddressMissing)
+}
+
+// getNode retrieves the node the broker is scheduled on
+func getNode(name string) (*corev1.Node, error) {
 	k8sconfig, err := rest.InClusterConfig()
 	if err != nil {
-		return fmt.Errorf("unable to create in cluster config: %w", err)
+		return nil, fmt.Errorf("unable to create in cluster config: %w", err)
 	}
 
 	clientset, err := kubernetes.NewForConfig(k8sconfig)
 	if err != nil {
-		return fmt.Errorf("unable to create clientset: %w", err)
+		return nil, fmt.Errorf("unable to create clientset: %w", err)
 	}
 
-	node, err := clientset.CoreV1().Nodes().Get(context.Background(), c.nodeName, metav1.GetOptions{})
+	node, err := clientset.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("unable to retrieve node: %w", err)
+		return nil, fmt.Errorf("unable to retrieve node: %w", err)
 	}
+	return node, nil
+}
 
-	cfg.Redpanda.AdvertisedKafkaApi = append(cfg.Redpanda.AdvertisedKafkaApi, config.NamedSocketAddress{
-		SocketAddress: config.SocketAddress{
-			Address: getExternalIP(node),
-			Port:    c.hostPort,
-		},
-		Name: "External",
-	})
+// registerRack sets the rack of the broker to the value of the rack label of
+// its node. A node without the label leaves the broker without a rack.
+func registerRack(c *configuratorConfig, cfg *config.Config) error {
+	if c.rackNodeLabel == "" {
+		return nil
+	}
+	node, err := getNode(c.nodeName)
+	if err != nil {
+		return err
+	}
+	rack, ok := node.Labels[c.rackNodeLabel]
+	if !ok || rack == "" {
+		log.Printf("Node %s has no %s label, the broker has no rack", c.nodeName, c.rackNodeLabel)
+		return nil
+	}
+	if cfg.Redpanda.Other == nil {
+		cfg.Redpanda.Other = map[string]interface{}{}
+	}
+	cfg.Redpanda.Other["rack"] = rack
+	log.Printf("Rack of the broker: %s", rack)
 	return nil
 }
 
+// externalAddress returns the address the broker advertises externally, the
+// value of the annotation of its node or the ExternalIP address of the node.
+// A node with neither is an error, the broker would advertise no address.
+func externalAddress(node *corev1.Node, annotation string) (string, error) {
+	if address := node.Annotations[annotation]; annotation != "" && address != "" {
+		return address, nil
+	}
+	if address := getExternalIP(node); address != "" {
+		return address, nil
+	}
+	if annotation != "" {
+		return "", fmt.Errorf("node %s has neither the %s annotation nor an ExternalIP address: %w", node.Name, annotation, errNodeAddressMissing)
+	}
+	return "", fmt.Errorf("node %s has no ExternalIP address: %w", node.Name, errNodeAddressMissing)
+}
+
 func getExternalIP(node *corev1.Node) string {
 	if node == nil {
 		return ""
@@ -290,6 +560,38 @@ func checkEnvVars() (configuratorConfig, error) {
 		*envVar.value = v
 	}
 
+	// The Pod IP is only passed when the Kafka API binds to it
+	c.podIP = os.Getenv(podIPEnvVar)
+	// The per broker configuration is only passed when overrides are set
+	c.perBrokerConfig = os.Getenv(perBrokerConfigEnvVar)
+	// The management network is only passed when the Admin API binds to it
+	c.adminAPIBindNetwork = os.Getenv(adminAPIBindNetworkEnvVar)
+	// The listener names are only passed when they differ from the defaults
+	c.internalListenerName = "Internal"
+	if name, ok := os.LookupEnv(internalListenerNameEnvVar); ok {
+		c.internalListenerName = name
+	}
+	c.externalListenerName = "External"
+	if name, ok := os.LookupEnv(externalListenerNameEnvVar); ok {
+		c.externalListenerName = name
+	}
+	// The rack node label is only passed when rack awareness is enabled
+	c.rackNodeLabel = os.Getenv(rackNodeLabelEnvVar)
+	// The node address annotation is only passed when it is configured
+	c.nodeAddressAnnotation = os.Getenv(nodeAddressAnnotationEnvVar)
+	// The advertised addresses are only mounted when they come from a
+	// ConfigMap or Secret
+	c.advertisedAddressesDir = os.Getenv(advertisedAddressesDirEnvVar)
+	// The Pandaproxy node port is only passed when the listener is exposed
+	c.proxySubdomain = os.Getenv(proxySubdomainEnvVar)
+	if port, ok := os.LookupEnv(proxyHostPortEnvVar); ok {
+		proxyHostPort, err := strconv.Atoi(port)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("unable to convert pandaproxy host port from string to int: %w", err))
+		}
+		c.proxyHostPort = proxyHostPort
+	}
+
 	extCon, exist := os.LookupEnv(externalConnectivityEnvVar)
 	if !exist {
 		result = multierror.Append(result, fmt.Errorf("%s %w", externalConnectivityEnvVar, errorMissingEnvironmentVariable))
@@ -314,6 +616,31 @@ func checkEnvVars() (configuratorConfig, error) {
 	return c, result
 }
 
+// applyBrokerOverrides sets the redpanda properties configured for the broker
+// on top of the base configuration. The overrides are a JSON object keyed by
+// the broker ordinal, values are parsed as YAML so numbers and booleans keep
+// their type.
+func applyBrokerOverrides(
+	v *viper.Viper, perBrokerConfig string, index brokerID,
+) error {
+	if perBrokerConfig == "" {
+		return nil
+	}
+	var overrides map[string]map[string]string
+	if err := json.Unmarshal([]byte(perBrokerConfig), &overrides); err != nil {
+		return fmt.Errorf("unable to parse %s: %w", perBrokerConfigEnvVar, err)
+	}
+	for key, value := range overrides[strconv.Itoa(int(index))] {
+		var parsed interface{}
+		if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+			parsed = value
+		}
+		log.Printf("Overriding redpanda.%s for broker %d", key, index)
+		v.Set("redpanda."+key, parsed)
+	}
+	return nil
+}
+
 // hostIndex takes advantage of pod naming convention in Kubernetes StatfulSet
 // the last number is the index of replica. This index is then propagated
 // to redpanda.node_id.
@@ -0,0 +1,173 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	reasonAdminAPIStepsApplied = "AdminAPIStepsApplied"
+	reasonAdminAPIStepsSkipped = "AdminAPIStepsSkipped"
+	reasonEndpointsServed      = "EndpointsServed"
+	reasonEndpointsNotServed   = "EndpointsNotServed"
+	eventAdminAPIUnsupported   = "AdminAPIUnsupported"
+
+	// adminAPIRequeue is the delay before skipped Admin API steps are tried
+	// again
+	adminAPIRequeue = 10 * time.Second
+)
+
+// skippedPhase returns the message of a skipped Admin API step, for the
+// current operation
+func skippedPhase(skipped error) string {
+	var e *resources.RequeueAfterError
+	if errors.As(skipped, &e) {
+		return e.Msg
+	}
+	if skipped != nil {
+		return skipped.Error()
+	}
+	return ""
+}
+
+// reportAdminAPIAvailable sets the AdminAPIUnavailable condition while steps
+// that need the Admin API are skipped. The condition stays false, rather than
+// absent, once the steps succeed again.
+func (r *ClusterReconciler) reportAdminAPIAvailable(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster, skipped error,
+) error {
+	status, reason, message := corev1.ConditionFalse, reasonAdminAPIStepsApplied, "the Admin API steps succeeded"
+	if skipped != nil {
+		status, reason, message = corev1.ConditionTrue, reasonAdminAPIStepsSkipped, skippedPhase(skipped)
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if skipped == nil && cluster.Status.GetCondition(redpandav1alpha1.ClusterAdminAPIUnavailable) == nil {
+			return nil
+		}
+		if !cluster.Status.SetCondition(redpandav1alpha1.ClusterAdminAPIUnavailable, status, reason, message) {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the Admin API condition: %w", err)
+	}
+	return nil
+}
+
+// notServed returns the error of a step that needs an Admin API endpoint
+// the running version does not serve, or nil for any other error
+func notServed(step string, err error) error {
+	if !admin.IsUnsupported(err) {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", step, err)
+}
+
+// withFallback returns err, or the error of notServed the step fell back
+// without when err is nil
+func withFallback(err, fallback error) error {
+	if err != nil {
+		return err
+	}
+	return fallback
+}
+
+// unsupportedSteps merges the steps and the endpoints they need into the
+// sorted list reported before, the same step is listed once
+func unsupportedSteps(reported []string, unsupported []error) []string {
+	seen := make(map[string]bool, len(reported)+len(unsupported))
+	steps := make([]string, 0, len(reported)+len(unsupported))
+	for _, step := range reported {
+		seen[step] = true
+		steps = append(steps, step)
+	}
+	for _, err := range unsupported {
+		step := strings.TrimSuffix(err.Error(), ": "+admin.ErrUnsupported.Error())
+		if !seen[step] {
+			seen[step] = true
+			steps = append(steps, step)
+		}
+	}
+	sort.Strings(steps)
+	return steps
+}
+
+// reportAdminAPIUnsupported sets the AdminAPIUnsupported condition while
+// steps need Admin API endpoints the running version does not serve and
+// emits a warning event when the list grows. Most steps call their endpoints
+// only now and then, so the steps are kept from one reconcile to the next
+// until the operator restarts. The condition stays false, rather than
+// absent, once an upgrade serves the endpoints.
+func (r *ClusterReconciler) reportAdminAPIUnsupported(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster, unsupported []error,
+) error {
+	var reported []string
+	if v, ok := r.unsupportedSteps.Load(redpandaCluster.UID); ok {
+		reported = v.([]string)
+	}
+	steps := unsupportedSteps(reported, unsupported)
+	r.unsupportedSteps.Store(redpandaCluster.UID, steps)
+
+	status, reason, message := corev1.ConditionFalse, reasonEndpointsServed, "the Admin API serves the endpoints of every step"
+	if len(steps) > 0 {
+		status, reason, message = corev1.ConditionTrue, reasonEndpointsNotServed,
+			"the Admin API of this Redpanda version does not serve the endpoints of these steps: "+strings.Join(steps, "; ")
+	}
+
+	changed := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if len(steps) == 0 && cluster.Status.GetCondition(redpandav1alpha1.ClusterAdminAPIUnsupported) == nil {
+			return nil
+		}
+		changed = cluster.Status.SetCondition(redpandav1alpha1.ClusterAdminAPIUnsupported, status, reason, message)
+		if !changed {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the Admin API support condition: %w", err)
+	}
+	if changed && status == corev1.ConditionTrue && r.Recorder != nil {
+		r.Recorder.Event(redpandaCluster, corev1.EventTypeWarning, eventAdminAPIUnsupported, message)
+	}
+	return nil
+}
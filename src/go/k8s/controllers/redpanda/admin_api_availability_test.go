@@ -0,0 +1,83 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReportAdminAPIAvailable(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+	}
+	f := newFixture(t, cluster)
+
+	// a cluster that never lost the Admin API has no condition
+	require.NoError(t, f.reportAdminAPIAvailable(context.Background(), cluster, nil))
+	actual := f.get(t, "cluster")
+	assert.Nil(t, actual.Status.GetCondition(redpandav1alpha1.ClusterAdminAPIUnavailable))
+
+	skipped := &resources.RequeueAfterError{Msg: "unable to retrieve cluster configuration: connection refused"}
+	require.NoError(t, f.reportAdminAPIAvailable(context.Background(), cluster, skipped))
+	actual = f.get(t, "cluster")
+	condition := actual.Status.GetCondition(redpandav1alpha1.ClusterAdminAPIUnavailable)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, "unable to retrieve cluster configuration: connection refused", condition.Message)
+
+	require.NoError(t, f.reportAdminAPIAvailable(context.Background(), cluster, nil))
+	actual = f.get(t, "cluster")
+	condition = actual.Status.GetCondition(redpandav1alpha1.ClusterAdminAPIUnavailable)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionFalse, condition.Status)
+}
+
+func TestReportAdminAPIUnsupported(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default", UID: "uid"},
+	}
+	f := newFixture(t, cluster)
+
+	assert.Nil(t, notServed("broker failure", errors.New("connection refused")))
+
+	// a cluster whose steps got every endpoint has no condition
+	require.NoError(t, f.reportAdminAPIUnsupported(context.Background(), cluster, nil))
+	actual := f.get(t, "cluster")
+	assert.Nil(t, actual.Status.GetCondition(redpandav1alpha1.ClusterAdminAPIUnsupported))
+
+	brokers := notServed("broker failure", fmt.Errorf("GET /v1/brokers: %w", admin.ErrUnsupported))
+	health := notServed("cluster readiness", fmt.Errorf("GET /v1/cluster/health_overview: %w", admin.ErrUnsupported))
+	require.NoError(t, f.reportAdminAPIUnsupported(context.Background(), cluster, []error{brokers, health, brokers}))
+	actual = f.get(t, "cluster")
+	condition := actual.Status.GetCondition(redpandav1alpha1.ClusterAdminAPIUnsupported)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, "the Admin API of this Redpanda version does not serve the endpoints of these steps: "+
+		"broker failure: GET /v1/brokers; cluster readiness: GET /v1/cluster/health_overview", condition.Message)
+	require.Len(t, f.recorder.Events, 1)
+	assert.Contains(t, <-f.recorder.Events, eventAdminAPIUnsupported)
+
+	// steps that did not call their endpoints this time stay listed
+	require.NoError(t, f.reportAdminAPIUnsupported(context.Background(), cluster, nil))
+	actual = f.get(t, "cluster")
+	assert.Equal(t, condition.Message, actual.Status.GetCondition(redpandav1alpha1.ClusterAdminAPIUnsupported).Message)
+	assert.Empty(t, f.recorder.Events)
+}
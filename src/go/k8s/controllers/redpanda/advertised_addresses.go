@@ -0,0 +1,98 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	advertisedAddressDialTimeout = 2 * time.Second
+
+	reasonAddressesReachable   = "AddressesReachable"
+	reasonAddressesUnreachable = "AddressesUnreachable"
+)
+
+// WithAdvertisedAddressCheck makes the reconciler connect to every advertised
+// external Kafka API address. The operator may not reach the addresses the
+// same way clients do, so the check is opt-in.
+func (r *ClusterReconciler) WithAdvertisedAddressCheck(
+	enabled bool,
+) *ClusterReconciler {
+	r.checkAdvertisedAddresses = enabled
+	return r
+}
+
+// reportAdvertisedAddresses records in the Cluster conditions whether the
+// advertised external addresses accept connections and emits a warning event
+// for the ones that do not
+func (r *ClusterReconciler) reportAdvertisedAddresses(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	nodesExternal []string,
+) error {
+	if !r.checkAdvertisedAddresses || !redpandaCluster.Spec.ExternalConnectivity.Enabled || len(nodesExternal) == 0 {
+		return nil
+	}
+
+	unreachable := unreachableAddresses(ctx, nodesExternal)
+	status, reason, message := corev1.ConditionTrue, reasonAddressesReachable, "all advertised addresses accept connections"
+	if len(unreachable) > 0 {
+		status, reason = corev1.ConditionFalse, reasonAddressesUnreachable
+		message = fmt.Sprintf("advertised addresses are unreachable from the operator: %s", strings.Join(unreachable, "; "))
+		if r.Recorder != nil {
+			r.Recorder.Event(redpandaCluster, corev1.EventTypeWarning, reason, message)
+		}
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if !cluster.Status.SetCondition(redpandav1alpha1.ClusterAdvertisedAddressesReachable, status, reason, message) {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update advertised addresses condition: %w", err)
+	}
+	return nil
+}
+
+// unreachableAddresses returns the addresses that cannot be resolved or that
+// refuse connections, each followed by the reason
+func unreachableAddresses(ctx context.Context, addresses []string) []string {
+	dialer := net.Dialer{Timeout: advertisedAddressDialTimeout}
+	var unreachable []string
+	for _, address := range addresses {
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			unreachable = append(unreachable, fmt.Sprintf("%s (%v)", address, err))
+			continue
+		}
+		conn.Close() // nolint:errcheck // the connection was only a probe
+	}
+	return unreachable
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestUnreachableAddresses(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddress := closed.Addr().String()
+	require.NoError(t, closed.Close())
+
+	unreachable := unreachableAddresses(context.Background(), []string{listener.Addr().String(), closedAddress})
+	require.Len(t, unreachable, 1)
+	assert.Contains(t, unreachable[0], closedAddress)
+}
+
+func TestSetCondition(t *testing.T) {
+	var status redpandav1alpha1.ClusterStatus
+	conditionType := redpandav1alpha1.ClusterAdvertisedAddressesReachable
+
+	assert.True(t, status.SetCondition(conditionType, corev1.ConditionFalse, reasonAddressesUnreachable, "unreachable"))
+	assert.False(t, status.SetCondition(conditionType, corev1.ConditionFalse, reasonAddressesUnreachable, "unreachable"))
+	assert.True(t, status.SetCondition(conditionType, corev1.ConditionTrue, reasonAddressesReachable, "reachable"))
+	require.Len(t, status.Conditions, 1)
+	assert.Equal(t, corev1.ConditionTrue, status.GetCondition(conditionType).Status)
+}
+
+func TestExternalAddress(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"example.com/public-ip": "203.0.113.7"}},
+		Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeInternalIP, Address: "10.0.0.7"},
+			{Type: corev1.NodeExternalIP, Address: "198.51.100.7"},
+		}},
+	}
+	assert.Equal(t, "198.51.100.7", externalAddress(node, ""))
+	assert.Equal(t, "203.0.113.7", externalAddress(node, "example.com/public-ip"))
+	assert.Equal(t, "198.51.100.7", externalAddress(node, "example.com/other-ip"))
+
+	node.Status.Addresses = node.Status.Addresses[:1]
+	assert.Empty(t, externalAddress(node, "example.com/other-ip"))
+}
+
+func TestCreateExternalNodesListWithPandaproxy(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			ExternalConnectivity: redpandav1alpha1.ExternalConnectivityConfig{Enabled: true, Subdomain: "example.com"},
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				PandaproxyAPI: &redpandav1alpha1.PandaproxyAPI{
+					Port:     8082,
+					External: redpandav1alpha1.ExternalListener{Enabled: true},
+				},
+			},
+		},
+	}
+	key := types.NamespacedName{Name: "cluster-external", Namespace: "default"}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeNodePort,
+			// the Pandaproxy port comes first to show that the ports are
+			// not matched by position
+			Ports: []corev1.ServicePort{
+				{Name: resources.PandaproxyPortName, Port: 8083, NodePort: 30083},
+				{Name: resources.AdminPortName, Port: 9644, NodePort: 30644},
+				{Name: resources.KafkaPortName, Port: 9093, NodePort: 30093},
+			},
+		},
+	}
+	pods := []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "cluster-0", GenerateName: "cluster-"}}}
+	f := newFixture(t, svc)
+
+	external, externalAdmin, err := f.createExternalNodesList(context.Background(), pods, cluster, key)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0.example.com:30093"}, external)
+	assert.Equal(t, []string{"0.example.com:30644"}, externalAdmin)
+
+	// a missing Admin API node port is reported even with three ports
+	svc.Spec.Ports[1].Name = "other"
+	require.NoError(t, f.Update(context.Background(), svc))
+	_, _, err = f.createExternalNodesList(context.Background(), pods, cluster, key)
+	assert.True(t, errors.Is(err, errNodePortMissing))
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	reasonBackupCurrent           = "BackupCurrent"
+	reasonBackupUnverified        = "BackupUnverified"
+	reasonArchivalIntervalTooLong = "ArchivalIntervalTooLong"
+
+	// archivalIntervalProperty is the cluster property setting how often the
+	// archiver uploads the closed segments to the cloud storage bucket
+	archivalIntervalProperty = "cloud_storage_reconciliation_interval_ms"
+
+	// backupRetryInterval is how soon a recovery point that could not be
+	// confirmed is checked again
+	backupRetryInterval = time.Minute
+)
+
+// reconcileBackup confirms a recovery point in the cloud storage bucket once
+// per backup interval. The segments closed before one interval ago are in
+// the bucket while the archiver uploads at least that often and every broker
+// is up with all partitions having a leader. The BackupOverdue
+// condition is set while it can not be confirmed. It is unknown, and no
+// recovery point is recorded, when the running version does not report the
+// cluster health. The returned duration is how long it takes until the next
+// recovery point is due.
+func (r *ClusterReconciler) reconcileBackup(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+) (time.Duration, error) {
+	if redpandaCluster.Spec.Backup == nil || !redpandaCluster.Spec.CloudStorage.Enabled {
+		return 0, nil
+	}
+	interval := redpandaCluster.BackupInterval()
+	previous := redpandaCluster.Status.Backup
+	if previous != nil {
+		if wait := time.Until(previous.LastBackupTime.Add(interval)); wait > 0 {
+			return wait, nil
+		}
+	}
+
+	reason, message, fallback := r.checkRecoveryPoint(ctx, redpandaCluster, fqdn, adminTLSProvider, interval)
+	status := corev1.ConditionTrue
+	recorded := previous
+	next := backupRetryInterval
+	switch reason {
+	case reasonBackupUnverified:
+		status = corev1.ConditionUnknown
+		next = interval
+	case reasonBackupCurrent:
+		now := time.Now()
+		status = corev1.ConditionFalse
+		recorded = &redpandav1alpha1.BackupStatus{
+			LastBackupTime: metav1.NewTime(now),
+			RecoveryPoint:  metav1.NewTime(now.Add(-interval)),
+		}
+		message = "the data written up to " + recorded.RecoveryPoint.UTC().Format(time.RFC3339) + " is in the bucket"
+		next = interval
+	}
+
+	var previousReason string
+	if c := redpandaCluster.Status.GetCondition(redpandav1alpha1.ClusterBackupOverdue); c != nil {
+		previousReason = c.Reason
+	}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		changed := cluster.Status.SetCondition(redpandav1alpha1.ClusterBackupOverdue, status, reason, message)
+		if !apiequality.Semantic.DeepEqual(recorded, cluster.Status.Backup) {
+			cluster.Status.Backup = recorded
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return next, fmt.Errorf("failed to update the backup status: %w", err)
+	}
+	redpandaCluster.Status.Backup = recorded
+	if reason != previousReason && r.Recorder != nil {
+		eventType := corev1.EventTypeNormal
+		if status != corev1.ConditionFalse {
+			eventType = corev1.EventTypeWarning
+		}
+		r.Recorder.Event(redpandaCluster, eventType, reason, message)
+	}
+	return next, fallback
+}
+
+// checkRecoveryPoint returns reasonBackupCurrent when the segments closed one
+// interval ago are in the bucket, otherwise the reason and message why they
+// are not. The error of the health endpoint is returned with
+// reasonBackupUnverified when the running version does not serve it.
+func (r *ClusterReconciler) checkRecoveryPoint(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+	interval time.Duration,
+) (reason, message string, fallback error) {
+	adminAPI, err := r.AdminAPIClientFactory(ctx, r, redpandaCluster, fqdn, adminTLSProvider)
+	if err != nil {
+		return reasonAdminAPIUnavailable, fmt.Sprintf("unable to create admin API client: %v", err), nil
+	}
+	config, err := adminAPI.ClusterConfig(ctx)
+	if err != nil {
+		return reasonAdminAPIUnavailable, fmt.Sprintf("unable to retrieve cluster configuration: %v", err), nil
+	}
+	// the Admin API decodes all numbers as floats, a missing property keeps
+	// its default of 10s
+	if archival, ok := config[archivalIntervalProperty].(float64); ok && time.Duration(archival)*time.Millisecond > interval {
+		return reasonArchivalIntervalTooLong, fmt.Sprintf("%s is %s, closed segments are not uploaded every %s",
+			archivalIntervalProperty, time.Duration(archival)*time.Millisecond, interval), nil
+	}
+	health, err := adminAPI.ClusterHealth(ctx)
+	if fallback := notServed("backup", err); fallback != nil {
+		// the uploads of brokers that are down can not be ruled out
+		return reasonBackupUnverified, "the running version does not report the cluster health, the recovery point can not be confirmed", fallback
+	}
+	if err != nil {
+		return reasonAdminAPIUnavailable, fmt.Sprintf("unable to retrieve cluster health: %v", err), nil
+	}
+	if len(health.NodesDown) != 0 || len(health.LeaderlessPartitions) != 0 {
+		return reasonClusterUnhealthy, fmt.Sprintf("%d brokers are down and %d partitions have no leader, their data may not be uploaded",
+			len(health.NodesDown), len(health.LeaderlessPartitions)), nil
+	}
+	return reasonBackupCurrent, "", nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReconcileBackup(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			CloudStorage: redpandav1alpha1.CloudStorageConfig{Enabled: true},
+			Backup: &redpandav1alpha1.Backup{
+				Interval: &metav1.Duration{Duration: 10 * time.Minute},
+			},
+		},
+	}
+	f := newFixture(t, cluster)
+	reconcile := func() (time.Duration, redpandav1alpha1.Cluster, error) {
+		wait, err := f.reconcileBackup(context.Background(), cluster, "cluster.local", nil)
+		actual := f.get(t, "cluster")
+		cluster = &actual
+		return wait, actual, err
+	}
+
+	// no recovery point while the archiver uploads less often than the
+	// interval, the Admin API decodes all numbers as floats
+	f.adminAPI.Config[archivalIntervalProperty] = float64(20 * time.Minute / time.Millisecond)
+	wait, actual, err := reconcile()
+	require.NoError(t, err)
+	assert.Equal(t, backupRetryInterval, wait)
+	assert.Nil(t, actual.Status.Backup)
+	condition := actual.Status.GetCondition(redpandav1alpha1.ClusterBackupOverdue)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, reasonArchivalIntervalTooLong, condition.Reason)
+	assert.Contains(t, <-f.recorder.Events, "Warning ArchivalIntervalTooLong")
+
+	// without the cluster health the uploads of down brokers can not be
+	// ruled out, no recovery point is recorded
+	f.adminAPI.Config[archivalIntervalProperty] = float64(10000)
+	wait, actual, err = reconcile()
+	assert.True(t, admin.IsUnsupported(err))
+	assert.Equal(t, 10*time.Minute, wait)
+	assert.Nil(t, actual.Status.Backup)
+	condition = actual.Status.GetCondition(redpandav1alpha1.ClusterBackupOverdue)
+	assert.Equal(t, corev1.ConditionUnknown, condition.Status)
+	assert.Equal(t, reasonBackupUnverified, condition.Reason)
+	assert.Contains(t, <-f.recorder.Events, "Warning BackupUnverified")
+}
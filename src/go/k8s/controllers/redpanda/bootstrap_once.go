@@ -0,0 +1,93 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+const reasonBootstrapped = "Bootstrapped"
+
+// bootstrapComplete returns true when a bootstrap-once cluster finished
+// bootstrapping the current generation of its spec
+func bootstrapComplete(cluster *redpandav1alpha1.Cluster) bool {
+	if cluster.Spec.Mode != redpandav1alpha1.ManagementModeBootstrapOnce {
+		return false
+	}
+	condition := cluster.Status.GetCondition(redpandav1alpha1.ClusterBootstrapComplete)
+	return condition != nil &&
+		condition.Status == corev1.ConditionTrue &&
+		condition.ObservedGeneration == cluster.Generation
+}
+
+// reportBootstrapComplete sets the bootstrap complete condition of a
+// bootstrap-once cluster when every broker is ready and the Admin API
+// reports a healthy cluster
+func (r *ClusterReconciler) reportBootstrapComplete(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	sts *appsv1.StatefulSet,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+) error {
+	if redpandaCluster.Spec.Mode != redpandav1alpha1.ManagementModeBootstrapOnce ||
+		redpandaCluster.Spec.Replicas == nil || sts == nil ||
+		sts.Status.ReadyReplicas != *redpandaCluster.Spec.Replicas ||
+		redpandaCluster.Status.Upgrading {
+		return nil
+	}
+
+	adminAPI, err := r.AdminAPIClientFactory(ctx, r, redpandaCluster, fqdn, adminTLSProvider)
+	if err != nil {
+		return fmt.Errorf("unable to create admin API client: %w", err)
+	}
+	health, err := adminAPI.ClusterHealth(ctx)
+	fallback := notServed("bootstrap completion", err)
+	if fallback != nil {
+		// this Redpanda version does not report the cluster health, every
+		// broker being ready has to do
+		health, err = admin.ClusterHealthOverview{IsHealthy: true}, nil
+	}
+	if err != nil || !health.IsHealthy {
+		r.Log.Info("Waiting for a healthy cluster to complete the bootstrap", "error", err)
+		return nil
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if cluster.Generation != redpandaCluster.Generation {
+			// the spec changed in the meantime and has to be reconciled first
+			return nil
+		}
+		cluster.Status.SetCondition(redpandav1alpha1.ClusterBootstrapComplete, corev1.ConditionTrue,
+			reasonBootstrapped, "the operator stopped reconciling the cluster until its spec changes")
+		cluster.Status.GetCondition(redpandav1alpha1.ClusterBootstrapComplete).ObservedGeneration = cluster.Generation
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update bootstrap complete condition: %w", err)
+	}
+	return fallback
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestBootstrapOnce(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default", Generation: 1},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replicas: pointer.Int32Ptr(3),
+			Mode:     redpandav1alpha1.ManagementModeBootstrapOnce,
+		},
+	}
+	f := newFixture(t, cluster)
+
+	report := func(ready int32) (*redpandav1alpha1.Cluster, error) {
+		sts := &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: ready}}
+		err := f.reportBootstrapComplete(context.Background(), cluster, sts, "cluster.local", nil)
+		actual := f.get(t, "cluster")
+		return &actual, err
+	}
+
+	notReady, err := report(2)
+	require.NoError(t, err)
+	assert.False(t, bootstrapComplete(notReady), "brokers are not ready")
+
+	// without the cluster health every broker being ready has to do
+	bootstrapped, err := report(3)
+	assert.True(t, admin.IsUnsupported(err))
+	assert.True(t, bootstrapComplete(bootstrapped))
+
+	bootstrapped.Generation++
+	assert.False(t, bootstrapComplete(bootstrapped), "spec changed")
+
+	bootstrapped.Spec.Mode = redpandav1alpha1.ManagementModeManaged
+	bootstrapped.Generation--
+	assert.False(t, bootstrapComplete(bootstrapped), "managed clusters are always reconciled")
+}
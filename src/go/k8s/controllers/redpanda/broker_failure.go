@@ -0,0 +1,172 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	reasonBrokersAlive = "BrokersAlive"
+	reasonBrokersDown  = "BrokersDown"
+
+	eventFailedBrokerDecommission = "FailedBrokerDecommission"
+
+	// brokerFailureRequeue is how often down brokers are checked, so the end
+	// of their grace period is noticed without a change of a watched object
+	brokerFailureRequeue = 30 * time.Second
+)
+
+// downBrokers returns the active members of the Pod ordinals that are not
+// alive, ordered by node ID. The time a broker was first seen down is kept
+// from the previous report, brokers seen down for the first time get now.
+func downBrokers(
+	previous []redpandav1alpha1.DownBroker,
+	brokers []admin.Broker,
+	replicas int,
+	now time.Time,
+) []redpandav1alpha1.DownBroker {
+	since := make(map[int]metav1.Time, len(previous))
+	for _, b := range previous {
+		since[b.NodeID] = b.Since
+	}
+	var down []redpandav1alpha1.DownBroker
+	for _, b := range brokers {
+		if b.NodeID < 0 || b.NodeID >= replicas ||
+			b.MembershipStatus != membershipActive || b.IsAlive == nil || *b.IsAlive {
+			continue
+		}
+		t, ok := since[b.NodeID]
+		if !ok {
+			t = metav1.NewTime(now)
+		}
+		down = append(down, redpandav1alpha1.DownBroker{NodeID: b.NodeID, Since: t})
+	}
+	sort.Slice(down, func(i, j int) bool { return down[i].NodeID < down[j].NodeID })
+	return down
+}
+
+// failedBrokers returns the down brokers past the grace period
+func failedBrokers(
+	down []redpandav1alpha1.DownBroker, gracePeriod time.Duration, now time.Time,
+) []redpandav1alpha1.DownBroker {
+	var failed []redpandav1alpha1.DownBroker
+	for _, b := range down {
+		if now.Sub(b.Since.Time) >= gracePeriod {
+			failed = append(failed, b)
+		}
+	}
+	return failed
+}
+
+// reportBrokerFailure records the brokers that the cluster reports as down
+// and sets the BrokersFailed condition once one of them stays down longer
+// than the grace period. When the policy enables it, a single failed broker
+// is decommissioned, never while another broker drains or several failed.
+// It returns true while brokers are down, so they are checked again soon.
+// Upgrades restart the brokers, the report is left as it is meanwhile.
+func (r *ClusterReconciler) reportBrokerFailure(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+) (bool, error) {
+	if redpandaCluster.Spec.Replicas == nil || redpandaCluster.Status.Upgrading {
+		return false, nil
+	}
+
+	adminAPI, err := r.AdminAPIClientFactory(ctx, r, redpandaCluster, fqdn, adminTLSProvider)
+	if err != nil {
+		return false, fmt.Errorf("unable to create admin API client: %w", err)
+	}
+	brokers, err := adminAPI.Brokers(ctx)
+	if err != nil {
+		r.Log.Info("Unable to read the liveness of the brokers", "error", err)
+		return false, notServed("broker failure", err)
+	}
+
+	now := time.Now()
+	down := downBrokers(redpandaCluster.Status.DownBrokers, brokers, int(*redpandaCluster.Spec.Replicas), now)
+	gracePeriod := redpandaCluster.BrokerFailureGracePeriod()
+	failed := failedBrokers(down, gracePeriod, now)
+
+	status, reason, message := corev1.ConditionFalse, reasonBrokersAlive, "no broker stayed down longer than "+gracePeriod.String()
+	if len(failed) > 0 {
+		ids := make([]string, 0, len(failed))
+		for _, b := range failed {
+			ids = append(ids, fmt.Sprintf("%d (down since %s)", b.NodeID, b.Since.UTC().Format(time.RFC3339)))
+		}
+		status, reason = corev1.ConditionTrue, reasonBrokersDown
+		message = "brokers down longer than " + gracePeriod.String() + ": " + strings.Join(ids, ", ")
+		r.Log.Info("Brokers failed", "brokers", ids)
+	}
+
+	if redpandaCluster.FailedBrokerDecommission() && len(failed) == 1 && !anyDraining(brokers) {
+		nodeID := failed[0].NodeID
+		r.Log.Info("Decommissioning failed broker", "node id", nodeID)
+		if err := adminAPI.DecommissionBroker(ctx, nodeID); err != nil {
+			return true, fmt.Errorf("unable to decommission failed broker %d: %w", nodeID, err)
+		}
+		if r.Recorder != nil {
+			r.Recorder.Eventf(redpandaCluster, corev1.EventTypeWarning, eventFailedBrokerDecommission,
+				"decommissioning broker %d, it is down since %s", nodeID, failed[0].Since.UTC().Format(time.RFC3339))
+		}
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		changed := false
+		if len(failed) > 0 || cluster.Status.GetCondition(redpandav1alpha1.ClusterBrokersFailed) != nil {
+			changed = cluster.Status.SetCondition(redpandav1alpha1.ClusterBrokersFailed, status, reason, message)
+		}
+		if !reflect.DeepEqual(down, cluster.Status.DownBrokers) {
+			cluster.Status.DownBrokers = down
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return len(down) > 0, fmt.Errorf("failed to update the broker failure condition: %w", err)
+	}
+	redpandaCluster.Status.DownBrokers = down
+	return len(down) > 0, nil
+}
+
+// anyDraining returns true when a broker is being decommissioned
+func anyDraining(brokers []admin.Broker) bool {
+	for _, b := range brokers {
+		if b.MembershipStatus == membershipDraining {
+			return true
+		}
+	}
+	return false
+}
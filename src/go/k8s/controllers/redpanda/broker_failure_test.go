@@ -0,0 +1,67 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestDownBrokers(t *testing.T) {
+	now := time.Now()
+	earlier := metav1.NewTime(now.Add(-time.Hour))
+	down := downBrokers([]redpandav1alpha1.DownBroker{
+		{NodeID: 2, Since: earlier},
+		{NodeID: 0, Since: earlier},
+	}, []admin.Broker{
+		{NodeID: 2, MembershipStatus: "active", IsAlive: pointer.BoolPtr(false)},
+		{NodeID: 0, MembershipStatus: "active", IsAlive: pointer.BoolPtr(true)},
+		{NodeID: 1, MembershipStatus: "active", IsAlive: pointer.BoolPtr(false)},
+		{NodeID: 3, MembershipStatus: "active", IsAlive: pointer.BoolPtr(false)},
+		{NodeID: 4, MembershipStatus: "draining", IsAlive: pointer.BoolPtr(false)},
+	}, 3, now)
+	require.Len(t, down, 2)
+	assert.Equal(t, 1, down[0].NodeID)
+	assert.Equal(t, now.Unix(), down[0].Since.Unix())
+	assert.Equal(t, 2, down[1].NodeID)
+	assert.Equal(t, earlier, down[1].Since)
+
+	failed := failedBrokers(down, 10*time.Minute, now)
+	require.Len(t, failed, 1)
+	assert.Equal(t, 2, failed[0].NodeID)
+}
+
+func TestReportBrokerFailure(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replicas:      pointer.Int32Ptr(3),
+			BrokerFailure: &redpandav1alpha1.BrokerFailurePolicy{Decommission: true},
+		},
+	}
+	f := newFixture(t, cluster)
+
+	// without the broker list no broker is seen down or decommissioned
+	down, err := f.reportBrokerFailure(context.Background(), cluster, "cluster.local", nil)
+	assert.True(t, admin.IsUnsupported(err))
+	assert.False(t, down)
+	actual := f.get(t, "cluster")
+	assert.Empty(t, actual.Status.DownBrokers)
+	assert.Nil(t, actual.Status.GetCondition(redpandav1alpha1.ClusterBrokersFailed))
+	assert.Empty(t, f.adminAPI.Decommissioned)
+}
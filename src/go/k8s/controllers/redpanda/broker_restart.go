@@ -0,0 +1,298 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	eventRestartBroker         = "RestartingBroker"
+	eventInvalidRestartRequest = "InvalidRestartRequest"
+
+	// brokerRestartRequeue is how often a requested restart is checked,
+	// neither the leadership drain nor the maintenance mode change a watched
+	// object
+	brokerRestartRequeue = 10 * time.Second
+)
+
+// queueRestarts appends the requested ordinals that are neither pending nor
+// restarting to the pending restarts
+func queueRestarts(
+	pending []int32, current *redpandav1alpha1.BrokerRestart, requested []int32,
+) []int32 {
+	queued := make(map[int32]bool, len(pending)+1)
+	for _, o := range pending {
+		queued[o] = true
+	}
+	if current != nil {
+		queued[current.Ordinal] = true
+	}
+	for _, o := range requested {
+		if !queued[o] {
+			pending = append(pending, o)
+			queued[o] = true
+		}
+	}
+	return pending
+}
+
+// reconcileBrokerRestart restarts the brokers requested with the
+// RestartAnnotation of the Cluster or of their Pods. The requests are queued
+// in the status and the annotations removed. One broker at a time, and only
+// while every broker is ready and the cluster is healthy, is put into
+// maintenance mode, its Pod is deleted once its leadership drained and the
+// maintenance mode is disabled when the recreated Pod is ready. It returns
+// true while restarts are pending. Upgrades restart the brokers themselves,
+// the requests wait meanwhile.
+func (r *ClusterReconciler) reconcileBrokerRestart(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	sts *appsv1.StatefulSet,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+) (bool, error) {
+	var podList corev1.PodList
+	err := r.List(ctx, &podList, &client.ListOptions{
+		LabelSelector: labels.ForCluster(redpandaCluster).AsClientSelector(),
+		Namespace:     redpandaCluster.Namespace,
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to list the broker Pods: %w", err)
+	}
+	pods := make(map[int32]*corev1.Pod, len(podList.Items))
+	var annotated []*corev1.Pod
+	var requested []int32
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		ordinal, ok := resources.PodOrdinal(redpandaCluster, pod.Name)
+		if !ok {
+			continue
+		}
+		pods[ordinal] = pod
+		if _, ok := pod.Annotations[redpandav1alpha1.RestartAnnotation]; ok {
+			annotated = append(annotated, pod)
+			requested = append(requested, ordinal)
+		}
+	}
+	sort.Slice(requested, func(i, j int) bool { return requested[i] < requested[j] })
+
+	_, clusterRequest := redpandaCluster.Annotations[redpandav1alpha1.RestartAnnotation]
+	ordinals, err := redpandaCluster.RequestedRestarts()
+	if err != nil {
+		r.Log.Info("Ignoring the invalid restart request", "error", err.Error())
+		if r.Recorder != nil {
+			r.Recorder.Eventf(redpandaCluster, corev1.EventTypeWarning, eventInvalidRestartRequest,
+				"ignoring the restart request: %s", err)
+		}
+	}
+	requested = append(ordinals, requested...)
+
+	pending := queueRestarts(append([]int32{}, redpandaCluster.Status.PendingRestarts...),
+		redpandaCluster.Status.BrokerRestart, requested)
+	current := redpandaCluster.Status.BrokerRestart.DeepCopy()
+	// the Pods of removed brokers do not come back
+	var replicas int32
+	if redpandaCluster.Spec.Replicas != nil {
+		replicas = *redpandaCluster.Spec.Replicas
+	}
+	kept := pending[:0]
+	for _, o := range pending {
+		if o < replicas {
+			kept = append(kept, o)
+		}
+	}
+	pending = kept
+
+	var fallback error
+	if len(pending) > 0 || current != nil {
+		if !redpandaCluster.Status.Upgrading {
+			current, pending, err = r.stepBrokerRestart(ctx, redpandaCluster, sts, pods, current, pending, fqdn, adminTLSProvider)
+		}
+		if fallback = notServed("broker restart", err); fallback != nil {
+			err = nil
+		}
+		if err != nil {
+			return true, err
+		}
+	}
+
+	if len(pending) == 0 {
+		pending = nil
+	}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if reflect.DeepEqual(pending, cluster.Status.PendingRestarts) &&
+			reflect.DeepEqual(current, cluster.Status.BrokerRestart) {
+			return nil
+		}
+		cluster.Status.PendingRestarts = pending
+		cluster.Status.BrokerRestart = current
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return true, fmt.Errorf("failed to update the broker restarts: %w", err)
+	}
+	redpandaCluster.Status.PendingRestarts = pending
+	redpandaCluster.Status.BrokerRestart = current
+
+	// the requests are removed only once they are queued in the status
+	if clusterRequest {
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			var cluster redpandav1alpha1.Cluster
+			err := r.Get(ctx, types.NamespacedName{
+				Name:      redpandaCluster.Name,
+				Namespace: redpandaCluster.Namespace,
+			}, &cluster)
+			if err != nil {
+				return err
+			}
+			if _, ok := cluster.Annotations[redpandav1alpha1.RestartAnnotation]; !ok {
+				return nil
+			}
+			delete(cluster.Annotations, redpandav1alpha1.RestartAnnotation)
+			return r.Update(ctx, &cluster)
+		})
+		if err != nil {
+			return true, fmt.Errorf("unable to remove the restart annotation of the Cluster: %w", err)
+		}
+		delete(redpandaCluster.Annotations, redpandav1alpha1.RestartAnnotation)
+	}
+	for _, pod := range annotated {
+		delete(pod.Annotations, redpandav1alpha1.RestartAnnotation)
+		if err := r.Update(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			return true, fmt.Errorf("unable to remove the restart annotation of %s: %w", pod.Name, err)
+		}
+	}
+	return len(pending) > 0 || current != nil, fallback
+}
+
+// stepBrokerRestart moves the restart in progress on by one phase, or starts
+// the restart of the next pending broker once the cluster is healthy. The
+// new state comes with the error of an Admin API endpoint the running version
+// does not serve when the phase fell back without it.
+func (r *ClusterReconciler) stepBrokerRestart(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	sts *appsv1.StatefulSet,
+	pods map[int32]*corev1.Pod,
+	current *redpandav1alpha1.BrokerRestart,
+	pending []int32,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+) (*redpandav1alpha1.BrokerRestart, []int32, error) {
+	adminAPI, err := r.AdminAPIClientFactory(ctx, r, redpandaCluster, fqdn, adminTLSProvider)
+	if err != nil {
+		return current, pending, fmt.Errorf("unable to create admin API client: %w", err)
+	}
+
+	switch {
+	case current == nil:
+		if sts == nil || redpandaCluster.Spec.Replicas == nil || sts.Status.ReadyReplicas < *redpandaCluster.Spec.Replicas {
+			r.Log.Info("Requested broker restart waits for every broker to be ready", "pending", pending)
+			return nil, pending, nil
+		}
+		var fallback error
+		health, err := adminAPI.ClusterHealth(ctx)
+		if admin.IsUnsupported(err) {
+			// this Redpanda version does not report the cluster health,
+			// every broker being ready has to do
+			fallback = err
+			health, err = admin.ClusterHealthOverview{IsHealthy: true}, nil
+		}
+		if err != nil || !health.IsHealthy {
+			r.Log.Info("Requested broker restart waits for a healthy cluster", "pending", pending, "error", err)
+			return nil, pending, nil
+		}
+		ordinal := pending[0]
+		r.Log.Info("Putting the broker into maintenance mode for the requested restart", "ordinal", ordinal)
+		err = adminAPI.EnableMaintenanceMode(ctx, int(ordinal))
+		if admin.IsUnsupported(err) {
+			r.Log.Info("The running version does not serve the maintenance mode, restarting the broker without draining its leadership", "ordinal", ordinal)
+			fallback = err
+		} else if err != nil {
+			return nil, pending, fmt.Errorf("unable to enable the maintenance mode of broker %d: %w", ordinal, err)
+		}
+		if r.Recorder != nil {
+			r.Recorder.Eventf(redpandaCluster, corev1.EventTypeNormal, eventRestartBroker,
+				"restarting broker %d as requested", ordinal)
+		}
+		return &redpandav1alpha1.BrokerRestart{
+			Ordinal: ordinal,
+			Phase:   redpandav1alpha1.BrokerRestartMaintenance,
+			Since:   metav1.Now(),
+		}, pending[1:], fallback
+
+	case current.Phase == redpandav1alpha1.BrokerRestartMaintenance:
+		var fallback error
+		maintenance, err := adminAPI.MaintenanceStatus(ctx, int(current.Ordinal))
+		if admin.IsUnsupported(err) {
+			// nothing was drained, Redpanda moves the leadership once the
+			// broker is down
+			fallback = err
+			maintenance, err = admin.MaintenanceStatus{Finished: true}, nil
+		}
+		if err != nil || !maintenance.Finished {
+			r.Log.Info("Requested broker restart waits for the leadership to drain", "ordinal", current.Ordinal, "error", err)
+			return current, pending, nil
+		}
+		pod, ok := pods[current.Ordinal]
+		if !ok {
+			r.Log.Info("Requested broker restart waits for the Pod", "ordinal", current.Ordinal)
+			return current, pending, nil
+		}
+		r.Log.Info("Deleting the Pod of the drained broker for the requested restart", "pod", pod.Name)
+		if err := r.Delete(ctx, pod, client.Preconditions{UID: &pod.UID}); err != nil && !apierrors.IsNotFound(err) {
+			return current, pending, fmt.Errorf("unable to delete the Pod %s: %w", pod.Name, err)
+		}
+		return &redpandav1alpha1.BrokerRestart{
+			Ordinal: current.Ordinal,
+			Phase:   redpandav1alpha1.BrokerRestartRestarting,
+			PodUID:  pod.UID,
+			Since:   metav1.Now(),
+		}, pending, fallback
+
+	default:
+		pod, ok := pods[current.Ordinal]
+		if !ok || pod.UID == current.PodUID || !podConditionTrue(pod, corev1.PodReady) {
+			r.Log.Info("Requested broker restart waits for the recreated Pod to be ready", "ordinal", current.Ordinal)
+			return current, pending, nil
+		}
+		err := adminAPI.DisableMaintenanceMode(ctx, int(current.Ordinal))
+		if err != nil && !admin.IsUnsupported(err) {
+			return current, pending, fmt.Errorf("unable to disable the maintenance mode of broker %d: %w", current.Ordinal, err)
+		}
+		r.Log.Info("Restarted the broker as requested", "pod", pod.Name)
+		return nil, pending, err
+	}
+}
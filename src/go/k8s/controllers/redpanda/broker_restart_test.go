@@ -0,0 +1,99 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestQueueRestarts(t *testing.T) {
+	current := &redpandav1alpha1.BrokerRestart{Ordinal: 1}
+	assert.Equal(t, []int32{2, 0}, queueRestarts([]int32{2}, current, []int32{0, 1, 2}))
+	assert.Equal(t, []int32{0, 1}, queueRestarts(nil, nil, []int32{0, 1, 1}))
+}
+
+func TestReconcileBrokerRestart(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cluster",
+			Namespace:   "default",
+			Annotations: map[string]string{redpandav1alpha1.RestartAnnotation: "1"},
+		},
+		Spec: redpandav1alpha1.ClusterSpec{Replicas: pointer.Int32Ptr(3)},
+	}
+	pod := func(name string, uid types.UID) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    labels.ForCluster(cluster),
+				UID:       uid,
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	f := newFixture(t, cluster, pod("cluster-0", "a"), pod("cluster-1", "b"), pod("cluster-2", "c"))
+	sts := &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: 3}}
+
+	// without the cluster health and the maintenance mode the broker is
+	// restarted once every broker is ready, without a drain
+	restarting, err := f.reconcileBrokerRestart(context.Background(), cluster, sts, "cluster.local", nil)
+	assert.True(t, admin.IsUnsupported(err))
+	assert.True(t, restarting)
+	actual := f.get(t, "cluster")
+	assert.NotContains(t, actual.Annotations, redpandav1alpha1.RestartAnnotation)
+	require.NotNil(t, actual.Status.BrokerRestart)
+	assert.Equal(t, redpandav1alpha1.BrokerRestartMaintenance, actual.Status.BrokerRestart.Phase)
+	assert.Contains(t, <-f.recorder.Events, eventRestartBroker)
+
+	// a request on a Pod is queued behind it
+	var other corev1.Pod
+	require.NoError(t, f.Get(context.Background(), types.NamespacedName{Name: "cluster-2", Namespace: "default"}, &other))
+	other.Annotations = map[string]string{redpandav1alpha1.RestartAnnotation: "true"}
+	require.NoError(t, f.Update(context.Background(), &other))
+
+	// the Pod is deleted right away, nothing was drained
+	_, err = f.reconcileBrokerRestart(context.Background(), cluster, sts, "cluster.local", nil)
+	assert.True(t, admin.IsUnsupported(err))
+	err = f.Get(context.Background(), types.NamespacedName{Name: "cluster-1", Namespace: "default"}, &corev1.Pod{})
+	assert.True(t, apierrors.IsNotFound(err))
+	actual = f.get(t, "cluster")
+	assert.Equal(t, redpandav1alpha1.BrokerRestartRestarting, actual.Status.BrokerRestart.Phase)
+	assert.Equal(t, []int32{2}, actual.Status.PendingRestarts)
+	require.NoError(t, f.Get(context.Background(), types.NamespacedName{Name: "cluster-2", Namespace: "default"}, &other))
+	assert.NotContains(t, other.Annotations, redpandav1alpha1.RestartAnnotation)
+
+	// the restart ends once the recreated Pod is ready
+	_, err = f.reconcileBrokerRestart(context.Background(), cluster, sts, "cluster.local", nil)
+	require.NoError(t, err)
+	assert.NotNil(t, f.get(t, "cluster").Status.BrokerRestart)
+
+	require.NoError(t, f.Create(context.Background(), pod("cluster-1", "d")))
+	_, err = f.reconcileBrokerRestart(context.Background(), cluster, sts, "cluster.local", nil)
+	assert.True(t, admin.IsUnsupported(err))
+	actual = f.get(t, "cluster")
+	assert.Nil(t, actual.Status.BrokerRestart)
+	assert.Equal(t, []int32{2}, actual.Status.PendingRestarts)
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonBrokersScheduled            = "BrokersScheduled"
+	reasonBrokersUnschedulable        = "Unschedulable"
+	reasonFailureDomainsUnsatisfiable = "FailureDomainsUnsatisfiable"
+
+	// brokerSchedulingRequeue is how often unschedulable brokers are
+	// checked, the Pods are not watched
+	brokerSchedulingRequeue = 30 * time.Second
+)
+
+// unschedulableMessage returns the message of the scheduler when it could
+// not place the Pod
+func unschedulableMessage(pod *corev1.Pod) (string, bool) {
+	if pod.Status.Phase != corev1.PodPending || !pod.DeletionTimestamp.IsZero() {
+		return "", false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse &&
+			c.Reason == corev1.PodReasonUnschedulable {
+			return c.Message, true
+		}
+	}
+	return "", false
+}
+
+// reportBrokerScheduling sets the BrokersUnschedulable condition with the
+// message of the scheduler for the brokers it can not place, so a strict
+// failure domain spread that can not be satisfied does not leave brokers
+// silently Pending. It returns true while brokers are unschedulable, so
+// they are checked again soon.
+func (r *ClusterReconciler) reportBrokerScheduling(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) (bool, error) {
+	var podList corev1.PodList
+	err := r.List(ctx, &podList, &client.ListOptions{
+		LabelSelector: labels.ForCluster(redpandaCluster).AsClientSelector(),
+		Namespace:     redpandaCluster.Namespace,
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to list the broker Pods: %w", err)
+	}
+
+	var pending []string
+	for i := range podList.Items {
+		if message, ok := unschedulableMessage(&podList.Items[i]); ok {
+			pending = append(pending, fmt.Sprintf("%s: %s", podList.Items[i].Name, message))
+		}
+	}
+	sort.Strings(pending)
+
+	status, reason, message := corev1.ConditionFalse, reasonBrokersScheduled, "the scheduler placed every broker"
+	if len(pending) > 0 {
+		status, reason = corev1.ConditionTrue, reasonBrokersUnschedulable
+		message = "unschedulable brokers " + strings.Join(pending, "; ")
+		if redpandaCluster.StrictFailureDomainSpread() {
+			reason = reasonFailureDomainsUnsatisfiable
+			message = "the strict failure domain spread keeps brokers on nodes of their own and the zones " +
+				"balanced by one broker, relax it or add nodes to the zones lacking brokers; " + message
+		}
+		r.Log.Info("Brokers can not be scheduled", "pods", pending)
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 && cluster.Status.GetCondition(redpandav1alpha1.ClusterBrokersUnschedulable) == nil {
+			return nil
+		}
+		if !cluster.Status.SetCondition(redpandav1alpha1.ClusterBrokersUnschedulable, status, reason, message) {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return len(pending) > 0, fmt.Errorf("failed to update the broker scheduling condition: %w", err)
+	}
+	return len(pending) > 0, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestReportBrokerScheduling(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replicas:            pointer.Int32Ptr(3),
+			FailureDomainSpread: redpandav1alpha1.FailureDomainSpreadStrict,
+		},
+	}
+	pending := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-2", Namespace: "default", Labels: labels.ForCluster(cluster)},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  corev1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available: 3 node(s) didn't match pod topology spread constraints.",
+			}},
+		},
+	}
+	running := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-0", Namespace: "default", Labels: labels.ForCluster(cluster)},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	f := newFixture(t, cluster, pending, running)
+	key := types.NamespacedName{Name: "cluster", Namespace: "default"}
+
+	unschedulable, err := f.reportBrokerScheduling(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.True(t, unschedulable)
+
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	condition := actual.Status.GetCondition(redpandav1alpha1.ClusterBrokersUnschedulable)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, reasonFailureDomainsUnsatisfiable, condition.Reason)
+	assert.True(t, strings.Contains(condition.Message, "cluster-2: 0/3 nodes are available"))
+
+	pending.Status = corev1.PodStatus{Phase: corev1.PodRunning}
+	require.NoError(t, f.Update(context.Background(), pending))
+	unschedulable, err = f.reportBrokerScheduling(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.False(t, unschedulable)
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	assert.Equal(t, corev1.ConditionFalse, actual.Status.GetCondition(redpandav1alpha1.ClusterBrokersUnschedulable).Status)
+}
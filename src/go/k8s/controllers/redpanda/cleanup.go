@@ -0,0 +1,134 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+
+	cmapiv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// generatedObjectLists are the kinds of objects the operator generates for a
+// Cluster. Without owner references the garbage collector of Kubernetes does
+// not know about them, so they are deleted by the cleanup finalizer.
+func generatedObjectLists() []client.ObjectList {
+	return []client.ObjectList{
+		&appsv1.StatefulSetList{},
+		&appsv1.DeploymentList{},
+		&batchv1.JobList{},
+		&corev1.ServiceList{},
+		&corev1.ConfigMapList{},
+		&corev1.SecretList{},
+		&corev1.ServiceAccountList{},
+		&networkingv1.IngressList{},
+		&networkingv1.NetworkPolicyList{},
+		&policyv1beta1.PodDisruptionBudgetList{},
+		&cmapiv1.CertificateList{},
+		&cmapiv1.IssuerList{},
+		verticalPodAutoscalerList(),
+	}
+}
+
+func verticalPodAutoscalerList() *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(resources.VerticalPodAutoscalerGVK.GroupVersion().WithKind(
+		resources.VerticalPodAutoscalerGVK.Kind + "List"))
+	return list
+}
+
+// reconcileCleanupFinalizer keeps the cleanup finalizer on clusters that skip
+// owner references. When such a cluster is deleted, the generated objects are
+// removed and deleted is true.
+func (r *ClusterReconciler) reconcileCleanupFinalizer(
+	ctx context.Context,
+	cluster *redpandav1alpha1.Cluster,
+	crb *resources.ClusterRoleBindingResource,
+) (deleted bool, err error) {
+	hasFinalizer := controllerutil.ContainsFinalizer(cluster, redpandav1alpha1.CleanupFinalizer)
+
+	if !cluster.DeletionTimestamp.IsZero() {
+		if !hasFinalizer {
+			return true, nil
+		}
+		if err := r.deleteGeneratedObjects(ctx, cluster); err != nil {
+			return false, err
+		}
+		if err := crb.RemoveSubject(ctx, client.ObjectKeyFromObject(cluster)); err != nil {
+			return false, fmt.Errorf("unable to remove subject in ClusterroleBinding: %w", err)
+		}
+		controllerutil.RemoveFinalizer(cluster, redpandav1alpha1.CleanupFinalizer)
+		if err := r.Update(ctx, cluster); err != nil {
+			return false, fmt.Errorf("unable to remove the cleanup finalizer: %w", err)
+		}
+		return true, nil
+	}
+
+	switch {
+	case cluster.OwnerReferencesDisabled() && !hasFinalizer:
+		controllerutil.AddFinalizer(cluster, redpandav1alpha1.CleanupFinalizer)
+	case !cluster.OwnerReferencesDisabled() && hasFinalizer:
+		controllerutil.RemoveFinalizer(cluster, redpandav1alpha1.CleanupFinalizer)
+	default:
+		return false, nil
+	}
+	if err := r.Update(ctx, cluster); err != nil {
+		return false, fmt.Errorf("unable to update the cleanup finalizer: %w", err)
+	}
+	return false, nil
+}
+
+func (r *ClusterReconciler) deleteGeneratedObjects(
+	ctx context.Context, cluster *redpandav1alpha1.Cluster,
+) error {
+	opts := &client.ListOptions{
+		LabelSelector: labels.ForCluster(cluster).AsOwnerSelector(),
+		Namespace:     cluster.Namespace,
+	}
+	for _, list := range generatedObjectLists() {
+		err := r.List(ctx, list, opts)
+		// The optional CRDs may not be installed
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("unable to list %T: %w", list, err)
+		}
+		err = meta.EachListItem(list, func(obj runtime.Object) error {
+			o, ok := obj.(client.Object)
+			if !ok {
+				return nil
+			}
+			if err := r.Delete(ctx, o, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("unable to delete %T %s: %w", o, o.GetName(), err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+
+	cmapiv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestCleanupFinalizer(t *testing.T) {
+	require.NoError(t, cmapiv1.AddToScheme(scheme.Scheme))
+	// The VerticalPodAutoscaler CRD has no vendored types
+	scheme.Scheme.AddKnownTypeWithName(resources.VerticalPodAutoscalerGVK, &unstructured.Unstructured{})
+	scheme.Scheme.AddKnownTypeWithName(resources.VerticalPodAutoscalerGVK.GroupVersion().WithKind("VerticalPodAutoscalerList"), &unstructured.UnstructuredList{})
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "gitops",
+			Namespace:   "default",
+			Annotations: map[string]string{redpandav1alpha1.SkipOwnerReferencesAnnotation: "true"},
+		},
+	}
+	generated := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      "gitops-base",
+		Namespace: "default",
+		Labels:    labels.ForCluster(cluster),
+	}}
+	unrelated := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      "unrelated",
+		Namespace: "default",
+	}}
+
+	f := newFixture(t, cluster, generated, unrelated)
+	crb := resources.NewClusterRoleBinding(f.Client, cluster, scheme.Scheme, ctrl.Log)
+	ctx := context.Background()
+
+	deleted, err := f.reconcileCleanupFinalizer(ctx, cluster, crb)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	assert.True(t, controllerutil.ContainsFinalizer(cluster, redpandav1alpha1.CleanupFinalizer))
+
+	now := metav1.Now()
+	cluster.DeletionTimestamp = &now
+	deleted, err = f.reconcileCleanupFinalizer(ctx, cluster, crb)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.False(t, controllerutil.ContainsFinalizer(cluster, redpandav1alpha1.CleanupFinalizer))
+
+	var cm corev1.ConfigMap
+	err = f.Get(ctx, types.NamespacedName{Name: "gitops-base", Namespace: "default"}, &cm)
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.NoError(t, f.Get(ctx, types.NamespacedName{Name: "unrelated", Namespace: "default"}, &cm))
+}
@@ -15,20 +15,30 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
 	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
 	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
 	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 var (
@@ -36,25 +46,67 @@ var (
 	errNodePortMissing              = errors.New("the node port is missing from the service")
 )
 
+// eventNodeAddressMissing is emitted for brokers on nodes without an external
+// address, they are left out of the external addresses in the status
+const eventNodeAddressMissing = "NodeAddressMissing"
+
 // ClusterReconciler reconciles a Cluster object
 type ClusterReconciler struct {
 	client.Client
 	Log             logr.Logger
 	configuratorTag string
 	Scheme          *runtime.Scheme
+	// AdminAPIClientFactory creates clients of the Redpanda Admin API. It is
+	// replaced by a fake in tests.
+	AdminAPIClientFactory admin.AdminAPIClientFactory
+	// Recorder emits Kubernetes events about the Cluster
+	Recorder record.EventRecorder
+	// APIReader reads objects that are not cached, e.g. the events of
+	// unbound PersistentVolumeClaims. Events are not read when it is nil.
+	APIReader client.Reader
+
+	checkAdvertisedAddresses bool
+	decommissionGhostBrokers bool
+	operatorNamespace        string
+
+	resyncPeriod    time.Duration
+	requeuePeriod   time.Duration
+	startupJitter   time.Duration
+	startTime       time.Time
+	startedClusters sync.Map
+	// unsupportedSteps holds the steps of each Cluster reported in the
+	// AdminAPIUnsupported condition
+	unsupportedSteps sync.Map
 }
 
 //+kubebuilder:rbac:groups=redpanda.vectorized.io,resources=clusters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=redpanda.vectorized.io,resources=clusters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=redpanda.vectorized.io,resources=clusters/finalizers,verbs=update
-//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;
-//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;
-//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;
-//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;
-//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;
-//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;
+//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete;
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;update;patch;delete;
+//+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;patch;delete;
+//+kubebuilder:rbac:groups=core,resources=pods/status,verbs=update;patch
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=autoscaling.k8s.io,resources=verticalpodautoscalers,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=podmonitors,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=get;list;watch;create;update;patch;
 //+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=resourcequotas;limitranges,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=get;list;create;patch
 //+kubebuilder:rbac:groups=cert-manager.io,resources=issuers;certificates;clusterissuers,verbs=create;get;list;watch;patch;delete;
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -88,12 +140,38 @@ func (r *ClusterReconciler) Reconcile(
 		return ctrl.Result{}, fmt.Errorf("unable to retrieve Cluster resource: %w", err)
 	}
 
+	if deleted, err := r.reconcileCleanupFinalizer(ctx, &redpandaCluster, crb); err != nil || deleted {
+		return ctrl.Result{}, err
+	}
+
+	if bootstrapComplete(&redpandaCluster) {
+		log.Info("Cluster is bootstrapped, reconciling resumes when its spec changes")
+		return ctrl.Result{}, nil
+	}
+
+	if delay := r.startupDelay(&redpandaCluster); delay > 0 {
+		log.Info("Postponing the first reconcile after the operator start", "delay", delay)
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+	log.Info("Feature gates", "enabled", redpandaCluster.EnabledFeatureGates())
+
+	// the Admin API, which also serves the metrics, is part of the headless
+	// Service so that each broker can be scraped and addressed by its Pod DNS
+	// name
 	ports := []resources.NamedServicePort{
 		{Name: resources.AdminPortName, Port: redpandaCluster.Spec.Configuration.AdminAPI.Port},
 		{Name: resources.KafkaPortName, Port: redpandaCluster.Spec.Configuration.KafkaAPI.Port},
 	}
+	nodePorts := append([]resources.NamedServicePort{}, ports...)
+	if proxy := redpandaCluster.Spec.Configuration.PandaproxyAPI; proxy != nil {
+		proxyPort := resources.NamedServicePort{Name: resources.PandaproxyPortName, Port: proxy.Port}
+		ports = append(ports, proxyPort)
+		if redpandaCluster.PandaproxyExternal() {
+			nodePorts = append(nodePorts, proxyPort)
+		}
+	}
 	headlessSvc := resources.NewHeadlessService(r.Client, &redpandaCluster, r.Scheme, ports, log)
-	nodeportSvc := resources.NewNodePortService(r.Client, &redpandaCluster, r.Scheme, ports, log)
+	nodeportSvc := resources.NewNodePortService(r.Client, &redpandaCluster, r.Scheme, nodePorts, log)
 
 	pki := certmanager.NewPki(r.Client, &redpandaCluster, headlessSvc.HeadlessServiceFQDN(), r.Scheme, log)
 	sa := resources.NewServiceAccount(r.Client, &redpandaCluster, r.Scheme, log)
@@ -108,27 +186,85 @@ func (r *ClusterReconciler) Reconcile(
 		pki.OperatorClientCert(),
 		pki.AdminCert(),
 		pki.AdminAPINodeCert(),
+		pki.RPCNodeCert(),
+		pki.PandaproxyAPINodeCert(),
+		pki.KafkaExternalNodeCert(),
 		sa.Key().Name,
 		r.configuratorTag,
+		r.AdminAPIClientFactory,
+		pki.AdminAPIConfigProvider(),
 		log)
+	// before the StatefulSet creates Pods the quotas would reject
+	if err := r.reportQuota(ctx, &redpandaCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+	replicationSource, err := r.replicationSource(ctx, &redpandaCluster)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	replication := resources.NewReplication(r.Client, &redpandaCluster, r.Scheme,
+		resources.KafkaEndpoint{
+			Cluster:    &redpandaCluster,
+			FQDN:       headlessSvc.HeadlessServiceFQDN(),
+			NodeCert:   pki.NodeCert(),
+			ClientCert: pki.AdminCert(),
+		}, replicationSource, log)
+
 	toApply := []resources.Reconciler{
 		headlessSvc,
 		nodeportSvc,
+		resources.NewClusterService(r.Client, &redpandaCluster, r.Scheme, log),
 		resources.NewConfigMap(r.Client, &redpandaCluster, r.Scheme, headlessSvc.HeadlessServiceFQDN(), log),
 		pki,
 		sa,
 		resources.NewClusterRole(r.Client, &redpandaCluster, r.Scheme, log),
 		crb,
 		sts,
+		resources.NewPodDisruptionBudget(r.Client, &redpandaCluster, r.Scheme, log),
+		resources.NewVerticalPodAutoscaler(r.Client, &redpandaCluster, r.Scheme, log),
+		resources.NewPodMonitor(r.Client, &redpandaCluster, r.Scheme, headlessSvc.HeadlessServiceFQDN(), pki.AdminAPINodeCert(), pki.AdminAPIClientCert(), log),
+		resources.NewNodeTuning(r.Client, &redpandaCluster, r.Scheme, log),
+		resources.NewClusterConfiguration(r.Client, &redpandaCluster, r.AdminAPIClientFactory, headlessSvc.HeadlessServiceFQDN(), pki.AdminAPIConfigProvider(), log),
+		resources.NewInternalTopicReplication(r.Client, &redpandaCluster, r.AdminAPIClientFactory, headlessSvc.HeadlessServiceFQDN(), pki.AdminAPIConfigProvider(), log),
+		resources.NewSuperuser(r.Client, &redpandaCluster, r.Scheme, r.AdminAPIClientFactory, headlessSvc.HeadlessServiceFQDN(), pki.AdminAPIConfigProvider(), log),
+		resources.NewPostBootstrapJob(r.Client, &redpandaCluster, r.Scheme, headlessSvc.HeadlessServiceFQDN(), log),
+		resources.NewClientConfig(r.Client, &redpandaCluster, r.Scheme, headlessSvc.HeadlessServiceFQDN(), pki.NodeCert(), log),
+		resources.NewServiceCatalog(r.Client, &redpandaCluster, r.Scheme, headlessSvc.HeadlessServiceFQDN(), pki.NodeCert(), pki.KafkaExternalNodeCert(), pki.AdminAPINodeCert(), log),
+		resources.NewCABundle(r.Client, &redpandaCluster, r.Scheme, pki.NodeCert(), pki.AdminAPINodeCert(), log),
+		resources.NewDiscoveryService(r.Client, &redpandaCluster, r.Scheme, headlessSvc.HeadlessServiceFQDN(), ports, log),
+		resources.NewNetworkPolicy(r.Client, &redpandaCluster, r.Scheme, r.operatorNamespace, log),
+		resources.NewManagementService(r.Client, &redpandaCluster, r.Scheme, log),
+		resources.NewConsole(r.Client, &redpandaCluster, r.Scheme, headlessSvc.HeadlessServiceFQDN(), pki.NodeCert(), pki.AdminCert(), pki.AdminAPINodeCert(), pki.AdminAPIClientCert(), log),
+		replication,
 	}
 
+	var skipped error
+	var unsupported []error
 	for _, res := range toApply {
 		err := res.Ensure(ctx)
+		if f, ok := res.(resources.AdminAPIFallback); ok {
+			unsupported = append(unsupported, f.UnsupportedAdminAPI()...)
+		}
+
+		if _, ok := res.(resources.AdminAPIReconciler); ok && admin.IsUnsupported(err) {
+			unsupported = append(unsupported, err)
+			continue
+		}
+		if _, ok := res.(resources.AdminAPIReconciler); ok && err != nil {
+			log.Info("Skipping an Admin API step", "error", err.Error())
+			if skipped == nil {
+				skipped = err
+			}
+			continue
+		}
 
 		var e *resources.RequeueAfterError
 		if errors.As(err, &e) {
 			log.Info(e.Error())
-			return ctrl.Result{RequeueAfter: e.RequeueAfter}, nil
+			if err := r.reportCurrentOperation(ctx, &redpandaCluster, e.Msg); err != nil {
+				log.Error(err, "Unable to report the current operation")
+			}
+			return ctrl.Result{RequeueAfter: r.nextRequeue(&redpandaCluster, e.RequeueAfter)}, nil
 		}
 
 		if err != nil {
@@ -137,12 +273,147 @@ func (r *ClusterReconciler) Reconcile(
 		}
 	}
 
-	err := r.reportStatus(ctx, &redpandaCluster, sts.LastObservedState, headlessSvc.HeadlessServiceFQDN(), nodeportSvc.Key())
-	if err != nil {
-		log.Error(err, "Unable to report status")
+	fqdn := headlessSvc.HeadlessServiceFQDN()
+	adminTLS := pki.AdminAPIConfigProvider()
+	// the gates that hold broker readiness run before the status reporters
+	steps := []reconcileStep{
+		func(ctx context.Context) (time.Duration, error) {
+			held, err := r.reconcileZoneStartup(ctx, &redpandaCluster)
+			return requeueWhen(held, zoneStartupRequeue), err
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			joining, err := r.reconcileMembershipReadiness(ctx, &redpandaCluster, fqdn, adminTLS)
+			return requeueWhen(joining, membershipReadinessRequeue), err
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return r.reconcileReadinessStabilization(ctx, &redpandaCluster)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return 0, r.reportAdminAPIAvailable(ctx, &redpandaCluster, skipped)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			unbound, err := r.reportStorageProvisioning(ctx, &redpandaCluster)
+			return requeueWhen(unbound, storageProvisioningRequeue), err
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return 0, r.reportStorageHints(ctx, &redpandaCluster)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return 0, r.reportCapacity(ctx, &redpandaCluster)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			unschedulable, err := r.reportBrokerScheduling(ctx, &redpandaCluster)
+			return requeueWhen(unschedulable, brokerSchedulingRequeue), err
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return 0, r.reportStatus(ctx, &redpandaCluster, sts.LastObservedState, fqdn, nodeportSvc.Key())
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			warming, err := r.reconcileTopicWarmup(ctx, &redpandaCluster, sts.LastObservedState, fqdn, adminTLS)
+			return requeueWhen(warming, topicWarmupRequeue), err
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return 0, r.reportClusterReady(ctx, &redpandaCluster, sts.LastObservedState, fqdn, adminTLS)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return 0, r.reportNodeMembership(ctx, &redpandaCluster, sts.LastObservedState, fqdn, adminTLS)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			down, err := r.reportBrokerFailure(ctx, &redpandaCluster, fqdn, adminTLS)
+			return requeueWhen(down, brokerFailureRequeue), err
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			draining, err := r.reconcileDecommissionDrain(ctx, &redpandaCluster, fqdn, adminTLS)
+			return requeueWhen(draining, decommissionDrainRequeue), err
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return r.reconcileCrashLoopRecovery(ctx, &redpandaCluster)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			restarting, err := r.reconcileBrokerRestart(ctx, &redpandaCluster, sts.LastObservedState, fqdn, adminTLS)
+			return requeueWhen(restarting, brokerRestartRequeue), err
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			racksDrifted, err := r.reconcileRackDrift(ctx, &redpandaCluster)
+			return requeueWhen(racksDrifted, rackDriftRequeue), err
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return 0, r.reportClusterMetadata(ctx, &redpandaCluster, fqdn, adminTLS)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return 0, r.recordHighestVersion(ctx, &redpandaCluster)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return r.reconcileBackup(ctx, &redpandaCluster, fqdn, adminTLS)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return r.reconcileResourceRecommendation(ctx, &redpandaCluster, fqdn, adminTLS)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return 0, r.reportCurrentOperation(ctx, &redpandaCluster, skippedPhase(skipped))
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return 0, r.reportBootstrapComplete(ctx, &redpandaCluster, sts.LastObservedState, fqdn, adminTLS)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return r.reconcileDebugBundle(ctx, &redpandaCluster, fqdn)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return r.reportReplication(ctx, &redpandaCluster, replication)
+		},
+		func(ctx context.Context) (time.Duration, error) {
+			return 0, r.reportAdminAPIUnsupported(ctx, &redpandaCluster, unsupported)
+		},
+	}
+
+	result := ctrl.Result{RequeueAfter: r.nextResync(&redpandaCluster)}
+	for _, step := range steps {
+		requeue, err := step(ctx)
+		if admin.IsUnsupported(err) {
+			unsupported = append(unsupported, err)
+			err = nil
+		}
+		if err != nil {
+			log.Error(err, "Unable to report status")
+			return result, err
+		}
+		result = minRequeue(result, requeue)
+	}
+	if next, ok := redpandaCluster.NextSuperuserPasswordRotation(); ok {
+		rotationWait := time.Until(next)
+		if rotationWait < time.Second {
+			rotationWait = time.Second
+		}
+		result = minRequeue(result, rotationWait)
+	}
+	if skipped != nil {
+		result = minRequeue(result, r.nextRequeue(&redpandaCluster, adminAPIRequeue))
 	}
+	return result, nil
+}
 
-	return ctrl.Result{}, err
+// reconcileStep is a step of Reconcile that runs after the resources are
+// applied. It returns the delay before the cluster has to be checked again,
+// zero when the step does not wait for anything. A step that falls back
+// because the running version does not serve an Admin API endpoint returns
+// its delay together with the error of notServed.
+type reconcileStep func(ctx context.Context) (time.Duration, error)
+
+// requeueWhen returns the delay when the step waits for something
+func requeueWhen(waiting bool, delay time.Duration) time.Duration {
+	if waiting {
+		return delay
+	}
+	return 0
+}
+
+// minRequeue shortens the delay of the result to d, a zero d leaves the
+// result unchanged
+func minRequeue(result ctrl.Result, d time.Duration) ctrl.Result {
+	if d > 0 && (result.RequeueAfter == 0 || result.RequeueAfter > d) {
+		result.RequeueAfter = d
+	}
+	return result
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -150,7 +421,20 @@ func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&redpandav1alpha1.Cluster{}).
 		Owns(&appsv1.StatefulSet{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.DaemonSet{}).
+		Owns(&batchv1.Job{}).
 		Owns(&corev1.Service{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Owns(&policyv1beta1.PodDisruptionBudget{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.Secret{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.clustersReferencingSecret)).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(r.clustersReferencingConfigMap)).
+		Watches(&source.Kind{Type: &redpandav1alpha1.Cluster{}},
+			handler.EnqueueRequestsFromMapFunc(r.clustersReplicatingFrom)).
 		Complete(r)
 }
 
@@ -186,7 +470,11 @@ func (r *ClusterReconciler) reportStatus(
 		return errNonexistentLastObservesState
 	}
 
-	if statusShouldBeUpdated(&redpandaCluster.Status, observedNodesInternal, observedNodesExternal, lastObservedSts.Status.ReadyReplicas) {
+	bootstrap := bootstrapListeners(redpandaCluster, internalFQDN, observedNodesExternal)
+	selector := labels.ForCluster(redpandaCluster).AsClientSelector().String()
+
+	if statusShouldBeUpdated(&redpandaCluster.Status, observedNodesInternal, observedNodesExternal, bootstrap, lastObservedSts.Status.ReadyReplicas) ||
+		redpandaCluster.Status.Selector != selector {
 		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 			var cluster redpandav1alpha1.Cluster
 			err := r.Get(ctx, types.NamespacedName{
@@ -201,6 +489,8 @@ func (r *ClusterReconciler) reportStatus(
 			cluster.Status.Nodes.External = observedNodesExternal
 			cluster.Status.Nodes.ExternalAdmin = observedExternalAdmin
 			cluster.Status.Replicas = lastObservedSts.Status.ReadyReplicas
+			cluster.Status.Bootstrap = bootstrap
+			cluster.Status.Selector = selector
 
 			return r.Status().Update(ctx, &cluster)
 		})
@@ -209,19 +499,51 @@ func (r *ClusterReconciler) reportStatus(
 			return fmt.Errorf("failed to update cluster status: %w", err)
 		}
 	}
-	return nil
+	return r.reportAdvertisedAddresses(ctx, redpandaCluster, observedNodesExternal)
 }
 
 func statusShouldBeUpdated(
 	status *redpandav1alpha1.ClusterStatus,
 	nodesInternal, nodesExternal []string,
+	bootstrap []redpandav1alpha1.BootstrapListener,
 	readyReplicas int32,
 ) bool {
 	return !reflect.DeepEqual(nodesInternal, status.Nodes.Internal) ||
 		!reflect.DeepEqual(nodesExternal, status.Nodes.External) ||
+		!reflect.DeepEqual(bootstrap, status.Bootstrap) ||
 		status.Replicas != readyReplicas
 }
 
+// bootstrapListeners returns the bootstrap servers of the internal listener,
+// reachable through the headless service, and of the external listener when
+// external connectivity is enabled
+func bootstrapListeners(
+	pandaCluster *redpandav1alpha1.Cluster,
+	internalFQDN string,
+	nodesExternal []string,
+) []redpandav1alpha1.BootstrapListener {
+	// TLS is applied to the external listener when it exists, otherwise to
+	// the internal one, unless the external listener has settings of its
+	// own (see the ConfigMap resource)
+	listeners := []redpandav1alpha1.BootstrapListener{
+		{
+			Name:    "internal",
+			Servers: fmt.Sprintf("%s:%d", internalFQDN, pandaCluster.Spec.Configuration.KafkaAPI.Port),
+			TLS:     pandaCluster.InternalKafkaTLS(),
+			SASL:    pandaCluster.KafkaAuthenticationMethod() == redpandav1alpha1.KafkaAuthenticationSASL,
+		},
+	}
+	if pandaCluster.Spec.ExternalConnectivity.Enabled && len(nodesExternal) > 0 {
+		listeners = append(listeners, redpandav1alpha1.BootstrapListener{
+			Name:    "external",
+			Servers: strings.Join(nodesExternal, ","),
+			TLS:     pandaCluster.ExternalKafkaTLS(),
+			SASL:    pandaCluster.ExternalKafkaAuthenticationMethod() == redpandav1alpha1.KafkaAuthenticationSASL,
+		})
+	}
+	return listeners
+}
+
 // WithConfiguratorTag set the configuratorTag
 func (r *ClusterReconciler) WithConfiguratorTag(
 	configuratorTag string,
@@ -230,6 +552,15 @@ func (r *ClusterReconciler) WithConfiguratorTag(
 	return r
 }
 
+// WithOperatorNamespace sets the namespace of the operator, which the
+// NetworkPolicy of a cluster opens the Admin API to
+func (r *ClusterReconciler) WithOperatorNamespace(
+	namespace string,
+) *ClusterReconciler {
+	r.operatorNamespace = namespace
+	return r
+}
+
 func (r *ClusterReconciler) createExternalNodesList(
 	ctx context.Context,
 	pods []corev1.Pod,
@@ -245,10 +576,18 @@ func (r *ClusterReconciler) createExternalNodesList(
 		return []string{}, []string{}, fmt.Errorf("failed to retrieve node port service %s: %w", nodePortName, err)
 	}
 
-	if len(nodePortSvc.Spec.Ports) != 2 || nodePortSvc.Spec.Ports[0].NodePort == 0 || nodePortSvc.Spec.Ports[1].NodePort == 0 {
+	// the service also carries the Pandaproxy port when it is exposed, so the
+	// ports are looked up by name
+	kafkaPort := getNodePort(&nodePortSvc, resources.KafkaPortName)
+	adminPort := getNodePort(&nodePortSvc, resources.AdminPortName)
+	if kafkaPort <= 0 || adminPort <= 0 {
 		return []string{}, []string{}, fmt.Errorf("node port service %s: %w", nodePortName, errNodePortMissing)
 	}
 
+	if port := pandaCluster.Spec.ExternalConnectivity.AdvertisedPort; port != 0 {
+		kafkaPort = int32(port)
+	}
+
 	var node corev1.Node
 	observedNodesExternal := make([]string, 0, len(pods))
 	observedNodesExternalAdmin := make([]string, 0, len(pods))
@@ -258,35 +597,54 @@ func (r *ClusterReconciler) createExternalNodesList(
 			observedNodesExternal = append(observedNodesExternal,
 				fmt.Sprintf("%s.%s:%d",
 					pods[i].Name[prefixLen:],
-					pandaCluster.Spec.ExternalConnectivity.Subdomain,
-					getNodePort(&nodePortSvc, resources.KafkaPortName),
+					pandaCluster.ExternalSubdomain(),
+					kafkaPort,
 				))
 			observedNodesExternalAdmin = append(observedNodesExternalAdmin,
 				fmt.Sprintf("%s.%s:%d",
 					pods[i].Name[prefixLen:],
-					pandaCluster.Spec.ExternalConnectivity.Subdomain,
-					getNodePort(&nodePortSvc, resources.AdminPortName),
+					pandaCluster.ExternalSubdomain(),
+					adminPort,
 				))
 		} else {
 			if err := r.Get(ctx, types.NamespacedName{Name: pods[i].Spec.NodeName}, &node); err != nil {
 				return []string{}, []string{}, fmt.Errorf("failed to retrieve node %s: %w", pods[i].Spec.NodeName, err)
 			}
+			address := externalAddress(&node, pandaCluster.Spec.ExternalConnectivity.NodeAddressAnnotation)
+			if address == "" {
+				r.Log.Info("The node of the broker has no external address, the broker is not published", "pod", pods[i].Name, "node", node.Name)
+				if r.Recorder != nil {
+					r.Recorder.Eventf(pandaCluster, corev1.EventTypeWarning, eventNodeAddressMissing,
+						"node %s of broker %s has no external address", node.Name, pods[i].Name)
+				}
+				continue
+			}
 
 			observedNodesExternal = append(observedNodesExternal,
 				fmt.Sprintf("%s:%d",
-					getExternalIP(&node),
-					getNodePort(&nodePortSvc, resources.KafkaPortName),
+					address,
+					kafkaPort,
 				))
 			observedNodesExternalAdmin = append(observedNodesExternalAdmin,
 				fmt.Sprintf("%s:%d",
-					getExternalIP(&node),
-					getNodePort(&nodePortSvc, resources.AdminPortName),
+					address,
+					adminPort,
 				))
 		}
 	}
 	return observedNodesExternal, observedNodesExternalAdmin, nil
 }
 
+// externalAddress returns the address a broker on the node advertises
+// externally, the value of the annotation or the ExternalIP address of the
+// node. It is empty when the node has neither.
+func externalAddress(node *corev1.Node, annotation string) string {
+	if address := node.Annotations[annotation]; annotation != "" && address != "" {
+		return address
+	}
+	return getExternalIP(node)
+}
+
 func getExternalIP(node *corev1.Node) string {
 	if node == nil {
 		return ""
@@ -76,7 +76,7 @@ var _ = Describe("RedPandaCluster controller", func() {
 					Version:  redpandaContainerTag,
 					Replicas: pointer.Int32Ptr(replicas),
 					Configuration: v1alpha1.RedpandaConfig{
-						KafkaAPI: v1alpha1.SocketAddress{Port: kafkaPort},
+						KafkaAPI: v1alpha1.KafkaAPI{Port: kafkaPort},
 						AdminAPI: v1alpha1.SocketAddress{Port: adminPort},
 					},
 					Resources: corev1.ResourceRequirements{
@@ -150,6 +150,18 @@ var _ = Describe("RedPandaCluster controller", func() {
 					validOwner(redpandaCluster, svc.OwnerReferences)
 			}, timeout, interval).Should(BeTrue())
 
+			By("Creating the bootstrap ClusterIP Service")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), types.NamespacedName{
+					Name:      key.Name + "-cluster",
+					Namespace: key.Namespace,
+				}, &svc)
+				return err == nil &&
+					svc.Spec.ClusterIP != corev1.ClusterIPNone &&
+					findPort(svc.Spec.Ports, res.KafkaPortName) == kafkaPort &&
+					validOwner(redpandaCluster, svc.OwnerReferences)
+			}, timeout, interval).Should(BeTrue())
+
 			By("Creating Configmap with the redpanda configuration")
 			var cm corev1.ConfigMap
 			Eventually(func() bool {
@@ -210,7 +222,8 @@ var _ = Describe("RedPandaCluster controller", func() {
 				return err == nil &&
 					len(rc.Status.Nodes.Internal) == 1 &&
 					len(rc.Status.Nodes.External) == 1 &&
-					len(rc.Status.Nodes.ExternalAdmin) == 1
+					len(rc.Status.Nodes.ExternalAdmin) == 1 &&
+					len(rc.Status.Bootstrap) == 2
 			}, timeout, interval).Should(BeTrue())
 		})
 		It("creates redpanda cluster with tls enabled", func() {
@@ -233,7 +246,7 @@ var _ = Describe("RedPandaCluster controller", func() {
 					Version:  redpandaContainerTag,
 					Replicas: pointer.Int32Ptr(replicas),
 					Configuration: v1alpha1.RedpandaConfig{
-						KafkaAPI: v1alpha1.SocketAddress{Port: kafkaPort},
+						KafkaAPI: v1alpha1.KafkaAPI{Port: kafkaPort},
 						AdminAPI: v1alpha1.SocketAddress{Port: adminPort},
 						TLS: v1alpha1.TLSConfig{
 							KafkaAPI: v1alpha1.KafkaAPITLS{
@@ -0,0 +1,198 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	reasonVersionsAligned = "VersionsAligned"
+	reasonVersionSkew     = "VersionSkew"
+)
+
+// clusterMetadata is the identity and the versions of a running cluster
+type clusterMetadata struct {
+	uuid     string
+	versions []redpandav1alpha1.BrokerVersion
+}
+
+// version returns the version run by every broker or an empty string when
+// the brokers disagree
+func (m *clusterMetadata) version() string {
+	if len(m.versions) == 0 {
+		return ""
+	}
+	for _, v := range m.versions[1:] {
+		if v.Version != m.versions[0].Version {
+			return ""
+		}
+	}
+	return m.versions[0].Version
+}
+
+func (m *clusterMetadata) String() string {
+	byVersion := map[string][]string{}
+	var versions []string
+	for _, v := range m.versions {
+		if _, ok := byVersion[v.Version]; !ok {
+			versions = append(versions, v.Version)
+		}
+		byVersion[v.Version] = append(byVersion[v.Version], fmt.Sprint(v.NodeID))
+	}
+	sort.Strings(versions)
+	parts := make([]string, 0, len(versions))
+	for _, v := range versions {
+		parts = append(parts, fmt.Sprintf("%s on brokers %s", v, strings.Join(byVersion[v], ",")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// brokerVersions returns the versions of the brokers sorted by node ID.
+// Brokers that do not report a version and decommissioned brokers are left
+// out.
+func brokerVersions(brokers []admin.Broker) []redpandav1alpha1.BrokerVersion {
+	var versions []redpandav1alpha1.BrokerVersion
+	for _, b := range brokers {
+		if b.Version == "" || b.MembershipStatus == "decommissioned" {
+			continue
+		}
+		versions = append(versions, redpandav1alpha1.BrokerVersion{NodeID: b.NodeID, Version: b.Version})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].NodeID < versions[j].NodeID })
+	return versions
+}
+
+// reportClusterMetadata records the cluster UUID and the broker versions in
+// the Cluster status, for inventories and upgrade tracking, and sets the
+// VersionSkew condition while the brokers run different versions. A skew
+// is expected during rolling upgrades; a condition that stays true for long
+// points to a stuck upgrade. Metadata the Admin API does not provide, e.g.
+// by this Redpanda version, is left out and reported by notServed.
+func (r *ClusterReconciler) reportClusterMetadata(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+) error {
+	adminAPI, err := r.AdminAPIClientFactory(ctx, r, redpandaCluster, fqdn, adminTLSProvider)
+	if err != nil {
+		return fmt.Errorf("unable to create admin API client: %w", err)
+	}
+	brokers, err := adminAPI.Brokers(ctx)
+	if err != nil {
+		r.Log.Info("Unable to read the broker versions", "error", err)
+		return notServed("cluster metadata", err)
+	}
+	metadata := clusterMetadata{versions: brokerVersions(brokers)}
+	metadata.uuid, err = adminAPI.ClusterUUID(ctx)
+	fallback := notServed("cluster metadata", err)
+	if err != nil && fallback == nil {
+		r.Log.Info("Unable to read the cluster UUID", "error", err)
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		changed := false
+		if metadata.uuid != "" && metadata.uuid != cluster.Status.ClusterUUID {
+			cluster.Status.ClusterUUID = metadata.uuid
+			changed = true
+		}
+		if len(metadata.versions) > 0 {
+			if !reflect.DeepEqual(metadata.versions, cluster.Status.BrokerVersions) {
+				cluster.Status.BrokerVersions = metadata.versions
+				changed = true
+			}
+			if version := metadata.version(); version != cluster.Status.Version {
+				cluster.Status.Version = version
+				changed = true
+			}
+			status, reason, message := corev1.ConditionFalse, reasonVersionsAligned, "every broker runs "+metadata.version()
+			if metadata.version() == "" {
+				status, reason, message = corev1.ConditionTrue, reasonVersionSkew, metadata.String()
+			}
+			if cluster.Status.SetCondition(redpandav1alpha1.ClusterVersionSkew, status, reason, message) {
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update cluster metadata: %w", err)
+	}
+	return fallback
+}
+
+// highestVersion returns the version to record as the highest one once the
+// desired version is applied. A downgrade that is allowed is recorded as the
+// highest version, tags that name no release keep the recorded one.
+func highestVersion(recorded, desired string, allowDowngrade bool) string {
+	version, ok := redpandav1alpha1.ParseRedpandaVersion(desired)
+	if !ok {
+		return recorded
+	}
+	highest, ok := redpandav1alpha1.ParseRedpandaVersion(recorded)
+	if !ok || allowDowngrade || highest.Less(version) {
+		return desired
+	}
+	return recorded
+}
+
+// recordHighestVersion records the version applied to the StatefulSet when
+// it is higher than any version the cluster ran before, so the webhook
+// rejects downgrades
+func (r *ClusterReconciler) recordHighestVersion(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	highest := highestVersion(redpandaCluster.Status.HighestVersion, redpandaCluster.Spec.Version, redpandaCluster.Spec.AllowDowngrade)
+	if highest == redpandaCluster.Status.HighestVersion {
+		return nil
+	}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if cluster.Status.HighestVersion == highest {
+			return nil
+		}
+		cluster.Status.HighestVersion = highest
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record the highest version: %w", err)
+	}
+	redpandaCluster.Status.HighestVersion = highest
+	return nil
+}
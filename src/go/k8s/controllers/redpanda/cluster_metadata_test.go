@@ -0,0 +1,62 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterMetadata(t *testing.T) {
+	metadata := clusterMetadata{versions: brokerVersions([]admin.Broker{
+		{NodeID: 1, MembershipStatus: "active", Version: "v21.11.2"},
+		{NodeID: 0, MembershipStatus: "active", Version: "v21.11.3"},
+		{NodeID: 2, MembershipStatus: "decommissioned", Version: "v21.9.1"},
+		{NodeID: 3, MembershipStatus: "active"},
+	})}
+	assert.Equal(t, []redpandav1alpha1.BrokerVersion{
+		{NodeID: 0, Version: "v21.11.3"},
+		{NodeID: 1, Version: "v21.11.2"},
+	}, metadata.versions)
+	assert.Empty(t, metadata.version())
+	assert.Equal(t, "v21.11.2 on brokers 1; v21.11.3 on brokers 0", metadata.String())
+
+	metadata.versions[1].Version = "v21.11.3"
+	assert.Equal(t, "v21.11.3", metadata.version())
+}
+
+func TestReportClusterMetadata(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+	}
+	f := newFixture(t, cluster)
+
+	// without the broker list no metadata is recorded
+	err := f.reportClusterMetadata(context.Background(), cluster, "cluster.local", nil)
+	assert.True(t, admin.IsUnsupported(err))
+	actual := f.get(t, "cluster")
+	assert.Empty(t, actual.Status.ClusterUUID)
+	assert.Empty(t, actual.Status.BrokerVersions)
+	assert.Nil(t, actual.Status.GetCondition(redpandav1alpha1.ClusterVersionSkew))
+}
+
+func TestHighestVersion(t *testing.T) {
+	assert.Equal(t, "v21.6.1", highestVersion("", "v21.6.1", false))
+	assert.Equal(t, "v21.6.1", highestVersion("v21.4.2", "v21.6.1", false))
+	assert.Equal(t, "v21.6.1", highestVersion("v21.6.1", "v21.4.2", false))
+	assert.Equal(t, "v21.6.1", highestVersion("v21.6.1-rc1", "v21.6.1", false))
+	assert.Equal(t, "v21.6.1", highestVersion("v21.6.1", "latest", false))
+	assert.Equal(t, "v21.4.2", highestVersion("v21.6.1", "v21.4.2", true))
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	reasonClusterReady        = "ClusterReady"
+	reasonBrokersNotReady     = "BrokersNotReady"
+	reasonAdminAPIUnavailable = "AdminAPIUnavailable"
+	reasonClusterUnhealthy    = "ClusterUnhealthy"
+
+	reasonStorageProvisioningFailed = "StorageProvisioningFailed"
+)
+
+// eventControllerLeaderChanged is the reason of the event emitted when the
+// controller leadership moved to another broker
+const eventControllerLeaderChanged = "ControllerLeaderChanged"
+
+// reportClusterReady sets the ClusterReady condition, so pipelines can wait
+// for the whole cluster with kubectl wait --for=condition=ClusterReady, and
+// reports the controller node and its Pod in the Cluster status. An event
+// is emitted when the controller leadership moves to another broker.
+func (r *ClusterReconciler) reportClusterReady(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	sts *appsv1.StatefulSet,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+) error {
+	status, reason, message, controllerID, fallback := r.clusterReadiness(ctx, redpandaCluster, sts, fqdn, adminTLSProvider)
+
+	var previous *int
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		previous = nil
+		changed := cluster.Status.SetCondition(redpandav1alpha1.ClusterReady, status, reason, message)
+		if controllerID != nil && !reflect.DeepEqual(controllerID, cluster.Status.ControllerID) {
+			previous = cluster.Status.ControllerID
+			cluster.Status.ControllerID = controllerID
+			cluster.Status.ControllerLeader = fmt.Sprintf("%s-%d", cluster.Name, *controllerID)
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update cluster ready condition: %w", err)
+	}
+	if previous != nil && r.Recorder != nil {
+		r.Recorder.Eventf(redpandaCluster, corev1.EventTypeNormal, eventControllerLeaderChanged,
+			"controller leadership moved from broker %d to broker %d (%s-%d)",
+			*previous, *controllerID, redpandaCluster.Name, *controllerID)
+	}
+	return fallback
+}
+
+// clusterReadiness returns the ClusterReady condition of the cluster and the
+// controller node when the Admin API could be asked for it, together with
+// the error of the health endpoint when the running version does not serve
+// it
+func (r *ClusterReconciler) clusterReadiness(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	sts *appsv1.StatefulSet,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+) (status corev1.ConditionStatus, reason, message string, controllerID *int, fallback error) {
+	if redpandaCluster.Spec.Replicas == nil || sts == nil ||
+		sts.Status.ReadyReplicas != *redpandaCluster.Spec.Replicas {
+		var ready int32
+		if sts != nil {
+			ready = sts.Status.ReadyReplicas
+		}
+		var replicas int32
+		if redpandaCluster.Spec.Replicas != nil {
+			replicas = *redpandaCluster.Spec.Replicas
+		}
+		message := fmt.Sprintf("%d of %d brokers are ready", ready, replicas)
+		if unbound := redpandaCluster.Status.UnboundVolumes; len(unbound) > 0 {
+			ordinals := make([]int, 0, len(unbound))
+			for _, v := range unbound {
+				ordinals = append(ordinals, v.Ordinal)
+			}
+			return corev1.ConditionFalse, reasonStorageProvisioningFailed,
+				fmt.Sprintf("%s, brokers %v wait for their volumes", message, ordinals), nil, nil
+		}
+		return corev1.ConditionFalse, reasonBrokersNotReady, message, nil, nil
+	}
+
+	adminAPI, err := r.AdminAPIClientFactory(ctx, r, redpandaCluster, fqdn, adminTLSProvider)
+	if err != nil {
+		return corev1.ConditionFalse, reasonAdminAPIUnavailable, err.Error(), nil, nil
+	}
+	health, err := adminAPI.ClusterHealth(ctx)
+	fallback = notServed("cluster readiness", err)
+	reported := fallback == nil
+	if !reported {
+		// this Redpanda version does not report the cluster health, the
+		// readiness of the brokers is all there is to go by
+		health = admin.ClusterHealthOverview{IsHealthy: true, ControllerID: -1}
+	} else if err != nil {
+		return corev1.ConditionFalse, reasonAdminAPIUnavailable, err.Error(), nil, nil
+	}
+
+	controllerID = &health.ControllerID
+	if health.ControllerID < 0 {
+		controllerID = nil
+	}
+	switch {
+	case !health.IsHealthy, len(health.NodesDown) > 0:
+		return corev1.ConditionFalse, reasonClusterUnhealthy,
+			fmt.Sprintf("nodes down: %v", health.NodesDown), controllerID, fallback
+	case len(health.LeaderlessPartitions) > 0:
+		return corev1.ConditionFalse, reasonClusterUnhealthy,
+			fmt.Sprintf("%d partitions without leader", len(health.LeaderlessPartitions)), controllerID, fallback
+	case health.UnderReplicatedCount > 0:
+		return corev1.ConditionFalse, reasonClusterUnhealthy,
+			fmt.Sprintf("%d under replicated partitions", health.UnderReplicatedCount), controllerID, fallback
+	}
+	if redpandaCluster.WarmingTopics() {
+		return corev1.ConditionFalse, reasonTopicsWarming, "the topics of the topic warmup are warming up", controllerID, fallback
+	}
+	if !reported {
+		return corev1.ConditionTrue, reasonClusterReady, "all brokers are ready, the running version does not report the cluster health", nil, fallback
+	}
+	return corev1.ConditionTrue, reasonClusterReady, "all brokers are ready and the cluster is healthy", controllerID, fallback
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestReportClusterReady(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec:       redpandav1alpha1.ClusterSpec{Replicas: pointer.Int32Ptr(3)},
+	}
+	f := newFixture(t, cluster)
+
+	report := func(ready int32) (*redpandav1alpha1.Cluster, error) {
+		sts := &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: ready}}
+		err := f.reportClusterReady(context.Background(), cluster, sts, "cluster.local", nil)
+		actual := f.get(t, "cluster")
+		return &actual, err
+	}
+
+	actual, err := report(2)
+	require.NoError(t, err)
+	condition := actual.Status.GetCondition(redpandav1alpha1.ClusterReady)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionFalse, condition.Status)
+	assert.Equal(t, reasonBrokersNotReady, condition.Reason)
+
+	// without the cluster health the ready brokers are all there is to go
+	// by, no controller is reported
+	actual, err = report(3)
+	assert.True(t, admin.IsUnsupported(err))
+	condition = actual.Status.GetCondition(redpandav1alpha1.ClusterReady)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Contains(t, condition.Message, "does not report the cluster health")
+	assert.Nil(t, actual.Status.ControllerID)
+	assert.Empty(t, f.recorder.Events)
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// referencedConfigMaps returns the ConfigMaps the reconciliation of the
+// cluster reads that are not owned by it
+func referencedConfigMaps(
+	cluster *redpandav1alpha1.Cluster,
+) []types.NamespacedName {
+	var configMaps []types.NamespacedName
+	if ref := cluster.Spec.SuperusersFrom; ref != nil {
+		configMaps = append(configMaps, types.NamespacedName{Name: ref.Name, Namespace: cluster.Namespace})
+	}
+	return configMaps
+}
+
+// clustersReferencingConfigMap maps a ConfigMap event to the Clusters that
+// read the ConfigMap, so changed lists are applied without waiting for the
+// resync
+func (r *ClusterReconciler) clustersReferencingConfigMap(
+	obj client.Object,
+) []reconcile.Request {
+	var clusters redpandav1alpha1.ClusterList
+	if err := r.List(context.Background(), &clusters, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "Unable to list clusters for configmap", "configmap", obj.GetName())
+		return nil
+	}
+	configMap := types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	var requests []reconcile.Request
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		for _, ref := range referencedConfigMaps(cluster) {
+			if ref == configMap {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+					Name:      cluster.Name,
+					Namespace: cluster.Namespace,
+				}})
+				break
+			}
+		}
+	}
+	return requests
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestClustersReferencingConfigMap(t *testing.T) {
+	referencing := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "referencing", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			SuperusersFrom: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "operators"},
+				Key:                  "superusers",
+			},
+		},
+	}
+	other := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+	}
+
+	f := newFixture(t, referencing, other)
+
+	configMap := func(namespace, name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "referencing", Namespace: "default"}}},
+		f.clustersReferencingConfigMap(configMap("default", "operators")))
+	assert.Empty(t, f.clustersReferencingConfigMap(configMap("kube-system", "operators")))
+	assert.Empty(t, f.clustersReferencingConfigMap(configMap("default", "other")))
+}
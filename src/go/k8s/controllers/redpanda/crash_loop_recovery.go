@@ -0,0 +1,145 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonNoCrashLoop              = "NoCrashLoop"
+	reasonCrashLoopConfigReset     = "ConfigReset"
+	reasonCrashLoopRecoveryLimited = "RecoveryRateLimited"
+
+	crashLoopBackOff = "CrashLoopBackOff"
+)
+
+// crashLooping returns true when the Redpanda container of the Pod waits in
+// CrashLoopBackOff after at least threshold restarts
+func crashLooping(pod *corev1.Pod, container string, threshold int32) bool {
+	for _, s := range pod.Status.ContainerStatuses {
+		if s.Name != container {
+			continue
+		}
+		return s.RestartCount >= threshold &&
+			s.State.Waiting != nil && s.State.Waiting.Reason == crashLoopBackOff
+	}
+	return false
+}
+
+// reconcileCrashLoopRecovery recreates the Pod of a crash looping broker, so
+// the configurator renders its configuration again, and reports the crash
+// looping brokers in the BrokersCrashLooping condition. At most one Pod is
+// recreated per interval, a broker that keeps crash looping afterwards is
+// only reported. The returned duration is how long the next recovery waits.
+func (r *ClusterReconciler) reconcileCrashLoopRecovery(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) (time.Duration, error) {
+	if redpandaCluster.Spec.CrashLoopRecovery == nil {
+		return 0, nil
+	}
+
+	var podList corev1.PodList
+	err := r.List(ctx, &podList, &client.ListOptions{
+		LabelSelector: labels.ForCluster(redpandaCluster).AsClientSelector(),
+		Namespace:     redpandaCluster.Namespace,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to list the broker Pods: %w", err)
+	}
+	threshold := redpandaCluster.CrashLoopRestartThreshold()
+	var looping []*corev1.Pod
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.DeletionTimestamp.IsZero() && crashLooping(pod, redpandaCluster.RedpandaContainerName(), threshold) {
+			looping = append(looping, pod)
+		}
+	}
+	sort.Slice(looping, func(i, j int) bool { return looping[i].Name < looping[j].Name })
+
+	now := time.Now()
+	last := redpandaCluster.Status.LastCrashLoopRecovery
+	status, reason := corev1.ConditionFalse, reasonNoCrashLoop
+	message := fmt.Sprintf("no broker restarted %d times", threshold)
+	var wait time.Duration
+	if len(looping) > 0 {
+		names := make([]string, 0, len(looping))
+		for _, pod := range looping {
+			names = append(names, pod.Name)
+		}
+		status = corev1.ConditionTrue
+		next := now
+		if last != nil {
+			next = last.Add(redpandaCluster.CrashLoopRecoveryInterval())
+		}
+		if now.Before(next) {
+			wait = next.Sub(now)
+			reason = reasonCrashLoopRecoveryLimited
+			message = fmt.Sprintf("crash looping brokers: %s; the next recovery is possible at %s",
+				strings.Join(names, ", "), next.UTC().Format(time.RFC3339))
+		} else {
+			pod := looping[0]
+			r.Log.Info("Recreating the Pod of a crash looping broker", "pod", pod.Name)
+			if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+				return 0, fmt.Errorf("unable to recreate the crash looping Pod %s: %w", pod.Name, err)
+			}
+			if r.Recorder != nil {
+				r.Recorder.Eventf(redpandaCluster, corev1.EventTypeWarning, reasonCrashLoopConfigReset,
+					"recreating pod %s to render its configuration again, it restarted at least %d times", pod.Name, threshold)
+			}
+			t := metav1.NewTime(now)
+			last = &t
+			reason = reasonCrashLoopConfigReset
+			message = fmt.Sprintf("crash looping brokers: %s; recreated pod %s at %s",
+				strings.Join(names, ", "), pod.Name, now.UTC().Format(time.RFC3339))
+		}
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		changed := false
+		if len(looping) > 0 || cluster.Status.GetCondition(redpandav1alpha1.ClusterBrokersCrashLooping) != nil {
+			changed = cluster.Status.SetCondition(redpandav1alpha1.ClusterBrokersCrashLooping, status, reason, message)
+		}
+		if last != nil && (cluster.Status.LastCrashLoopRecovery == nil || !cluster.Status.LastCrashLoopRecovery.Equal(last)) {
+			cluster.Status.LastCrashLoopRecovery = last
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return wait, fmt.Errorf("failed to update the crash loop condition: %w", err)
+	}
+	redpandaCluster.Status.LastCrashLoopRecovery = last
+	return wait, nil
+}
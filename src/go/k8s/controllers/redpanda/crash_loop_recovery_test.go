@@ -0,0 +1,92 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestReconcileCrashLoopRecovery(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			CrashLoopRecovery: &redpandav1alpha1.CrashLoopRecovery{},
+		},
+	}
+	pod := func(name string, restarts int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    labels.ForCluster(cluster),
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:         cluster.RedpandaContainerName(),
+					RestartCount: restarts,
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+					},
+				}},
+			},
+		}
+	}
+	f := newFixture(t, cluster, pod("cluster-0", 2), pod("cluster-1", 7), pod("cluster-2", 9))
+	key := types.NamespacedName{Name: "cluster", Namespace: "default"}
+	exists := func(name string) bool {
+		err := f.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, &corev1.Pod{})
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		require.NoError(t, err)
+		return true
+	}
+
+	// one crash looping Pod is recreated
+	wait, err := f.reconcileCrashLoopRecovery(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	assert.True(t, exists("cluster-0"))
+	assert.False(t, exists("cluster-1"))
+	assert.True(t, exists("cluster-2"))
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	condition := actual.Status.GetCondition(redpandav1alpha1.ClusterBrokersCrashLooping)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, reasonCrashLoopConfigReset, condition.Reason)
+	require.NotNil(t, actual.Status.LastCrashLoopRecovery)
+
+	// the next one waits for the interval and is only reported
+	wait, err = f.reconcileCrashLoopRecovery(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.Greater(t, int64(wait), int64(25*time.Minute))
+	assert.True(t, exists("cluster-2"))
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	assert.Equal(t, reasonCrashLoopRecoveryLimited, actual.Status.GetCondition(redpandav1alpha1.ClusterBrokersCrashLooping).Reason)
+
+	// the condition clears once no broker crash loops
+	require.NoError(t, f.Delete(context.Background(), pod("cluster-2", 9)))
+	_, err = f.reconcileCrashLoopRecovery(context.Background(), cluster)
+	require.NoError(t, err)
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	assert.Equal(t, corev1.ConditionFalse, actual.Status.GetCondition(redpandav1alpha1.ClusterBrokersCrashLooping).Status)
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+// currentOperation returns the operation the cluster is in or nil when it is
+// idle. The phase is the message of the resource the reconcile waits for, if
+// any. Brokers are counted by the versions they report, so a broker counts
+// once it joined the cluster.
+func currentOperation(
+	cluster *redpandav1alpha1.Cluster, phase string,
+) *redpandav1alpha1.ClusterOperation {
+	var replicas int
+	if cluster.Spec.Replicas != nil {
+		replicas = int(*cluster.Spec.Replicas)
+	}
+	joined := len(cluster.Status.BrokerVersions)
+	// releases that do not report versions count the ready brokers
+	if joined == 0 {
+		joined = int(cluster.Status.Replicas)
+	}
+
+	var operation redpandav1alpha1.ClusterOperation
+	switch {
+	case cluster.Status.Upgrading:
+		operation.Type = redpandav1alpha1.ClusterOperationUpgrade
+		operation.Description = "upgrading to " + cluster.FullImageName()
+	case joined == 0 && (replicas > 0 || phase != ""):
+		operation.Type = redpandav1alpha1.ClusterOperationProvision
+		operation.Description = fmt.Sprintf("creating a cluster of %d brokers", replicas)
+	case joined < replicas:
+		operation.Type = redpandav1alpha1.ClusterOperationScaleUp
+		operation.Description = fmt.Sprintf("scaling %d to %d brokers", joined, replicas)
+		if phase == "" {
+			phase = fmt.Sprintf("joining broker %d", nextJoiningBroker(cluster.Status.BrokerVersions, joined, replicas))
+		}
+	case phase != "":
+		operation.Type = redpandav1alpha1.ClusterOperationProvision
+		operation.Description = "applying the cluster resources"
+	default:
+		return nil
+	}
+	operation.Phase = phase
+
+	// An operation keeps its description and start time until it ends
+	if current := cluster.Status.CurrentOperation; current != nil && current.Type == operation.Type {
+		operation.Description = current.Description
+		operation.StartTime = current.StartTime
+	} else {
+		operation.StartTime = metav1.NewTime(time.Now())
+	}
+	return &operation
+}
+
+// nextJoiningBroker returns the lowest ordinal that did not join the cluster.
+// Without versions the brokers are assumed to join in the order of their
+// ordinals.
+func nextJoiningBroker(
+	versions []redpandav1alpha1.BrokerVersion, joinedCount, replicas int,
+) int {
+	if len(versions) == 0 {
+		return joinedCount
+	}
+	joined := make(map[int]bool, len(versions))
+	for _, v := range versions {
+		joined[v.NodeID] = true
+	}
+	for ordinal := 0; ordinal < replicas; ordinal++ {
+		if !joined[ordinal] {
+			return ordinal
+		}
+	}
+	return replicas
+}
+
+// reportCurrentOperation records the operation the operator is running in the
+// Cluster status, so dashboards can show it without reading the logs
+func (r *ClusterReconciler) reportCurrentOperation(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster, phase string,
+) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		operation := currentOperation(&cluster, phase)
+		if reflect.DeepEqual(operation, cluster.Status.CurrentOperation) {
+			return nil
+		}
+		cluster.Status.CurrentOperation = operation
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the current operation: %w", err)
+	}
+	return nil
+}
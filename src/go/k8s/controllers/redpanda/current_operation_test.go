@@ -0,0 +1,57 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"k8s.io/utils/pointer"
+)
+
+func TestCurrentOperation(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{}
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	cluster.Spec.Image = "vectorized/redpanda"
+	cluster.Spec.Version = "v21.11.3"
+
+	operation := currentOperation(cluster, "waiting for the certificate cluster-redpanda")
+	require.NotNil(t, operation)
+	assert.Equal(t, redpandav1alpha1.ClusterOperationProvision, operation.Type)
+	assert.Equal(t, "waiting for the certificate cluster-redpanda", operation.Phase)
+
+	cluster.Status.BrokerVersions = []redpandav1alpha1.BrokerVersion{
+		{NodeID: 0}, {NodeID: 1}, {NodeID: 2},
+	}
+	assert.Nil(t, currentOperation(cluster, ""))
+
+	cluster.Spec.Replicas = pointer.Int32Ptr(5)
+	operation = currentOperation(cluster, "")
+	require.NotNil(t, operation)
+	assert.Equal(t, redpandav1alpha1.ClusterOperationScaleUp, operation.Type)
+	assert.Equal(t, "scaling 3 to 5 brokers", operation.Description)
+	assert.Equal(t, "joining broker 3", operation.Phase)
+
+	// the description of a running operation is kept
+	cluster.Status.CurrentOperation = operation
+	cluster.Status.BrokerVersions = append(cluster.Status.BrokerVersions, redpandav1alpha1.BrokerVersion{NodeID: 3})
+	next := currentOperation(cluster, "")
+	assert.Equal(t, "scaling 3 to 5 brokers", next.Description)
+	assert.Equal(t, "joining broker 4", next.Phase)
+	assert.Equal(t, operation.StartTime, next.StartTime)
+
+	cluster.Status.Upgrading = true
+	operation = currentOperation(cluster, "wait for pod (ordinal: 4) to restart")
+	require.NotNil(t, operation)
+	assert.Equal(t, redpandav1alpha1.ClusterOperationUpgrade, operation.Type)
+	assert.Equal(t, "upgrading to vectorized/redpanda:v21.11.3", operation.Description)
+}
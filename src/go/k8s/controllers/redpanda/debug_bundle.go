@@ -0,0 +1,242 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	debugBundleComponent     = "debug-bundle"
+	debugBundleContainerName = "debug-bundle"
+	debugBundleHashBytes     = 5
+	debugBundleOutput        = "/tmp/debug-bundle.zip"
+	// debugBundleDeadlineSeconds stops a bundle that hangs, e.g. on an
+	// unreachable broker
+	debugBundleDeadlineSeconds = 30 * 60
+)
+
+// debugBundleLabels are the labels of the debug bundle Jobs of the cluster
+func debugBundleLabels(cluster *redpandav1alpha1.Cluster) labels.CommonLabels {
+	l := make(labels.CommonLabels)
+	for k, v := range labels.ForCluster(cluster) {
+		l[k] = v
+	}
+	l[labels.ComponentKey] = debugBundleComponent
+	return l
+}
+
+// debugBundleJobName derives the Job name from the request, so each request
+// is collected once
+func debugBundleJobName(cluster *redpandav1alpha1.Cluster, request string) string {
+	sum := sha256.Sum256([]byte(request))
+	return fmt.Sprintf("%s-debug-bundle-%x", cluster.Name, sum[:debugBundleHashBytes])
+}
+
+// debugBundleJob returns the Job running rpk debug bundle against the brokers
+// and uploading the bundle to the configured URL
+func (r *ClusterReconciler) debugBundleJob(
+	cluster *redpandav1alpha1.Cluster, name, fqdn string,
+) (*batchv1.Job, error) {
+	replicas := 0
+	if cluster.Spec.Replicas != nil {
+		replicas = int(*cluster.Spec.Replicas)
+	}
+	adminHosts := make([]string, 0, replicas)
+	brokers := make([]string, 0, replicas)
+	for i := 0; i < replicas; i++ {
+		host := fmt.Sprintf("%s-%d.%s", cluster.Name, i, strings.TrimSuffix(fqdn, "."))
+		adminHosts = append(adminHosts, fmt.Sprintf("%s:%d", host, cluster.Spec.Configuration.AdminAPI.Port))
+		brokers = append(brokers, fmt.Sprintf("%s:%d", host, cluster.Spec.Configuration.KafkaAPI.Port))
+	}
+	spec := cluster.Spec.DebugBundle
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cluster.Namespace,
+			Labels:    debugBundleLabels(cluster),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          pointer.Int32Ptr(1),
+			ActiveDeadlineSeconds: pointer.Int64Ptr(debugBundleDeadlineSeconds),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: spec.ServiceAccountName,
+					Containers: []corev1.Container{
+						{
+							Name:    debugBundleContainerName,
+							Image:   cluster.FullImageName(),
+							Command: []string{"rpk", "debug", "bundle"},
+							Args: []string{
+								"--namespace", cluster.Namespace,
+								"--output", debugBundleOutput,
+								"--upload-url", "$(UPLOAD_URL)",
+							},
+							Env: []corev1.EnvVar{
+								{
+									Name:  "RPK_ADMIN_HOSTS",
+									Value: strings.Join(adminHosts, ","),
+								},
+								{
+									Name:  "RPK_BROKERS",
+									Value: strings.Join(brokers, ","),
+								},
+								{
+									Name: "UPLOAD_URL",
+									ValueFrom: &corev1.EnvVarSource{
+										SecretKeyRef: spec.UploadURLSecretRef.DeepCopy(),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := resources.SetOwner(cluster, job, r.Scheme); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// debugBundlePhase returns the phase of the bundle collected by the Job and
+// when the Job finished
+func debugBundlePhase(job *batchv1.Job) (redpandav1alpha1.DebugBundlePhase, *metav1.Time) {
+	if job.Status.Succeeded > 0 {
+		return redpandav1alpha1.DebugBundleSucceeded, job.Status.CompletionTime
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			t := c.LastTransitionTime
+			return redpandav1alpha1.DebugBundleFailed, &t
+		}
+	}
+	return redpandav1alpha1.DebugBundleRunning, nil
+}
+
+// reconcileDebugBundle starts a debug bundle Job for a new value of the
+// DebugBundleAnnotation and reports its progress in the status. A request
+// waits until the minimal interval since the start of the previous bundle
+// passed, the returned duration is how long it still waits. The Job of the
+// previous bundle is deleted when the next one starts.
+func (r *ClusterReconciler) reconcileDebugBundle(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster, fqdn string,
+) (time.Duration, error) {
+	request := redpandaCluster.Annotations[redpandav1alpha1.DebugBundleAnnotation]
+	if request == "" || redpandaCluster.Spec.DebugBundle == nil {
+		return 0, nil
+	}
+	if s := redpandaCluster.Status.DebugBundle; s != nil && s.Request == request &&
+		(s.Phase == redpandav1alpha1.DebugBundleSucceeded || s.Phase == redpandav1alpha1.DebugBundleFailed) {
+		return 0, nil
+	}
+
+	var jobs batchv1.JobList
+	err := r.List(ctx, &jobs, &client.ListOptions{
+		LabelSelector: debugBundleLabels(redpandaCluster).AsClientSelector(),
+		Namespace:     redpandaCluster.Namespace,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to list the debug bundle Jobs: %w", err)
+	}
+	name := debugBundleJobName(redpandaCluster, request)
+	var current *batchv1.Job
+	var previous []*batchv1.Job
+	var lastStart time.Time
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if job.Name == name {
+			current = job
+			continue
+		}
+		previous = append(previous, job)
+		if job.CreationTimestamp.After(lastStart) {
+			lastStart = job.CreationTimestamp.Time
+		}
+	}
+
+	status := redpandav1alpha1.DebugBundleStatus{Request: request, JobName: name}
+	switch {
+	case current != nil:
+		status.StartTime = &current.CreationTimestamp
+		status.Phase, status.CompletionTime = debugBundlePhase(current)
+	case !lastStart.IsZero() && time.Until(lastStart.Add(redpandaCluster.DebugBundleMinInterval())) > 0:
+		wait := time.Until(lastStart.Add(redpandaCluster.DebugBundleMinInterval()))
+		r.Log.Info("Debug bundle waits for the minimal interval", "request", request, "wait", wait)
+		status.JobName = ""
+		status.Phase = redpandav1alpha1.DebugBundlePending
+		return wait, r.updateDebugBundleStatus(ctx, redpandaCluster, &status)
+	default:
+		for _, job := range previous {
+			err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+			if err != nil && !apierrors.IsNotFound(err) {
+				return 0, fmt.Errorf("unable to delete the debug bundle Job %s: %w", job.Name, err)
+			}
+		}
+		job, err := r.debugBundleJob(redpandaCluster, name, fqdn)
+		if err != nil {
+			return 0, fmt.Errorf("unable to construct the debug bundle Job: %w", err)
+		}
+		r.Log.Info("Collecting a debug bundle", "request", request, "job", name)
+		if err := r.Create(ctx, job); err != nil && !apierrors.IsAlreadyExists(err) {
+			return 0, fmt.Errorf("unable to create the debug bundle Job: %w", err)
+		}
+		now := metav1.Now()
+		status.StartTime = &now
+		status.Phase = redpandav1alpha1.DebugBundleRunning
+	}
+	return 0, r.updateDebugBundleStatus(ctx, redpandaCluster, &status)
+}
+
+func (r *ClusterReconciler) updateDebugBundleStatus(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	status *redpandav1alpha1.DebugBundleStatus,
+) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if reflect.DeepEqual(cluster.Status.DebugBundle, status) {
+			return nil
+		}
+		cluster.Status.DebugBundle = status
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the debug bundle status: %w", err)
+	}
+	redpandaCluster.Status.DebugBundle = status
+	return nil
+}
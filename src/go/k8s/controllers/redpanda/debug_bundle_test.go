@@ -0,0 +1,103 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestReconcileDebugBundle(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cluster",
+			Namespace:   "default",
+			Annotations: map[string]string{redpandav1alpha1.DebugBundleAnnotation: "case-1234"},
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replicas: pointer.Int32Ptr(2),
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				AdminAPI: redpandav1alpha1.SocketAddress{Port: 9644},
+			},
+			DebugBundle: &redpandav1alpha1.DebugBundle{
+				UploadURLSecretRef: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "upload"},
+					Key:                  "url",
+				},
+			},
+		},
+	}
+	previous := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "cluster-debug-bundle-previous",
+			Namespace:         "default",
+			Labels:            debugBundleLabels(cluster),
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-10 * time.Minute)),
+		},
+	}
+	f := newFixture(t, cluster, previous)
+	key := types.NamespacedName{Name: "cluster", Namespace: "default"}
+
+	// the request waits for the minimal interval since the previous bundle
+	wait, err := f.reconcileDebugBundle(context.Background(), cluster, "cluster.local")
+	require.NoError(t, err)
+	assert.Greater(t, int64(wait), int64(45*time.Minute))
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	require.NotNil(t, actual.Status.DebugBundle)
+	assert.Equal(t, redpandav1alpha1.DebugBundlePending, actual.Status.DebugBundle.Phase)
+
+	// once it passed the Job replaces the one of the previous bundle
+	cluster.Spec.DebugBundle.MinInterval = &metav1.Duration{Duration: 5 * time.Minute}
+	wait, err = f.reconcileDebugBundle(context.Background(), cluster, "cluster.local")
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	err = f.Get(context.Background(), types.NamespacedName{Name: previous.Name, Namespace: "default"}, &batchv1.Job{})
+	assert.True(t, apierrors.IsNotFound(err))
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	require.Equal(t, redpandav1alpha1.DebugBundleRunning, actual.Status.DebugBundle.Phase)
+	var job batchv1.Job
+	require.NoError(t, f.Get(context.Background(), types.NamespacedName{Name: actual.Status.DebugBundle.JobName, Namespace: "default"}, &job))
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{"rpk", "debug", "bundle"}, container.Command)
+	assert.Contains(t, container.Env, corev1.EnvVar{
+		Name:  "RPK_ADMIN_HOSTS",
+		Value: "cluster-0.cluster.local:9644,cluster-1.cluster.local:9644",
+	})
+
+	// the result of the Job is reported and the request is not collected again
+	completion := metav1.Now()
+	job.Status.Succeeded = 1
+	job.Status.CompletionTime = &completion
+	require.NoError(t, f.Status().Update(context.Background(), &job))
+	_, err = f.reconcileDebugBundle(context.Background(), cluster, "cluster.local")
+	require.NoError(t, err)
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	assert.Equal(t, redpandav1alpha1.DebugBundleSucceeded, actual.Status.DebugBundle.Phase)
+	assert.NotNil(t, actual.Status.DebugBundle.CompletionTime)
+
+	require.NoError(t, f.Delete(context.Background(), &job))
+	_, err = f.reconcileDebugBundle(context.Background(), cluster, "cluster.local")
+	require.NoError(t, err)
+	var jobs batchv1.JobList
+	require.NoError(t, f.List(context.Background(), &jobs))
+	assert.Empty(t, jobs.Items)
+}
@@ -0,0 +1,225 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonServing    = "Serving"
+	reasonDraining   = "Draining"
+	eventDrainBroker = "DrainingBroker"
+
+	// decommissionDrainRequeue is how often draining brokers are checked,
+	// neither the progress of the decommissioning nor the end of the drain
+	// timeout change a watched object
+	decommissionDrainRequeue = 10 * time.Second
+)
+
+// drainingBrokers returns the decommissioned brokers of the Pod ordinals,
+// ordered by node ID. A broker is draining while Redpanda decommissions it
+// or until the drain timeout since it was first seen decommissioned passed,
+// afterwards it is drained. When the brokers could not be read the previous
+// report is kept.
+func drainingBrokers(
+	previous []redpandav1alpha1.DrainingBroker,
+	brokers []admin.Broker,
+	ordinals []int,
+	timeout time.Duration,
+	now time.Time,
+) []redpandav1alpha1.DrainingBroker {
+	tracked := make(map[int]redpandav1alpha1.DrainingBroker, len(previous))
+	for _, b := range previous {
+		tracked[b.NodeID] = b
+	}
+	membership := make(map[int]string, len(brokers))
+	for _, b := range brokers {
+		membership[b.NodeID] = b.MembershipStatus
+	}
+	var draining []redpandav1alpha1.DrainingBroker
+	for _, id := range ordinals {
+		b, ok := tracked[id]
+		status, listed := membership[id]
+		switch {
+		case brokers == nil:
+			if !ok {
+				continue
+			}
+		case status == membershipDraining:
+			if !ok {
+				b = redpandav1alpha1.DrainingBroker{NodeID: id, Since: metav1.NewTime(now)}
+			}
+			b.Phase = redpandav1alpha1.DrainPhaseDraining
+		case ok && (!listed || status != membershipActive):
+			b.Phase = redpandav1alpha1.DrainPhaseDraining
+			if now.Sub(b.Since.Time) >= timeout {
+				b.Phase = redpandav1alpha1.DrainPhaseDrained
+			}
+		default:
+			// active, e.g. recommissioned
+			continue
+		}
+		draining = append(draining, b)
+	}
+	sort.Slice(draining, func(i, j int) bool { return draining[i].NodeID < draining[j].NodeID })
+	return draining
+}
+
+// reconcileDecommissionDrain turns the serving readiness gate of the
+// brokers off once they are decommissioned, so their Pods leave the Service
+// endpoints and clients bootstrap from the other brokers, and reports the
+// progress of the draining. The other brokers get the gate turned on. It
+// returns true while brokers drain, so they are checked again soon. The
+// Admin API of this Redpanda version does not list the brokers, so none is
+// seen decommissioned: every broker gets the gate turned on, without it their
+// Pods would never be ready, and the endpoint is reported by notServed.
+func (r *ClusterReconciler) reconcileDecommissionDrain(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+) (bool, error) {
+	if redpandaCluster.Spec.DecommissionDrain == nil {
+		return false, nil
+	}
+
+	var podList corev1.PodList
+	err := r.List(ctx, &podList, &client.ListOptions{
+		LabelSelector: labels.ForCluster(redpandaCluster).AsClientSelector(),
+		Namespace:     redpandaCluster.Namespace,
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to list the broker Pods: %w", err)
+	}
+	pods := make(map[int]*corev1.Pod, len(podList.Items))
+	ordinals := make([]int, 0, len(podList.Items))
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		ordinal, ok := resources.PodOrdinal(redpandaCluster, pod.Name)
+		if !ok || !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		pods[int(ordinal)] = pod
+		ordinals = append(ordinals, int(ordinal))
+	}
+
+	var brokers []admin.Broker
+	adminAPI, err := r.AdminAPIClientFactory(ctx, r, redpandaCluster, fqdn, adminTLSProvider)
+	if err == nil {
+		brokers, err = adminAPI.Brokers(ctx)
+	}
+	fallback := notServed("decommission drain", err)
+	if err != nil {
+		// the brokers of a new cluster get their gate before the Admin API
+		// is reachable
+		r.Log.Info("Unable to read the decommissioned brokers", "error", err)
+		brokers = nil
+	}
+
+	draining := drainingBrokers(redpandaCluster.Status.DrainingBrokers, brokers, ordinals,
+		redpandaCluster.DecommissionDrainTimeout(), time.Now())
+	drainingIDs := make(map[int]bool, len(draining))
+	for _, b := range draining {
+		drainingIDs[b.NodeID] = true
+	}
+
+	for _, id := range ordinals {
+		pod := pods[id]
+		status, reason := corev1.ConditionTrue, reasonServing
+		if drainingIDs[id] {
+			status, reason = corev1.ConditionFalse, reasonDraining
+		}
+		if !setPodCondition(pod, resources.ServingReadinessGate, status, reason) {
+			continue
+		}
+		if status == corev1.ConditionFalse {
+			r.Log.Info("Draining decommissioned broker", "pod", pod.Name, "node id", id)
+			if r.Recorder != nil {
+				r.Recorder.Eventf(redpandaCluster, corev1.EventTypeNormal, eventDrainBroker,
+					"broker %d is decommissioned, pod %s stops receiving new connections", id, pod.Name)
+			}
+		}
+		// Conflicts with the kubelet are retried on the next reconcile
+		if err := r.Status().Update(ctx, pod); err != nil {
+			return true, fmt.Errorf("unable to set the readiness gate of %s: %w", pod.Name, err)
+		}
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if reflect.DeepEqual(draining, cluster.Status.DrainingBrokers) {
+			return nil
+		}
+		cluster.Status.DrainingBrokers = draining
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return true, fmt.Errorf("failed to update the draining brokers: %w", err)
+	}
+	redpandaCluster.Status.DrainingBrokers = draining
+	for _, b := range draining {
+		if b.Phase == redpandav1alpha1.DrainPhaseDraining {
+			return true, fallback
+		}
+	}
+	return false, fallback
+}
+
+// setPodCondition sets the condition of the Pod and returns true when it
+// changed
+func setPodCondition(
+	pod *corev1.Pod,
+	conditionType corev1.PodConditionType,
+	status corev1.ConditionStatus,
+	reason string,
+) bool {
+	for i := range pod.Status.Conditions {
+		c := &pod.Status.Conditions[i]
+		if c.Type != conditionType {
+			continue
+		}
+		if c.Status == status {
+			return false
+		}
+		c.Status = status
+		c.Reason = reason
+		c.LastTransitionTime = metav1.Now()
+		return true
+	}
+	pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		LastTransitionTime: metav1.Now(),
+	})
+	return true
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestDrainingBrokers(t *testing.T) {
+	now := time.Now()
+	earlier := metav1.NewTime(now.Add(-time.Hour))
+	previous := []redpandav1alpha1.DrainingBroker{
+		{NodeID: 2, Since: earlier, Phase: redpandav1alpha1.DrainPhaseDraining},
+		{NodeID: 3, Since: metav1.NewTime(now), Phase: redpandav1alpha1.DrainPhaseDraining},
+	}
+	draining := drainingBrokers(previous, []admin.Broker{
+		{NodeID: 0, MembershipStatus: "active"},
+		{NodeID: 1, MembershipStatus: "draining"},
+	}, []int{0, 1, 2, 3}, time.Minute, now)
+	require.Len(t, draining, 3)
+	assert.Equal(t, redpandav1alpha1.DrainingBroker{NodeID: 1, Since: metav1.NewTime(now), Phase: redpandav1alpha1.DrainPhaseDraining}, draining[0])
+	// removed by Redpanda, but only drained once the timeout passed
+	assert.Equal(t, redpandav1alpha1.DrainPhaseDrained, draining[1].Phase)
+	assert.Equal(t, redpandav1alpha1.DrainPhaseDraining, draining[2].Phase)
+
+	// the previous report is kept while the brokers can not be read
+	assert.Equal(t, previous, drainingBrokers(previous, nil, []int{0, 1, 2, 3}, time.Minute, now))
+}
+
+func TestReconcileDecommissionDrain(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replicas:          pointer.Int32Ptr(2),
+			DecommissionDrain: &redpandav1alpha1.DecommissionDrain{},
+		},
+	}
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    labels.ForCluster(cluster),
+		}}
+	}
+	f := newFixture(t, cluster, pod("cluster-0"), pod("cluster-1"))
+	gate := func(name string) corev1.ConditionStatus {
+		var p corev1.Pod
+		require.NoError(t, f.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, &p))
+		for _, c := range p.Status.Conditions {
+			if c.Type == resources.ServingReadinessGate {
+				return c.Status
+			}
+		}
+		return ""
+	}
+
+	// without the broker list no broker is seen decommissioned, every Pod
+	// gets the gate so it can become ready
+	draining, err := f.reconcileDecommissionDrain(context.Background(), cluster, "cluster.local", nil)
+	assert.True(t, admin.IsUnsupported(err))
+	assert.False(t, draining)
+	assert.Equal(t, corev1.ConditionTrue, gate("cluster-0"))
+	assert.Equal(t, corev1.ConditionTrue, gate("cluster-1"))
+	actual := f.get(t, "cluster")
+	assert.Empty(t, actual.Status.DrainingBrokers)
+}
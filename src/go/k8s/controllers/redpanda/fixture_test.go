@@ -0,0 +1,63 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fixture is a ClusterReconciler on a fake client. Its Admin API mock
+// answers the endpoints this Redpanda version does not serve with
+// ErrUnsupported, like the real client.
+type fixture struct {
+	*ClusterReconciler
+	adminAPI *admin.MockAdminAPI
+	recorder *record.FakeRecorder
+}
+
+// newFixture creates a fixture whose client stores the objects
+func newFixture(t *testing.T, objs ...client.Object) *fixture {
+	t.Helper()
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	adminAPI := admin.NewMockAdminAPI()
+	adminAPI.Stubbed = true
+	// the fake recorder blocks once its buffer is full
+	recorder := record.NewFakeRecorder(100)
+	return &fixture{
+		ClusterReconciler: &ClusterReconciler{
+			Client:                fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build(),
+			Log:                   ctrl.Log,
+			Scheme:                scheme.Scheme,
+			AdminAPIClientFactory: adminAPI.Factory(),
+			Recorder:              recorder,
+		},
+		adminAPI: adminAPI,
+		recorder: recorder,
+	}
+}
+
+// get returns the stored Cluster of the default namespace
+func (f *fixture) get(t *testing.T, name string) redpandav1alpha1.Cluster {
+	t.Helper()
+	var cluster redpandav1alpha1.Cluster
+	require.NoError(t, f.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, &cluster))
+	return cluster
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonClusterMember = "ClusterMember"
+
+	// membershipReadinessRequeue is how often brokers waiting for their
+	// membership are checked, joining the cluster does not change any
+	// watched object
+	membershipReadinessRequeue = 10 * time.Second
+)
+
+// activeMembers returns the node IDs of the brokers that are active and
+// alive members of the cluster
+func activeMembers(brokers []admin.Broker) map[int]bool {
+	members := make(map[int]bool, len(brokers))
+	for _, b := range brokers {
+		if b.MembershipStatus == membershipActive && (b.IsAlive == nil || *b.IsAlive) {
+			members[b.NodeID] = true
+		}
+	}
+	return members
+}
+
+// reconcileMembershipReadiness sets the cluster member readiness gate of the
+// brokers whose containers are ready and that joined the cluster. The gate
+// is not cleared afterwards, like the other readiness conditions of a Pod it
+// starts over when the Pod is recreated. It returns true while ready brokers
+// wait for their membership, so they are checked again soon.
+func (r *ClusterReconciler) reconcileMembershipReadiness(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+) (waiting bool, err error) {
+	if !redpandaCluster.MembershipReadinessGated() {
+		return false, nil
+	}
+
+	var podList corev1.PodList
+	err = r.List(ctx, &podList, &client.ListOptions{
+		LabelSelector: labels.ForCluster(redpandaCluster).AsClientSelector(),
+		Namespace:     redpandaCluster.Namespace,
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to list the broker Pods: %w", err)
+	}
+	var candidates []*corev1.Pod
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !pod.DeletionTimestamp.IsZero() || podConditionTrue(pod, resources.ClusterMemberReadinessGate) {
+			continue
+		}
+		if _, ready := containersReadySince(pod); ready {
+			candidates = append(candidates, pod)
+		}
+	}
+	if len(candidates) == 0 {
+		return false, nil
+	}
+
+	adminAPI, err := r.AdminAPIClientFactory(ctx, r, redpandaCluster, fqdn, adminTLSProvider)
+	if err != nil {
+		return true, fmt.Errorf("unable to create admin API client: %w", err)
+	}
+	brokers, err := adminAPI.Brokers(ctx)
+	fallback := notServed("membership readiness", err)
+	if fallback != nil {
+		// this Redpanda version does not list the brokers, a ready broker
+		// is taken to be a member
+		brokers = ordinalBrokers(redpandaCluster)
+	} else if err != nil {
+		r.Log.Info("Unable to read the cluster membership", "error", err)
+		return true, nil
+	}
+	members := activeMembers(brokers)
+
+	for _, pod := range candidates {
+		ordinal, ok := resources.PodOrdinal(redpandaCluster, pod.Name)
+		if !ok || !members[int(ordinal)] {
+			waiting = true
+			continue
+		}
+		r.Log.Info("Broker joined the cluster", "pod", pod.Name, "node id", ordinal)
+		pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+			Type:               resources.ClusterMemberReadinessGate,
+			Status:             corev1.ConditionTrue,
+			Reason:             reasonClusterMember,
+			LastTransitionTime: metav1.Now(),
+		})
+		// Conflicts with the kubelet are retried on the next reconcile
+		if err := r.Status().Update(ctx, pod); err != nil {
+			return true, fmt.Errorf("unable to set the readiness gate of %s: %w", pod.Name, err)
+		}
+	}
+	return waiting, fallback
+}
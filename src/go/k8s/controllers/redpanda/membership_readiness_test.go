@@ -0,0 +1,58 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestReconcileMembershipReadiness(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec:       redpandav1alpha1.ClusterSpec{Replicas: pointer.Int32Ptr(3)},
+	}
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	cluster.Spec.ExternalConnectivity.MembershipReadiness = true
+	pod := func(name string, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels.ForCluster(cluster)},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+				{Type: corev1.ContainersReady, Status: ready},
+			}},
+		}
+	}
+	f := newFixture(t, pod("cluster-0", corev1.ConditionTrue), pod("cluster-1", corev1.ConditionTrue), pod("cluster-2", corev1.ConditionFalse))
+	released := func(name string) bool {
+		var actual corev1.Pod
+		require.NoError(t, f.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, &actual))
+		return podConditionTrue(&actual, resources.ClusterMemberReadinessGate)
+	}
+
+	// this version does not list the brokers, every ready broker is taken
+	// to be a member
+	waiting, err := f.reconcileMembershipReadiness(context.Background(), cluster, "cluster.local", nil)
+	assert.True(t, admin.IsUnsupported(err))
+	assert.False(t, waiting)
+	assert.True(t, released("cluster-0"))
+	assert.True(t, released("cluster-1"))
+	assert.False(t, released("cluster-2"))
+}
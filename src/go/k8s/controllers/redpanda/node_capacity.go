@@ -0,0 +1,197 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonCapacitySufficient = "CapacitySufficient"
+	reasonInsufficientNodes  = "InsufficientNodes"
+)
+
+// brokerRequests returns the CPU and memory requested by a broker. A
+// resource without a request is requested at its limit, as the API server
+// defaults it.
+func brokerRequests(cluster *redpandav1alpha1.Cluster) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if q, ok := cluster.Spec.Resources.Requests[name]; ok {
+			requests[name] = q
+		} else if q, ok := cluster.Spec.Resources.Limits[name]; ok {
+			requests[name] = q
+		}
+	}
+	return requests
+}
+
+// nodeFitsBroker returns true when a broker can be scheduled on the node as
+// far as it is known without the Pods running there: the node is
+// schedulable, its taints are tolerated and its allocatable resources cover
+// the requests
+func nodeFitsBroker(
+	node *corev1.Node,
+	tolerations []corev1.Toleration,
+	requests corev1.ResourceList,
+) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	for name, requested := range requests {
+		allocatable, ok := node.Status.Allocatable[name]
+		if !ok || allocatable.Cmp(requested) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeSelectorOperators maps the operators of node selector requirements to
+// the label selection operators
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// nodeMatchesAffinity returns true when the node matches one of the terms
+// required by the node affinity. The fields of the terms are matched
+// against the name of the node, the only field the scheduler supports.
+func nodeMatchesAffinity(node *corev1.Node, affinity *corev1.NodeAffinity) bool {
+	if affinity == nil || affinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	fields := labels.Set{"metadata.name": node.Name}
+	for _, term := range affinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		if requirementsMatch(term.MatchExpressions, labels.Set(node.Labels)) &&
+			requirementsMatch(term.MatchFields, fields) {
+			return true
+		}
+	}
+	return false
+}
+
+// requirementsMatch returns true when the set satisfies every requirement,
+// an invalid requirement matches nothing
+func requirementsMatch(requirements []corev1.NodeSelectorRequirement, set labels.Set) bool {
+	for _, req := range requirements {
+		op, ok := nodeSelectorOperators[req.Operator]
+		if !ok {
+			return false
+		}
+		r, err := labels.NewRequirement(req.Key, op, req.Values)
+		if err != nil || !r.Matches(set) {
+			return false
+		}
+	}
+	return true
+}
+
+// reportCapacity sets the InsufficientCapacity condition when fewer nodes
+// matching the node selector and the node affinity than brokers fit a
+// broker. The brokers are spread one per node, so each one needs a node of
+// its own. The check is best-effort, it does not account for the Pods
+// already running on the nodes, and it is skipped when the operator may not
+// list nodes.
+func (r *ClusterReconciler) reportCapacity(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if !redpandaCluster.FeatureGateEnabled(redpandav1alpha1.FeatureGateCapacityCheck) ||
+		redpandaCluster.Spec.Replicas == nil {
+		return nil
+	}
+
+	var nodes corev1.NodeList
+	err := r.List(ctx, &nodes, client.MatchingLabels(redpandaCluster.BrokerNodeSelector()))
+	if apierrors.IsForbidden(err) {
+		r.Log.Info("Nodes cannot be listed, the capacity is not checked", "error", err.Error())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	requests := brokerRequests(redpandaCluster)
+	matching, fitting := 0, 0
+	for i := range nodes.Items {
+		if !nodeMatchesAffinity(&nodes.Items[i], redpandaCluster.Spec.NodeAffinity) {
+			continue
+		}
+		matching++
+		if nodeFitsBroker(&nodes.Items[i], redpandaCluster.BrokerTolerations(), requests) {
+			fitting++
+		}
+	}
+
+	replicas := int(*redpandaCluster.Spec.Replicas)
+	status, reason := corev1.ConditionFalse, reasonCapacitySufficient
+	message := fmt.Sprintf("%d of %d nodes fit a broker requesting cpu %s and memory %s",
+		fitting, matching, requests.Cpu(), requests.Memory())
+	if fitting < replicas {
+		status, reason = corev1.ConditionTrue, reasonInsufficientNodes
+		message = fmt.Sprintf("%s, %d brokers are requested", message, replicas)
+		r.Log.Info("Too few nodes fit the brokers", "fitting", fitting, "replicas", replicas)
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if status == corev1.ConditionFalse && cluster.Status.GetCondition(redpandav1alpha1.ClusterInsufficientCapacity) == nil {
+			return nil
+		}
+		if !cluster.Status.SetCondition(redpandav1alpha1.ClusterInsufficientCapacity, status, reason, message) {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the capacity condition: %w", err)
+	}
+	return nil
+}
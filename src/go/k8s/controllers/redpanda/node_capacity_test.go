@@ -0,0 +1,122 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestNodeFitsBroker(t *testing.T) {
+	requests := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("4Gi"),
+	}
+	node := func(cpu, memory string) *corev1.Node {
+		return &corev1.Node{Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}}}
+	}
+
+	assert.True(t, nodeFitsBroker(node("4", "8Gi"), nil, requests))
+	assert.False(t, nodeFitsBroker(node("1", "8Gi"), nil, requests))
+	assert.False(t, nodeFitsBroker(node("4", "2Gi"), nil, requests))
+
+	cordoned := node("4", "8Gi")
+	cordoned.Spec.Unschedulable = true
+	assert.False(t, nodeFitsBroker(cordoned, nil, requests))
+
+	tainted := node("4", "8Gi")
+	tainted.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "redpanda", Effect: corev1.TaintEffectNoSchedule}}
+	assert.False(t, nodeFitsBroker(tainted, nil, requests))
+	assert.True(t, nodeFitsBroker(tainted, []corev1.Toleration{
+		{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "redpanda", Effect: corev1.TaintEffectNoSchedule},
+	}, requests))
+}
+
+func TestNodeMatchesAffinity(t *testing.T) {
+	large := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "large", Labels: map[string]string{"pool": "large"}}}
+	small := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "small", Labels: map[string]string{"pool": "small"}}}
+	required := func(terms ...corev1.NodeSelectorTerm) *corev1.NodeAffinity {
+		return &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: terms},
+		}
+	}
+
+	assert.True(t, nodeMatchesAffinity(small, nil))
+	assert.True(t, nodeMatchesAffinity(small, &corev1.NodeAffinity{}))
+
+	largePool := required(corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+		{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"large"}},
+	}})
+	assert.True(t, nodeMatchesAffinity(large, largePool))
+	assert.False(t, nodeMatchesAffinity(small, largePool))
+
+	// the terms are alternatives
+	named := required(largePool.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0],
+		corev1.NodeSelectorTerm{MatchFields: []corev1.NodeSelectorRequirement{
+			{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"small"}},
+		}})
+	assert.True(t, nodeMatchesAffinity(small, named))
+}
+
+func TestReportCapacity(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replicas:     pointer.Int32Ptr(2),
+			NodeSelector: map[string]string{"pool": "redpanda"},
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+			},
+		},
+	}
+	node := func(name, pool, cpu string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": pool}},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			}},
+		}
+	}
+	small := node("small", "redpanda", "1")
+	f := newFixture(t,
+		cluster, node("large", "redpanda", "4"), small, node("other", "default", "8"),
+	)
+	key := types.NamespacedName{Name: "cluster", Namespace: "default"}
+
+	require.NoError(t, f.reportCapacity(context.Background(), cluster))
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	condition := actual.Status.GetCondition(redpandav1alpha1.ClusterInsufficientCapacity)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, reasonInsufficientNodes, condition.Reason)
+
+	small.Status.Allocatable[corev1.ResourceCPU] = resource.MustParse("2")
+	require.NoError(t, f.Update(context.Background(), small))
+	require.NoError(t, f.reportCapacity(context.Background(), cluster))
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	assert.Equal(t, corev1.ConditionFalse, actual.Status.GetCondition(redpandav1alpha1.ClusterInsufficientCapacity).Status)
+}
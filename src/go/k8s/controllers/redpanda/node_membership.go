@@ -0,0 +1,183 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	membershipActive   = "active"
+	membershipDraining = "draining"
+
+	reasonNodeIDsConsistent = "NodeIDsConsistent"
+	reasonNodeIDConflict    = "NodeIDConflict"
+)
+
+// WithGhostBrokerDecommission makes the reconciler decommission brokers that
+// are members of the cluster but do not belong to any Pod ordinal and are not
+// alive. Decommissioning moves their partitions to the other brokers, so it
+// is opt-in.
+func (r *ClusterReconciler) WithGhostBrokerDecommission(
+	enabled bool,
+) *ClusterReconciler {
+	r.decommissionGhostBrokers = enabled
+	return r
+}
+
+// nodeMembership is the comparison of the brokers known to the cluster with
+// the node IDs derived from the StatefulSet ordinals
+type nodeMembership struct {
+	// ghosts are members of the cluster without a Pod ordinal
+	ghosts []admin.Broker
+	// inactive are expected node IDs that are not active members, e.g. a
+	// Pod that came back with the ID of a decommissioned broker
+	inactive []admin.Broker
+	// missing are expected node IDs that are not members of the cluster
+	missing []int
+}
+
+func (m *nodeMembership) consistent() bool {
+	return len(m.ghosts) == 0 && len(m.inactive) == 0 && len(m.missing) == 0
+}
+
+func (m *nodeMembership) String() string {
+	var problems []string
+	for _, b := range m.ghosts {
+		problems = append(problems, fmt.Sprintf("broker %d (%s) has no pod", b.NodeID, b.MembershipStatus))
+	}
+	for _, b := range m.inactive {
+		problems = append(problems, fmt.Sprintf("broker %d is %s", b.NodeID, b.MembershipStatus))
+	}
+	for _, id := range m.missing {
+		problems = append(problems, fmt.Sprintf("broker %d is not a member of the cluster", id))
+	}
+	return strings.Join(problems, "; ")
+}
+
+// ordinalBrokers returns an active broker for every Pod ordinal of the
+// Cluster, the membership the configurator sets up when the Admin API does
+// not list the brokers
+func ordinalBrokers(redpandaCluster *redpandav1alpha1.Cluster) []admin.Broker {
+	if redpandaCluster.Spec.Replicas == nil {
+		return nil
+	}
+	brokers := make([]admin.Broker, 0, *redpandaCluster.Spec.Replicas)
+	for id := 0; id < int(*redpandaCluster.Spec.Replicas); id++ {
+		brokers = append(brokers, admin.Broker{NodeID: id, MembershipStatus: membershipActive})
+	}
+	return brokers
+}
+
+// compareNodeMembership compares the cluster membership with the node IDs
+// 0..replicas-1 that the configurator derives from the Pod ordinals.
+// Decommissioned brokers without a Pod are ignored.
+func compareNodeMembership(brokers []admin.Broker, replicas int) *nodeMembership {
+	m := &nodeMembership{}
+	known := make(map[int]bool, len(brokers))
+	for _, b := range brokers {
+		known[b.NodeID] = true
+		expected := b.NodeID >= 0 && b.NodeID < replicas
+		switch {
+		case expected && b.MembershipStatus != membershipActive:
+			m.inactive = append(m.inactive, b)
+		case !expected && (b.MembershipStatus == membershipActive || b.MembershipStatus == membershipDraining):
+			m.ghosts = append(m.ghosts, b)
+		}
+	}
+	for id := 0; id < replicas; id++ {
+		if !known[id] {
+			m.missing = append(m.missing, id)
+		}
+	}
+	sort.Slice(m.ghosts, func(i, j int) bool { return m.ghosts[i].NodeID < m.ghosts[j].NodeID })
+	sort.Slice(m.inactive, func(i, j int) bool { return m.inactive[i].NodeID < m.inactive[j].NodeID })
+	return m
+}
+
+// reportNodeMembership records in the Cluster conditions whether the brokers
+// known to the cluster match the Pod ordinals and emits a warning event on
+// conflicts. When enabled, ghost brokers that are not alive are
+// decommissioned. A draining broker is left alone, so the recovery resumes
+// from where it is on every reconcile until Redpanda removes the broker.
+func (r *ClusterReconciler) reportNodeMembership(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	sts *appsv1.StatefulSet,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+) error {
+	// membership settles only after every broker started
+	if redpandaCluster.Spec.Replicas == nil || sts == nil ||
+		sts.Status.ReadyReplicas != *redpandaCluster.Spec.Replicas ||
+		redpandaCluster.Status.Upgrading {
+		return nil
+	}
+
+	adminAPI, err := r.AdminAPIClientFactory(ctx, r, redpandaCluster, fqdn, adminTLSProvider)
+	if err != nil {
+		return fmt.Errorf("unable to create admin API client: %w", err)
+	}
+	brokers, err := adminAPI.Brokers(ctx)
+	if err != nil {
+		r.Log.Info("Unable to compare the broker membership", "error", err)
+		return notServed("node membership", err)
+	}
+
+	membership := compareNodeMembership(brokers, int(*redpandaCluster.Spec.Replicas))
+	status, reason, message := corev1.ConditionTrue, reasonNodeIDsConsistent, "every broker matches a pod ordinal"
+	if !membership.consistent() {
+		status, reason, message = corev1.ConditionFalse, reasonNodeIDConflict, membership.String()
+		if r.Recorder != nil {
+			r.Recorder.Event(redpandaCluster, corev1.EventTypeWarning, reason, message)
+		}
+	}
+
+	if r.decommissionGhostBrokers {
+		for _, b := range membership.ghosts {
+			if b.MembershipStatus != membershipActive || b.IsAlive == nil || *b.IsAlive {
+				continue
+			}
+			r.Log.Info("Decommissioning ghost broker", "node id", b.NodeID)
+			if err := adminAPI.DecommissionBroker(ctx, b.NodeID); err != nil {
+				return fmt.Errorf("unable to decommission ghost broker %d: %w", b.NodeID, err)
+			}
+		}
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if !cluster.Status.SetCondition(redpandav1alpha1.ClusterNodeIDsConsistent, status, reason, message) {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update node ID condition: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestCompareNodeMembership(t *testing.T) {
+	membership := compareNodeMembership([]admin.Broker{
+		{NodeID: 0, MembershipStatus: "active"},
+		{NodeID: 1, MembershipStatus: "decommissioned"},
+		{NodeID: 3, MembershipStatus: "active"},
+		{NodeID: 4, MembershipStatus: "decommissioned"},
+	}, 3)
+
+	assert.False(t, membership.consistent())
+	require.Len(t, membership.ghosts, 1)
+	assert.Equal(t, 3, membership.ghosts[0].NodeID)
+	require.Len(t, membership.inactive, 1)
+	assert.Equal(t, 1, membership.inactive[0].NodeID)
+	assert.Equal(t, []int{2}, membership.missing)
+
+	assert.True(t, compareNodeMembership([]admin.Broker{
+		{NodeID: 0, MembershipStatus: "active"},
+		{NodeID: 5, MembershipStatus: "decommissioned"},
+	}, 1).consistent())
+}
+
+func TestReportNodeMembership(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec:       redpandav1alpha1.ClusterSpec{Replicas: pointer.Int32Ptr(1)},
+	}
+	f := newFixture(t, cluster)
+	f.WithGhostBrokerDecommission(true)
+
+	// this version does not list the brokers, the membership is not compared
+	sts := &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: 1}}
+	err := f.reportNodeMembership(context.Background(), cluster, sts, "cluster.local", nil)
+	assert.True(t, admin.IsUnsupported(err))
+	assert.Empty(t, f.adminAPI.Decommissioned)
+	actual := f.get(t, "cluster")
+	assert.Nil(t, actual.Status.GetCondition(redpandav1alpha1.ClusterNodeIDsConsistent))
+	assert.Empty(t, f.recorder.Events)
+}
@@ -0,0 +1,200 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonRacksInSync       = "RacksInSync"
+	reasonRackDrifted       = "RackDrifted"
+	reasonRackRestarting    = "RestartingBroker"
+	reasonRebalanceRequired = "RebalanceRequired"
+
+	// rackDriftRequeue is how often drifted brokers are checked, the labels
+	// of the nodes are not watched
+	rackDriftRequeue = 30 * time.Second
+)
+
+// brokerRack is the observed state of a running broker
+type brokerRack struct {
+	startedAt metav1.Time
+	// rack is the current rack label of the node of the broker
+	rack string
+}
+
+// brokerRacks returns the racks the brokers of the replicas run with,
+// ordered by node ID. A broker that started after its recorded start runs
+// with the current rack of its node, the configurator read the label right
+// before. Brokers that are not running keep their record.
+func brokerRacks(
+	previous []redpandav1alpha1.BrokerRack, running map[int]brokerRack, replicas int,
+) []redpandav1alpha1.BrokerRack {
+	recorded := make(map[int]redpandav1alpha1.BrokerRack, len(previous))
+	for _, b := range previous {
+		if b.NodeID < replicas {
+			recorded[b.NodeID] = b
+		}
+	}
+	for id, b := range running {
+		if r, ok := recorded[id]; ok && !r.StartedAt.Before(&b.startedAt) {
+			continue
+		}
+		recorded[id] = redpandav1alpha1.BrokerRack{NodeID: id, Rack: b.rack, StartedAt: b.startedAt}
+	}
+	var racks []redpandav1alpha1.BrokerRack
+	for _, b := range recorded {
+		racks = append(racks, b)
+	}
+	sort.Slice(racks, func(i, j int) bool { return racks[i].NodeID < racks[j].NodeID })
+	return racks
+}
+
+// reconcileRackDrift records the racks the brokers run with and reports the
+// brokers whose node has another rack label in the BrokerRacksDrifted
+// condition. The rack is rendered by the configurator when the Pod starts,
+// with the Restart policy the Pod of one drifted broker is recreated while
+// all brokers are ready. Partitions keep the replicas placed with the old
+// racks, so a rebalance is flagged once the racks are in sync again. It
+// returns true while brokers drift, so they are checked again soon.
+func (r *ClusterReconciler) reconcileRackDrift(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) (bool, error) {
+	if !redpandaCluster.RackAwarenessEnabled() {
+		return false, nil
+	}
+
+	var podList corev1.PodList
+	err := r.List(ctx, &podList, &client.ListOptions{
+		LabelSelector: labels.ForCluster(redpandaCluster).AsClientSelector(),
+		Namespace:     redpandaCluster.Namespace,
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to list the broker Pods: %w", err)
+	}
+	pods := make(map[int]*corev1.Pod, len(podList.Items))
+	running := make(map[int]brokerRack, len(podList.Items))
+	ready := true
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		ordinal, ok := resources.PodOrdinal(redpandaCluster, pod.Name)
+		if !ok || !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		ready = ready && podConditionTrue(pod, corev1.PodReady)
+		startedAt := redpandaStartedAt(pod, redpandaCluster.RedpandaContainerName())
+		if startedAt == nil || pod.Spec.NodeName == "" {
+			continue
+		}
+		var node corev1.Node
+		if err := r.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, &node); err != nil {
+			return false, fmt.Errorf("unable to retrieve node %s of %s: %w", pod.Spec.NodeName, pod.Name, err)
+		}
+		pods[int(ordinal)] = pod
+		running[int(ordinal)] = brokerRack{
+			startedAt: *startedAt,
+			rack:      node.Labels[redpandaCluster.RackNodeLabel()],
+		}
+	}
+	ready = ready && len(pods) == int(*redpandaCluster.Spec.Replicas)
+
+	racks := brokerRacks(redpandaCluster.Status.BrokerRacks, running, int(*redpandaCluster.Spec.Replicas))
+	var drifted []string
+	var restart *corev1.Pod
+	for _, b := range racks {
+		observed, ok := running[b.NodeID]
+		if !ok || observed.rack == b.Rack {
+			continue
+		}
+		pod := pods[b.NodeID]
+		drifted = append(drifted, fmt.Sprintf("%s (%q -> %q)", pod.Name, b.Rack, observed.rack))
+		if restart == nil {
+			restart = pod
+		}
+	}
+
+	status, reason := corev1.ConditionFalse, reasonRacksInSync
+	message := "the brokers run with the rack of their node"
+	previous := redpandaCluster.Status.GetCondition(redpandav1alpha1.ClusterBrokerRacksDrifted)
+	switch {
+	case len(drifted) > 0 && redpandaCluster.RackChangePolicy() == redpandav1alpha1.RackChangeRestart && ready:
+		r.Log.Info("Recreating the Pod of a broker with a stale rack", "pod", restart.Name)
+		if err := r.Delete(ctx, restart); err != nil && !apierrors.IsNotFound(err) {
+			return true, fmt.Errorf("unable to recreate the Pod %s with a stale rack: %w", restart.Name, err)
+		}
+		if r.Recorder != nil {
+			r.Recorder.Eventf(redpandaCluster, corev1.EventTypeNormal, reasonRackRestarting,
+				"recreating pod %s to apply the rack of its node", restart.Name)
+		}
+		status, reason = corev1.ConditionTrue, reasonRackRestarting
+		message = fmt.Sprintf("brokers with a stale rack: %s; recreating pod %s", strings.Join(drifted, ", "), restart.Name)
+	case len(drifted) > 0:
+		status, reason = corev1.ConditionTrue, reasonRackDrifted
+		message = fmt.Sprintf("brokers with a stale rack: %s", strings.Join(drifted, ", "))
+	case previous != nil && (previous.Status == corev1.ConditionTrue || previous.Reason == reasonRebalanceRequired):
+		reason = reasonRebalanceRequired
+		message = "the brokers run with the rack of their node, partitions placed before the rack change keep their replicas until they are moved"
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		changed := false
+		if len(drifted) > 0 || cluster.Status.GetCondition(redpandav1alpha1.ClusterBrokerRacksDrifted) != nil {
+			changed = cluster.Status.SetCondition(redpandav1alpha1.ClusterBrokerRacksDrifted, status, reason, message)
+		}
+		if !reflect.DeepEqual(racks, cluster.Status.BrokerRacks) {
+			cluster.Status.BrokerRacks = racks
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return true, fmt.Errorf("failed to update the broker racks: %w", err)
+	}
+	redpandaCluster.Status.BrokerRacks = racks
+	return len(drifted) > 0, nil
+}
+
+// redpandaStartedAt returns when the running Redpanda container of the Pod
+// started, nil when it is not running
+func redpandaStartedAt(pod *corev1.Pod, container string) *metav1.Time {
+	for _, s := range pod.Status.ContainerStatuses {
+		if s.Name == container && s.State.Running != nil {
+			return &s.State.Running.StartedAt
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestBrokerRacks(t *testing.T) {
+	start := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	restart := metav1.NewTime(start.Add(time.Minute))
+	previous := []redpandav1alpha1.BrokerRack{
+		{NodeID: 0, Rack: "a", StartedAt: start},
+		{NodeID: 1, Rack: "b", StartedAt: start},
+		{NodeID: 2, Rack: "c", StartedAt: start},
+	}
+	racks := brokerRacks(previous, map[int]brokerRack{
+		0: {startedAt: start, rack: "z"},
+		1: {startedAt: restart, rack: "z"},
+	}, 2)
+	assert.Equal(t, []redpandav1alpha1.BrokerRack{
+		// still running with the rack it started with
+		{NodeID: 0, Rack: "a", StartedAt: start},
+		// restarted with the rack of its node
+		{NodeID: 1, Rack: "z", StartedAt: restart},
+	}, racks)
+}
+
+func TestReconcileRackDrift(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replicas: pointer.Int32Ptr(2),
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				RackAwareness: &redpandav1alpha1.RackAwareness{
+					Enabled:      true,
+					OnRackChange: redpandav1alpha1.RackChangeRestart,
+				},
+			},
+		},
+	}
+	node := func(name, zone string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{redpandav1alpha1.DefaultRackNodeLabel: zone},
+		}}
+	}
+	startedAt := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	pod := func(name, nodeName string, startedAt metav1.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    labels.ForCluster(cluster),
+			},
+			Spec: corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name: cluster.RedpandaContainerName(),
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{StartedAt: startedAt},
+					},
+				}},
+			},
+		}
+	}
+	f := newFixture(t, cluster, node("node-a", "a"), node("node-b", "b"),
+		pod("cluster-0", "node-a", startedAt), pod("cluster-1", "node-b", startedAt))
+	key := types.NamespacedName{Name: "cluster", Namespace: "default"}
+
+	// the racks the brokers started with are recorded
+	drifted, err := f.reconcileRackDrift(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.False(t, drifted)
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	require.Len(t, actual.Status.BrokerRacks, 2)
+	assert.Equal(t, "b", actual.Status.BrokerRacks[1].Rack)
+	assert.Nil(t, actual.Status.GetCondition(redpandav1alpha1.ClusterBrokerRacksDrifted))
+
+	// the broker is recreated once its node changed zones
+	require.NoError(t, f.Update(context.Background(), node("node-b", "c")))
+	drifted, err = f.reconcileRackDrift(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.True(t, drifted)
+	err = f.Get(context.Background(), types.NamespacedName{Name: "cluster-1", Namespace: "default"}, &corev1.Pod{})
+	assert.True(t, apierrors.IsNotFound(err))
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	condition := actual.Status.GetCondition(redpandav1alpha1.ClusterBrokerRacksDrifted)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, reasonRackRestarting, condition.Reason)
+
+	// the restarted broker runs with the new rack, a rebalance is flagged
+	require.NoError(t, f.Create(context.Background(),
+		pod("cluster-1", "node-b", metav1.NewTime(startedAt.Add(time.Minute)))))
+	drifted, err = f.reconcileRackDrift(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.False(t, drifted)
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	assert.Equal(t, "c", actual.Status.BrokerRacks[1].Rack)
+	condition = actual.Status.GetCondition(redpandav1alpha1.ClusterBrokerRacksDrifted)
+	assert.Equal(t, corev1.ConditionFalse, condition.Status)
+	assert.Equal(t, reasonRebalanceRequired, condition.Reason)
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonStabilized         = "Stabilized"
+	reasonStabilizing        = "Stabilizing"
+	reasonContainersNotReady = "ContainersNotReady"
+	reasonBrokersStable      = "BrokersStable"
+)
+
+// stabilizationRemaining returns how long the containers of the broker still
+// have to stay ready for the window. It returns false when they are not
+// ready.
+func stabilizationRemaining(pod *corev1.Pod, window time.Duration, now time.Time) (time.Duration, bool) {
+	since, ready := containersReadySince(pod)
+	if !ready {
+		return 0, false
+	}
+	if remaining := window - now.Sub(since); remaining > 0 {
+		return remaining, true
+	}
+	return 0, true
+}
+
+// reconcileReadinessStabilization sets the stabilized readiness gate of the
+// brokers whose containers stayed ready for the stabilization window and
+// clears it for the ones whose containers are not ready or became ready
+// again within the window. Unlike the other gates it is cleared again, so a
+// broker that flaps stays out of the endpoints until it is stable. The
+// operator notices the probe failures through the ready replicas of the
+// StatefulSet. It returns how long until the window of the next broker
+// ends.
+func (r *ClusterReconciler) reconcileReadinessStabilization(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) (time.Duration, error) {
+	if redpandaCluster.Spec.ReadinessStabilization == nil {
+		return 0, r.reportStabilizingBrokers(ctx, redpandaCluster, nil)
+	}
+
+	var podList corev1.PodList
+	err := r.List(ctx, &podList, &client.ListOptions{
+		LabelSelector: labels.ForCluster(redpandaCluster).AsClientSelector(),
+		Namespace:     redpandaCluster.Namespace,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to list the broker Pods: %w", err)
+	}
+
+	window := redpandaCluster.Spec.ReadinessStabilization.Window.Duration
+	now := time.Now()
+	var wait time.Duration
+	var stabilizing []string
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		status, reason := corev1.ConditionFalse, reasonContainersNotReady
+		remaining, ready := stabilizationRemaining(pod, window, now)
+		switch {
+		case ready && remaining == 0:
+			status, reason = corev1.ConditionTrue, reasonStabilized
+		case ready:
+			reason = reasonStabilizing
+			stabilizing = append(stabilizing, pod.Name)
+			if wait == 0 || remaining < wait {
+				wait = remaining
+			}
+		}
+		if !setPodCondition(pod, resources.StabilizedReadinessGate, status, reason) {
+			continue
+		}
+		if status == corev1.ConditionTrue {
+			r.Log.Info("Broker stayed ready for the stabilization window", "pod", pod.Name)
+		}
+		// Conflicts with the kubelet are retried on the next reconcile
+		if err := r.Status().Update(ctx, pod); err != nil {
+			return wait, fmt.Errorf("unable to set the readiness gate of %s: %w", pod.Name, err)
+		}
+	}
+	sort.Strings(stabilizing)
+	return wait, r.reportStabilizingBrokers(ctx, redpandaCluster, stabilizing)
+}
+
+// reportStabilizingBrokers sets the BrokersStabilizing condition with the
+// brokers that wait for the stabilization window. The remaining time is not
+// reported, it would update the status on every reconcile.
+func (r *ClusterReconciler) reportStabilizingBrokers(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster, stabilizing []string,
+) error {
+	status, reason, message := corev1.ConditionFalse, reasonBrokersStable, "no broker waits for the stabilization window"
+	if len(stabilizing) > 0 {
+		status, reason = corev1.ConditionTrue, reasonStabilizing
+		message = fmt.Sprintf("brokers wait for their containers to stay ready for %s: %s",
+			redpandaCluster.Spec.ReadinessStabilization.Window.Duration, strings.Join(stabilizing, ", "))
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if len(stabilizing) == 0 && cluster.Status.GetCondition(redpandav1alpha1.ClusterBrokersStabilizing) == nil {
+			return nil
+		}
+		if !cluster.Status.SetCondition(redpandav1alpha1.ClusterBrokersStabilizing, status, reason, message) {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the stabilization condition: %w", err)
+	}
+	return nil
+}
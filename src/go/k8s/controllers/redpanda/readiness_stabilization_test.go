@@ -0,0 +1,87 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestReconcileReadinessStabilization(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replicas: pointer.Int32Ptr(3),
+			ReadinessStabilization: &redpandav1alpha1.ReadinessStabilization{
+				Window: metav1.Duration{Duration: time.Minute},
+			},
+		},
+	}
+	now := time.Now()
+	pod := func(name string, readyFor time.Duration, stabilized bool) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels.ForCluster(cluster)},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		if readyFor > 0 {
+			p.Status.Conditions = append(p.Status.Conditions, corev1.PodCondition{
+				Type:               corev1.ContainersReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(now.Add(-readyFor)),
+			})
+		}
+		if stabilized {
+			p.Status.Conditions = append(p.Status.Conditions, corev1.PodCondition{
+				Type:   resources.StabilizedReadinessGate,
+				Status: corev1.ConditionTrue,
+			})
+		}
+		return p
+	}
+	f := newFixture(t,
+		cluster,
+		pod("cluster-0", 2*time.Minute, false),
+		// the containers flapped after the gate was set
+		pod("cluster-1", 20*time.Second, true),
+		pod("cluster-2", 0, true),
+	)
+
+	wait, err := f.reconcileReadinessStabilization(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.True(t, wait > 30*time.Second && wait <= 40*time.Second)
+
+	gate := func(name string) bool {
+		var actual corev1.Pod
+		require.NoError(t, f.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, &actual))
+		return podConditionTrue(&actual, resources.StabilizedReadinessGate)
+	}
+	assert.True(t, gate("cluster-0"))
+	assert.False(t, gate("cluster-1"))
+	assert.False(t, gate("cluster-2"))
+
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, f.Get(context.Background(), types.NamespacedName{Name: "cluster", Namespace: "default"}, &actual))
+	condition := actual.Status.GetCondition(redpandav1alpha1.ClusterBrokersStabilizing)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.True(t, strings.HasSuffix(condition.Message, ": cluster-1"))
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"hash/fnv"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// resyncJitterFraction is the part of the resync period used to spread
+// periodic reconciles
+const resyncJitterFraction = 10
+
+// WithStartupJitter spreads the first reconcile of clusters that existed
+// before the operator started over the given window
+func (r *ClusterReconciler) WithStartupJitter(
+	jitter time.Duration,
+) *ClusterReconciler {
+	r.startupJitter = jitter
+	r.startTime = time.Now()
+	return r
+}
+
+// startupDelay returns how long the first reconcile of the cluster after the
+// operator start is postponed. Clusters created later are not delayed.
+func (r *ClusterReconciler) startupDelay(
+	cluster *redpandav1alpha1.Cluster,
+) time.Duration {
+	if r.startupJitter <= 0 || !cluster.CreationTimestamp.Time.Before(r.startTime) {
+		return 0
+	}
+	if _, seen := r.startedClusters.LoadOrStore(cluster.UID, true); seen {
+		return 0
+	}
+	return reconcileJitter(cluster.UID, r.startupJitter)
+}
+
+// WithResyncPeriod sets the period of the periodic reconcile of clusters
+// without Spec.ResyncPeriod. Zero disables it.
+func (r *ClusterReconciler) WithResyncPeriod(
+	period time.Duration,
+) *ClusterReconciler {
+	r.resyncPeriod = period
+	return r
+}
+
+// WithRequeuePeriod sets how long the operator waits before it checks again
+// on a resource that is not ready. Zero keeps the delay of each resource.
+func (r *ClusterReconciler) WithRequeuePeriod(
+	period time.Duration,
+) *ClusterReconciler {
+	r.requeuePeriod = period
+	return r
+}
+
+// nextResync returns the delay of the next periodic reconcile or zero when
+// neither the cluster nor the operator request one
+func (r *ClusterReconciler) nextResync(
+	cluster *redpandav1alpha1.Cluster,
+) time.Duration {
+	period := r.resyncPeriod
+	if cluster.Spec.ResyncPeriod != nil && cluster.Spec.ResyncPeriod.Duration > 0 {
+		period = cluster.Spec.ResyncPeriod.Duration
+	}
+	if period <= 0 {
+		return 0
+	}
+	return period + reconcileJitter(cluster.UID, period/resyncJitterFraction)
+}
+
+// nextRequeue returns the delay before a resource that is not ready is
+// checked again, the RequeuePeriodAnnotation of the cluster takes precedence
+// over the operator setting and the delay requested by the resource
+func (r *ClusterReconciler) nextRequeue(
+	cluster *redpandav1alpha1.Cluster, requested time.Duration,
+) time.Duration {
+	if period := cluster.RequeuePeriodOverride(); period > 0 {
+		return period
+	}
+	if r.requeuePeriod > 0 {
+		return r.requeuePeriod
+	}
+	return requested
+}
+
+// reconcileJitter returns a delay within the window derived from the cluster
+// UID, so the same cluster always lands in the same slot
+func reconcileJitter(uid types.UID, window time.Duration) time.Duration {
+	if window <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(uid))
+	return time.Duration(h.Sum64() % uint64(window))
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReconcileJitter(t *testing.T) {
+	window := time.Minute
+	jitter := reconcileJitter("ff2770aa-c919-43f0-8b4a-30cb7cfdaf79", window)
+	assert.Equal(t, jitter, reconcileJitter("ff2770aa-c919-43f0-8b4a-30cb7cfdaf79", window))
+	assert.True(t, jitter >= 0 && jitter < window)
+	assert.Zero(t, reconcileJitter("ff2770aa-c919-43f0-8b4a-30cb7cfdaf79", 0))
+}
+
+func TestStartupDelay(t *testing.T) {
+	r := (&ClusterReconciler{}).WithStartupJitter(time.Hour)
+
+	existing := &redpandav1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{
+		UID:               "ff2770aa-c919-43f0-8b4a-30cb7cfdaf79",
+		CreationTimestamp: metav1.NewTime(r.startTime.Add(-time.Hour)),
+	}}
+	assert.Equal(t, reconcileJitter(existing.UID, time.Hour), r.startupDelay(existing))
+	assert.Zero(t, r.startupDelay(existing), "only the first reconcile is delayed")
+
+	created := &redpandav1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{
+		UID:               "0d9510e4-6bd4-4c55-a0e4-0ab4a5bd8d1c",
+		CreationTimestamp: metav1.NewTime(r.startTime.Add(time.Minute)),
+	}}
+	assert.Zero(t, r.startupDelay(created), "clusters created after the start are not delayed")
+}
+
+func TestResyncPeriod(t *testing.T) {
+	r := &ClusterReconciler{}
+	cluster := &redpandav1alpha1.Cluster{}
+	assert.Zero(t, r.nextResync(cluster))
+
+	cluster.UID = "ff2770aa-c919-43f0-8b4a-30cb7cfdaf79"
+	r.WithResyncPeriod(time.Hour)
+	period := r.nextResync(cluster)
+	assert.True(t, period >= time.Hour && period < 66*time.Minute)
+
+	cluster.Spec.ResyncPeriod = &metav1.Duration{Duration: 10 * time.Minute}
+	period = r.nextResync(cluster)
+	assert.True(t, period >= 10*time.Minute && period < 11*time.Minute)
+}
+
+func TestRequeuePeriod(t *testing.T) {
+	r := &ClusterReconciler{}
+	cluster := &redpandav1alpha1.Cluster{}
+	assert.Equal(t, 10*time.Second, r.nextRequeue(cluster, 10*time.Second))
+
+	r.WithRequeuePeriod(time.Minute)
+	assert.Equal(t, time.Minute, r.nextRequeue(cluster, 10*time.Second))
+
+	cluster.Annotations = map[string]string{redpandav1alpha1.RequeuePeriodAnnotation: "2s"}
+	assert.Equal(t, 2*time.Second, r.nextRequeue(cluster, 10*time.Second))
+
+	cluster.Annotations[redpandav1alpha1.RequeuePeriodAnnotation] = "1ms"
+	assert.Equal(t, time.Minute, r.nextRequeue(cluster, 10*time.Second))
+}
@@ -0,0 +1,177 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	reasonReplicating              = "Replicating"
+	reasonReplicationSourceMissing = "SourceNotFound"
+	reasonReplicationLagUnknown    = "LagUnavailable"
+	reasonReplicationDisabled      = "ReplicationDisabled"
+
+	// replicationLagInterval is how often the lag of the replication is
+	// measured, every measurement connects to both clusters
+	replicationLagInterval = time.Minute
+)
+
+// replicationSource returns the internal Kafka API listener of the source
+// Cluster of the replication. It returns nil when the replication is
+// disabled or the source does not exist.
+func (r *ClusterReconciler) replicationSource(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) (*resources.KafkaEndpoint, error) {
+	if redpandaCluster.Spec.Replication == nil {
+		return nil, nil
+	}
+	var source redpandav1alpha1.Cluster
+	err := r.Get(ctx, redpandaCluster.ReplicationSourceKey(), &source)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the source Cluster of the replication: %w", err)
+	}
+	headlessSvc := resources.NewHeadlessService(r.Client, &source, r.Scheme, nil, r.Log)
+	pki := certmanager.NewPki(r.Client, &source, headlessSvc.HeadlessServiceFQDN(), r.Scheme, r.Log)
+	return &resources.KafkaEndpoint{
+		Cluster:    &source,
+		FQDN:       headlessSvc.HeadlessServiceFQDN(),
+		NodeCert:   pki.NodeCert(),
+		ClientCert: pki.AdminCert(),
+	}, nil
+}
+
+// clustersReplicatingFrom maps a Cluster event to the Clusters replicating
+// its topics, so MirrorMaker follows the listeners and credentials of the
+// source and starts once the source is created
+func (r *ClusterReconciler) clustersReplicatingFrom(
+	obj client.Object,
+) []reconcile.Request {
+	var clusters redpandav1alpha1.ClusterList
+	if err := r.List(context.Background(), &clusters); err != nil {
+		r.Log.Error(err, "Unable to list clusters for the replication source", "cluster", obj.GetName())
+		return nil
+	}
+	source := types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	var requests []reconcile.Request
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		if cluster.Spec.Replication == nil || cluster.ReplicationSourceKey() != source {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		}})
+	}
+	return requests
+}
+
+// reportReplication records the lag of the replicated topics and sets the
+// Replicating condition. The lag is measured at most every
+// replicationLagInterval, the returned duration is how long until the
+// next measurement.
+func (r *ClusterReconciler) reportReplication(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	replication *resources.ReplicationResource,
+) (time.Duration, error) {
+	if redpandaCluster.Spec.Replication == nil {
+		return 0, r.updateReplicationStatus(ctx, redpandaCluster, nil,
+			corev1.ConditionFalse, reasonReplicationDisabled, "the replication is disabled")
+	}
+
+	recorded := redpandaCluster.Status.Replication
+	condition := redpandaCluster.Status.GetCondition(redpandav1alpha1.ClusterReplicating)
+	if recorded != nil && condition != nil && condition.Status == corev1.ConditionTrue {
+		if wait := replicationLagInterval - time.Since(recorded.ObservedAt.Time); wait > 0 {
+			return wait, nil
+		}
+	}
+
+	if !replication.SourceFound() {
+		message := fmt.Sprintf("the source Cluster %s does not exist", redpandaCluster.ReplicationSourceKey())
+		return replicationLagInterval, r.updateReplicationStatus(ctx, redpandaCluster, recorded,
+			corev1.ConditionFalse, reasonReplicationSourceMissing, message)
+	}
+
+	topics, err := replication.Lag(ctx)
+	if err != nil {
+		r.Log.Info("Unable to measure the replication lag", "error", err)
+		return replicationLagInterval, r.updateReplicationStatus(ctx, redpandaCluster, recorded,
+			corev1.ConditionFalse, reasonReplicationLagUnknown, fmt.Sprintf("unable to measure the lag: %v", err))
+	}
+	status := &redpandav1alpha1.ReplicationStatus{
+		Topics:     topics,
+		ObservedAt: metav1.Now(),
+	}
+	for _, t := range topics {
+		status.Lag += t.Lag
+	}
+	message := fmt.Sprintf("%d topics are replicated from %s, %d records behind",
+		len(topics), redpandaCluster.ReplicationSourceKey(), status.Lag)
+	return replicationLagInterval, r.updateReplicationStatus(ctx, redpandaCluster, status,
+		corev1.ConditionTrue, reasonReplicating, message)
+}
+
+// updateReplicationStatus stores the lag and the Replicating condition. A
+// cluster that never replicated does not get the condition.
+func (r *ClusterReconciler) updateReplicationStatus(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	recorded *redpandav1alpha1.ReplicationStatus,
+	status corev1.ConditionStatus,
+	reason, message string,
+) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		changed := false
+		if cluster.Spec.Replication != nil || cluster.Status.GetCondition(redpandav1alpha1.ClusterReplicating) != nil {
+			changed = cluster.Status.SetCondition(redpandav1alpha1.ClusterReplicating, status, reason, message)
+		}
+		if !apiequality.Semantic.DeepEqual(recorded, cluster.Status.Replication) {
+			cluster.Status.Replication = recorded
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the replication status: %w", err)
+	}
+	redpandaCluster.Status.Replication = recorded
+	return nil
+}
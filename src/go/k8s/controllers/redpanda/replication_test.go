@@ -0,0 +1,79 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestClustersReplicatingFrom(t *testing.T) {
+	replicating := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "replica", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replication: &redpandav1alpha1.Replication{
+				Source: redpandav1alpha1.ReplicationSource{Name: "source", Namespace: "upstream"},
+			},
+		},
+	}
+	other := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "upstream"},
+	}
+
+	f := newFixture(t, replicating, other)
+
+	cluster := func(namespace, name string) *redpandav1alpha1.Cluster {
+		return &redpandav1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "replica", Namespace: "default"}}},
+		f.clustersReplicatingFrom(cluster("upstream", "source")))
+	assert.Empty(t, f.clustersReplicatingFrom(cluster("default", "source")))
+	assert.Empty(t, f.clustersReplicatingFrom(cluster("upstream", "other")))
+}
+
+func TestReportReplicationSourceNotFound(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "replica", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replication: &redpandav1alpha1.Replication{
+				Source: redpandav1alpha1.ReplicationSource{Name: "source"},
+			},
+		},
+	}
+	f := newFixture(t, cluster)
+
+	source, err := f.replicationSource(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.Nil(t, source)
+
+	replication := resources.NewReplication(f.Client, cluster, scheme.Scheme,
+		resources.KafkaEndpoint{Cluster: cluster}, source, ctrl.Log)
+	wait, err := f.reportReplication(context.Background(), cluster, replication)
+	require.NoError(t, err)
+	assert.Equal(t, replicationLagInterval, wait)
+
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, f.Get(context.Background(), types.NamespacedName{Name: "replica", Namespace: "default"}, &actual))
+	condition := actual.Status.GetCondition(redpandav1alpha1.ClusterReplicating)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionFalse, condition.Status)
+	assert.Equal(t, reasonReplicationSourceMissing, condition.Reason)
+}
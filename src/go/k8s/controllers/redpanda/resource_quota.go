@@ -0,0 +1,298 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonQuotaSufficient    = "QuotaSufficient"
+	reasonQuotaExceeded      = "QuotaExceeded"
+	reasonLimitRangeViolated = "LimitRangeViolated"
+)
+
+// brokerContainerResources returns the CPU and memory requests and limits of
+// the Redpanda container once the LimitRanges of the namespace defaulted
+// them. Like the API server, a request that is not set defaults to the
+// limit.
+func brokerContainerResources(
+	cluster *redpandav1alpha1.Cluster, limitRanges []corev1.LimitRange,
+) (requests, limits corev1.ResourceList) {
+	requests, limits = corev1.ResourceList{}, corev1.ResourceList{}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if q, ok := cluster.Spec.Resources.Limits[name]; ok {
+			limits[name] = q
+		}
+		if q, ok := cluster.Spec.Resources.Requests[name]; ok {
+			requests[name] = q
+		}
+		for i := range limitRanges {
+			for _, item := range limitRanges[i].Spec.Limits {
+				if item.Type != corev1.LimitTypeContainer {
+					continue
+				}
+				if _, ok := limits[name]; !ok {
+					if q, ok := item.Default[name]; ok {
+						limits[name] = q
+					}
+				}
+				if _, ok := requests[name]; !ok {
+					if q, ok := item.DefaultRequest[name]; ok {
+						requests[name] = q
+					}
+				}
+			}
+		}
+		if _, ok := requests[name]; !ok {
+			if q, ok := limits[name]; ok {
+				requests[name] = q
+			}
+		}
+	}
+	return requests, limits
+}
+
+// limitRangeViolations returns the constraints of the LimitRanges the
+// Redpanda container or the data directory claim of a broker violate
+func limitRangeViolations(
+	cluster *redpandav1alpha1.Cluster,
+	limitRanges []corev1.LimitRange,
+	requests, limits corev1.ResourceList,
+) []string {
+	var violations []string
+	for i := range limitRanges {
+		name := limitRanges[i].Name
+		for _, item := range limitRanges[i].Spec.Limits {
+			switch item.Type {
+			case corev1.LimitTypeContainer:
+				for res, max := range item.Max {
+					if q, ok := limits[res]; !ok {
+						violations = append(violations, fmt.Sprintf("LimitRange %s requires a %s limit", name, res))
+					} else if q.Cmp(max) > 0 {
+						violations = append(violations,
+							fmt.Sprintf("the %s limit %s exceeds the maximum %s of LimitRange %s", res, q.String(), max.String(), name))
+					}
+				}
+				for res, min := range item.Min {
+					if q, ok := requests[res]; ok && q.Cmp(min) < 0 {
+						violations = append(violations,
+							fmt.Sprintf("the %s request %s is below the minimum %s of LimitRange %s", res, q.String(), min.String(), name))
+					}
+				}
+			case corev1.LimitTypePersistentVolumeClaim:
+				if cluster.Spec.Storage.IsEmptyDir() {
+					continue
+				}
+				capacity := cluster.Spec.Storage.Capacity
+				if max, ok := item.Max[corev1.ResourceStorage]; ok && capacity.Cmp(max) > 0 {
+					violations = append(violations,
+						fmt.Sprintf("the storage capacity %s exceeds the maximum %s of LimitRange %s", capacity.String(), max.String(), name))
+				}
+				if min, ok := item.Min[corev1.ResourceStorage]; ok && capacity.Cmp(min) < 0 {
+					violations = append(violations,
+						fmt.Sprintf("the storage capacity %s is below the minimum %s of LimitRange %s", capacity.String(), min.String(), name))
+				}
+			}
+		}
+	}
+	sort.Strings(violations)
+	return violations
+}
+
+// brokerQuotaUsage returns the quota resources a new broker and its data
+// directory claim consume
+func brokerQuotaUsage(
+	cluster *redpandav1alpha1.Cluster, requests, limits corev1.ResourceList,
+) (pod, claim corev1.ResourceList) {
+	pod = corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")}
+	for name, q := range requests {
+		pod[name] = q
+		pod[corev1.ResourceName("requests."+string(name))] = q
+	}
+	for name, q := range limits {
+		pod[corev1.ResourceName("limits."+string(name))] = q
+	}
+	claim = corev1.ResourceList{}
+	if !cluster.Spec.Storage.IsEmptyDir() {
+		claim[corev1.ResourcePersistentVolumeClaims] = resource.MustParse("1")
+		claim[corev1.ResourceRequestsStorage] = cluster.Spec.Storage.Capacity
+	}
+	return pod, claim
+}
+
+// computeQuotaResources are the quota resources every Pod has to set once
+// a quota tracks them
+var computeQuotaResources = map[corev1.ResourceName]bool{
+	corev1.ResourceCPU:            true,
+	corev1.ResourceMemory:         true,
+	corev1.ResourceRequestsCPU:    true,
+	corev1.ResourceRequestsMemory: true,
+	corev1.ResourceLimitsCPU:      true,
+	corev1.ResourceLimitsMemory:   true,
+}
+
+// quotaViolations returns the resources of the ResourceQuotas the missing
+// brokers and claims would exceed. Quotas with scopes are skipped, they
+// depend on the priority class and the lifetime of the Pods.
+func quotaViolations(
+	quotas []corev1.ResourceQuota, missingPods, missingClaims int, pod, claim corev1.ResourceList,
+) []string {
+	var violations []string
+	for i := range quotas {
+		quota := &quotas[i]
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		hard := quota.Status.Hard
+		if len(hard) == 0 {
+			hard = quota.Spec.Hard
+		}
+		for name, max := range hard {
+			perBroker, missing := pod[name], missingPods
+			if q, ok := claim[name]; ok {
+				perBroker, missing = q, missingClaims
+			} else if _, ok := pod[name]; !ok {
+				// admission rejects Pods without the requests and limits
+				// a quota tracks
+				if computeQuotaResources[name] && missingPods > 0 {
+					violations = append(violations,
+						fmt.Sprintf("ResourceQuota %s requires the brokers to set %s", quota.Name, name))
+				}
+				continue
+			}
+			if missing == 0 {
+				continue
+			}
+			needed := quota.Status.Used[name].DeepCopy()
+			for j := 0; j < missing; j++ {
+				needed.Add(perBroker)
+			}
+			if needed.Cmp(max) > 0 {
+				used := quota.Status.Used[name]
+				violations = append(violations,
+					fmt.Sprintf("ResourceQuota %s: %s would reach %s with %d more brokers, %s of %s are used",
+						quota.Name, name, needed.String(), missing, used.String(), max.String()))
+			}
+		}
+	}
+	sort.Strings(violations)
+	return violations
+}
+
+// reportQuota sets the QuotaExceeded condition when the ResourceQuotas or
+// LimitRanges of the namespace would reject the brokers or their claims, so
+// the admission errors the StatefulSet controller only reports in events
+// surface on the Cluster before the StatefulSet creates the Pods. The check
+// only accounts for the Redpanda container and is skipped when the operator
+// may not list quotas or limit ranges.
+func (r *ClusterReconciler) reportQuota(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if !redpandaCluster.FeatureGateEnabled(redpandav1alpha1.FeatureGateQuotaCheck) ||
+		redpandaCluster.Spec.Replicas == nil {
+		return nil
+	}
+
+	var quotas corev1.ResourceQuotaList
+	err := r.List(ctx, &quotas, client.InNamespace(redpandaCluster.Namespace))
+	var limitRanges corev1.LimitRangeList
+	if err == nil {
+		err = r.List(ctx, &limitRanges, client.InNamespace(redpandaCluster.Namespace))
+	}
+	if apierrors.IsForbidden(err) {
+		r.Log.Info("Quotas cannot be listed, they are not checked", "error", err.Error())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to list the quotas: %w", err)
+	}
+	return r.reportQuotaViolations(ctx, redpandaCluster, quotas.Items, limitRanges.Items)
+}
+
+// reportQuotaViolations checks the brokers and claims that do not exist yet
+// against the quotas and limit ranges
+func (r *ClusterReconciler) reportQuotaViolations(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	quotas []corev1.ResourceQuota,
+	limitRanges []corev1.LimitRange,
+) error {
+	opts := &client.ListOptions{
+		LabelSelector: labels.ForCluster(redpandaCluster).AsClientSelector(),
+		Namespace:     redpandaCluster.Namespace,
+	}
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, opts); err != nil {
+		return fmt.Errorf("unable to list the broker Pods: %w", err)
+	}
+	var claims corev1.PersistentVolumeClaimList
+	if err := r.List(ctx, &claims, opts); err != nil {
+		return fmt.Errorf("unable to list the broker claims: %w", err)
+	}
+	replicas := int(*redpandaCluster.Spec.Replicas)
+	missingPods, missingClaims := replicas-len(pods.Items), replicas-len(claims.Items)
+	if missingPods < 0 {
+		missingPods = 0
+	}
+	if missingClaims < 0 {
+		missingClaims = 0
+	}
+
+	requests, limits := brokerContainerResources(redpandaCluster, limitRanges)
+	status, reason, message := corev1.ConditionFalse, reasonQuotaSufficient, "the quotas of the namespace admit the brokers"
+	if violations := limitRangeViolations(redpandaCluster, limitRanges, requests, limits); len(violations) > 0 {
+		status, reason = corev1.ConditionTrue, reasonLimitRangeViolated
+		message = "the brokers are rejected, " + strings.Join(violations, "; ")
+	} else {
+		pod, claim := brokerQuotaUsage(redpandaCluster, requests, limits)
+		if violations := quotaViolations(quotas, missingPods, missingClaims, pod, claim); len(violations) > 0 {
+			status, reason = corev1.ConditionTrue, reasonQuotaExceeded
+			message = "the brokers are rejected, " + strings.Join(violations, "; ")
+		}
+	}
+	if status == corev1.ConditionTrue {
+		r.Log.Info("The quotas of the namespace reject the brokers", "reason", reason, "message", message)
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if status == corev1.ConditionFalse && cluster.Status.GetCondition(redpandav1alpha1.ClusterQuotaExceeded) == nil {
+			return nil
+		}
+		if !cluster.Status.SetCondition(redpandav1alpha1.ClusterQuotaExceeded, status, reason, message) {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the quota condition: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestReportQuota(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replicas: pointer.Int32Ptr(3),
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+			},
+			Storage: redpandav1alpha1.StorageSpec{Capacity: resource.MustParse("100Gi")},
+		},
+	}
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "default"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{
+				corev1.ResourceRequestsCPU: resource.MustParse("8"),
+				corev1.ResourceLimitsCPU:   resource.MustParse("8"),
+			},
+			Used: corev1.ResourceList{
+				corev1.ResourceRequestsCPU: resource.MustParse("3"),
+			},
+		},
+	}
+	f := newFixture(t, cluster, quota)
+	key := types.NamespacedName{Name: "cluster", Namespace: "default"}
+	condition := func() *redpandav1alpha1.ClusterCondition {
+		var actual redpandav1alpha1.Cluster
+		require.NoError(t, f.Get(context.Background(), key, &actual))
+		return actual.Status.GetCondition(redpandav1alpha1.ClusterQuotaExceeded)
+	}
+
+	require.NoError(t, f.reportQuota(context.Background(), cluster))
+	require.NotNil(t, condition())
+	assert.Equal(t, corev1.ConditionTrue, condition().Status)
+	assert.Equal(t, reasonQuotaExceeded, condition().Reason)
+	assert.True(t, strings.Contains(condition().Message, "requests.cpu would reach 9 with 3 more brokers"))
+
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "containers", Namespace: "default"},
+		Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+			Type: corev1.LimitTypePersistentVolumeClaim,
+			Max:  corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("50Gi")},
+		}}},
+	}
+	require.NoError(t, f.Create(context.Background(), limitRange))
+	require.NoError(t, f.reportQuota(context.Background(), cluster))
+	assert.Equal(t, reasonLimitRangeViolated, condition().Reason)
+	assert.True(t, strings.Contains(condition().Message, "exceeds the maximum 50Gi of LimitRange containers"))
+
+	require.NoError(t, f.Delete(context.Background(), limitRange))
+	quota.Status.Used = nil
+	require.NoError(t, f.Update(context.Background(), quota))
+	require.NoError(t, f.reportQuota(context.Background(), cluster))
+	assert.Equal(t, corev1.ConditionFalse, condition().Status)
+	assert.Equal(t, reasonQuotaSufficient, condition().Reason)
+}
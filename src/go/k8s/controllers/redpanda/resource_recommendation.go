@@ -0,0 +1,160 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// resourceSampleInterval is how often the brokers are sampled for the
+	// resource recommendation
+	resourceSampleInterval = 5 * time.Minute
+
+	mebibyte = 1 << 20
+)
+
+// nextRecommendation adds the usage of the brokers to the peaks of the
+// current window. Once the window is complete the peaks plus the headroom
+// become the recommendation and a new window starts.
+func nextRecommendation(
+	previous *redpandav1alpha1.ResourceRecommendationStatus,
+	usage []admin.ResourceUsage,
+	now time.Time,
+	window time.Duration,
+	headroomPercent int32,
+) *redpandav1alpha1.ResourceRecommendationStatus {
+	next := &redpandav1alpha1.ResourceRecommendationStatus{WindowStart: metav1.NewTime(now)}
+	if previous != nil {
+		next = previous.DeepCopy()
+	}
+	peakCPU := next.PeakCPU.MilliValue()
+	peakMemory := next.PeakMemory.Value()
+	for _, u := range usage {
+		if cpu := int64(math.Ceil(u.CPUCores * 1000)); cpu > peakCPU {
+			peakCPU = cpu
+		}
+		if u.MemoryBytes > peakMemory {
+			peakMemory = u.MemoryBytes
+		}
+	}
+	sampled := metav1.NewTime(now)
+	next.LastSampleTime = &sampled
+	next.Samples++
+	next.PeakCPU = *resource.NewMilliQuantity(peakCPU, resource.DecimalSI)
+	next.PeakMemory = *resource.NewQuantity(peakMemory, resource.BinarySI)
+	if now.Sub(next.WindowStart.Time) < window {
+		return next
+	}
+
+	factor := float64(100+headroomPercent) / 100
+	// Redpanda runs one shard per core
+	cores := int64(math.Ceil(float64(peakCPU) * factor / 1000))
+	if cores < 1 {
+		cores = 1
+	}
+	memory := int64(math.Ceil(float64(peakMemory)*factor/mebibyte)) * mebibyte
+	next.CPU = resource.NewQuantity(cores, resource.DecimalSI)
+	next.Memory = resource.NewQuantity(memory, resource.BinarySI)
+	next.RecommendedAt = &sampled
+	next.WindowStart = sampled
+	next.Samples = 0
+	next.PeakCPU = *resource.NewQuantity(0, resource.DecimalSI)
+	next.PeakMemory = *resource.NewQuantity(0, resource.BinarySI)
+	return next
+}
+
+// reconcileResourceRecommendation samples the CPU and memory usage of the
+// brokers every sample interval and records the recommended resources in the
+// status. The resources of the brokers are not changed. The returned duration
+// is how long it takes until the next sample is due.
+func (r *ClusterReconciler) reconcileResourceRecommendation(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+) (time.Duration, error) {
+	if redpandaCluster.Spec.ResourceRecommendation == nil {
+		return 0, nil
+	}
+	previous := redpandaCluster.Status.ResourceRecommendation
+	if previous != nil && previous.LastSampleTime != nil {
+		if wait := time.Until(previous.LastSampleTime.Add(resourceSampleInterval)); wait > 0 {
+			return wait, nil
+		}
+	}
+
+	adminAPI, err := r.AdminAPIClientFactory(ctx, r, redpandaCluster, fqdn, adminTLSProvider)
+	if err != nil {
+		return resourceSampleInterval, fmt.Errorf("unable to create admin API client: %w", err)
+	}
+	brokers, err := adminAPI.Brokers(ctx)
+	fallback := notServed("resource recommendation", err)
+	if fallback != nil {
+		// this Redpanda version does not list the brokers, their node IDs
+		// are the Pod ordinals
+		brokers = ordinalBrokers(redpandaCluster)
+	} else if err != nil {
+		r.Log.Info("Unable to list the brokers to sample their resource usage", "error", err)
+		return resourceSampleInterval, nil
+	}
+	var usage []admin.ResourceUsage
+	for _, b := range brokers {
+		if b.MembershipStatus != membershipActive || (b.IsAlive != nil && !*b.IsAlive) {
+			continue
+		}
+		u, err := adminAPI.ResourceUsage(ctx, b.NodeID)
+		if err != nil {
+			r.Log.Info("Unable to read the resource usage of a broker", "node id", b.NodeID, "error", err)
+			continue
+		}
+		usage = append(usage, u)
+	}
+	if len(usage) == 0 {
+		return resourceSampleInterval, fallback
+	}
+
+	recorded := nextRecommendation(previous, usage, time.Now(),
+		redpandaCluster.RecommendationWindow(), redpandaCluster.RecommendationHeadroomPercent())
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if apiequality.Semantic.DeepEqual(recorded, cluster.Status.ResourceRecommendation) {
+			return nil
+		}
+		cluster.Status.ResourceRecommendation = recorded
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return resourceSampleInterval, fmt.Errorf("failed to update the resource recommendation: %w", err)
+	}
+	if recorded.RecommendedAt != nil && (previous == nil || previous.RecommendedAt == nil || !recorded.RecommendedAt.Equal(previous.RecommendedAt)) {
+		r.Log.Info("Recommending broker resources", "cpu", recorded.CPU.String(), "memory", recorded.Memory.String())
+	}
+	redpandaCluster.Status.ResourceRecommendation = recorded
+	return resourceSampleInterval, fallback
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestNextRecommendation(t *testing.T) {
+	start := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	status := nextRecommendation(nil, []admin.ResourceUsage{
+		{CPUCores: 1.2, MemoryBytes: 3 << 30},
+		{CPUCores: 0.4, MemoryBytes: 4 << 30},
+	}, start, time.Hour, 30)
+	assert.Nil(t, status.CPU)
+	assert.Equal(t, int32(1), status.Samples)
+	assert.Equal(t, int64(1200), status.PeakCPU.MilliValue())
+	assert.Equal(t, int64(4<<30), status.PeakMemory.Value())
+
+	// lower usage keeps the peaks
+	status = nextRecommendation(status, []admin.ResourceUsage{{CPUCores: 0.1, MemoryBytes: 1 << 30}},
+		start.Add(30*time.Minute), time.Hour, 30)
+	assert.Equal(t, int32(2), status.Samples)
+	assert.Equal(t, int64(1200), status.PeakCPU.MilliValue())
+
+	// the complete window recommends the peaks plus the headroom
+	status = nextRecommendation(status, []admin.ResourceUsage{{CPUCores: 1.6, MemoryBytes: 2 << 30}},
+		start.Add(time.Hour), time.Hour, 30)
+	require.NotNil(t, status.CPU)
+	assert.True(t, resource.MustParse("3").Equal(*status.CPU))
+	assert.Equal(t, int64(5325)*mebibyte, status.Memory.Value())
+	assert.Equal(t, start.Add(time.Hour), status.RecommendedAt.Time)
+	assert.Equal(t, start.Add(time.Hour), status.WindowStart.Time)
+	assert.Equal(t, int32(0), status.Samples)
+	assert.True(t, status.PeakCPU.IsZero())
+}
+
+func TestReconcileResourceRecommendation(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replicas:               pointer.Int32Ptr(2),
+			ResourceRecommendation: &redpandav1alpha1.ResourceRecommendation{},
+		},
+	}
+	f := newFixture(t, cluster)
+	// this version does not list the brokers, the usage is read from the
+	// Pod ordinals and the usage of broker 1 can not be read
+	f.adminAPI.Usage = map[int]admin.ResourceUsage{0: {CPUCores: 0.5, MemoryBytes: 1 << 30}}
+
+	wait, err := f.reconcileResourceRecommendation(context.Background(), cluster, "cluster.local", nil)
+	assert.True(t, admin.IsUnsupported(err))
+	assert.Equal(t, resourceSampleInterval, wait)
+	actual := f.get(t, "cluster")
+	require.NotNil(t, actual.Status.ResourceRecommendation)
+	assert.Equal(t, int32(1), actual.Status.ResourceRecommendation.Samples)
+	assert.Equal(t, int64(500), actual.Status.ResourceRecommendation.PeakCPU.MilliValue())
+	assert.Nil(t, actual.Status.ResourceRecommendation.CPU)
+
+	// the next sample waits for the interval
+	wait, err = f.reconcileResourceRecommendation(context.Background(), &actual, "cluster.local", nil)
+	require.NoError(t, err)
+	assert.True(t, wait > 0 && wait <= resourceSampleInterval)
+	actual = f.get(t, "cluster")
+	assert.Equal(t, int32(1), actual.Status.ResourceRecommendation.Samples)
+}
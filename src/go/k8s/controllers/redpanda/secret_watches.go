@@ -0,0 +1,80 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// referencedSecrets returns the Secrets the reconciliation of the cluster
+// reads that are not owned by it: the user provided node certificate and
+// cloud storage key, and the certificates issued by cert-manager. Owned
+// Secrets and ConfigMaps are watched through their owner reference.
+func (r *ClusterReconciler) referencedSecrets(
+	cluster *redpandav1alpha1.Cluster,
+) []types.NamespacedName {
+	var secrets []types.NamespacedName
+	tls := cluster.Spec.Configuration.TLS
+	pki := certmanager.NewPki(r.Client, cluster, "", r.Scheme, r.Log)
+	if tls.KafkaAPI.Enabled {
+		secrets = append(secrets, pki.NodeCert())
+		if ref := tls.KafkaAPI.NodeSecretRef; ref != nil && ref.Namespace != cluster.Namespace {
+			secrets = append(secrets, types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace})
+		}
+		if tls.KafkaAPI.RequireClientAuth {
+			secrets = append(secrets, pki.OperatorClientCert())
+		}
+	}
+	if tls.AdminAPI.Enabled {
+		secrets = append(secrets, pki.AdminAPINodeCert())
+		if tls.AdminAPI.RequireClientAuth {
+			secrets = append(secrets, pki.AdminAPIClientCert())
+		}
+	}
+	if cluster.Spec.CloudStorage.Enabled {
+		ref := cluster.Spec.CloudStorage.SecretKeyRef
+		secrets = append(secrets, types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace})
+	}
+	return secrets
+}
+
+// clustersReferencingSecret maps a Secret event to the Clusters that read
+// the Secret, so a rotated certificate or key is picked up without waiting
+// for the resync
+func (r *ClusterReconciler) clustersReferencingSecret(
+	obj client.Object,
+) []reconcile.Request {
+	var clusters redpandav1alpha1.ClusterList
+	if err := r.List(context.Background(), &clusters); err != nil {
+		r.Log.Error(err, "Unable to list clusters for secret", "secret", obj.GetName())
+		return nil
+	}
+	secret := types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	var requests []reconcile.Request
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		for _, ref := range r.referencedSecrets(cluster) {
+			if ref == secret {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+					Name:      cluster.Name,
+					Namespace: cluster.Namespace,
+				}})
+				break
+			}
+		}
+	}
+	return requests
+}
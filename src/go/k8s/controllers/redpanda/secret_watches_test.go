@@ -0,0 +1,48 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestClustersReferencingSecret(t *testing.T) {
+	archived := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "archived", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			CloudStorage: redpandav1alpha1.CloudStorageConfig{
+				Enabled:      true,
+				SecretKeyRef: corev1.ObjectReference{Name: "s3", Namespace: "storage"},
+			},
+		},
+	}
+	secured := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "secured", Namespace: "default"},
+	}
+	secured.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+
+	f := newFixture(t, archived, secured)
+
+	secret := func(namespace, name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "archived", Namespace: "default"}}},
+		f.clustersReferencingSecret(secret("storage", "s3")))
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "secured", Namespace: "default"}}},
+		f.clustersReferencingSecret(secret("default", "secured-redpanda")))
+	assert.Empty(t, f.clustersReferencingSecret(secret("default", "s3")))
+}
@@ -0,0 +1,262 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonStorageSuboptimal = "StorageSuboptimal"
+	reasonStorageOptimal    = "StorageOptimal"
+
+	// defaultStorageClassAnnotationKey marks the StorageClass of claims
+	// without a storage class name
+	defaultStorageClassAnnotationKey = "storageclass.kubernetes.io/is-default-class"
+)
+
+// fsTypeParameters are the StorageClass parameters the common provisioners
+// read the filesystem from
+var fsTypeParameters = []string{"csi.storage.k8s.io/fstype", "fsType", "fstype"}
+
+// missingMountOptions returns the mount options of the hints that are not in
+// the options
+func missingMountOptions(hints *redpandav1alpha1.StorageHints, options []string) []string {
+	present := make(map[string]bool, len(options))
+	for _, o := range options {
+		for _, single := range strings.Split(o, ",") {
+			present[strings.TrimSpace(single)] = true
+		}
+	}
+	var missing []string
+	for _, o := range hints.MountOptions {
+		if !present[o] {
+			missing = append(missing, o)
+		}
+	}
+	return missing
+}
+
+// storageClassFindings compares the StorageClass of the brokers with the
+// hints. A StorageClass that leaves the filesystem to the provisioner is not
+// reported, the brokers check the mounted one.
+func storageClassFindings(
+	hints *redpandav1alpha1.StorageHints, sc *storagev1.StorageClass,
+) []string {
+	var findings []string
+	if missing := missingMountOptions(hints, sc.MountOptions); len(missing) > 0 {
+		findings = append(findings, fmt.Sprintf("the StorageClass %s does not list the mount options %s",
+			sc.Name, strings.Join(missing, ", ")))
+	}
+	for _, key := range fsTypeParameters {
+		if fs, ok := sc.Parameters[key]; ok && fs != hints.ExpectedFilesystem() {
+			findings = append(findings, fmt.Sprintf("the StorageClass %s formats volumes with %s instead of %s",
+				sc.Name, fs, hints.ExpectedFilesystem()))
+			break
+		}
+	}
+	return findings
+}
+
+// volumeFindings compares a bound volume with the hints. The provisioner
+// copies the mount options of the StorageClass when it creates the volume,
+// volumes created before the options were added lack them.
+func volumeFindings(
+	hints *redpandav1alpha1.StorageHints, pv *corev1.PersistentVolume,
+) []string {
+	var findings []string
+	if missing := missingMountOptions(hints, pv.Spec.MountOptions); len(missing) > 0 {
+		findings = append(findings, fmt.Sprintf("the volume %s is not mounted with %s",
+			pv.Name, strings.Join(missing, ", ")))
+	}
+	if csi := pv.Spec.CSI; csi != nil && csi.FSType != "" && csi.FSType != hints.ExpectedFilesystem() {
+		findings = append(findings, fmt.Sprintf("the volume %s is formatted with %s instead of %s",
+			pv.Name, csi.FSType, hints.ExpectedFilesystem()))
+	}
+	return findings
+}
+
+// storageCheckFindings returns the deviations the storage check init
+// container of the broker wrote to its termination message
+func storageCheckFindings(pod *corev1.Pod) []string {
+	var findings []string
+	for i := range pod.Status.InitContainerStatuses {
+		status := &pod.Status.InitContainerStatuses[i]
+		if status.Name != resources.StorageCheckContainerName || status.State.Terminated == nil {
+			continue
+		}
+		for _, line := range strings.Split(status.State.Terminated.Message, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				findings = append(findings, fmt.Sprintf("%s: %s", pod.Name, line))
+			}
+		}
+	}
+	return findings
+}
+
+// brokerStorageClass returns the StorageClass of the data directories, the
+// default one when the cluster does not name one. It returns nil when it
+// does not exist.
+func (r *ClusterReconciler) brokerStorageClass(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) (*storagev1.StorageClass, error) {
+	if name := redpandaCluster.Spec.Storage.StorageClassName; name != "" {
+		var sc storagev1.StorageClass
+		err := r.Get(ctx, types.NamespacedName{Name: name}, &sc)
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch the StorageClass %s: %w", name, err)
+		}
+		return &sc, nil
+	}
+	var classes storagev1.StorageClassList
+	if err := r.List(ctx, &classes); err != nil {
+		return nil, fmt.Errorf("unable to list StorageClasses: %w", err)
+	}
+	for i := range classes.Items {
+		if classes.Items[i].Annotations[defaultStorageClassAnnotationKey] == "true" {
+			return &classes.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// reportStorageHints sets the StorageSuboptimal condition with the
+// deviations of the StorageClass, the bound volumes and the mounted data
+// directories from the storage hints. A warning event is emitted when the
+// deviations change, the brokers keep running.
+func (r *ClusterReconciler) reportStorageHints(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	hints := redpandaCluster.Spec.Storage.Hints
+	var findings []string
+	if hints != nil {
+		var err error
+		findings, err = r.storageHintFindings(ctx, redpandaCluster, hints)
+		if err != nil {
+			return err
+		}
+	}
+
+	status, reason, message := corev1.ConditionFalse, reasonStorageOptimal, "the storage of the brokers meets the storage hints"
+	if len(findings) > 0 {
+		status, reason = corev1.ConditionTrue, reasonStorageSuboptimal
+		message = strings.Join(findings, "; ")
+	}
+
+	var changed bool
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if len(findings) == 0 && cluster.Status.GetCondition(redpandav1alpha1.ClusterStorageSuboptimal) == nil {
+			return nil
+		}
+		changed = cluster.Status.SetCondition(redpandav1alpha1.ClusterStorageSuboptimal, status, reason, message)
+		if !changed {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the storage hints condition: %w", err)
+	}
+	if changed && len(findings) > 0 {
+		r.Log.Info("The storage of the brokers deviates from the storage hints", "findings", findings)
+		if r.Recorder != nil {
+			r.Recorder.Event(redpandaCluster, corev1.EventTypeWarning, reasonStorageSuboptimal, message)
+		}
+	}
+	return nil
+}
+
+// storageHintFindings collects the deviations from the hints, the ones of
+// the StorageClass first and those of the brokers in the order of their
+// names
+func (r *ClusterReconciler) storageHintFindings(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	hints *redpandav1alpha1.StorageHints,
+) ([]string, error) {
+	var findings []string
+	if !redpandaCluster.Spec.Storage.IsEmptyDir() {
+		sc, err := r.brokerStorageClass(ctx, redpandaCluster)
+		if err != nil {
+			return nil, err
+		}
+		if sc != nil {
+			findings = append(findings, storageClassFindings(hints, sc)...)
+		}
+
+		var pvcs corev1.PersistentVolumeClaimList
+		err = r.List(ctx, &pvcs, &client.ListOptions{
+			LabelSelector: labels.ForCluster(redpandaCluster).AsClientSelector(),
+			Namespace:     redpandaCluster.Namespace,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list PersistentVolumeClaims: %w", err)
+		}
+		sort.Slice(pvcs.Items, func(i, j int) bool {
+			return pvcs.Items[i].Name < pvcs.Items[j].Name
+		})
+		for i := range pvcs.Items {
+			pvc := &pvcs.Items[i]
+			if _, ok := resources.DataDirClaimOrdinal(redpandaCluster, pvc.Name); !ok ||
+				pvc.Status.Phase != corev1.ClaimBound || pvc.Spec.VolumeName == "" {
+				continue
+			}
+			var pv corev1.PersistentVolume
+			err := r.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, &pv)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("unable to fetch the volume of %s: %w", pvc.Name, err)
+			}
+			findings = append(findings, volumeFindings(hints, &pv)...)
+		}
+	}
+
+	var podList corev1.PodList
+	err := r.List(ctx, &podList, &client.ListOptions{
+		LabelSelector: labels.ForCluster(redpandaCluster).AsClientSelector(),
+		Namespace:     redpandaCluster.Namespace,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the broker Pods: %w", err)
+	}
+	sort.Slice(podList.Items, func(i, j int) bool {
+		return podList.Items[i].Name < podList.Items[j].Name
+	})
+	for i := range podList.Items {
+		findings = append(findings, storageCheckFindings(&podList.Items[i])...)
+	}
+	return findings, nil
+}
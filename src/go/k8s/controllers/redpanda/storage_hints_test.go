@@ -0,0 +1,110 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestReportStorageHints(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replicas: pointer.Int32Ptr(2),
+			Storage: redpandav1alpha1.StorageSpec{
+				Hints: &redpandav1alpha1.StorageHints{
+					MountOptions:  []string{"noatime", "nodiscard"},
+					DiskScheduler: "none",
+				},
+			},
+		},
+	}
+	sc := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "fast",
+			Annotations: map[string]string{defaultStorageClassAnnotationKey: "true"},
+		},
+		Provisioner:  "ebs.csi.aws.com",
+		Parameters:   map[string]string{"csi.storage.k8s.io/fstype": "ext4"},
+		MountOptions: []string{"noatime"},
+	}
+	claim := func(name, volume string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels.ForCluster(cluster)},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: volume},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		}
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-0"},
+		Spec: corev1.PersistentVolumeSpec{
+			MountOptions: []string{"noatime,nodiscard"},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", FSType: "ext4"},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-0", Namespace: "default", Labels: labels.ForCluster(cluster)},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name: resources.StorageCheckContainerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Message: "the data directory is ext4 instead of xfs\n",
+				}},
+			}},
+		},
+	}
+
+	f := newFixture(t,
+		cluster, sc, pv, pod, claim("datadir-cluster-0", "pv-0"),
+	)
+
+	require.NoError(t, f.reportStorageHints(context.Background(), cluster))
+
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, f.Get(context.Background(), types.NamespacedName{Name: "cluster", Namespace: "default"}, &actual))
+	condition := actual.Status.GetCondition(redpandav1alpha1.ClusterStorageSuboptimal)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t,
+		"the StorageClass fast does not list the mount options nodiscard; "+
+			"the StorageClass fast formats volumes with ext4 instead of xfs; "+
+			"the volume pv-0 is formatted with ext4 instead of xfs; "+
+			"cluster-0: the data directory is ext4 instead of xfs",
+		condition.Message)
+
+	t.Run("hints that are met clear the condition", func(t *testing.T) {
+		cluster.Spec.Storage.Hints = &redpandav1alpha1.StorageHints{
+			Filesystem:   "ext4",
+			MountOptions: []string{"noatime"},
+		}
+		pod.Status.InitContainerStatuses = nil
+		require.NoError(t, f.Status().Update(context.Background(), pod))
+		require.NoError(t, f.reportStorageHints(context.Background(), cluster))
+
+		require.NoError(t, f.Get(context.Background(), types.NamespacedName{Name: "cluster", Namespace: "default"}, &actual))
+		condition := actual.Status.GetCondition(redpandav1alpha1.ClusterStorageSuboptimal)
+		require.NotNil(t, condition)
+		assert.Equal(t, corev1.ConditionFalse, condition.Status)
+	})
+}
@@ -0,0 +1,187 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonVolumesBound   = "VolumesBound"
+	reasonVolumesUnbound = "VolumesUnbound"
+
+	// storageProvisioningTimeout is how long a claim may stay pending before
+	// it is reported, provisioning a volume usually takes a few seconds
+	storageProvisioningTimeout = 2 * time.Minute
+	// storageProvisioningRequeue is how often unbound claims are checked, the
+	// claims are not watched
+	storageProvisioningRequeue = 30 * time.Second
+)
+
+// unboundVolumes returns the datadir claims of the brokers that stayed
+// pending longer than storageProvisioningTimeout, ordered by the ordinal
+func unboundVolumes(
+	cluster *redpandav1alpha1.Cluster,
+	pvcs []corev1.PersistentVolumeClaim,
+	now time.Time,
+) []redpandav1alpha1.UnboundVolume {
+	var replicas int32
+	if cluster.Spec.Replicas != nil {
+		replicas = *cluster.Spec.Replicas
+	}
+	var unbound []redpandav1alpha1.UnboundVolume
+	for i := range pvcs {
+		pvc := &pvcs[i]
+		ordinal, ok := resources.DataDirClaimOrdinal(cluster, pvc.Name)
+		if !ok || ordinal >= replicas || !pvc.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if pvc.Status.Phase != corev1.ClaimPending ||
+			now.Sub(pvc.CreationTimestamp.Time) < storageProvisioningTimeout {
+			continue
+		}
+		unbound = append(unbound, redpandav1alpha1.UnboundVolume{
+			Ordinal:   int(ordinal),
+			ClaimName: pvc.Name,
+		})
+	}
+	sort.Slice(unbound, func(i, j int) bool {
+		return unbound[i].Ordinal < unbound[j].Ordinal
+	})
+	return unbound
+}
+
+// lastEvent returns the latest event, preferring warnings over normal events
+func lastEvent(events []corev1.Event) *corev1.Event {
+	var last *corev1.Event
+	for i := range events {
+		e := &events[i]
+		switch {
+		case last == nil:
+		case e.Type == corev1.EventTypeWarning && last.Type != corev1.EventTypeWarning:
+		case e.Type == last.Type && e.LastTimestamp.After(last.LastTimestamp.Time):
+		default:
+			continue
+		}
+		last = e
+	}
+	return last
+}
+
+// claimEvent returns the reason and the message of the last event of the
+// claim. Events are read without the cache, so the operator does not watch
+// every event of the cluster.
+func (r *ClusterReconciler) claimEvent(
+	ctx context.Context, pvc types.NamespacedName,
+) (reason, message string, err error) {
+	if r.APIReader == nil {
+		return "", "", nil
+	}
+	var events corev1.EventList
+	err = r.APIReader.List(ctx, &events, &client.ListOptions{
+		Namespace: pvc.Namespace,
+		FieldSelector: fields.SelectorFromSet(fields.Set{
+			"involvedObject.kind": "PersistentVolumeClaim",
+			"involvedObject.name": pvc.Name,
+		}),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("unable to list the events of %s: %w", pvc.Name, err)
+	}
+	if e := lastEvent(events.Items); e != nil {
+		return e.Reason, e.Message, nil
+	}
+	return "", "", nil
+}
+
+// reportStorageProvisioning sets the StorageProvisioningFailed condition and
+// lists the claims of the brokers that are not bound, with the reason from
+// their events. It returns true while claims are unbound, so they are checked
+// again soon. The in-memory status is updated for the readiness report.
+func (r *ClusterReconciler) reportStorageProvisioning(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) (bool, error) {
+	if redpandaCluster.Spec.Storage.IsEmptyDir() {
+		return false, nil
+	}
+
+	var pvcs corev1.PersistentVolumeClaimList
+	err := r.List(ctx, &pvcs, &client.ListOptions{
+		LabelSelector: labels.ForCluster(redpandaCluster).AsClientSelector(),
+		Namespace:     redpandaCluster.Namespace,
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to list PersistentVolumeClaims: %w", err)
+	}
+
+	unbound := unboundVolumes(redpandaCluster, pvcs.Items, time.Now())
+	for i := range unbound {
+		unbound[i].Reason, unbound[i].Message, err = r.claimEvent(ctx, types.NamespacedName{
+			Name:      unbound[i].ClaimName,
+			Namespace: redpandaCluster.Namespace,
+		})
+		if err != nil {
+			return true, err
+		}
+	}
+
+	status, reason, message := corev1.ConditionFalse, reasonVolumesBound, "the volumes of all brokers are bound"
+	if len(unbound) > 0 {
+		claims := make([]string, 0, len(unbound))
+		for _, v := range unbound {
+			claims = append(claims, fmt.Sprintf("%s (broker %d)", v.ClaimName, v.Ordinal))
+		}
+		status, reason = corev1.ConditionTrue, reasonVolumesUnbound
+		message = "unbound PersistentVolumeClaims: " + strings.Join(claims, ", ")
+		r.Log.Info("Brokers wait for their volumes", "claims", claims)
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		if len(unbound) == 0 && cluster.Status.GetCondition(redpandav1alpha1.ClusterStorageProvisioningFailed) == nil {
+			return nil
+		}
+		changed := cluster.Status.SetCondition(redpandav1alpha1.ClusterStorageProvisioningFailed, status, reason, message)
+		if !reflect.DeepEqual(unbound, cluster.Status.UnboundVolumes) {
+			cluster.Status.UnboundVolumes = unbound
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return len(unbound) > 0, fmt.Errorf("failed to update the storage provisioning condition: %w", err)
+	}
+	redpandaCluster.Status.UnboundVolumes = unbound
+	return len(unbound) > 0, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestUnboundVolumes(t *testing.T) {
+	now := time.Now()
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec:       redpandav1alpha1.ClusterSpec{Replicas: pointer.Int32Ptr(3)},
+	}
+	pvc := func(name string, phase corev1.PersistentVolumeClaimPhase, age time.Duration) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+		}
+	}
+
+	unbound := unboundVolumes(cluster, []corev1.PersistentVolumeClaim{
+		pvc("datadir-cluster-2", corev1.ClaimPending, time.Hour),
+		pvc("datadir-cluster-0", corev1.ClaimBound, time.Hour),
+		pvc("datadir-cluster-1", corev1.ClaimPending, time.Second),
+		pvc("datadir-cluster-5", corev1.ClaimPending, time.Hour),
+		pvc("other-claim", corev1.ClaimPending, time.Hour),
+	}, now)
+	assert.Equal(t, []redpandav1alpha1.UnboundVolume{
+		{Ordinal: 2, ClaimName: "datadir-cluster-2"},
+	}, unbound)
+}
+
+func TestLastEvent(t *testing.T) {
+	now := time.Now()
+	event := func(eventType, reason string, age time.Duration) corev1.Event {
+		return corev1.Event{Type: eventType, Reason: reason, LastTimestamp: metav1.NewTime(now.Add(-age))}
+	}
+
+	assert.Nil(t, lastEvent(nil))
+	e := lastEvent([]corev1.Event{
+		event(corev1.EventTypeNormal, "WaitForFirstConsumer", 0),
+		event(corev1.EventTypeWarning, "ProvisioningFailed", time.Hour),
+		event(corev1.EventTypeWarning, "ProvisioningFailed", time.Minute),
+	})
+	require.NotNil(t, e)
+	assert.Equal(t, "ProvisioningFailed", e.Reason)
+	assert.Equal(t, now.Add(-time.Minute).Unix(), e.LastTimestamp.Unix())
+}
+
+func TestReportStorageProvisioning(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec:       redpandav1alpha1.ClusterSpec{Replicas: pointer.Int32Ptr(2)},
+	}
+	pending := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "datadir-cluster-1",
+			Namespace:         "default",
+			Labels:            labels.ForCluster(cluster),
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	}
+	f := newFixture(t, cluster, pending)
+	key := types.NamespacedName{Name: "cluster", Namespace: "default"}
+
+	unbound, err := f.reportStorageProvisioning(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.True(t, unbound)
+
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	condition := actual.Status.GetCondition(redpandav1alpha1.ClusterStorageProvisioningFailed)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	require.Len(t, actual.Status.UnboundVolumes, 1)
+	assert.Equal(t, 1, actual.Status.UnboundVolumes[0].Ordinal)
+
+	// the readiness names the brokers waiting for their volumes
+	status, reason, _, _, _ := f.clusterReadiness(context.Background(), cluster, nil, "cluster.local", nil)
+	assert.Equal(t, corev1.ConditionFalse, status)
+	assert.Equal(t, reasonStorageProvisioningFailed, reason)
+
+	pending.Status.Phase = corev1.ClaimBound
+	require.NoError(t, f.Update(context.Background(), pending))
+	unbound, err = f.reportStorageProvisioning(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.False(t, unbound)
+	require.NoError(t, f.Get(context.Background(), key, &actual))
+	assert.Equal(t, corev1.ConditionFalse, actual.Status.GetCondition(redpandav1alpha1.ClusterStorageProvisioningFailed).Status)
+	assert.Empty(t, actual.Status.UnboundVolumes)
+}
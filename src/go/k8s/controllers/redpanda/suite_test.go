@@ -20,6 +20,7 @@ import (
 	"github.com/onsi/gomega/gexec"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
 	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
 	"k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -34,6 +35,7 @@ import (
 
 var k8sClient client.Client
 var testEnv *envtest.Environment
+var testAdminAPI *admin.MockAdminAPI
 
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -69,10 +71,12 @@ var _ = BeforeSuite(func(done Done) {
 	})
 	Expect(err).ToNot(HaveOccurred())
 
+	testAdminAPI = admin.NewMockAdminAPI()
 	err = (&redpandacontrollers.ClusterReconciler{
-		Client: k8sManager.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("core").WithName("RedpandaCluster"),
-		Scheme: k8sManager.GetScheme(),
+		Client:                k8sManager.GetClient(),
+		Log:                   ctrl.Log.WithName("controllers").WithName("core").WithName("RedpandaCluster"),
+		Scheme:                k8sManager.GetScheme(),
+		AdminAPIClientFactory: testAdminAPI.Factory(),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
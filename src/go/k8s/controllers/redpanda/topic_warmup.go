@@ -0,0 +1,321 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	topicWarmupComponent     = "topic-warmup"
+	topicWarmupContainerName = "topic-warmup"
+	topicWarmupHashLength    = 10
+
+	reasonTopicsWarming      = "TopicsWarming"
+	reasonTopicsCreating     = "TopicsCreating"
+	reasonPartitionsNoLeader = "PartitionsWithoutLeader"
+	reasonTopicWarmupFailed  = "TopicWarmupFailed"
+	reasonTopicsWarm         = "TopicsWarm"
+	reasonTopicsCreated      = "TopicsCreated"
+
+	// topicWarmupRequeue is how often the topics are checked while they are
+	// warming up
+	topicWarmupRequeue = 10 * time.Second
+)
+
+// topicWarmupJobName is the name of the Job creating the topics, it contains
+// a hash of the topics so each set of missing topics is created once
+func topicWarmupJobName(
+	cluster *redpandav1alpha1.Cluster, topics []redpandav1alpha1.WarmupTopic,
+) string {
+	// marshalling the typed topics does not fail
+	b, _ := json.Marshal(topics) // nolint:errcheck // see above
+	hash := fmt.Sprintf("%x", sha256.Sum256(b))[:topicWarmupHashLength]
+	return cluster.Name + "-topic-warmup-" + hash
+}
+
+// topicWarmupScript returns the rpk commands creating the topics, the topic
+// names are validated by the webhook
+func topicWarmupScript(topics []redpandav1alpha1.WarmupTopic) string {
+	commands := make([]string, 0, len(topics))
+	for _, t := range topics {
+		command := fmt.Sprintf("rpk topic create %s --partitions %d", t.Name, t.Partitions)
+		if t.ReplicationFactor != nil {
+			command += fmt.Sprintf(" --replicas %d", *t.ReplicationFactor)
+		}
+		commands = append(commands, command)
+	}
+	return strings.Join(commands, " && ")
+}
+
+// topicWarmupJob returns the Job creating the missing topics of the warmup
+func (r *ClusterReconciler) topicWarmupJob(
+	cluster *redpandav1alpha1.Cluster,
+	topics []redpandav1alpha1.WarmupTopic,
+	fqdn string,
+) (*batchv1.Job, error) {
+	replicas := int(*cluster.Spec.Replicas)
+	brokers := make([]string, 0, replicas)
+	for i := 0; i < replicas; i++ {
+		brokers = append(brokers, fmt.Sprintf("%s-%d.%s:%d",
+			cluster.Name, i, strings.TrimSuffix(fqdn, "."), cluster.Spec.Configuration.KafkaAPI.Port))
+	}
+	l := make(labels.CommonLabels)
+	for k, v := range labels.ForCluster(cluster) {
+		l[k] = v
+	}
+	l[labels.ComponentKey] = topicWarmupComponent
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      topicWarmupJobName(cluster, topics),
+			Namespace: cluster.Namespace,
+			Labels:    l,
+		},
+		Spec: batchv1.JobSpec{
+			// a retry fails on the topics the first attempt created, the
+			// remaining topics are created by the Job of the next check
+			BackoffLimit: pointer.Int32Ptr(0),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: cluster.Spec.TopicWarmup.ServiceAccountName,
+					Containers: []corev1.Container{
+						{
+							Name:    topicWarmupContainerName,
+							Image:   cluster.FullImageName(),
+							Command: []string{"/bin/sh", "-c", topicWarmupScript(topics)},
+							Env: []corev1.EnvVar{
+								{
+									Name:  "RPK_BROKERS",
+									Value: strings.Join(brokers, ","),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := resources.SetOwner(cluster, job, r.Scheme); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// warmupProgress returns the topics of the warmup that do not exist, the
+// partitions of existing topics without a leader and the warm topics
+func warmupProgress(
+	topics []redpandav1alpha1.WarmupTopic, partitions []admin.PartitionSummary,
+) (missing []redpandav1alpha1.WarmupTopic, leaderless int, warm []string) {
+	existing := map[string]int{}
+	withoutLeader := map[string]int{}
+	for _, p := range partitions {
+		if p.Namespace != "kafka" {
+			continue
+		}
+		existing[p.Topic]++
+		if p.Leader < 0 {
+			withoutLeader[p.Topic]++
+		}
+	}
+	for _, t := range topics {
+		count, ok := existing[t.Name]
+		switch {
+		case !ok:
+			missing = append(missing, t)
+		case withoutLeader[t.Name] > 0:
+			leaderless += withoutLeader[t.Name]
+		case count < int(t.Partitions):
+			// the partitions of a new topic are listed as they are created
+			leaderless += int(t.Partitions) - count
+		default:
+			warm = append(warm, t.Name)
+		}
+	}
+	sort.Strings(warm)
+	return missing, leaderless, warm
+}
+
+// reconcileTopicWarmup creates the topics of the warmup once every broker is
+// ready and reports in the TopicsWarm condition whether each of their
+// partitions has a leader. It returns true while the topics are warming up.
+// The ClusterReady condition waits for the topics to be warm.
+func (r *ClusterReconciler) reconcileTopicWarmup(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	sts *appsv1.StatefulSet,
+	fqdn string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+) (bool, error) {
+	if !redpandaCluster.WarmingTopics() || redpandaCluster.Spec.Replicas == nil {
+		return false, nil
+	}
+	if sts == nil || sts.Status.ReadyReplicas != *redpandaCluster.Spec.Replicas {
+		return true, r.updateTopicWarmup(ctx, redpandaCluster, redpandaCluster.Status.WarmTopics,
+			corev1.ConditionFalse, reasonTopicsWarming, "waiting for every broker to be ready")
+	}
+
+	adminAPI, err := r.AdminAPIClientFactory(ctx, r, redpandaCluster, fqdn, adminTLSProvider)
+	if err != nil {
+		return true, fmt.Errorf("unable to create admin API client: %w", err)
+	}
+	partitions, err := adminAPI.ListPartitions(ctx)
+	// this Redpanda version does not list the partitions, the topics count
+	// as warm once the Job created them
+	fallback := notServed("topic warmup", err)
+	unlisted := fallback != nil
+	if err != nil && !unlisted {
+		r.Log.Info("Unable to list the partitions of the warmup topics", "error", err)
+		return true, nil
+	}
+	missing, leaderless, warm := warmupProgress(redpandaCluster.Spec.TopicWarmup.Topics, partitions)
+	if unlisted {
+		missing, warm = createdTopics(redpandaCluster.Spec.TopicWarmup.Topics, redpandaCluster.Status.WarmTopics)
+	}
+	if len(missing) == 0 && leaderless == 0 {
+		return false, r.updateTopicWarmup(ctx, redpandaCluster, warm,
+			corev1.ConditionTrue, reasonTopicsWarm, "every partition of the warmup topics has a leader")
+	}
+	if len(missing) == 0 {
+		return true, r.updateTopicWarmup(ctx, redpandaCluster, warm,
+			corev1.ConditionFalse, reasonPartitionsNoLeader, fmt.Sprintf("%d partitions of the warmup topics without leader", leaderless))
+	}
+
+	name := topicWarmupJobName(redpandaCluster, missing)
+	names := make([]string, 0, len(missing))
+	for _, t := range missing {
+		names = append(names, t.Name)
+	}
+	var job batchv1.Job
+	err = r.Get(ctx, types.NamespacedName{Name: name, Namespace: redpandaCluster.Namespace}, &job)
+	switch {
+	case apierrors.IsNotFound(err):
+		obj, err := r.topicWarmupJob(redpandaCluster, missing, fqdn)
+		if err != nil {
+			return true, fmt.Errorf("unable to construct the topic warmup Job: %w", err)
+		}
+		r.Log.Info("Creating the warmup topics", "topics", names, "job", name)
+		if err := r.Create(ctx, obj); err != nil && !apierrors.IsAlreadyExists(err) {
+			return true, fmt.Errorf("unable to create the topic warmup Job: %w", err)
+		}
+	case err != nil:
+		return true, fmt.Errorf("unable to get the topic warmup Job: %w", err)
+	case unlisted && topicJobSucceeded(&job):
+		created := append(append([]string{}, warm...), names...)
+		sort.Strings(created)
+		return false, withFallback(r.updateTopicWarmup(ctx, redpandaCluster, created,
+			corev1.ConditionTrue, reasonTopicsCreated,
+			fmt.Sprintf("job %s created topics %v, the running version does not report their leaders", name, names)), fallback)
+	case topicJobFailed(&job):
+		previous := redpandaCluster.Status.GetCondition(redpandav1alpha1.ClusterTopicsWarm)
+		if (previous == nil || previous.Reason != reasonTopicWarmupFailed) && r.Recorder != nil {
+			r.Recorder.Eventf(redpandaCluster, corev1.EventTypeWarning, reasonTopicWarmupFailed,
+				"creating topics %v failed, see the logs of job %s", names, name)
+		}
+		return false, withFallback(r.updateTopicWarmup(ctx, redpandaCluster, warm,
+			corev1.ConditionFalse, reasonTopicWarmupFailed, fmt.Sprintf("job %s failed to create topics %v", name, names)), fallback)
+	}
+	return true, withFallback(r.updateTopicWarmup(ctx, redpandaCluster, warm,
+		corev1.ConditionFalse, reasonTopicsCreating, fmt.Sprintf("job %s creates topics %v", name, names)), fallback)
+}
+
+// createdTopics returns the topics of the warmup that no Job created yet and
+// the ones that were created, for versions that do not list the partitions
+func createdTopics(
+	topics []redpandav1alpha1.WarmupTopic, created []string,
+) (missing []redpandav1alpha1.WarmupTopic, warm []string) {
+	known := make(map[string]bool, len(created))
+	for _, t := range created {
+		known[t] = true
+	}
+	for _, t := range topics {
+		if known[t.Name] {
+			warm = append(warm, t.Name)
+		} else {
+			missing = append(missing, t)
+		}
+	}
+	sort.Strings(warm)
+	return missing, warm
+}
+
+// topicJobSucceeded returns true when the Job created its topics
+func topicJobSucceeded(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobComplete && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// topicJobFailed returns true when the Job creating topics failed
+func topicJobFailed(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ClusterReconciler) updateTopicWarmup(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	warm []string,
+	status corev1.ConditionStatus,
+	reason, message string,
+) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+		changed := cluster.Status.SetCondition(redpandav1alpha1.ClusterTopicsWarm, status, reason, message)
+		if !reflect.DeepEqual(warm, cluster.Status.WarmTopics) {
+			cluster.Status.WarmTopics = warm
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return r.Status().Update(ctx, &cluster)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the topics warm condition: %w", err)
+	}
+	redpandaCluster.Status.WarmTopics = warm
+	redpandaCluster.Status.SetCondition(redpandav1alpha1.ClusterTopicsWarm, status, reason, message)
+	return nil
+}
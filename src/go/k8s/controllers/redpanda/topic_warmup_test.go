@@ -0,0 +1,125 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestWarmupProgress(t *testing.T) {
+	topics := []redpandav1alpha1.WarmupTopic{
+		{Name: "orders", Partitions: 2},
+		{Name: "quotes", Partitions: 2},
+		{Name: "trades", Partitions: 1},
+	}
+	partitions := []admin.PartitionSummary{
+		{Namespace: "kafka", Topic: "orders", PartitionID: 0, Leader: 1},
+		{Namespace: "kafka", Topic: "orders", PartitionID: 1, Leader: 2},
+		{Namespace: "kafka", Topic: "quotes", PartitionID: 0, Leader: -1},
+		{Namespace: "redpanda", Topic: "trades", PartitionID: 0, Leader: 1},
+	}
+
+	missing, leaderless, warm := warmupProgress(topics, partitions)
+	assert.Equal(t, []redpandav1alpha1.WarmupTopic{topics[2]}, missing)
+	assert.Equal(t, 1, leaderless)
+	assert.Equal(t, []string{"orders"}, warm)
+}
+
+func TestReconcileTopicWarmup(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replicas: pointer.Int32Ptr(1),
+			TopicWarmup: &redpandav1alpha1.TopicWarmup{Topics: []redpandav1alpha1.WarmupTopic{
+				{Name: "orders", Partitions: 2, ReplicationFactor: pointer.Int32Ptr(1)},
+				{Name: "quotes", Partitions: 1},
+			}},
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				KafkaAPI: redpandav1alpha1.KafkaAPI{Port: 9092},
+			},
+		},
+	}
+	f := newFixture(t, cluster)
+	reconcile := func(ready int32, warming bool) (*redpandav1alpha1.Cluster, error) {
+		sts := &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: ready}}
+		actualWarming, err := f.reconcileTopicWarmup(context.Background(), cluster, sts, "cluster.default.svc.cluster.local.", nil)
+		assert.Equal(t, warming, actualWarming)
+		actual := f.get(t, "cluster")
+		cluster = &actual
+		return &actual, err
+	}
+	jobKey := types.NamespacedName{
+		Name:      topicWarmupJobName(cluster, cluster.Spec.TopicWarmup.Topics),
+		Namespace: "default",
+	}
+
+	// the warmup waits for every broker
+	actual, err := reconcile(0, true)
+	require.NoError(t, err)
+	assert.Equal(t, reasonTopicsWarming, actual.Status.GetCondition(redpandav1alpha1.ClusterTopicsWarm).Reason)
+	assert.Error(t, f.Get(context.Background(), jobKey, &batchv1.Job{}))
+
+	// this version does not list the partitions, every topic is created
+	actual, err = reconcile(1, true)
+	assert.True(t, admin.IsUnsupported(err))
+	assert.Equal(t, reasonTopicsCreating, actual.Status.GetCondition(redpandav1alpha1.ClusterTopicsWarm).Reason)
+	var job batchv1.Job
+	require.NoError(t, f.Get(context.Background(), jobKey, &job))
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{"/bin/sh", "-c",
+		"rpk topic create orders --partitions 2 --replicas 1 && rpk topic create quotes --partitions 1",
+	}, container.Command)
+	assert.Contains(t, container.Env, corev1.EnvVar{
+		Name:  "RPK_BROKERS",
+		Value: "cluster-0.cluster.default.svc.cluster.local:9092",
+	})
+
+	// the topics count as warm once the Job created them
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	require.NoError(t, f.Status().Update(context.Background(), &job))
+	actual, err = reconcile(1, false)
+	assert.True(t, admin.IsUnsupported(err))
+	condition := actual.Status.GetCondition(redpandav1alpha1.ClusterTopicsWarm)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, reasonTopicsCreated, condition.Reason)
+	assert.Equal(t, []string{"orders", "quotes"}, actual.Status.WarmTopics)
+	assert.False(t, actual.WarmingTopics())
+
+	// a failed Job is reported once
+	cluster.Spec.TopicWarmup.Topics = append(cluster.Spec.TopicWarmup.Topics,
+		redpandav1alpha1.WarmupTopic{Name: "trades", Partitions: 1})
+	require.NoError(t, f.Update(context.Background(), cluster))
+	_, err = reconcile(1, true)
+	assert.True(t, admin.IsUnsupported(err))
+	failedKey := types.NamespacedName{
+		Name:      topicWarmupJobName(cluster, cluster.Spec.TopicWarmup.Topics[2:]),
+		Namespace: "default",
+	}
+	require.NoError(t, f.Get(context.Background(), failedKey, &job))
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+	require.NoError(t, f.Status().Update(context.Background(), &job))
+	actual, _ = reconcile(1, false)
+	assert.Equal(t, reasonTopicWarmupFailed, actual.Status.GetCondition(redpandav1alpha1.ClusterTopicsWarm).Reason)
+	assert.Equal(t, []string{"orders", "quotes"}, actual.Status.WarmTopics)
+	_, _ = reconcile(1, false)
+	assert.Len(t, f.recorder.Events, 1)
+}
@@ -0,0 +1,190 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonZoneTurn        = "ZoneTurn"
+	reasonZoneWaitTimeout = "ZoneWaitTimeout"
+
+	// zoneStartupTimeout bounds how long a ready broker waits for the other
+	// zones, so a zone that does not come up does not block the cluster
+	zoneStartupTimeout = 5 * time.Minute
+	// zoneStartupRequeue is how often held back brokers are checked, the
+	// containers becoming ready do not change the StatefulSet
+	zoneStartupRequeue = 10 * time.Second
+)
+
+// zonePod is a broker Pod scheduled to a node in a zone
+type zonePod struct {
+	pod  *corev1.Pod
+	zone string
+}
+
+func (p *zonePod) released() bool {
+	return podConditionTrue(p.pod, resources.ZoneBalancedReadinessGate)
+}
+
+func podConditionTrue(
+	pod *corev1.Pod, conditionType corev1.PodConditionType,
+) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == conditionType {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// containersReadySince returns when the containers of the Pod became ready or
+// false when they are not ready
+func containersReadySince(pod *corev1.Pod) (time.Time, bool) {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.ContainersReady && c.Status == corev1.ConditionTrue {
+			return c.LastTransitionTime.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// podsToRelease returns the Pods whose readiness gate is set next. A broker
+// whose containers are ready is released when its zone has no more released
+// brokers than any other zone still waiting for brokers, in the order of the
+// Pod names, or when it waited longer than zoneStartupTimeout. Brokers on
+// nodes without a zone are released right away.
+func podsToRelease(pods []zonePod, now time.Time) (release []zonePod, held bool) {
+	released := map[string]int{}
+	waiting := map[string]int{}
+	var candidates []zonePod
+	for _, p := range pods {
+		if p.released() {
+			released[p.zone]++
+			continue
+		}
+		waiting[p.zone]++
+		if _, ready := containersReadySince(p.pod); ready {
+			candidates = append(candidates, p)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].pod.Name < candidates[j].pod.Name
+	})
+
+	for _, p := range candidates {
+		since, _ := containersReadySince(p.pod)
+		if p.zone != "" && !leastReleased(p.zone, released, waiting) &&
+			now.Sub(since) < zoneStartupTimeout {
+			held = true
+			continue
+		}
+		release = append(release, p)
+		released[p.zone]++
+		waiting[p.zone]--
+	}
+	return release, held
+}
+
+// leastReleased is true when no other zone waiting for brokers has fewer
+// released brokers than the zone
+func leastReleased(zone string, released, waiting map[string]int) bool {
+	for z, n := range waiting {
+		if n > 0 && z != "" && z != zone && released[z] < released[zone] {
+			return false
+		}
+	}
+	return true
+}
+
+// zoneNodeLabel returns the node label the turns of the zones are taken by.
+// With rack awareness the racks the brokers run with are the zones, so the
+// turns follow the placement of the replicas.
+func zoneNodeLabel(redpandaCluster *redpandav1alpha1.Cluster) string {
+	if redpandaCluster.RackAwarenessEnabled() {
+		return redpandaCluster.RackNodeLabel()
+	}
+	return corev1.LabelZoneFailureDomainStable
+}
+
+// reconcileZoneStartup sets the zone balanced readiness gate of the brokers
+// whose zone takes its turn. It returns true while ready brokers are held
+// back, so the cluster is checked again soon.
+func (r *ClusterReconciler) reconcileZoneStartup(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) (held bool, err error) {
+	if !redpandaCluster.Spec.ZoneBalancedStartup {
+		return false, nil
+	}
+
+	var podList corev1.PodList
+	err = r.List(ctx, &podList, &client.ListOptions{
+		LabelSelector: labels.ForCluster(redpandaCluster).AsClientSelector(),
+		Namespace:     redpandaCluster.Namespace,
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to list the broker Pods: %w", err)
+	}
+
+	zoneLabel := zoneNodeLabel(redpandaCluster)
+	zones := map[string]string{}
+	pods := make([]zonePod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Spec.NodeName == "" || !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		zone, ok := zones[pod.Spec.NodeName]
+		if !ok {
+			var node corev1.Node
+			err := r.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, &node)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return false, fmt.Errorf("unable to fetch node %s: %w", pod.Spec.NodeName, err)
+			}
+			zone = node.Labels[zoneLabel]
+			zones[pod.Spec.NodeName] = zone
+		}
+		pods = append(pods, zonePod{pod: pod, zone: zone})
+	}
+
+	now := time.Now()
+	release, held := podsToRelease(pods, now)
+	for _, p := range release {
+		reason := reasonZoneTurn
+		if since, _ := containersReadySince(p.pod); now.Sub(since) >= zoneStartupTimeout {
+			reason = reasonZoneWaitTimeout
+		}
+		r.Log.Info("Releasing the readiness of the broker", "pod", p.pod.Name, "zone", p.zone, "reason", reason)
+		p.pod.Status.Conditions = append(p.pod.Status.Conditions, corev1.PodCondition{
+			Type:               resources.ZoneBalancedReadinessGate,
+			Status:             corev1.ConditionTrue,
+			Reason:             reason,
+			LastTransitionTime: metav1.NewTime(now),
+		})
+		// Conflicts with the kubelet are retried on the next reconcile
+		if err := r.Status().Update(ctx, p.pod); err != nil {
+			return true, fmt.Errorf("unable to set the readiness gate of %s: %w", p.pod.Name, err)
+		}
+	}
+	return held, nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodsToRelease(t *testing.T) {
+	now := time.Now()
+	pod := func(name, zone string, readyFor time.Duration, released bool) zonePod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if readyFor > 0 {
+			p.Status.Conditions = append(p.Status.Conditions, corev1.PodCondition{
+				Type:               corev1.ContainersReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(now.Add(-readyFor)),
+			})
+		}
+		if released {
+			p.Status.Conditions = append(p.Status.Conditions, corev1.PodCondition{
+				Type:   resources.ZoneBalancedReadinessGate,
+				Status: corev1.ConditionTrue,
+			})
+		}
+		return zonePod{pod: p, zone: zone}
+	}
+	names := func(pods []zonePod) []string {
+		var n []string
+		for _, p := range pods {
+			n = append(n, p.pod.Name)
+		}
+		return n
+	}
+
+	tests := []struct {
+		name     string
+		pods     []zonePod
+		expected []string
+		held     bool
+	}{
+		{
+			name: "one broker per zone at a time",
+			pods: []zonePod{
+				pod("cluster-0", "a", time.Second, false),
+				pod("cluster-1", "b", 0, false),
+				pod("cluster-2", "a", time.Second, false),
+				pod("cluster-3", "b", 0, false),
+			},
+			expected: []string{"cluster-0"},
+			held:     true,
+		},
+		{
+			name: "a zone that caught up releases the next turn",
+			pods: []zonePod{
+				pod("cluster-0", "a", time.Minute, true),
+				pod("cluster-1", "b", time.Second, false),
+				pod("cluster-2", "a", time.Second, false),
+				pod("cluster-3", "b", time.Second, false),
+			},
+			expected: []string{"cluster-1", "cluster-2", "cluster-3"},
+		},
+		{
+			name: "restarted brokers are released in a balanced cluster",
+			pods: []zonePod{
+				pod("cluster-0", "a", time.Second, false),
+				pod("cluster-1", "b", time.Minute, true),
+				pod("cluster-2", "c", time.Minute, true),
+			},
+			expected: []string{"cluster-0"},
+		},
+		{
+			name: "a zone that does not come up blocks for a bounded time",
+			pods: []zonePod{
+				pod("cluster-0", "a", time.Hour, true),
+				pod("cluster-1", "b", 0, false),
+				pod("cluster-2", "a", zoneStartupTimeout, false),
+			},
+			expected: []string{"cluster-2"},
+		},
+		{
+			name: "nodes without a zone are not held back",
+			pods: []zonePod{
+				pod("cluster-0", "", time.Second, false),
+				pod("cluster-1", "", time.Second, false),
+			},
+			expected: []string{"cluster-0", "cluster-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release, held := podsToRelease(tt.pods, now)
+			assert.Equal(t, tt.expected, names(release))
+			assert.Equal(t, tt.held, held)
+		})
+	}
+}
+
+func TestZoneNodeLabel(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{}
+	assert.Equal(t, corev1.LabelZoneFailureDomainStable, zoneNodeLabel(cluster))
+
+	// the racks are the zones of the turns
+	cluster.Spec.Configuration.RackAwareness = &redpandav1alpha1.RackAwareness{NodeLabel: "example.com/rack"}
+	assert.Equal(t, corev1.LabelZoneFailureDomainStable, zoneNodeLabel(cluster))
+	cluster.Spec.Configuration.RackAwareness.Enabled = true
+	assert.Equal(t, "example.com/rack", zoneNodeLabel(cluster))
+}
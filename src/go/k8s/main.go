@@ -10,11 +10,14 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"time"
 
 	cmapiv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
 	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -24,6 +27,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
+// defaultSyncPeriod is the default of controller-runtime
+const defaultSyncPeriod = 10 * time.Hour
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -39,11 +45,18 @@ func init() {
 
 func main() {
 	var (
-		metricsAddr          string
-		enableLeaderElection bool
-		probeAddr            string
-		webhookEnabled       bool
-		configuratorTag      string
+		metricsAddr              string
+		enableLeaderElection     bool
+		probeAddr                string
+		webhookEnabled           bool
+		configuratorTag          string
+		startupJitter            time.Duration
+		checkAdvertisedAddresses bool
+		decommissionGhostBrokers bool
+		operatorNamespace        string
+		syncPeriod               time.Duration
+		resyncPeriod             time.Duration
+		requeuePeriod            time.Duration
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -53,6 +66,28 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&webhookEnabled, "webhook-enabled", false, "Enable webhook Manager")
 	flag.StringVar(&configuratorTag, "configurator-tag", "latest", "Set the configurator tag")
+	flag.DurationVar(&startupJitter, "reconcile-startup-jitter", 0,
+		"Spread the first reconcile of existing clusters after the operator start over this window. "+
+			"Every cluster gets a fixed slot derived from its UID.")
+	flag.BoolVar(&checkAdvertisedAddresses, "check-advertised-addresses", false,
+		"Connect to the advertised external Kafka API addresses and report the unreachable ones in events and the Cluster conditions. "+
+			"Enable only when the operator reaches the addresses the same way as clients.")
+	flag.BoolVar(&decommissionGhostBrokers, "decommission-ghost-brokers", false,
+		"Decommission brokers that are members of a cluster without a matching pod ordinal and are not alive. "+
+			"Node ID conflicts are reported in events and the Cluster conditions either way.")
+	flag.StringVar(&operatorNamespace, "operator-namespace", "",
+		"The namespace of the operator. Cluster NetworkPolicies allow it to reach the Admin API.")
+	flag.DurationVar(&syncPeriod, "sync-period", defaultSyncPeriod,
+		"The period after which the informer caches are resynced and every watched object is reconciled again.")
+	flag.DurationVar(&resyncPeriod, "resync-period", 0,
+		"Reconcile clusters periodically, unless they set spec.resyncPeriod. Disabled when zero.")
+	flag.DurationVar(&requeuePeriod, "requeue-period", 0,
+		"The delay before a resource that is not ready is checked again. "+
+			"The redpanda.vectorized.io/requeue-period annotation overrides it per Cluster. "+
+			"Each resource uses its own delay when zero.")
+	flag.BoolVar(&redpandav1alpha1.AllowEmptyDirStorage, "allow-emptydir-storage", false,
+		"Allow clusters to keep their data directory on an emptyDir instead of a PersistentVolumeClaim. "+
+			"The data of a broker is lost with its pod, use it only for development and CI clusters.")
 
 	opts := zap.Options{
 		Development: true,
@@ -64,6 +99,11 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if err := validatePeriods(syncPeriod, resyncPeriod, requeuePeriod); err != nil {
+		setupLog.Error(err, "Invalid reconcile periods")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -71,6 +111,7 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "aa9fc693.vectorized.io",
+		SyncPeriod:             &syncPeriod,
 	})
 	if err != nil {
 		setupLog.Error(err, "Unable to start manager")
@@ -78,10 +119,20 @@ func main() {
 	}
 
 	if err = (&redpandacontrollers.ClusterReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("redpanda").WithName("Cluster"),
-		Scheme: mgr.GetScheme(),
-	}).WithConfiguratorTag(configuratorTag).SetupWithManager(mgr); err != nil {
+		Client:                mgr.GetClient(),
+		Log:                   ctrl.Log.WithName("controllers").WithName("redpanda").WithName("Cluster"),
+		Scheme:                mgr.GetScheme(),
+		AdminAPIClientFactory: admin.NewInternalAdminAPI,
+		Recorder:              mgr.GetEventRecorderFor("redpanda-cluster"),
+		APIReader:             mgr.GetAPIReader(),
+	}).WithConfiguratorTag(configuratorTag).
+		WithStartupJitter(startupJitter).
+		WithAdvertisedAddressCheck(checkAdvertisedAddresses).
+		WithGhostBrokerDecommission(decommissionGhostBrokers).
+		WithOperatorNamespace(operatorNamespace).
+		WithResyncPeriod(resyncPeriod).
+		WithRequeuePeriod(requeuePeriod).
+		SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "Unable to create controller", "controller", "Cluster")
 		os.Exit(1)
 	}
@@ -119,3 +170,18 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// validatePeriods rejects reconcile periods short enough to overload the API
+// server and the Admin API of the brokers
+func validatePeriods(syncPeriod, resyncPeriod, requeuePeriod time.Duration) error {
+	if syncPeriod < redpandav1alpha1.MinResyncPeriod {
+		return fmt.Errorf("--sync-period must be at least %s", redpandav1alpha1.MinResyncPeriod)
+	}
+	if resyncPeriod != 0 && resyncPeriod < redpandav1alpha1.MinResyncPeriod {
+		return fmt.Errorf("--resync-period must be zero or at least %s", redpandav1alpha1.MinResyncPeriod)
+	}
+	if requeuePeriod != 0 && requeuePeriod < redpandav1alpha1.MinRequeuePeriod {
+		return fmt.Errorf("--requeue-period must be zero or at least %s", redpandav1alpha1.MinRequeuePeriod)
+	}
+	return nil
+}
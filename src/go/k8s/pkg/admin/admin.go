@@ -0,0 +1,581 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+// Package admin contains tools for the operator to connect to the admin API
+package admin
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+
+	brokersEndpoint       = "/v1/brokers"
+	clusterHealthEndpoint = "/v1/cluster/health_overview"
+	clusterUUIDEndpoint   = "/v1/cluster/uuid"
+	clusterConfigEndpoint = "/v1/cluster_config"
+	configEndpoint        = "/v1/config"
+	configSchemaEndpoint  = "/v1/cluster_config/schema"
+	partitionsEndpoint    = "/v1/partitions"
+	usersEndpoint         = "/v1/security/users"
+	raftEndpoint          = "/v1/raft"
+	metricsEndpoint       = "/metrics"
+
+	// metrics of the Seastar reactor of every shard of a broker
+	reactorUtilizationMetric = "vectorized_reactor_utilization"
+	allocatedMemoryMetric    = "vectorized_memory_allocated_memory"
+
+	// ControllerRaftGroup is the Raft group of the controller partition
+	ControllerRaftGroup = 0
+
+	// NetworkStatusAnnotation lists the addresses of a Pod in every network
+	// it is attached to
+	NetworkStatusAnnotation = "k8s.v1.cni.cncf.io/network-status"
+)
+
+var (
+	// ErrNoAdminAPIURL is returned when the client has no broker to talk to
+	ErrNoAdminAPIURL = errors.New("no admin API URL configured")
+	// ErrUnknownNode is returned when a node specific call can not be routed
+	ErrUnknownNode = errors.New("node is not known to the admin API client")
+	// ErrUnsupported is returned without sending a request for the
+	// endpoints the Admin API of this Redpanda version does not serve
+	ErrUnsupported = errors.New("the Admin API of this Redpanda version does not serve the endpoint")
+	// ErrNoResourceMetrics is returned when the metrics of a broker do not
+	// report its CPU and memory usage
+	ErrNoResourceMetrics = errors.New("the broker metrics do not report its CPU and memory usage")
+)
+
+// AdminAPIClient is a sub interface of the Redpanda Admin API that the
+// operator uses. It is an interface so it can be replaced by a fake in tests.
+// This Redpanda version serves the configuration of a broker, the SASL
+// users, the leadership transfers and the metrics. The other calls return
+// ErrUnsupported and their callers degrade, see IsUnsupported.
+type AdminAPIClient interface {
+	// Brokers returns the list of brokers known to the cluster
+	Brokers(ctx context.Context) ([]Broker, error)
+	// DecommissionBroker starts moving partitions out of the given broker
+	DecommissionBroker(ctx context.Context, nodeID int) error
+	// RecommissionBroker cancels an ongoing decommission of the given broker
+	RecommissionBroker(ctx context.Context, nodeID int) error
+	// ClusterHealth returns the cluster health overview
+	ClusterHealth(ctx context.Context) (ClusterHealthOverview, error)
+	// ClusterUUID returns the UUID of the cluster
+	ClusterUUID(ctx context.Context) (string, error)
+	// ClusterConfig returns the configuration properties of the broker that
+	// answers, they are the same on every broker the operator configures
+	ClusterConfig(ctx context.Context) (map[string]interface{}, error)
+	// ClusterConfigSchema returns the cluster properties the running
+	// version supports keyed by their name
+	ClusterConfigSchema(ctx context.Context) (map[string]ConfigPropertySchema, error)
+	// SetConfig upserts and removes cluster level configuration properties
+	SetConfig(ctx context.Context, upsert map[string]interface{}, remove []string) error
+	// EnableMaintenanceMode drains leadership from the given broker
+	EnableMaintenanceMode(ctx context.Context, nodeID int) error
+	// DisableMaintenanceMode lets the given broker take leadership again
+	DisableMaintenanceMode(ctx context.Context, nodeID int) error
+	// MaintenanceStatus returns whether the given broker in maintenance
+	// mode finished draining its leadership
+	MaintenanceStatus(ctx context.Context, nodeID int) (MaintenanceStatus, error)
+	// GetPartitions returns partitions of the given topic with their replicas
+	GetPartitions(ctx context.Context, namespace, topic string) ([]Partition, error)
+	// ListPartitions returns every partition of the cluster with its leader
+	ListPartitions(ctx context.Context) ([]PartitionSummary, error)
+	// SetPartitionReplicas moves the partition to the given set of replicas
+	SetPartitionReplicas(ctx context.Context, namespace, topic string, partition int, replicas []Replica) error
+	// ListUsers returns the names of the SASL users
+	ListUsers(ctx context.Context) ([]string, error)
+	// CreateUser creates a SASL user with the given SCRAM mechanism
+	CreateUser(ctx context.Context, username, password, mechanism string) error
+	// UpdateUser replaces the password of a SASL user. A SCRAM user has one
+	// password, the previous one stops working right away.
+	UpdateUser(ctx context.Context, username, password, mechanism string) error
+	// TransferLeadership asks the leader of a Raft group to hand the
+	// leadership over to the target broker
+	TransferLeadership(ctx context.Context, leaderID, group, targetID int) error
+	// ResourceUsage returns the CPU and memory the given broker uses, read
+	// from its metrics
+	ResourceUsage(ctx context.Context, nodeID int) (ResourceUsage, error)
+}
+
+// AdminAPIClientFactory creates an AdminAPIClient for the given cluster
+type AdminAPIClientFactory func(
+	ctx context.Context,
+	k8sClient client.Reader,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	fqdn string,
+	adminTLSProvider AdminTLSConfigProvider,
+) (AdminAPIClient, error)
+
+// AdminTLSConfigProvider returns the TLS configuration used to reach the
+// admin API of a cluster
+type AdminTLSConfigProvider interface {
+	GetTLSConfig(ctx context.Context, k8sClient client.Reader) (*tls.Config, error)
+}
+
+var _ AdminAPIClientFactory = NewInternalAdminAPI
+
+// Broker is the broker information returned by the admin API
+type Broker struct {
+	NodeID           int    `json:"node_id"`
+	NumCores         int    `json:"num_cores"`
+	MembershipStatus string `json:"membership_status"`
+	IsAlive          *bool  `json:"is_alive,omitempty"`
+	Version          string `json:"version,omitempty"`
+}
+
+// MaintenanceStatus is the progress of the leadership drain of a broker in
+// maintenance mode returned by the admin API
+type MaintenanceStatus struct {
+	Draining bool `json:"draining"`
+	Finished bool `json:"finished"`
+	Errors   bool `json:"errors"`
+}
+
+// ClusterHealthOverview is the cluster health summary returned by the admin API
+type ClusterHealthOverview struct {
+	IsHealthy            bool     `json:"is_healthy"`
+	ControllerID         int      `json:"controller_id"`
+	AllNodes             []int    `json:"all_nodes"`
+	NodesDown            []int    `json:"nodes_down"`
+	LeaderlessPartitions []string `json:"leaderless_partitions"`
+	UnderReplicatedCount int      `json:"under_replicated_count"`
+}
+
+// Partition is the partition assignment returned by the admin API
+type Partition struct {
+	Namespace   string    `json:"ns"`
+	Topic       string    `json:"topic"`
+	PartitionID int       `json:"partition_id"`
+	Replicas    []Replica `json:"replicas"`
+	LeaderID    int       `json:"leader_id"`
+}
+
+// PartitionSummary is a partition of the cluster wide partition list
+// returned by the admin API
+type PartitionSummary struct {
+	Namespace   string `json:"ns"`
+	Topic       string `json:"topic"`
+	PartitionID int    `json:"partition_id"`
+	Leader      int    `json:"leader"`
+}
+
+// Replica is a placement of a partition replica on a broker core
+type Replica struct {
+	NodeID int `json:"node_id"`
+	Core   int `json:"core"`
+}
+
+// ResourceUsage is the CPU and memory usage of a broker summed over its
+// shards
+type ResourceUsage struct {
+	// CPUCores are the cores the reactors of the broker are busy on
+	CPUCores float64
+	// MemoryBytes are the bytes allocated by the broker
+	MemoryBytes int64
+}
+
+// ConfigPropertySchema describes a cluster property in the configuration
+// schema returned by the admin API
+type ConfigPropertySchema struct {
+	Type         string `json:"type"`
+	NeedsRestart bool   `json:"needs_restart"`
+}
+
+type newUser struct {
+	User      string `json:"username"`
+	Password  string `json:"password"`
+	Algorithm string `json:"algorithm"`
+}
+
+// NewInternalAdminAPI creates an AdminAPIClient that talks to each broker
+// of the cluster through the internal (headless service) address
+func NewInternalAdminAPI(
+	ctx context.Context,
+	k8sClient client.Reader,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	fqdn string,
+	adminTLSProvider AdminTLSConfigProvider,
+) (AdminAPIClient, error) {
+	var tlsConfig *tls.Config
+	if redpandaCluster.Spec.Configuration.TLS.AdminAPI.Enabled {
+		var err error
+		tlsConfig, err = adminTLSProvider.GetTLSConfig(ctx, k8sClient)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create admin API TLS configuration: %w", err)
+		}
+	}
+
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+
+	replicas := 0
+	if redpandaCluster.Spec.Replicas != nil {
+		replicas = int(*redpandaCluster.Spec.Replicas)
+	}
+	// the configurator sets the node ID of a broker to its Pod ordinal
+	urls := make(map[int]string, replicas)
+	for i := 0; i < replicas; i++ {
+		host := fmt.Sprintf("%s-%d.%s", redpandaCluster.Name, i, fqdn)
+		if network := redpandaCluster.Spec.Configuration.AdminAPIBindNetwork; network != "" {
+			host = managementAddress(ctx, k8sClient, redpandaCluster, i, network, host)
+		}
+		urls[i] = fmt.Sprintf("%s://%s:%d",
+			scheme,
+			host,
+			redpandaCluster.Spec.Configuration.AdminAPI.Port)
+	}
+
+	return newAdminAPI(urls, tlsConfig), nil
+}
+
+// networkStatus is an entry of the network status annotation that Multus
+// compatible CNI plugins set on Pods
+type networkStatus struct {
+	Name string   `json:"name"`
+	IPs  []string `json:"ips"`
+}
+
+// managementAddress returns the address of the broker Pod in the management
+// network the Admin API binds to. The Pod may not exist yet or the network
+// status may not be reported, in which case the fallback is returned.
+func managementAddress(
+	ctx context.Context,
+	k8sClient client.Reader,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	ordinal int,
+	network string,
+	fallback string,
+) string {
+	_, ipNet, err := net.ParseCIDR(network)
+	if err != nil {
+		return fallback
+	}
+	var pod corev1.Pod
+	err = k8sClient.Get(ctx, types.NamespacedName{
+		Name:      fmt.Sprintf("%s-%d", redpandaCluster.Name, ordinal),
+		Namespace: redpandaCluster.Namespace,
+	}, &pod)
+	if err != nil {
+		return fallback
+	}
+	var statuses []networkStatus
+	if err := json.Unmarshal([]byte(pod.Annotations[NetworkStatusAnnotation]), &statuses); err != nil {
+		return fallback
+	}
+	for _, status := range statuses {
+		for _, ip := range status.IPs {
+			if parsed := net.ParseIP(ip); parsed != nil && ipNet.Contains(parsed) {
+				return ip
+			}
+		}
+	}
+	return fallback
+}
+
+// NewAdminAPI creates an AdminAPIClient for the given broker URLs keyed by
+// the node ID of the broker
+func NewAdminAPI(urls map[int]string, tlsConfig *tls.Config) AdminAPIClient {
+	return newAdminAPI(urls, tlsConfig)
+}
+
+func newAdminAPI(urls map[int]string, tlsConfig *tls.Config) *adminAPI {
+	nodeIDs := make([]int, 0, len(urls))
+	for id := range urls {
+		nodeIDs = append(nodeIDs, id)
+	}
+	sort.Ints(nodeIDs)
+	return &adminAPI{
+		urls:    urls,
+		nodeIDs: nodeIDs,
+		client: &http.Client{
+			Timeout: defaultTimeout,
+			Transport: &http.Transport{
+				TLSClientConfig: tlsConfig,
+			},
+		},
+	}
+}
+
+type adminAPI struct {
+	urls map[int]string
+	// nodeIDs are the keys of urls in the order sendAny tries them
+	nodeIDs []int
+	client  *http.Client
+}
+
+func (a *adminAPI) Brokers(ctx context.Context) ([]Broker, error) {
+	return nil, unsupported(http.MethodGet, brokersEndpoint)
+}
+
+func (a *adminAPI) DecommissionBroker(ctx context.Context, nodeID int) error {
+	return unsupported(http.MethodPut, fmt.Sprintf("%s/%d/decommission", brokersEndpoint, nodeID))
+}
+
+func (a *adminAPI) RecommissionBroker(ctx context.Context, nodeID int) error {
+	return unsupported(http.MethodPut, fmt.Sprintf("%s/%d/recommission", brokersEndpoint, nodeID))
+}
+
+func (a *adminAPI) ClusterHealth(ctx context.Context) (ClusterHealthOverview, error) {
+	return ClusterHealthOverview{}, unsupported(http.MethodGet, clusterHealthEndpoint)
+}
+
+func (a *adminAPI) ClusterUUID(ctx context.Context) (string, error) {
+	return "", unsupported(http.MethodGet, clusterUUIDEndpoint)
+}
+
+func (a *adminAPI) ClusterConfig(ctx context.Context) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+	return config, a.sendAny(ctx, http.MethodGet, configEndpoint, nil, &config)
+}
+
+func (a *adminAPI) ClusterConfigSchema(ctx context.Context) (map[string]ConfigPropertySchema, error) {
+	return nil, unsupported(http.MethodGet, configSchemaEndpoint)
+}
+
+func (a *adminAPI) SetConfig(
+	ctx context.Context, upsert map[string]interface{}, remove []string,
+) error {
+	return unsupported(http.MethodPut, clusterConfigEndpoint)
+}
+
+func (a *adminAPI) EnableMaintenanceMode(ctx context.Context, nodeID int) error {
+	return unsupported(http.MethodPut, fmt.Sprintf("%s/%d/maintenance", brokersEndpoint, nodeID))
+}
+
+func (a *adminAPI) DisableMaintenanceMode(ctx context.Context, nodeID int) error {
+	return unsupported(http.MethodDelete, fmt.Sprintf("%s/%d/maintenance", brokersEndpoint, nodeID))
+}
+
+func (a *adminAPI) MaintenanceStatus(ctx context.Context, nodeID int) (MaintenanceStatus, error) {
+	return MaintenanceStatus{}, unsupported(http.MethodGet, fmt.Sprintf("%s/%d", brokersEndpoint, nodeID))
+}
+
+func (a *adminAPI) GetPartitions(
+	ctx context.Context, namespace, topic string,
+) ([]Partition, error) {
+	return nil, unsupported(http.MethodGet, fmt.Sprintf("%s/%s/%s", partitionsEndpoint, namespace, topic))
+}
+
+func (a *adminAPI) ListPartitions(ctx context.Context) ([]PartitionSummary, error) {
+	return nil, unsupported(http.MethodGet, partitionsEndpoint)
+}
+
+func (a *adminAPI) SetPartitionReplicas(
+	ctx context.Context, namespace, topic string, partition int, replicas []Replica,
+) error {
+	return unsupported(http.MethodPost, fmt.Sprintf("%s/%s/%s/%d/replicas", partitionsEndpoint, namespace, topic, partition))
+}
+
+func (a *adminAPI) ListUsers(ctx context.Context) ([]string, error) {
+	var users []string
+	return users, a.sendAny(ctx, http.MethodGet, usersEndpoint, nil, &users)
+}
+
+func (a *adminAPI) CreateUser(
+	ctx context.Context, username, password, mechanism string,
+) error {
+	return a.sendAny(ctx, http.MethodPost, usersEndpoint, newUser{username, password, mechanism}, nil)
+}
+
+func (a *adminAPI) UpdateUser(
+	ctx context.Context, username, password, mechanism string,
+) error {
+	return a.sendAny(ctx, http.MethodPut, fmt.Sprintf("%s/%s", usersEndpoint, url.PathEscape(username)), newUser{username, password, mechanism}, nil)
+}
+
+func (a *adminAPI) TransferLeadership(
+	ctx context.Context, leaderID, group, targetID int,
+) error {
+	return a.sendToNode(ctx, leaderID, http.MethodPost, fmt.Sprintf("%s/%d/transfer_leadership?target=%d", raftEndpoint, group, targetID), nil, nil)
+}
+
+func (a *adminAPI) ResourceUsage(ctx context.Context, nodeID int) (ResourceUsage, error) {
+	var metrics []byte
+	if err := a.sendToNode(ctx, nodeID, http.MethodGet, metricsEndpoint, nil, &metrics); err != nil {
+		return ResourceUsage{}, err
+	}
+	return parseResourceUsage(metrics)
+}
+
+// parseResourceUsage sums the reactor utilization and the allocated memory
+// of every shard in the Prometheus text exposition of the broker metrics
+func parseResourceUsage(metrics []byte) (ResourceUsage, error) {
+	var usage ResourceUsage
+	var cpu, memory bool
+	for _, line := range strings.Split(string(metrics), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, rest := line, ""
+		if i := strings.IndexAny(line, "{ "); i >= 0 {
+			name, rest = line[:i], line[i:]
+		}
+		if name != reactorUtilizationMetric && name != allocatedMemoryMetric {
+			continue
+		}
+		// the value follows the labels
+		if i := strings.LastIndex(rest, "}"); i >= 0 {
+			rest = rest[i+1:]
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return ResourceUsage{}, fmt.Errorf("unable to parse %s: %w", name, err)
+		}
+		if name == reactorUtilizationMetric {
+			// the utilization is reported in percent of a core
+			usage.CPUCores += value / 100
+			cpu = true
+		} else {
+			usage.MemoryBytes += int64(value)
+			memory = true
+		}
+	}
+	if !cpu || !memory {
+		return ResourceUsage{}, ErrNoResourceMetrics
+	}
+	return usage, nil
+}
+
+// sendAny tries the brokers one after another until one of them answers
+func (a *adminAPI) sendAny(
+	ctx context.Context, method, path string, body, into interface{},
+) error {
+	if len(a.urls) == 0 {
+		return ErrNoAdminAPIURL
+	}
+	var err error
+	for _, id := range a.nodeIDs {
+		err = a.send(ctx, method, a.urls[id]+path, body, into)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (a *adminAPI) sendToNode(
+	ctx context.Context, nodeID int, method, path string, body, into interface{},
+) error {
+	nodeURL, ok := a.urls[nodeID]
+	if !ok {
+		return fmt.Errorf("node %d: %w", nodeID, ErrUnknownNode)
+	}
+	return a.send(ctx, method, nodeURL+path, body, into)
+}
+
+// unsupported is the error of a call to an endpoint this Redpanda version
+// does not serve
+func unsupported(method, path string) error {
+	return fmt.Errorf("%s %s: %w", method, path, ErrUnsupported)
+}
+
+func (a *adminAPI) send(
+	ctx context.Context, method, target string, body, into interface{},
+) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return fmt.Errorf("unable to encode request body for %s: %w", target, err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, &reqBody)
+	if err != nil {
+		return fmt.Errorf("unable to create request for %s: %w", target, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request %s %s failed: %w", method, target, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read response of %s %s: %w", method, target, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &HTTPResponseError{Method: method, URL: target, StatusCode: resp.StatusCode, Body: respBody}
+	}
+
+	if into == nil || len(respBody) == 0 {
+		return nil
+	}
+	if raw, ok := into.(*[]byte); ok {
+		*raw = respBody
+		return nil
+	}
+	if err := json.Unmarshal(respBody, into); err != nil {
+		return fmt.Errorf("unable to decode response of %s %s: %w", method, target, err)
+	}
+	return nil
+}
+
+// HTTPResponseError is returned when the admin API answers with a non 2xx code
+type HTTPResponseError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       []byte
+}
+
+func (e *HTTPResponseError) Error() string {
+	return fmt.Sprintf("request %s %s failed: %s, body: %q", e.Method, e.URL, http.StatusText(e.StatusCode), e.Body)
+}
+
+// IsNotFound returns true when the admin API reports that the requested
+// object does not exist
+func IsNotFound(err error) bool {
+	var httpErr *HTTPResponseError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
+
+// IsUnsupported returns true when the Admin API of this Redpanda version
+// does not serve the endpoint of the call
+func IsUnsupported(err error) bool {
+	return errors.Is(err, ErrUnsupported)
+}
+
+// IsUnauthorized returns true when the admin API rejects the credentials of
+// the request
+func IsUnauthorized(err error) bool {
+	var httpErr *HTTPResponseError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnauthorized
+}
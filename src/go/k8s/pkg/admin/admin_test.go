@@ -0,0 +1,202 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAdminAPIFallsBackToNextBroker(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/config", r.URL.Path)
+		_, _ = w.Write([]byte(`{"enable_idempotence":true}`))
+	}))
+	defer healthy.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	a := admin.NewAdminAPI(map[int]string{0: broken.URL, 1: healthy.URL}, nil)
+	config, err := a.ClusterConfig(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"enable_idempotence": true}, config)
+}
+
+func TestAdminAPIErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	a := admin.NewAdminAPI(map[int]string{0: srv.URL}, nil)
+	err := a.CreateUser(context.Background(), "user", "password", "SCRAM-SHA-256")
+	var httpErr *admin.HTTPResponseError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+
+	err = a.TransferLeadership(context.Background(), 3, admin.ControllerRaftGroup, 0)
+	assert.True(t, errors.Is(err, admin.ErrUnknownNode))
+
+	_, err = admin.NewAdminAPI(nil, nil).ClusterConfig(context.Background())
+	assert.True(t, errors.Is(err, admin.ErrNoAdminAPIURL))
+}
+
+func TestAdminAPIRoutesByNodeID(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		got = r.URL.Path + "?" + r.URL.RawQuery
+	}))
+	defer srv.Close()
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("the request went to the wrong broker")
+	}))
+	defer other.Close()
+
+	a := admin.NewAdminAPI(map[int]string{2: other.URL, 5: srv.URL}, nil)
+	require.NoError(t, a.TransferLeadership(context.Background(), 5, admin.ControllerRaftGroup, 2))
+	assert.Equal(t, "/v1/raft/0/transfer_leadership?target=2", got)
+}
+
+func TestAdminAPIUnsupported(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	stubbed := map[string]func(admin.AdminAPIClient) error{
+		"Brokers":                func(a admin.AdminAPIClient) error { _, err := a.Brokers(ctx); return err },
+		"DecommissionBroker":     func(a admin.AdminAPIClient) error { return a.DecommissionBroker(ctx, 0) },
+		"RecommissionBroker":     func(a admin.AdminAPIClient) error { return a.RecommissionBroker(ctx, 0) },
+		"ClusterHealth":          func(a admin.AdminAPIClient) error { _, err := a.ClusterHealth(ctx); return err },
+		"ClusterUUID":            func(a admin.AdminAPIClient) error { _, err := a.ClusterUUID(ctx); return err },
+		"ClusterConfigSchema":    func(a admin.AdminAPIClient) error { _, err := a.ClusterConfigSchema(ctx); return err },
+		"SetConfig":              func(a admin.AdminAPIClient) error { return a.SetConfig(ctx, nil, nil) },
+		"EnableMaintenanceMode":  func(a admin.AdminAPIClient) error { return a.EnableMaintenanceMode(ctx, 0) },
+		"DisableMaintenanceMode": func(a admin.AdminAPIClient) error { return a.DisableMaintenanceMode(ctx, 0) },
+		"MaintenanceStatus":      func(a admin.AdminAPIClient) error { _, err := a.MaintenanceStatus(ctx, 0); return err },
+		"GetPartitions":          func(a admin.AdminAPIClient) error { _, err := a.GetPartitions(ctx, "kafka", "topic"); return err },
+		"ListPartitions":         func(a admin.AdminAPIClient) error { _, err := a.ListPartitions(ctx); return err },
+		"SetPartitionReplicas":   func(a admin.AdminAPIClient) error { return a.SetPartitionReplicas(ctx, "kafka", "topic", 0, nil) },
+	}
+
+	// the stubbed mock answers like the client of this Redpanda version
+	a := admin.NewAdminAPI(map[int]string{0: srv.URL}, nil)
+	m := admin.NewMockAdminAPI()
+	m.Stubbed = true
+	for name, call := range stubbed {
+		err := call(a)
+		assert.True(t, admin.IsUnsupported(err), name)
+		assert.False(t, admin.IsNotFound(err), name)
+		assert.Equal(t, err, call(m), name)
+	}
+}
+
+func TestAdminAPIResourceUsage(t *testing.T) {
+	metrics := `# HELP vectorized_reactor_utilization CPU busy ratio
+# TYPE vectorized_reactor_utilization gauge
+vectorized_reactor_utilization{shard="0"} 75.000000
+vectorized_reactor_utilization{shard="1"} 25.000000
+# TYPE vectorized_memory_allocated_memory counter
+vectorized_memory_allocated_memory{shard="0"} 1073741824.000000
+vectorized_memory_allocated_memory{shard="1"} 536870912.000000
+vectorized_memory_free_memory{shard="0"} 1073741824.000000
+`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/metrics", r.URL.Path)
+		_, _ = w.Write([]byte(metrics))
+	}))
+	defer srv.Close()
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("# no metrics\n"))
+	}))
+	defer empty.Close()
+
+	a := admin.NewAdminAPI(map[int]string{0: srv.URL, 1: empty.URL}, nil)
+	usage, err := a.ResourceUsage(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, admin.ResourceUsage{CPUCores: 1, MemoryBytes: 3 << 29}, usage)
+
+	_, err = a.ResourceUsage(context.Background(), 1)
+	assert.True(t, errors.Is(err, admin.ErrNoResourceMetrics))
+}
+
+func TestInternalAdminAPIUsesManagementAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+	port, err := strconv.Atoi(srv.URL[strings.LastIndex(srv.URL, ":")+1:])
+	require.NoError(t, err)
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Replicas: pointer.Int32Ptr(1),
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				AdminAPI:            redpandav1alpha1.SocketAddress{Port: port},
+				AdminAPIBindNetwork: "127.0.0.0/8",
+			},
+		},
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "cluster-0",
+		Namespace: "default",
+		Annotations: map[string]string{
+			admin.NetworkStatusAnnotation: `[{"name":"default","ips":["10.0.0.5"]},{"name":"management","ips":["127.0.0.1"]}]`,
+		},
+	}}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+
+	// the headless service address does not resolve, so the call only
+	// succeeds through the management address
+	a, err := admin.NewInternalAdminAPI(context.Background(), c, cluster, "cluster.invalid", nil)
+	require.NoError(t, err)
+	_, err = a.ListUsers(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestMockAdminAPI(t *testing.T) {
+	m := admin.NewMockAdminAPI()
+	m.BrokersResponse = []admin.Broker{{NodeID: 0, MembershipStatus: "active"}}
+
+	require.NoError(t, m.DecommissionBroker(context.Background(), 0))
+	brokers, err := m.Brokers(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "draining", brokers[0].MembershipStatus)
+	assert.Equal(t, []int{0}, m.Decommissioned)
+
+	require.NoError(t, m.SetConfig(context.Background(), map[string]interface{}{"a": 1}, nil))
+	config, err := m.ClusterConfig(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": 1}, config)
+
+	m.Err = errors.New("boom")
+	_, err = m.ClusterHealth(context.Background())
+	assert.Error(t, err)
+}
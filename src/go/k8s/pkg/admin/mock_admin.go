@@ -0,0 +1,401 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ AdminAPIClient = &MockAdminAPI{}
+
+// MockAdminAPI is an in-memory AdminAPIClient for tests. Responses are
+// programmed through the exported fields and every call is recorded.
+type MockAdminAPI struct {
+	mu sync.Mutex
+
+	BrokersResponse []Broker
+	Health          ClusterHealthOverview
+	// UUID is the cluster UUID, when empty ClusterUUID answers
+	// ErrUnsupported like this Redpanda version
+	UUID   string
+	Config map[string]interface{}
+	// Schema is the configuration schema, when nil ClusterConfigSchema
+	// answers ErrUnsupported like this Redpanda version
+	Schema map[string]ConfigPropertySchema
+	// Partitions are keyed by namespace/topic
+	Partitions map[string][]Partition
+	// Usage is the resource usage keyed by node ID, brokers without usage
+	// answer not found
+	Usage map[int]ResourceUsage
+	// Users maps SASL user names to their passwords
+	Users map[string]string
+	// Err, when set, is returned by every call
+	Err error
+	// Stubbed answers ErrUnsupported from every endpoint the client of this
+	// Redpanda version does not serve, like that client
+	Stubbed bool
+
+	Decommissioned []int
+	Recommissioned []int
+	Maintenance    map[int]bool
+	ConfigWrites   int
+	// Transfers records leadership transfers as group/leader/target
+	Transfers []string
+}
+
+// NewMockAdminAPI creates a MockAdminAPI reporting a healthy cluster
+func NewMockAdminAPI() *MockAdminAPI {
+	return &MockAdminAPI{
+		Health:      ClusterHealthOverview{IsHealthy: true},
+		Config:      map[string]interface{}{},
+		Maintenance: map[int]bool{},
+		Partitions:  map[string][]Partition{},
+		Users:       map[string]string{},
+	}
+}
+
+// Factory returns an AdminAPIClientFactory that always hands out this mock
+func (m *MockAdminAPI) Factory() AdminAPIClientFactory {
+	return func(
+		_ context.Context,
+		_ client.Reader,
+		_ *redpandav1alpha1.Cluster,
+		_ string,
+		_ AdminTLSConfigProvider,
+	) (AdminAPIClient, error) {
+		return m, nil
+	}
+}
+
+// Brokers returns the programmed broker list
+func (m *MockAdminAPI) Brokers(_ context.Context) ([]Broker, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if m.Stubbed {
+		return nil, unsupported(http.MethodGet, brokersEndpoint)
+	}
+	return append([]Broker{}, m.BrokersResponse...), nil
+}
+
+// DecommissionBroker records the call and marks the broker as draining
+func (m *MockAdminAPI) DecommissionBroker(_ context.Context, nodeID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if m.Stubbed {
+		return unsupported(http.MethodPut, fmt.Sprintf("%s/%d/decommission", brokersEndpoint, nodeID))
+	}
+	m.Decommissioned = append(m.Decommissioned, nodeID)
+	m.setMembershipStatus(nodeID, "draining")
+	return nil
+}
+
+// RecommissionBroker records the call and marks the broker as active
+func (m *MockAdminAPI) RecommissionBroker(_ context.Context, nodeID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if m.Stubbed {
+		return unsupported(http.MethodPut, fmt.Sprintf("%s/%d/recommission", brokersEndpoint, nodeID))
+	}
+	m.Recommissioned = append(m.Recommissioned, nodeID)
+	m.setMembershipStatus(nodeID, "active")
+	return nil
+}
+
+// ClusterHealth returns the programmed health overview
+func (m *MockAdminAPI) ClusterHealth(_ context.Context) (ClusterHealthOverview, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return ClusterHealthOverview{}, m.Err
+	}
+	if m.Stubbed {
+		return ClusterHealthOverview{}, unsupported(http.MethodGet, clusterHealthEndpoint)
+	}
+	return m.Health, nil
+}
+
+// ClusterUUID returns the programmed cluster UUID
+func (m *MockAdminAPI) ClusterUUID(_ context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return "", m.Err
+	}
+	if m.Stubbed || m.UUID == "" {
+		return "", unsupported(http.MethodGet, clusterUUIDEndpoint)
+	}
+	return m.UUID, nil
+}
+
+// ClusterConfig returns a copy of the stored cluster configuration
+func (m *MockAdminAPI) ClusterConfig(_ context.Context) (map[string]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	config := make(map[string]interface{}, len(m.Config))
+	for k, v := range m.Config {
+		config[k] = v
+	}
+	return config, nil
+}
+
+// ClusterConfigSchema returns the programmed configuration schema
+func (m *MockAdminAPI) ClusterConfigSchema(_ context.Context) (map[string]ConfigPropertySchema, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if m.Stubbed || m.Schema == nil {
+		return nil, unsupported(http.MethodGet, configSchemaEndpoint)
+	}
+	return m.Schema, nil
+}
+
+// SetConfig applies the changes to the stored cluster configuration
+func (m *MockAdminAPI) SetConfig(
+	_ context.Context, upsert map[string]interface{}, remove []string,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if m.Stubbed {
+		return unsupported(http.MethodPut, clusterConfigEndpoint)
+	}
+	if m.Config == nil {
+		m.Config = map[string]interface{}{}
+	}
+	for k, v := range upsert {
+		m.Config[k] = v
+	}
+	for _, k := range remove {
+		delete(m.Config, k)
+	}
+	m.ConfigWrites++
+	return nil
+}
+
+// EnableMaintenanceMode marks the broker as in maintenance
+func (m *MockAdminAPI) EnableMaintenanceMode(_ context.Context, nodeID int) error {
+	return m.setMaintenance(nodeID, true)
+}
+
+// DisableMaintenanceMode marks the broker as out of maintenance
+func (m *MockAdminAPI) DisableMaintenanceMode(_ context.Context, nodeID int) error {
+	return m.setMaintenance(nodeID, false)
+}
+
+// MaintenanceStatus reports the leadership of a broker in maintenance as
+// drained right away
+func (m *MockAdminAPI) MaintenanceStatus(_ context.Context, nodeID int) (MaintenanceStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return MaintenanceStatus{}, m.Err
+	}
+	if m.Stubbed {
+		return MaintenanceStatus{}, unsupported(http.MethodGet, fmt.Sprintf("%s/%d", brokersEndpoint, nodeID))
+	}
+	return MaintenanceStatus{Draining: m.Maintenance[nodeID], Finished: m.Maintenance[nodeID]}, nil
+}
+
+// GetPartitions returns the programmed partitions or a not found error
+func (m *MockAdminAPI) GetPartitions(
+	_ context.Context, namespace, topic string,
+) ([]Partition, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if m.Stubbed {
+		return nil, unsupported(http.MethodGet, fmt.Sprintf("%s/%s/%s", partitionsEndpoint, namespace, topic))
+	}
+	partitions, ok := m.Partitions[namespace+"/"+topic]
+	if !ok {
+		return nil, &HTTPResponseError{Method: http.MethodGet, URL: namespace + "/" + topic, StatusCode: http.StatusNotFound}
+	}
+	return append([]Partition{}, partitions...), nil
+}
+
+// ListPartitions summarizes the programmed partitions of every topic
+func (m *MockAdminAPI) ListPartitions(_ context.Context) ([]PartitionSummary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if m.Stubbed {
+		return nil, unsupported(http.MethodGet, partitionsEndpoint)
+	}
+	var summaries []PartitionSummary
+	for _, partitions := range m.Partitions {
+		for _, p := range partitions {
+			summaries = append(summaries, PartitionSummary{
+				Namespace:   p.Namespace,
+				Topic:       p.Topic,
+				PartitionID: p.PartitionID,
+				Leader:      p.LeaderID,
+			})
+		}
+	}
+	return summaries, nil
+}
+
+// SetPartitionReplicas replaces replicas of the stored partition
+func (m *MockAdminAPI) SetPartitionReplicas(
+	_ context.Context, namespace, topic string, partition int, replicas []Replica,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if m.Stubbed {
+		return unsupported(http.MethodPost, fmt.Sprintf("%s/%s/%s/%d/replicas", partitionsEndpoint, namespace, topic, partition))
+	}
+	partitions := m.Partitions[namespace+"/"+topic]
+	for i := range partitions {
+		if partitions[i].PartitionID == partition {
+			partitions[i].Replicas = append([]Replica{}, replicas...)
+			return nil
+		}
+	}
+	return &HTTPResponseError{Method: http.MethodPost, URL: fmt.Sprintf("%s/%s/%d", namespace, topic, partition), StatusCode: http.StatusNotFound}
+}
+
+// ListUsers returns the names of the stored users
+func (m *MockAdminAPI) ListUsers(_ context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	users := make([]string, 0, len(m.Users))
+	for user := range m.Users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// CreateUser stores the user or fails when it already exists
+func (m *MockAdminAPI) CreateUser(
+	_ context.Context, username, password, _ string,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if _, ok := m.Users[username]; ok {
+		return &HTTPResponseError{Method: http.MethodPost, URL: username, StatusCode: http.StatusBadRequest}
+	}
+	if m.Users == nil {
+		m.Users = map[string]string{}
+	}
+	m.Users[username] = password
+	return nil
+}
+
+// UpdateUser replaces the password of a stored user or fails when it does
+// not exist
+func (m *MockAdminAPI) UpdateUser(
+	_ context.Context, username, password, _ string,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if _, ok := m.Users[username]; !ok {
+		return &HTTPResponseError{Method: http.MethodPut, URL: username, StatusCode: http.StatusNotFound}
+	}
+	m.Users[username] = password
+	return nil
+}
+
+// TransferLeadership records the call and moves the controller leadership
+// of the programmed health overview
+func (m *MockAdminAPI) TransferLeadership(
+	_ context.Context, leaderID, group, targetID int,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	m.Transfers = append(m.Transfers, fmt.Sprintf("%d/%d/%d", group, leaderID, targetID))
+	if group == ControllerRaftGroup && m.Health.ControllerID == leaderID {
+		m.Health.ControllerID = targetID
+	}
+	return nil
+}
+
+// ResourceUsage returns the programmed resource usage of the broker or a
+// not found error
+func (m *MockAdminAPI) ResourceUsage(_ context.Context, nodeID int) (ResourceUsage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return ResourceUsage{}, m.Err
+	}
+	usage, ok := m.Usage[nodeID]
+	if !ok {
+		return ResourceUsage{}, &HTTPResponseError{Method: http.MethodGet, URL: metricsEndpoint, StatusCode: http.StatusNotFound}
+	}
+	return usage, nil
+}
+
+func (m *MockAdminAPI) setMaintenance(nodeID int, enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if m.Stubbed {
+		method := http.MethodPut
+		if !enabled {
+			method = http.MethodDelete
+		}
+		return unsupported(method, fmt.Sprintf("%s/%d/maintenance", brokersEndpoint, nodeID))
+	}
+	if m.Maintenance == nil {
+		m.Maintenance = map[int]bool{}
+	}
+	m.Maintenance[nodeID] = enabled
+	return nil
+}
+
+func (m *MockAdminAPI) setMembershipStatus(nodeID int, status string) {
+	for i := range m.BrokersResponse {
+		if m.BrokersResponse[i].NodeID == nodeID {
+			m.BrokersResponse[i].MembershipStatus = status
+		}
+	}
+}
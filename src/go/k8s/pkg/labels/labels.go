@@ -44,6 +44,55 @@ func ForCluster(cluster *redpandav1alpha1.Cluster) CommonLabels {
 	return labels
 }
 
+// ForConsole returns the labels of the Redpanda Console resources of the
+// cluster. The component differs from ForCluster, so the selectors of the
+// brokers do not match the Console pods.
+func ForConsole(cluster *redpandav1alpha1.Cluster) CommonLabels {
+	labels := make(CommonLabels)
+	for k, v := range ForCluster(cluster) {
+		labels[k] = v
+	}
+	labels[ComponentKey] = "console"
+
+	return labels
+}
+
+// ForNodeTuning returns the labels of the node tuning DaemonSet of the
+// cluster, whose component differs from the brokers like that of the Console
+func ForNodeTuning(cluster *redpandav1alpha1.Cluster) CommonLabels {
+	labels := make(CommonLabels)
+	for k, v := range ForCluster(cluster) {
+		labels[k] = v
+	}
+	labels[ComponentKey] = "node-tuning"
+
+	return labels
+}
+
+// ForImagePrePull returns the labels of the DaemonSet pulling the image of
+// an upgrade on the nodes of the brokers
+func ForImagePrePull(cluster *redpandav1alpha1.Cluster) CommonLabels {
+	labels := make(CommonLabels)
+	for k, v := range ForCluster(cluster) {
+		labels[k] = v
+	}
+	labels[ComponentKey] = "image-prepull"
+
+	return labels
+}
+
+// ForReplication returns the labels of the MirrorMaker Deployment
+// replicating into the cluster
+func ForReplication(cluster *redpandav1alpha1.Cluster) CommonLabels {
+	labels := make(CommonLabels)
+	for k, v := range ForCluster(cluster) {
+		labels[k] = v
+	}
+	labels[ComponentKey] = "replication"
+
+	return labels
+}
+
 // AsClientSelector returns label selector made out of subset of common labels: name, instance, component
 // return type is apimachinery labels selector, which is used when constructing client calls
 func (cl CommonLabels) AsClientSelector() k8slabels.Selector {
@@ -56,6 +105,16 @@ func (cl CommonLabels) AsAPISelector() *metav1.LabelSelector {
 	return metav1.SetAsLabelSelector(cl.selectorLabels())
 }
 
+// AsOwnerSelector returns label selector made out of subset of common labels: name, instance, managed-by
+// It selects every object generated for the cluster, whatever its component
+func (cl CommonLabels) AsOwnerSelector() k8slabels.Selector {
+	return k8slabels.SelectorFromSet(k8slabels.Set{
+		NameKey:      cl[NameKey],
+		InstanceKey:  cl[InstanceKey],
+		ManagedByKey: cl[ManagedByKey],
+	})
+}
+
 // AsSet returns common labels with types labels.Set
 func (cl CommonLabels) AsSet() k8slabels.Set {
 	var mapLabels map[string]string = cl
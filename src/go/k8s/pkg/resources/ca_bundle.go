@@ -0,0 +1,192 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	caBundleSuffix = "-ca"
+
+	// CABundleKafkaAPIKey is the key of the Kafka API CA certificate in the
+	// CA bundle ConfigMap
+	CABundleKafkaAPIKey = cmetav1.TLSCAKey
+	// CABundleAdminAPIKey is the key of the Admin API CA certificate in the
+	// CA bundle ConfigMap
+	CABundleAdminAPIKey = "admin-api-ca.crt"
+)
+
+var _ Resource = &CABundleResource{}
+
+// CABundleResource publishes the CA certificates of the TLS listeners in a
+// ConfigMap named <cluster>-ca, so clients can mount it instead of extracting
+// the CA from the certificate Secrets. The certificate Secrets are watched,
+// so the ConfigMap follows rotations of the CA.
+type CABundleResource struct {
+	k8sclient.Client
+	scheme           *runtime.Scheme
+	pandaCluster     *redpandav1alpha1.Cluster
+	kafkaNodeCert    types.NamespacedName
+	adminAPINodeCert types.NamespacedName
+	logger           logr.Logger
+}
+
+// NewCABundle creates CABundleResource
+func NewCABundle(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	kafkaNodeCert types.NamespacedName,
+	adminAPINodeCert types.NamespacedName,
+	logger logr.Logger,
+) *CABundleResource {
+	return &CABundleResource{
+		client,
+		scheme,
+		pandaCluster,
+		kafkaNodeCert,
+		adminAPINodeCert,
+		logger.WithValues("Kind", "ConfigMap", "Reconciler", "CA bundle"),
+	}
+}
+
+// Ensure creates or updates the CA bundle ConfigMap when a listener uses TLS,
+// deletes it otherwise and records it in the Cluster status
+func (r *CABundleResource) Ensure(ctx context.Context) error {
+	tls := r.pandaCluster.Spec.Configuration.TLS
+	if !tls.KafkaAPI.Enabled && !tls.AdminAPI.Enabled {
+		if err := r.deleteIfExists(ctx); err != nil {
+			return err
+		}
+		return r.setStatus(ctx, "")
+	}
+
+	obj, err := r.obj(ctx)
+	if err != nil {
+		return err
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil {
+		return err
+	}
+	if !created {
+		var cm corev1.ConfigMap
+		if err := r.Get(ctx, r.Key(), &cm); err != nil {
+			return fmt.Errorf("error while fetching CA bundle ConfigMap: %w", err)
+		}
+		if err := Update(ctx, &cm, obj, r.Client, r.logger); err != nil {
+			return err
+		}
+	}
+	return r.setStatus(ctx, r.Key().Name)
+}
+
+func (r *CABundleResource) setStatus(ctx context.Context, name string) error {
+	if r.pandaCluster.Status.CABundle == name {
+		return nil
+	}
+	r.pandaCluster.Status.CABundle = name
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return fmt.Errorf("unable to update CA bundle status: %w", err)
+	}
+	return nil
+}
+
+func (r *CABundleResource) deleteIfExists(ctx context.Context) error {
+	var cm corev1.ConfigMap
+	err := r.Get(ctx, r.Key(), &cm)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching CA bundle ConfigMap: %w", err)
+	}
+	if !IsManagedBy(r.pandaCluster, &cm) {
+		return nil
+	}
+	r.logger.Info("Deleting the CA bundle as TLS is disabled")
+	if err := r.Delete(ctx, &cm); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete CA bundle ConfigMap: %w", err)
+	}
+	return nil
+}
+
+func (r *CABundleResource) obj(ctx context.Context) (k8sclient.Object, error) {
+	tls := r.pandaCluster.Spec.Configuration.TLS
+	data := map[string]string{}
+	if tls.KafkaAPI.Enabled {
+		ca, err := r.caCertificate(ctx, r.kafkaNodeCert)
+		if err != nil {
+			return nil, err
+		}
+		data[CABundleKafkaAPIKey] = ca
+	}
+	if tls.AdminAPI.Enabled {
+		ca, err := r.caCertificate(ctx, r.adminAPINodeCert)
+		if err != nil {
+			return nil, err
+		}
+		data[CABundleAdminAPIKey] = ca
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    labels.ForCluster(r.pandaCluster),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		Data: data,
+	}
+
+	err := SetOwner(r.pandaCluster, cm, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+	return cm, nil
+}
+
+// caCertificate returns the CA certificate stored next to a node certificate
+func (r *CABundleResource) caCertificate(
+	ctx context.Context, nodeCert types.NamespacedName,
+) (string, error) {
+	var secret corev1.Secret
+	err := r.Get(ctx, nodeCert, &secret)
+	if apierrors.IsNotFound(err) {
+		return "", &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("waiting for the certificate %s", nodeCert.Name)}
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to fetch certificate %s: %w", nodeCert.Name, err)
+	}
+	return string(secret.Data[cmetav1.TLSCAKey]), nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *CABundleResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + caBundleSuffix, Namespace: r.pandaCluster.Namespace}
+}
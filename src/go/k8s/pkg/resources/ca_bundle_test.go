@@ -0,0 +1,74 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCABundleEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+	cluster.Spec.Configuration.TLS.AdminAPI.Enabled = true
+
+	nodeCert := types.NamespacedName{Name: "cluster-redpanda", Namespace: cluster.Namespace}
+	adminAPINodeCert := types.NamespacedName{Name: "cluster-admin-api-node", Namespace: cluster.Namespace}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	caBundle := res.NewCABundle(c, cluster, scheme.Scheme, nodeCert, adminAPINodeCert, ctrl.Log)
+
+	var requeue *res.RequeueAfterError
+	assert.True(t, errors.As(caBundle.Ensure(context.Background()), &requeue))
+
+	for _, key := range []types.NamespacedName{nodeCert, adminAPINodeCert} {
+		require.NoError(t, c.Create(context.Background(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Data:       map[string][]byte{"ca.crt": []byte(key.Name + " CA")},
+		}))
+	}
+	require.NoError(t, caBundle.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), caBundle.Key(), &actual))
+	assert.Equal(t, "cluster-ca", actual.Name)
+	assert.Equal(t, "cluster-redpanda CA", actual.Data[res.CABundleKafkaAPIKey])
+	assert.Equal(t, "cluster-admin-api-node CA", actual.Data[res.CABundleAdminAPIKey])
+	assert.Equal(t, "cluster-ca", cluster.Status.CABundle)
+
+	// a rotated CA is published on the next reconciliation
+	var secret corev1.Secret
+	require.NoError(t, c.Get(context.Background(), nodeCert, &secret))
+	secret.Data["ca.crt"] = []byte("rotated CA")
+	require.NoError(t, c.Update(context.Background(), &secret))
+	require.NoError(t, caBundle.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), caBundle.Key(), &actual))
+	assert.Equal(t, "rotated CA", actual.Data[res.CABundleKafkaAPIKey])
+
+	cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = false
+	cluster.Spec.Configuration.TLS.AdminAPI.Enabled = false
+	require.NoError(t, caBundle.Ensure(context.Background()))
+	assert.True(t, apierrors.IsNotFound(c.Get(context.Background(), caBundle.Key(), &actual)))
+	assert.Empty(t, cluster.Status.CABundle)
+}
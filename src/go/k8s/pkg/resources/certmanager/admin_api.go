@@ -10,9 +10,18 @@
 package certmanager
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
 	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -28,6 +37,51 @@ func (r *PkiReconciler) AdminAPINodeCert() types.NamespacedName {
 	return types.NamespacedName{Name: r.pandaCluster.Name + "-" + AdminAPINodeCert, Namespace: r.pandaCluster.Namespace}
 }
 
+// AdminAPIClientCert returns the namespaced name for the client certificate used to query the Admin API
+func (r *PkiReconciler) AdminAPIClientCert() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + "-" + AdminAPIClientCert, Namespace: r.pandaCluster.Namespace}
+}
+
+var errInvalidAdminAPICA = errors.New("unable to parse admin API CA certificate")
+
+// AdminAPIConfigProvider returns the provider of the TLS configuration used by
+// the operator to call the Admin API
+func (r *PkiReconciler) AdminAPIConfigProvider() admin.AdminTLSConfigProvider {
+	return r
+}
+
+// GetTLSConfig builds the TLS configuration trusting the Admin API node
+// certificate and, when client authentication is required, presenting the
+// Admin API client certificate
+func (r *PkiReconciler) GetTLSConfig(
+	ctx context.Context, k8sClient k8sclient.Reader,
+) (*tls.Config, error) {
+	var nodeCertSecret corev1.Secret
+	if err := k8sClient.Get(ctx, r.AdminAPINodeCert(), &nodeCertSecret); err != nil {
+		return nil, fmt.Errorf("unable to fetch admin API node certificate %s: %w", r.AdminAPINodeCert(), err)
+	}
+
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(nodeCertSecret.Data[cmmeta.TLSCAKey]) {
+		return nil, errInvalidAdminAPICA
+	}
+	tlsConfig := &tls.Config{RootCAs: caCertPool, MinVersion: tls.VersionTLS12}
+
+	if r.pandaCluster.Spec.Configuration.TLS.AdminAPI.RequireClientAuth {
+		var clientCertSecret corev1.Secret
+		if err := k8sClient.Get(ctx, r.AdminAPIClientCert(), &clientCertSecret); err != nil {
+			return nil, fmt.Errorf("unable to fetch admin API client certificate %s: %w", r.AdminAPIClientCert(), err)
+		}
+		cert, err := tls.X509KeyPair(clientCertSecret.Data[corev1.TLSCertKey], clientCertSecret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse admin API client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
 func (r *PkiReconciler) prepareAdminAPI(
 	issuerRef *cmmeta.ObjectReference,
 ) []resources.Resource {
@@ -37,13 +91,13 @@ func (r *PkiReconciler) prepareAdminAPI(
 	cn := NewCommonName(r.pandaCluster.Name, AdminAPINodeCert)
 	certsKey := types.NamespacedName{Name: string(cn), Namespace: r.pandaCluster.Namespace}
 
-	dnsName := r.internalFQDN
+	dnsNames := []string{r.internalFQDN}
 	externConn := r.pandaCluster.Spec.ExternalConnectivity
 	if externConn.Enabled && externConn.Subdomain != "" {
-		dnsName = externConn.Subdomain
+		dnsNames = r.pandaCluster.ExternalSubdomains()
 	}
 
-	nodeCert := NewNodeCertificate(r.Client, r.scheme, r.pandaCluster, certsKey, issuerRef, dnsName, cn, false, r.logger)
+	nodeCert := NewNodeCertificate(r.Client, r.scheme, r.pandaCluster, certsKey, issuerRef, dnsNames, cn, false, r.logger)
 	toApply = append(toApply, nodeCert)
 
 	if r.pandaCluster.Spec.Configuration.TLS.AdminAPI.RequireClientAuth {
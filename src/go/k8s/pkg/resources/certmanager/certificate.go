@@ -24,7 +24,6 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 var _ resources.Resource = &CertificateResource{}
@@ -40,26 +39,26 @@ type CertificateResource struct {
 	pandaCluster *redpandav1alpha1.Cluster
 	key          types.NamespacedName
 	issuerRef    *cmetav1.ObjectReference
-	fqdn         string
+	fqdns        []string
 	commonName   CommonName
 	isCA         bool
 	logger       logr.Logger
 }
 
-// NewNodeCertificate creates certificate with given FQDN that is either internal or external
+// NewNodeCertificate creates certificate with given FQDNs that are either internal or external
 func NewNodeCertificate(
 	client k8sclient.Client,
 	scheme *runtime.Scheme,
 	pandaCluster *redpandav1alpha1.Cluster,
 	key types.NamespacedName,
 	issuerRef *cmetav1.ObjectReference,
-	fqdn string,
+	fqdns []string,
 	commonName CommonName,
 	isCA bool,
 	logger logr.Logger,
 ) *CertificateResource {
 	return &CertificateResource{
-		client, scheme, pandaCluster, key, issuerRef, fqdn, commonName, isCA, logger.WithValues("Kind", certificateKind()),
+		client, scheme, pandaCluster, key, issuerRef, fqdns, commonName, isCA, logger.WithValues("Kind", certificateKind()),
 	}
 }
 
@@ -75,7 +74,7 @@ func NewCertificate(
 	logger logr.Logger,
 ) *CertificateResource {
 	return &CertificateResource{
-		client, scheme, pandaCluster, key, issuerRef, "", commonName, isCA, logger.WithValues("Kind", certificateKind()),
+		client, scheme, pandaCluster, key, issuerRef, nil, commonName, isCA, logger.WithValues("Kind", certificateKind()),
 	}
 }
 
@@ -86,8 +85,16 @@ func (r *CertificateResource) Ensure(ctx context.Context) error {
 		return fmt.Errorf("unable to construct object: %w", err)
 	}
 
-	_, err = resources.CreateIfNotExists(ctx, r, obj, r.logger)
-	return err
+	created, err := resources.CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var cert cmapiv1.Certificate
+	err = r.Get(ctx, r.Key(), &cert)
+	if err != nil {
+		return fmt.Errorf("error while fetching Certificate resource: %w", err)
+	}
+	return resources.Update(ctx, &cert, obj, r.Client, r.logger)
 }
 
 // obj returns resource managed client.Object
@@ -106,15 +113,12 @@ func (r *CertificateResource) obj() (k8sclient.Object, error) {
 		},
 	}
 
-	if r.fqdn != "" {
-		name := "*." + strings.TrimSuffix(r.fqdn, ".")
-		cert.Spec.CommonName = string(r.commonName)
-		cert.Spec.DNSNames = []string{name}
-	} else {
-		cert.Spec.CommonName = string(r.commonName)
+	cert.Spec.CommonName = string(r.commonName)
+	for _, fqdn := range r.fqdns {
+		cert.Spec.DNSNames = append(cert.Spec.DNSNames, "*."+strings.TrimSuffix(fqdn, "."))
 	}
 
-	err := controllerutil.SetControllerReference(r.pandaCluster, cert, r.scheme)
+	err := resources.SetOwner(r.pandaCluster, cert, r.scheme)
 	if err != nil {
 		return nil, err
 	}
@@ -23,7 +23,6 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 var _ resources.Resource = &IssuerResource{}
@@ -95,7 +94,7 @@ func (r *IssuerResource) obj() (k8sclient.Object, error) {
 		Spec:       spec,
 	}
 
-	err := controllerutil.SetControllerReference(r.pandaCluster, issuer, r.scheme)
+	err := resources.SetOwner(r.pandaCluster, issuer, r.scheme)
 	if err != nil {
 		return nil, err
 	}
@@ -11,6 +11,10 @@ package certmanager
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
 
 	cmmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
@@ -71,17 +75,25 @@ func (r *PkiReconciler) prepareKafkaAPI(
 			nodeIssuerRef = externalIssuerRef
 		}
 
-		dnsName := r.internalFQDN
+		// The external listener with settings of its own has a certificate
+		// of its own
+		dnsNames := []string{r.internalFQDN}
 		externConn := r.pandaCluster.Spec.ExternalConnectivity
-		if externConn.Enabled && externConn.Subdomain != "" {
-			dnsName = externConn.Subdomain
+		if externConn.Enabled && externConn.Subdomain != "" && r.pandaCluster.KafkaExternalListener() == nil {
+			dnsNames = r.pandaCluster.ExternalSubdomains()
 		}
 
-		redpandaCert := NewNodeCertificate(r.Client, r.scheme, r.pandaCluster, certsKey, nodeIssuerRef, dnsName, cn, false, r.logger)
+		redpandaCert := NewNodeCertificate(r.Client, r.scheme, r.pandaCluster, certsKey, nodeIssuerRef, dnsNames, cn, false, r.logger)
 
 		toApply = append(toApply, redpandaCert)
 	}
 
+	if nodeSecretRef != nil {
+		if err := r.verifyNodeSecretSubdomains(ctx, nodeSecretRef); err != nil {
+			return nil, err
+		}
+	}
+
 	if nodeSecretRef != nil && nodeSecretRef.Namespace != r.pandaCluster.Namespace {
 		if err := r.copyNodeSecretToLocalNamespace(ctx, nodeSecretRef); err != nil {
 			return nil, err
@@ -110,6 +122,45 @@ func (r *PkiReconciler) prepareKafkaAPI(
 	return toApply, nil
 }
 
+var (
+	errInvalidNodeCertificate = errors.New("unable to parse node certificate")
+	errSubdomainNotCovered    = errors.New("node certificate does not cover external subdomain")
+)
+
+// verifyNodeSecretSubdomains checks that the provided node certificate is valid
+// for every external subdomain under which the brokers are reachable
+func (r *PkiReconciler) verifyNodeSecretSubdomains(
+	ctx context.Context, secretRef *corev1.ObjectReference,
+) error {
+	externConn := r.pandaCluster.Spec.ExternalConnectivity
+	if !externConn.Enabled || externConn.Subdomain == "" || r.pandaCluster.KafkaExternalListener() != nil {
+		return nil
+	}
+
+	var secret corev1.Secret
+	err := r.Get(ctx, types.NamespacedName{Name: secretRef.Name, Namespace: secretRef.Namespace}, &secret)
+	if err != nil {
+		return err
+	}
+
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		return fmt.Errorf("secret %s/%s: %w", secretRef.Namespace, secretRef.Name, errInvalidNodeCertificate)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("secret %s/%s: %w", secretRef.Namespace, secretRef.Name, err)
+	}
+
+	for _, subdomain := range r.pandaCluster.ExternalSubdomains() {
+		// brokers are reached as <ordinal>.<subdomain>
+		if err := cert.VerifyHostname("0." + subdomain); err != nil {
+			return fmt.Errorf("%w %s: %v", errSubdomainNotCovered, subdomain, err)
+		}
+	}
+	return nil
+}
+
 // Creates copy of secret in Redpanda cluster's namespace
 func (r *PkiReconciler) copyNodeSecretToLocalNamespace(
 	ctx context.Context, secretRef *corev1.ObjectReference,
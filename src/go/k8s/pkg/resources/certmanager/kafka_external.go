@@ -0,0 +1,61 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager
+
+import (
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	kafkaExternal = "kafka-external"
+	// KafkaExternalClientCert cert name - client certificate for the
+	// external Kafka API listener
+	KafkaExternalClientCert = "kafka-external-client"
+	// KafkaExternalNodeCert cert name - node certificate for the external
+	// Kafka API listener
+	KafkaExternalNodeCert = "kafka-external-node"
+)
+
+// KafkaExternalNodeCert returns the namespaced name for the certificate of
+// the external Kafka API listener used by node
+func (r *PkiReconciler) KafkaExternalNodeCert() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + "-" + KafkaExternalNodeCert, Namespace: r.pandaCluster.Namespace}
+}
+
+func (r *PkiReconciler) prepareKafkaExternalListener(
+	issuerRef *cmmeta.ObjectReference,
+) []resources.Resource {
+	toApply := []resources.Resource{}
+	listenerTLS := r.pandaCluster.KafkaExternalListener().TLS
+
+	nodeIssuerRef := issuerRef
+	if listenerTLS.IssuerRef != nil {
+		nodeIssuerRef = listenerTLS.IssuerRef
+	}
+
+	// Redpanda cluster certificate for the external listener - to be provided to each broker
+	cn := NewCommonName(r.pandaCluster.Name, KafkaExternalNodeCert)
+	certsKey := types.NamespacedName{Name: string(cn), Namespace: r.pandaCluster.Namespace}
+	nodeCert := NewNodeCertificate(r.Client, r.scheme, r.pandaCluster, certsKey, nodeIssuerRef, r.pandaCluster.ExternalSubdomains(), cn, false, r.logger)
+	toApply = append(toApply, nodeCert)
+
+	if listenerTLS.RequireClientAuth && listenerTLS.TruststoreSecretRef == nil {
+		// Certificate for external clients to call the external listener on any broker
+		cn := NewCommonName(r.pandaCluster.Name, KafkaExternalClientCert)
+		clientCertsKey := types.NamespacedName{Name: string(cn), Namespace: r.pandaCluster.Namespace}
+		clientCert := NewCertificate(r.Client, r.scheme, r.pandaCluster, clientCertsKey, nodeIssuerRef, cn, false, r.logger)
+
+		toApply = append(toApply, clientCert)
+	}
+
+	return toApply
+}
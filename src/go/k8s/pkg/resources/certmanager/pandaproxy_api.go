@@ -0,0 +1,64 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager
+
+import (
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	pandaproxyAPI = "proxy"
+	// PandaproxyAPIClientCert cert name - client certificate for Pandaproxy
+	PandaproxyAPIClientCert = "proxy-api-client"
+	// PandaproxyAPINodeCert cert name - node certificate for Pandaproxy
+	PandaproxyAPINodeCert = "proxy-api-node"
+)
+
+// PandaproxyAPINodeCert returns the namespaced name for the Pandaproxy certificate used by node
+func (r *PkiReconciler) PandaproxyAPINodeCert() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + "-" + PandaproxyAPINodeCert, Namespace: r.pandaCluster.Namespace}
+}
+
+func (r *PkiReconciler) preparePandaproxyAPI(
+	issuerRef *cmmeta.ObjectReference,
+) []resources.Resource {
+	toApply := []resources.Resource{}
+	proxyTLS := r.pandaCluster.Spec.Configuration.PandaproxyAPI.TLS
+
+	nodeIssuerRef := issuerRef
+	if proxyTLS.IssuerRef != nil {
+		nodeIssuerRef = proxyTLS.IssuerRef
+	}
+
+	// Redpanda cluster certificate for Pandaproxy - to be provided to each broker
+	cn := NewCommonName(r.pandaCluster.Name, PandaproxyAPINodeCert)
+	certsKey := types.NamespacedName{Name: string(cn), Namespace: r.pandaCluster.Namespace}
+
+	dnsNames := []string{r.internalFQDN}
+	if subdomain := r.pandaCluster.PandaproxyExternalSubdomain(); subdomain != "" {
+		dnsNames = []string{subdomain}
+	}
+
+	nodeCert := NewNodeCertificate(r.Client, r.scheme, r.pandaCluster, certsKey, nodeIssuerRef, dnsNames, cn, false, r.logger)
+	toApply = append(toApply, nodeCert)
+
+	if proxyTLS.RequireClientAuth {
+		// Certificate for calling Pandaproxy on any broker
+		cn := NewCommonName(r.pandaCluster.Name, PandaproxyAPIClientCert)
+		clientCertsKey := types.NamespacedName{Name: string(cn), Namespace: r.pandaCluster.Namespace}
+		clientCert := NewCertificate(r.Client, r.scheme, r.pandaCluster, clientCertsKey, issuerRef, cn, false, r.logger)
+
+		toApply = append(toApply, clientCert)
+	}
+
+	return toApply
+}
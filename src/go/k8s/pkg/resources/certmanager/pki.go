@@ -100,12 +100,30 @@ func (r *PkiReconciler) Ensure(ctx context.Context) error {
 		toApply = append(toApply, toApplyKafka...)
 	}
 
+	if listener := r.pandaCluster.KafkaExternalListener(); listener != nil && listener.TLS.Enabled {
+		toApplyRootExternal, externalIssuerRef := r.prepareRoot(kafkaExternal)
+		toApply = append(toApply, toApplyRootExternal...)
+		toApply = append(toApply, r.prepareKafkaExternalListener(externalIssuerRef)...)
+	}
+
 	if r.pandaCluster.Spec.Configuration.TLS.AdminAPI.Enabled {
 		toApplyRootAdmin, adminIssuerRef := r.prepareRoot(adminAPI)
 		toApply = append(toApply, toApplyRootAdmin...)
 		toApply = append(toApply, r.prepareAdminAPI(adminIssuerRef)...)
 	}
 
+	if r.pandaCluster.Spec.Configuration.TLS.RPC.Enabled {
+		toApplyRootRPC, rpcIssuerRef := r.prepareRoot(rpcAPI)
+		toApply = append(toApply, toApplyRootRPC...)
+		toApply = append(toApply, r.prepareRPC(rpcIssuerRef)...)
+	}
+
+	if proxy := r.pandaCluster.Spec.Configuration.PandaproxyAPI; proxy != nil && proxy.TLS.Enabled {
+		toApplyRootProxy, proxyIssuerRef := r.prepareRoot(pandaproxyAPI)
+		toApply = append(toApply, toApplyRootProxy...)
+		toApply = append(toApply, r.preparePandaproxyAPI(proxyIssuerRef)...)
+	}
+
 	for _, res := range toApply {
 		err := res.Ensure(ctx)
 		if err != nil {
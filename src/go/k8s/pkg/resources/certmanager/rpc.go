@@ -0,0 +1,55 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager
+
+import (
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	rpcAPI = "rpc"
+	// RPCNodeCert cert name - node certificate for the internal RPC
+	RPCNodeCert = "rpc-node"
+)
+
+// RPCNodeCert returns the namespaced name for the certificate the brokers use
+// to talk to each other
+func (r *PkiReconciler) RPCNodeCert() types.NamespacedName {
+	if r.pandaCluster.Spec.Configuration.TLS.RPC.NodeSecretRef != nil {
+		return types.NamespacedName{
+			Name:      r.pandaCluster.Spec.Configuration.TLS.RPC.NodeSecretRef.Name,
+			Namespace: r.pandaCluster.Namespace,
+		}
+	}
+	return types.NamespacedName{Name: r.pandaCluster.Name + "-" + RPCNodeCert, Namespace: r.pandaCluster.Namespace}
+}
+
+func (r *PkiReconciler) prepareRPC(
+	issuerRef *cmmeta.ObjectReference,
+) []resources.Resource {
+	rpcTLS := r.pandaCluster.Spec.Configuration.TLS.RPC
+	if rpcTLS.NodeSecretRef != nil {
+		return nil
+	}
+	if rpcTLS.IssuerRef != nil {
+		issuerRef = rpcTLS.IssuerRef
+	}
+
+	// The seed servers and the peers are reached through the per Pod
+	// addresses of the headless service, the certificate requests a wildcard
+	// name for them
+	cn := NewCommonName(r.pandaCluster.Name, RPCNodeCert)
+	certsKey := types.NamespacedName{Name: string(cn), Namespace: r.pandaCluster.Namespace}
+	nodeCert := NewNodeCertificate(r.Client, r.scheme, r.pandaCluster, certsKey, issuerRef, []string{r.internalFQDN}, cn, false, r.logger)
+
+	return []resources.Resource{nodeCert}
+}
@@ -0,0 +1,148 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	clientConfigSuffix = "-client"
+
+	// ClientConfigBrokersKey is the key of the bootstrap servers in the
+	// client ConfigMap
+	ClientConfigBrokersKey = "brokers"
+	// ClientConfigTLSKey is the key telling whether the listener uses TLS
+	ClientConfigTLSKey = "tls"
+	// ClientConfigSASLMechanismKey is the key of the SASL mechanism
+	ClientConfigSASLMechanismKey = "sasl_mechanism"
+)
+
+var _ Resource = &ClientConfigResource{}
+
+// ClientConfigResource manages the ConfigMap with the settings clients need
+// to connect to the internal Kafka API listener. It is rendered on every
+// reconcile, so it follows changes of the port, TLS and SASL settings and
+// rotations of the CA certificate.
+type ClientConfigResource struct {
+	k8sclient.Client
+	scheme        *runtime.Scheme
+	pandaCluster  *redpandav1alpha1.Cluster
+	serviceFQDN   string
+	kafkaNodeCert types.NamespacedName
+	logger        logr.Logger
+}
+
+// NewClientConfig creates ClientConfigResource
+func NewClientConfig(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	serviceFQDN string,
+	kafkaNodeCert types.NamespacedName,
+	logger logr.Logger,
+) *ClientConfigResource {
+	return &ClientConfigResource{
+		client,
+		scheme,
+		pandaCluster,
+		serviceFQDN,
+		kafkaNodeCert,
+		logger.WithValues("Kind", "ConfigMap", "Reconciler", "client config"),
+	}
+}
+
+// Ensure creates or updates the client ConfigMap when it is enabled
+func (r *ClientConfigResource) Ensure(ctx context.Context) error {
+	if r.pandaCluster.Spec.ClientConfig == nil {
+		return nil
+	}
+
+	obj, err := r.obj(ctx)
+	if err != nil {
+		return err
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var cm corev1.ConfigMap
+	if err := r.Get(ctx, r.Key(), &cm); err != nil {
+		return fmt.Errorf("error while fetching client ConfigMap: %w", err)
+	}
+	return Update(ctx, &cm, obj, r.Client, r.logger)
+}
+
+func (r *ClientConfigResource) obj(ctx context.Context) (k8sclient.Object, error) {
+	conf := r.pandaCluster.Spec.Configuration
+	tls := r.pandaCluster.InternalKafkaTLS()
+
+	data := map[string]string{
+		ClientConfigBrokersKey: fmt.Sprintf("%s:%d", r.serviceFQDN, conf.KafkaAPI.Port),
+		ClientConfigTLSKey:     strconv.FormatBool(tls),
+	}
+	if tls {
+		var secret corev1.Secret
+		err := r.Get(ctx, r.kafkaNodeCert, &secret)
+		if apierrors.IsNotFound(err) {
+			return nil, &RequeueAfterError{RequeueAfter: requeueDuration,
+				Msg: fmt.Sprintf("waiting for the Kafka API certificate %s", r.kafkaNodeCert.Name)}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch Kafka API certificate: %w", err)
+		}
+		data[cmetav1.TLSCAKey] = string(secret.Data[cmetav1.TLSCAKey])
+	}
+	if r.pandaCluster.KafkaAuthenticationMethod() == redpandav1alpha1.KafkaAuthenticationSASL {
+		data[ClientConfigSASLMechanismKey] = superuserSASLMechanism
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    labels.ForCluster(r.pandaCluster),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		Data: data,
+	}
+
+	err := SetOwner(r.pandaCluster, cm, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+	return cm, nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *ClientConfigResource) Key() types.NamespacedName {
+	name := r.pandaCluster.Name + clientConfigSuffix
+	if config := r.pandaCluster.Spec.ClientConfig; config != nil && config.Name != "" {
+		name = config.Name
+	}
+	return types.NamespacedName{Name: name, Namespace: r.pandaCluster.Namespace}
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClientConfigEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.ClientConfig = &redpandav1alpha1.ClientConfig{}
+	cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+	cluster.Spec.EnableSASL = true
+
+	nodeCert := types.NamespacedName{Name: "cluster-redpanda", Namespace: cluster.Namespace}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	clientConfig := res.NewClientConfig(c, cluster, scheme.Scheme, "cluster.local", nodeCert, ctrl.Log)
+
+	var requeue *res.RequeueAfterError
+	assert.True(t, errors.As(clientConfig.Ensure(context.Background()), &requeue))
+
+	require.NoError(t, c.Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeCert.Name, Namespace: nodeCert.Namespace},
+		Data:       map[string][]byte{"ca.crt": []byte("CA")},
+	}))
+	require.NoError(t, clientConfig.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), clientConfig.Key(), &actual))
+	assert.Equal(t, "cluster-client", actual.Name)
+	assert.Equal(t, "cluster.local:123", actual.Data[res.ClientConfigBrokersKey])
+	assert.Equal(t, "true", actual.Data[res.ClientConfigTLSKey])
+	assert.Equal(t, "CA", actual.Data["ca.crt"])
+	assert.Equal(t, "SCRAM-SHA-256", actual.Data[res.ClientConfigSASLMechanismKey])
+}
@@ -0,0 +1,346 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	corev1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ AdminAPIReconciler = &ClusterConfigurationReconciler{}
+var _ AdminAPIFallback = &ClusterConfigurationReconciler{}
+
+// ClusterConfigurationReconciler applies cluster properties to a running
+// Redpanda cluster through the Admin API. The Admin API of this Redpanda
+// version does not serve the cluster configuration, the brokers pick the
+// same properties up from redpanda.yaml and restart when they change.
+type ClusterConfigurationReconciler struct {
+	k8sclient.Client
+	pandaCluster          *redpandav1alpha1.Cluster
+	adminAPIClientFactory admin.AdminAPIClientFactory
+	serviceFQDN           string
+	adminTLSProvider      admin.AdminTLSConfigProvider
+	logger                logr.Logger
+	// unsupported holds the Admin API errors the last Ensure fell back without
+	unsupported []error
+}
+
+// NewClusterConfiguration creates ClusterConfigurationReconciler
+func NewClusterConfiguration(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	adminAPIClientFactory admin.AdminAPIClientFactory,
+	serviceFQDN string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+	logger logr.Logger,
+) *ClusterConfigurationReconciler {
+	return &ClusterConfigurationReconciler{
+		client,
+		pandaCluster,
+		adminAPIClientFactory,
+		serviceFQDN,
+		adminTLSProvider,
+		logger.WithValues("Reconciler", "cluster configuration"),
+		nil,
+	}
+}
+
+// RequiresAdminAPI implements AdminAPIReconciler
+func (r *ClusterConfigurationReconciler) RequiresAdminAPI() {}
+
+// UnsupportedAdminAPI implements AdminAPIFallback
+func (r *ClusterConfigurationReconciler) UnsupportedAdminAPI() []error {
+	return r.unsupported
+}
+
+// Ensure upserts cluster properties that differ from the desired ones, among
+// them the superusers. A property that differs from the value the operator
+// applied before is drift and is only reverted when the ConfigDriftCorrection
+// feature gate is enabled.
+func (r *ClusterConfigurationReconciler) Ensure(ctx context.Context) error {
+	r.unsupported = nil
+	if !r.pandaCluster.FeatureGateEnabled(redpandav1alpha1.FeatureGateOnlineConfiguration) {
+		return nil
+	}
+
+	// brokers that are not running yet will read the properties from redpanda.yaml
+	if r.pandaCluster.Status.Replicas == 0 {
+		return nil
+	}
+	desired := clusterProperties(r.pandaCluster)
+	users, err := superusers(ctx, r, r.pandaCluster)
+	if err != nil {
+		return err
+	}
+	// the empty list revokes the superusers that were removed
+	desired["superusers"] = users
+
+	adminAPI, err := r.adminAPIClientFactory(ctx, r, r.pandaCluster, r.serviceFQDN, r.adminTLSProvider)
+	if err != nil {
+		return fmt.Errorf("unable to create admin API client: %w", err)
+	}
+
+	current, err := adminAPI.ClusterConfig(ctx)
+	if err != nil {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("unable to retrieve cluster configuration: %v", err)}
+	}
+
+	unknown, err := r.unknownProperties(ctx, adminAPI, desired)
+	if err != nil {
+		return err
+	}
+	if len(unknown) > 0 && r.pandaCluster.UnknownPropertyPolicy() == redpandav1alpha1.UnknownPropertyReject {
+		r.logger.Info("Not applying cluster properties unknown to the running version", "properties", unknown)
+		return r.rejectProperties(ctx, unknown)
+	}
+	for _, k := range unknown {
+		delete(desired, k)
+	}
+
+	correct := r.pandaCluster.FeatureGateEnabled(redpandav1alpha1.FeatureGateConfigDriftCorrection)
+	upsert := map[string]interface{}{}
+	var drifted []string
+	for k, v := range desired {
+		if propertyEqual(current[k], v) {
+			continue
+		}
+		if r.drifted(k, v) {
+			drifted = append(drifted, k)
+			if !correct {
+				continue
+			}
+		}
+		upsert[k] = v
+	}
+	sort.Strings(drifted)
+	if len(drifted) > 0 {
+		r.logger.Info("Cluster properties changed out of band", "properties", drifted, "corrected", correct)
+	}
+	if len(upsert) == 0 {
+		return r.updateStatus(ctx, desired, drifted, correct, unknown)
+	}
+
+	r.logger.Info("Applying cluster properties", "properties", sortedKeys(upsert))
+	err = adminAPI.SetConfig(ctx, upsert, nil)
+	if admin.IsUnsupported(err) {
+		return fmt.Errorf("cluster configuration: %w", err)
+	}
+	if err != nil {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("unable to apply cluster configuration: %v", err)}
+	}
+	return r.updateStatus(ctx, desired, drifted, correct, unknown)
+}
+
+// unknownProperties returns the desired properties the configuration schema
+// of the running version does not list. Without schema validation, or when
+// the version does not serve its schema, every property is assumed known.
+func (r *ClusterConfigurationReconciler) unknownProperties(
+	ctx context.Context, adminAPI admin.AdminAPIClient, desired map[string]interface{},
+) ([]string, error) {
+	if r.pandaCluster.Spec.Configuration.SchemaValidation == nil {
+		return nil, nil
+	}
+	schema, err := adminAPI.ClusterConfigSchema(ctx)
+	if admin.IsUnsupported(err) {
+		r.logger.Info("The running version does not serve the configuration schema, skipping the validation")
+		r.unsupported = append(r.unsupported, fmt.Errorf("configuration schema validation: %w", err))
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("unable to retrieve the configuration schema: %v", err)}
+	}
+	var unknown []string
+	for k := range desired {
+		if _, ok := schema[k]; !ok {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	if len(unknown) > 0 {
+		r.logger.Info("Cluster properties are unknown to the running version", "properties", unknown)
+	}
+	return unknown, nil
+}
+
+// rejectProperties reports the unknown properties without applying any of
+// the desired properties
+func (r *ClusterConfigurationReconciler) rejectProperties(
+	ctx context.Context, unknown []string,
+) error {
+	status := r.pandaCluster.Status.DeepCopy()
+	status.UnknownProperties = unknown
+	changed := status.SetCondition(redpandav1alpha1.ClusterUnknownProperties, corev1.ConditionTrue, "PropertiesRejected",
+		"no property applied, unknown properties: "+strings.Join(unknown, ", "))
+	if !changed && reflect.DeepEqual(status.UnknownProperties, r.pandaCluster.Status.UnknownProperties) {
+		return nil
+	}
+	r.pandaCluster.Status = *status
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return fmt.Errorf("unable to update cluster configuration status: %w", err)
+	}
+	return nil
+}
+
+// drifted returns true when the operator already applied the desired value
+// of the property, so a different live value was set out of band
+func (r *ClusterConfigurationReconciler) drifted(
+	property string, desired interface{},
+) bool {
+	applied, ok := r.pandaCluster.Status.AppliedProperties[property]
+	return ok && applied == propertyValue(desired)
+}
+
+// updateStatus reports the applied properties, among them the default
+// partition count of new topics, the drifted properties in the ConfigDrift
+// condition and the skipped properties in the UnknownProperties condition
+func (r *ClusterConfigurationReconciler) updateStatus(
+	ctx context.Context,
+	applied map[string]interface{},
+	drifted []string,
+	corrected bool,
+	unknown []string,
+) error {
+	status := r.pandaCluster.Status.DeepCopy()
+	if partitions, ok := applied["default_topic_partitions"].(int); ok {
+		status.DefaultTopicPartitions = partitions
+	}
+	status.AppliedProperties = make(map[string]string, len(applied))
+	for k, v := range applied {
+		status.AppliedProperties[k] = propertyValue(v)
+	}
+
+	conditionStatus, reason, message := corev1.ConditionFalse, "NoConfigDrift", "cluster properties match the Cluster resource"
+	switch {
+	case len(drifted) > 0 && corrected:
+		reason, message = "ConfigDriftCorrected", "reverted properties: "+strings.Join(drifted, ", ")
+	case len(drifted) > 0:
+		conditionStatus, reason, message = corev1.ConditionTrue, "ConfigDrifted", "drifted properties: "+strings.Join(drifted, ", ")
+	}
+	changed := status.SetCondition(redpandav1alpha1.ClusterConfigDrift, conditionStatus, reason, message)
+
+	status.UnknownProperties = unknown
+	if len(unknown) > 0 {
+		changed = status.SetCondition(redpandav1alpha1.ClusterUnknownProperties, corev1.ConditionTrue, "PropertiesSkipped",
+			"skipped unknown properties: "+strings.Join(unknown, ", ")) || changed
+	} else if status.GetCondition(redpandav1alpha1.ClusterUnknownProperties) != nil {
+		changed = status.SetCondition(redpandav1alpha1.ClusterUnknownProperties, corev1.ConditionFalse, "PropertiesKnown",
+			"every cluster property is known to the running version") || changed
+	}
+
+	if !changed &&
+		status.DefaultTopicPartitions == r.pandaCluster.Status.DefaultTopicPartitions &&
+		reflect.DeepEqual(status.AppliedProperties, r.pandaCluster.Status.AppliedProperties) &&
+		reflect.DeepEqual(status.UnknownProperties, r.pandaCluster.Status.UnknownProperties) {
+		return nil
+	}
+	r.pandaCluster.Status = *status
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return fmt.Errorf("unable to update cluster configuration status: %w", err)
+	}
+	return nil
+}
+
+// clusterProperties returns the cluster properties derived from the
+// Cluster custom resource that the reconciler applies through the Admin API
+func clusterProperties(
+	pandaCluster *redpandav1alpha1.Cluster,
+) map[string]interface{} {
+	properties := map[string]interface{}{}
+
+	limits := pandaCluster.Spec.Configuration.KafkaClientLimits
+	if limits.TargetQuotaByteRate != 0 {
+		properties["target_quota_byte_rate"] = limits.TargetQuotaByteRate
+	}
+
+	if window := pandaCluster.Spec.Configuration.Raft.ReplicateBatchWindowSize; window != 0 {
+		properties["raft_replicate_batch_window_size"] = window
+	}
+
+	compression := pandaCluster.Spec.Configuration.Compression
+	if compression.TopicDefault != "" {
+		properties["log_compression_type"] = string(compression.TopicDefault)
+	}
+
+	topics := pandaCluster.Spec.Configuration.TopicDefaults
+	if topics.AutoCreateTopics != nil {
+		properties["auto_create_topics_enabled"] = *topics.AutoCreateTopics
+	}
+	if topics.Partitions != 0 {
+		properties["default_topic_partitions"] = topics.Partitions
+	}
+
+	retention := pandaCluster.Spec.Configuration.Retention
+	if retention.TimeMs != nil {
+		properties["delete_retention_ms"] = *retention.TimeMs
+	}
+	if retention.Bytes != nil {
+		properties["retention_bytes"] = *retention.Bytes
+	}
+
+	compaction := pandaCluster.Spec.Configuration.Compaction
+	if compaction.IntervalMs != nil {
+		properties["log_compaction_interval_ms"] = *compaction.IntervalMs
+	}
+	if compaction.CleanupPolicy != "" {
+		properties["log_cleanup_policy"] = compaction.CleanupPolicy
+	}
+
+	compatibility := pandaCluster.Spec.Configuration.KafkaCompatibility
+	if compatibility.MessageTimestampType != "" {
+		properties["log_message_timestamp_type"] = string(compatibility.MessageTimestampType)
+	}
+
+	return properties
+}
+
+// propertyEqual compares values by their JSON representation as the Admin
+// API decodes all numbers as floats
+func propertyEqual(current, desired interface{}) bool {
+	c, err := json.Marshal(current)
+	if err != nil {
+		return false
+	}
+	d, err := json.Marshal(desired)
+	if err != nil {
+		return false
+	}
+	return string(c) == string(d)
+}
+
+// propertyValue returns the JSON representation of a property value
+func propertyValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClusterConfigurationEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.FeatureGates = map[string]bool{redpandav1alpha1.FeatureGateOnlineConfiguration: true}
+	cluster.Spec.Configuration.KafkaClientLimits.TargetQuotaByteRate = 1024
+	cluster.Spec.Configuration.Raft.ReplicateBatchWindowSize = 2 * 1024 * 1024
+
+	// this version serves the cluster configuration but does not apply it
+	adminAPI := admin.NewMockAdminAPI()
+	adminAPI.Stubbed = true
+	// the admin API reports numbers as floats
+	adminAPI.Config["raft_replicate_batch_window_size"] = float64(2 * 1024 * 1024)
+	adminAPI.Config["superusers"] = []string{res.BootstrapSuperuserName}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	reconciler := func() *res.ClusterConfigurationReconciler {
+		return res.NewClusterConfiguration(c, cluster, adminAPI.Factory(), "cluster.local", nil, ctrl.Log)
+	}
+	ensure := func() error {
+		return reconciler().Ensure(context.Background())
+	}
+
+	t.Run("not running cluster is configured through redpanda.yaml", func(t *testing.T) {
+		cluster.Status.Replicas = 0
+		require.NoError(t, ensure())
+		assert.Equal(t, 0, adminAPI.ConfigWrites)
+	})
+
+	t.Run("changed properties are not applied online", func(t *testing.T) {
+		cluster.Status.Replicas = 1
+		assert.True(t, admin.IsUnsupported(ensure()))
+		assert.Equal(t, 0, adminAPI.ConfigWrites)
+		assert.Empty(t, cluster.Status.AppliedProperties)
+	})
+
+	t.Run("properties in place are reported in the status", func(t *testing.T) {
+		adminAPI.Config["target_quota_byte_rate"] = float64(1024)
+		require.NoError(t, ensure())
+		assert.Equal(t, "1024", cluster.Status.AppliedProperties["target_quota_byte_rate"])
+		assert.Equal(t, corev1.ConditionFalse, cluster.Status.GetCondition(redpandav1alpha1.ClusterConfigDrift).Status)
+	})
+
+	t.Run("out of band changes are reported", func(t *testing.T) {
+		adminAPI.Config["target_quota_byte_rate"] = float64(2048)
+		require.NoError(t, ensure())
+		assert.Equal(t, 0, adminAPI.ConfigWrites)
+		condition := cluster.Status.GetCondition(redpandav1alpha1.ClusterConfigDrift)
+		require.NotNil(t, condition)
+		assert.Equal(t, corev1.ConditionTrue, condition.Status)
+		assert.Contains(t, condition.Message, "target_quota_byte_rate")
+	})
+
+	t.Run("drift is not corrected online", func(t *testing.T) {
+		cluster.Spec.FeatureGates[redpandav1alpha1.FeatureGateConfigDriftCorrection] = true
+		defer delete(cluster.Spec.FeatureGates, redpandav1alpha1.FeatureGateConfigDriftCorrection)
+		assert.True(t, admin.IsUnsupported(ensure()))
+		assert.Equal(t, float64(2048), adminAPI.Config["target_quota_byte_rate"])
+	})
+
+	t.Run("versions without the schema skip the validation", func(t *testing.T) {
+		cluster.Spec.Configuration.SchemaValidation = &redpandav1alpha1.ConfigSchemaValidation{
+			OnUnknownProperty: redpandav1alpha1.UnknownPropertyReject,
+		}
+		cluster.Spec.Configuration.KafkaCompatibility.MessageTimestampType = redpandav1alpha1.MessageTimestampType("LogAppendTime")
+		adminAPI.Config["log_message_timestamp_type"] = "LogAppendTime"
+		r := reconciler()
+		require.NoError(t, r.Ensure(context.Background()))
+		assert.Empty(t, cluster.Status.UnknownProperties)
+		require.Len(t, r.UnsupportedAdminAPI(), 1)
+		assert.True(t, admin.IsUnsupported(r.UnsupportedAdminAPI()[0]))
+	})
+}
@@ -50,7 +50,7 @@ func NewClusterRole(
 
 // Ensure manages v1.ClusterRole that is assigned to v1.ServiceAccount used in initContainer
 func (r *ClusterRoleResource) Ensure(ctx context.Context) error {
-	if !r.pandaCluster.Spec.ExternalConnectivity.Enabled {
+	if !r.pandaCluster.ConfiguratorReadsNode() {
 		return nil
 	}
 	_, err := CreateIfNotExists(ctx, r, r.obj(), r.logger)
@@ -0,0 +1,174 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	clusterServiceSuffix = "-cluster"
+
+	// TopologyAwareHintsAnnotation lets the EndpointSlice controller add
+	// zone hints to the endpoints of a Service
+	TopologyAwareHintsAnnotation = "service.kubernetes.io/topology-aware-hints"
+	// serviceNameLabel links an EndpointSlice to its Service
+	serviceNameLabel = "kubernetes.io/service-name"
+)
+
+// EndpointSliceGVK is the kind of the EndpointSlices that carry zone hints.
+// The client libraries of the operator predate it.
+var EndpointSliceGVK = schema.GroupVersionKind{
+	Group:   "discovery.k8s.io",
+	Version: "v1",
+	Kind:    "EndpointSlice",
+}
+
+var _ Resource = &ClusterServiceResource{}
+
+// ClusterServiceResource manages the ClusterIP Service <cluster>-cluster, the
+// stable bootstrap endpoint of the internal Kafka API listener. Its name does
+// not depend on the number of brokers and it only routes to ready brokers,
+// unlike the headless Service that resolves to the individual brokers.
+// Clients bootstrap from it and then connect to the advertised addresses.
+type ClusterServiceResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	logger       logr.Logger
+}
+
+// NewClusterService creates ClusterServiceResource
+func NewClusterService(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	logger logr.Logger,
+) *ClusterServiceResource {
+	return &ClusterServiceResource{
+		client,
+		scheme,
+		pandaCluster,
+		logger.WithValues(
+			"Kind", serviceKind(),
+			"ServiceType", corev1.ServiceTypeClusterIP,
+		),
+	}
+}
+
+// Ensure will manage kubernetes v1.Service for redpanda.vectorized.io custom resource
+func (r *ClusterServiceResource) Ensure(ctx context.Context) error {
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	if r.pandaCluster.Spec.TopologyAwareHints {
+		supported, err := r.endpointSlicesServed(ctx)
+		if err != nil {
+			return err
+		}
+		if supported {
+			obj.Annotations = map[string]string{TopologyAwareHintsAnnotation: "auto"}
+		} else {
+			r.logger.Info("WARNING: topology aware hints need the discovery.k8s.io/v1 EndpointSlice API, the Service is not annotated")
+		}
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var svc corev1.Service
+	err = r.Get(ctx, r.Key(), &svc)
+	if err != nil {
+		return fmt.Errorf("error while fetching Service resource: %w", err)
+	}
+	if managed, err := claimService(r.pandaCluster, &svc, obj, r.logger); err != nil || !managed {
+		return err
+	}
+	return Update(ctx, &svc, obj, r.Client, r.logger)
+}
+
+// obj returns resource managed client.Object
+func (r *ClusterServiceResource) obj() (*corev1.Service, error) {
+	port := r.pandaCluster.Spec.Configuration.KafkaAPI.Port
+	objLabels := labels.ForCluster(r.pandaCluster)
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    objLabels,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       KafkaPortName,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       int32(port),
+					TargetPort: intstr.FromInt(port),
+				},
+			},
+			Selector: objLabels.AsAPISelector().MatchLabels,
+		},
+	}
+
+	err := SetOwner(r.pandaCluster, svc, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// endpointSlicesServed returns true when the API server serves the
+// EndpointSlices that carry the zone hints
+func (r *ClusterServiceResource) endpointSlicesServed(ctx context.Context) (bool, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(EndpointSliceGVK.GroupVersion().WithKind(EndpointSliceGVK.Kind + "List"))
+	err := r.List(ctx, list,
+		k8sclient.InNamespace(r.Key().Namespace),
+		k8sclient.MatchingLabels{serviceNameLabel: r.Key().Name})
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to list the EndpointSlices of the Service: %w", err)
+	}
+	return true, nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *ClusterServiceResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + clusterServiceSuffix, Namespace: r.pandaCluster.Namespace}
+}
+
+// ServiceFQDN returns the fully qualified domain name clients bootstrap from
+func (r *ClusterServiceResource) ServiceFQDN() string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local", r.Key().Name, r.Key().Namespace)
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClusterService(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.DNS = &redpandav1alpha1.DNSConfig{PublishNotReadyAddresses: true}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	svc := res.NewClusterService(c, cluster, scheme.Scheme, ctrl.Log)
+	require.NoError(t, svc.Ensure(context.Background()))
+
+	var actual corev1.Service
+	require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+	assert.Equal(t, "cluster-cluster", actual.Name)
+	assert.Equal(t, corev1.ServiceTypeClusterIP, actual.Spec.Type)
+	assert.Empty(t, actual.Spec.ClusterIP)
+	// the bootstrap endpoint only routes to ready brokers
+	assert.False(t, actual.Spec.PublishNotReadyAddresses)
+	require.Len(t, actual.Spec.Ports, 1)
+	assert.Equal(t, res.KafkaPortName, actual.Spec.Ports[0].Name)
+	assert.Equal(t, int32(123), actual.Spec.Ports[0].Port)
+	assert.Equal(t, "cluster-cluster.default.svc.cluster.local", svc.ServiceFQDN())
+}
+
+func TestClusterServiceTopologyAwareHints(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, scheme.AddToScheme(s))
+	require.NoError(t, redpandav1alpha1.AddToScheme(s))
+	// the fake API server serves the EndpointSlices
+	s.AddKnownTypeWithName(res.EndpointSliceGVK, &unstructured.Unstructured{})
+	s.AddKnownTypeWithName(res.EndpointSliceGVK.GroupVersion().WithKind(res.EndpointSliceGVK.Kind+"List"), &unstructured.UnstructuredList{})
+
+	cluster := pandaCluster()
+	cluster.Spec.TopologyAwareHints = true
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(cluster).Build()
+	svc := res.NewClusterService(c, cluster, s, ctrl.Log)
+	require.NoError(t, svc.Ensure(context.Background()))
+
+	var actual corev1.Service
+	require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+	assert.Equal(t, "auto", actual.Annotations[res.TopologyAwareHintsAnnotation])
+
+	cluster.Spec.TopologyAwareHints = false
+	require.NoError(t, svc.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+	assert.NotContains(t, actual.Annotations, res.TopologyAwareHintsAnnotation)
+}
@@ -11,8 +11,10 @@ package resources
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/go-logr/logr"
 	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
@@ -26,7 +28,6 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 const (
@@ -37,9 +38,24 @@ const (
 	tlsDirCA = "/etc/tls/certs/ca"
 
 	tlsAdminDir = "/etc/tls/certs/admin"
+
+	tlsRPCDir = "/etc/tls/certs/rpc"
+
+	tlsPandaproxyDir = "/etc/tls/certs/pandaproxy"
+
+	tlsKafkaExternalDir   = "/etc/tls/certs/kafka-external"
+	tlsKafkaExternalCADir = "/etc/tls/certs/kafka-external/ca"
+
+	// ConfigHashAnnotationKey is the annotation holding the hash of the
+	// configuration properties that require a restart of the brokers
+	ConfigHashAnnotationKey = "redpanda.vectorized.io/configmap-hash"
+	// IOPropertiesKey is the ConfigMap key holding the disk properties of
+	// the reactor configuration
+	IOPropertiesKey = "io-config.yaml"
 )
 
 var errKeyDoesNotExistInSecretData = errors.New("cannot find key in secret data")
+var errKeyDoesNotExistInConfigMapData = errors.New("cannot find key in configmap data")
 var errCloudStorageSecretKeyCannotBeEmpty = errors.New("cloud storage SecretKey string cannot be empty")
 
 var _ Resource = &ConfigMapResource{}
@@ -102,11 +118,19 @@ func (r *ConfigMapResource) obj(ctx context.Context) (k8sclient.Object, error) {
 		return nil, err
 	}
 
+	hash, err := restartRequiredConfigHash(conf)
+	if err != nil {
+		return nil, err
+	}
+
 	cm := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: r.Key().Namespace,
 			Name:      r.Key().Name,
 			Labels:    labels.ForCluster(r.pandaCluster),
+			Annotations: map[string]string{
+				ConfigHashAnnotationKey: hash,
+			},
 		},
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "ConfigMap",
@@ -116,8 +140,11 @@ func (r *ConfigMapResource) obj(ctx context.Context) (k8sclient.Object, error) {
 			"redpanda.yaml": string(cfgBytes),
 		},
 	}
+	if reactor := r.pandaCluster.Spec.Reactor; reactor != nil && reactor.IOProperties != "" {
+		cm.Data[IOPropertiesKey] = reactor.IOProperties
+	}
 
-	err = controllerutil.SetControllerReference(r.pandaCluster, cm, r.scheme)
+	err = SetOwner(r.pandaCluster, cm, r.scheme)
 	if err != nil {
 		return nil, err
 	}
@@ -140,7 +167,7 @@ func (r *ConfigMapResource) createConfiguration(
 				Address: "0.0.0.0",
 				Port:    c.KafkaAPI.Port,
 			},
-			Name: "Internal",
+			Name: r.pandaCluster.InternalListenerName(),
 		},
 	}
 
@@ -150,7 +177,7 @@ func (r *ConfigMapResource) createConfiguration(
 				Address: "0.0.0.0",
 				Port:    calculateExternalPort(c.KafkaAPI.Port),
 			},
-			Name: "External",
+			Name: r.pandaCluster.ExternalListenerName(),
 		})
 	}
 
@@ -164,11 +191,12 @@ func (r *ConfigMapResource) createConfiguration(
 	cr.DeveloperMode = c.DeveloperMode
 	cr.Directory = dataDirectory
 	if r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.Enabled {
-		// If external connectivity is enabled the TLS config will be applied to the external listener,
-		// otherwise TLS will be applied to the internal listener. // TODO support multiple TLS configs
-		name := "Internal"
-		if r.pandaCluster.Spec.ExternalConnectivity.Enabled {
-			name = "External"
+		// Without independent settings of the external listener the TLS
+		// config will be applied to the external listener if external
+		// connectivity is enabled, otherwise to the internal listener.
+		name := r.pandaCluster.InternalListenerName()
+		if !r.pandaCluster.InternalKafkaTLS() {
+			name = r.pandaCluster.ExternalListenerName()
 		}
 		tls := config.ServerTLS{
 			Name:              name,
@@ -184,6 +212,24 @@ func (r *ConfigMapResource) createConfiguration(
 			tls,
 		}
 	}
+	if listener := r.pandaCluster.KafkaExternalListener(); listener != nil && listener.TLS.Enabled {
+		tls := config.ServerTLS{
+			Name:              r.pandaCluster.ExternalListenerName(),
+			KeyFile:           fmt.Sprintf("%s/%s", tlsKafkaExternalDir, corev1.TLSPrivateKeyKey),
+			CertFile:          fmt.Sprintf("%s/%s", tlsKafkaExternalDir, corev1.TLSCertKey),
+			Enabled:           true,
+			RequireClientAuth: listener.TLS.RequireClientAuth,
+		}
+		if listener.TLS.RequireClientAuth {
+			// The client certificates are verified against the CA of the
+			// node certificate unless a truststore is provided
+			tls.TruststoreFile = fmt.Sprintf("%s/%s", tlsKafkaExternalDir, cmetav1.TLSCAKey)
+			if listener.TLS.TruststoreSecretRef != nil {
+				tls.TruststoreFile = fmt.Sprintf("%s/%s", tlsKafkaExternalCADir, cmetav1.TLSCAKey)
+			}
+		}
+		cr.KafkaApiTLS = append(cr.KafkaApiTLS, tls)
+	}
 	if r.pandaCluster.Spec.Configuration.TLS.AdminAPI.Enabled {
 		cr.AdminApiTLS = config.ServerTLS{
 			KeyFile:           fmt.Sprintf("%s/%s", tlsAdminDir, corev1.TLSPrivateKeyKey),
@@ -196,6 +242,23 @@ func (r *ConfigMapResource) createConfiguration(
 		}
 	}
 
+	if r.pandaCluster.Spec.Configuration.TLS.RPC.Enabled {
+		// Redpanda uses the RPC server TLS settings for the connections to
+		// its peers and the seed servers too, every broker presents its
+		// certificate and verifies the one of the other end
+		setOtherProperty(cr, "rpc_server_tls", config.ServerTLS{
+			KeyFile:           fmt.Sprintf("%s/%s", tlsRPCDir, corev1.TLSPrivateKeyKey),
+			CertFile:          fmt.Sprintf("%s/%s", tlsRPCDir, corev1.TLSCertKey),
+			TruststoreFile:    fmt.Sprintf("%s/%s", tlsRPCDir, cmetav1.TLSCAKey),
+			Enabled:           true,
+			RequireClientAuth: true,
+		})
+	}
+
+	if r.pandaCluster.Spec.Configuration.PandaproxyAPI != nil {
+		r.preparePandaproxy(cfgRpk)
+	}
+
 	if r.pandaCluster.Spec.CloudStorage.Enabled {
 		secretName := types.NamespacedName{
 			Name:      r.pandaCluster.Spec.CloudStorage.SecretKeyRef.Name,
@@ -212,11 +275,15 @@ func (r *ConfigMapResource) createConfiguration(
 		r.prepareCloudStorage(cr, secretKeyStr)
 	}
 
-	for _, user := range r.pandaCluster.Spec.Superusers {
-		cr.Superusers = append(cr.Superusers, user.Username)
+	users, err := superusers(ctx, r, r.pandaCluster)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) > 0 {
+		cr.Superusers = users
 	}
 
-	if r.pandaCluster.Spec.EnableSASL {
+	if r.pandaCluster.KafkaAuthenticationMethod() == redpandav1alpha1.KafkaAuthenticationSASL {
 		cr.EnableSASL = pointer.BoolPtr(true)
 	}
 
@@ -225,8 +292,37 @@ func (r *ConfigMapResource) createConfiguration(
 		cr.GroupTopicPartitions = &partitions
 	}
 
+	raft := r.pandaCluster.Spec.Configuration.Raft
+	if raft.HeartbeatIntervalMs != 0 {
+		setOtherProperty(cr, "raft_heartbeat_interval_ms", raft.HeartbeatIntervalMs)
+	}
+	if raft.ElectionTimeoutMs != 0 {
+		setOtherProperty(cr, "election_timeout_ms", raft.ElectionTimeoutMs)
+	}
+	if timeout := r.pandaCluster.Spec.Configuration.RPCServerTuning.HeartbeatTimeoutMs; timeout != 0 {
+		setOtherProperty(cr, "raft_heartbeat_timeout_ms", timeout)
+	}
+
+	compatibility := r.pandaCluster.Spec.Configuration.KafkaCompatibility
+	if compatibility.EnableIdempotence != nil {
+		setOtherProperty(cr, "enable_idempotence", *compatibility.EnableIdempotence)
+	}
+	if compatibility.EnableTransactions != nil {
+		setOtherProperty(cr, "enable_transactions", *compatibility.EnableTransactions)
+	}
+
+	for k, v := range clusterProperties(r.pandaCluster) {
+		setOtherProperty(cr, k, v)
+	}
+
 	replicas := *r.pandaCluster.Spec.Replicas
-	for i := int32(0); i < replicas; i++ {
+	// A single broker has no peer to join. With an empty seed list it forms
+	// a standalone cluster right away instead of waiting for itself to be
+	// resolvable through the headless Service.
+	if replicas == 1 {
+		cr.SeedServers = []config.SeedServer{}
+	}
+	for i := int32(0); replicas > 1 && i < replicas; i++ {
 		cr.SeedServers = append(cr.SeedServers, config.SeedServer{
 			Host: config.SocketAddress{
 				// Example address: cluster-sample-0.cluster-sample.default.svc.cluster.local
@@ -239,6 +335,75 @@ func (r *ConfigMapResource) createConfiguration(
 	return cfgRpk, nil
 }
 
+// preparePandaproxy configures the Pandaproxy listeners. Like the Kafka API,
+// the external listener listens on the port next to the internal one and
+// gets the TLS configuration when it exists. Pandaproxy reaches the Kafka API
+// of the brokers through the headless Service.
+func (r *ConfigMapResource) preparePandaproxy(cfgRpk *config.Config) {
+	proxy := r.pandaCluster.Spec.Configuration.PandaproxyAPI
+	cfgRpk.Pandaproxy = &config.Pandaproxy{
+		PandaproxyAPI: []config.NamedSocketAddress{
+			{
+				SocketAddress: config.SocketAddress{
+					Address: "0.0.0.0",
+					Port:    proxy.Port,
+				},
+				Name: redpandav1alpha1.PandaproxyInternalListenerName,
+			},
+		},
+	}
+	name := redpandav1alpha1.PandaproxyInternalListenerName
+	if r.pandaCluster.PandaproxyExternal() {
+		name = redpandav1alpha1.PandaproxyExternalListenerName
+		cfgRpk.Pandaproxy.PandaproxyAPI = append(cfgRpk.Pandaproxy.PandaproxyAPI, config.NamedSocketAddress{
+			SocketAddress: config.SocketAddress{
+				Address: "0.0.0.0",
+				Port:    calculateExternalPort(proxy.Port),
+			},
+			Name: name,
+		})
+	}
+	if proxy.TLS.Enabled {
+		tls := config.ServerTLS{
+			Name:              name,
+			KeyFile:           fmt.Sprintf("%s/%s", tlsPandaproxyDir, corev1.TLSPrivateKeyKey),
+			CertFile:          fmt.Sprintf("%s/%s", tlsPandaproxyDir, corev1.TLSCertKey),
+			Enabled:           true,
+			RequireClientAuth: proxy.TLS.RequireClientAuth,
+		}
+		if proxy.TLS.RequireClientAuth {
+			tls.TruststoreFile = fmt.Sprintf("%s/%s", tlsPandaproxyDir, cmetav1.TLSCAKey)
+		}
+		cfgRpk.Pandaproxy.PandaproxyAPITLS = []config.ServerTLS{tls}
+	}
+
+	cfgRpk.PandaproxyClient = &config.PandaproxyClient{
+		Brokers: []config.SocketAddress{
+			{
+				Address: r.serviceFQDN,
+				Port:    r.pandaCluster.Spec.Configuration.KafkaAPI.Port,
+			},
+		},
+	}
+}
+
+// restartRequiredConfigHash hashes the configuration without the seed
+// servers, so the hash changes only when brokers have to be restarted. Seed
+// servers are only used when a broker joins the cluster for the first time,
+// so scaling does not restart running brokers. The Admin API of this Redpanda
+// version does not apply cluster properties at runtime, so every other
+// property, the superusers among them, is hashed.
+func restartRequiredConfigHash(cfg *config.Config) (string, error) {
+	restartRequired := *cfg
+	restartRequired.Redpanda.SeedServers = nil
+
+	cfgBytes, err := yaml.Marshal(&restartRequired)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal configuration for hashing: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(cfgBytes)), nil
+}
+
 // calculateExternalPort can calculate external Kafka API port based on the internal Kafka API port
 func calculateExternalPort(kafkaInternalPort int) int {
 	if kafkaInternalPort < 0 || kafkaInternalPort > 65535 {
@@ -279,6 +444,17 @@ func (r *ConfigMapResource) prepareCloudStorage(
 	}
 }
 
+// setOtherProperty sets redpanda.yaml property that is not modelled by the
+// rpk configuration
+func setOtherProperty(
+	cr *config.RedpandaConfig, key string, value interface{},
+) {
+	if cr.Other == nil {
+		cr.Other = map[string]interface{}{}
+	}
+	cr.Other[key] = value
+}
+
 func (r *ConfigMapResource) getSecretValue(
 	ctx context.Context, nsName types.NamespacedName, key string,
 ) (string, error) {
@@ -295,6 +471,74 @@ func (r *ConfigMapResource) getSecretValue(
 	return "", fmt.Errorf("secret name %s, ns %s, data key %s: %w", nsName.Name, nsName.Namespace, key, errKeyDoesNotExistInSecretData)
 }
 
+// superusers returns the superusers of the cluster without duplicates: the
+// listed ones, the ones of the referenced ConfigMap, the bootstrap superuser
+// of the operator and the bootstrap user
+func superusers(
+	ctx context.Context, c k8sclient.Reader, pandaCluster *redpandav1alpha1.Cluster,
+) ([]string, error) {
+	var users []string
+	for _, user := range pandaCluster.Spec.Superusers {
+		users = append(users, user.Username)
+	}
+	if ref := pandaCluster.Spec.SuperusersFrom; ref != nil {
+		list, err := getConfigMapValue(ctx, c, types.NamespacedName{Name: ref.Name, Namespace: pandaCluster.Namespace}, ref.Key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot retrieve superusers: %w", err)
+		}
+		users = append(users, parseUserList(list)...)
+	}
+	if pandaCluster.BootstrapSuperuserRequired() {
+		users = append(users, BootstrapSuperuserName)
+	}
+	if user := pandaCluster.Spec.BootstrapUser; user != nil {
+		var secret corev1.Secret
+		err := c.Get(ctx, types.NamespacedName{Name: user.SecretRef.Name, Namespace: pandaCluster.Namespace}, &secret)
+		if err != nil {
+			return nil, fmt.Errorf("cannot retrieve the bootstrap user: %w", err)
+		}
+		users = append(users, string(secret.Data[corev1.BasicAuthUsernameKey]))
+	}
+
+	unique := make([]string, 0, len(users))
+	seen := make(map[string]bool, len(users))
+	for _, user := range users {
+		if !seen[user] {
+			seen[user] = true
+			unique = append(unique, user)
+		}
+	}
+	return unique, nil
+}
+
+func getConfigMapValue(
+	ctx context.Context, c k8sclient.Reader, nsName types.NamespacedName, key string,
+) (string, error) {
+	var cm corev1.ConfigMap
+	err := c.Get(ctx, nsName, &cm)
+	if err != nil {
+		return "", err
+	}
+
+	if v, exists := cm.Data[key]; exists {
+		return v, nil
+	}
+
+	return "", fmt.Errorf("configmap name %s, ns %s, data key %s: %w", nsName.Name, nsName.Namespace, key, errKeyDoesNotExistInConfigMapData)
+}
+
+// parseUserList returns the user names of a list with one name per line,
+// skipping blank lines
+func parseUserList(list string) []string {
+	var users []string
+	for _, line := range strings.Split(list, "\n") {
+		if user := strings.TrimSpace(line); user != "" {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
 func clusterCRPortOrRPKDefault(clusterPort, defaultPort int) int {
 	if clusterPort == 0 {
 		return defaultPort
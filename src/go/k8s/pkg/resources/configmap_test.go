@@ -0,0 +1,291 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConfigMapRestartRequiredHash(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	hash := func() string {
+		cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", ctrl.Log)
+		require.NoError(t, cm.Ensure(context.Background()))
+
+		var actual corev1.ConfigMap
+		require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+		require.NotEmpty(t, actual.Annotations[res.ConfigHashAnnotationKey])
+		return actual.Annotations[res.ConfigHashAnnotationKey]
+	}
+
+	initial := hash()
+
+	t.Run("scaling does not restart brokers", func(t *testing.T) {
+		cluster.Spec.Replicas = pointer.Int32Ptr(3)
+		assert.Equal(t, initial, hash())
+	})
+
+	t.Run("cluster property restarts brokers", func(t *testing.T) {
+		restarted := hash()
+		cluster.Spec.Configuration.KafkaClientLimits.TargetQuotaByteRate = 1024
+		assert.NotEqual(t, restarted, hash())
+	})
+
+	t.Run("raft batch window restarts brokers", func(t *testing.T) {
+		restarted := hash()
+		cluster.Spec.Configuration.Raft.ReplicateBatchWindowSize = 2 * 1024 * 1024
+		assert.NotEqual(t, restarted, hash())
+	})
+
+	t.Run("superusers restart brokers", func(t *testing.T) {
+		restarted := hash()
+		cluster.Spec.Superusers = []redpandav1alpha1.Superuser{{Username: "alice"}}
+		defer func() { cluster.Spec.Superusers = nil }()
+		assert.NotEqual(t, restarted, hash())
+	})
+
+	t.Run("restart required property restarts brokers", func(t *testing.T) {
+		restarted := hash()
+		cluster.Spec.Configuration.DeveloperMode = !cluster.Spec.Configuration.DeveloperMode
+		assert.NotEqual(t, restarted, hash())
+	})
+
+	t.Run("raft timeouts restart brokers", func(t *testing.T) {
+		restarted := hash()
+		cluster.Spec.Configuration.Raft.ElectionTimeoutMs = 3000
+		assert.NotEqual(t, restarted, hash())
+	})
+
+	t.Run("kafka compatibility restarts brokers", func(t *testing.T) {
+		restarted := hash()
+		cluster.Spec.Configuration.KafkaCompatibility.MessageTimestampType = redpandav1alpha1.MessageTimestampType("LogAppendTime")
+		assert.NotEqual(t, restarted, hash())
+		restarted = hash()
+		cluster.Spec.Configuration.KafkaCompatibility.EnableIdempotence = pointer.BoolPtr(false)
+		assert.NotEqual(t, restarted, hash())
+	})
+}
+
+func TestConfigMapSeedServers(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	seeds := func() []config.SeedServer {
+		cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", ctrl.Log)
+		require.NoError(t, cm.Ensure(context.Background()))
+
+		var actual corev1.ConfigMap
+		require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+		var cfg config.Config
+		require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+		return cfg.Redpanda.SeedServers
+	}
+
+	t.Run("single broker starts the cluster by itself", func(t *testing.T) {
+		cluster.Spec.Replicas = pointer.Int32Ptr(1)
+		assert.Empty(t, seeds())
+	})
+
+	t.Run("every broker is a seed of a multi broker cluster", func(t *testing.T) {
+		cluster.Spec.Replicas = pointer.Int32Ptr(3)
+		actual := seeds()
+		require.Len(t, actual, 3)
+		assert.Equal(t, "cluster-2.cluster.local", actual[2].Host.Address)
+	})
+}
+
+func TestConfigMapRPCTLS(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.TLS.RPC.Enabled = true
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", ctrl.Log)
+	require.NoError(t, cm.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+	var cfg config.Config
+	require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+	rpcTLS, ok := cfg.Redpanda.Other["rpc_server_tls"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, true, rpcTLS["enabled"])
+	assert.Equal(t, true, rpcTLS["require_client_auth"])
+	assert.Equal(t, "/etc/tls/certs/rpc/tls.crt", rpcTLS["cert_file"])
+	assert.Equal(t, "/etc/tls/certs/rpc/ca.crt", rpcTLS["truststore_file"])
+}
+
+func TestConfigMapInternalTopicPartitions(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.GroupTopicPartitions = 64
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", ctrl.Log)
+	require.NoError(t, cm.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+	var cfg config.Config
+	require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+	require.NotNil(t, cfg.Redpanda.GroupTopicPartitions)
+	assert.Equal(t, 64, *cfg.Redpanda.GroupTopicPartitions)
+}
+
+func TestConfigMapRaftTuning(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.Raft.HeartbeatIntervalMs = 300
+	cluster.Spec.Configuration.Raft.ElectionTimeoutMs = 3000
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", ctrl.Log)
+	require.NoError(t, cm.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+	var cfg config.Config
+	require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+	assert.Equal(t, 300, cfg.Redpanda.Other["raft_heartbeat_interval_ms"])
+	assert.Equal(t, 3000, cfg.Redpanda.Other["election_timeout_ms"])
+	assert.NotContains(t, cfg.Redpanda.Other, "raft_election_timeout_ms")
+}
+
+func TestConfigMapListenerNames(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	cluster.Spec.Configuration.KafkaAPI.InternalListenerName = "cluster"
+	cluster.Spec.Configuration.KafkaAPI.ExternalListenerName = "clients"
+	cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", ctrl.Log)
+	require.NoError(t, cm.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+	var cfg config.Config
+	require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+	require.Len(t, cfg.Redpanda.KafkaApi, 2)
+	assert.Equal(t, "cluster", cfg.Redpanda.KafkaApi[0].Name)
+	assert.Equal(t, "clients", cfg.Redpanda.KafkaApi[1].Name)
+	require.Len(t, cfg.Redpanda.KafkaApiTLS, 1)
+	assert.Equal(t, "clients", cfg.Redpanda.KafkaApiTLS[0].Name)
+}
+
+func TestConfigMapKafkaExternalListener(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	cluster.Spec.ExternalConnectivity.Subdomain = "partner.example.com"
+	cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+	cluster.Spec.Configuration.KafkaAPI.ExternalListener = &redpandav1alpha1.KafkaExternalListener{
+		TLS: redpandav1alpha1.KafkaExternalListenerTLS{
+			Enabled:             true,
+			RequireClientAuth:   true,
+			TruststoreSecretRef: &corev1.LocalObjectReference{Name: "partner-ca"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", ctrl.Log)
+	require.NoError(t, cm.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+	var cfg config.Config
+	require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+	require.Len(t, cfg.Redpanda.KafkaApi, 2)
+	require.Len(t, cfg.Redpanda.KafkaApiTLS, 2)
+	assert.Equal(t, redpandav1alpha1.DefaultInternalListenerName, cfg.Redpanda.KafkaApiTLS[0].Name)
+	assert.Equal(t, "/etc/tls/certs/tls.crt", cfg.Redpanda.KafkaApiTLS[0].CertFile)
+	assert.False(t, cfg.Redpanda.KafkaApiTLS[0].RequireClientAuth)
+	assert.Equal(t, redpandav1alpha1.DefaultExternalListenerName, cfg.Redpanda.KafkaApiTLS[1].Name)
+	assert.Equal(t, "/etc/tls/certs/kafka-external/tls.crt", cfg.Redpanda.KafkaApiTLS[1].CertFile)
+	assert.True(t, cfg.Redpanda.KafkaApiTLS[1].RequireClientAuth)
+	assert.Equal(t, "/etc/tls/certs/kafka-external/ca/ca.crt", cfg.Redpanda.KafkaApiTLS[1].TruststoreFile)
+}
+
+func TestConfigMapPandaproxy(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	cluster.Spec.Configuration.PandaproxyAPI = &redpandav1alpha1.PandaproxyAPI{
+		Port:     8082,
+		External: redpandav1alpha1.ExternalListener{Enabled: true, Subdomain: "proxy.example.com"},
+		TLS:      redpandav1alpha1.PandaproxyAPITLS{Enabled: true},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", ctrl.Log)
+	require.NoError(t, cm.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+	var cfg config.Config
+	require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+	require.NotNil(t, cfg.Pandaproxy)
+	require.Len(t, cfg.Pandaproxy.PandaproxyAPI, 2)
+	assert.Equal(t, redpandav1alpha1.PandaproxyInternalListenerName, cfg.Pandaproxy.PandaproxyAPI[0].Name)
+	assert.Equal(t, 8082, cfg.Pandaproxy.PandaproxyAPI[0].Port)
+	assert.Equal(t, redpandav1alpha1.PandaproxyExternalListenerName, cfg.Pandaproxy.PandaproxyAPI[1].Name)
+	assert.Equal(t, 8083, cfg.Pandaproxy.PandaproxyAPI[1].Port)
+	require.Len(t, cfg.Pandaproxy.PandaproxyAPITLS, 1)
+	assert.Equal(t, redpandav1alpha1.PandaproxyExternalListenerName, cfg.Pandaproxy.PandaproxyAPITLS[0].Name)
+	assert.Equal(t, "/etc/tls/certs/pandaproxy/tls.crt", cfg.Pandaproxy.PandaproxyAPITLS[0].CertFile)
+	require.NotNil(t, cfg.PandaproxyClient)
+	assert.Equal(t, []config.SocketAddress{{Address: "cluster.local", Port: cluster.Spec.Configuration.KafkaAPI.Port}},
+		cfg.PandaproxyClient.Brokers)
+}
+
+func TestConfigMapSuperusersFrom(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Superusers = []redpandav1alpha1.Superuser{{Username: "alice"}}
+	cluster.Spec.SuperusersFrom = &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "operators"},
+		Key:                  "superusers",
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", ctrl.Log)
+	assert.Error(t, cm.Ensure(context.Background()))
+
+	require.NoError(t, c.Create(context.Background(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "operators", Namespace: cluster.Namespace},
+		Data:       map[string]string{"superusers": "bob\n\n  carol \nalice\n"},
+	}))
+	require.NoError(t, cm.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+	var cfg config.Config
+	require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+	assert.Equal(t, []string{"alice", "bob", "carol"}, cfg.Redpanda.Superusers)
+}
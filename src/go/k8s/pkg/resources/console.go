@@ -0,0 +1,436 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConsoleConfigHashAnnotationKey is the annotation of the Console pods
+	// holding the hash of their configuration, so the pods are restarted
+	// when the endpoints or credentials of the cluster change
+	ConsoleConfigHashAnnotationKey = "redpanda.vectorized.io/console-config-hash"
+	// ConsoleSuperuserPasswordRotatedAnnotationKey is the annotation of the
+	// Console pods holding when the superuser password was last rotated, so
+	// the pods restart with the new password
+	ConsoleSuperuserPasswordRotatedAnnotationKey = "redpanda.vectorized.io/superuser-password-rotated"
+
+	consoleSuffix        = "-console"
+	consoleContainerName = "console"
+	consolePortName      = "http"
+	consolePort          = 8080
+	consoleConfigFile    = "config.yaml"
+	consoleConfigDir     = "/etc/console"
+	consoleKafkaCertDir  = "/etc/console/certs/kafka"
+	consoleAdminCertDir  = "/etc/console/certs/admin"
+)
+
+var _ Resource = &ConsoleResource{}
+
+// ConsoleResource deploys Redpanda Console for the cluster. The Console
+// configuration is kept in a Secret that is rendered from the services,
+// certificates and superuser of the cluster, so it follows changes of the
+// cluster configuration.
+type ConsoleResource struct {
+	k8sclient.Client
+	scheme             *runtime.Scheme
+	pandaCluster       *redpandav1alpha1.Cluster
+	serviceFQDN        string
+	kafkaNodeCert      types.NamespacedName
+	kafkaClientCert    types.NamespacedName
+	adminAPINodeCert   types.NamespacedName
+	adminAPIClientCert types.NamespacedName
+	logger             logr.Logger
+}
+
+// NewConsole creates ConsoleResource
+func NewConsole(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	serviceFQDN string,
+	kafkaNodeCert types.NamespacedName,
+	kafkaClientCert types.NamespacedName,
+	adminAPINodeCert types.NamespacedName,
+	adminAPIClientCert types.NamespacedName,
+	logger logr.Logger,
+) *ConsoleResource {
+	return &ConsoleResource{
+		client,
+		scheme,
+		pandaCluster,
+		serviceFQDN,
+		kafkaNodeCert,
+		kafkaClientCert,
+		adminAPINodeCert,
+		adminAPIClientCert,
+		logger.WithValues("Reconciler", "console"),
+	}
+}
+
+// Ensure manages the Secret, Deployment, Service and optional Ingress of
+// Redpanda Console
+func (r *ConsoleResource) Ensure(ctx context.Context) error {
+	if r.pandaCluster.Spec.Console == nil {
+		return nil
+	}
+
+	cfg, err := r.config()
+	if err != nil {
+		return fmt.Errorf("unable to render Console configuration: %w", err)
+	}
+
+	objects := []struct {
+		obj     k8sclient.Object
+		current k8sclient.Object
+	}{
+		{r.secret(cfg), &corev1.Secret{}},
+		{r.deployment(cfg), &appsv1.Deployment{}},
+		{r.service(), &corev1.Service{}},
+	}
+	if r.pandaCluster.Spec.Console.Ingress != nil {
+		objects = append(objects, struct {
+			obj     k8sclient.Object
+			current k8sclient.Object
+		}{r.ingress(), &networkingv1.Ingress{}})
+	}
+
+	for _, o := range objects {
+		if err := SetOwner(r.pandaCluster, o.obj, r.scheme); err != nil {
+			return err
+		}
+		created, err := CreateIfNotExists(ctx, r, o.obj, r.logger)
+		if err != nil {
+			return err
+		}
+		if created {
+			continue
+		}
+		if err := r.Get(ctx, r.Key(), o.current); err != nil {
+			return fmt.Errorf("error while fetching Console %s: %w", o.obj.GetObjectKind().GroupVersionKind().Kind, err)
+		}
+		if err := Update(ctx, o.current, o.obj, r.Client, r.logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type consoleConfig struct {
+	Kafka    consoleKafkaConfig    `yaml:"kafka"`
+	Redpanda consoleRedpandaConfig `yaml:"redpanda"`
+}
+
+type consoleKafkaConfig struct {
+	Brokers []string          `yaml:"brokers"`
+	TLS     *consoleTLSConfig `yaml:"tls,omitempty"`
+	SASL    *consoleSASL      `yaml:"sasl,omitempty"`
+}
+
+type consoleRedpandaConfig struct {
+	AdminAPI consoleAdminAPIConfig `yaml:"adminApi"`
+}
+
+type consoleAdminAPIConfig struct {
+	Enabled bool              `yaml:"enabled"`
+	URLs    []string          `yaml:"urls"`
+	TLS     *consoleTLSConfig `yaml:"tls,omitempty"`
+}
+
+type consoleTLSConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	CAFilepath   string `yaml:"caFilepath"`
+	CertFilepath string `yaml:"certFilepath,omitempty"`
+	KeyFilepath  string `yaml:"keyFilepath,omitempty"`
+}
+
+// consoleSASL leaves out the password, Console reads it from the
+// KAFKA_SASL_PASSWORD environment variable set from the superuser Secret
+type consoleSASL struct {
+	Enabled   bool   `yaml:"enabled"`
+	Username  string `yaml:"username"`
+	Mechanism string `yaml:"mechanism"`
+}
+
+func (r *ConsoleResource) config() ([]byte, error) {
+	conf := r.pandaCluster.Spec.Configuration
+	tls := conf.TLS
+
+	cfg := consoleConfig{
+		Kafka: consoleKafkaConfig{
+			Brokers: []string{fmt.Sprintf("%s:%d", r.serviceFQDN, conf.KafkaAPI.Port)},
+		},
+	}
+	if tls.KafkaAPI.Enabled {
+		cfg.Kafka.TLS = consoleTLS(consoleKafkaCertDir, tls.KafkaAPI.RequireClientAuth)
+	}
+	if r.pandaCluster.Spec.EnableSASL {
+		cfg.Kafka.SASL = &consoleSASL{
+			Enabled:   true,
+			Username:  BootstrapSuperuserName,
+			Mechanism: superuserSASLMechanism,
+		}
+	}
+
+	adminScheme := "http"
+	if tls.AdminAPI.Enabled {
+		adminScheme = "https"
+		cfg.Redpanda.AdminAPI.TLS = consoleTLS(consoleAdminCertDir, tls.AdminAPI.RequireClientAuth)
+	}
+	cfg.Redpanda.AdminAPI.Enabled = true
+	cfg.Redpanda.AdminAPI.URLs = []string{fmt.Sprintf("%s://%s:%d", adminScheme, r.serviceFQDN, conf.AdminAPI.Port)}
+
+	return yaml.Marshal(cfg)
+}
+
+func consoleTLS(dir string, clientAuth bool) *consoleTLSConfig {
+	cfg := &consoleTLSConfig{
+		Enabled:    true,
+		CAFilepath: filepath.Join(dir, cmetav1.TLSCAKey),
+	}
+	if clientAuth {
+		cfg.CertFilepath = filepath.Join(dir, corev1.TLSCertKey)
+		cfg.KeyFilepath = filepath.Join(dir, corev1.TLSPrivateKeyKey)
+	}
+	return cfg
+}
+
+func (r *ConsoleResource) objectMeta() metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      r.Key().Name,
+		Namespace: r.Key().Namespace,
+		Labels:    labels.ForConsole(r.pandaCluster),
+	}
+}
+
+func (r *ConsoleResource) secret(cfg []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: r.objectMeta(),
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		Data: map[string][]byte{
+			consoleConfigFile: cfg,
+		},
+	}
+}
+
+func (r *ConsoleResource) deployment(cfg []byte) *appsv1.Deployment {
+	console := r.pandaCluster.Spec.Console
+	consoleLabels := labels.ForConsole(r.pandaCluster)
+
+	replicas := console.Replicas
+	if replicas == nil {
+		replicas = pointer.Int32Ptr(1)
+	}
+
+	volumes := []corev1.Volume{
+		{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: r.Key().Name},
+			},
+		},
+	}
+	mounts := []corev1.VolumeMount{
+		{
+			Name:      "config",
+			MountPath: consoleConfigDir,
+			ReadOnly:  true,
+		},
+	}
+	tls := r.pandaCluster.Spec.Configuration.TLS
+	if tls.KafkaAPI.Enabled {
+		volumes = append(volumes, consoleCertVolume("kafka-cert", r.kafkaNodeCert, r.kafkaClientCert, tls.KafkaAPI.RequireClientAuth))
+		mounts = append(mounts, corev1.VolumeMount{Name: "kafka-cert", MountPath: consoleKafkaCertDir, ReadOnly: true})
+	}
+	if tls.AdminAPI.Enabled {
+		volumes = append(volumes, consoleCertVolume("admin-cert", r.adminAPINodeCert, r.adminAPIClientCert, tls.AdminAPI.RequireClientAuth))
+		mounts = append(mounts, corev1.VolumeMount{Name: "admin-cert", MountPath: consoleAdminCertDir, ReadOnly: true})
+	}
+
+	annotations := map[string]string{
+		ConsoleConfigHashAnnotationKey: fmt.Sprintf("%x", sha256.Sum256(cfg)),
+	}
+	var env []corev1.EnvVar
+	if r.pandaCluster.Spec.EnableSASL {
+		if rotated := r.pandaCluster.Status.SuperuserPasswordRotated; rotated != nil &&
+			r.pandaCluster.Spec.Configuration.SuperuserPasswordRotation != nil {
+			annotations[ConsoleSuperuserPasswordRotatedAnnotationKey] = rotated.UTC().Format(time.RFC3339)
+		}
+		env = append(env, corev1.EnvVar{
+			Name: "KAFKA_SASL_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: r.pandaCluster.SuperuserSecretName(),
+					},
+					Key: SuperuserSecretPasswordKey,
+				},
+			},
+		})
+	}
+
+	return &appsv1.Deployment{
+		ObjectMeta: r.objectMeta(),
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: replicas,
+			Selector: consoleLabels.AsAPISelector(),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      consoleLabels.AsAPISelector().MatchLabels,
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  consoleContainerName,
+							Image: console.Image,
+							Args:  []string{"-config.filepath=" + filepath.Join(consoleConfigDir, consoleConfigFile)},
+							Env:   env,
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          consolePortName,
+									ContainerPort: consolePort,
+								},
+							},
+							Resources:    console.Resources,
+							VolumeMounts: mounts,
+						},
+					},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+}
+
+// consoleCertVolume mounts the client certificate when the API requires
+// client authentication. Otherwise only the CA is taken from the node
+// certificate, the node private key is not mounted into the Console pods.
+func consoleCertVolume(
+	name string, nodeCert, clientCert types.NamespacedName, clientAuth bool,
+) corev1.Volume {
+	source := &corev1.SecretVolumeSource{SecretName: clientCert.Name}
+	if !clientAuth {
+		source = &corev1.SecretVolumeSource{
+			SecretName: nodeCert.Name,
+			Items: []corev1.KeyToPath{
+				{
+					Key:  cmetav1.TLSCAKey,
+					Path: cmetav1.TLSCAKey,
+				},
+			},
+		}
+	}
+	return corev1.Volume{
+		Name:         name,
+		VolumeSource: corev1.VolumeSource{Secret: source},
+	}
+}
+
+func (r *ConsoleResource) service() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: r.objectMeta(),
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       consolePortName,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       consolePort,
+					TargetPort: intstr.FromInt(consolePort),
+				},
+			},
+			Selector: labels.ForConsole(r.pandaCluster).AsAPISelector().MatchLabels,
+		},
+	}
+}
+
+func (r *ConsoleResource) ingress() *networkingv1.Ingress {
+	spec := r.pandaCluster.Spec.Console.Ingress
+	pathType := networkingv1.PathTypePrefix
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: r.objectMeta(),
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Ingress",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: spec.IngressClassName,
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: spec.Host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: r.Key().Name,
+											Port: networkingv1.ServiceBackendPort{Name: consolePortName},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if spec.TLSSecretName != "" {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{
+			{
+				Hosts:      []string{spec.Host},
+				SecretName: spec.TLSSecretName,
+			},
+		}
+	}
+	return ingress
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *ConsoleResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + consoleSuffix, Namespace: r.pandaCluster.Namespace}
+}
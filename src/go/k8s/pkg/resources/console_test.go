@@ -0,0 +1,73 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConsoleEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Console = &redpandav1alpha1.Console{
+		Image:   "vectorized/console:latest",
+		Ingress: &redpandav1alpha1.ConsoleIngress{Host: "console.example.com"},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	console := res.NewConsole(c, cluster, scheme.Scheme, "cluster.local",
+		types.NamespacedName{}, types.NamespacedName{}, types.NamespacedName{}, types.NamespacedName{}, ctrl.Log)
+
+	require.NoError(t, console.Ensure(context.Background()))
+
+	var secret corev1.Secret
+	require.NoError(t, c.Get(context.Background(), console.Key(), &secret))
+	assert.Contains(t, string(secret.Data["config.yaml"]), "cluster.local:123")
+
+	var svc corev1.Service
+	require.NoError(t, c.Get(context.Background(), console.Key(), &svc))
+	var ingress networkingv1.Ingress
+	require.NoError(t, c.Get(context.Background(), console.Key(), &ingress))
+	assert.Equal(t, "console.example.com", ingress.Spec.Rules[0].Host)
+
+	var deployment appsv1.Deployment
+	require.NoError(t, c.Get(context.Background(), console.Key(), &deployment))
+	assert.Equal(t, svc.Spec.Selector, deployment.Spec.Template.Labels)
+	assert.Empty(t, deployment.Spec.Template.Spec.Containers[0].Env)
+	hash := deployment.Spec.Template.Annotations[res.ConsoleConfigHashAnnotationKey]
+
+	t.Run("credentials follow the cluster", func(t *testing.T) {
+		cluster.Spec.EnableSASL = true
+		require.NoError(t, console.Ensure(context.Background()))
+
+		require.NoError(t, c.Get(context.Background(), console.Key(), &secret))
+		assert.Contains(t, string(secret.Data["config.yaml"]), res.BootstrapSuperuserName)
+
+		require.NoError(t, c.Get(context.Background(), console.Key(), &deployment))
+		env := deployment.Spec.Template.Spec.Containers[0].Env
+		require.Len(t, env, 1)
+		assert.Equal(t, res.SuperuserSecretPasswordKey, env[0].ValueFrom.SecretKeyRef.Key)
+		assert.NotEqual(t, hash, deployment.Spec.Template.Annotations[res.ConsoleConfigHashAnnotationKey])
+	})
+}
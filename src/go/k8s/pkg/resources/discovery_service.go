@@ -0,0 +1,138 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ Reconciler = &DiscoveryServiceReconciler{}
+
+// DiscoveryServiceReconciler manages ExternalName Services that resolve to
+// the headless Service of the cluster from other namespaces
+type DiscoveryServiceReconciler struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	serviceFQDN  string
+	svcPorts     []NamedServicePort
+	logger       logr.Logger
+}
+
+// NewDiscoveryService creates DiscoveryServiceReconciler
+func NewDiscoveryService(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	serviceFQDN string,
+	svcPorts []NamedServicePort,
+	logger logr.Logger,
+) *DiscoveryServiceReconciler {
+	return &DiscoveryServiceReconciler{
+		client,
+		scheme,
+		pandaCluster,
+		serviceFQDN,
+		svcPorts,
+		logger.WithValues("Kind", serviceKind(), "ServiceType", corev1.ServiceTypeExternalName),
+	}
+}
+
+// Ensure creates or updates every discovery Service of the Cluster
+func (r *DiscoveryServiceReconciler) Ensure(ctx context.Context) error {
+	for _, spec := range r.pandaCluster.Spec.DiscoveryServices {
+		obj, err := r.obj(r.key(spec))
+		if err != nil {
+			return fmt.Errorf("unable to construct object: %w", err)
+		}
+		created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+		if err != nil {
+			return err
+		}
+		if created {
+			continue
+		}
+		var svc corev1.Service
+		if err := r.Get(ctx, r.key(spec), &svc); err != nil {
+			return fmt.Errorf("error while fetching Service resource: %w", err)
+		}
+		if err := Update(ctx, &svc, obj, r.Client, r.logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// obj returns the ExternalName Service with the given key. Only a Service in
+// the namespace of the Cluster gets an owner reference.
+func (r *DiscoveryServiceReconciler) obj(
+	key types.NamespacedName,
+) (k8sclient.Object, error) {
+	ports := make([]corev1.ServicePort, 0, len(r.svcPorts))
+	for _, svcPort := range r.svcPorts {
+		ports = append(ports, corev1.ServicePort{
+			Name:       svcPort.Name,
+			Protocol:   corev1.ProtocolTCP,
+			Port:       int32(svcPort.Port),
+			TargetPort: intstr.FromInt(svcPort.Port),
+		})
+	}
+
+	if err := checkDuplicatePorts(ports); err != nil {
+		return nil, err
+	}
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Labels:    labels.ForCluster(r.pandaCluster),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: r.serviceFQDN,
+			Ports:        ports,
+		},
+	}
+
+	if key.Namespace == r.pandaCluster.Namespace {
+		err := SetOwner(r.pandaCluster, svc, r.scheme)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return svc, nil
+}
+
+func (r *DiscoveryServiceReconciler) key(
+	spec redpandav1alpha1.DiscoveryService,
+) types.NamespacedName {
+	name := spec.Name
+	if name == "" {
+		name = r.pandaCluster.Name
+	}
+	return types.NamespacedName{Name: name, Namespace: spec.Namespace}
+}
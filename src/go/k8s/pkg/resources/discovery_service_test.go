@@ -0,0 +1,50 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDiscoveryServiceEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.DiscoveryServices = []redpandav1alpha1.DiscoveryService{
+		{Namespace: "apps"},
+		{Namespace: cluster.Namespace, Name: "kafka"},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	ports := []res.NamedServicePort{{Name: res.KafkaPortName, Port: 123}}
+	require.NoError(t, res.NewDiscoveryService(c, cluster, scheme.Scheme, "cluster.local", ports, ctrl.Log).
+		Ensure(context.Background()))
+
+	var other corev1.Service
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: cluster.Name, Namespace: "apps"}, &other))
+	assert.Equal(t, corev1.ServiceTypeExternalName, other.Spec.Type)
+	assert.Equal(t, "cluster.local", other.Spec.ExternalName)
+	assert.Empty(t, other.OwnerReferences, "owner references cannot cross namespaces")
+
+	var local corev1.Service
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "kafka", Namespace: cluster.Namespace}, &local))
+	assert.Len(t, local.OwnerReferences, 1)
+}
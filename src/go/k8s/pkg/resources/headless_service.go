@@ -12,6 +12,8 @@ package resources
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/go-logr/logr"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
@@ -22,7 +24,6 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 var _ Resource = &HeadlessServiceResource{}
@@ -30,6 +31,7 @@ var _ Resource = &HeadlessServiceResource{}
 const (
 	externalDNSHostname  = "external-dns.alpha.kubernetes.io/hostname"
 	externalDNSUseHostIP = "external-dns.alpha.kubernetes.io/use-external-host-ip"
+	externalDNSTTL       = "external-dns.alpha.kubernetes.io/ttl"
 )
 
 // HeadlessServiceResource is part of the reconciliation of redpanda.vectorized.io CRD
@@ -42,7 +44,9 @@ type HeadlessServiceResource struct {
 	logger       logr.Logger
 }
 
-// NewHeadlessService creates HeadlessServiceResource
+// NewHeadlessService creates HeadlessServiceResource. The ports are exposed
+// on every broker, e.g. redpanda-0.<service>, and have to include the Admin
+// API for per broker scraping and targeted Admin API calls.
 func NewHeadlessService(
 	client k8sclient.Client,
 	pandaCluster *redpandav1alpha1.Cluster,
@@ -69,12 +73,23 @@ func (r *HeadlessServiceResource) Ensure(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("unable to construct object: %w", err)
 	}
-	_, err = CreateIfNotExists(ctx, r, obj, r.logger)
-	return err
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var svc corev1.Service
+	err = r.Get(ctx, r.Key(), &svc)
+	if err != nil {
+		return fmt.Errorf("error while fetching Service resource: %w", err)
+	}
+	if managed, err := claimService(r.pandaCluster, &svc, obj, r.logger); err != nil || !managed {
+		return err
+	}
+	return Update(ctx, &svc, obj, r.Client, r.logger)
 }
 
 // obj returns resource managed client.Object
-func (r *HeadlessServiceResource) obj() (k8sclient.Object, error) {
+func (r *HeadlessServiceResource) obj() (*corev1.Service, error) {
 	ports := make([]corev1.ServicePort, 0, len(r.svcPorts))
 	for _, svcPort := range r.svcPorts {
 		ports = append(ports, corev1.ServicePort{
@@ -85,6 +100,10 @@ func (r *HeadlessServiceResource) obj() (k8sclient.Object, error) {
 		})
 	}
 
+	if err := checkDuplicatePorts(ports); err != nil {
+		return nil, err
+	}
+
 	objLabels := labels.ForCluster(r.pandaCluster)
 	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -98,14 +117,15 @@ func (r *HeadlessServiceResource) obj() (k8sclient.Object, error) {
 			APIVersion: "v1",
 		},
 		Spec: corev1.ServiceSpec{
-			Type:      corev1.ServiceTypeClusterIP,
-			ClusterIP: corev1.ClusterIPNone,
-			Ports:     ports,
-			Selector:  objLabels.AsAPISelector().MatchLabels,
+			Type:                     corev1.ServiceTypeClusterIP,
+			ClusterIP:                corev1.ClusterIPNone,
+			Ports:                    ports,
+			Selector:                 objLabels.AsAPISelector().MatchLabels,
+			PublishNotReadyAddresses: r.publishNotReadyAddresses(),
 		},
 	}
 
-	err := controllerutil.SetControllerReference(r.pandaCluster, svc, r.scheme)
+	err := SetOwner(r.pandaCluster, svc, r.scheme)
 	if err != nil {
 		return nil, err
 	}
@@ -140,10 +160,37 @@ func (r *HeadlessServiceResource) getAnnotation() map[string]string {
 		return nil
 	}
 
-	return map[string]string{
-		externalDNSHostname: r.pandaCluster.Spec.ExternalConnectivity.Subdomain,
+	annotations := map[string]string{}
+	externalDNS := r.pandaCluster.Spec.ExternalConnectivity.ExternalDNS
+	if externalDNS != nil {
+		for k, v := range externalDNS.Annotations {
+			annotations[k] = v
+		}
+	}
+	// external-dns creates records for each of the comma separated hostnames
+	annotations[externalDNSHostname] = strings.Join(r.pandaCluster.ExternalDNSHostnames(), ",")
+	if _, ok := annotations[externalDNSUseHostIP]; !ok {
 		// This annotation comes from the not merged feature
 		// https://github.com/kubernetes-sigs/external-dns/pull/1391
-		externalDNSUseHostIP: "true",
+		annotations[externalDNSUseHostIP] = "true"
+	}
+	if dns := r.pandaCluster.Spec.DNS; dns != nil && dns.ExternalRecordTTL != nil {
+		annotations[externalDNSTTL] = strconv.Itoa(int(*dns.ExternalRecordTTL))
+	}
+	if externalDNS != nil && externalDNS.TTL != nil {
+		annotations[externalDNSTTL] = strconv.Itoa(int(*externalDNS.TTL))
+	}
+	return annotations
+}
+
+func (r *HeadlessServiceResource) publishNotReadyAddresses() bool {
+	if rollout := r.pandaCluster.Spec.RolloutEndpoints; rollout != nil &&
+		rollout.PublishNotReadyAddresses && r.pandaCluster.Status.Upgrading {
+		return true
+	}
+	// brokers have to resolve each other before they join
+	if r.pandaCluster.MembershipReadinessGated() {
+		return true
 	}
+	return r.pandaCluster.Spec.DNS != nil && r.pandaCluster.Spec.DNS.PublishNotReadyAddresses
 }
@@ -0,0 +1,208 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHeadlessServiceDNS(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	cluster.Spec.ExternalConnectivity.Subdomain = "example.com"
+	cluster.Spec.DNS = &redpandav1alpha1.DNSConfig{
+		PublishNotReadyAddresses: true,
+		ExternalRecordTTL:        pointer.Int32Ptr(30),
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	ports := []res.NamedServicePort{{Name: res.KafkaPortName, Port: 123}}
+	svc := res.NewHeadlessService(c, cluster, scheme.Scheme, ports, ctrl.Log)
+	require.NoError(t, svc.Ensure(context.Background()))
+
+	var actual corev1.Service
+	require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+	assert.True(t, actual.Spec.PublishNotReadyAddresses)
+	assert.Equal(t, "30", actual.Annotations["external-dns.alpha.kubernetes.io/ttl"])
+}
+
+func TestHeadlessServiceExternalDNSAnnotations(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	cluster.Spec.ExternalConnectivity.Subdomain = "example.com"
+	cluster.Spec.DNS = &redpandav1alpha1.DNSConfig{ExternalRecordTTL: pointer.Int32Ptr(30)}
+	cluster.Spec.ExternalConnectivity.ExternalDNS = &redpandav1alpha1.ExternalDNSConfig{
+		TTL: pointer.Int32Ptr(60),
+		Annotations: map[string]string{
+			"external-dns.alpha.kubernetes.io/cloudflare-proxied": "false",
+			"external-dns.alpha.kubernetes.io/hostname":           "other.example.com",
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	ports := []res.NamedServicePort{{Name: res.KafkaPortName, Port: 123}}
+	svc := res.NewHeadlessService(c, cluster, scheme.Scheme, ports, ctrl.Log)
+	require.NoError(t, svc.Ensure(context.Background()))
+
+	var actual corev1.Service
+	require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+	assert.Equal(t, "false", actual.Annotations["external-dns.alpha.kubernetes.io/cloudflare-proxied"])
+	assert.Equal(t, "example.com", actual.Annotations["external-dns.alpha.kubernetes.io/hostname"])
+	assert.Equal(t, "true", actual.Annotations["external-dns.alpha.kubernetes.io/use-external-host-ip"])
+	assert.Equal(t, "60", actual.Annotations["external-dns.alpha.kubernetes.io/ttl"])
+}
+
+func TestHeadlessServiceRolloutEndpoints(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.RolloutEndpoints = &redpandav1alpha1.RolloutEndpoints{PublishNotReadyAddresses: true}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	ports := []res.NamedServicePort{{Name: res.KafkaPortName, Port: 123}}
+	svc := res.NewHeadlessService(c, cluster, scheme.Scheme, ports, ctrl.Log)
+	require.NoError(t, svc.Ensure(context.Background()))
+
+	var actual corev1.Service
+	require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+	assert.False(t, actual.Spec.PublishNotReadyAddresses)
+
+	// not ready brokers are only published during an upgrade
+	cluster.Status.Upgrading = true
+	require.NoError(t, svc.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+	assert.True(t, actual.Spec.PublishNotReadyAddresses)
+}
+
+func TestHeadlessServiceMembershipReadiness(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	cluster.Spec.ExternalConnectivity.MembershipReadiness = true
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	ports := []res.NamedServicePort{{Name: res.KafkaPortName, Port: 123}}
+	svc := res.NewHeadlessService(c, cluster, scheme.Scheme, ports, ctrl.Log)
+	require.NoError(t, svc.Ensure(context.Background()))
+
+	// joining brokers resolve each other before the readiness gate is set
+	var actual corev1.Service
+	require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+	assert.True(t, actual.Spec.PublishNotReadyAddresses)
+}
+
+func TestHeadlessServiceAdoption(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	ports := []res.NamedServicePort{{Name: res.KafkaPortName, Port: 123}}
+	existing := func(clusterIP string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Type:      corev1.ServiceTypeClusterIP,
+				ClusterIP: clusterIP,
+				Ports:     []corev1.ServicePort{{Name: "kafka", Port: 9092}},
+			},
+		}
+	}
+
+	t.Run("unmanaged Service is left untouched", func(t *testing.T) {
+		cluster := pandaCluster()
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster, existing(corev1.ClusterIPNone)).Build()
+		svc := res.NewHeadlessService(c, cluster, scheme.Scheme, ports, ctrl.Log)
+		require.NoError(t, svc.Ensure(context.Background()))
+
+		var actual corev1.Service
+		require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+		assert.Empty(t, actual.OwnerReferences)
+		assert.Equal(t, int32(9092), actual.Spec.Ports[0].Port)
+	})
+
+	t.Run("compatible Service is adopted", func(t *testing.T) {
+		cluster := pandaCluster()
+		cluster.Spec.AdoptServices = true
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster, existing(corev1.ClusterIPNone)).Build()
+		svc := res.NewHeadlessService(c, cluster, scheme.Scheme, ports, ctrl.Log)
+		require.NoError(t, svc.Ensure(context.Background()))
+
+		var actual corev1.Service
+		require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+		assert.True(t, res.IsManagedBy(cluster, &actual))
+		assert.Equal(t, labels.ForCluster(cluster)[labels.InstanceKey], actual.Labels[labels.InstanceKey])
+		assert.Equal(t, int32(123), actual.Spec.Ports[0].Port)
+	})
+
+	t.Run("Service with a cluster IP is not adopted", func(t *testing.T) {
+		cluster := pandaCluster()
+		cluster.Spec.AdoptServices = true
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster, existing("10.0.0.1")).Build()
+		svc := res.NewHeadlessService(c, cluster, scheme.Scheme, ports, ctrl.Log)
+		assert.Error(t, svc.Ensure(context.Background()))
+	})
+}
+
+func TestHeadlessServicePorts(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	ports := []res.NamedServicePort{
+		{Name: res.AdminPortName, Port: 125},
+		{Name: res.KafkaPortName, Port: 123},
+	}
+	svc := res.NewHeadlessService(c, cluster, scheme.Scheme, ports, ctrl.Log)
+	require.NoError(t, svc.Ensure(context.Background()))
+
+	// the Admin API and its metrics are reachable on each broker
+	var actual corev1.Service
+	require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+	require.Len(t, actual.Spec.Ports, 2)
+	assert.Equal(t, res.AdminPortName, actual.Spec.Ports[0].Name)
+	assert.Equal(t, int32(125), actual.Spec.Ports[0].Port)
+	assert.Equal(t, 125, actual.Spec.Ports[0].TargetPort.IntValue())
+	assert.Equal(t, corev1.ClusterIPNone, actual.Spec.ClusterIP)
+}
+
+func TestHeadlessServiceDuplicatePorts(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	ports := []res.NamedServicePort{
+		{Name: res.KafkaPortName, Port: 123},
+		{Name: res.PandaproxyPortName, Port: 123},
+	}
+	svc := res.NewHeadlessService(c, cluster, scheme.Scheme, ports, ctrl.Log)
+	err := svc.Ensure(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "port 123 is used by both the kafka and the proxy listener")
+
+	// no invalid Service is created
+	var actual corev1.Service
+	assert.Error(t, c.Get(context.Background(), svc.Key(), &actual))
+}
@@ -0,0 +1,214 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultInternalTopicReplicationFactor is used when the Cluster does
+	// not specify the target replication factor of internal topics
+	DefaultInternalTopicReplicationFactor = 3
+
+	membershipStatusActive = "active"
+)
+
+// DefaultInternalTopics are the internal topics managed when the Cluster
+// does not list them
+var DefaultInternalTopics = []string{"kafka_internal/group", "kafka/_schemas"}
+
+var _ AdminAPIReconciler = &InternalTopicReplicationReconciler{}
+
+// InternalTopicReplicationReconciler raises the replication factor of
+// internal topics towards the target after the cluster has been scaled up.
+// Raising the replication factor moves data, so only one partition is
+// changed per reconciliation and only when the cluster is stable.
+type InternalTopicReplicationReconciler struct {
+	k8sclient.Client
+	pandaCluster          *redpandav1alpha1.Cluster
+	adminAPIClientFactory admin.AdminAPIClientFactory
+	serviceFQDN           string
+	adminTLSProvider      admin.AdminTLSConfigProvider
+	logger                logr.Logger
+}
+
+// NewInternalTopicReplication creates InternalTopicReplicationReconciler
+func NewInternalTopicReplication(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	adminAPIClientFactory admin.AdminAPIClientFactory,
+	serviceFQDN string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+	logger logr.Logger,
+) *InternalTopicReplicationReconciler {
+	return &InternalTopicReplicationReconciler{
+		client,
+		pandaCluster,
+		adminAPIClientFactory,
+		serviceFQDN,
+		adminTLSProvider,
+		logger.WithValues("Reconciler", "internal topic replication"),
+	}
+}
+
+// RequiresAdminAPI implements AdminAPIReconciler
+func (r *InternalTopicReplicationReconciler) RequiresAdminAPI() {}
+
+// Ensure adds a replica to the first under-replicated internal partition
+func (r *InternalTopicReplicationReconciler) Ensure(ctx context.Context) error {
+	spec := r.pandaCluster.Spec.InternalTopicReplication
+	if !spec.Enabled || !r.clusterStable() {
+		return nil
+	}
+
+	adminAPI, err := r.adminAPIClientFactory(ctx, r, r.pandaCluster, r.serviceFQDN, r.adminTLSProvider)
+	if err != nil {
+		return fmt.Errorf("unable to create admin API client: %w", err)
+	}
+
+	health, err := adminAPI.ClusterHealth(ctx)
+	if admin.IsUnsupported(err) {
+		return fmt.Errorf("internal topic replication: %w", err)
+	}
+	if err != nil || !health.IsHealthy {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("waiting for healthy cluster before changing internal topic replication: %v", err)}
+	}
+
+	brokers, err := adminAPI.Brokers(ctx)
+	if err != nil {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("unable to list brokers: %v", err)}
+	}
+	var activeBrokers []int
+	for _, b := range brokers {
+		if b.MembershipStatus == "" || b.MembershipStatus == membershipStatusActive {
+			activeBrokers = append(activeBrokers, b.NodeID)
+		}
+	}
+	sort.Ints(activeBrokers)
+
+	target := spec.ReplicationFactor
+	if target == 0 {
+		target = DefaultInternalTopicReplicationFactor
+	}
+	if target > len(activeBrokers) {
+		target = len(activeBrokers)
+	}
+
+	topics := spec.Topics
+	if len(topics) == 0 {
+		topics = DefaultInternalTopics
+	}
+
+	applied := 0
+	for _, nsTopic := range topics {
+		ns, topic := splitTopic(nsTopic)
+		partitions, err := adminAPI.GetPartitions(ctx, ns, topic)
+		if admin.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return &RequeueAfterError{RequeueAfter: requeueDuration,
+				Msg: fmt.Sprintf("unable to retrieve partitions of %s: %v", nsTopic, err)}
+		}
+
+		for _, p := range partitions {
+			if len(p.Replicas) < target {
+				replicas := addReplica(p.Replicas, partitions, activeBrokers)
+				r.logger.Info("Increasing replication factor of internal topic partition, data will be moved",
+					"topic", nsTopic, "partition", p.PartitionID,
+					"current replication factor", len(p.Replicas), "target replication factor", target)
+				if err := adminAPI.SetPartitionReplicas(ctx, ns, topic, p.PartitionID, replicas); err != nil {
+					return &RequeueAfterError{RequeueAfter: requeueDuration,
+						Msg: fmt.Sprintf("unable to set replicas of %s/%d: %v", nsTopic, p.PartitionID, err)}
+				}
+				return &RequeueAfterError{RequeueAfter: requeueDuration,
+					Msg: fmt.Sprintf("waiting for %s/%d to replicate", nsTopic, p.PartitionID)}
+			}
+			if applied == 0 || len(p.Replicas) < applied {
+				applied = len(p.Replicas)
+			}
+		}
+	}
+
+	return r.updateStatus(ctx, applied)
+}
+
+// clusterStable returns true when every requested broker is ready and no
+// upgrade is in progress
+func (r *InternalTopicReplicationReconciler) clusterStable() bool {
+	return r.pandaCluster.Spec.Replicas != nil &&
+		r.pandaCluster.Status.Replicas == *r.pandaCluster.Spec.Replicas &&
+		!r.pandaCluster.Status.Upgrading
+}
+
+func (r *InternalTopicReplicationReconciler) updateStatus(
+	ctx context.Context, applied int,
+) error {
+	if r.pandaCluster.Status.InternalTopicReplicationFactor == applied {
+		return nil
+	}
+	r.pandaCluster.Status.InternalTopicReplicationFactor = applied
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return fmt.Errorf("unable to update internal topic replication factor status: %w", err)
+	}
+	return nil
+}
+
+// addReplica places a new replica on the active broker that hosts the
+// fewest replicas of the topic
+func addReplica(
+	current []admin.Replica, partitions []admin.Partition, brokers []int,
+) []admin.Replica {
+	load := map[int]int{}
+	for _, p := range partitions {
+		for _, replica := range p.Replicas {
+			load[replica.NodeID]++
+		}
+	}
+	used := map[int]bool{}
+	for _, replica := range current {
+		used[replica.NodeID] = true
+	}
+
+	candidate := -1
+	for _, b := range brokers {
+		if used[b] {
+			continue
+		}
+		if candidate == -1 || load[b] < load[candidate] {
+			candidate = b
+		}
+	}
+
+	replicas := append([]admin.Replica{}, current...)
+	if candidate != -1 {
+		replicas = append(replicas, admin.Replica{NodeID: candidate})
+	}
+	return replicas
+}
+
+// splitTopic splits namespace/topic, defaulting to the kafka namespace
+func splitTopic(nsTopic string) (namespace, topic string) {
+	if i := strings.Index(nsTopic, "/"); i >= 0 {
+		return nsTopic[:i], nsTopic[i+1:]
+	}
+	return "kafka", nsTopic
+}
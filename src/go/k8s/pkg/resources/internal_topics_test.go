@@ -0,0 +1,64 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestInternalTopicReplicationEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	cluster.Status.Replicas = 3
+	cluster.Spec.InternalTopicReplication = redpandav1alpha1.InternalTopicReplication{
+		Enabled: true,
+		Topics:  []string{"kafka_internal/group"},
+	}
+
+	// this version does not report the cluster health and does not list or
+	// move the partitions
+	adminAPI := admin.NewMockAdminAPI()
+	adminAPI.Stubbed = true
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	ensure := func() error {
+		return res.NewInternalTopicReplication(c, cluster, adminAPI.Factory(), "cluster.local", nil, ctrl.Log).
+			Ensure(context.Background())
+	}
+
+	err := ensure()
+	assert.True(t, admin.IsUnsupported(err))
+	var requeue *res.RequeueAfterError
+	assert.False(t, errors.As(err, &requeue))
+	assert.Zero(t, cluster.Status.InternalTopicReplicationFactor)
+
+	t.Run("disabled by default", func(t *testing.T) {
+		disabled := cluster.DeepCopy()
+		disabled.Spec.InternalTopicReplication.Enabled = false
+		adminAPI.Err = errors.New("admin API must not be called")
+		err := res.NewInternalTopicReplication(c, disabled, adminAPI.Factory(), "cluster.local", nil, ctrl.Log).
+			Ensure(context.Background())
+		assert.NoError(t, err)
+	})
+}
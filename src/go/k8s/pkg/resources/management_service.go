@@ -0,0 +1,147 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const managementServiceSuffix = "-management"
+
+var _ Resource = &ManagementServiceResource{}
+
+// ManagementServiceResource manages the NodePort Service <cluster>-management
+// that exposes the Admin API on the fixed port of the management node port,
+// e.g. for rpk on a jump host
+type ManagementServiceResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	logger       logr.Logger
+}
+
+// NewManagementService creates ManagementServiceResource
+func NewManagementService(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	logger logr.Logger,
+) *ManagementServiceResource {
+	return &ManagementServiceResource{
+		client,
+		scheme,
+		pandaCluster,
+		logger.WithValues(
+			"Kind", serviceKind(),
+			"ServiceType", "ManagementNodePort",
+		),
+	}
+}
+
+// Ensure creates or updates the management Service and deletes it when the
+// management node port is removed
+func (r *ManagementServiceResource) Ensure(ctx context.Context) error {
+	if r.pandaCluster.Spec.ManagementNodePort == nil {
+		return r.deleteIfExists(ctx)
+	}
+
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var svc corev1.Service
+	if err := r.Get(ctx, r.Key(), &svc); err != nil {
+		return fmt.Errorf("error while fetching Service resource: %w", err)
+	}
+	// the node port is fixed, only the cluster IP is allocated
+	obj.Spec.ClusterIP = svc.Spec.ClusterIP
+	return Update(ctx, &svc, obj, r.Client, r.logger)
+}
+
+func (r *ManagementServiceResource) deleteIfExists(ctx context.Context) error {
+	var svc corev1.Service
+	err := r.Get(ctx, r.Key(), &svc)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching Service resource: %w", err)
+	}
+	if !IsManagedBy(r.pandaCluster, &svc) {
+		return nil
+	}
+	r.logger.Info("Deleting the management Service of the removed node port")
+	if err := r.Delete(ctx, &svc); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete management Service: %w", err)
+	}
+	return nil
+}
+
+// obj returns resource managed client.Object
+func (r *ManagementServiceResource) obj() (*corev1.Service, error) {
+	port := r.pandaCluster.Spec.Configuration.AdminAPI.Port
+	objLabels := labels.ForCluster(r.pandaCluster)
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    objLabels,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeNodePort,
+			// The NetworkPolicy matches the source ranges against the
+			// client address, which the Local policy does not translate
+			ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       AdminPortName,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       int32(port),
+					TargetPort: intstr.FromInt(port),
+					NodePort:   r.pandaCluster.Spec.ManagementNodePort.NodePort,
+				},
+			},
+			Selector: objLabels.AsAPISelector().MatchLabels,
+		},
+	}
+
+	err := SetOwner(r.pandaCluster, svc, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *ManagementServiceResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + managementServiceSuffix, Namespace: r.pandaCluster.Namespace}
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestManagementServiceEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.ManagementNodePort = &redpandav1alpha1.ManagementNodePort{
+		NodePort:     31644,
+		SourceRanges: []string{"10.1.0.0/24"},
+	}
+	cluster.Spec.NetworkPolicy = &redpandav1alpha1.NetworkPolicy{Enabled: true}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	svc := res.NewManagementService(c, cluster, scheme.Scheme, ctrl.Log)
+	require.NoError(t, svc.Ensure(context.Background()))
+
+	var actual corev1.Service
+	require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+	assert.Equal(t, "cluster-management", actual.Name)
+	assert.Equal(t, corev1.ServiceTypeNodePort, actual.Spec.Type)
+	assert.Equal(t, corev1.ServiceExternalTrafficPolicyTypeLocal, actual.Spec.ExternalTrafficPolicy)
+	require.Len(t, actual.Spec.Ports, 1)
+	assert.Equal(t, int32(125), actual.Spec.Ports[0].Port)
+	assert.Equal(t, int32(31644), actual.Spec.Ports[0].NodePort)
+
+	// the NetworkPolicy admits the source ranges to the Admin API
+	np := res.NewNetworkPolicy(c, cluster, scheme.Scheme, "", ctrl.Log)
+	require.NoError(t, np.Ensure(context.Background()))
+	var policy networkingv1.NetworkPolicy
+	require.NoError(t, c.Get(context.Background(), np.Key(), &policy))
+	management := policy.Spec.Ingress[len(policy.Spec.Ingress)-1]
+	require.Len(t, management.From, 1)
+	assert.Equal(t, "10.1.0.0/24", management.From[0].IPBlock.CIDR)
+	assert.Equal(t, 125, management.Ports[0].Port.IntValue())
+
+	cluster.Spec.ManagementNodePort = nil
+	require.NoError(t, svc.Ensure(context.Background()))
+	err := c.Get(context.Background(), svc.Key(), &actual)
+	assert.True(t, apierrors.IsNotFound(err))
+}
@@ -0,0 +1,221 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// namespaceNameLabel is set by Kubernetes on every namespace since 1.21
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+var _ Reconciler = &NetworkPolicyResource{}
+
+// NetworkPolicyResource is part of the reconciliation of redpanda.vectorized.io CRD
+// restricting the traffic that reaches the brokers
+type NetworkPolicyResource struct {
+	k8sclient.Client
+	scheme            *runtime.Scheme
+	pandaCluster      *redpandav1alpha1.Cluster
+	operatorNamespace string
+	logger            logr.Logger
+}
+
+// NewNetworkPolicy creates NetworkPolicyResource. The Admin API is opened to
+// the operatorNamespace, when it is known.
+func NewNetworkPolicy(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	operatorNamespace string,
+	logger logr.Logger,
+) *NetworkPolicyResource {
+	return &NetworkPolicyResource{
+		client,
+		scheme,
+		pandaCluster,
+		operatorNamespace,
+		logger.WithValues("Kind", "NetworkPolicy"),
+	}
+}
+
+// Ensure creates or updates the NetworkPolicy of the brokers and deletes it
+// when it was disabled
+func (r *NetworkPolicyResource) Ensure(ctx context.Context) error {
+	policy := r.pandaCluster.Spec.NetworkPolicy
+	if policy == nil || !policy.Enabled {
+		return r.deleteIfExists(ctx)
+	}
+
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var np networkingv1.NetworkPolicy
+	if err := r.Get(ctx, r.Key(), &np); err != nil {
+		return fmt.Errorf("error while fetching NetworkPolicy resource: %w", err)
+	}
+	return Update(ctx, &np, obj, r.Client, r.logger)
+}
+
+func (r *NetworkPolicyResource) deleteIfExists(ctx context.Context) error {
+	var np networkingv1.NetworkPolicy
+	err := r.Get(ctx, r.Key(), &np)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching NetworkPolicy resource: %w", err)
+	}
+	if !IsManagedBy(r.pandaCluster, &np) {
+		return nil
+	}
+	r.logger.Info("Deleting the disabled NetworkPolicy")
+	if err := r.Delete(ctx, &np); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete NetworkPolicy: %w", err)
+	}
+	return nil
+}
+
+// obj returns resource managed client.Object
+func (r *NetworkPolicyResource) obj() (k8sclient.Object, error) {
+	conf := r.pandaCluster.Spec.Configuration
+	kafkaPort := intstr.FromInt(conf.KafkaAPI.Port)
+	adminPort := intstr.FromInt(conf.AdminAPI.Port)
+	brokers := labels.ForCluster(r.pandaCluster).AsAPISelector()
+	console := labels.ForConsole(r.pandaCluster).AsAPISelector()
+
+	ingress := []networkingv1.NetworkPolicyIngressRule{
+		{
+			From: []networkingv1.NetworkPolicyPeer{{PodSelector: brokers}},
+		},
+		{
+			From:  []networkingv1.NetworkPolicyPeer{{PodSelector: console}},
+			Ports: tcpPorts(kafkaPort, adminPort),
+		},
+	}
+
+	var clients []networkingv1.NetworkPolicyPeer
+	for _, ns := range r.pandaCluster.Spec.NetworkPolicy.AllowedClientNamespaces {
+		clients = append(clients, namespacePeer(ns))
+	}
+	for i := range r.pandaCluster.Spec.NetworkPolicy.AllowedClientSelectors {
+		clients = append(clients, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &r.pandaCluster.Spec.NetworkPolicy.AllowedClientSelectors[i],
+		})
+	}
+	clientPorts := []intstr.IntOrString{kafkaPort}
+	if conf.PandaproxyAPI != nil {
+		clientPorts = append(clientPorts, intstr.FromInt(conf.PandaproxyAPI.Port))
+	}
+	if len(clients) > 0 {
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			From:  clients,
+			Ports: tcpPorts(clientPorts...),
+		})
+	}
+
+	if r.operatorNamespace != "" {
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			From:  []networkingv1.NetworkPolicyPeer{namespacePeer(r.operatorNamespace)},
+			Ports: tcpPorts(adminPort),
+		})
+	}
+
+	// An ingress rule without peers allows every source
+	if r.pandaCluster.Spec.ExternalConnectivity.Enabled {
+		externalPorts := []intstr.IntOrString{intstr.FromInt(calculateExternalPort(conf.KafkaAPI.Port))}
+		if r.pandaCluster.PandaproxyExternal() {
+			externalPorts = append(externalPorts, intstr.FromInt(calculateExternalPort(conf.PandaproxyAPI.Port)))
+		}
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			Ports: tcpPorts(externalPorts...),
+		})
+	}
+	if management := r.pandaCluster.Spec.ManagementNodePort; management != nil {
+		var sources []networkingv1.NetworkPolicyPeer
+		for _, cidr := range management.SourceRanges {
+			sources = append(sources, networkingv1.NetworkPolicyPeer{
+				IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+			})
+		}
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			From:  sources,
+			Ports: tcpPorts(adminPort),
+		})
+	}
+
+	np := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    labels.ForCluster(r.pandaCluster),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *brokers,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     ingress,
+		},
+	}
+
+	err := SetOwner(r.pandaCluster, np, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return np, nil
+}
+
+func namespacePeer(namespace string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: metav1.SetAsLabelSelector(map[string]string{
+			namespaceNameLabel: namespace,
+		}),
+	}
+}
+
+func tcpPorts(ports ...intstr.IntOrString) []networkingv1.NetworkPolicyPort {
+	protocol := corev1.ProtocolTCP
+	result := make([]networkingv1.NetworkPolicyPort, 0, len(ports))
+	for i := range ports {
+		result = append(result, networkingv1.NetworkPolicyPort{
+			Protocol: &protocol,
+			Port:     &ports[i],
+		})
+	}
+	return result
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *NetworkPolicyResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name, Namespace: r.pandaCluster.Namespace}
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNetworkPolicyEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.AdminAPI.Port = 9644
+	cluster.Spec.NetworkPolicy = &redpandav1alpha1.NetworkPolicy{
+		Enabled:                 true,
+		AllowedClientNamespaces: []string{"apps"},
+		AllowedClientSelectors: []metav1.LabelSelector{
+			{MatchLabels: map[string]string{"team": "data"}},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	np := res.NewNetworkPolicy(c, cluster, scheme.Scheme, "redpanda-system", ctrl.Log)
+	require.NoError(t, np.Ensure(context.Background()))
+
+	var actual networkingv1.NetworkPolicy
+	require.NoError(t, c.Get(context.Background(), np.Key(), &actual))
+	assert.Len(t, actual.OwnerReferences, 1)
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, actual.Spec.PolicyTypes)
+	assert.Equal(t, "cluster", actual.Spec.PodSelector.MatchLabels["app.kubernetes.io/instance"])
+
+	// brokers, console, clients and operator
+	require.Len(t, actual.Spec.Ingress, 4)
+	assert.Empty(t, actual.Spec.Ingress[0].Ports, "brokers reach each other on every port")
+	clients := actual.Spec.Ingress[2]
+	require.Len(t, clients.From, 2)
+	assert.Equal(t, "apps", clients.From[0].NamespaceSelector.MatchLabels["kubernetes.io/metadata.name"])
+	assert.Equal(t, "data", clients.From[1].NamespaceSelector.MatchLabels["team"])
+	require.Len(t, clients.Ports, 1)
+	assert.Equal(t, 123, clients.Ports[0].Port.IntValue())
+	operator := actual.Spec.Ingress[3]
+	assert.Equal(t, "redpanda-system", operator.From[0].NamespaceSelector.MatchLabels["kubernetes.io/metadata.name"])
+	assert.Equal(t, 9644, operator.Ports[0].Port.IntValue())
+
+	cluster.Spec.NetworkPolicy.Enabled = false
+	require.NoError(t, np.Ensure(context.Background()))
+	err := c.Get(context.Background(), np.Key(), &actual)
+	assert.True(t, apierrors.IsNotFound(err))
+}
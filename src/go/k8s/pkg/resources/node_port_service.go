@@ -22,7 +22,6 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 var _ Resource = &NodePortServiceResource{}
@@ -65,15 +64,40 @@ func (r *NodePortServiceResource) Ensure(ctx context.Context) error {
 		return fmt.Errorf("unable to construct object: %w", err)
 	}
 
-	_, err = CreateIfNotExists(ctx, r, obj, r.logger)
-	return err
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var svc corev1.Service
+	err = r.Get(ctx, r.Key(), &svc)
+	if err != nil {
+		return fmt.Errorf("error while fetching Service resource: %w", err)
+	}
+	keepAllocatedPorts(&svc, obj)
+	return Update(ctx, &svc, obj, r.Client, r.logger)
+}
+
+// keepAllocatedPorts copies the cluster IP and the node ports allocated to
+// the current Service, the brokers advertise the node ports, so adding a
+// listener must not move the ports of the others
+func keepAllocatedPorts(current, modified *corev1.Service) {
+	modified.Spec.ClusterIP = current.Spec.ClusterIP
+	for i := range modified.Spec.Ports {
+		for _, port := range current.Spec.Ports {
+			if port.Name == modified.Spec.Ports[i].Name {
+				modified.Spec.Ports[i].NodePort = port.NodePort
+			}
+		}
+	}
 }
 
 // obj returns resource managed client.Object
-func (r *NodePortServiceResource) obj() (k8sclient.Object, error) {
+func (r *NodePortServiceResource) obj() (*corev1.Service, error) {
 	ports := make([]corev1.ServicePort, 0, len(r.svcPorts))
 	for _, svcPort := range r.svcPorts {
-		if svcPort.Name == "kafka" {
+		// The external Kafka API and Pandaproxy listeners listen on the
+		// port next to the internal ones
+		if svcPort.Name == KafkaPortName || svcPort.Name == PandaproxyPortName {
 			svcPort.Port++
 		}
 		ports = append(ports, corev1.ServicePort{
@@ -84,6 +108,10 @@ func (r *NodePortServiceResource) obj() (k8sclient.Object, error) {
 		})
 	}
 
+	if err := checkDuplicatePorts(ports); err != nil {
+		return nil, err
+	}
+
 	objLabels := labels.ForCluster(r.pandaCluster)
 	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -115,7 +143,7 @@ func (r *NodePortServiceResource) obj() (k8sclient.Object, error) {
 		},
 	}
 
-	err := controllerutil.SetControllerReference(r.pandaCluster, svc, r.scheme)
+	err := SetOwner(r.pandaCluster, svc, r.scheme)
 	if err != nil {
 		return nil, err
 	}
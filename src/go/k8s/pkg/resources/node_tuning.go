@@ -0,0 +1,207 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	nodeTuningSuffix        = "-node-tuning"
+	nodeTuningContainerName = "tuning"
+	nodeTuningPauseName     = "pause"
+)
+
+var _ Reconciler = &NodeTuningResource{}
+
+// NodeTuningResource is part of the reconciliation of redpanda.vectorized.io CRD
+// running a DaemonSet that tunes the kernel parameters and block devices of
+// the nodes of the brokers
+type NodeTuningResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	logger       logr.Logger
+}
+
+// NewNodeTuning creates NodeTuningResource
+func NewNodeTuning(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	logger logr.Logger,
+) *NodeTuningResource {
+	return &NodeTuningResource{
+		client,
+		scheme,
+		pandaCluster,
+		logger.WithValues("Kind", daemonSetKind()),
+	}
+}
+
+// Ensure creates or updates the node tuning DaemonSet when it is enabled and
+// deletes it otherwise
+func (r *NodeTuningResource) Ensure(ctx context.Context) error {
+	tuning := r.pandaCluster.Spec.NodeTuning
+	if tuning == nil || !tuning.Privileged ||
+		!r.pandaCluster.FeatureGateEnabled(redpandav1alpha1.FeatureGateNodeTuning) {
+		return r.deleteIfExists(ctx)
+	}
+
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var ds appsv1.DaemonSet
+	if err := r.Get(ctx, r.Key(), &ds); err != nil {
+		return fmt.Errorf("error while fetching DaemonSet resource: %w", err)
+	}
+	return Update(ctx, &ds, obj, r.Client, r.logger)
+}
+
+func (r *NodeTuningResource) deleteIfExists(ctx context.Context) error {
+	var ds appsv1.DaemonSet
+	err := r.Get(ctx, r.Key(), &ds)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching DaemonSet resource: %w", err)
+	}
+	if !IsManagedBy(r.pandaCluster, &ds) {
+		return nil
+	}
+	r.logger.Info("Deleting the disabled node tuning DaemonSet")
+	if err := r.Delete(ctx, &ds); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete DaemonSet: %w", err)
+	}
+	return nil
+}
+
+// obj returns resource managed client.Object
+func (r *NodeTuningResource) obj() (k8sclient.Object, error) {
+	tuning := r.pandaCluster.Spec.NodeTuning
+	objLabels := labels.ForNodeTuning(r.pandaCluster)
+	nodeSelector := tuning.NodeSelector
+	if len(nodeSelector) == 0 {
+		nodeSelector = r.pandaCluster.BrokerNodeSelector()
+	}
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.Key().Name,
+			Namespace: r.Key().Namespace,
+			Labels:    objLabels,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       daemonSetKind(),
+			APIVersion: "apps/v1",
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: objLabels.AsAPISelector(),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: objLabels,
+				},
+				Spec: corev1.PodSpec{
+					HostNetwork:  true,
+					NodeSelector: nodeSelector,
+					Tolerations:  r.pandaCluster.BrokerTolerations(),
+					// the tuning is applied by the init container, so it
+					// runs again whenever the Pod starts on a node
+					InitContainers: []corev1.Container{
+						{
+							Name:            nodeTuningContainerName,
+							Image:           r.pandaCluster.FullImageName(),
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"/bin/sh", "-c", nodeTuningScript(tuning)},
+							SecurityContext: &corev1.SecurityContext{
+								Privileged: pointer.BoolPtr(true),
+							},
+							Resources: tuning.Resources,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            nodeTuningPauseName,
+							Image:           r.pandaCluster.FullImageName(),
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command: []string{"/bin/sh", "-c",
+								"trap 'exit 0' TERM; while true; do sleep 3600 & wait $!; done"},
+							Resources: tuning.Resources,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := SetOwner(r.pandaCluster, ds, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return ds, nil
+}
+
+// nodeTuningScript writes the kernel parameters to /proc/sys and the disk
+// scheduler to the devices. The values are validated by the webhook to not
+// need quoting, the order is stable so the Pod template only changes with
+// the tuning.
+func nodeTuningScript(tuning *redpandav1alpha1.NodeTuning) string {
+	keys := make([]string, 0, len(tuning.Sysctls))
+	for key := range tuning.Sysctls {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := []string{"set -e"}
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("echo '%s' > /proc/sys/%s",
+			tuning.Sysctls[key], strings.ReplaceAll(key, ".", "/")))
+	}
+	if tuning.DiskScheduler != "" {
+		for _, device := range tuning.Devices {
+			lines = append(lines, fmt.Sprintf("echo %s > /sys/block/%s/queue/scheduler",
+				tuning.DiskScheduler, device))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func daemonSetKind() string {
+	var ds appsv1.DaemonSet
+	return ds.Kind
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *NodeTuningResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + nodeTuningSuffix, Namespace: r.pandaCluster.Namespace}
+}
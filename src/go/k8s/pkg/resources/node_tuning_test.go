@@ -0,0 +1,86 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNodeTuningEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.NodeSelector = map[string]string{"node-role": "redpanda"}
+	cluster.Spec.FeatureGates = map[string]bool{redpandav1alpha1.FeatureGateNodeTuning: true}
+	cluster.Spec.NodeTuning = &redpandav1alpha1.NodeTuning{
+		Privileged: true,
+		Sysctls: map[string]string{
+			"net.core.rmem_max":          "16777216",
+			"fs.aio-max-nr":              "1048576",
+			"net.ipv4.tcp_rmem":          "4096 87380 16777216",
+			"vm.swappiness":              "1",
+			"net.core.somaxconn":         "4096",
+			"net.core.wmem_max":          "16777216",
+			"kernel.sched_rt_runtime_us": "-1",
+		},
+		DiskScheduler: "none",
+		Devices:       []string{"nvme0n1"},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	tuning := res.NewNodeTuning(c, cluster, scheme.Scheme, ctrl.Log)
+	require.NoError(t, tuning.Ensure(context.Background()))
+
+	var ds appsv1.DaemonSet
+	require.NoError(t, c.Get(context.Background(), tuning.Key(), &ds))
+	assert.Equal(t, "cluster-node-tuning", ds.Name)
+	assert.Equal(t, "node-tuning", ds.Spec.Selector.MatchLabels["app.kubernetes.io/component"])
+	pod := ds.Spec.Template.Spec
+	assert.True(t, pod.HostNetwork)
+	assert.Equal(t, cluster.Spec.NodeSelector, pod.NodeSelector)
+	require.Len(t, pod.InitContainers, 1)
+	require.NotNil(t, pod.InitContainers[0].SecurityContext)
+	assert.True(t, *pod.InitContainers[0].SecurityContext.Privileged)
+	assert.Equal(t, []string{"/bin/sh", "-c", "set -e\n" +
+		"echo '1048576' > /proc/sys/fs/aio-max-nr\n" +
+		"echo '-1' > /proc/sys/kernel/sched_rt_runtime_us\n" +
+		"echo '16777216' > /proc/sys/net/core/rmem_max\n" +
+		"echo '4096' > /proc/sys/net/core/somaxconn\n" +
+		"echo '16777216' > /proc/sys/net/core/wmem_max\n" +
+		"echo '4096 87380 16777216' > /proc/sys/net/ipv4/tcp_rmem\n" +
+		"echo '1' > /proc/sys/vm/swappiness\n" +
+		"echo none > /sys/block/nvme0n1/queue/scheduler",
+	}, pod.InitContainers[0].Command)
+	require.Len(t, pod.Containers, 1)
+	assert.Nil(t, pod.Containers[0].SecurityContext)
+
+	// the tuning selects its own nodes
+	cluster.Spec.NodeTuning.NodeSelector = map[string]string{"tuned": "true"}
+	require.NoError(t, tuning.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), tuning.Key(), &ds))
+	assert.Equal(t, map[string]string{"tuned": "true"}, ds.Spec.Template.Spec.NodeSelector)
+
+	// disabling the feature gate removes the DaemonSet
+	cluster.Spec.FeatureGates[redpandav1alpha1.FeatureGateNodeTuning] = false
+	require.NoError(t, tuning.Ensure(context.Background()))
+	err := c.Get(context.Background(), tuning.Key(), &ds)
+	assert.True(t, apierrors.IsNotFound(err))
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// SetOwner makes the cluster the controller of a generated object, unless
+// owner references are disabled for the cluster
+func SetOwner(
+	pandaCluster *redpandav1alpha1.Cluster, obj metav1.Object, scheme *runtime.Scheme,
+) error {
+	if pandaCluster.OwnerReferencesDisabled() {
+		return nil
+	}
+	return controllerutil.SetControllerReference(pandaCluster, obj, scheme)
+}
+
+// IsManagedBy returns true when the object was generated for the cluster,
+// judged by the controller reference or, when owner references are disabled,
+// by the labels of the object
+func IsManagedBy(
+	pandaCluster *redpandav1alpha1.Cluster, obj metav1.Object,
+) bool {
+	if pandaCluster.OwnerReferencesDisabled() {
+		return labels.ForCluster(pandaCluster).AsOwnerSelector().
+			Matches(k8slabels.Set(obj.GetLabels()))
+	}
+	return metav1.IsControlledBy(obj, pandaCluster)
+}
+
+// claimService returns true when the existing Service is managed by the
+// cluster or can be adopted. A Service that is not managed by the cluster is
+// adopted when the cluster allows it, it is not controlled by another object
+// and its type matches the generated one; the type and the cluster IP of a
+// Service can not be changed in place.
+func claimService(
+	pandaCluster *redpandav1alpha1.Cluster,
+	current, desired *corev1.Service,
+	logger logr.Logger,
+) (bool, error) {
+	if IsManagedBy(pandaCluster, current) {
+		return true, nil
+	}
+	if !pandaCluster.Spec.AdoptServices {
+		logger.Info(fmt.Sprintf("Service %s exists and is not managed by the cluster, leaving it untouched", current.Name))
+		return false, nil
+	}
+	if owner := metav1.GetControllerOf(current); owner != nil {
+		return false, fmt.Errorf("service %s is controlled by %s %s and cannot be adopted", current.Name, owner.Kind, owner.Name)
+	}
+	if current.Spec.Type != desired.Spec.Type {
+		return false, fmt.Errorf("service %s of type %s cannot be adopted as %s", current.Name, current.Spec.Type, desired.Spec.Type)
+	}
+	if (current.Spec.ClusterIP == corev1.ClusterIPNone) != (desired.Spec.ClusterIP == corev1.ClusterIPNone) {
+		return false, fmt.Errorf("service %s cannot be adopted, headless and ClusterIP Services cannot be converted", current.Name)
+	}
+	logger.Info(fmt.Sprintf("Adopting Service %s", current.Name))
+	return true, nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestSetOwner(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	owned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owned", Namespace: "default"}}
+	require.NoError(t, res.SetOwner(cluster, owned, scheme.Scheme))
+	assert.True(t, metav1.IsControlledBy(owned, cluster))
+	assert.True(t, res.IsManagedBy(cluster, owned))
+
+	cluster.Annotations = map[string]string{redpandav1alpha1.SkipOwnerReferencesAnnotation: "true"}
+	labeled := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      "labeled",
+		Namespace: "default",
+		Labels:    labels.ForCluster(cluster),
+	}}
+	require.NoError(t, res.SetOwner(cluster, labeled, scheme.Scheme))
+	assert.Empty(t, labeled.OwnerReferences)
+	assert.True(t, res.IsManagedBy(cluster, labeled))
+
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      "other",
+		Namespace: "default",
+		Labels:    map[string]string{labels.InstanceKey: "other"},
+	}}
+	assert.False(t, res.IsManagedBy(cluster, other))
+}
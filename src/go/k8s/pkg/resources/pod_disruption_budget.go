@@ -0,0 +1,130 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const defaultMaxUnavailable = 1
+
+var _ Reconciler = &PodDisruptionBudgetResource{}
+
+// PodDisruptionBudgetResource is part of the reconciliation of redpanda.vectorized.io CRD
+// limiting the brokers evicted at the same time while they drain
+type PodDisruptionBudgetResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	logger       logr.Logger
+}
+
+// NewPodDisruptionBudget creates PodDisruptionBudgetResource
+func NewPodDisruptionBudget(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	logger logr.Logger,
+) *PodDisruptionBudgetResource {
+	return &PodDisruptionBudgetResource{
+		client,
+		scheme,
+		pandaCluster,
+		logger.WithValues("Kind", "PodDisruptionBudget"),
+	}
+}
+
+// Ensure creates or updates the PodDisruptionBudget of the brokers when they
+// drain on shutdown and deletes it otherwise
+func (r *PodDisruptionBudgetResource) Ensure(ctx context.Context) error {
+	if r.pandaCluster.Spec.GracefulShutdown == nil {
+		return r.deleteIfExists(ctx)
+	}
+
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var pdb policyv1beta1.PodDisruptionBudget
+	if err := r.Get(ctx, r.Key(), &pdb); err != nil {
+		return fmt.Errorf("error while fetching PodDisruptionBudget resource: %w", err)
+	}
+	return Update(ctx, &pdb, obj, r.Client, r.logger)
+}
+
+func (r *PodDisruptionBudgetResource) deleteIfExists(ctx context.Context) error {
+	var pdb policyv1beta1.PodDisruptionBudget
+	err := r.Get(ctx, r.Key(), &pdb)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching PodDisruptionBudget resource: %w", err)
+	}
+	if !IsManagedBy(r.pandaCluster, &pdb) {
+		return nil
+	}
+	r.logger.Info("Deleting the PodDisruptionBudget as graceful shutdown is disabled")
+	if err := r.Delete(ctx, &pdb); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete PodDisruptionBudget: %w", err)
+	}
+	return nil
+}
+
+// obj returns resource managed client.Object
+func (r *PodDisruptionBudgetResource) obj() (k8sclient.Object, error) {
+	maxUnavailable := intstr.FromInt(int(int32OrDefault(
+		r.pandaCluster.Spec.GracefulShutdown.MaxUnavailable, defaultMaxUnavailable)))
+
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    labels.ForCluster(r.pandaCluster),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: "policy/v1beta1",
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			Selector:       labels.ForCluster(r.pandaCluster).AsAPISelector(),
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+
+	err := SetOwner(r.pandaCluster, pdb, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdb, nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *PodDisruptionBudgetResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name, Namespace: r.pandaCluster.Namespace}
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodDisruptionBudgetEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.GracefulShutdown = &redpandav1alpha1.GracefulShutdown{}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	pdb := res.NewPodDisruptionBudget(c, cluster, scheme.Scheme, ctrl.Log)
+	require.NoError(t, pdb.Ensure(context.Background()))
+
+	var actual policyv1beta1.PodDisruptionBudget
+	require.NoError(t, c.Get(context.Background(), pdb.Key(), &actual))
+	assert.Equal(t, 1, actual.Spec.MaxUnavailable.IntValue())
+	assert.Equal(t, "cluster", actual.Spec.Selector.MatchLabels["app.kubernetes.io/instance"])
+
+	cluster.Spec.GracefulShutdown.MaxUnavailable = pointer.Int32Ptr(2)
+	require.NoError(t, pdb.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), pdb.Key(), &actual))
+	assert.Equal(t, 2, actual.Spec.MaxUnavailable.IntValue())
+
+	cluster.Spec.GracefulShutdown = nil
+	require.NoError(t, pdb.Ensure(context.Background()))
+	err := c.Get(context.Background(), pdb.Key(), &actual)
+	assert.True(t, apierrors.IsNotFound(err))
+}
@@ -0,0 +1,218 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const defaultMetricsPath = "/metrics"
+
+// PodMonitorGVK is the kind of the Prometheus operator PodMonitor. Its types
+// are not vendored, the object is handled as unstructured.
+var PodMonitorGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "PodMonitor",
+}
+
+var _ Reconciler = &PodMonitorResource{}
+
+// PodMonitorResource is part of the reconciliation of redpanda.vectorized.io CRD
+// letting Prometheus scrape the metrics of the brokers from their Pods
+type PodMonitorResource struct {
+	k8sclient.Client
+	scheme             *runtime.Scheme
+	pandaCluster       *redpandav1alpha1.Cluster
+	serviceFQDN        string
+	adminAPINodeCert   types.NamespacedName
+	adminAPIClientCert types.NamespacedName
+	logger             logr.Logger
+}
+
+// NewPodMonitor creates PodMonitorResource
+func NewPodMonitor(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	serviceFQDN string,
+	adminAPINodeCert types.NamespacedName,
+	adminAPIClientCert types.NamespacedName,
+	logger logr.Logger,
+) *PodMonitorResource {
+	return &PodMonitorResource{
+		client,
+		scheme,
+		pandaCluster,
+		serviceFQDN,
+		adminAPINodeCert,
+		adminAPIClientCert,
+		logger.WithValues("Kind", PodMonitorGVK.Kind),
+	}
+}
+
+// Ensure creates or updates the PodMonitor of the brokers when it is
+// enabled and deletes it otherwise
+func (r *PodMonitorResource) Ensure(ctx context.Context) error {
+	if r.pandaCluster.Spec.PodMonitor == nil {
+		return r.deleteIfExists(ctx)
+	}
+
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	podMonitor := newPodMonitor()
+	if err := r.Get(ctx, r.Key(), podMonitor); err != nil {
+		return fmt.Errorf("error while fetching PodMonitor resource: %w", err)
+	}
+	return Update(ctx, podMonitor, obj, r.Client, r.logger)
+}
+
+func (r *PodMonitorResource) deleteIfExists(ctx context.Context) error {
+	podMonitor := newPodMonitor()
+	err := r.Get(ctx, r.Key(), podMonitor)
+	// Without the PodMonitor CRD there is nothing to delete, and without the
+	// permission to read PodMonitors the operator never created one
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) || apierrors.IsForbidden(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching PodMonitor resource: %w", err)
+	}
+	if !IsManagedBy(r.pandaCluster, podMonitor) {
+		return nil
+	}
+	r.logger.Info("Deleting the disabled PodMonitor")
+	if err := r.Delete(ctx, podMonitor); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete PodMonitor: %w", err)
+	}
+	return nil
+}
+
+// obj returns resource managed client.Object
+func (r *PodMonitorResource) obj() (k8sclient.Object, error) {
+	spec := r.pandaCluster.Spec.PodMonitor
+	objLabels := labels.ForCluster(r.pandaCluster)
+	for k, v := range spec.Labels {
+		if _, ok := objLabels[k]; !ok {
+			objLabels[k] = v
+		}
+	}
+
+	path := spec.Path
+	if path == "" {
+		path = defaultMetricsPath
+	}
+	// the Admin API port is named differently with external connectivity,
+	// it is selected by number
+	endpoint := map[string]interface{}{
+		"targetPort": int64(r.pandaCluster.Spec.Configuration.AdminAPI.Port),
+		"path":       path,
+		"scheme":     "http",
+	}
+	if spec.Interval != nil {
+		endpoint["interval"] = prometheusDuration(spec.Interval.Duration)
+	}
+	if tls := r.pandaCluster.Spec.Configuration.TLS.AdminAPI; tls.Enabled {
+		endpoint["scheme"] = "https"
+		// Prometheus scrapes the Pod IPs, the certificates are issued for
+		// the headless Service
+		tlsConfig := map[string]interface{}{
+			"serverName": r.serviceFQDN,
+			"ca":         prometheusSecretKey(r.adminAPINodeCert.Name, cmetav1.TLSCAKey),
+		}
+		if tls.RequireClientAuth {
+			tlsConfig["cert"] = prometheusSecretKey(r.adminAPIClientCert.Name, corev1.TLSCertKey)
+			tlsConfig["keySecret"] = map[string]interface{}{
+				"name": r.adminAPIClientCert.Name,
+				"key":  corev1.TLSPrivateKeyKey,
+			}
+		}
+		endpoint["tlsConfig"] = tlsConfig
+	}
+
+	matchLabels := labels.ForCluster(r.pandaCluster).AsAPISelector().MatchLabels
+	selector := make(map[string]interface{}, len(matchLabels))
+	for k, v := range matchLabels {
+		selector[k] = v
+	}
+	podMonitor := newPodMonitor()
+	podMonitor.SetNamespace(r.Key().Namespace)
+	podMonitor.SetName(r.Key().Name)
+	podMonitor.SetLabels(objLabels)
+	podMonitor.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": selector,
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{r.pandaCluster.Namespace},
+		},
+		"podMetricsEndpoints": []interface{}{endpoint},
+	}
+
+	err := SetOwner(r.pandaCluster, podMonitor, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return podMonitor, nil
+}
+
+// prometheusSecretKey returns the reference to the key of a Secret in the
+// format of the Prometheus operator
+func prometheusSecretKey(name, key string) map[string]interface{} {
+	return map[string]interface{}{
+		"secret": map[string]interface{}{
+			"name": name,
+			"key":  key,
+		},
+	}
+}
+
+// prometheusDuration formats the duration as Prometheus parses it, which
+// does not accept fractions
+func prometheusDuration(d time.Duration) string {
+	if d%time.Second == 0 {
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return fmt.Sprintf("%dms", d/time.Millisecond)
+}
+
+func newPodMonitor() *unstructured.Unstructured {
+	podMonitor := &unstructured.Unstructured{}
+	podMonitor.SetGroupVersionKind(PodMonitorGVK)
+	return podMonitor
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *PodMonitorResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name, Namespace: r.pandaCluster.Namespace}
+}
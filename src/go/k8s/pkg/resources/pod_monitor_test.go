@@ -0,0 +1,91 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodMonitorEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.TLS.AdminAPI.Enabled = true
+	cluster.Spec.PodMonitor = &redpandav1alpha1.PodMonitor{
+		Interval: &metav1.Duration{Duration: 15 * time.Second},
+		Labels:   map[string]string{"release": "prometheus"},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	podMonitor := res.NewPodMonitor(c, cluster, scheme.Scheme, "cluster.local",
+		types.NamespacedName{Name: "admin-node"}, types.NamespacedName{Name: "admin-client"}, ctrl.Log)
+	require.NoError(t, podMonitor.Ensure(context.Background()))
+
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(res.PodMonitorGVK)
+	require.NoError(t, c.Get(context.Background(), podMonitor.Key(), actual))
+	assert.Equal(t, "prometheus", actual.GetLabels()["release"])
+	endpoints, _, err := unstructured.NestedSlice(actual.Object, "spec", "podMetricsEndpoints")
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	endpoint := endpoints[0].(map[string]interface{})
+	assert.Equal(t, "15s", endpoint["interval"])
+	assert.Equal(t, "/metrics", endpoint["path"])
+	assert.Equal(t, "https", endpoint["scheme"])
+	ca, _, err := unstructured.NestedString(endpoint, "tlsConfig", "ca", "secret", "name")
+	require.NoError(t, err)
+	assert.Equal(t, "admin-node", ca)
+
+	cluster.Spec.PodMonitor = nil
+	require.NoError(t, podMonitor.Ensure(context.Background()))
+	err = c.Get(context.Background(), podMonitor.Key(), actual)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+// podMonitorForbiddenClient denies reading PodMonitors like an operator
+// installed without the role for them
+type podMonitorForbiddenClient struct {
+	client.Client
+}
+
+func (c podMonitorForbiddenClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if obj.GetObjectKind().GroupVersionKind() == res.PodMonitorGVK {
+		return apierrors.NewForbidden(schema.GroupResource{Group: res.PodMonitorGVK.Group, Resource: "podmonitors"},
+			key.Name, errors.New("no RBAC rule"))
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestPodMonitorDisabledWithoutPermission(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	c := podMonitorForbiddenClient{fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()}
+	podMonitor := res.NewPodMonitor(c, cluster, scheme.Scheme, "cluster.local",
+		types.NamespacedName{}, types.NamespacedName{}, ctrl.Log)
+	assert.NoError(t, podMonitor.Ensure(context.Background()))
+}
@@ -0,0 +1,205 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	postBootstrapJobContainerName = "post-bootstrap"
+	postBootstrapJobHashLength    = 10
+
+	reasonJobRunning   = "JobRunning"
+	reasonJobSucceeded = "JobSucceeded"
+	reasonJobFailed    = "JobFailed"
+)
+
+var _ Resource = &PostBootstrapJobResource{}
+
+// PostBootstrapJobResource runs the post bootstrap Job once the cluster is
+// ready. The Job name contains a hash of the job spec, so a Job runs once per
+// spec and changing the spec starts a new one.
+type PostBootstrapJobResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	serviceFQDN  string
+	logger       logr.Logger
+}
+
+// NewPostBootstrapJob creates PostBootstrapJobResource
+func NewPostBootstrapJob(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	serviceFQDN string,
+	logger logr.Logger,
+) *PostBootstrapJobResource {
+	return &PostBootstrapJobResource{
+		client,
+		scheme,
+		pandaCluster,
+		serviceFQDN,
+		logger.WithValues("Kind", "Job"),
+	}
+}
+
+// Ensure creates the Job when the cluster is ready and reports its progress
+// in the Cluster conditions
+func (r *PostBootstrapJobResource) Ensure(ctx context.Context) error {
+	spec := r.pandaCluster.Spec.PostBootstrapJob
+	if spec == nil {
+		return nil
+	}
+
+	var job batchv1.Job
+	err := r.Get(ctx, r.Key(), &job)
+	if apierrors.IsNotFound(err) {
+		if !r.clusterReady() {
+			return nil
+		}
+		obj, err := r.obj()
+		if err != nil {
+			return fmt.Errorf("unable to construct Job: %w", err)
+		}
+		if _, err := CreateIfNotExists(ctx, r, obj, r.logger); err != nil {
+			return err
+		}
+		return r.updateCondition(ctx, corev1.ConditionFalse, reasonJobRunning, fmt.Sprintf("job %s started", r.Key().Name))
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching Job resource: %w", err)
+	}
+
+	switch {
+	case job.Status.Succeeded > 0:
+		return r.updateCondition(ctx, corev1.ConditionTrue, reasonJobSucceeded, fmt.Sprintf("job %s succeeded", job.Name))
+	case jobFailed(&job):
+		return r.updateCondition(ctx, corev1.ConditionFalse, reasonJobFailed, fmt.Sprintf("job %s failed", job.Name))
+	default:
+		return r.updateCondition(ctx, corev1.ConditionFalse, reasonJobRunning, fmt.Sprintf("job %s is running", job.Name))
+	}
+}
+
+// clusterReady returns true when every requested broker is ready
+func (r *PostBootstrapJobResource) clusterReady() bool {
+	return r.pandaCluster.Spec.Replicas != nil &&
+		*r.pandaCluster.Spec.Replicas > 0 &&
+		r.pandaCluster.Status.Replicas == *r.pandaCluster.Spec.Replicas
+}
+
+func (r *PostBootstrapJobResource) updateCondition(
+	ctx context.Context, status corev1.ConditionStatus, reason, message string,
+) error {
+	if !r.pandaCluster.Status.SetCondition(redpandav1alpha1.ClusterPostBootstrapJobComplete, status, reason, message) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return fmt.Errorf("unable to update post bootstrap job condition: %w", err)
+	}
+	return nil
+}
+
+// obj returns resource managed client.Object
+func (r *PostBootstrapJobResource) obj() (k8sclient.Object, error) {
+	spec := r.pandaCluster.Spec.PostBootstrapJob
+
+	adminScheme := "http"
+	if r.pandaCluster.Spec.Configuration.TLS.AdminAPI.Enabled {
+		adminScheme = "https"
+	}
+	env := []corev1.EnvVar{
+		{
+			Name:  "REDPANDA_BROKERS",
+			Value: fmt.Sprintf("%s:%d", r.serviceFQDN, r.pandaCluster.Spec.Configuration.KafkaAPI.Port),
+		},
+		{
+			Name:  "REDPANDA_ADMIN_API",
+			Value: fmt.Sprintf("%s://%s:%d", adminScheme, r.serviceFQDN, r.pandaCluster.Spec.Configuration.AdminAPI.Port),
+		},
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.Key().Name,
+			Namespace: r.Key().Namespace,
+			Labels:    labels.ForCluster(r.pandaCluster),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: spec.BackoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					ServiceAccountName: spec.ServiceAccountName,
+					Containers: []corev1.Container{
+						{
+							Name:      postBootstrapJobContainerName,
+							Image:     spec.Image,
+							Command:   spec.Command,
+							Args:      spec.Args,
+							Env:       append(env, spec.Env...),
+							Resources: spec.Resources,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := SetOwner(r.pandaCluster, job, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *PostBootstrapJobResource) Key() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      fmt.Sprintf("%s-post-bootstrap-%s", r.pandaCluster.Name, postBootstrapJobHash(r.pandaCluster.Spec.PostBootstrapJob)),
+		Namespace: r.pandaCluster.Namespace,
+	}
+}
+
+func postBootstrapJobHash(spec *redpandav1alpha1.PostBootstrapJob) string {
+	// marshalling the typed spec does not fail
+	b, _ := json.Marshal(spec) // nolint:errcheck // see above
+	return fmt.Sprintf("%x", sha256.Sum256(b))[:postBootstrapJobHashLength]
+}
+
+func jobFailed(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
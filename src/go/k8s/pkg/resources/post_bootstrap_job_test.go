@@ -0,0 +1,71 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPostBootstrapJobEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.PostBootstrapJob = &redpandav1alpha1.PostBootstrapJob{
+		Image:   "vectorized/redpanda:latest",
+		Command: []string{"rpk", "topic", "create", "events"},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	job := res.NewPostBootstrapJob(c, cluster, scheme.Scheme, "cluster.local", ctrl.Log)
+	condition := func() *redpandav1alpha1.ClusterCondition {
+		return cluster.Status.GetCondition(redpandav1alpha1.ClusterPostBootstrapJobComplete)
+	}
+
+	t.Run("job waits for the brokers", func(t *testing.T) {
+		require.NoError(t, job.Ensure(context.Background()))
+		assert.Nil(t, condition())
+	})
+
+	t.Run("job is created when the cluster is ready", func(t *testing.T) {
+		cluster.Status.Replicas = *cluster.Spec.Replicas
+		require.NoError(t, job.Ensure(context.Background()))
+
+		var actual batchv1.Job
+		require.NoError(t, c.Get(context.Background(), job.Key(), &actual))
+		assert.Contains(t, actual.Spec.Template.Spec.Containers[0].Env,
+			corev1.EnvVar{Name: "REDPANDA_BROKERS", Value: "cluster.local:123"})
+		assert.Equal(t, corev1.ConditionFalse, condition().Status)
+
+		actual.Status.Succeeded = 1
+		require.NoError(t, c.Status().Update(context.Background(), &actual))
+		require.NoError(t, job.Ensure(context.Background()))
+		assert.Equal(t, corev1.ConditionTrue, condition().Status)
+	})
+
+	t.Run("changed spec starts a new job", func(t *testing.T) {
+		previous := job.Key()
+		cluster.Spec.PostBootstrapJob.Command = []string{"rpk", "acl", "create"}
+		assert.NotEqual(t, previous, job.Key())
+
+		require.NoError(t, job.Ensure(context.Background()))
+		assert.Equal(t, corev1.ConditionFalse, condition().Status)
+	})
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ensurePVCRetention applies the PVCRetentionPolicy of the cluster to the
+// claims of the StatefulSet. The persistentVolumeClaimRetentionPolicy field
+// of StatefulSets is not part of the Kubernetes API the operator is built
+// against, so the StatefulSet controller behavior is reproduced: claims
+// deleted with the StatefulSet get it as owner, claims of removed brokers
+// are deleted once their Pod is gone.
+func (r *StatefulSetResource) ensurePVCRetention(
+	ctx context.Context, sts *appsv1.StatefulSet,
+) error {
+	policy := r.pandaCluster.Spec.Storage.PVCRetentionPolicy
+	if policy == nil || r.pandaCluster.Spec.Storage.IsEmptyDir() {
+		return nil
+	}
+
+	var pvcs corev1.PersistentVolumeClaimList
+	err := r.List(ctx, &pvcs, &k8sclient.ListOptions{
+		LabelSelector: labels.ForCluster(r.pandaCluster).AsClientSelector(),
+		Namespace:     r.pandaCluster.Namespace,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list PersistentVolumeClaims: %w", err)
+	}
+
+	replicas := r.replicasOrDefault()
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		ordinal, ok := r.pvcOrdinal(pvc.Name)
+		if !ok {
+			continue
+		}
+
+		if policy.WhenScaled == redpandav1alpha1.PVCRetentionPolicyDelete &&
+			replicas != nil && ordinal >= *replicas {
+			deleted, err := r.deleteScaledDownPVC(ctx, pvc, ordinal)
+			if err != nil {
+				return err
+			}
+			if deleted {
+				continue
+			}
+		}
+
+		if !setPVCOwner(pvc, sts, policy.WhenDeleted == redpandav1alpha1.PVCRetentionPolicyDelete) {
+			continue
+		}
+		r.logger.Info("Updating the owner of PersistentVolumeClaim", "name", pvc.Name, "whenDeleted", policy.WhenDeleted)
+		if err := r.Update(ctx, pvc); err != nil {
+			return fmt.Errorf("unable to update PersistentVolumeClaim %s: %w", pvc.Name, err)
+		}
+	}
+	return nil
+}
+
+// pvcOrdinal returns the ordinal of the broker a datadir claim belongs to
+func (r *StatefulSetResource) pvcOrdinal(name string) (int32, bool) {
+	return DataDirClaimOrdinal(r.pandaCluster, name)
+}
+
+// DataDirClaimOrdinal returns the ordinal of the broker a datadir claim of
+// the cluster belongs to
+func DataDirClaimOrdinal(
+	pandaCluster *redpandav1alpha1.Cluster, name string,
+) (int32, bool) {
+	prefix := fmt.Sprintf("%s-%s-", datadirName, pandaCluster.Name)
+	if !strings.HasPrefix(name, prefix) {
+		return 0, false
+	}
+	ordinal, err := strconv.ParseInt(strings.TrimPrefix(name, prefix), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(ordinal), true
+}
+
+func (r *StatefulSetResource) deleteScaledDownPVC(
+	ctx context.Context, pvc *corev1.PersistentVolumeClaim, ordinal int32,
+) (bool, error) {
+	var pod corev1.Pod
+	err := r.Get(ctx, types.NamespacedName{
+		Name:      fmt.Sprintf("%s-%d", r.Key().Name, ordinal),
+		Namespace: r.pandaCluster.Namespace,
+	}, &pod)
+	if err == nil {
+		// The StatefulSet did not remove the broker yet
+		return false, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("unable to fetch Pod of PersistentVolumeClaim %s: %w", pvc.Name, err)
+	}
+
+	r.logger.Info("Deleting the PersistentVolumeClaim of a removed broker", "name", pvc.Name)
+	if err := r.Delete(ctx, pvc); err != nil && !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("unable to delete PersistentVolumeClaim %s: %w", pvc.Name, err)
+	}
+	return true, nil
+}
+
+// setPVCOwner adds or removes the StatefulSet from the owners of the claim
+// and returns true when they changed
+func setPVCOwner(
+	pvc *corev1.PersistentVolumeClaim, sts *appsv1.StatefulSet, owned bool,
+) bool {
+	refs := make([]metav1.OwnerReference, 0, len(pvc.OwnerReferences))
+	found := false
+	for _, ref := range pvc.OwnerReferences {
+		if ref.UID == sts.UID {
+			found = true
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	if found == owned {
+		return false
+	}
+	if owned {
+		refs = append(refs, metav1.OwnerReference{
+			APIVersion: "apps/v1",
+			Kind:       "StatefulSet",
+			Name:       sts.Name,
+			UID:        sts.UID,
+		})
+	}
+	pvc.OwnerReferences = refs
+	return true
+}
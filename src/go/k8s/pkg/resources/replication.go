@@ -0,0 +1,597 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/go-logr/logr"
+	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ReplicationConfigHashAnnotationKey is the annotation of the MirrorMaker
+	// pods holding the hash of their configuration, so the pods are
+	// restarted when the endpoints or credentials of either cluster change
+	ReplicationConfigHashAnnotationKey = "redpanda.vectorized.io/replication-config-hash"
+
+	// DefaultReplicationCommand starts MirrorMaker 2 in the Apache Kafka
+	// image
+	DefaultReplicationCommand = "/opt/kafka/bin/connect-mirror-maker.sh"
+
+	replicationSuffix        = "-replication"
+	replicationContainerName = "mirrormaker"
+	replicationConfigFile    = "mm2.properties"
+	replicationConfigDir     = "/etc/mirrormaker"
+	// replicationMaxReplicationFactor bounds the replication factor of the
+	// replicated and internal topics of MirrorMaker
+	replicationMaxReplicationFactor = 3
+	replicationClientTimeout        = 5 * time.Second
+)
+
+var errReplicationSourceNotFound = errors.New("the source Cluster of the replication does not exist")
+
+// KafkaEndpoint is the internal Kafka API listener of a cluster with the
+// certificates of its clients
+type KafkaEndpoint struct {
+	Cluster *redpandav1alpha1.Cluster
+	// FQDN of the headless Service of the brokers
+	FQDN string
+	// NodeCert holds the CA of the brokers
+	NodeCert types.NamespacedName
+	// ClientCert is presented when the listener requires client
+	// authentication
+	ClientCert types.NamespacedName
+}
+
+func (e *KafkaEndpoint) brokers() string {
+	return fmt.Sprintf("%s:%d", strings.TrimSuffix(e.FQDN, "."), e.Cluster.Spec.Configuration.KafkaAPI.Port)
+}
+
+func (e *KafkaEndpoint) sasl() bool {
+	return e.Cluster.KafkaAuthenticationMethod() == redpandav1alpha1.KafkaAuthenticationSASL
+}
+
+var _ Resource = &ReplicationResource{}
+
+// ReplicationResource deploys MirrorMaker 2 replicating the topics of the
+// source Cluster into the cluster. Its configuration is kept in a Secret
+// rendered from the endpoints, certificates and superusers of both
+// clusters, the certificates of a source in another namespace are copied
+// into it.
+type ReplicationResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	target       KafkaEndpoint
+	source       *KafkaEndpoint
+	logger       logr.Logger
+}
+
+// NewReplication creates ReplicationResource. The source is nil when the
+// source Cluster does not exist.
+func NewReplication(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	target KafkaEndpoint,
+	source *KafkaEndpoint,
+	logger logr.Logger,
+) *ReplicationResource {
+	return &ReplicationResource{
+		client,
+		scheme,
+		pandaCluster,
+		target,
+		source,
+		logger.WithValues("Reconciler", "replication"),
+	}
+}
+
+// Ensure manages the Secret and Deployment of MirrorMaker and deletes them
+// when the replication is disabled. Nothing is deployed while the source
+// Cluster does not exist.
+func (r *ReplicationResource) Ensure(ctx context.Context) error {
+	if r.pandaCluster.Spec.Replication == nil {
+		return r.deleteIfExists(ctx)
+	}
+	if r.source == nil {
+		r.logger.Info("The source Cluster of the replication does not exist, MirrorMaker is not deployed",
+			"source", r.pandaCluster.ReplicationSourceKey())
+		return nil
+	}
+
+	data, err := r.secretData(ctx)
+	if err != nil {
+		return err
+	}
+
+	objects := []struct {
+		obj     k8sclient.Object
+		current k8sclient.Object
+	}{
+		{r.secret(data), &corev1.Secret{}},
+		{r.deployment(data), &appsv1.Deployment{}},
+	}
+	for _, o := range objects {
+		if err := SetOwner(r.pandaCluster, o.obj, r.scheme); err != nil {
+			return err
+		}
+		created, err := CreateIfNotExists(ctx, r, o.obj, r.logger)
+		if err != nil {
+			return err
+		}
+		if created {
+			continue
+		}
+		if err := r.Get(ctx, r.Key(), o.current); err != nil {
+			return fmt.Errorf("error while fetching MirrorMaker %s: %w", o.obj.GetObjectKind().GroupVersionKind().Kind, err)
+		}
+		if err := Update(ctx, o.current, o.obj, r.Client, r.logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ReplicationResource) deleteIfExists(ctx context.Context) error {
+	for _, obj := range []k8sclient.Object{&appsv1.Deployment{}, &corev1.Secret{}} {
+		err := r.Get(ctx, r.Key(), obj)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error while fetching MirrorMaker resource: %w", err)
+		}
+		if !IsManagedBy(r.pandaCluster, obj) {
+			continue
+		}
+		r.logger.Info("Deleting MirrorMaker of the disabled replication", "kind", fmt.Sprintf("%T", obj))
+		if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete MirrorMaker resource: %w", err)
+		}
+	}
+	return nil
+}
+
+// properties collects the lines of a Java properties file
+type properties struct {
+	buf bytes.Buffer
+}
+
+// add appends the property, backslashes are escaped so the regular
+// expressions of the topics reach MirrorMaker unchanged
+func (p *properties) add(key, value string) {
+	fmt.Fprintf(&p.buf, "%s=%s\n", key, strings.ReplaceAll(value, `\`, `\\`))
+}
+
+// secretData renders the MirrorMaker configuration and the files it reads,
+// the certificates of both clusters
+func (r *ReplicationResource) secretData(ctx context.Context) (map[string][]byte, error) {
+	replication := r.pandaCluster.Spec.Replication
+	source, target := r.source.Cluster.Name, r.target.Cluster.Name
+	data := map[string][]byte{}
+	p := &properties{}
+
+	p.add("clusters", source+", "+target)
+	for _, e := range []*KafkaEndpoint{r.source, &r.target} {
+		if err := r.clientProperties(ctx, e, p, data); err != nil {
+			return nil, err
+		}
+	}
+
+	flow := source + "->" + target
+	p.add(flow+".enabled", "true")
+	topics := replication.Topics
+	if topics == "" {
+		topics = ".*"
+	}
+	p.add(flow+".topics", topics)
+	p.add(flow+".topics.exclude", r.pandaCluster.ReplicationTopicsExclude())
+	p.add(flow+".sync.group.offsets.enabled", strconv.FormatBool(replication.SyncGroupOffsets))
+	p.add(target+"->"+source+".enabled", "false")
+
+	// the offset syncs live in the source, the other topics in the target
+	replicationFactor := int32(replicationMaxReplicationFactor)
+	for _, e := range []*KafkaEndpoint{r.source, &r.target} {
+		if replicas := e.Cluster.Spec.Replicas; replicas != nil && *replicas < replicationFactor {
+			replicationFactor = *replicas
+		}
+	}
+	for _, key := range []string{
+		"replication.factor",
+		"checkpoints.topic.replication.factor",
+		"heartbeats.topic.replication.factor",
+		"offset-syncs.topic.replication.factor",
+		"offset.storage.replication.factor",
+		"status.storage.replication.factor",
+		"config.storage.replication.factor",
+	} {
+		p.add(key, strconv.Itoa(int(replicationFactor)))
+	}
+
+	data[replicationConfigFile] = p.buf.Bytes()
+	return data, nil
+}
+
+// clientProperties renders the settings of the MirrorMaker clients of a
+// cluster. The certificates of the brokers name the headless Service, not
+// the brokers, MirrorMaker verifies them against the CA only.
+func (r *ReplicationResource) clientProperties(
+	ctx context.Context, e *KafkaEndpoint, p *properties, data map[string][]byte,
+) error {
+	alias := e.Cluster.Name
+	internalTLS := e.Cluster.InternalKafkaTLS()
+	protocol := "PLAINTEXT"
+	switch {
+	case internalTLS && e.sasl():
+		protocol = "SASL_SSL"
+	case internalTLS:
+		protocol = "SSL"
+	case e.sasl():
+		protocol = "SASL_PLAINTEXT"
+	}
+	p.add(alias+".bootstrap.servers", e.brokers())
+	p.add(alias+".security.protocol", protocol)
+
+	if internalTLS {
+		var nodeCert corev1.Secret
+		err := r.Get(ctx, e.NodeCert, &nodeCert)
+		if apierrors.IsNotFound(err) {
+			return &RequeueAfterError{RequeueAfter: requeueDuration,
+				Msg: fmt.Sprintf("waiting for the Kafka API certificate %s of %s", e.NodeCert.Name, alias)}
+		}
+		if err != nil {
+			return fmt.Errorf("unable to fetch the node certificate of %s: %w", alias, err)
+		}
+		ca, ok := nodeCert.Data[cmetav1.TLSCAKey]
+		if !ok {
+			return fmt.Errorf("the node certificate %s of %s has no %s", e.NodeCert.Name, alias, cmetav1.TLSCAKey)
+		}
+		truststore := alias + "-" + cmetav1.TLSCAKey
+		data[truststore] = ca
+		p.add(alias+".ssl.truststore.type", "PEM")
+		p.add(alias+".ssl.truststore.location", filepath.Join(replicationConfigDir, truststore))
+		p.add(alias+".ssl.endpoint.identification.algorithm", "")
+
+		if e.Cluster.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth {
+			var clientCert corev1.Secret
+			err := r.Get(ctx, e.ClientCert, &clientCert)
+			if apierrors.IsNotFound(err) {
+				return &RequeueAfterError{RequeueAfter: requeueDuration,
+					Msg: fmt.Sprintf("waiting for the client certificate %s of %s", e.ClientCert.Name, alias)}
+			}
+			if err != nil {
+				return fmt.Errorf("unable to fetch the client certificate of %s: %w", alias, err)
+			}
+			// Kafka reads PKCS#8 keys only
+			key, err := pkcs8PrivateKey(clientCert.Data[corev1.TLSPrivateKeyKey])
+			if err != nil {
+				return fmt.Errorf("invalid private key of the client certificate of %s: %w", alias, err)
+			}
+			keystore := alias + "-keystore.pem"
+			data[keystore] = append(key, clientCert.Data[corev1.TLSCertKey]...)
+			p.add(alias+".ssl.keystore.type", "PEM")
+			p.add(alias+".ssl.keystore.location", filepath.Join(replicationConfigDir, keystore))
+		}
+	}
+
+	if e.sasl() {
+		password, err := r.superuserPassword(ctx, e)
+		if err != nil {
+			return err
+		}
+		p.add(alias+".sasl.mechanism", superuserSASLMechanism)
+		p.add(alias+".sasl.jaas.config",
+			fmt.Sprintf(`org.apache.kafka.common.security.scram.ScramLoginModule required username="%s" password="%s";`,
+				BootstrapSuperuserName, password))
+	}
+	return nil
+}
+
+func (r *ReplicationResource) superuserPassword(
+	ctx context.Context, e *KafkaEndpoint,
+) (string, error) {
+	var secret corev1.Secret
+	key := types.NamespacedName{Name: e.Cluster.SuperuserSecretName(), Namespace: e.Cluster.Namespace}
+	err := r.Get(ctx, key, &secret)
+	if apierrors.IsNotFound(err) {
+		return "", &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("waiting for the superuser of %s", e.Cluster.Name)}
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to fetch the superuser of %s: %w", e.Cluster.Name, err)
+	}
+	return string(secret.Data[SuperuserSecretPasswordKey]), nil
+}
+
+// pkcs8PrivateKey converts the PEM encoded PKCS#1, SEC 1 or PKCS#8 private
+// key of cert-manager to PKCS#8
+func pkcs8PrivateKey(keyPEM []byte) ([]byte, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM encoded key")
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return keyPEM, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+func (r *ReplicationResource) objectMeta() metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      r.Key().Name,
+		Namespace: r.Key().Namespace,
+		Labels:    labels.ForReplication(r.pandaCluster),
+	}
+}
+
+func (r *ReplicationResource) secret(data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: r.objectMeta(),
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		Data: data,
+	}
+}
+
+// configHash hashes the files of the Secret in the order of their names
+func configHash(data map[string][]byte) string {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\n", name)
+		h.Write(data[name]) // nolint:errcheck // writing to a hash never fails
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+func (r *ReplicationResource) deployment(data map[string][]byte) *appsv1.Deployment {
+	replication := r.pandaCluster.Spec.Replication
+	replicationLabels := labels.ForReplication(r.pandaCluster)
+
+	replicas := replication.Replicas
+	if replicas == nil {
+		replicas = pointer.Int32Ptr(1)
+	}
+	command := replication.Command
+	if len(command) == 0 {
+		command = []string{DefaultReplicationCommand}
+	}
+	command = append(append([]string{}, command...), filepath.Join(replicationConfigDir, replicationConfigFile))
+
+	return &appsv1.Deployment{
+		ObjectMeta: r.objectMeta(),
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: replicas,
+			Selector: replicationLabels.AsAPISelector(),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: replicationLabels.AsAPISelector().MatchLabels,
+					Annotations: map[string]string{
+						ReplicationConfigHashAnnotationKey: configHash(data),
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:      replicationContainerName,
+							Image:     replication.Image,
+							Command:   command,
+							Resources: replication.Resources,
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "config",
+									MountPath: replicationConfigDir,
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "config",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{SecretName: r.Key().Name},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// SourceFound returns true when the source Cluster of the replication
+// exists
+func (r *ReplicationResource) SourceFound() bool {
+	return r.source != nil
+}
+
+// Lag measures the lag of the replicated topics of the source Cluster. The
+// replicated topics are prefixed with the name of the source.
+func (r *ReplicationResource) Lag(
+	ctx context.Context,
+) ([]redpandav1alpha1.ReplicatedTopic, error) {
+	if r.source == nil {
+		return nil, errReplicationSourceNotFound
+	}
+	source, err := r.kafkaClient(ctx, r.source)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to %s: %w", r.source.Cluster.Name, err)
+	}
+	defer source.Close() // nolint:errcheck // the offsets are read
+	target, err := r.kafkaClient(ctx, &r.target)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to %s: %w", r.target.Cluster.Name, err)
+	}
+	defer target.Close() // nolint:errcheck // the offsets are read
+
+	topics, err := source.Topics()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the topics of %s: %w", r.source.Cluster.Name, err)
+	}
+	sort.Strings(topics)
+	var lag []redpandav1alpha1.ReplicatedTopic
+	for _, topic := range topics {
+		if !r.pandaCluster.ReplicatesTopic(topic) {
+			continue
+		}
+		sourceOffsets, err := highWatermarks(source, topic)
+		if err != nil {
+			return nil, err
+		}
+		targetOffsets, err := highWatermarks(target, r.source.Cluster.Name+"."+topic)
+		if err != nil {
+			return nil, err
+		}
+		lag = append(lag, redpandav1alpha1.ReplicatedTopic{
+			Name: topic,
+			Lag:  partitionLag(sourceOffsets, targetOffsets),
+		})
+	}
+	return lag, nil
+}
+
+// partitionLag sums the difference of the high watermarks of the source and
+// the replicated partitions, a partition that was not replicated yet lags
+// by all of its offsets
+func partitionLag(source, target map[int32]int64) int64 {
+	var lag int64
+	for partition, hwm := range source {
+		if behind := hwm - target[partition]; behind > 0 {
+			lag += behind
+		}
+	}
+	return lag
+}
+
+// highWatermarks returns the next offset of every partition of the topic, a
+// topic that does not exist has none
+func highWatermarks(client sarama.Client, topic string) (map[int32]int64, error) {
+	partitions, err := client.Partitions(topic)
+	if errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the partitions of %s: %w", topic, err)
+	}
+	offsets := make(map[int32]int64, len(partitions))
+	for _, partition := range partitions {
+		offset, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the high watermark of %s/%d: %w", topic, partition, err)
+		}
+		offsets[partition] = offset
+	}
+	return offsets, nil
+}
+
+// kafkaClient connects to the internal listener of the cluster with the
+// superuser and the client certificate
+func (r *ReplicationResource) kafkaClient(
+	ctx context.Context, e *KafkaEndpoint,
+) (sarama.Client, error) {
+	conf := sarama.NewConfig()
+	conf.Version = sarama.V2_4_0_0
+	conf.ClientID = "operator"
+	conf.Net.DialTimeout = replicationClientTimeout
+	conf.Net.ReadTimeout = replicationClientTimeout
+	conf.Net.WriteTimeout = replicationClientTimeout
+	conf.Metadata.Retry.Max = 1
+
+	if e.Cluster.InternalKafkaTLS() {
+		tlsConfig := tls.Config{MinVersion: tls.VersionTLS12} // TLS12 is min version allowed by gosec.
+		// Like the other Kafka clients of the operator the brokers are not
+		// verified, their certificates name the headless Service
+		tlsConfig.InsecureSkipVerify = true
+		if e.Cluster.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth {
+			var certSecret corev1.Secret
+			if err := r.Get(ctx, e.ClientCert, &certSecret); err != nil {
+				return nil, err
+			}
+			cert, err := tls.X509KeyPair(certSecret.Data[corev1.TLSCertKey], certSecret.Data[corev1.TLSPrivateKeyKey])
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		conf.Net.TLS.Enable = true
+		conf.Net.TLS.Config = &tlsConfig
+	}
+
+	if e.sasl() {
+		password, err := r.superuserPassword(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+		conf.Net.SASL.Enable = true
+		conf.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		conf.Net.SASL.User = BootstrapSuperuserName
+		conf.Net.SASL.Password = password
+		conf.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramSHA256Client{}
+		}
+	}
+	return sarama.NewClient([]string{e.brokers()}, conf)
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *ReplicationResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + replicationSuffix, Namespace: r.pandaCluster.Namespace}
+}
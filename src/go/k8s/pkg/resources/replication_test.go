@@ -0,0 +1,124 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReplicationEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replication = &redpandav1alpha1.Replication{
+		Image:  "apache/kafka:3.0.0",
+		Source: redpandav1alpha1.ReplicationSource{Name: "source", Namespace: "upstream"},
+		Topics: `orders\..*`,
+	}
+	source := pandaCluster()
+	source.Name, source.Namespace = "source", "upstream"
+	source.Spec.Replicas = pointer.Int32Ptr(3)
+	source.Spec.EnableSASL = true
+	source.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+
+	sourceCert := types.NamespacedName{Name: "source-redpanda", Namespace: "upstream"}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		cluster,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: sourceCert.Name, Namespace: sourceCert.Namespace},
+			Data:       map[string][]byte{"ca.crt": []byte("source CA")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: source.SuperuserSecretName(), Namespace: "upstream"},
+			Data:       map[string][]byte{res.SuperuserSecretPasswordKey: []byte("secret")},
+		},
+	).Build()
+	replication := res.NewReplication(c, cluster, scheme.Scheme,
+		res.KafkaEndpoint{Cluster: cluster, FQDN: "cluster.default.svc.cluster.local."},
+		&res.KafkaEndpoint{Cluster: source, FQDN: "source.upstream.svc.cluster.local.", NodeCert: sourceCert},
+		ctrl.Log)
+
+	require.NoError(t, replication.Ensure(context.Background()))
+
+	var secret corev1.Secret
+	require.NoError(t, c.Get(context.Background(), replication.Key(), &secret))
+	assert.Equal(t, []byte("source CA"), secret.Data["source-ca.crt"])
+	config := string(secret.Data["mm2.properties"])
+	for _, line := range []string{
+		"clusters=source, cluster\n",
+		"source.bootstrap.servers=source.upstream.svc.cluster.local:123\n",
+		"source.security.protocol=SASL_SSL\n",
+		"source.ssl.truststore.location=/etc/mirrormaker/source-ca.crt\n",
+		"source.sasl.mechanism=SCRAM-SHA-256\n",
+		`password="secret";`,
+		"cluster.bootstrap.servers=cluster.default.svc.cluster.local:123\n",
+		"cluster.security.protocol=PLAINTEXT\n",
+		"source->cluster.enabled=true\n",
+		`source->cluster.topics=orders\\..*` + "\n",
+		"cluster->source.enabled=false\n",
+		"replication.factor=1\n",
+	} {
+		assert.Contains(t, config, line)
+	}
+
+	var deployment appsv1.Deployment
+	require.NoError(t, c.Get(context.Background(), replication.Key(), &deployment))
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "apache/kafka:3.0.0", container.Image)
+	assert.Equal(t, []string{res.DefaultReplicationCommand, "/etc/mirrormaker/mm2.properties"}, container.Command)
+	hash := deployment.Spec.Template.Annotations[res.ReplicationConfigHashAnnotationKey]
+	assert.NotEmpty(t, hash)
+
+	t.Run("credentials of the source restart MirrorMaker", func(t *testing.T) {
+		var superuser corev1.Secret
+		require.NoError(t, c.Get(context.Background(),
+			types.NamespacedName{Name: source.SuperuserSecretName(), Namespace: "upstream"}, &superuser))
+		superuser.Data[res.SuperuserSecretPasswordKey] = []byte("rotated")
+		require.NoError(t, c.Update(context.Background(), &superuser))
+		require.NoError(t, replication.Ensure(context.Background()))
+
+		require.NoError(t, c.Get(context.Background(), replication.Key(), &deployment))
+		assert.NotEqual(t, hash, deployment.Spec.Template.Annotations[res.ReplicationConfigHashAnnotationKey])
+	})
+
+	t.Run("missing certificates of the source are awaited", func(t *testing.T) {
+		waiting := res.NewReplication(c, cluster, scheme.Scheme,
+			res.KafkaEndpoint{Cluster: cluster, FQDN: "cluster.default.svc.cluster.local."},
+			&res.KafkaEndpoint{Cluster: source, FQDN: "source.upstream.svc.cluster.local.",
+				NodeCert: types.NamespacedName{Name: "missing", Namespace: "upstream"}},
+			ctrl.Log)
+		var requeue *res.RequeueAfterError
+		assert.True(t, errors.As(waiting.Ensure(context.Background()), &requeue))
+	})
+
+	t.Run("disabling the replication deletes MirrorMaker", func(t *testing.T) {
+		cluster.Spec.Replication = nil
+		require.NoError(t, replication.Ensure(context.Background()))
+
+		assert.True(t, apierrors.IsNotFound(c.Get(context.Background(), replication.Key(), &appsv1.Deployment{})))
+		assert.True(t, apierrors.IsNotFound(c.Get(context.Background(), replication.Key(), &corev1.Secret{})))
+	})
+}
@@ -16,8 +16,12 @@ import (
 
 	"github.com/banzaicloud/k8s-objectmatcher/patch"
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -28,6 +32,8 @@ const (
 	KafkaPortName = "kafka"
 	// AdminPortName is name of admin port in Service definition
 	AdminPortName = "admin"
+	// PandaproxyPortName is name of the Pandaproxy port in Service definition
+	PandaproxyPortName = "proxy"
 )
 
 // NamedServicePort allows to pass name ports, e.g., to service resources
@@ -36,6 +42,21 @@ type NamedServicePort struct {
 	Port int
 }
 
+// checkDuplicatePorts fails when two listeners of a Service share a port, the
+// API server would reject the Service or route one listener to the other.
+// The webhook rejects such a spec, this guards against listeners added
+// without a matching validation.
+func checkDuplicatePorts(ports []corev1.ServicePort) error {
+	names := make(map[int32]string, len(ports))
+	for _, port := range ports {
+		if other, ok := names[port.Port]; ok {
+			return fmt.Errorf("port %d is used by both the %s and the %s listener", port.Port, other, port.Name)
+		}
+		names[port.Port] = port.Name
+	}
+	return nil
+}
+
 // Resource decompose the reconciliation loop to specific kubernetes objects
 type Resource interface {
 	Reconciler
@@ -51,6 +72,26 @@ type Reconciler interface {
 	Ensure(ctx context.Context) error
 }
 
+// AdminAPIReconciler is a Reconciler that acts on the running cluster through
+// the Admin API. When it fails, the reconcilers after it still run, so an
+// Admin API outage does not stop the management of the Kubernetes objects.
+type AdminAPIReconciler interface {
+	Reconciler
+
+	// RequiresAdminAPI marks the reconciler as depending on the Admin API
+	RequiresAdminAPI()
+}
+
+// AdminAPIFallback is a Reconciler that falls back when the running version
+// does not serve an Admin API endpoint it reads
+type AdminAPIFallback interface {
+	Reconciler
+
+	// UnsupportedAdminAPI returns the errors of the endpoints the last
+	// Ensure fell back without
+	UnsupportedAdminAPI() []error
+}
+
 // CreateIfNotExists tries to get a kubernetes resource and creates it if does not exist
 func CreateIfNotExists(
 	ctx context.Context, c client.Client, obj client.Object, l logr.Logger,
@@ -74,7 +115,9 @@ func CreateIfNotExists(
 }
 
 // Update ensures resource is updated if necessary. The method calculates patch
-// and applies it if something changed
+// and applies it if something changed. The selector of a StatefulSet or a
+// Deployment is immutable, so a changed selector migrates the object instead,
+// see recreateWithSelector.
 func Update(
 	ctx context.Context,
 	current runtime.Object,
@@ -93,6 +136,10 @@ func Update(
 	if !patchResult.IsEmpty() {
 		// need to set current version first otherwise the request would get rejected
 		logger.Info(fmt.Sprintf("Resource changed, updating %s. Diff: %v", modified.GetName(), string(patchResult.Patch)))
+		if currentSelector, modifiedSelector := selector(current), selector(modified); modifiedSelector != nil &&
+			!apiequality.Semantic.DeepEqual(currentSelector, modifiedSelector) {
+			return recreateWithSelector(ctx, current, currentSelector, modifiedSelector, c, logger)
+		}
 		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(modified); err != nil {
 			return err
 		}
@@ -112,3 +159,69 @@ func Update(
 	}
 	return nil
 }
+
+// selector returns the immutable selector of StatefulSets and Deployments and
+// nil for every other kind
+func selector(obj runtime.Object) *metav1.LabelSelector {
+	switch o := obj.(type) {
+	case *appsv1.StatefulSet:
+		return o.Spec.Selector
+	case *appsv1.Deployment:
+		return o.Spec.Selector
+	}
+	return nil
+}
+
+// recreateWithSelector migrates current to a new selector without restarting
+// its Pods. The Pods matching the old selector get the labels of the new one,
+// then current is deleted with orphan propagation. The object is created with
+// the new selector on the next reconcile, once the deletion went through, and
+// adopts the relabeled Pods. Only a selector made of match labels can be
+// migrated, anything else is rejected, so the object is left untouched.
+func recreateWithSelector(
+	ctx context.Context,
+	current runtime.Object,
+	currentSelector, modifiedSelector *metav1.LabelSelector,
+	c client.Client,
+	logger logr.Logger,
+) error {
+	obj, ok := current.(client.Object)
+	if !ok {
+		return fmt.Errorf("unable to migrate selector of %T", current)
+	}
+	if len(modifiedSelector.MatchExpressions) > 0 {
+		return fmt.Errorf("selector of %s %s is immutable and cannot be migrated to match expressions",
+			current.GetObjectKind().GroupVersionKind().Kind, obj.GetName())
+	}
+	podSelector, err := metav1.LabelSelectorAsSelector(currentSelector)
+	if err != nil {
+		return fmt.Errorf("unable to parse selector of %s: %w", obj.GetName(), err)
+	}
+
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(obj.GetNamespace()), client.MatchingLabelsSelector{Selector: podSelector}); err != nil {
+		return fmt.Errorf("unable to list pods of %s: %w", obj.GetName(), err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		base := pod.DeepCopy()
+		if pod.Labels == nil {
+			pod.Labels = make(map[string]string, len(modifiedSelector.MatchLabels))
+		}
+		for k, v := range modifiedSelector.MatchLabels {
+			pod.Labels[k] = v
+		}
+		if err := c.Patch(ctx, pod, client.MergeFrom(base)); err != nil {
+			return fmt.Errorf("unable to relabel pod %s: %w", pod.Name, err)
+		}
+	}
+
+	logger.Info(fmt.Sprintf("Selector of %s changed, recreating it while preserving its %d pods", obj.GetName(), len(pods.Items)))
+	if err := c.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete %s for selector migration: %w", obj.GetName(), err)
+	}
+	return &RequeueAfterError{
+		RequeueAfter: requeueDuration,
+		Msg:          fmt.Sprintf("%s is recreated with a new selector", obj.GetName()),
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
 	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -69,16 +70,22 @@ func TestEnsure_StatefulSet(t *testing.T) {
 		cluster,
 		scheme.Scheme,
 		"cluster.local",
-		"servicename",
+		cluster.Name,
 		types.NamespacedName{Name: "test", Namespace: "test"},
 		types.NamespacedName{},
 		types.NamespacedName{},
 		types.NamespacedName{},
 		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
 		"",
 		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
 		ctrl.Log.WithName("test"))
 
+	assert.NoError(t, c.Create(context.Background(), headlessService(cluster)))
 	err := sts.Ensure(context.Background())
 	assert.NoError(t, err)
 
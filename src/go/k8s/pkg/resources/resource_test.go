@@ -0,0 +1,62 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateRecreatesOnSelectorChange(t *testing.T) {
+	key := types.NamespacedName{Name: "cluster", Namespace: "default"}
+	statefulSet := func(selector map[string]string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			TypeMeta:   metav1.TypeMeta{Kind: "StatefulSet", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: appsv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: selector},
+				Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: selector}},
+			},
+		}
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "cluster-0", Namespace: key.Namespace, Labels: map[string]string{"app": "redpanda"},
+	}}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+	_, err := res.CreateIfNotExists(context.Background(), c, statefulSet(map[string]string{"app": "redpanda"}), ctrl.Log)
+	require.NoError(t, err)
+
+	var current appsv1.StatefulSet
+	require.NoError(t, c.Get(context.Background(), key, &current))
+	modified := statefulSet(map[string]string{"app": "redpanda", "version": "v1"})
+	err = res.Update(context.Background(), &current, modified, c, ctrl.Log)
+	var requeue *res.RequeueAfterError
+	assert.True(t, errors.As(err, &requeue))
+
+	err = c.Get(context.Background(), key, &current)
+	assert.True(t, apierrors.IsNotFound(err))
+	var actual corev1.Pod
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, &actual))
+	assert.Equal(t, "v1", actual.Labels["version"])
+}
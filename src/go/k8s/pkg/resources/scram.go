@@ -0,0 +1,150 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/sarama"
+)
+
+var _ sarama.SCRAMClient = &scramSHA256Client{}
+
+var errSCRAMServerSignature = errors.New("the SCRAM server signature does not match")
+
+// scramSHA256Client authenticates the Kafka clients of the operator with
+// SCRAM-SHA-256 as described in RFC 5802 and RFC 7677, sarama only ships the
+// interface. The user names and passwords of the operator are ASCII, they
+// are not normalized with SASLprep.
+type scramSHA256Client struct {
+	user            string
+	password        string
+	nonce           string
+	clientFirstBare string
+	serverSignature []byte
+	step            int
+}
+
+// Begin starts the authentication of the user, the nonce is generated
+// unless it was set before
+func (c *scramSHA256Client) Begin(user, password, _ string) error {
+	if c.nonce == "" {
+		nonce := make([]byte, 24)
+		if _, err := rand.Read(nonce); err != nil {
+			return fmt.Errorf("unable to generate the SCRAM nonce: %w", err)
+		}
+		c.nonce = base64.StdEncoding.EncodeToString(nonce)
+	}
+	c.user = strings.NewReplacer("=", "=3D", ",", "=2C").Replace(user)
+	c.password = password
+	c.step = 0
+	return nil
+}
+
+// Step returns the response to the challenge of the server
+func (c *scramSHA256Client) Step(challenge string) (string, error) {
+	c.step++
+	switch c.step {
+	case 1:
+		c.clientFirstBare = "n=" + c.user + ",r=" + c.nonce
+		return "n,," + c.clientFirstBare, nil
+	case 2:
+		return c.clientFinal(challenge)
+	case 3:
+		attrs := scramAttributes(challenge)
+		if e, ok := attrs["e"]; ok {
+			return "", fmt.Errorf("SCRAM authentication failed: %s", e)
+		}
+		signature, err := base64.StdEncoding.DecodeString(attrs["v"])
+		if err != nil || !hmac.Equal(signature, c.serverSignature) {
+			return "", errSCRAMServerSignature
+		}
+		return "", nil
+	}
+	return "", fmt.Errorf("unexpected SCRAM challenge %q", challenge)
+}
+
+// Done returns true once the signature of the server was verified
+func (c *scramSHA256Client) Done() bool {
+	return c.step == 3
+}
+
+// clientFinal proves the password for the salt and iterations of the
+// server-first message and keeps the signature the server has to return
+func (c *scramSHA256Client) clientFinal(serverFirst string) (string, error) {
+	attrs := scramAttributes(serverFirst)
+	nonce := attrs["r"]
+	if !strings.HasPrefix(nonce, c.nonce) {
+		return "", fmt.Errorf("the SCRAM server nonce does not extend the client nonce")
+	}
+	salt, err := base64.StdEncoding.DecodeString(attrs["s"])
+	if err != nil {
+		return "", fmt.Errorf("invalid SCRAM salt: %w", err)
+	}
+	iterations, err := strconv.Atoi(attrs["i"])
+	if err != nil || iterations <= 0 {
+		return "", fmt.Errorf("invalid SCRAM iteration count %q", attrs["i"])
+	}
+
+	salted := saltPassword(c.password, salt, iterations)
+	clientKey := hmacSHA256(salted, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	// biws is the encoded GS2 header n,, of the client-first message
+	withoutProof := "c=biws,r=" + nonce
+	authMessage := []byte(c.clientFirstBare + "," + serverFirst + "," + withoutProof)
+	proof := hmacSHA256(storedKey[:], authMessage)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+	c.serverSignature = hmacSHA256(hmacSHA256(salted, []byte("Server Key")), authMessage)
+	return withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+// saltPassword is PBKDF2 with HMAC-SHA-256 deriving a single block, the
+// length of the hash
+func saltPassword(password string, salt []byte, iterations int) []byte {
+	block := make([]byte, len(salt)+4)
+	copy(block, salt)
+	binary.BigEndian.PutUint32(block[len(salt):], 1)
+	u := hmacSHA256([]byte(password), block)
+	salted := append([]byte{}, u...)
+	for i := 1; i < iterations; i++ {
+		u = hmacSHA256([]byte(password), u)
+		for j := range salted {
+			salted[j] ^= u[j]
+		}
+	}
+	return salted
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data) // nolint:errcheck // writing to a hash never fails
+	return mac.Sum(nil)
+}
+
+// scramAttributes parses the comma separated attributes of a SCRAM message
+func scramAttributes(message string) map[string]string {
+	attrs := map[string]string{}
+	for _, attr := range strings.Split(message, ",") {
+		if len(attr) > 1 && attr[1] == '=' {
+			attrs[attr[:1]] = attr[2:]
+		}
+	}
+	return attrs
+}
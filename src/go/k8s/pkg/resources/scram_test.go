@@ -0,0 +1,47 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The exchange of RFC 7677, section 3
+func TestSCRAMSHA256Client(t *testing.T) {
+	c := &scramSHA256Client{nonce: "rOprNGfwEbeRWgbNEkqO"}
+	require.NoError(t, c.Begin("user", "pencil", ""))
+
+	clientFirst, err := c.Step("")
+	require.NoError(t, err)
+	assert.Equal(t, "n,,n=user,r=rOprNGfwEbeRWgbNEkqO", clientFirst)
+
+	clientFinal, err := c.Step("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	require.NoError(t, err)
+	assert.Equal(t,
+		"c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=",
+		clientFinal)
+	assert.False(t, c.Done())
+
+	_, err = c.Step("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=")
+	require.NoError(t, err)
+	assert.True(t, c.Done())
+
+	c = &scramSHA256Client{nonce: "rOprNGfwEbeRWgbNEkqO"}
+	require.NoError(t, c.Begin("user", "pencil", ""))
+	_, err = c.Step("")
+	require.NoError(t, err)
+	_, err = c.Step("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	require.NoError(t, err)
+	_, err = c.Step("v=AAAA")
+	assert.Error(t, err)
+}
@@ -15,12 +15,12 @@ import (
 
 	"github.com/go-logr/logr"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 var _ Resource = &ServiceAccountResource{}
@@ -51,7 +51,7 @@ func NewServiceAccount(
 
 // Ensure manages ServiceAccount that is used in initContainer
 func (s *ServiceAccountResource) Ensure(ctx context.Context) error {
-	if !s.pandaCluster.Spec.ExternalConnectivity.Enabled {
+	if !s.pandaCluster.ConfiguratorReadsNode() {
 		return nil
 	}
 
@@ -70,6 +70,7 @@ func (s *ServiceAccountResource) obj() (k8sclient.Object, error) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      s.Key().Name,
 			Namespace: s.Key().Namespace,
+			Labels:    labels.ForCluster(s.pandaCluster),
 		},
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "ServiceAccount",
@@ -77,7 +78,7 @@ func (s *ServiceAccountResource) obj() (k8sclient.Object, error) {
 		},
 	}
 
-	err := controllerutil.SetControllerReference(s.pandaCluster, sa, s.scheme)
+	err := SetOwner(s.pandaCluster, sa, s.scheme)
 	if err != nil {
 		return nil, err
 	}
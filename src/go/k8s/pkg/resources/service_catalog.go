@@ -0,0 +1,174 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	serviceCatalogSuffix = "-service-catalog"
+
+	// ServiceCatalogSchemaVersion is the version of the keys of the service
+	// catalog ConfigMap, see the ServiceCatalog type for their description
+	ServiceCatalogSchemaVersion = "1"
+)
+
+var _ Resource = &ServiceCatalogResource{}
+
+// ServiceCatalogResource manages the ConfigMap documenting the endpoints of
+// the cluster for service discovery tools. The external listener is taken
+// from the bootstrap servers in the status, the reconcile that reports them
+// renders the ConfigMap again.
+type ServiceCatalogResource struct {
+	k8sclient.Client
+	scheme                *runtime.Scheme
+	pandaCluster          *redpandav1alpha1.Cluster
+	serviceFQDN           string
+	kafkaNodeCert         types.NamespacedName
+	kafkaExternalNodeCert types.NamespacedName
+	adminAPINodeCert      types.NamespacedName
+	logger                logr.Logger
+}
+
+// NewServiceCatalog creates ServiceCatalogResource
+func NewServiceCatalog(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	serviceFQDN string,
+	kafkaNodeCert types.NamespacedName,
+	kafkaExternalNodeCert types.NamespacedName,
+	adminAPINodeCert types.NamespacedName,
+	logger logr.Logger,
+) *ServiceCatalogResource {
+	return &ServiceCatalogResource{
+		client,
+		scheme,
+		pandaCluster,
+		serviceFQDN,
+		kafkaNodeCert,
+		kafkaExternalNodeCert,
+		adminAPINodeCert,
+		logger.WithValues("Kind", "ConfigMap", "Reconciler", "service catalog"),
+	}
+}
+
+// Ensure creates or updates the service catalog ConfigMap when it is enabled
+func (r *ServiceCatalogResource) Ensure(ctx context.Context) error {
+	if r.pandaCluster.Spec.ServiceCatalog == nil {
+		return nil
+	}
+
+	obj, err := r.obj()
+	if err != nil {
+		return err
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var cm corev1.ConfigMap
+	if err := r.Get(ctx, r.Key(), &cm); err != nil {
+		return fmt.Errorf("error while fetching service catalog ConfigMap: %w", err)
+	}
+	return Update(ctx, &cm, obj, r.Client, r.logger)
+}
+
+func (r *ServiceCatalogResource) obj() (k8sclient.Object, error) {
+	conf := r.pandaCluster.Spec.Configuration
+	sasl := r.pandaCluster.KafkaAuthenticationMethod() == redpandav1alpha1.KafkaAuthenticationSASL
+
+	data := map[string]string{
+		"schema_version":  ServiceCatalogSchemaVersion,
+		"cluster":         r.pandaCluster.Namespace + "/" + r.pandaCluster.Name,
+		"version":         r.pandaCluster.Spec.Version,
+		"kafka_bootstrap": fmt.Sprintf("%s:%d", r.serviceFQDN, conf.KafkaAPI.Port),
+		"kafka_tls":       strconv.FormatBool(r.pandaCluster.InternalKafkaTLS()),
+		"kafka_sasl":      strconv.FormatBool(sasl),
+		"admin_url":       fmt.Sprintf("%s://%s:%d", urlScheme(conf.TLS.AdminAPI.Enabled), r.serviceFQDN, conf.AdminAPI.Port),
+		"admin_tls":       strconv.FormatBool(conf.TLS.AdminAPI.Enabled),
+	}
+	for _, listener := range r.pandaCluster.Status.Bootstrap {
+		if listener.Name != "external" {
+			continue
+		}
+		data["kafka_external_bootstrap"] = listener.Servers
+		data["kafka_external_tls"] = strconv.FormatBool(listener.TLS)
+		data["kafka_external_sasl"] = strconv.FormatBool(listener.SASL)
+	}
+	if conf.TLS.KafkaAPI.Enabled {
+		data["kafka_ca_secret"] = r.kafkaNodeCert.Name
+	}
+	if listener := r.pandaCluster.KafkaExternalListener(); listener != nil && listener.TLS.Enabled {
+		data["kafka_external_ca_secret"] = r.kafkaExternalNodeCert.Name
+	}
+	if conf.TLS.AdminAPI.Enabled {
+		data["admin_ca_secret"] = r.adminAPINodeCert.Name
+	}
+	if proxy := conf.PandaproxyAPI; proxy != nil {
+		// TLS applies to the internal listener when it is not exposed
+		tls := proxy.TLS.Enabled && !r.pandaCluster.PandaproxyExternal()
+		data["pandaproxy_url"] = fmt.Sprintf("%s://%s:%d", urlScheme(tls), r.serviceFQDN, proxy.Port)
+	}
+
+	objLabels := labels.ForCluster(r.pandaCluster)
+	for k, v := range r.pandaCluster.Spec.ServiceCatalog.Labels {
+		if _, ok := objLabels[k]; !ok {
+			objLabels[k] = v
+		}
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    objLabels,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		Data: data,
+	}
+
+	err := SetOwner(r.pandaCluster, cm, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+	return cm, nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *ServiceCatalogResource) Key() types.NamespacedName {
+	name := r.pandaCluster.Name + serviceCatalogSuffix
+	if catalog := r.pandaCluster.Spec.ServiceCatalog; catalog != nil && catalog.Name != "" {
+		name = catalog.Name
+	}
+	return types.NamespacedName{Name: name, Namespace: r.pandaCluster.Namespace}
+}
+
+func urlScheme(tls bool) string {
+	if tls {
+		return "https"
+	}
+	return "http"
+}
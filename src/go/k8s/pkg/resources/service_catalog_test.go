@@ -0,0 +1,73 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestServiceCatalogEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.ServiceCatalog = &redpandav1alpha1.ServiceCatalog{
+		Labels: map[string]string{"catalog.example.com/index": "true"},
+	}
+	cluster.Spec.Configuration.AdminAPI.Port = 9644
+	cluster.Spec.Configuration.TLS.AdminAPI.Enabled = true
+	cluster.Spec.Configuration.PandaproxyAPI = &redpandav1alpha1.PandaproxyAPI{Port: 8082}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	catalog := res.NewServiceCatalog(c, cluster, scheme.Scheme, "cluster.local",
+		types.NamespacedName{Name: "cluster-redpanda", Namespace: cluster.Namespace},
+		types.NamespacedName{Name: "cluster-kafka-external-node", Namespace: cluster.Namespace},
+		types.NamespacedName{Name: "cluster-admin-api-node", Namespace: cluster.Namespace},
+		ctrl.Log)
+	require.NoError(t, catalog.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), catalog.Key(), &actual))
+	assert.Equal(t, "cluster-service-catalog", actual.Name)
+	assert.Equal(t, "true", actual.Labels["catalog.example.com/index"])
+	assert.Equal(t, map[string]string{
+		"schema_version":  res.ServiceCatalogSchemaVersion,
+		"cluster":         "default/cluster",
+		"version":         "latest",
+		"kafka_bootstrap": "cluster.local:123",
+		"kafka_tls":       "false",
+		"kafka_sasl":      "false",
+		"admin_url":       "https://cluster.local:9644",
+		"admin_tls":       "true",
+		"admin_ca_secret": "cluster-admin-api-node",
+		"pandaproxy_url":  "http://cluster.local:8082",
+	}, actual.Data)
+
+	// the external listener follows the bootstrap servers in the status
+	cluster.Status.Bootstrap = []redpandav1alpha1.BootstrapListener{
+		{Name: "internal", Servers: "cluster.local:123"},
+		{Name: "external", Servers: "0.example.com:30001,1.example.com:30001", TLS: true},
+	}
+	require.NoError(t, catalog.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), catalog.Key(), &actual))
+	assert.Equal(t, "0.example.com:30001,1.example.com:30001", actual.Data["kafka_external_bootstrap"])
+	assert.Equal(t, "true", actual.Data["kafka_external_tls"])
+	assert.Equal(t, "false", actual.Data["kafka_external_sasl"])
+}
@@ -11,35 +11,68 @@ package resources
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/go-logr/logr"
 	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
 	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 var _ Resource = &StatefulSetResource{}
+var _ AdminAPIFallback = &StatefulSetResource{}
 
 var errNodePortMissing = errors.New("the node port is missing from the service")
 
+// errAdvertisedAddressMissing is returned when the ConfigMap or Secret of the
+// advertised addresses has no address for a broker
+var errAdvertisedAddressMissing = errors.New("a broker has no advertised address")
+
+// errHeadlessServiceMismatch is returned when the Service named by the
+// serviceName of the StatefulSet does not give the brokers the DNS records
+// that their FQDNs and the seed servers rely on
+var errHeadlessServiceMismatch = errors.New("the service of the StatefulSet is not the headless service of the cluster")
+
 const (
-	redpandaContainerName      = "redpanda"
-	configuratorContainerName  = "redpanda-configurator"
+	// ConfiguratorContainerName is the name of the init container that
+	// renders redpanda.yaml for the broker before Redpanda starts. The
+	// Redpanda container is named after Cluster.RedpandaContainerName.
+	ConfiguratorContainerName  = "redpanda-configurator"
 	configuratorContainerImage = "vectorized/configurator"
 
+	// safeToEvictAnnotationKey keeps the cluster autoscaler from removing the
+	// node of a Pod
+	safeToEvictAnnotationKey = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+	// ioPropertiesHashAnnotationKey restarts the brokers when the disk
+	// properties of the reactor configuration change
+	ioPropertiesHashAnnotationKey = "redpanda.vectorized.io/io-properties-hash"
+
+	// dataDirOwnershipContainerName is the init container that fixes the
+	// ownership of the data directory
+	dataDirOwnershipContainerName = "redpanda-data-dir-ownership"
+	// StorageCheckContainerName is the init container that compares the
+	// mounted data directory with the storage hints, its termination
+	// message lists the deviations
+	StorageCheckContainerName = "redpanda-storage-check"
+
 	userID  = 101
 	groupID = 101
 	fsGroup = 101
@@ -47,28 +80,92 @@ const (
 	configDestinationDir = "/etc/redpanda"
 	configSourceDir      = "/mnt/operator"
 	configFile           = "redpanda.yaml"
+	// adminAddressFile is written by the configurator when the Admin API
+	// binds to a management network
+	adminAddressFile = "admin-address"
 
 	datadirName            = "datadir"
-	defaultDatadirCapacity = "100Gi"
+	hugePagesVolumeName    = "hugepages"
+	hugePagesDir           = "/dev/hugepages"
+	ioPropertiesVolumeName = "io-properties"
+	ioPropertiesDir        = "/etc/redpanda-io"
+	// advertisedAddressesVolumeName holds the advertised external addresses
+	// of the brokers, read by the configurator
+	advertisedAddressesVolumeName  = "advertised-addresses"
+	advertisedAddressesDir         = "/etc/redpanda-addresses"
+	advertisedAddressKafkaKey      = "kafka"
+	advertisedAddressPandaproxyKey = "pandaproxy"
+	defaultDatadirCapacity         = "100Gi"
+
+	// readinessEndpoint is served once the Admin API listens, the Admin
+	// API of this Redpanda version has no dedicated readiness endpoint
+	readinessEndpoint              = "/v1/config"
+	readinessProbePeriodSeconds    = 10
+	readinessProbeFailureThreshold = 3
+
+	livenessProbeInitialDelaySeconds = 30
+	livenessProbePeriodSeconds       = 10
+	livenessProbeFailureThreshold    = 6
+
+	defaultDrainTimeoutSeconds = 60
+	// defaultPreStopHookTimeoutSeconds bounds a custom preStop command
+	defaultPreStopHookTimeoutSeconds = 30
+	// shutdownGracePeriodSeconds is the time Redpanda has to stop after the
+	// drain, the default termination grace period of Kubernetes
+	shutdownGracePeriodSeconds = 30
+	// defaultMaxShutdownSeconds caps the shutdown time scaled with the data
+	// volume capacity
+	defaultMaxShutdownSeconds = 600
+	// partitionLeaderMetric is 1 for every partition the broker leads
+	partitionLeaderMetric = "vectorized_cluster_partition_leader"
 )
 
+// ZoneBalancedReadinessGate is the readiness gate of brokers with zone
+// balanced startup, the operator sets the condition once the zone of the
+// broker takes its turn
+const ZoneBalancedReadinessGate corev1.PodConditionType = "redpanda.vectorized.io/zone-balanced"
+
+// ClusterMemberReadinessGate is the readiness gate of brokers with
+// membership readiness, the operator sets the condition once the broker is
+// an active member of the cluster
+const ClusterMemberReadinessGate corev1.PodConditionType = "redpanda.vectorized.io/cluster-member"
+
+// ServingReadinessGate is the readiness gate of brokers with decommission
+// draining, the operator turns the condition off once the broker is
+// decommissioned
+const ServingReadinessGate corev1.PodConditionType = "redpanda.vectorized.io/serving"
+
+// StabilizedReadinessGate is the readiness gate of brokers with readiness
+// stabilization, the operator sets the condition once the containers of the
+// broker stayed ready for the stabilization window
+const StabilizedReadinessGate corev1.PodConditionType = "redpanda.vectorized.io/stabilized"
+
 // StatefulSetResource is part of the reconciliation of redpanda.vectorized.io CRD
 // focusing on the management of redpanda cluster
 type StatefulSetResource struct {
 	k8sclient.Client
-	scheme                      *runtime.Scheme
-	pandaCluster                *redpandav1alpha1.Cluster
-	serviceFQDN                 string
-	serviceName                 string
-	nodePortName                types.NamespacedName
-	nodePortSvc                 corev1.Service
-	redpandaCertSecretKey       types.NamespacedName
-	internalClientCertSecretKey types.NamespacedName
-	adminCertSecretKey          types.NamespacedName
-	adminAPINodeCertSecretKey   types.NamespacedName
-	serviceAccountName          string
-	configuratorTag             string
-	logger                      logr.Logger
+	scheme                         *runtime.Scheme
+	pandaCluster                   *redpandav1alpha1.Cluster
+	serviceFQDN                    string
+	serviceName                    string
+	nodePortName                   types.NamespacedName
+	nodePortSvc                    corev1.Service
+	nodeConfigHash                 string
+	replicas                       *int32
+	redpandaCertSecretKey          types.NamespacedName
+	internalClientCertSecretKey    types.NamespacedName
+	adminCertSecretKey             types.NamespacedName
+	adminAPINodeCertSecretKey      types.NamespacedName
+	rpcNodeCertSecretKey           types.NamespacedName
+	pandaproxyNodeCertSecretKey    types.NamespacedName
+	kafkaExternalNodeCertSecretKey types.NamespacedName
+	serviceAccountName             string
+	configuratorTag                string
+	adminAPIClientFactory          admin.AdminAPIClientFactory
+	adminTLSProvider               admin.AdminTLSConfigProvider
+	logger                         logr.Logger
+	// unsupported holds the Admin API errors the last Ensure fell back without
+	unsupported []error
 
 	LastObservedState *appsv1.StatefulSet
 }
@@ -85,8 +182,13 @@ func NewStatefulSet(
 	internalClientCertSecretKey types.NamespacedName,
 	adminCertSecretKey types.NamespacedName,
 	adminAPINodeCertSecretKey types.NamespacedName,
+	rpcNodeCertSecretKey types.NamespacedName,
+	pandaproxyNodeCertSecretKey types.NamespacedName,
+	kafkaExternalNodeCertSecretKey types.NamespacedName,
 	serviceAccountName string,
 	configuratorTag string,
+	adminAPIClientFactory admin.AdminAPIClientFactory,
+	adminTLSProvider admin.AdminTLSConfigProvider,
 	logger logr.Logger,
 ) *StatefulSetResource {
 	return &StatefulSetResource{
@@ -97,20 +199,34 @@ func NewStatefulSet(
 		serviceName,
 		nodePortName,
 		corev1.Service{},
+		"",
+		nil,
 		redpandaCertSecretKey,
 		internalClientCertSecretKey,
 		adminCertSecretKey,
 		adminAPINodeCertSecretKey,
+		rpcNodeCertSecretKey,
+		pandaproxyNodeCertSecretKey,
+		kafkaExternalNodeCertSecretKey,
 		serviceAccountName,
 		configuratorTag,
+		adminAPIClientFactory,
+		adminTLSProvider,
 		logger.WithValues("Kind", statefulSetKind()),
 		nil,
+		nil,
 	}
 }
 
+// UnsupportedAdminAPI implements AdminAPIFallback
+func (r *StatefulSetResource) UnsupportedAdminAPI() []error {
+	return r.unsupported
+}
+
 // Ensure will manage kubernetes v1.StatefulSet for redpanda.vectorized.io custom resource
 func (r *StatefulSetResource) Ensure(ctx context.Context) error {
 	var sts appsv1.StatefulSet
+	r.unsupported = nil
 
 	if r.pandaCluster.Spec.ExternalConnectivity.Enabled {
 		err := r.Get(ctx, r.nodePortName, &r.nodePortSvc)
@@ -118,11 +234,27 @@ func (r *StatefulSetResource) Ensure(ctx context.Context) error {
 			return fmt.Errorf("failed to retrieve node port service %s: %w", r.nodePortName, err)
 		}
 
-		if len(r.nodePortSvc.Spec.Ports) != 2 || r.nodePortSvc.Spec.Ports[0].NodePort == 0 || r.nodePortSvc.Spec.Ports[1].NodePort == 0 {
+		if !r.nodePortsAssigned() {
 			return fmt.Errorf("node port service %s: %w", r.nodePortName, errNodePortMissing)
 		}
 	}
 
+	if err := r.verifyHeadlessService(ctx); err != nil {
+		return err
+	}
+
+	if err := r.verifyAdvertisedAddresses(ctx); err != nil {
+		return err
+	}
+
+	var cm corev1.ConfigMap
+	err := r.Get(ctx, ConfigMapKey(r.pandaCluster), &cm)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to retrieve ConfigMap %s: %w", ConfigMapKey(r.pandaCluster), err)
+	}
+	// Pods are restarted only when a property that Redpanda can not reload changes
+	r.nodeConfigHash = cm.Annotations[ConfigHashAnnotationKey]
+
 	obj, err := r.obj()
 	if err != nil {
 		return fmt.Errorf("unable to construct StatefulSet object: %w", err)
@@ -141,6 +273,12 @@ func (r *StatefulSetResource) Ensure(ctx context.Context) error {
 		return fmt.Errorf("error while fetching StatefulSet resource: %w", err)
 	}
 	r.LastObservedState = &sts
+	r.replicas = r.nextReplicas(&sts)
+
+	rolledBack, err := r.keepRolledBackRevision(ctx, &sts)
+	if err != nil || rolledBack {
+		return err
+	}
 
 	partitioned, err := r.shouldUsePartitionedUpdate(&sts)
 	if err != nil {
@@ -156,15 +294,197 @@ func (r *StatefulSetResource) Ensure(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
-		err = Update(ctx, &sts, modified, r.Client, r.logger)
-		if err != nil {
+		if fields := immutableFieldChanges(&sts, modified.(*appsv1.StatefulSet)); len(fields) > 0 {
+			if err := r.applyImmutableFieldChanges(ctx, &sts, fields); err != nil {
+				return err
+			}
+		} else {
+			err = Update(ctx, &sts, modified, r.Client, r.logger)
+			if err != nil {
+				return err
+			}
+			err = r.updateBlockedCondition(ctx, corev1.ConditionFalse, reasonStatefulSetUpToDate,
+				"the StatefulSet matches the cluster spec")
+			if err != nil {
+				return err
+			}
+		}
+		// a pre-pull left behind by a rolled back or abandoned upgrade
+		if err := r.deleteImagePrePull(ctx); err != nil {
 			return err
 		}
 	}
 
+	if err := r.ensurePVCRetention(ctx, &sts); err != nil {
+		return err
+	}
+
+	if target := r.pandaCluster.Spec.Replicas; target != nil && *r.replicas < *target {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("scaling up step by step, %d of %d replicas", *r.replicas, *target)}
+	}
+
+	return nil
+}
+
+// nextReplicas caps the number of brokers added at once to ScaleUpStep. The
+// next step is taken only when all current brokers are ready. The brokers are
+// only scaled down with DecommissionDrain and once they were decommissioned
+// and drained: the scale subresource of the Cluster, e.g. written by a
+// HorizontalPodAutoscaler, bypasses the webhook, and removing Pods without
+// decommissioning the brokers first can lose data.
+func (r *StatefulSetResource) nextReplicas(sts *appsv1.StatefulSet) *int32 {
+	target := r.pandaCluster.Spec.Replicas
+	if target == nil || sts.Spec.Replicas == nil {
+		return target
+	}
+	current := *sts.Spec.Replicas
+	if *target < current {
+		next := current
+		for next > *target && r.pandaCluster.Spec.DecommissionDrain != nil &&
+			r.pandaCluster.BrokerDrained(int(next-1)) {
+			next--
+		}
+		if next < current {
+			r.logger.Info("Scaling down drained brokers", "replicas", current, "next", next)
+			return &next
+		}
+		r.logger.Info("WARNING: scaling down requires decommission draining and the brokers to be decommissioned and drained first. Keeping the current replicas", "replicas", current, "requested", *target)
+		return &current
+	}
+	step := r.pandaCluster.Spec.ScaleUpStep
+	if step == nil {
+		return target
+	}
+	if *target <= current+*step {
+		return target
+	}
+	if sts.Status.ReadyReplicas < current {
+		r.logger.Info("Waiting for brokers to be ready before the next scale up step", "ready", sts.Status.ReadyReplicas, "replicas", current)
+		return &current
+	}
+	next := current + *step
+	return &next
+}
+
+// verifyHeadlessService checks that the Service named by the serviceName of
+// the StatefulSet is headless and selects the brokers. The Service is named
+// after the cluster, a Service of another object named like a new cluster,
+// e.g. one that replaces a renamed cluster, is not adopted as the headless
+// Service and would leave the brokers without the DNS records of their FQDNs.
+func (r *StatefulSetResource) verifyHeadlessService(ctx context.Context) error {
+	var svc corev1.Service
+	err := r.Get(ctx, types.NamespacedName{Name: r.serviceName, Namespace: r.pandaCluster.Namespace}, &svc)
+	if apierrors.IsNotFound(err) {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("waiting for the headless service %s of the StatefulSet", r.serviceName)}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to retrieve headless service %s: %w", r.serviceName, err)
+	}
+	if svc.Spec.ClusterIP != corev1.ClusterIPNone {
+		return fmt.Errorf("service %s has the cluster IP %s: %w", r.serviceName, svc.Spec.ClusterIP, errHeadlessServiceMismatch)
+	}
+	brokers := k8slabels.Set(labels.ForCluster(r.pandaCluster).AsAPISelector().MatchLabels)
+	if len(svc.Spec.Selector) == 0 || !k8slabels.SelectorFromSet(svc.Spec.Selector).Matches(brokers) {
+		return fmt.Errorf("service %s does not select the brokers of %s: %w", r.serviceName, r.pandaCluster.Name, errHeadlessServiceMismatch)
+	}
+	return nil
+}
+
+// verifyAdvertisedAddresses checks that the ConfigMap or Secret of the
+// advertised addresses holds an address of every external listener of each
+// broker, the configurator of a broker without one fails
+func (r *StatefulSetResource) verifyAdvertisedAddresses(ctx context.Context) error {
+	if !r.pandaCluster.AdvertisedAddressesFromSource() || r.pandaCluster.Spec.Replicas == nil {
+		return nil
+	}
+	from := r.pandaCluster.Spec.ExternalConnectivity.AdvertisedAddressesFrom
+	key := types.NamespacedName{Name: from.ConfigMapName, Namespace: r.pandaCluster.Namespace}
+	kind := "ConfigMap"
+	addresses := map[string]string{}
+	if from.SecretName != "" {
+		key.Name, kind = from.SecretName, "Secret"
+		var secret corev1.Secret
+		if err := r.Get(ctx, key, &secret); err != nil {
+			return fmt.Errorf("failed to retrieve the advertised addresses from Secret %s: %w", key.Name, err)
+		}
+		for k, v := range secret.Data {
+			addresses[k] = string(v)
+		}
+	} else {
+		var cm corev1.ConfigMap
+		if err := r.Get(ctx, key, &cm); err != nil {
+			return fmt.Errorf("failed to retrieve the advertised addresses from ConfigMap %s: %w", key.Name, err)
+		}
+		addresses = cm.Data
+	}
+
+	listeners := []string{advertisedAddressKafkaKey}
+	if r.pandaCluster.PandaproxyExternal() {
+		listeners = append(listeners, advertisedAddressPandaproxyKey)
+	}
+	var missing []string
+	for ordinal := 0; ordinal < int(*r.pandaCluster.Spec.Replicas); ordinal++ {
+		for _, l := range listeners {
+			if !hasAdvertisedAddress(addresses, ordinal, l) {
+				missing = append(missing, fmt.Sprintf("%d.%s", ordinal, l))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s %s has no address for %s: %w",
+			kind, key.Name, strings.Join(missing, ", "), errAdvertisedAddressMissing)
+	}
 	return nil
 }
 
+// hasAdvertisedAddress returns true when the addresses hold one for the
+// listener of the broker, the key of the listener or that of the broker
+func hasAdvertisedAddress(addresses map[string]string, ordinal int, listener string) bool {
+	for _, k := range []string{fmt.Sprintf("%d.%s", ordinal, listener), strconv.Itoa(ordinal)} {
+		if strings.TrimSpace(addresses[k]) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *StatefulSetResource) replicasOrDefault() *int32 {
+	if r.replicas != nil {
+		return r.replicas
+	}
+	return r.pandaCluster.Spec.Replicas
+}
+
+// updateStrategy returns the OnDelete strategy when the operator restarts
+// the brokers in leadership order, partitions only go down the ordinals
+func (r *StatefulSetResource) updateStrategy() appsv1.StatefulSetUpdateStrategy {
+	if r.pandaCluster.RestartsByLeadership() {
+		return appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+	}
+	return appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType}
+}
+
+func (r *StatefulSetResource) podAnnotations() map[string]string {
+	annotations := map[string]string{}
+	if r.nodeConfigHash != "" {
+		annotations[ConfigHashAnnotationKey] = r.nodeConfigHash
+	}
+	// the disk properties in the ConfigMap of the cluster are not part of
+	// the configuration hash
+	if reactor := r.pandaCluster.Spec.Reactor; reactor != nil && reactor.IOProperties != "" {
+		annotations[ioPropertiesHashAnnotationKey] = fmt.Sprintf("%x", sha256.Sum256([]byte(reactor.IOProperties)))
+	}
+	if autoscaling := r.pandaCluster.Spec.Autoscaling; autoscaling != nil && autoscaling.ClusterAutoscalerAnnotations {
+		annotations[safeToEvictAnnotationKey] = "false"
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
 func preparePVCResource(
 	name, namespace string,
 	storage redpandav1alpha1.StorageSpec,
@@ -199,6 +519,42 @@ func preparePVCResource(
 	return pvc
 }
 
+// dataDirVolume returns the volume of the data directory, an emptyDir or the
+// claim created from the volume claim template
+func (r *StatefulSetResource) dataDirVolume() corev1.Volume {
+	storage := r.pandaCluster.Spec.Storage
+	if storage.IsEmptyDir() {
+		emptyDir := &corev1.EmptyDirVolumeSource{}
+		if storage.EmptyDir != nil {
+			emptyDir.Medium = storage.EmptyDir.Medium
+			emptyDir.SizeLimit = storage.EmptyDir.SizeLimit
+		}
+		return corev1.Volume{
+			Name:         datadirName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: emptyDir},
+		}
+	}
+	return corev1.Volume{
+		Name: datadirName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: datadirName,
+			},
+		},
+	}
+}
+
+func (r *StatefulSetResource) volumeClaimTemplates(
+	clusterLabels labels.CommonLabels,
+) []corev1.PersistentVolumeClaim {
+	if r.pandaCluster.Spec.Storage.IsEmptyDir() {
+		return nil
+	}
+	return []corev1.PersistentVolumeClaim{
+		preparePVCResource(datadirName, r.pandaCluster.Namespace, r.pandaCluster.Spec.Storage, clusterLabels),
+	}
+}
+
 // obj returns resource managed client.Object
 // nolint:funlen // The complexity of obj function will be address in the next version TODO
 func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
@@ -206,9 +562,8 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 
 	var clusterLabels = labels.ForCluster(r.pandaCluster)
 
-	pvc := preparePVCResource(datadirName, r.pandaCluster.Namespace, r.pandaCluster.Spec.Storage, clusterLabels)
-	tolerations := r.pandaCluster.Spec.Tolerations
-	nodeSelector := r.pandaCluster.Spec.NodeSelector
+	tolerations := r.pandaCluster.BrokerTolerations()
+	nodeSelector := r.pandaCluster.BrokerNodeSelector()
 
 	ss := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -221,33 +576,29 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 			APIVersion: "apps/v1",
 		},
 		Spec: appsv1.StatefulSetSpec{
-			Replicas:            r.pandaCluster.Spec.Replicas,
-			PodManagementPolicy: appsv1.ParallelPodManagement,
-			Selector:            clusterLabels.AsAPISelector(),
-			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
-				Type: appsv1.RollingUpdateStatefulSetStrategyType,
-			},
-			ServiceName: r.pandaCluster.Name,
+			Replicas:             r.replicasOrDefault(),
+			PodManagementPolicy:  appsv1.ParallelPodManagement,
+			RevisionHistoryLimit: r.pandaCluster.Spec.RevisionHistoryLimit,
+			Selector:             clusterLabels.AsAPISelector(),
+			UpdateStrategy:       r.updateStrategy(),
+			ServiceName:          r.serviceName,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      r.pandaCluster.Name,
-					Namespace: r.pandaCluster.Namespace,
-					Labels:    clusterLabels.AsAPISelector().MatchLabels,
+					Name:        r.pandaCluster.Name,
+					Namespace:   r.pandaCluster.Namespace,
+					Labels:      clusterLabels.AsAPISelector().MatchLabels,
+					Annotations: r.podAnnotations(),
 				},
 				Spec: corev1.PodSpec{
-					ServiceAccountName: r.getServiceAccountName(),
+					ServiceAccountName:            r.getServiceAccountName(),
+					TerminationGracePeriodSeconds: r.terminationGracePeriodSeconds(),
+					ReadinessGates:                r.readinessGates(),
 					SecurityContext: &corev1.PodSecurityContext{
 						FSGroup: pointer.Int64Ptr(fsGroup),
+						Sysctls: r.sysctls(),
 					},
 					Volumes: append([]corev1.Volume{
-						{
-							Name: datadirName,
-							VolumeSource: corev1.VolumeSource{
-								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-									ClaimName: datadirName,
-								},
-							},
-						},
+						r.dataDirVolume(),
 						{
 							Name: "configmap-dir",
 							VolumeSource: corev1.VolumeSource{
@@ -265,82 +616,22 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 								EmptyDir: &corev1.EmptyDirVolumeSource{},
 							},
 						},
-					}, r.secretVolumes()...),
-					InitContainers: []corev1.Container{
-						{
-							Name:            configuratorContainerName,
-							Image:           configuratorContainerImage + ":" + r.configuratorTag,
-							ImagePullPolicy: corev1.PullIfNotPresent,
-							Env: []corev1.EnvVar{
-								{
-									Name:  "SERVICE_FQDN",
-									Value: r.serviceFQDN,
-								},
-								{
-									Name:  "CONFIG_SOURCE_DIR",
-									Value: configSourceDir,
-								},
-								{
-									Name:  "CONFIG_DESTINATION",
-									Value: filepath.Join(configDestinationDir, configFile),
-								},
-								{
-									Name:  "REDPANDA_RPC_PORT",
-									Value: strconv.Itoa(r.pandaCluster.Spec.Configuration.RPCServer.Port),
-								},
-								{
-									Name: "NODE_NAME",
-									ValueFrom: &corev1.EnvVarSource{
-										FieldRef: &corev1.ObjectFieldSelector{
-											APIVersion: "v1",
-											FieldPath:  "spec.nodeName",
-										},
-									},
-								},
-								{
-									Name:  "EXTERNAL_CONNECTIVITY",
-									Value: strconv.FormatBool(r.pandaCluster.Spec.ExternalConnectivity.Enabled),
-								},
-								{
-									Name:  "EXTERNAL_CONNECTIVITY_SUBDOMAIN",
-									Value: r.pandaCluster.Spec.ExternalConnectivity.Subdomain,
-								},
-								{
-									Name:  "HOST_PORT",
-									Value: r.getNodePort("kafka"),
-								},
-							},
-							SecurityContext: &corev1.SecurityContext{
-								RunAsUser:  pointer.Int64Ptr(userID),
-								RunAsGroup: pointer.Int64Ptr(groupID),
-							},
-							VolumeMounts: []corev1.VolumeMount{
-								{
-									Name:      "config-dir",
-									MountPath: configDestinationDir,
-								},
-								{
-									Name:      "configmap-dir",
-									MountPath: configSourceDir,
-								},
-							},
-						},
-					},
+					}, append(append(append(r.secretVolumes(), r.hugePagesVolumes()...), r.ioPropertiesVolumes()...), r.advertisedAddressesVolumes()...)...),
+					InitContainers: r.initContainers(),
 					Containers: []corev1.Container{
 						{
-							Name:  redpandaContainerName,
+							Name:  r.pandaCluster.RedpandaContainerName(),
 							Image: r.pandaCluster.FullImageName(),
-							Args: []string{
+							Args: append([]string{
 								"redpanda",
 								"start",
 								"--check=false",
-								"--smp 1",
-								// sometimes a little bit of memory is consumed by other processes than seastar
-								"--reserve-memory " + redpandav1alpha1.ReserveMemoryString,
+								r.smpArgument(),
+								r.memoryArgument(),
 								r.portsConfiguration(),
 								"--default-log-level=debug",
-							},
-							Env: []corev1.EnvVar{
+							}, r.additionalArguments()...),
+							Env: append([]corev1.EnvVar{
 								{
 									Name:  "REDPANDA_ENVIRONMENT",
 									Value: "kubernetes",
@@ -372,17 +663,18 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 										},
 									},
 								},
-							},
+							}, append(r.bootstrapUserEnv(), r.pandaCluster.Spec.Env...)...),
 							Ports: append([]corev1.ContainerPort{
 								{
 									Name:          "rpc",
 									ContainerPort: int32(r.pandaCluster.Spec.Configuration.RPCServer.Port),
 								},
 							}, r.getPorts()...),
-							Resources: corev1.ResourceRequirements{
-								Limits:   r.pandaCluster.Spec.Resources.Limits,
-								Requests: r.pandaCluster.Spec.Resources.Requests,
-							},
+							ReadinessProbe:  r.readinessProbe(),
+							LivenessProbe:   r.livenessProbe(),
+							Lifecycle:       r.lifecycle(),
+							Resources:       r.containerResources(),
+							SecurityContext: r.redpandaSecurityContext(),
 							VolumeMounts: append([]corev1.VolumeMount{
 								{
 									Name:      datadirName,
@@ -392,12 +684,14 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 									Name:      "config-dir",
 									MountPath: configDestinationDir,
 								},
-							}, r.secretVolumeMounts()...),
+							}, append(append(r.secretVolumeMounts(), r.hugePagesVolumeMounts()...), r.ioPropertiesVolumeMounts()...)...),
 						},
 					},
 					Tolerations:  tolerations,
 					NodeSelector: nodeSelector,
 					Affinity: &corev1.Affinity{
+						NodeAffinity: r.pandaCluster.Spec.NodeAffinity,
+						PodAffinity:  r.pandaCluster.Spec.PodAffinity,
 						PodAntiAffinity: &corev1.PodAntiAffinity{
 							RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
 								{
@@ -421,19 +715,17 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 						{
 							MaxSkew:           1,
 							TopologyKey:       corev1.LabelZoneFailureDomainStable,
-							WhenUnsatisfiable: corev1.ScheduleAnyway,
+							WhenUnsatisfiable: r.zoneSpreadWhenUnsatisfiable(),
 							LabelSelector:     clusterLabels.AsAPISelector(),
 						},
 					},
 				},
 			},
-			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
-				pvc,
-			},
+			VolumeClaimTemplates: r.volumeClaimTemplates(clusterLabels),
 		},
 	}
 
-	err := controllerutil.SetControllerReference(r.pandaCluster, ss, r.scheme)
+	err := SetOwner(r.pandaCluster, ss, r.scheme)
 	if err != nil {
 		return nil, err
 	}
@@ -441,6 +733,266 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 	return ss, nil
 }
 
+// zoneSpreadWhenUnsatisfiable returns whether brokers that would skew the
+// zones are scheduled
+func (r *StatefulSetResource) zoneSpreadWhenUnsatisfiable() corev1.UnsatisfiableConstraintAction {
+	if r.pandaCluster.StrictFailureDomainSpread() {
+		return corev1.DoNotSchedule
+	}
+	return corev1.ScheduleAnyway
+}
+
+func (r *StatefulSetResource) readinessGates() []corev1.PodReadinessGate {
+	var gates []corev1.PodReadinessGate
+	if r.pandaCluster.Spec.ZoneBalancedStartup {
+		gates = append(gates, corev1.PodReadinessGate{ConditionType: ZoneBalancedReadinessGate})
+	}
+	if r.pandaCluster.MembershipReadinessGated() {
+		gates = append(gates, corev1.PodReadinessGate{ConditionType: ClusterMemberReadinessGate})
+	}
+	if r.pandaCluster.Spec.DecommissionDrain != nil {
+		gates = append(gates, corev1.PodReadinessGate{ConditionType: ServingReadinessGate})
+	}
+	if r.pandaCluster.Spec.ReadinessStabilization != nil {
+		gates = append(gates, corev1.PodReadinessGate{ConditionType: StabilizedReadinessGate})
+	}
+	return gates
+}
+
+// PodOrdinal returns the ordinal of a broker Pod of the cluster, which is
+// also the node ID of the broker
+func PodOrdinal(
+	pandaCluster *redpandav1alpha1.Cluster, name string,
+) (int32, bool) {
+	prefix := pandaCluster.Name + "-"
+	if !strings.HasPrefix(name, prefix) {
+		return 0, false
+	}
+	ordinal, err := strconv.ParseInt(strings.TrimPrefix(name, prefix), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(ordinal), true
+}
+
+// readinessProbe checks that the Admin API serves requests. The Admin API of
+// this Redpanda version listens before the broker joined the cluster, the
+// cluster member readiness gate holds back brokers that are not members yet.
+func (r *StatefulSetResource) readinessProbe() *corev1.Probe {
+	adminAPI := r.pandaCluster.Spec.Configuration.AdminAPI
+	tlsConfig := r.pandaCluster.Spec.Configuration.TLS.AdminAPI
+	port := intstr.FromInt(adminAPI.Port)
+
+	handler := corev1.Handler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path:   readinessEndpoint,
+			Port:   port,
+			Scheme: corev1.URISchemeHTTP,
+		},
+	}
+	if tlsConfig.Enabled {
+		handler.HTTPGet.Scheme = corev1.URISchemeHTTPS
+	}
+	// The kubelet can not present a client certificate, so with mutual TLS
+	// only the admin port is checked
+	if tlsConfig.RequireClientAuth {
+		handler = corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: port,
+			},
+		}
+	}
+	// The kubelet reaches the Pod IP only, so an Admin API bound to the
+	// management network is checked from inside the container
+	if r.pandaCluster.Spec.Configuration.AdminAPIBindNetwork != "" {
+		scheme := "http"
+		if tlsConfig.Enabled {
+			scheme = "https"
+		}
+		handler = corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"/bin/sh", "-c",
+					fmt.Sprintf(`curl -sfk "%s://%s:%d%s"`, scheme, r.adminHost(), adminAPI.Port, readinessEndpoint)},
+			},
+		}
+	}
+
+	return &corev1.Probe{
+		Handler:          handler,
+		PeriodSeconds:    readinessProbePeriodSeconds,
+		FailureThreshold: readinessProbeFailureThreshold,
+	}
+}
+
+// livenessProbe only checks that the broker process accepts connections on
+// the RPC port. It does not depend on the Admin API or on other brokers, so a
+// cluster wide outage of the Admin API does not restart the brokers.
+func (r *StatefulSetResource) livenessProbe() *corev1.Probe {
+	liveness := r.pandaCluster.Spec.LivenessProbe
+	if liveness == nil {
+		return nil
+	}
+
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromInt(r.pandaCluster.Spec.Configuration.RPCServer.Port),
+			},
+		},
+		InitialDelaySeconds: int32OrDefault(liveness.InitialDelaySeconds, livenessProbeInitialDelaySeconds),
+		PeriodSeconds:       int32OrDefault(liveness.PeriodSeconds, livenessProbePeriodSeconds),
+		FailureThreshold:    int32OrDefault(liveness.FailureThreshold, livenessProbeFailureThreshold),
+	}
+}
+
+// lifecycle moves the leadership of the partitions the broker leads to other
+// brokers before it is stopped and waits until it led none or the drain
+// timeout passed. The Admin API of this Redpanda version has no maintenance
+// mode, so the hook reads the partitions the broker leads from the leader
+// gauge of its metrics and transfers them one by one. The phases are written
+// to the output of Redpanda, so they show up in the container logs. A custom
+// preStop hook runs before or after the drain.
+// The hook never decommissions the broker: a Pod can not tell a restart from
+// its removal, so leaving the cluster is never the intent of a stopping
+// broker.
+func (r *StatefulSetResource) lifecycle() *corev1.Lifecycle {
+	shutdown := r.pandaCluster.Spec.GracefulShutdown
+	hook := r.pandaCluster.PreStopHook()
+	if shutdown == nil && hook == nil {
+		return nil
+	}
+
+	logger := `log() { echo "graceful shutdown: $*" > /proc/1/fd/1; }
+id="${HOSTNAME##*-}"
+`
+	preStop := logger
+	if hook != nil && hook.Order == redpandav1alpha1.PreStopHookBeforeDrain {
+		preStop += preStopHookScript(hook)
+	}
+	lifecycle := &corev1.Lifecycle{}
+	if shutdown != nil {
+		scheme := "http"
+		if r.pandaCluster.Spec.Configuration.TLS.AdminAPI.Enabled {
+			scheme = "https"
+		}
+		adminURL := fmt.Sprintf("%s://%s:%d", scheme, r.adminHost(), r.pandaCluster.Spec.Configuration.AdminAPI.Port)
+		preStop += fmt.Sprintf(`log "draining leadership of broker ${id}"
+leaders() {
+  curl -sfk "%[1]s%[2]s" | grep '^%[3]s{.*namespace="kafka"' | grep -v ' 0[.0-9e+]*$'
+}
+i=0
+while led="$(leaders)" && [ -n "${led}" ]; do
+  if [ "${i}" -ge %[4]d ]; then log "drain timed out after %[4]d seconds"; break; fi
+  echo "${led}" | while read -r line; do
+    topic="$(echo "${line}" | sed 's/.*topic="\([^"]*\)".*/\1/')"
+    partition="$(echo "${line}" | sed 's/.*partition="\([0-9]*\)".*/\1/')"
+    curl -sfk -X POST "%[1]s/v1/kafka/${topic}/${partition}/transfer_leadership" > /dev/null
+  done
+  sleep 1
+  i=$((i+1))
+done
+`, adminURL, defaultMetricsPath, partitionLeaderMetric, int32OrDefault(shutdown.DrainTimeoutSeconds, defaultDrainTimeoutSeconds))
+	}
+	if hook != nil && hook.Order != redpandav1alpha1.PreStopHookBeforeDrain {
+		preStop += preStopHookScript(hook)
+	}
+	preStop += `log "stopping redpanda"`
+
+	lifecycle.PreStop = &corev1.Handler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"/bin/sh", "-c", preStop},
+		},
+	}
+	return lifecycle
+}
+
+// preStopHookScript runs the custom preStop command with its timeout. A
+// failing command is logged and the shutdown goes on.
+func preStopHookScript(hook *redpandav1alpha1.PreStopHook) string {
+	args := make([]string, 0, len(hook.Command))
+	for _, arg := range hook.Command {
+		args = append(args, shellQuote(arg))
+	}
+	return fmt.Sprintf(`log "running the preStop hook"
+timeout %d %s || log "preStop hook failed with status $?"
+`, int32OrDefault(hook.TimeoutSeconds, defaultPreStopHookTimeoutSeconds), strings.Join(args, " "))
+}
+
+// shellQuote quotes an argument for /bin/sh
+func shellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// adminHost is the host the scripts in the Redpanda container reach the Admin
+// API on. The configurator records the address in the management network
+// when the Admin API binds to it.
+func (r *StatefulSetResource) adminHost() string {
+	if r.pandaCluster.Spec.Configuration.AdminAPIBindNetwork == "" {
+		return "localhost"
+	}
+	return fmt.Sprintf("$(cat %s)", filepath.Join(configDestinationDir, adminAddressFile))
+}
+
+// terminationGracePeriodSeconds leaves Redpanda its shutdown time to stop
+// after the drain timeout and the custom hook timeout of the preStop hook.
+// The kubelet starts the grace period with the preStop hook and sends SIGTERM
+// once the hook returned, so the hook timeouts are not taken from Redpanda.
+func (r *StatefulSetResource) terminationGracePeriodSeconds() *int64 {
+	shutdown := r.pandaCluster.Spec.GracefulShutdown
+	hook := r.pandaCluster.PreStopHook()
+	stop := r.pandaCluster.BrokerShutdown()
+	if shutdown == nil && hook == nil && stop == nil {
+		return nil
+	}
+	var preStop int32
+	if shutdown != nil {
+		preStop += int32OrDefault(shutdown.DrainTimeoutSeconds, defaultDrainTimeoutSeconds)
+	}
+	if hook != nil {
+		preStop += int32OrDefault(hook.TimeoutSeconds, defaultPreStopHookTimeoutSeconds)
+	}
+	return pointer.Int64Ptr(int64(preStop) + r.shutdownSeconds())
+}
+
+// shutdownSeconds is the time Redpanda has to stop after SIGTERM. It grows
+// with the capacity of the data volume, rounded up to whole GiB, up to the
+// maximum. The configured base time is never capped.
+func (r *StatefulSetResource) shutdownSeconds() int64 {
+	stop := r.pandaCluster.BrokerShutdown()
+	if stop == nil {
+		return shutdownGracePeriodSeconds
+	}
+	seconds := int64(int32OrDefault(stop.TimeoutSeconds, shutdownGracePeriodSeconds))
+	perGiB := int64(int32OrDefault(stop.SecondsPerGiB, 0))
+	if perGiB == 0 {
+		return seconds
+	}
+	storage := r.pandaCluster.Spec.Storage
+	capacity := storage.Capacity
+	if storage.IsEmptyDir() {
+		capacity = resource.Quantity{}
+		if storage.EmptyDir != nil && storage.EmptyDir.SizeLimit != nil {
+			capacity = *storage.EmptyDir.SizeLimit
+		}
+	}
+	gib := (capacity.Value() + (1 << 30) - 1) >> 30
+	scaled := seconds + gib*perGiB
+	if max := int64(int32OrDefault(stop.MaxTimeoutSeconds, defaultMaxShutdownSeconds)); scaled > max {
+		scaled = max
+	}
+	if scaled < seconds {
+		return seconds
+	}
+	return scaled
+}
+
+func int32OrDefault(value *int32, def int32) int32 {
+	if value == nil {
+		return def
+	}
+	return *value
+}
+
 func (r *StatefulSetResource) secretVolumeMounts() []corev1.VolumeMount {
 	var mounts []corev1.VolumeMount
 	if r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.Enabled {
@@ -461,6 +1013,30 @@ func (r *StatefulSetResource) secretVolumeMounts() []corev1.VolumeMount {
 			MountPath: tlsAdminDir,
 		})
 	}
+	if r.pandaCluster.Spec.Configuration.TLS.RPC.Enabled {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      "tlsrpccert",
+			MountPath: tlsRPCDir,
+		})
+	}
+	if proxy := r.pandaCluster.Spec.Configuration.PandaproxyAPI; proxy != nil && proxy.TLS.Enabled {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      "tlsproxycert",
+			MountPath: tlsPandaproxyDir,
+		})
+	}
+	if listener := r.pandaCluster.KafkaExternalListener(); listener != nil && listener.TLS.Enabled {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      "tlskafkaexternalcert",
+			MountPath: tlsKafkaExternalDir,
+		})
+		if listener.TLS.RequireClientAuth && listener.TLS.TruststoreSecretRef != nil {
+			mounts = append(mounts, corev1.VolumeMount{
+				Name:      "tlskafkaexternalca",
+				MountPath: tlsKafkaExternalCADir,
+			})
+		}
+	}
 	return mounts
 }
 
@@ -533,13 +1109,111 @@ func (r *StatefulSetResource) secretVolumes() []corev1.Volume {
 		})
 	}
 
-	return vols
-}
+	// When RPC TLS is enabled, Redpanda needs a keypair certificate and the
+	// CA certificate to verify its peers.
+	if r.pandaCluster.Spec.Configuration.TLS.RPC.Enabled {
+		vols = append(vols, corev1.Volume{
+			Name: "tlsrpccert",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: r.rpcNodeCertSecretKey.Name,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  corev1.TLSPrivateKeyKey,
+							Path: corev1.TLSPrivateKeyKey,
+						},
+						{
+							Key:  corev1.TLSCertKey,
+							Path: corev1.TLSCertKey,
+						},
+						{
+							Key:  cmetav1.TLSCAKey,
+							Path: cmetav1.TLSCAKey,
+						},
+					},
+				},
+			},
+		})
+	}
 
-func (r *StatefulSetResource) getNodePort(name string) string {
-	if r.pandaCluster.Spec.ExternalConnectivity.Enabled {
-		for _, port := range r.nodePortSvc.Spec.Ports {
-			if port.Name == name {
+	// When Pandaproxy TLS is enabled, Redpanda needs a keypair certificate
+	// and the CA certificate to verify clients.
+	if proxy := r.pandaCluster.Spec.Configuration.PandaproxyAPI; proxy != nil && proxy.TLS.Enabled {
+		vols = append(vols, corev1.Volume{
+			Name: "tlsproxycert",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: r.pandaproxyNodeCertSecretKey.Name,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  corev1.TLSPrivateKeyKey,
+							Path: corev1.TLSPrivateKeyKey,
+						},
+						{
+							Key:  corev1.TLSCertKey,
+							Path: corev1.TLSCertKey,
+						},
+						{
+							Key:  cmetav1.TLSCAKey,
+							Path: cmetav1.TLSCAKey,
+						},
+					},
+				},
+			},
+		})
+	}
+
+	// When the external Kafka API listener has TLS settings of its own,
+	// Redpanda needs its keypair certificate and the CA certificates to
+	// verify the external clients.
+	if listener := r.pandaCluster.KafkaExternalListener(); listener != nil && listener.TLS.Enabled {
+		vols = append(vols, corev1.Volume{
+			Name: "tlskafkaexternalcert",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: r.kafkaExternalNodeCertSecretKey.Name,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  corev1.TLSPrivateKeyKey,
+							Path: corev1.TLSPrivateKeyKey,
+						},
+						{
+							Key:  corev1.TLSCertKey,
+							Path: corev1.TLSCertKey,
+						},
+						{
+							Key:  cmetav1.TLSCAKey,
+							Path: cmetav1.TLSCAKey,
+						},
+					},
+				},
+			},
+		})
+		if listener.TLS.RequireClientAuth && listener.TLS.TruststoreSecretRef != nil {
+			vols = append(vols, corev1.Volume{
+				Name: "tlskafkaexternalca",
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: listener.TLS.TruststoreSecretRef.Name,
+						Items: []corev1.KeyToPath{
+							{
+								Key:  cmetav1.TLSCAKey,
+								Path: cmetav1.TLSCAKey,
+							},
+						},
+					},
+				},
+			})
+		}
+	}
+
+	return vols
+}
+
+func (r *StatefulSetResource) getNodePort(name string) string {
+	if r.pandaCluster.Spec.ExternalConnectivity.Enabled {
+		for _, port := range r.nodePortSvc.Spec.Ports {
+			if port.Name == name {
 				return strconv.FormatInt(int64(port.NodePort), 10)
 			}
 		}
@@ -547,8 +1221,639 @@ func (r *StatefulSetResource) getNodePort(name string) string {
 	return ""
 }
 
+// nodePortsAssigned returns true when the node port Service has a port for
+// the Kafka API, the Admin API and every other external listener and all of
+// them got their node port
+func (r *StatefulSetResource) nodePortsAssigned() bool {
+	expected := 2
+	if r.pandaCluster.PandaproxyExternal() {
+		expected++
+	}
+	if len(r.nodePortSvc.Spec.Ports) != expected {
+		return false
+	}
+	for _, port := range r.nodePortSvc.Spec.Ports {
+		if port.NodePort == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// advertisedKafkaPort is the port of the external Kafka API listener that
+// the brokers advertise
+func (r *StatefulSetResource) advertisedKafkaPort() string {
+	if port := r.pandaCluster.Spec.ExternalConnectivity.AdvertisedPort; port != 0 {
+		return strconv.Itoa(port)
+	}
+	return r.getNodePort("kafka")
+}
+
+// smpArgument returns the number of shards Redpanda starts, one per pinned
+// CPU or a single one otherwise
+func (r *StatefulSetResource) smpArgument() string {
+	if cpus, ok := r.pandaCluster.PinnedCPUs(); ok {
+		return fmt.Sprintf("--smp %d", cpus)
+	}
+	return "--smp 1"
+}
+
+// containerResources returns the resources of the Redpanda container. With
+// CPU pinning the CPU and memory requests equal the limits, so the Pod gets
+// Guaranteed QoS and exclusive CPUs from the static CPU manager policy.
+// Huge pages are requested and limited to their configured size.
+func (r *StatefulSetResource) containerResources() corev1.ResourceRequirements {
+	resources := corev1.ResourceRequirements{
+		Limits:   r.pandaCluster.Spec.Resources.Limits,
+		Requests: r.pandaCluster.Spec.Resources.Requests,
+	}
+	if hugePages := r.hugePages(); hugePages != nil {
+		resources.Limits = resources.Limits.DeepCopy()
+		if resources.Limits == nil {
+			resources.Limits = corev1.ResourceList{}
+		}
+		resources.Limits[hugePages.ResourceName()] = hugePages.Size
+		resources.Requests = resources.Requests.DeepCopy()
+		if resources.Requests == nil {
+			resources.Requests = corev1.ResourceList{}
+		}
+		resources.Requests[hugePages.ResourceName()] = hugePages.Size
+	}
+	if _, ok := r.pandaCluster.PinnedCPUs(); !ok {
+		return resources
+	}
+	requests := resources.Requests.DeepCopy()
+	if requests == nil {
+		requests = corev1.ResourceList{}
+	}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if limit, ok := resources.Limits[name]; ok {
+			requests[name] = limit
+		}
+	}
+	resources.Requests = requests
+	return resources
+}
+
+// configuratorResources returns the resources of the configurator init
+// container. It has none unless CPU pinning is enabled, as every container
+// needs requests equal to limits for the Pod to get Guaranteed QoS. Only
+// Redpanda uses the huge pages.
+func (r *StatefulSetResource) configuratorResources() corev1.ResourceRequirements {
+	if _, ok := r.pandaCluster.PinnedCPUs(); !ok {
+		return corev1.ResourceRequirements{}
+	}
+	resources := r.containerResources()
+	if hugePages := r.hugePages(); hugePages != nil {
+		delete(resources.Limits, hugePages.ResourceName())
+		delete(resources.Requests, hugePages.ResourceName())
+	}
+	return resources
+}
+
+func (r *StatefulSetResource) hugePages() *redpandav1alpha1.HugePages {
+	if r.pandaCluster.Spec.MemoryLocking == nil {
+		return nil
+	}
+	return r.pandaCluster.Spec.MemoryLocking.HugePages
+}
+
+// sysctls returns the TCP keepalive sysctls of the network namespace of the
+// Pod
+func (r *StatefulSetResource) sysctls() []corev1.Sysctl {
+	keepalive := r.pandaCluster.Spec.Configuration.RPCServerTuning.TCPKeepalive
+	if keepalive == nil {
+		return nil
+	}
+	var sysctls []corev1.Sysctl
+	for _, s := range []struct {
+		name  string
+		value *int32
+	}{
+		{"net.ipv4.tcp_keepalive_time", keepalive.TimeSeconds},
+		{"net.ipv4.tcp_keepalive_intvl", keepalive.IntervalSeconds},
+		{"net.ipv4.tcp_keepalive_probes", keepalive.Probes},
+	} {
+		if s.value != nil {
+			sysctls = append(sysctls, corev1.Sysctl{Name: s.name, Value: strconv.Itoa(int(*s.value))})
+		}
+	}
+	return sysctls
+}
+
+// redpandaSecurityContext lets Redpanda lock its memory when memory locking
+// is enabled
+func (r *StatefulSetResource) redpandaSecurityContext() *corev1.SecurityContext {
+	locking := r.pandaCluster.Spec.MemoryLocking
+	if locking == nil || !locking.LockMemory {
+		return nil
+	}
+	return &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Add: []corev1.Capability{"IPC_LOCK"},
+		},
+	}
+}
+
+// hugePagesVolumes returns the hugetlbfs volume Redpanda allocates its memory
+// from
+func (r *StatefulSetResource) hugePagesVolumes() []corev1.Volume {
+	if r.hugePages() == nil {
+		return nil
+	}
+	return []corev1.Volume{
+		{
+			Name: hugePagesVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumHugePages},
+			},
+		},
+	}
+}
+
+func (r *StatefulSetResource) hugePagesVolumeMounts() []corev1.VolumeMount {
+	if r.hugePages() == nil {
+		return nil
+	}
+	return []corev1.VolumeMount{
+		{
+			Name:      hugePagesVolumeName,
+			MountPath: hugePagesDir,
+		},
+	}
+}
+
+// ioPropertiesVolumes returns the volume holding the disk properties, taken
+// from the ConfigMap of the cluster or the referenced one
+func (r *StatefulSetResource) ioPropertiesVolumes() []corev1.Volume {
+	reactor := r.pandaCluster.Spec.Reactor
+	if !reactor.IOPropertiesSet() {
+		return nil
+	}
+	name, key := ConfigMapKey(r.pandaCluster).Name, IOPropertiesKey
+	if from := reactor.IOPropertiesFrom; from != nil {
+		name, key = from.Name, from.Key
+	}
+	var configMapDefaultMode int32 = 0754
+	return []corev1.Volume{
+		{
+			Name: ioPropertiesVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: name},
+					Items:                []corev1.KeyToPath{{Key: key, Path: IOPropertiesKey}},
+					DefaultMode:          &configMapDefaultMode,
+				},
+			},
+		},
+	}
+}
+
+func (r *StatefulSetResource) ioPropertiesVolumeMounts() []corev1.VolumeMount {
+	if !r.pandaCluster.Spec.Reactor.IOPropertiesSet() {
+		return nil
+	}
+	return []corev1.VolumeMount{
+		{
+			Name:      ioPropertiesVolumeName,
+			MountPath: ioPropertiesDir,
+			ReadOnly:  true,
+		},
+	}
+}
+
+// advertisedAddressesVolumes returns the volume of the ConfigMap or Secret
+// holding the advertised external addresses of the brokers
+func (r *StatefulSetResource) advertisedAddressesVolumes() []corev1.Volume {
+	if !r.pandaCluster.AdvertisedAddressesFromSource() {
+		return nil
+	}
+	from := r.pandaCluster.Spec.ExternalConnectivity.AdvertisedAddressesFrom
+	source := corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: from.ConfigMapName},
+		},
+	}
+	if from.SecretName != "" {
+		source = corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: from.SecretName},
+		}
+	}
+	return []corev1.Volume{
+		{
+			Name:         advertisedAddressesVolumeName,
+			VolumeSource: source,
+		},
+	}
+}
+
+func (r *StatefulSetResource) advertisedAddressesVolumeMounts() []corev1.VolumeMount {
+	if !r.pandaCluster.AdvertisedAddressesFromSource() {
+		return nil
+	}
+	return []corev1.VolumeMount{
+		{
+			Name:      advertisedAddressesVolumeName,
+			MountPath: advertisedAddressesDir,
+			ReadOnly:  true,
+		},
+	}
+}
+
+// initContainers returns the init containers of the broker Pods in the order
+// they run: the ones of the user placed before the operator, the
+// configurator rendering redpanda.yaml, the data directory ownership fix, the
+// storage check and the ones of the user placed after the operator
+func (r *StatefulSetResource) initContainers() []corev1.Container {
+	containers := r.userInitContainers(redpandav1alpha1.InitContainerBeforeOperator)
+	containers = append(containers, r.configuratorContainer())
+	containers = append(containers, r.dataDirOwnershipContainers()...)
+	containers = append(containers, r.storageCheckContainers()...)
+	return append(containers, r.userInitContainers(redpandav1alpha1.InitContainerAfterOperator)...)
+}
+
+// userInitContainers returns the init containers of the user at the position
+func (r *StatefulSetResource) userInitContainers(
+	position redpandav1alpha1.InitContainerPosition,
+) []corev1.Container {
+	var containers []corev1.Container
+	for _, c := range r.pandaCluster.Spec.InitContainers {
+		if c.Position == position || (c.Position == "" && position == redpandav1alpha1.InitContainerAfterOperator) {
+			containers = append(containers, corev1.Container{
+				Name:         c.Name,
+				Image:        c.Image,
+				Command:      c.Command,
+				Args:         c.Args,
+				Env:          c.Env,
+				Resources:    c.Resources,
+				VolumeMounts: c.VolumeMounts,
+			})
+		}
+	}
+	return containers
+}
+
+// configuratorContainer renders redpanda.yaml of the broker
+func (r *StatefulSetResource) configuratorContainer() corev1.Container {
+	return corev1.Container{
+		Name:            ConfiguratorContainerName,
+		Image:           configuratorContainerImage + ":" + r.configuratorTag,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Env: append([]corev1.EnvVar{
+			{
+				Name:  "SERVICE_FQDN",
+				Value: r.serviceFQDN,
+			},
+			{
+				Name:  "CONFIG_SOURCE_DIR",
+				Value: configSourceDir,
+			},
+			{
+				Name:  "CONFIG_DESTINATION",
+				Value: filepath.Join(configDestinationDir, configFile),
+			},
+			{
+				Name:  "REDPANDA_RPC_PORT",
+				Value: strconv.Itoa(r.pandaCluster.Spec.Configuration.RPCServer.Port),
+			},
+			{
+				Name: "NODE_NAME",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						APIVersion: "v1",
+						FieldPath:  "spec.nodeName",
+					},
+				},
+			},
+			{
+				Name:  "EXTERNAL_CONNECTIVITY",
+				Value: strconv.FormatBool(r.pandaCluster.Spec.ExternalConnectivity.Enabled),
+			},
+			{
+				Name:  "EXTERNAL_CONNECTIVITY_SUBDOMAIN",
+				Value: r.pandaCluster.ExternalSubdomain(),
+			},
+			{
+				Name:  "HOST_PORT",
+				Value: r.advertisedKafkaPort(),
+			},
+		}, r.configuratorOptionalEnv()...),
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:  pointer.Int64Ptr(userID),
+			RunAsGroup: pointer.Int64Ptr(groupID),
+		},
+		Resources: r.configuratorResources(),
+		VolumeMounts: append([]corev1.VolumeMount{
+			{
+				Name:      "config-dir",
+				MountPath: configDestinationDir,
+			},
+			{
+				Name:      "configmap-dir",
+				MountPath: configSourceDir,
+			},
+		}, r.advertisedAddressesVolumeMounts()...),
+	}
+}
+
+// dataDirOwnershipContainers returns the init container that hands the data
+// directory to the Redpanda user, for CSI drivers that ignore the fsGroup of
+// the Pod. Only files with another owner or without write permission for the
+// owner are changed, so restarts of brokers with large data directories stay
+// fast.
+func (r *StatefulSetResource) dataDirOwnershipContainers() []corev1.Container {
+	if !r.pandaCluster.Spec.Storage.FixDataDirOwnership {
+		return nil
+	}
+	owner := fmt.Sprintf("%d:%d", userID, groupID)
+	script := fmt.Sprintf(
+		"find %[1]s \\( ! -user %[2]d -o ! -group %[3]d \\) -exec chown -h %[4]s {} + && "+
+			"find %[1]s ! -perm -u+w -exec chmod u+rwX {} +",
+		dataDirectory, userID, groupID, owner)
+	return []corev1.Container{
+		{
+			Name:            dataDirOwnershipContainerName,
+			Image:           r.pandaCluster.FullImageName(),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/bin/sh", "-c", script},
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser:  pointer.Int64Ptr(0),
+				RunAsGroup: pointer.Int64Ptr(0),
+			},
+			Resources: r.configuratorResources(),
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      datadirName,
+					MountPath: dataDirectory,
+				},
+			},
+		},
+	}
+}
+
+// storageCheckScript compares the mount of the data directory in
+// /proc/self/mountinfo and the scheduler of its block device with the
+// expected ones. Every deviation is written as a line of the termination
+// message, the check never fails so the broker starts regardless. Devices
+// without a queue in sysfs, e.g. network volumes, have no scheduler.
+const storageCheckScript = `warn() { echo "$1"; echo "$1" >> /dev/termination-log; }
+mount=""
+while read -r id parent devno root point options rest; do
+  if [ "$point" = "$DATA_DIRECTORY" ]; then mount="$devno $options $rest"; fi
+done < /proc/self/mountinfo
+if [ -z "$mount" ]; then
+  warn "the data directory is not a separate mount"
+  exit 0
+fi
+set -- $mount
+devno=$1
+options=$2
+shift 2
+while [ $# -gt 0 ] && [ "$1" != "-" ]; do shift; done
+fs=$2
+options="$options,$4"
+if [ "$fs" != "$EXPECTED_FILESYSTEM" ]; then
+  warn "the data directory is $fs instead of $EXPECTED_FILESYSTEM"
+fi
+for option in $EXPECTED_MOUNT_OPTIONS; do
+  case ",$options," in
+    *",$option,"*) ;;
+    *) warn "the data directory is not mounted with $option" ;;
+  esac
+done
+if [ -n "$EXPECTED_DISK_SCHEDULER" ]; then
+  queue=/sys/dev/block/$devno/queue/scheduler
+  [ -r "$queue" ] || queue=/sys/dev/block/$devno/../queue/scheduler
+  if [ -r "$queue" ]; then
+    scheduler=$(cat "$queue")
+    case "$scheduler" in
+      *"["*) scheduler=${scheduler#*\[}; scheduler=${scheduler%%]*} ;;
+    esac
+    if [ "$scheduler" != "$EXPECTED_DISK_SCHEDULER" ]; then
+      warn "the device of the data directory uses the $scheduler scheduler instead of $EXPECTED_DISK_SCHEDULER"
+    fi
+  fi
+fi
+exit 0
+`
+
+// storageCheckContainers returns the init container that checks the mounted
+// data directory against the storage hints. It runs unprivileged and only
+// reads the mount table and sysfs.
+func (r *StatefulSetResource) storageCheckContainers() []corev1.Container {
+	hints := r.pandaCluster.Spec.Storage.Hints
+	if hints == nil {
+		return nil
+	}
+	return []corev1.Container{
+		{
+			Name:            StorageCheckContainerName,
+			Image:           r.pandaCluster.FullImageName(),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/bin/sh", "-c", storageCheckScript},
+			Env: []corev1.EnvVar{
+				{
+					Name:  "DATA_DIRECTORY",
+					Value: dataDirectory,
+				},
+				{
+					Name:  "EXPECTED_FILESYSTEM",
+					Value: hints.ExpectedFilesystem(),
+				},
+				{
+					Name:  "EXPECTED_MOUNT_OPTIONS",
+					Value: strings.Join(hints.MountOptions, " "),
+				},
+				{
+					Name:  "EXPECTED_DISK_SCHEDULER",
+					Value: hints.DiskScheduler,
+				},
+			},
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser:  pointer.Int64Ptr(userID),
+				RunAsGroup: pointer.Int64Ptr(groupID),
+			},
+			Resources: r.configuratorResources(),
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      datadirName,
+					MountPath: dataDirectory,
+					ReadOnly:  true,
+				},
+			},
+		},
+	}
+}
+
+// memoryArgument returns the Redpanda argument that sizes its memory. When
+// the memory reservation is configured the memory is passed explicitly,
+// otherwise Redpanda reserves a fixed amount for other processes.
+func (r *StatefulSetResource) memoryArgument() string {
+	if memory, ok := r.pandaCluster.RedpandaMemory(); ok {
+		return fmt.Sprintf("--memory %dM", memory/(1024*1024))
+	}
+	// sometimes a little bit of memory is consumed by other processes than seastar
+	return "--reserve-memory " + redpandav1alpha1.ReserveMemoryString
+}
+
+// additionalArguments returns the arguments derived from the durability
+// policy, the CPU pinning, the memory locking and the reactor configuration
+// followed by the user provided ones
+func (r *StatefulSetResource) additionalArguments() []string {
+	var args []string
+	if r.pandaCluster.Spec.Durability.IsRelaxed() {
+		args = append(args, "--unsafe-bypass-fsync=1")
+	}
+	if pinning := r.pandaCluster.Spec.CPUPinning; pinning != nil && pinning.Enabled && pinning.CPUSet != "" {
+		args = append(args, "--cpuset "+pinning.CPUSet)
+	}
+	if locking := r.pandaCluster.Spec.MemoryLocking; locking != nil && locking.LockMemory {
+		args = append(args, "--lock-memory=true")
+	}
+	if r.hugePages() != nil {
+		args = append(args, "--hugepages="+hugePagesDir)
+	}
+	if reactor := r.pandaCluster.Spec.Reactor; reactor != nil && reactor.Backend != "" {
+		args = append(args, "--reactor-backend="+string(reactor.Backend))
+	}
+	if r.pandaCluster.Spec.Reactor.IOPropertiesSet() {
+		args = append(args, "--io-properties-file="+filepath.Join(ioPropertiesDir, IOPropertiesKey))
+	}
+	return append(args, r.pandaCluster.Spec.AdditionalCommandLineArguments...)
+}
+
+// perBrokerConfigEnv passes the per broker overrides to the configurator,
+// which applies the ones of its ordinal on top of the base configuration
+func (r *StatefulSetResource) perBrokerConfigEnv() []corev1.EnvVar {
+	if len(r.pandaCluster.Spec.PerBrokerConfig) == 0 {
+		return nil
+	}
+	// maps are marshaled with sorted keys, so the Pod template is stable
+	overrides, err := json.Marshal(r.pandaCluster.Spec.PerBrokerConfig)
+	if err != nil {
+		r.logger.Error(err, "Unable to marshal the per broker configuration")
+		return nil
+	}
+	return []corev1.EnvVar{
+		{
+			Name:  "PER_BROKER_CONFIG",
+			Value: string(overrides),
+		},
+	}
+}
+
+// configuratorOptionalEnv are the environment variables the configurator
+// only reads when the matching feature is configured
+func (r *StatefulSetResource) configuratorOptionalEnv() []corev1.EnvVar {
+	env := append(r.bindAddressEnv(), r.perBrokerConfigEnv()...)
+	if network := r.pandaCluster.Spec.Configuration.AdminAPIBindNetwork; network != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  "ADMIN_API_BIND_NETWORK",
+			Value: network,
+		})
+	}
+	if extConn := r.pandaCluster.Spec.ExternalConnectivity; extConn.Enabled && extConn.NodeAddressAnnotation != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  "NODE_ADDRESS_ANNOTATION",
+			Value: extConn.NodeAddressAnnotation,
+		})
+	}
+	if r.pandaCluster.AdvertisedAddressesFromSource() {
+		env = append(env, corev1.EnvVar{
+			Name:  "ADVERTISED_ADDRESSES_DIR",
+			Value: advertisedAddressesDir,
+		})
+	}
+	if r.pandaCluster.RackAwarenessEnabled() {
+		env = append(env, corev1.EnvVar{
+			Name:  "RACK_NODE_LABEL",
+			Value: r.pandaCluster.RackNodeLabel(),
+		})
+	}
+	if r.pandaCluster.PandaproxyExternal() {
+		env = append(env, corev1.EnvVar{
+			Name:  "PROXY_HOST_PORT",
+			Value: r.getNodePort(PandaproxyPortName),
+		}, corev1.EnvVar{
+			Name:  "PROXY_EXTERNAL_SUBDOMAIN",
+			Value: r.pandaCluster.PandaproxyExternalSubdomain(),
+		})
+	}
+	return append(env, r.listenerNamesEnv()...)
+}
+
+// bootstrapUserEnv passes the credentials of the bootstrap user to Redpanda,
+// which creates the user when the cluster forms and ignores them afterwards
+func (r *StatefulSetResource) bootstrapUserEnv() []corev1.EnvVar {
+	user := r.pandaCluster.Spec.BootstrapUser
+	if user == nil {
+		return nil
+	}
+	secretKey := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: user.SecretRef,
+				Key:                  key,
+			},
+		}
+	}
+	return []corev1.EnvVar{
+		{
+			Name:      "BOOTSTRAP_USERNAME",
+			ValueFrom: secretKey(corev1.BasicAuthUsernameKey),
+		},
+		{
+			Name:      "BOOTSTRAP_PASSWORD",
+			ValueFrom: secretKey(corev1.BasicAuthPasswordKey),
+		},
+		{
+			Name:  "RP_BOOTSTRAP_USER",
+			Value: "$(BOOTSTRAP_USERNAME):$(BOOTSTRAP_PASSWORD):" + r.pandaCluster.BootstrapUserMechanism(),
+		},
+	}
+}
+
+// listenerNamesEnv passes the Kafka listener names to the configurator when
+// they differ from the defaults, it advertises an address per listener
+func (r *StatefulSetResource) listenerNamesEnv() []corev1.EnvVar {
+	var env []corev1.EnvVar
+	kafkaAPI := r.pandaCluster.Spec.Configuration.KafkaAPI
+	if kafkaAPI.InternalListenerName != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  "INTERNAL_LISTENER_NAME",
+			Value: kafkaAPI.InternalListenerName,
+		})
+	}
+	if kafkaAPI.ExternalListenerName != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  "EXTERNAL_LISTENER_NAME",
+			Value: kafkaAPI.ExternalListenerName,
+		})
+	}
+	return env
+}
+
+// bindAddressEnv passes the Pod IP to the configurator when the Kafka API has
+// to listen on the Pod IP only
+func (r *StatefulSetResource) bindAddressEnv() []corev1.EnvVar {
+	if !r.pandaCluster.Spec.Configuration.BindToPodIP {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{
+			Name: "POD_IP",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					APIVersion: "v1",
+					FieldPath:  "status.podIP",
+				},
+			},
+		},
+	}
+}
+
 func (r *StatefulSetResource) getServiceAccountName() string {
-	if r.pandaCluster.Spec.ExternalConnectivity.Enabled {
+	if r.pandaCluster.ConfiguratorReadsNode() {
 		return r.serviceAccountName
 	}
 	return ""
@@ -582,6 +1887,12 @@ func (r *StatefulSetResource) getPorts() []corev1.ContainerPort {
 				ContainerPort: int32(r.pandaCluster.Spec.Configuration.AdminAPI.Port),
 			},
 		}
+		if proxy := r.pandaCluster.Spec.Configuration.PandaproxyAPI; proxy != nil {
+			ports = append(ports, corev1.ContainerPort{
+				Name:          PandaproxyPortName + "-internal",
+				ContainerPort: int32(proxy.Port),
+			})
+		}
 		for _, port := range r.nodePortSvc.Spec.Ports {
 			ports = append(ports, corev1.ContainerPort{
 				Name: port.Name + "-external",
@@ -599,7 +1910,7 @@ func (r *StatefulSetResource) getPorts() []corev1.ContainerPort {
 		return ports
 	}
 
-	return []corev1.ContainerPort{
+	ports := []corev1.ContainerPort{
 		{
 			Name:          "kafka",
 			ContainerPort: int32(r.pandaCluster.Spec.Configuration.KafkaAPI.Port),
@@ -609,6 +1920,13 @@ func (r *StatefulSetResource) getPorts() []corev1.ContainerPort {
 			ContainerPort: int32(r.pandaCluster.Spec.Configuration.AdminAPI.Port),
 		},
 	}
+	if proxy := r.pandaCluster.Spec.Configuration.PandaproxyAPI; proxy != nil {
+		ports = append(ports, corev1.ContainerPort{
+			Name:          PandaproxyPortName,
+			ContainerPort: int32(proxy.Port),
+		})
+	}
+	return ports
 }
 
 func statefulSetKind() string {
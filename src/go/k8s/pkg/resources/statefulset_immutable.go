@@ -0,0 +1,117 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonImmutableFieldsChanged = "ImmutableFieldsChanged"
+	reasonStatefulSetRecreating  = "Recreating"
+	reasonStatefulSetUpToDate    = "UpToDate"
+)
+
+// immutableFieldChanges returns the immutable fields of the StatefulSet that
+// differ from the modified one. The selector is not one of them, Update
+// migrates it.
+func immutableFieldChanges(current, modified *appsv1.StatefulSet) []string {
+	var fields []string
+	if current.Spec.ServiceName != modified.Spec.ServiceName {
+		fields = append(fields, "spec.serviceName")
+	}
+	if modified.Spec.PodManagementPolicy != "" && current.Spec.PodManagementPolicy != modified.Spec.PodManagementPolicy {
+		fields = append(fields, "spec.podManagementPolicy")
+	}
+	if claimTemplatesChanged(current.Spec.VolumeClaimTemplates, modified.Spec.VolumeClaimTemplates) {
+		fields = append(fields, "spec.volumeClaimTemplates")
+	}
+	return fields
+}
+
+// claimTemplatesChanged compares the parts of the volume claim templates the
+// operator sets, the API server defaults the rest
+func claimTemplatesChanged(current, modified []corev1.PersistentVolumeClaim) bool {
+	if len(current) != len(modified) {
+		return true
+	}
+	for i := range modified {
+		c, m := current[i].Spec, modified[i].Spec
+		if current[i].Name != modified[i].Name ||
+			!apiequality.Semantic.DeepEqual(c.AccessModes, m.AccessModes) ||
+			!apiequality.Semantic.DeepEqual(c.Resources.Requests, m.Resources.Requests) {
+			return true
+		}
+		if m.StorageClassName != nil && (c.StorageClassName == nil || *c.StorageClassName != *m.StorageClassName) {
+			return true
+		}
+		if m.VolumeMode != nil && c.VolumeMode != nil && *c.VolumeMode != *m.VolumeMode {
+			return true
+		}
+	}
+	return false
+}
+
+// applyImmutableFieldChanges deletes the StatefulSet with orphan propagation
+// when the policy allows recreating it, it is created with the new spec on
+// the next reconcile and adopts the Pods. Otherwise the update is blocked.
+// The changes are reported in the StatefulSetUpdateBlocked condition.
+func (r *StatefulSetResource) applyImmutableFieldChanges(
+	ctx context.Context, sts *appsv1.StatefulSet, fields []string,
+) error {
+	changed := strings.Join(fields, ", ")
+	if r.pandaCluster.Spec.ImmutableFieldChanges != redpandav1alpha1.ImmutableFieldChangeRecreate {
+		r.logger.Info("WARNING: immutable StatefulSet fields changed, the update is blocked until spec.immutableFieldChanges is Recreate", "fields", changed)
+		return r.updateBlockedCondition(ctx, corev1.ConditionTrue, reasonImmutableFieldsChanged,
+			fmt.Sprintf("changing %s requires recreating the StatefulSet, set spec.immutableFieldChanges to Recreate", changed))
+	}
+
+	err := r.updateBlockedCondition(ctx, corev1.ConditionFalse, reasonStatefulSetRecreating,
+		fmt.Sprintf("recreating the StatefulSet to change %s, the pods and volumes are kept", changed))
+	if err != nil {
+		return err
+	}
+	r.logger.Info("Immutable StatefulSet fields changed, recreating it while preserving its pods", "fields", changed)
+	if err := r.Delete(ctx, sts, k8sclient.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete StatefulSet %s to change %s: %w", sts.Name, changed, err)
+	}
+	return &RequeueAfterError{
+		RequeueAfter: requeueDuration,
+		Msg:          fmt.Sprintf("StatefulSet %s is recreated to change %s", sts.Name, changed),
+	}
+}
+
+// updateBlockedCondition sets the StatefulSetUpdateBlocked condition. It is
+// only added once immutable fields changed.
+func (r *StatefulSetResource) updateBlockedCondition(
+	ctx context.Context, status corev1.ConditionStatus, reason, message string,
+) error {
+	if reason == reasonStatefulSetUpToDate &&
+		r.pandaCluster.Status.GetCondition(redpandav1alpha1.ClusterStatefulSetUpdateBlocked) == nil {
+		return nil
+	}
+	if !r.pandaCluster.Status.SetCondition(redpandav1alpha1.ClusterStatefulSetUpdateBlocked, status, reason, message) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return fmt.Errorf("unable to update the StatefulSet update condition: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	imagePrePullSuffix        = "-image-prepull"
+	imagePrePullContainerName = "prepull"
+	// imagePrePullImageAnnotationKey records the image the pre-pull
+	// DaemonSet was created for
+	imagePrePullImageAnnotationKey = "redpanda.vectorized.io/prepull-image"
+)
+
+// prePullImage makes sure the nodes of the brokers that do not run the new
+// image yet have pulled it before the first of them is restarted. A
+// DaemonSet pinned to those nodes runs the image. It returns a
+// RequeueAfterError until the DaemonSet is ready on every node or the
+// timeout of the pre-pull passed, the upgrade continues then. The DaemonSet
+// is deleted when every broker runs the new image or the pre-pull is
+// disabled.
+func (r *StatefulSetResource) prePullImage(
+	ctx context.Context, sts *appsv1.StatefulSet, newImage string,
+) error {
+	if r.pandaCluster.Spec.ImagePrePull == nil {
+		return r.deleteImagePrePull(ctx)
+	}
+	nodes, err := r.nodesOfStaleBrokers(ctx, sts, newImage)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return r.deleteImagePrePull(ctx)
+	}
+
+	var ds appsv1.DaemonSet
+	err = r.Get(ctx, r.imagePrePullKey(), &ds)
+	if apierrors.IsNotFound(err) {
+		obj := r.imagePrePullDaemonSet(newImage, nodes)
+		if err := SetOwner(r.pandaCluster, obj, r.scheme); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, obj); err != nil {
+			return fmt.Errorf("unable to create the image pre-pull DaemonSet: %w", err)
+		}
+		r.logger.Info("Pre-pulling the image of the upgrade", "image", newImage, "nodes", nodes)
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("pre-pulling %s on %d nodes", newImage, len(nodes))}
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching the image pre-pull DaemonSet: %w", err)
+	}
+
+	// the pre-pull of a previous upgrade is replaced, its timeout does not
+	// apply to the new image
+	if ds.Annotations[imagePrePullImageAnnotationKey] != newImage {
+		if err := r.deleteImagePrePull(ctx); err != nil {
+			return err
+		}
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("replacing the pre-pull of %s", ds.Annotations[imagePrePullImageAnnotationKey])}
+	}
+
+	observed := ds.Status.ObservedGeneration >= ds.Generation
+	if observed && ds.Status.NumberReady >= ds.Status.DesiredNumberScheduled {
+		return nil
+	}
+	if time.Since(ds.CreationTimestamp.Time) >= r.pandaCluster.ImagePrePullTimeout() {
+		r.logger.Info("The image pre-pull timed out, the upgrade continues",
+			"image", newImage, "ready", ds.Status.NumberReady, "nodes", ds.Status.DesiredNumberScheduled)
+		return nil
+	}
+	return &RequeueAfterError{RequeueAfter: requeueDuration,
+		Msg: fmt.Sprintf("waiting for nodes to pull %s, %d of %d done",
+			newImage, ds.Status.NumberReady, ds.Status.DesiredNumberScheduled)}
+}
+
+// nodesOfStaleBrokers returns the nodes of the broker Pods whose Redpanda
+// container does not run the image, in the order of their names
+func (r *StatefulSetResource) nodesOfStaleBrokers(
+	ctx context.Context, sts *appsv1.StatefulSet, image string,
+) ([]string, error) {
+	seen := map[string]bool{}
+	var nodes []string
+	for ordinal := int32(0); ordinal < *sts.Spec.Replicas; ordinal++ {
+		var pod corev1.Pod
+		err := r.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("%s-%d", sts.Name, ordinal), Namespace: sts.Namespace}, &pod)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch the broker Pod: %w", err)
+		}
+		if pod.Spec.NodeName == "" || seen[pod.Spec.NodeName] || !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		container, err := findContainer(pod.Spec.Containers, r.pandaCluster.RedpandaContainerName())
+		if err != nil || container.Image == image {
+			continue
+		}
+		seen[pod.Spec.NodeName] = true
+		nodes = append(nodes, pod.Spec.NodeName)
+	}
+	sort.Strings(nodes)
+	return nodes, nil
+}
+
+// imagePrePullDaemonSet runs the image with a minimal footprint on the
+// nodes. The Pods pin the nodes by name like the ones the DaemonSet
+// controller schedules itself.
+func (r *StatefulSetResource) imagePrePullDaemonSet(
+	image string, nodes []string,
+) *appsv1.DaemonSet {
+	objLabels := labels.ForImagePrePull(r.pandaCluster)
+	footprint := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("10m"),
+		corev1.ResourceMemory: resource.MustParse("16Mi"),
+	}
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.imagePrePullKey().Name,
+			Namespace:   r.imagePrePullKey().Namespace,
+			Labels:      objLabels,
+			Annotations: map[string]string{imagePrePullImageAnnotationKey: image},
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       daemonSetKind(),
+			APIVersion: "apps/v1",
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: objLabels.AsAPISelector(),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: objLabels,
+				},
+				Spec: corev1.PodSpec{
+					Affinity: &corev1.Affinity{
+						NodeAffinity: &corev1.NodeAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+								NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+									MatchFields: []corev1.NodeSelectorRequirement{{
+										Key:      "metadata.name",
+										Operator: corev1.NodeSelectorOpIn,
+										Values:   nodes,
+									}},
+								}},
+							},
+						},
+					},
+					Tolerations:                   r.pandaCluster.BrokerTolerations(),
+					TerminationGracePeriodSeconds: pointer.Int64Ptr(0),
+					Containers: []corev1.Container{
+						{
+							Name:            imagePrePullContainerName,
+							Image:           image,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command: []string{"/bin/sh", "-c",
+								"trap 'exit 0' TERM; while true; do sleep 3600 & wait $!; done"},
+							SecurityContext: &corev1.SecurityContext{
+								RunAsUser:  pointer.Int64Ptr(userID),
+								RunAsGroup: pointer.Int64Ptr(groupID),
+							},
+							Resources: corev1.ResourceRequirements{
+								Limits:   footprint,
+								Requests: footprint,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *StatefulSetResource) deleteImagePrePull(ctx context.Context) error {
+	var ds appsv1.DaemonSet
+	err := r.Get(ctx, r.imagePrePullKey(), &ds)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching the image pre-pull DaemonSet: %w", err)
+	}
+	if !IsManagedBy(r.pandaCluster, &ds) {
+		return nil
+	}
+	r.logger.Info("Deleting the image pre-pull DaemonSet", "image", ds.Annotations[imagePrePullImageAnnotationKey])
+	if err := r.Delete(ctx, &ds); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete the image pre-pull DaemonSet: %w", err)
+	}
+	return nil
+}
+
+func (r *StatefulSetResource) imagePrePullKey() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + imagePrePullSuffix, Namespace: r.pandaCluster.Namespace}
+}
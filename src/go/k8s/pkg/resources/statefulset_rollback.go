@@ -0,0 +1,218 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	reasonRolledBack     = "RolledBack"
+	reasonRolloutRetried = "TemplateChanged"
+)
+
+// templateHash returns the hash of the Pod template the cluster spec renders,
+// it identifies the update that was rolled back
+func (r *StatefulSetResource) templateHash() (string, error) {
+	obj, err := r.obj()
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(obj.(*appsv1.StatefulSet).Spec.Template)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal the Pod template: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
+// rollbackStalledPod rolls the update back when the Pod of the ordinal runs
+// the update revision and did not become ready within the progress deadline
+// of the rollback. It does nothing when the rollback is not enabled.
+func (r *StatefulSetResource) rollbackStalledPod(
+	ctx context.Context, sts *appsv1.StatefulSet, ordinal int32,
+) error {
+	if r.pandaCluster.Spec.RolloutRollback == nil || sts.Status.CurrentRevision == "" ||
+		sts.Status.CurrentRevision == sts.Status.UpdateRevision {
+		return nil
+	}
+
+	var pod corev1.Pod
+	podName := fmt.Sprintf("%s-%d", sts.Name, ordinal)
+	if err := r.Get(ctx, types.NamespacedName{Name: podName, Namespace: sts.Namespace}, &pod); apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	// a Pod that was not ready before the update is not its failure
+	if pod.Labels[appsv1.ControllerRevisionHashLabelKey] != sts.Status.UpdateRevision || podIsReady(&pod) ||
+		time.Since(pod.CreationTimestamp.Time) < r.pandaCluster.RolloutProgressDeadline() {
+		return nil
+	}
+
+	return r.rollback(ctx, sts, podName)
+}
+
+// rollback reverts the Pod template of the StatefulSet to its current
+// revision, the revision every broker ran before the update, and records the
+// failed template in the status. The brokers are restarted on the reverted
+// revision by restartRolledBackPods.
+func (r *StatefulSetResource) rollback(
+	ctx context.Context, sts *appsv1.StatefulSet, podName string,
+) error {
+	template, err := r.revisionTemplate(ctx, sts.Namespace, sts.Status.CurrentRevision)
+	if err != nil {
+		return err
+	}
+	hash, err := r.templateHash()
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info("Pod did not become ready on the update revision, rolling back", "pod", podName,
+		"update revision", sts.Status.UpdateRevision, "revision", sts.Status.CurrentRevision)
+	reverted := sts.DeepCopy()
+	reverted.Spec.Template = *template
+	if err := r.Update(ctx, reverted); err != nil {
+		return fmt.Errorf("failed to roll back StatefulSet %s: %w", sts.Name, err)
+	}
+
+	r.pandaCluster.Status.Upgrading = false
+	r.pandaCluster.Status.RestartOrder = nil
+	r.pandaCluster.Status.RolloutFailure = &redpandav1alpha1.RolloutFailure{
+		TemplateHash: hash,
+		Revision:     sts.Status.CurrentRevision,
+		Pod:          podName,
+		FailedAt:     metav1.Now(),
+	}
+	r.pandaCluster.Status.SetCondition(redpandav1alpha1.ClusterRolloutFailed, corev1.ConditionTrue, reasonRolledBack,
+		fmt.Sprintf("pod %s was not ready on revision %s within %s, rolled back to revision %s",
+			podName, sts.Status.UpdateRevision, r.pandaCluster.RolloutProgressDeadline(), sts.Status.CurrentRevision))
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return fmt.Errorf("unable to record the rollback: %w", err)
+	}
+
+	return &RequeueAfterError{RequeueAfter: requeueDuration,
+		Msg: fmt.Sprintf("rolled back StatefulSet %s to revision %s", sts.Name, sts.Status.CurrentRevision)}
+}
+
+// revisionTemplate returns the Pod template stored in the ControllerRevision
+// of the StatefulSet
+func (r *StatefulSetResource) revisionTemplate(
+	ctx context.Context, namespace, name string,
+) (*corev1.PodTemplateSpec, error) {
+	var revision appsv1.ControllerRevision
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &revision); err != nil {
+		return nil, fmt.Errorf("unable to get revision %s to roll back to: %w", name, err)
+	}
+	// the StatefulSet controller stores the template as a patch replacing
+	// the one of the StatefulSet
+	var patch struct {
+		Spec struct {
+			Template corev1.PodTemplateSpec `json:"template"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(revision.Data.Raw, &patch); err != nil {
+		return nil, fmt.Errorf("unable to decode revision %s: %w", name, err)
+	}
+	return &patch.Spec.Template, nil
+}
+
+// keepRolledBackRevision returns true while the StatefulSet is kept on the
+// revision of a rollback. The update is retried once the cluster spec renders
+// another Pod template or the rollback is disabled.
+func (r *StatefulSetResource) keepRolledBackRevision(
+	ctx context.Context, sts *appsv1.StatefulSet,
+) (bool, error) {
+	failure := r.pandaCluster.Status.RolloutFailure
+	if failure == nil {
+		return false, nil
+	}
+	hash, err := r.templateHash()
+	if err != nil {
+		return false, err
+	}
+	if hash == failure.TemplateHash && r.pandaCluster.Spec.RolloutRollback != nil {
+		return true, r.restartRolledBackPods(ctx, sts, failure.Revision)
+	}
+
+	r.logger.Info("Pod template changed since the rollback, retrying the update", "revision", failure.Revision)
+	r.pandaCluster.Status.RolloutFailure = nil
+	r.pandaCluster.Status.SetCondition(redpandav1alpha1.ClusterRolloutFailed, corev1.ConditionFalse, reasonRolloutRetried,
+		fmt.Sprintf("the Pod template changed since pod %s failed, retrying the update", failure.Pod))
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return false, fmt.Errorf("unable to clear the rollback: %w", err)
+	}
+	return false, nil
+}
+
+// restartRolledBackPods deletes the broker Pods that are not ready and do not
+// run the revision of the rollback, so they are recreated on it. The
+// StatefulSet controller does not replace a Pod that is not ready by itself.
+// With the OnDelete strategy the ready brokers left on another revision are
+// then restarted one at a time, the RollingUpdate strategy reverts them.
+func (r *StatefulSetResource) restartRolledBackPods(
+	ctx context.Context, sts *appsv1.StatefulSet, revision string,
+) error {
+	if sts.Status.ObservedGeneration < sts.Generation {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("wait for the StatefulSet controller to observe the rollback of %s", sts.Name)}
+	}
+
+	var stale *corev1.Pod
+	for ordinal := *sts.Spec.Replicas - 1; ordinal >= 0; ordinal-- {
+		pod := &corev1.Pod{}
+		podName := fmt.Sprintf("%s-%d", sts.Name, ordinal)
+		if err := r.Get(ctx, types.NamespacedName{Name: podName, Namespace: sts.Namespace}, pod); apierrors.IsNotFound(err) {
+			return &RequeueAfterError{RequeueAfter: requeueDuration,
+				Msg: fmt.Sprintf("wait for pod %s to be recreated on revision %s", podName, revision)}
+		} else if err != nil {
+			return err
+		}
+		if pod.Labels[appsv1.ControllerRevisionHashLabelKey] == revision {
+			continue
+		}
+		if !podIsReady(pod) {
+			r.logger.Info("Restarting pod on the rolled back revision", "pod", podName, "revision", revision)
+			if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete pod %s: %w", podName, err)
+			}
+			return &RequeueAfterError{RequeueAfter: requeueDuration,
+				Msg: fmt.Sprintf("wait for pod %s to restart on revision %s", podName, revision)}
+		}
+		if stale == nil {
+			stale = pod
+		}
+	}
+
+	if stale == nil || sts.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType {
+		return nil
+	}
+	if sts.Status.ReadyReplicas < *sts.Spec.Replicas {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("wait for the brokers to be ready before restarting pod %s on revision %s", stale.Name, revision)}
+	}
+	r.logger.Info("Restarting pod on the rolled back revision", "pod", stale.Name, "revision", revision)
+	if err := r.Delete(ctx, stale); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete pod %s: %w", stale.Name, err)
+	}
+	return &RequeueAfterError{RequeueAfter: requeueDuration,
+		Msg: fmt.Sprintf("wait for pod %s to restart on revision %s", stale.Name, revision)}
+}
@@ -11,16 +11,26 @@ package resources_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
 	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/pointer"
@@ -61,7 +71,7 @@ func TestEnsure(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		c := fake.NewClientBuilder().Build()
+		c := fake.NewClientBuilder().WithObjects(headlessService(tt.pandaCluster)).Build()
 
 		err := redpandav1alpha1.AddToScheme(scheme.Scheme)
 		assert.NoError(t, err, tt.name)
@@ -78,14 +88,19 @@ func TestEnsure(t *testing.T) {
 			tt.pandaCluster,
 			scheme.Scheme,
 			"cluster.local",
-			"servicename",
+			tt.pandaCluster.Name,
 			types.NamespacedName{Name: "test", Namespace: "test"},
 			types.NamespacedName{},
 			types.NamespacedName{},
 			types.NamespacedName{},
 			types.NamespacedName{},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			types.NamespacedName{},
 			"",
 			"latest",
+			admin.NewMockAdminAPI().Factory(),
+			nil,
 			ctrl.Log.WithName("test"))
 
 		err = sts.Ensure(context.Background())
@@ -105,6 +120,1554 @@ func TestEnsure(t *testing.T) {
 	}
 }
 
+func TestEnsureScaleUpStep(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	existing := stsFromCluster(cluster)
+	existing.Status.ReadyReplicas = 1
+
+	cluster.Spec.Replicas = pointer.Int32Ptr(5)
+	cluster.Spec.ScaleUpStep = pointer.Int32Ptr(2)
+
+	c := fake.NewClientBuilder().WithObjects(existing, headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+
+	var requeue *res.RequeueAfterError
+	assert.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	assert.Equal(t, int32(3), *actual.Spec.Replicas)
+
+	// the new brokers are not ready yet
+	assert.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	assert.Equal(t, int32(3), *actual.Spec.Replicas)
+}
+
+func TestConfigChangeRollingRestart(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	existing := stsFromCluster(cluster)
+	existing.Spec.Template.Annotations = map[string]string{res.ConfigHashAnnotationKey: "old"}
+	existing.Status.ReadyReplicas = 3
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:        res.ConfigMapKey(cluster).Name,
+		Namespace:   cluster.Namespace,
+		Annotations: map[string]string{res.ConfigHashAnnotationKey: "new"},
+	}}
+	pod := func(ordinal int, hash string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("cluster-%d", ordinal),
+				Namespace:   cluster.Namespace,
+				Annotations: map[string]string{res.ConfigHashAnnotationKey: hash},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "redpanda", Image: "image:latest"}}},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+			}}},
+		}
+	}
+
+	c := fake.NewClientBuilder().
+		WithObjects(cluster, existing, cm, pod(0, "old"), pod(1, "old"), pod(2, "old"), headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	partition := func() int32 {
+		actual := &v1.StatefulSet{}
+		require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+		assert.Equal(t, "new", actual.Spec.Template.Annotations[res.ConfigHashAnnotationKey])
+		require.NotNil(t, actual.Spec.UpdateStrategy.RollingUpdate)
+		return *actual.Spec.UpdateStrategy.RollingUpdate.Partition
+	}
+
+	// only the broker with the highest ordinal restarts
+	var requeue *res.RequeueAfterError
+	require.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+	assert.Contains(t, requeue.Msg, "wait for pod (ordinal: 2) to restart")
+	assert.Equal(t, int32(2), partition())
+	assert.True(t, cluster.Status.Upgrading)
+
+	// the next broker waits until the restarted one has the new configuration
+	require.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+	assert.Equal(t, int32(2), partition())
+
+	// and until it serves the Kafka API again, which it does not in the test
+	require.NoError(t, c.Update(context.Background(), pod(2, "new")))
+	require.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+	assert.Contains(t, requeue.Msg, "not ready")
+	assert.Equal(t, int32(2), partition())
+}
+
+func TestLeadershipRestartOrder(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	cluster.Spec.GracefulShutdown = &redpandav1alpha1.GracefulShutdown{}
+	cluster.Spec.RestartOrder = redpandav1alpha1.RestartOrderFewestLeadershipsFirst
+	existing := stsFromCluster(cluster)
+	existing.Spec.Template.Annotations = map[string]string{res.ConfigHashAnnotationKey: "old"}
+	existing.Status.ReadyReplicas = 3
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:        res.ConfigMapKey(cluster).Name,
+		Namespace:   cluster.Namespace,
+		Annotations: map[string]string{res.ConfigHashAnnotationKey: "new"},
+	}}
+	pod := func(ordinal int, hash string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("cluster-%d", ordinal),
+				Namespace:   cluster.Namespace,
+				Annotations: map[string]string{res.ConfigHashAnnotationKey: hash},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "redpanda", Image: "image:latest"}}},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+			}}},
+		}
+	}
+	// this version does not list the partitions or report the controller
+	adminAPI := admin.NewMockAdminAPI()
+	adminAPI.Stubbed = true
+
+	c := fake.NewClientBuilder().
+		WithObjects(cluster, existing, cm, pod(0, "old"), pod(1, "old"), pod(2, "old"), headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		adminAPI.Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+
+	// without the leaderships the brokers restart in ordinal order
+	var requeue *res.RequeueAfterError
+	require.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+	assert.Contains(t, requeue.Msg, "wait for pod (ordinal: 2) to restart")
+	assert.Equal(t, []int32{2, 1, 0}, cluster.Status.RestartOrder)
+	unsupported := sts.UnsupportedAdminAPI()
+	require.Len(t, unsupported, 2)
+	assert.Contains(t, unsupported[0].Error(), "broker restart order")
+	assert.Contains(t, unsupported[1].Error(), "controller leadership transfer")
+	assert.True(t, admin.IsUnsupported(unsupported[0]) && admin.IsUnsupported(unsupported[1]))
+	assert.True(t, apierrors.IsNotFound(c.Get(context.Background(),
+		types.NamespacedName{Name: "cluster-2", Namespace: cluster.Namespace}, &corev1.Pod{})))
+	actual := &v1.StatefulSet{}
+	require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	assert.Equal(t, v1.OnDeleteStatefulSetStrategyType, actual.Spec.UpdateStrategy.Type)
+	assert.Equal(t, "new", actual.Spec.Template.Annotations[res.ConfigHashAnnotationKey])
+
+	// the order is kept while the broker restarts
+	require.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+	assert.Equal(t, []int32{2, 1, 0}, cluster.Status.RestartOrder)
+
+	// the next broker waits until the restarted one serves the Kafka API
+	// again, which it does not in the test
+	require.NoError(t, c.Create(context.Background(), pod(2, "new")))
+	require.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+	assert.Contains(t, requeue.Msg, "not ready")
+	assert.NoError(t, c.Get(context.Background(),
+		types.NamespacedName{Name: "cluster-1", Namespace: cluster.Namespace}, &corev1.Pod{}))
+}
+
+func TestRolloutRollback(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	cluster.Spec.RolloutRollback = &redpandav1alpha1.RolloutRollback{ProgressDeadlineSeconds: pointer.Int32Ptr(60)}
+	existing := stsFromCluster(cluster)
+	existing.Spec.Template.Annotations = map[string]string{res.ConfigHashAnnotationKey: "old"}
+	existing.Status.Replicas = 3
+	existing.Status.ReadyReplicas = 2
+	existing.Status.CurrentRevision = "cluster-old"
+	existing.Status.UpdateRevision = "cluster-new"
+	data, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"template": existing.Spec.Template},
+	})
+	require.NoError(t, err)
+	revision := &v1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-old", Namespace: cluster.Namespace},
+		Data:       runtime.RawExtension{Raw: data},
+		Revision:   1,
+	}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:        res.ConfigMapKey(cluster).Name,
+		Namespace:   cluster.Namespace,
+		Annotations: map[string]string{res.ConfigHashAnnotationKey: "new"},
+	}}
+	pod := func(ordinal int, hash, revision string, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              fmt.Sprintf("cluster-%d", ordinal),
+				Namespace:         cluster.Namespace,
+				Annotations:       map[string]string{res.ConfigHashAnnotationKey: hash},
+				Labels:            map[string]string{v1.ControllerRevisionHashLabelKey: revision},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "redpanda", Image: "image:latest"}}},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             ready,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+			}}},
+		}
+	}
+
+	// the broker restarted on the new configuration is not ready for an hour
+	c := fake.NewClientBuilder().WithObjects(cluster, existing, revision, cm, headlessService(cluster),
+		pod(0, "old", "cluster-old", corev1.ConditionTrue),
+		pod(1, "old", "cluster-old", corev1.ConditionTrue),
+		pod(2, "new", "cluster-new", corev1.ConditionFalse)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	templateHash := func() string {
+		actual := &v1.StatefulSet{}
+		require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+		return actual.Spec.Template.Annotations[res.ConfigHashAnnotationKey]
+	}
+
+	var requeue *res.RequeueAfterError
+	require.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+	assert.Contains(t, requeue.Msg, "rolled back StatefulSet cluster to revision cluster-old")
+	assert.Equal(t, "old", templateHash())
+	assert.False(t, cluster.Status.Upgrading)
+	require.NotNil(t, cluster.Status.RolloutFailure)
+	assert.Equal(t, "cluster-2", cluster.Status.RolloutFailure.Pod)
+	assert.Equal(t, "cluster-old", cluster.Status.RolloutFailure.Revision)
+	assert.Equal(t, corev1.ConditionTrue, cluster.Status.GetCondition(redpandav1alpha1.ClusterRolloutFailed).Status)
+
+	// the failed broker is restarted on the reverted revision and the
+	// StatefulSet is kept on it
+	require.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+	assert.Contains(t, requeue.Msg, "wait for pod cluster-2 to restart on revision cluster-old")
+	assert.True(t, apierrors.IsNotFound(c.Get(context.Background(),
+		types.NamespacedName{Name: "cluster-2", Namespace: cluster.Namespace}, &corev1.Pod{})))
+	assert.Equal(t, "old", templateHash())
+
+	// a new version retries the update
+	cluster.Spec.Version = "v2"
+	require.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+	assert.Contains(t, requeue.Msg, "wait for pod (ordinal: 2) to restart")
+	assert.Nil(t, cluster.Status.RolloutFailure)
+	assert.Equal(t, corev1.ConditionFalse, cluster.Status.GetCondition(redpandav1alpha1.ClusterRolloutFailed).Status)
+	assert.Equal(t, "new", templateHash())
+}
+
+func TestEnsureNoScaleDown(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	existing := stsFromCluster(cluster)
+
+	// e.g. written through the scale subresource, which bypasses the webhook.
+	// Drained brokers are kept while decommission draining is disabled.
+	cluster.Spec.Replicas = pointer.Int32Ptr(1)
+	cluster.Status.DrainingBrokers = []redpandav1alpha1.DrainingBroker{
+		{NodeID: 1, Phase: redpandav1alpha1.DrainPhaseDrained},
+		{NodeID: 2, Phase: redpandav1alpha1.DrainPhaseDrained},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(existing, headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	assert.Equal(t, int32(3), *actual.Spec.Replicas)
+}
+
+func TestEnsureDrainedScaleDown(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(4)
+	existing := stsFromCluster(cluster)
+
+	// only the drained broker at the top ordinal is removed
+	cluster.Spec.Replicas = pointer.Int32Ptr(1)
+	cluster.Spec.DecommissionDrain = &redpandav1alpha1.DecommissionDrain{}
+	cluster.Status.DrainingBrokers = []redpandav1alpha1.DrainingBroker{
+		{NodeID: 1, Phase: redpandav1alpha1.DrainPhaseDrained},
+		{NodeID: 2, Phase: redpandav1alpha1.DrainPhaseDraining},
+		{NodeID: 3, Phase: redpandav1alpha1.DrainPhaseDrained},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(existing, headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	assert.Equal(t, int32(3), *actual.Spec.Replicas)
+	assert.Contains(t, actual.Spec.Template.Spec.ReadinessGates,
+		corev1.PodReadinessGate{ConditionType: res.ServingReadinessGate})
+}
+
+func TestImmutableFieldChanges(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	existing := stsFromCluster(cluster)
+	cluster.Spec.Storage.Capacity = resource.MustParse("20Gi")
+
+	c := fake.NewClientBuilder().WithObjects(existing, cluster, headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+
+	// without the opt-in the update is blocked
+	assert.NoError(t, sts.Ensure(context.Background()))
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	assert.Equal(t, resource.MustParse("10Gi"), actual.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage])
+	condition := cluster.Status.GetCondition(redpandav1alpha1.ClusterStatefulSetUpdateBlocked)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, corev1.ConditionTrue, condition.Status)
+		assert.Contains(t, condition.Message, "spec.volumeClaimTemplates")
+	}
+
+	// with it the StatefulSet is deleted, orphaning the pods, and created
+	// again with the new template
+	cluster.Spec.ImmutableFieldChanges = redpandav1alpha1.ImmutableFieldChangeRecreate
+	var requeue *res.RequeueAfterError
+	assert.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+	assert.True(t, apierrors.IsNotFound(c.Get(context.Background(), sts.Key(), &v1.StatefulSet{})))
+	assert.NoError(t, sts.Ensure(context.Background()))
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	assert.Equal(t, resource.MustParse("20Gi"), actual.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage])
+	assert.NoError(t, sts.Ensure(context.Background()))
+	assert.Equal(t, corev1.ConditionFalse, cluster.Status.GetCondition(redpandav1alpha1.ClusterStatefulSetUpdateBlocked).Status)
+}
+
+func TestHeadlessServiceMismatch(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	tests := []struct {
+		name    string
+		service *corev1.Service
+	}{
+		{"not headless", func() *corev1.Service {
+			svc := headlessService(cluster)
+			svc.Spec.ClusterIP = "10.0.0.1"
+			return svc
+		}()},
+		{"brokers of another cluster", func() *corev1.Service {
+			svc := headlessService(cluster)
+			svc.Spec.Selector = labels.ForCluster(&redpandav1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "renamed", Namespace: cluster.Namespace},
+			}).AsAPISelector().MatchLabels
+			return svc
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithObjects(tt.service).Build()
+			sts := res.NewStatefulSet(
+				c,
+				cluster,
+				scheme.Scheme,
+				"cluster.local",
+				cluster.Name,
+				types.NamespacedName{Name: "test", Namespace: "test"},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				"",
+				"latest",
+				admin.NewMockAdminAPI().Factory(),
+				nil,
+				ctrl.Log.WithName("test"))
+			assert.Error(t, sts.Ensure(context.Background()))
+			assert.True(t, apierrors.IsNotFound(c.Get(context.Background(), sts.Key(), &v1.StatefulSet{})))
+		})
+	}
+
+	// without the Service the StatefulSet waits for it
+	c := fake.NewClientBuilder().Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	var requeue *res.RequeueAfterError
+	assert.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+}
+
+func TestPVCRetentionPolicy(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Storage.PVCRetentionPolicy = &redpandav1alpha1.PVCRetentionPolicy{
+		WhenDeleted: redpandav1alpha1.PVCRetentionPolicyDelete,
+		WhenScaled:  redpandav1alpha1.PVCRetentionPolicyDelete,
+	}
+	existing := stsFromCluster(cluster)
+	existing.UID = "sts-uid"
+	pvc := func(name string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cluster.Namespace,
+			Labels:    labels.ForCluster(cluster),
+		}}
+	}
+
+	c := fake.NewClientBuilder().WithObjects(existing, pvc("datadir-cluster-0"), pvc("datadir-cluster-1"), headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	var actual corev1.PersistentVolumeClaim
+	assert.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "datadir-cluster-0", Namespace: cluster.Namespace}, &actual))
+	assert.Len(t, actual.OwnerReferences, 1)
+	assert.Equal(t, types.UID("sts-uid"), actual.OwnerReferences[0].UID)
+	err := c.Get(context.Background(), types.NamespacedName{Name: "datadir-cluster-1", Namespace: cluster.Namespace}, &actual)
+	assert.True(t, apierrors.IsNotFound(err))
+
+	cluster.Spec.Storage.PVCRetentionPolicy.WhenDeleted = redpandav1alpha1.PVCRetentionPolicyRetain
+	assert.NoError(t, sts.Ensure(context.Background()))
+	assert.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "datadir-cluster-0", Namespace: cluster.Namespace}, &actual))
+	assert.Empty(t, actual.OwnerReferences)
+}
+
+func TestReadinessProbe(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	tests := []struct {
+		name           string
+		tls            redpandav1alpha1.AdminAPITLS
+		expectedScheme corev1.URIScheme
+		expectTCP      bool
+	}{
+		{"plaintext admin API", redpandav1alpha1.AdminAPITLS{}, corev1.URISchemeHTTP, false},
+		{"TLS admin API", redpandav1alpha1.AdminAPITLS{Enabled: true}, corev1.URISchemeHTTPS, false},
+		{"mutual TLS admin API", redpandav1alpha1.AdminAPITLS{Enabled: true, RequireClientAuth: true}, "", true},
+	}
+
+	for _, tt := range tests {
+		cluster := pandaCluster()
+		cluster.Spec.Configuration.AdminAPI.Port = 9644
+		cluster.Spec.Configuration.TLS.AdminAPI = tt.tls
+
+		c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+		sts := res.NewStatefulSet(
+			c,
+			cluster,
+			scheme.Scheme,
+			"cluster.local",
+			cluster.Name,
+			types.NamespacedName{Name: "test", Namespace: "test"},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			"",
+			"latest",
+			admin.NewMockAdminAPI().Factory(),
+			nil,
+			ctrl.Log.WithName("test"))
+		assert.NoError(t, sts.Ensure(context.Background()), tt.name)
+
+		actual := &v1.StatefulSet{}
+		assert.NoError(t, c.Get(context.Background(), sts.Key(), actual), tt.name)
+
+		probe := actual.Spec.Template.Spec.Containers[0].ReadinessProbe
+		if !assert.NotNil(t, probe, tt.name) {
+			continue
+		}
+		if tt.expectTCP {
+			assert.Nil(t, probe.HTTPGet, tt.name)
+			assert.Equal(t, 9644, probe.TCPSocket.Port.IntValue(), tt.name)
+			continue
+		}
+		assert.Equal(t, 9644, probe.HTTPGet.Port.IntValue(), tt.name)
+		assert.Equal(t, tt.expectedScheme, probe.HTTPGet.Scheme, tt.name)
+	}
+}
+
+func TestLivenessProbe(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.RPCServer.Port = 33145
+	cluster.Spec.LivenessProbe = &redpandav1alpha1.LivenessProbe{FailureThreshold: pointer.Int32Ptr(10)}
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	probe := actual.Spec.Template.Spec.Containers[0].LivenessProbe
+	if !assert.NotNil(t, probe) {
+		return
+	}
+	assert.Nil(t, probe.HTTPGet)
+	assert.Equal(t, 33145, probe.TCPSocket.Port.IntValue())
+	assert.Equal(t, int32(10), probe.FailureThreshold)
+	assert.Equal(t, int32(10), probe.PeriodSeconds)
+}
+
+func TestImagePrePull(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	cluster.Spec.Version = "v2"
+	cluster.Spec.ImagePrePull = &redpandav1alpha1.ImagePrePull{}
+	existing := stsFromCluster(cluster)
+	pod := func(ordinal int, node string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("cluster-%d", ordinal),
+				Namespace: cluster.Namespace,
+			},
+			Spec: corev1.PodSpec{
+				NodeName:   node,
+				Containers: []corev1.Container{{Name: "redpanda", Image: "image:latest"}},
+			},
+		}
+	}
+
+	c := fake.NewClientBuilder().WithObjects(cluster, existing, headlessService(cluster),
+		pod(0, "node-b"), pod(1, "node-a"), pod(2, "node-b")).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	key := types.NamespacedName{Name: "cluster-image-prepull", Namespace: cluster.Namespace}
+
+	// the upgrade starts with the pre-pull on the nodes of the brokers
+	var requeue *res.RequeueAfterError
+	require.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+	assert.Equal(t, "pre-pulling image:v2 on 2 nodes", requeue.Msg)
+	var ds v1.DaemonSet
+	require.NoError(t, c.Get(context.Background(), key, &ds))
+	assert.Equal(t, "image:v2", ds.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, []string{"node-a", "node-b"},
+		ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchFields[0].Values)
+	assert.NoError(t, c.Get(context.Background(),
+		types.NamespacedName{Name: "cluster-0", Namespace: cluster.Namespace}, &corev1.Pod{}))
+
+	// no broker restarts while a node pulls
+	ds.Status.DesiredNumberScheduled = 2
+	ds.Status.NumberReady = 1
+	require.NoError(t, c.Status().Update(context.Background(), &ds))
+	require.True(t, errors.As(sts.Ensure(context.Background()), &requeue))
+	assert.Equal(t, "waiting for nodes to pull image:v2, 1 of 2 done", requeue.Msg)
+
+	// the pre-pull is deleted once every broker runs the new image
+	for i := 0; i < 3; i++ {
+		var p corev1.Pod
+		require.NoError(t, c.Get(context.Background(),
+			types.NamespacedName{Name: fmt.Sprintf("cluster-%d", i), Namespace: cluster.Namespace}, &p))
+		p.Spec.Containers[0].Image = "image:v2"
+		require.NoError(t, c.Update(context.Background(), &p))
+	}
+	_ = sts.Ensure(context.Background())
+	assert.True(t, apierrors.IsNotFound(c.Get(context.Background(), key, &v1.DaemonSet{})))
+}
+
+func TestFixDataDirOwnership(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Storage.FixDataDirOwnership = true
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	initContainers := actual.Spec.Template.Spec.InitContainers
+	if !assert.Len(t, initContainers, 2) {
+		return
+	}
+	ownership := initContainers[1]
+	assert.Equal(t, cluster.FullImageName(), ownership.Image)
+	assert.Equal(t, int64(0), *ownership.SecurityContext.RunAsUser)
+	assert.Contains(t, ownership.Command[2], "chown -h 101:101")
+	assert.Equal(t, "/var/lib/redpanda/data", ownership.VolumeMounts[0].MountPath)
+}
+
+func TestStorageCheck(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Storage.Hints = &redpandav1alpha1.StorageHints{
+		MountOptions:  []string{"noatime", "nodiscard"},
+		DiskScheduler: "none",
+	}
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	initContainers := actual.Spec.Template.Spec.InitContainers
+	if !assert.Len(t, initContainers, 2) {
+		return
+	}
+	check := initContainers[1]
+	assert.Equal(t, res.StorageCheckContainerName, check.Name)
+	assert.Equal(t, int64(101), *check.SecurityContext.RunAsUser)
+	assert.True(t, check.VolumeMounts[0].ReadOnly)
+	assert.Contains(t, check.Env, corev1.EnvVar{Name: "EXPECTED_FILESYSTEM", Value: "xfs"})
+	assert.Contains(t, check.Env, corev1.EnvVar{Name: "EXPECTED_MOUNT_OPTIONS", Value: "noatime nodiscard"})
+	assert.Contains(t, check.Env, corev1.EnvVar{Name: "EXPECTED_DISK_SCHEDULER", Value: "none"})
+}
+
+func TestEmptyDirStorage(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	limit := resource.MustParse("2Gi")
+	cluster := pandaCluster()
+	cluster.Spec.Storage.Type = redpandav1alpha1.StorageTypeEmptyDir
+	cluster.Spec.Storage.EmptyDir = &redpandav1alpha1.EmptyDirStorage{
+		Medium:    corev1.StorageMediumMemory,
+		SizeLimit: &limit,
+	}
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	assert.Empty(t, actual.Spec.VolumeClaimTemplates)
+	for _, volume := range actual.Spec.Template.Spec.Volumes {
+		if volume.Name != "datadir" {
+			continue
+		}
+		if assert.NotNil(t, volume.EmptyDir) {
+			assert.Equal(t, corev1.StorageMediumMemory, volume.EmptyDir.Medium)
+			assert.Equal(t, "2Gi", volume.EmptyDir.SizeLimit.String())
+		}
+		assert.Nil(t, volume.PersistentVolumeClaim)
+		return
+	}
+	t.Error("data directory volume not found")
+}
+
+func TestMemoryLocking(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.MemoryLocking = &redpandav1alpha1.MemoryLocking{
+		LockMemory: true,
+		HugePages:  &redpandav1alpha1.HugePages{PageSize: "2Mi", Size: resource.MustParse("2Gi")},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	container := actual.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, container.Args, "--lock-memory=true")
+	assert.Contains(t, container.Args, "--hugepages=/dev/hugepages")
+	if assert.NotNil(t, container.SecurityContext) {
+		assert.Equal(t, []corev1.Capability{"IPC_LOCK"}, container.SecurityContext.Capabilities.Add)
+	}
+	hugePages := container.Resources.Limits[corev1.ResourceName("hugepages-2Mi")]
+	assert.Equal(t, "2Gi", hugePages.String())
+	hugePages = container.Resources.Requests[corev1.ResourceName("hugepages-2Mi")]
+	assert.Equal(t, "2Gi", hugePages.String())
+	// the cluster resources are not changed
+	_, ok := cluster.Spec.Resources.Limits[corev1.ResourceName("hugepages-2Mi")]
+	assert.False(t, ok)
+
+	found := false
+	for _, volume := range actual.Spec.Template.Spec.Volumes {
+		if volume.EmptyDir != nil && volume.EmptyDir.Medium == corev1.StorageMediumHugePages {
+			found = true
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestReactorTuning(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Reactor = &redpandav1alpha1.ReactorTuning{
+		Backend:      redpandav1alpha1.ReactorBackendEpoll,
+		IOProperties: "disks:\n- mountpoint: /var/lib/redpanda/data\n",
+	}
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	container := actual.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, container.Args, "--reactor-backend=epoll")
+	assert.Contains(t, container.Args, "--io-properties-file=/etc/redpanda-io/io-config.yaml")
+	assert.NotEmpty(t, actual.Spec.Template.Annotations["redpanda.vectorized.io/io-properties-hash"])
+
+	found := false
+	for _, volume := range actual.Spec.Template.Spec.Volumes {
+		if volume.ConfigMap != nil && len(volume.ConfigMap.Items) == 1 &&
+			volume.ConfigMap.Items[0].Key == res.IOPropertiesKey {
+			found = volume.ConfigMap.Name == res.ConfigMapKey(cluster).Name
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestInitContainerOrder(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Storage.FixDataDirOwnership = true
+	cluster.Spec.InitContainers = []redpandav1alpha1.InitContainer{
+		{Name: "patch-config", Image: "busybox"},
+		{Name: "tune", Image: "busybox", Position: redpandav1alpha1.InitContainerBeforeOperator},
+		{Name: "verify", Image: "busybox", Position: redpandav1alpha1.InitContainerAfterOperator},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	var names []string
+	for _, container := range actual.Spec.Template.Spec.InitContainers {
+		names = append(names, container.Name)
+	}
+	assert.Equal(t, []string{
+		"tune",
+		res.ConfiguratorContainerName,
+		"redpanda-data-dir-ownership",
+		"patch-config",
+		"verify",
+	}, names)
+}
+
+func TestClusterAutoscalerAnnotations(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Autoscaling = &redpandav1alpha1.Autoscaling{ClusterAutoscalerAnnotations: true}
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	assert.Equal(t, "false", actual.Spec.Template.Annotations["cluster-autoscaler.kubernetes.io/safe-to-evict"])
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.GracefulShutdown = &redpandav1alpha1.GracefulShutdown{DrainTimeoutSeconds: pointer.Int32Ptr(120)}
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	lifecycle := actual.Spec.Template.Spec.Containers[0].Lifecycle
+	if !assert.NotNil(t, lifecycle) {
+		return
+	}
+	assert.Contains(t, lifecycle.PreStop.Exec.Command[2], "vectorized_cluster_partition_leader")
+	assert.Contains(t, lifecycle.PreStop.Exec.Command[2], "/transfer_leadership")
+	assert.Contains(t, lifecycle.PreStop.Exec.Command[2], "-ge 120")
+	assert.NotContains(t, lifecycle.PreStop.Exec.Command[2], "decommission")
+	assert.NotContains(t, lifecycle.PreStop.Exec.Command[2], "/maintenance")
+	assert.Nil(t, lifecycle.PostStart)
+	assert.Equal(t, int64(150), *actual.Spec.Template.Spec.TerminationGracePeriodSeconds)
+}
+
+func TestPreStopHook(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.GracefulShutdown = &redpandav1alpha1.GracefulShutdown{}
+	cluster.Spec.Lifecycle = &redpandav1alpha1.BrokerLifecycle{
+		PreStop: &redpandav1alpha1.PreStopHook{
+			Command:        []string{"/usr/bin/notify", "it's stopping"},
+			Order:          redpandav1alpha1.PreStopHookBeforeDrain,
+			TimeoutSeconds: pointer.Int32Ptr(20),
+		},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	lifecycle := actual.Spec.Template.Spec.Containers[0].Lifecycle
+	if !assert.NotNil(t, lifecycle) {
+		return
+	}
+	script := lifecycle.PreStop.Exec.Command[2]
+	assert.Contains(t, script, `timeout 20 '/usr/bin/notify' 'it'\''s stopping'`)
+	// the hook runs before the drain, which is followed by the stop
+	assert.Less(t, strings.Index(script, "/usr/bin/notify"), strings.Index(script, "/transfer_leadership"))
+	assert.True(t, strings.HasSuffix(script, `log "stopping redpanda"`))
+	assert.Equal(t, int64(60+20+30), *actual.Spec.Template.Spec.TerminationGracePeriodSeconds)
+}
+
+func TestBrokerShutdown(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	tests := []struct {
+		name     string
+		shutdown redpandav1alpha1.BrokerShutdown
+		expected int64
+	}{
+		{"base timeout", redpandav1alpha1.BrokerShutdown{TimeoutSeconds: pointer.Int32Ptr(45)}, 60 + 45},
+		{"scaled with the capacity", redpandav1alpha1.BrokerShutdown{SecondsPerGiB: pointer.Int32Ptr(2)}, 60 + 30 + 2*100},
+		{"capped", redpandav1alpha1.BrokerShutdown{SecondsPerGiB: pointer.Int32Ptr(2), MaxTimeoutSeconds: pointer.Int32Ptr(120)}, 60 + 120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.Storage.Capacity = resource.MustParse("100Gi")
+			cluster.Spec.GracefulShutdown = &redpandav1alpha1.GracefulShutdown{}
+			shutdown := tt.shutdown
+			cluster.Spec.Lifecycle = &redpandav1alpha1.BrokerLifecycle{Shutdown: &shutdown}
+
+			c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+			sts := res.NewStatefulSet(
+				c,
+				cluster,
+				scheme.Scheme,
+				"cluster.local",
+				cluster.Name,
+				types.NamespacedName{Name: "test", Namespace: "test"},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				"",
+				"latest",
+				admin.NewMockAdminAPI().Factory(),
+				nil,
+				ctrl.Log.WithName("test"))
+			assert.NoError(t, sts.Ensure(context.Background()))
+
+			actual := &v1.StatefulSet{}
+			assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+			assert.Equal(t, tt.expected, *actual.Spec.Template.Spec.TerminationGracePeriodSeconds)
+		})
+	}
+}
+
+func TestExternalAdvertisedPort(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	cluster.Spec.ExternalConnectivity.AdvertisedPort = 9094
+
+	nodePort := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-external", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "admin", NodePort: 30644},
+				{Name: "kafka", NodePort: 30092},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(nodePort, headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "cluster-external", Namespace: "default"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	assert.Contains(t, actual.Spec.Template.Spec.InitContainers[0].Env,
+		corev1.EnvVar{Name: "HOST_PORT", Value: "9094"})
+}
+
+func TestAdvertisedAddressesFrom(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(2)
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	cluster.Spec.ExternalConnectivity.AdvertisedAddressesFrom = &redpandav1alpha1.AdvertisedAddressesSource{
+		ConfigMapName: "ipam",
+	}
+
+	nodePort := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-external", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "admin", NodePort: 30644},
+				{Name: "kafka", NodePort: 30092},
+			},
+		},
+	}
+	addresses := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "ipam", Namespace: "default"},
+		Data:       map[string]string{"0": "10.0.0.1"},
+	}
+	c := fake.NewClientBuilder().WithObjects(nodePort, addresses, headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "cluster-external", Namespace: "default"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+
+	// the second broker has no address
+	err := sts.Ensure(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ConfigMap ipam has no address for 1.kafka")
+	assert.True(t, apierrors.IsNotFound(c.Get(context.Background(), sts.Key(), &v1.StatefulSet{})))
+
+	addresses.Data["1.kafka"] = "10.0.0.2"
+	require.NoError(t, c.Update(context.Background(), addresses))
+	require.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	configurator := actual.Spec.Template.Spec.InitContainers[0]
+	assert.Contains(t, configurator.Env,
+		corev1.EnvVar{Name: "ADVERTISED_ADDRESSES_DIR", Value: "/etc/redpanda-addresses"})
+	assert.Contains(t, configurator.VolumeMounts,
+		corev1.VolumeMount{Name: "advertised-addresses", MountPath: "/etc/redpanda-addresses", ReadOnly: true})
+	found := false
+	for _, v := range actual.Spec.Template.Spec.Volumes {
+		if v.Name == "advertised-addresses" {
+			found = true
+			require.NotNil(t, v.ConfigMap)
+			assert.Equal(t, "ipam", v.ConfigMap.Name)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestAdminAPIBindNetwork(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.AdminAPIBindNetwork = "10.20.0.0/16"
+	cluster.Spec.Configuration.AdminAPI.Port = 9644
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	assert.Contains(t, actual.Spec.Template.Spec.InitContainers[0].Env,
+		corev1.EnvVar{Name: "ADMIN_API_BIND_NETWORK", Value: "10.20.0.0/16"})
+	probe := actual.Spec.Template.Spec.Containers[0].ReadinessProbe
+	if !assert.NotNil(t, probe.Exec) {
+		return
+	}
+	assert.Equal(t, `curl -sfk "http://$(cat /etc/redpanda/admin-address):9644/v1/config"`, probe.Exec.Command[2])
+}
+
+func TestRackAwareness(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.RackAwareness = &redpandav1alpha1.RackAwareness{Enabled: true}
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"configurator-sa",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	// the configurator reads the rack from the node of the broker
+	assert.Contains(t, actual.Spec.Template.Spec.InitContainers[0].Env,
+		corev1.EnvVar{Name: "RACK_NODE_LABEL", Value: "topology.kubernetes.io/zone"})
+	assert.Equal(t, "configurator-sa", actual.Spec.Template.Spec.ServiceAccountName)
+}
+
+func TestTCPKeepalive(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.RPCServerTuning.TCPKeepalive = &redpandav1alpha1.TCPKeepalive{
+		TimeSeconds: pointer.Int32Ptr(60),
+		Probes:      pointer.Int32Ptr(3),
+	}
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	assert.Equal(t, []corev1.Sysctl{
+		{Name: "net.ipv4.tcp_keepalive_time", Value: "60"},
+		{Name: "net.ipv4.tcp_keepalive_probes", Value: "3"},
+	}, actual.Spec.Template.Spec.SecurityContext.Sysctls)
+}
+
+func TestCPUPinning(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Resources.Limits = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("8Gi"),
+	}
+	cluster.Spec.Resources.Requests = nil
+	cluster.Spec.CPUPinning = &redpandav1alpha1.CPUPinning{Enabled: true, CPUSet: "0-3"}
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	container := actual.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, container.Args, "--smp 4")
+	assert.Contains(t, container.Args, "--cpuset 0-3")
+	assert.Equal(t, container.Resources.Limits, container.Resources.Requests)
+	assert.Equal(t, container.Resources, actual.Spec.Template.Spec.InitContainers[0].Resources)
+}
+
+func TestPerBrokerConfig(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.PerBrokerConfig = map[string]map[string]string{
+		"0": {"cloud_storage_enabled": "true"},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	assert.Contains(t, actual.Spec.Template.Spec.InitContainers[0].Env, corev1.EnvVar{
+		Name:  "PER_BROKER_CONFIG",
+		Value: `{"0":{"cloud_storage_enabled":"true"}}`,
+	})
+}
+
+// headlessService returns the headless Service that the StatefulSet of the
+// cluster is bound to
+func headlessService(pandaCluster *redpandav1alpha1.Cluster) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: pandaCluster.Namespace,
+			Name:      pandaCluster.Name,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  labels.ForCluster(pandaCluster).AsAPISelector().MatchLabels,
+		},
+	}
+}
+
 func stsFromCluster(pandaCluster *redpandav1alpha1.Cluster) *v1.StatefulSet {
 	fileSystemMode := corev1.PersistentVolumeFilesystem
 
@@ -114,7 +1677,9 @@ func stsFromCluster(pandaCluster *redpandav1alpha1.Cluster) *v1.StatefulSet {
 			Name:      pandaCluster.Name,
 		},
 		Spec: v1.StatefulSetSpec{
-			Replicas: pandaCluster.Spec.Replicas,
+			Replicas:            pandaCluster.Spec.Replicas,
+			PodManagementPolicy: v1.ParallelPodManagement,
+			ServiceName:         pandaCluster.Name,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      pandaCluster.Name,
@@ -137,7 +1702,7 @@ func stsFromCluster(pandaCluster *redpandav1alpha1.Cluster) *v1.StatefulSet {
 				{
 					ObjectMeta: metav1.ObjectMeta{
 						Namespace: pandaCluster.Namespace,
-						Name:      "dataDir",
+						Name:      "datadir",
 					},
 					Spec: corev1.PersistentVolumeClaimSpec{
 						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
@@ -181,7 +1746,7 @@ func pandaCluster() *redpandav1alpha1.Cluster {
 			Version:  "latest",
 			Replicas: pointer.Int32Ptr(replicas),
 			Configuration: redpandav1alpha1.RedpandaConfig{
-				KafkaAPI: redpandav1alpha1.SocketAddress{Port: 123},
+				KafkaAPI: redpandav1alpha1.KafkaAPI{Port: 123},
 			},
 			Resources: corev1.ResourceRequirements{
 				Limits:   resources,
@@ -194,3 +1759,124 @@ func pandaCluster() *redpandav1alpha1.Cluster {
 		},
 	}
 }
+
+func TestStrictFailureDomainSpread(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.FailureDomainSpread = redpandav1alpha1.FailureDomainSpreadStrict
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	pod := actual.Spec.Template.Spec
+	require.Len(t, pod.TopologySpreadConstraints, 1)
+	assert.Equal(t, corev1.DoNotSchedule, pod.TopologySpreadConstraints[0].WhenUnsatisfiable)
+	assert.NotEmpty(t, pod.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+}
+
+func TestDedicatedNodes(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.NodeSelector = map[string]string{"disk": "nvme"}
+	cluster.Spec.DedicatedNodes = &redpandav1alpha1.DedicatedNodes{
+		Key:    "dedicated",
+		Value:  "redpanda",
+		Effect: corev1.TaintEffectNoSchedule,
+	}
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	pod := actual.Spec.Template.Spec
+	assert.Equal(t, map[string]string{"disk": "nvme", "dedicated": "redpanda"}, pod.NodeSelector)
+	assert.Contains(t, pod.Tolerations, corev1.Toleration{
+		Key:      "dedicated",
+		Operator: corev1.TolerationOpEqual,
+		Value:    "redpanda",
+		Effect:   corev1.TaintEffectNoSchedule,
+	})
+	assert.Equal(t, map[string]string{"disk": "nvme"}, cluster.Spec.NodeSelector)
+}
+
+func TestBootstrapUser(t *testing.T) {
+	assert.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.BootstrapUser = &redpandav1alpha1.BootstrapUser{
+		SecretRef: corev1.LocalObjectReference{Name: "bootstrap-user"},
+		Mechanism: "SCRAM-SHA-512",
+	}
+	c := fake.NewClientBuilder().WithObjects(headlessService(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		cluster.Name,
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		admin.NewMockAdminAPI().Factory(),
+		nil,
+		ctrl.Log.WithName("test"))
+	assert.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	assert.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	env := map[string]corev1.EnvVar{}
+	for _, e := range actual.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e
+	}
+	require.Contains(t, env, "BOOTSTRAP_PASSWORD")
+	assert.Equal(t, "bootstrap-user", env["BOOTSTRAP_PASSWORD"].ValueFrom.SecretKeyRef.Name)
+	assert.Equal(t, "$(BOOTSTRAP_USERNAME):$(BOOTSTRAP_PASSWORD):SCRAM-SHA-512", env["RP_BOOTSTRAP_USER"].Value)
+}
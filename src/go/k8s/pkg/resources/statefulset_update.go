@@ -15,25 +15,36 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/go-logr/logr"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
-const requeueDuration = time.Second * 10
+const (
+	requeueDuration = time.Second * 10
 
-// runPartitionedUpdate handles image changes in the redpanda cluster CR by triggering
-// a rolling update (using partitions) against the statefulset underneath the CR.
-// The partitioned rolling update allows us to verify the ith pod in a custom manner
-// before proceeding to the next pod.
+	// DefaultMinReadySeconds is used when the Cluster does not specify how
+	// long a restarted broker has to be ready
+	DefaultMinReadySeconds = 10
+)
+
+// runPartitionedUpdate handles image changes in the redpanda cluster CR, and
+// changes of the configuration that Redpanda only reads on startup, by
+// triggering a rolling update (using partitions) against the statefulset
+// underneath the CR. The partitioned rolling update allows us to verify the
+// ith pod in a custom manner before proceeding to the next pod.
 //
 // The process maintains an Upgrading bool status that is set to true once the
-// CR and statefulset images differ. It is set back to false when all pods are
-// verified to be updated.
+// CR and statefulset images or configuration hashes differ. It is set back to
+// false when all pods are verified to be updated.
 //
 // The steps are as follows: 1) check the Upgrading status or if the statefulset image
 // version differs from that of the cluster CR; 2) if true, set the Upgrading status
@@ -43,6 +54,17 @@ const requeueDuration = time.Second * 10
 // verify the previously updated pod and requeue as necessary. Currently, the
 // verification checks the pod has started listening in its Kafka API port and may be
 // extended.
+//
+// When the rollback is enabled, a Pod that does not become ready on the update
+// revision within the progress deadline reverts the StatefulSet to the
+// revision the brokers ran before, see rollback.
+//
+// When the image pre-pull is enabled, the nodes of the brokers pull the new
+// image before the first broker restarts, see prePullImage.
+//
+// With the FewestLeadershipsFirst restart order the StatefulSet uses the
+// OnDelete strategy instead of partitions, and the pods are deleted in the
+// order computed from the leadership counts when the update starts.
 func (r *StatefulSetResource) runPartitionedUpdate(
 	ctx context.Context, sts *appsv1.StatefulSet,
 ) error {
@@ -61,6 +83,10 @@ func (r *StatefulSetResource) runPartitionedUpdate(
 		return err
 	}
 
+	if err := r.prePullImage(ctx, sts, newImage); err != nil {
+		return err
+	}
+
 	if err := r.partitionUpdateImage(ctx, sts, newImage); err != nil {
 		return err
 	}
@@ -79,20 +105,42 @@ func (r *StatefulSetResource) shouldUsePartitionedUpdate(
 ) (bool, error) {
 	upgrading := r.pandaCluster.Status.Upgrading
 
-	rpContainer, err := findContainer(sts.Spec.Template.Spec.Containers, redpandaContainerName)
+	rpContainer, err := findContainer(sts.Spec.Template.Spec.Containers, r.pandaCluster.RedpandaContainerName())
 	if err != nil {
 		return false, err
 	}
 
 	newImage := r.pandaCluster.FullImageName()
-	return rpContainer.Image != newImage || upgrading, nil
+	return rpContainer.Image != newImage || r.configHashChanged(sts) || r.podsOnStaleRevision(sts) || upgrading, nil
+}
+
+// podsOnStaleRevision returns true when the operator restarts the brokers
+// in leadership order and some Pods were not recreated from the current
+// template, the OnDelete strategy leaves them running otherwise
+func (r *StatefulSetResource) podsOnStaleRevision(sts *appsv1.StatefulSet) bool {
+	return r.pandaCluster.RestartsByLeadership() &&
+		sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType &&
+		sts.Status.UpdateRevision != "" && sts.Status.UpdatedReplicas < sts.Status.Replicas
+}
+
+// configHashChanged returns true when the configuration hash of the
+// ConfigMap differs from the one the Pods of the StatefulSet were created
+// with, so the brokers are restarted one at a time like on an upgrade
+func (r *StatefulSetResource) configHashChanged(sts *appsv1.StatefulSet) bool {
+	return r.nodeConfigHash != "" &&
+		sts.Spec.Template.Annotations[ConfigHashAnnotationKey] != r.nodeConfigHash
 }
 
 func (r *StatefulSetResource) updateUpgradingStatus(
 	ctx context.Context, upgrading bool,
 ) error {
-	if !reflect.DeepEqual(upgrading, r.pandaCluster.Status.Upgrading) {
+	// the restart order is computed again for the next update
+	if !reflect.DeepEqual(upgrading, r.pandaCluster.Status.Upgrading) ||
+		(!upgrading && r.pandaCluster.Status.RestartOrder != nil) {
 		r.pandaCluster.Status.Upgrading = upgrading
+		if !upgrading {
+			r.pandaCluster.Status.RestartOrder = nil
+		}
 		if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
 			return err
 		}
@@ -109,29 +157,70 @@ func (r *StatefulSetResource) partitionUpdateImage(
 	// When a StatefulSet's partition number is set to `i`, only Pods with ordinal
 	// greater than or equal to `i` will be updated.
 	// https://kubernetes.io/docs/concepts/workloads/controllers/statefulset/#partitions
-	for ordinal := replicas - 1; ordinal >= 0; ordinal-- {
+	order := descendingOrdinals(replicas)
+	if r.pandaCluster.RestartsByLeadership() {
+		var err error
+		if order, err = r.restartOrder(ctx, replicas); err != nil {
+			return err
+		}
+	}
+	for i, ordinal := range order {
 		// Update() on statefulset has not been called yet in this run, however,
 		// this could be a retry call in which case we skip the current partition.
-		poderr := r.podImageIdenticalToClusterImage(ctx, sts, newImage, ordinal)
+		poderr := r.podUpToDate(ctx, sts, newImage, ordinal)
 		if poderr == nil {
 			r.logger.Info("Pod already updated, skip", "ordinal", ordinal)
 			continue
 		}
 
 		// Continue only if error is due to Pod not ready, or unchanged image
-		// as an attempt to fix the Pod.
-		if !errors.Is(poderr, errContainerHasWrongImage) && !errors.Is(poderr, errPodNotReady) {
+		// or configuration as an attempt to fix the Pod.
+		if !errors.Is(poderr, errContainerHasWrongImage) && !errors.Is(poderr, errPodHasStaleConfig) &&
+			!errors.Is(poderr, errPodOnStaleRevision) && !errors.Is(poderr, errPodNotReady) {
 			return poderr
 		}
 
+		// A Pod restarted on the update that does not become ready is
+		// rolled back when the rollback is enabled.
+		if errors.Is(poderr, errPodNotReady) {
+			if err := r.rollbackStalledPod(ctx, sts, ordinal); err != nil {
+				return err
+			}
+		}
+
 		// Before continuing to update the ith Pod, verify that the previously updated
 		// Pod (if any) has rejoined its groups after restarting, i.e., is ready for I/O.
-		if err := r.ensureRedpandaGroupsReady(ctx, sts, replicas, ordinal+1); err != nil {
+		// The replica count stands for no previous Pod.
+		previous := replicas
+		if i > 0 {
+			previous = order[i-1]
+		}
+		if err := r.ensureRedpandaGroupsReady(ctx, sts, replicas, previous); err != nil {
 			return &RequeueAfterError{RequeueAfter: requeueDuration,
 				Msg: fmt.Sprintf("redpanda on pod (ordinal: %d) not ready", ordinal)}
 		}
 
-		if err := r.rollingUpdatePartition(ctx, ordinal, sts); err != nil {
+		if err := r.ensurePodMinReady(ctx, sts, replicas, previous); err != nil {
+			return err
+		}
+
+		if err := r.ensureRestartBudget(sts, replicas); err != nil {
+			return err
+		}
+
+		if err := r.ensureConsumerGroupsStable(ctx, sts, replicas, previous); err != nil {
+			return err
+		}
+
+		if err := r.transferControllerLeadership(ctx, replicas, ordinal, leadershipTarget(order, i)); err != nil {
+			return err
+		}
+
+		if r.pandaCluster.RestartsByLeadership() {
+			if err := r.restartPod(ctx, ordinal, sts); err != nil {
+				return err
+			}
+		} else if err := r.rollingUpdatePartition(ctx, ordinal, sts); err != nil {
 			return err
 		}
 
@@ -140,15 +229,123 @@ func (r *StatefulSetResource) partitionUpdateImage(
 			Msg: fmt.Sprintf("wait for pod (ordinal: %d) to restart", ordinal)}
 	}
 
-	// Ensure 0th Pod is ready for I/O before completing the upgrade.
-	if err := r.ensureRedpandaGroupsReady(ctx, sts, replicas, 0); err != nil {
-		return &RequeueAfterError{RequeueAfter: requeueDuration,
-			Msg: fmt.Sprintf("redpanda on pod (ordinal: %d) not ready", 0)}
+	// Ensure the last restarted Pod is ready for I/O before completing the upgrade.
+	if len(order) > 0 {
+		last := order[len(order)-1]
+		if err := r.ensureRedpandaGroupsReady(ctx, sts, replicas, last); err != nil {
+			return &RequeueAfterError{RequeueAfter: requeueDuration,
+				Msg: fmt.Sprintf("redpanda on pod (ordinal: %d) not ready", last)}
+		}
 	}
 
 	return nil
 }
 
+// descendingOrdinals returns the Pod ordinals from the highest down, the order
+// of the partitioned update
+func descendingOrdinals(replicas int32) []int32 {
+	order := make([]int32, 0, replicas)
+	for ordinal := replicas - 1; ordinal >= 0; ordinal-- {
+		order = append(order, ordinal)
+	}
+	return order
+}
+
+// restartOrder returns the Pod ordinals sorted by the number of partitions
+// their brokers lead, fewest first. The order is computed when the update
+// starts and kept in the status, so the leaderships moving with each restart
+// do not reorder the brokers left. The ordinal order is used when the Admin
+// API does not answer, an update may be what brings it back.
+func (r *StatefulSetResource) restartOrder(
+	ctx context.Context, replicas int32,
+) ([]int32, error) {
+	if order := r.pandaCluster.Status.RestartOrder; len(order) == int(replicas) {
+		return order, nil
+	}
+
+	order := descendingOrdinals(replicas)
+	leaderships := make(map[int32]int, replicas)
+	adminAPI, err := r.adminAPIClientFactory(ctx, r, r.pandaCluster, r.serviceFQDN, r.adminTLSProvider)
+	if err == nil {
+		var partitions []admin.PartitionSummary
+		partitions, err = adminAPI.ListPartitions(ctx)
+		for _, p := range partitions {
+			leaderships[int32(p.Leader)]++
+		}
+	}
+	if admin.IsUnsupported(err) {
+		r.unsupported = append(r.unsupported, fmt.Errorf("broker restart order: %w", err))
+	}
+	if err != nil {
+		r.logger.Info("Unable to count the leaderships of the brokers, restarting in ordinal order", "error", err)
+	} else {
+		// ties keep the ordinal order
+		sort.SliceStable(order, func(i, j int) bool {
+			return leaderships[order[i]] < leaderships[order[j]]
+		})
+	}
+	r.logger.Info("Computed the broker restart order", "order", order, "leaderships", leaderships)
+
+	r.pandaCluster.Status.RestartOrder = order
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return nil, fmt.Errorf("unable to record the restart order: %w", err)
+	}
+	return order, nil
+}
+
+// leadershipTarget returns the broker that takes over the controller
+// leadership from the ith broker of the order, the broker restarted before
+// it or, for the first one, the broker restarted after it
+func leadershipTarget(order []int32, i int) int32 {
+	if i > 0 {
+		return order[i-1]
+	}
+	if len(order) > 1 {
+		return order[1]
+	}
+	return order[0]
+}
+
+// transferControllerLeadership moves the controller leadership away from the
+// broker that is restarted next, so the cluster metadata stays available
+// while it is down. The restart order is fixed when the update starts, so
+// the controller leader cannot simply be restarted last. The leadership goes
+// to the target, a broker that was already restarted when there is one. The
+// update requeues after the transfer and continues once the leadership
+// moved.
+func (r *StatefulSetResource) transferControllerLeadership(
+	ctx context.Context, replicas, ordinal, target int32,
+) error {
+	if replicas < 2 {
+		return nil
+	}
+
+	adminAPI, err := r.adminAPIClientFactory(ctx, r, r.pandaCluster, r.serviceFQDN, r.adminTLSProvider)
+	if err != nil {
+		return fmt.Errorf("unable to create admin API client: %w", err)
+	}
+	health, err := adminAPI.ClusterHealth(ctx)
+	if admin.IsUnsupported(err) {
+		r.unsupported = append(r.unsupported, fmt.Errorf("controller leadership transfer: %w", err))
+	}
+	if err != nil {
+		// Redpanda elects a new controller leader when the broker goes down
+		r.logger.Info("Unable to find the controller leader, restarting without transferring", "error", err)
+		return nil
+	}
+	if health.ControllerID != int(ordinal) {
+		return nil
+	}
+
+	r.logger.Info("Transferring controller leadership before the restart", "from", ordinal, "to", target)
+	if err := adminAPI.TransferLeadership(ctx, int(ordinal), admin.ControllerRaftGroup, int(target)); err != nil {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("unable to transfer controller leadership from broker %d: %v", ordinal, err)}
+	}
+	return &RequeueAfterError{RequeueAfter: requeueDuration,
+		Msg: fmt.Sprintf("wait for controller leadership to move away from broker %d", ordinal)}
+}
+
 // Ensures the Redpanda pod has rejoined its groups after restarting,
 // i.e., is ready for I/O.
 func (r *StatefulSetResource) ensureRedpandaGroupsReady(
@@ -166,6 +363,157 @@ func (r *StatefulSetResource) ensureRedpandaGroupsReady(
 	return r.queryRedpandaForTopicMembers(ctx, addresses, r.logger)
 }
 
+// ensurePodMinReady requeues until the Pod has been ready for at least
+// MinReadySeconds. The StatefulSet minReadySeconds field is not available in
+// the supported Kubernetes API version, so the partitioned update enforces it.
+func (r *StatefulSetResource) ensurePodMinReady(
+	ctx context.Context, sts *appsv1.StatefulSet, replicas, ordinal int32,
+) error {
+	if replicas == 0 || ordinal == replicas {
+		return nil
+	}
+
+	minReady := time.Duration(r.minReadySeconds()) * time.Second
+	if minReady == 0 {
+		return nil
+	}
+
+	podName := fmt.Sprintf("%s-%d", sts.Name, ordinal)
+	var pod corev1.Pod
+	if err := r.Get(ctx, types.NamespacedName{Name: podName, Namespace: sts.Namespace}, &pod); err != nil {
+		return err
+	}
+
+	readySince, ready := podReadySince(&pod)
+	if !ready {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("pod (ordinal: %d) not ready", ordinal)}
+	}
+	if remaining := minReady - time.Since(readySince); remaining > 0 {
+		return &RequeueAfterError{RequeueAfter: remaining,
+			Msg: fmt.Sprintf("pod (ordinal: %d) not ready for %s yet", ordinal, minReady)}
+	}
+
+	return nil
+}
+
+// ensureRestartBudget requeues while the brokers that are not ready use up
+// the disruption budget, when the cluster waits for ready brokers
+func (r *StatefulSetResource) ensureRestartBudget(
+	sts *appsv1.StatefulSet, replicas int32,
+) error {
+	rollout := r.pandaCluster.Spec.RolloutEndpoints
+	if rollout == nil || !rollout.WaitForReadyBrokers {
+		return nil
+	}
+	var budget *int32
+	if r.pandaCluster.Spec.GracefulShutdown != nil {
+		budget = r.pandaCluster.Spec.GracefulShutdown.MaxUnavailable
+	}
+	maxUnavailable := int32OrDefault(budget, defaultMaxUnavailable)
+	if unavailable := replicas - sts.Status.ReadyReplicas; unavailable >= maxUnavailable {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("%d brokers not ready, waiting before the next restart", unavailable)}
+	}
+	return nil
+}
+
+// rebalancingStates are the states of a consumer group between a change of
+// its members and their new assignment
+var rebalancingStates = map[string]bool{
+	"PreparingRebalance":  true,
+	"CompletingRebalance": true,
+}
+
+// rebalancingGroups returns the names of the rebalancing consumer groups
+func rebalancingGroups(groups []*sarama.GroupDescription) []string {
+	var names []string
+	for _, g := range groups {
+		if rebalancingStates[g.State] {
+			names = append(names, g.GroupId)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ensureConsumerGroupsStable requeues, when the restarts are coordinated with
+// the consumer groups, until the groups had the stabilization time since the
+// previous broker became ready and at most MaxRebalancingGroups of them are
+// rebalancing. The restart is not delayed when the groups can not be
+// described.
+func (r *StatefulSetResource) ensureConsumerGroupsStable(
+	ctx context.Context, sts *appsv1.StatefulSet, replicas, previous int32,
+) error {
+	coordination := r.pandaCluster.Spec.ConsumerGroupCoordination
+	if coordination == nil || replicas == 0 {
+		return nil
+	}
+
+	if previous != replicas {
+		podName := fmt.Sprintf("%s-%d", sts.Name, previous)
+		var pod corev1.Pod
+		if err := r.Get(ctx, types.NamespacedName{Name: podName, Namespace: sts.Namespace}, &pod); err != nil {
+			return err
+		}
+		readySince, _ := podReadySince(&pod)
+		if remaining := r.pandaCluster.ConsumerGroupStabilization() - time.Since(readySince); remaining > 0 {
+			return &RequeueAfterError{RequeueAfter: remaining,
+				Msg: fmt.Sprintf("consumer groups stabilizing after the restart of pod (ordinal: %d)", previous)}
+		}
+	}
+
+	addresses := make([]string, 0, replicas)
+	for ordinal := int32(0); ordinal < replicas; ordinal++ {
+		addresses = append(addresses, fmt.Sprintf("%s-%d.%s:%d", sts.Name, ordinal, r.serviceFQDN,
+			r.pandaCluster.Spec.Configuration.KafkaAPI.Port))
+	}
+	conf, err := r.kafkaClientConfig(ctx)
+	if err != nil {
+		return err
+	}
+	groups, err := describeConsumerGroups(addresses, conf)
+	if err != nil {
+		r.logger.Info("Unable to describe the consumer groups, restarting without waiting for them", "error", err)
+		return nil
+	}
+
+	rebalancing := rebalancingGroups(groups)
+	if allowed := int32OrDefault(coordination.MaxRebalancingGroups, 0); int32(len(rebalancing)) > allowed {
+		r.logger.Info("Consumer groups rebalancing, waiting before the next restart", "groups", rebalancing)
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("%d consumer groups rebalancing, waiting before the next restart", len(rebalancing))}
+	}
+	return nil
+}
+
+// describeConsumerGroups returns the descriptions of every consumer group
+func describeConsumerGroups(
+	addresses []string, conf *sarama.Config,
+) ([]*sarama.GroupDescription, error) {
+	clusterAdmin, err := sarama.NewClusterAdmin(addresses, conf)
+	if err != nil {
+		return nil, err
+	}
+	defer clusterAdmin.Close() // nolint:errcheck // the descriptions are read
+	groups, err := clusterAdmin.ListConsumerGroups()
+	if err != nil || len(groups) == 0 {
+		return nil, err
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	return clusterAdmin.DescribeConsumerGroups(names)
+}
+
+func (r *StatefulSetResource) minReadySeconds() int32 {
+	if r.pandaCluster.Spec.MinReadySeconds == nil {
+		return DefaultMinReadySeconds
+	}
+	return *r.pandaCluster.Spec.MinReadySeconds
+}
+
 // Used as a temporary indicator that Redpanda is ready until a health
 // endpoint is introduced or logic is added here that goes through all topics
 // metadata.
@@ -174,35 +522,47 @@ func (r *StatefulSetResource) queryRedpandaForTopicMembers(
 ) error {
 	logger.Info("Connect to Redpanda broker", "broker", addresses)
 
+	conf, err := r.kafkaClientConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	consumer, err := sarama.NewConsumer(addresses, conf)
+	if err != nil {
+		logger.Error(err, "Error while creating consumer")
+		return err
+	}
+
+	return consumer.Close()
+}
+
+// kafkaClientConfig returns the configuration of the Kafka clients of the
+// operator, which connect to the internal listener
+func (r *StatefulSetResource) kafkaClientConfig(
+	ctx context.Context,
+) (*sarama.Config, error) {
 	conf := sarama.NewConfig()
 	conf.Version = sarama.V2_4_0_0
 	conf.ClientID = "operator"
 	conf.Admin.Timeout = time.Second
 
-	// TODO right now we support TLS only on one listener so if external
-	// connectivity is enabled, TLS is enabled only on external listener. This
-	// will be fixed by https://github.com/vectorizedio/redpanda/issues/1084
-	if !r.pandaCluster.Spec.ExternalConnectivity.Enabled && r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.Enabled {
+	// Unless the external listener has independent settings, TLS is
+	// enabled only on the external listener when external connectivity is
+	// enabled
+	if r.pandaCluster.InternalKafkaTLS() {
 		tlsConfig := tls.Config{MinVersion: tls.VersionTLS12} // TLS12 is min version allowed by gosec.
 		// For simplicity, we skip broker verification until per-listener
 		// TLS is available in Redpanda. This client calls the internal listener.
 		tlsConfig.InsecureSkipVerify = true
 
 		if err := r.populateTLSConfigCert(ctx, &tlsConfig); err != nil {
-			return err
+			return nil, err
 		}
 
 		conf.Net.TLS.Enable = true
 		conf.Net.TLS.Config = &tlsConfig
 	}
-
-	consumer, err := sarama.NewConsumer(addresses, conf)
-	if err != nil {
-		logger.Error(err, "Error while creating consumer")
-		return err
-	}
-
-	return consumer.Close()
+	return conf, nil
 }
 
 // Populates crypto/TLS configuration for certificate used by the operator
@@ -230,17 +590,23 @@ func (r *StatefulSetResource) populateTLSConfigCert(
 	return nil
 }
 
-func (r *StatefulSetResource) podImageIdenticalToClusterImage(
+// podUpToDate returns nil when the Pod runs the cluster image with the
+// current configuration hash and is ready
+func (r *StatefulSetResource) podUpToDate(
 	ctx context.Context, sts *appsv1.StatefulSet, newImage string, ordinal int32,
 ) error {
 	podName := fmt.Sprintf("%s-%d", sts.Name, ordinal)
 
 	var pod corev1.Pod
-	if err := r.Get(ctx, types.NamespacedName{Name: podName, Namespace: sts.Namespace}, &pod); err != nil {
+	if err := r.Get(ctx, types.NamespacedName{Name: podName, Namespace: sts.Namespace}, &pod); apierrors.IsNotFound(err) {
+		// a deleted Pod is not ready until the StatefulSet controller recreates it
+		r.logger.Info("Pod not recreated yet", "pod", podName)
+		return podNotReadyError(podName)
+	} else if err != nil {
 		return err
 	}
 
-	container, err := findContainer(pod.Spec.Containers, redpandaContainerName)
+	container, err := findContainer(pod.Spec.Containers, r.pandaCluster.RedpandaContainerName())
 	if err != nil {
 		return err
 	}
@@ -251,6 +617,20 @@ func (r *StatefulSetResource) podImageIdenticalToClusterImage(
 		return containerHasWrongImageError(podName, container.Name, container.Image, newImage)
 	}
 
+	if hash := pod.Annotations[ConfigHashAnnotationKey]; r.nodeConfigHash != "" && hash != r.nodeConfigHash {
+		r.logger.Info("Pod not restarted with the current configuration", "pod", pod.Name,
+			"pod configuration hash", hash, "configuration hash", r.nodeConfigHash)
+		return podHasStaleConfigError(podName, hash, r.nodeConfigHash)
+	}
+
+	// the OnDelete strategy leaves Pods of any other template change running
+	if revision := pod.Labels[appsv1.ControllerRevisionHashLabelKey]; r.pandaCluster.RestartsByLeadership() &&
+		sts.Status.UpdateRevision != "" && revision != sts.Status.UpdateRevision {
+		r.logger.Info("Pod not recreated from the current template", "pod", pod.Name,
+			"pod revision", revision, "revision", sts.Status.UpdateRevision)
+		return podOnStaleRevisionError(podName, revision, sts.Status.UpdateRevision)
+	}
+
 	if !podIsReady(&pod) {
 		r.logger.Info("Pod not ready yet", "pod", pod.Name)
 		return podNotReadyError(pod.Name)
@@ -282,10 +662,47 @@ func (r *StatefulSetResource) rollingUpdatePartition(
 	return nil
 }
 
+// restartPod applies the template to the StatefulSet and deletes the Pod, so
+// the StatefulSet controller recreates it with the OnDelete strategy. The Pod
+// is only deleted once the controller observed the template, it would be
+// recreated from the previous one otherwise.
+func (r *StatefulSetResource) restartPod(
+	ctx context.Context, ordinal int32, sts *appsv1.StatefulSet,
+) error {
+	r.logger.Info("Restarting pod of statefulset", "ordinal", ordinal)
+
+	modified, err := r.obj()
+	if err != nil {
+		return err
+	}
+	if err := Update(ctx, sts, modified, r.Client, r.logger); err != nil {
+		return fmt.Errorf("failed to update StatefulSet (ordinal %d): %w", ordinal, err)
+	}
+
+	var current appsv1.StatefulSet
+	if err := r.Get(ctx, r.Key(), &current); err != nil {
+		return fmt.Errorf("error while fetching StatefulSet resource: %w", err)
+	}
+	if current.Status.ObservedGeneration < current.Generation {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("wait for the StatefulSet controller before restarting pod (ordinal: %d)", ordinal)}
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      fmt.Sprintf("%s-%d", sts.Name, ordinal),
+		Namespace: sts.Namespace,
+	}}
+	if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete pod (ordinal %d): %w", ordinal, err)
+	}
+
+	return nil
+}
+
 func (r *StatefulSetResource) modifyPodImage(
 	stsSpec *corev1.PodSpec, newImage string,
 ) error {
-	return modifyContainerImage(stsSpec.Containers, redpandaContainerName, newImage)
+	return modifyContainerImage(stsSpec.Containers, r.pandaCluster.RedpandaContainerName(), newImage)
 }
 
 func modifyContainerImage(
@@ -324,6 +741,18 @@ func podIsReady(pod *corev1.Pod) bool {
 	return false
 }
 
+// podReadySince returns when the Pod became ready
+func podReadySince(pod *corev1.Pod) (time.Time, bool) {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady &&
+			c.Status == corev1.ConditionTrue {
+			return c.LastTransitionTime.Time, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
 // RequeueAfterError error carrying the time after which to requeue.
 type RequeueAfterError struct {
 	RequeueAfter time.Duration
@@ -343,6 +772,20 @@ func containerHasWrongImageError(
 		errContainerHasWrongImage, podName, containerName, currentImage, expectedImage)
 }
 
+var errPodHasStaleConfig = errors.New("pod has stale configuration")
+
+func podHasStaleConfigError(podName, currentHash, expectedHash string) error {
+	return fmt.Errorf("podHasStaleConfig %w : pod: %s; configuration hash: %s; expected: %s",
+		errPodHasStaleConfig, podName, currentHash, expectedHash)
+}
+
+var errPodOnStaleRevision = errors.New("pod has stale revision")
+
+func podOnStaleRevisionError(podName, currentRevision, expectedRevision string) error {
+	return fmt.Errorf("podOnStaleRevision %w : pod: %s; revision: %s; expected: %s",
+		errPodOnStaleRevision, podName, currentRevision, expectedRevision)
+}
+
 var errContainerNotFound = errors.New("container not found")
 
 func containerNotFoundError(container string) error {
@@ -0,0 +1,277 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// BootstrapSuperuserName is the SASL superuser created by the operator
+	// when Kafka clients authenticate with SASL
+	BootstrapSuperuserName = "admin"
+	// SuperuserSecretUsernameKey is the Secret key holding the user name
+	SuperuserSecretUsernameKey = "username"
+	// SuperuserSecretPasswordKey is the Secret key holding the password
+	SuperuserSecretPasswordKey = "password"
+
+	// superuserSecretPendingPasswordKey holds the password a rotation in
+	// progress sets
+	superuserSecretPendingPasswordKey = "pending-password"
+	superuserPasswordBytes            = 24
+	superuserSASLMechanism            = "SCRAM-SHA-256"
+)
+
+var _ AdminAPIReconciler = &SuperuserReconciler{}
+
+// SuperuserReconciler bootstraps SASL authentication. It generates the
+// password of the bootstrap superuser once, keeps it in a Secret and creates
+// the user through the Admin API when the brokers are running. The name of
+// the Secret is reported in the Cluster status after the user is created. The
+// password is rotated on the configured interval.
+type SuperuserReconciler struct {
+	k8sclient.Client
+	scheme                *runtime.Scheme
+	pandaCluster          *redpandav1alpha1.Cluster
+	adminAPIClientFactory admin.AdminAPIClientFactory
+	serviceFQDN           string
+	adminTLSProvider      admin.AdminTLSConfigProvider
+	logger                logr.Logger
+}
+
+// NewSuperuser creates SuperuserReconciler
+func NewSuperuser(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	adminAPIClientFactory admin.AdminAPIClientFactory,
+	serviceFQDN string,
+	adminTLSProvider admin.AdminTLSConfigProvider,
+	logger logr.Logger,
+) *SuperuserReconciler {
+	return &SuperuserReconciler{
+		client,
+		scheme,
+		pandaCluster,
+		adminAPIClientFactory,
+		serviceFQDN,
+		adminTLSProvider,
+		logger.WithValues("Reconciler", "superuser"),
+	}
+}
+
+// RequiresAdminAPI implements AdminAPIReconciler
+func (r *SuperuserReconciler) RequiresAdminAPI() {}
+
+// Ensure creates the superuser Secret and the SASL user, and rotates its
+// password
+func (r *SuperuserReconciler) Ensure(ctx context.Context) error {
+	if !r.pandaCluster.BootstrapSuperuserRequired() {
+		return nil
+	}
+
+	secret, err := r.ensureSecret(ctx)
+	if err != nil {
+		return err
+	}
+
+	// brokers have to be running to create the user
+	if r.pandaCluster.Status.Replicas == 0 {
+		return nil
+	}
+	if r.pandaCluster.Status.SuperuserSecret == r.Key().Name {
+		return r.rotatePassword(ctx, secret)
+	}
+	password := string(secret.Data[SuperuserSecretPasswordKey])
+
+	adminAPI, err := r.adminAPIClientFactory(ctx, r, r.pandaCluster, r.serviceFQDN, r.adminTLSProvider)
+	if err != nil {
+		return fmt.Errorf("unable to create admin API client: %w", err)
+	}
+
+	users, err := adminAPI.ListUsers(ctx)
+	if err != nil {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("unable to list SASL users: %v", err)}
+	}
+	if userExists(users, BootstrapSuperuserName) {
+		r.logger.Info("Superuser already exists, its password is not changed", "user", BootstrapSuperuserName)
+	} else {
+		r.logger.Info("Creating superuser", "user", BootstrapSuperuserName, "secret", r.Key().Name)
+		if err := adminAPI.CreateUser(ctx, BootstrapSuperuserName, password, superuserSASLMechanism); err != nil {
+			return &RequeueAfterError{RequeueAfter: requeueDuration,
+				Msg: fmt.Sprintf("unable to create superuser: %v", err)}
+		}
+	}
+
+	now := metav1.Now()
+	r.pandaCluster.Status.SuperuserSecret = r.Key().Name
+	r.pandaCluster.Status.SuperuserPasswordRotated = &now
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return fmt.Errorf("unable to update superuser secret status: %w", err)
+	}
+	return nil
+}
+
+// rotatePassword replaces the password of the superuser once the rotation
+// interval elapsed. The new password is stored in the Secret before the user
+// is changed, so a rotation interrupted in between is completed with the
+// same password. Redpanda keeps one password per SCRAM user, the previous
+// one stops working as soon as the user is changed.
+func (r *SuperuserReconciler) rotatePassword(ctx context.Context, secret *corev1.Secret) error {
+	if r.pandaCluster.Spec.Configuration.SuperuserPasswordRotation == nil {
+		return nil
+	}
+	if r.pandaCluster.Status.SuperuserPasswordRotated == nil {
+		// users created before the rotation was enabled
+		created := secret.CreationTimestamp
+		r.pandaCluster.Status.SuperuserPasswordRotated = &created
+		if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+			return fmt.Errorf("unable to update superuser password rotation status: %w", err)
+		}
+	}
+
+	pending, inProgress := secret.Data[superuserSecretPendingPasswordKey]
+	if !inProgress {
+		next, _ := r.pandaCluster.NextSuperuserPasswordRotation()
+		if time.Now().Before(next) {
+			return nil
+		}
+		password, err := generatePassword()
+		if err != nil {
+			return err
+		}
+		pending = []byte(password)
+		secret.Data[superuserSecretPendingPasswordKey] = pending
+		if err := r.Update(ctx, secret); err != nil {
+			return fmt.Errorf("unable to store the pending superuser password: %w", err)
+		}
+	}
+
+	adminAPI, err := r.adminAPIClientFactory(ctx, r, r.pandaCluster, r.serviceFQDN, r.adminTLSProvider)
+	if err != nil {
+		return fmt.Errorf("unable to create admin API client: %w", err)
+	}
+	err = adminAPI.UpdateUser(ctx, BootstrapSuperuserName, string(pending), superuserSASLMechanism)
+	switch {
+	case admin.IsUnauthorized(err):
+		// the operator authenticates with the superuser on the Admin API,
+		// the password it read from the Secret was replaced before the
+		// rotation was interrupted
+		r.logger.Info("Superuser password already replaced, completing the rotation", "user", BootstrapSuperuserName)
+	case err != nil:
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("unable to rotate the superuser password: %v", err)}
+	}
+
+	secret.Data[SuperuserSecretPasswordKey] = pending
+	delete(secret.Data, superuserSecretPendingPasswordKey)
+	if err := r.Update(ctx, secret); err != nil {
+		return fmt.Errorf("unable to store the rotated superuser password: %w", err)
+	}
+	now := metav1.Now()
+	r.pandaCluster.Status.SuperuserPasswordRotated = &now
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return fmt.Errorf("unable to update superuser password rotation status: %w", err)
+	}
+	r.logger.Info("Superuser password rotated", "user", BootstrapSuperuserName, "secret", r.Key().Name)
+	return nil
+}
+
+// ensureSecret returns the Secret holding the password and creates it with
+// a new password when it does not exist. The Secret is created without the
+// last applied annotation, which would expose the password.
+func (r *SuperuserReconciler) ensureSecret(ctx context.Context) (*corev1.Secret, error) {
+	var secret corev1.Secret
+	err := r.Get(ctx, r.Key(), &secret)
+	if err == nil {
+		return &secret, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to retrieve superuser secret: %w", err)
+	}
+
+	password, err := generatePassword()
+	if err != nil {
+		return nil, err
+	}
+	obj, err := r.obj(password)
+	if err != nil {
+		return nil, fmt.Errorf("unable to construct superuser secret: %w", err)
+	}
+	if err := r.Create(ctx, obj); err != nil {
+		return nil, fmt.Errorf("unable to create superuser secret: %w", err)
+	}
+	r.logger.Info("Superuser secret created", "secret", r.Key().Name)
+	return obj, nil
+}
+
+func (r *SuperuserReconciler) obj(password string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.Key().Name,
+			Namespace: r.Key().Namespace,
+			Labels:    labels.ForCluster(r.pandaCluster),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			SuperuserSecretUsernameKey: []byte(BootstrapSuperuserName),
+			SuperuserSecretPasswordKey: []byte(password),
+		},
+	}
+
+	err := SetOwner(r.pandaCluster, secret, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *SuperuserReconciler) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.SuperuserSecretName(), Namespace: r.pandaCluster.Namespace}
+}
+
+func userExists(users []string, username string) bool {
+	for _, u := range users {
+		if u == username {
+			return true
+		}
+	}
+	return false
+}
+
+func generatePassword() (string, error) {
+	b := make([]byte, superuserPasswordBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("unable to generate superuser password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSuperuserEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.EnableSASL = true
+
+	adminAPI := admin.NewMockAdminAPI()
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	superuser := res.NewSuperuser(c, cluster, scheme.Scheme, adminAPI.Factory(), "cluster.local", nil, ctrl.Log)
+
+	t.Run("secret is created before the brokers run", func(t *testing.T) {
+		require.NoError(t, superuser.Ensure(context.Background()))
+		assert.Empty(t, adminAPI.Users)
+		assert.Empty(t, cluster.Status.SuperuserSecret)
+	})
+
+	var secret corev1.Secret
+	require.NoError(t, c.Get(context.Background(), superuser.Key(), &secret))
+	password := string(secret.Data[res.SuperuserSecretPasswordKey])
+	require.NotEmpty(t, password)
+
+	t.Run("user is created with the stored password", func(t *testing.T) {
+		cluster.Status.Replicas = 1
+		require.NoError(t, superuser.Ensure(context.Background()))
+		assert.Equal(t, password, adminAPI.Users[res.BootstrapSuperuserName])
+		assert.Equal(t, superuser.Key().Name, cluster.Status.SuperuserSecret)
+	})
+
+	t.Run("secret is reused", func(t *testing.T) {
+		cluster.Status.SuperuserSecret = ""
+		require.NoError(t, superuser.Ensure(context.Background()))
+		require.NoError(t, c.Get(context.Background(), superuser.Key(), &secret))
+		assert.Equal(t, password, string(secret.Data[res.SuperuserSecretPasswordKey]))
+	})
+
+	t.Run("password is rotated once the interval elapsed", func(t *testing.T) {
+		cluster.Spec.Configuration.SuperuserPasswordRotation = &redpandav1alpha1.PasswordRotation{
+			Interval: metav1.Duration{Duration: 24 * time.Hour},
+		}
+		rotated := metav1.NewTime(time.Now().Add(-time.Hour))
+		cluster.Status.SuperuserPasswordRotated = &rotated
+		require.NoError(t, superuser.Ensure(context.Background()))
+		require.NoError(t, c.Get(context.Background(), superuser.Key(), &secret))
+		assert.Equal(t, password, string(secret.Data[res.SuperuserSecretPasswordKey]))
+
+		rotated = metav1.NewTime(time.Now().Add(-25 * time.Hour))
+		cluster.Status.SuperuserPasswordRotated = &rotated
+		require.NoError(t, superuser.Ensure(context.Background()))
+		require.NoError(t, c.Get(context.Background(), superuser.Key(), &secret))
+		rotatedPassword := string(secret.Data[res.SuperuserSecretPasswordKey])
+		assert.NotEqual(t, password, rotatedPassword)
+		assert.Equal(t, rotatedPassword, adminAPI.Users[res.BootstrapSuperuserName])
+		assert.NotContains(t, secret.Data, "pending-password")
+		assert.True(t, cluster.Status.SuperuserPasswordRotated.After(rotated.Time))
+	})
+
+	t.Run("interrupted rotation is completed with the pending password", func(t *testing.T) {
+		secret.Data["pending-password"] = []byte("pending")
+		require.NoError(t, c.Update(context.Background(), &secret))
+		require.NoError(t, superuser.Ensure(context.Background()))
+		require.NoError(t, c.Get(context.Background(), superuser.Key(), &secret))
+		assert.Equal(t, "pending", string(secret.Data[res.SuperuserSecretPasswordKey]))
+		assert.Equal(t, "pending", adminAPI.Users[res.BootstrapSuperuserName])
+		assert.NotContains(t, secret.Data, "pending-password")
+	})
+}
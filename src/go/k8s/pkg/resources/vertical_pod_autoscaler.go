@@ -0,0 +1,140 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// VerticalPodAutoscalerGVK is the kind of the VerticalPodAutoscaler. Its
+// types are not vendored, the object is handled as unstructured.
+var VerticalPodAutoscalerGVK = schema.GroupVersionKind{
+	Group:   "autoscaling.k8s.io",
+	Version: "v1",
+	Kind:    "VerticalPodAutoscaler",
+}
+
+var _ Reconciler = &VerticalPodAutoscalerResource{}
+
+// VerticalPodAutoscalerResource is part of the reconciliation of redpanda.vectorized.io CRD
+// reporting resource recommendations for the brokers without resizing them
+type VerticalPodAutoscalerResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	logger       logr.Logger
+}
+
+// NewVerticalPodAutoscaler creates VerticalPodAutoscalerResource
+func NewVerticalPodAutoscaler(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	logger logr.Logger,
+) *VerticalPodAutoscalerResource {
+	return &VerticalPodAutoscalerResource{
+		client,
+		scheme,
+		pandaCluster,
+		logger.WithValues("Kind", VerticalPodAutoscalerGVK.Kind),
+	}
+}
+
+// Ensure creates or updates the VerticalPodAutoscaler of the brokers when it
+// is enabled and deletes it otherwise
+func (r *VerticalPodAutoscalerResource) Ensure(ctx context.Context) error {
+	autoscaling := r.pandaCluster.Spec.Autoscaling
+	if autoscaling == nil || !autoscaling.VerticalPodAutoscaler {
+		return r.deleteIfExists(ctx)
+	}
+
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	vpa := newVerticalPodAutoscaler()
+	if err := r.Get(ctx, r.Key(), vpa); err != nil {
+		return fmt.Errorf("error while fetching VerticalPodAutoscaler resource: %w", err)
+	}
+	return Update(ctx, vpa, obj, r.Client, r.logger)
+}
+
+func (r *VerticalPodAutoscalerResource) deleteIfExists(ctx context.Context) error {
+	vpa := newVerticalPodAutoscaler()
+	err := r.Get(ctx, r.Key(), vpa)
+	// Without the VerticalPodAutoscaler CRD there is nothing to delete
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching VerticalPodAutoscaler resource: %w", err)
+	}
+	if !IsManagedBy(r.pandaCluster, vpa) {
+		return nil
+	}
+	r.logger.Info("Deleting the disabled VerticalPodAutoscaler")
+	if err := r.Delete(ctx, vpa); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete VerticalPodAutoscaler: %w", err)
+	}
+	return nil
+}
+
+// obj returns resource managed client.Object
+func (r *VerticalPodAutoscalerResource) obj() (k8sclient.Object, error) {
+	vpa := newVerticalPodAutoscaler()
+	vpa.SetNamespace(r.Key().Namespace)
+	vpa.SetName(r.Key().Name)
+	vpa.SetLabels(labels.ForCluster(r.pandaCluster))
+	vpa.Object["spec"] = map[string]interface{}{
+		"targetRef": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "StatefulSet",
+			"name":       r.pandaCluster.Name,
+		},
+		"updatePolicy": map[string]interface{}{
+			"updateMode": "Off",
+		},
+	}
+
+	err := SetOwner(r.pandaCluster, vpa, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return vpa, nil
+}
+
+func newVerticalPodAutoscaler() *unstructured.Unstructured {
+	vpa := &unstructured.Unstructured{}
+	vpa.SetGroupVersionKind(VerticalPodAutoscalerGVK)
+	return vpa
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *VerticalPodAutoscalerResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name, Namespace: r.pandaCluster.Namespace}
+}
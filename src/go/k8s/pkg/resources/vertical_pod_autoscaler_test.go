@@ -0,0 +1,51 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestVerticalPodAutoscalerEnsure(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Autoscaling = &redpandav1alpha1.Autoscaling{VerticalPodAutoscaler: true}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster).Build()
+	vpa := res.NewVerticalPodAutoscaler(c, cluster, scheme.Scheme, ctrl.Log)
+	require.NoError(t, vpa.Ensure(context.Background()))
+
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(res.VerticalPodAutoscalerGVK)
+	require.NoError(t, c.Get(context.Background(), vpa.Key(), actual))
+	mode, _, err := unstructured.NestedString(actual.Object, "spec", "updatePolicy", "updateMode")
+	require.NoError(t, err)
+	assert.Equal(t, "Off", mode)
+	target, _, err := unstructured.NestedString(actual.Object, "spec", "targetRef", "name")
+	require.NoError(t, err)
+	assert.Equal(t, "cluster", target)
+
+	cluster.Spec.Autoscaling.VerticalPodAutoscaler = false
+	require.NoError(t, vpa.Ensure(context.Background()))
+	err = c.Get(context.Background(), vpa.Key(), actual)
+	assert.True(t, apierrors.IsNotFound(err))
+}
@@ -872,21 +872,28 @@ rpk:
 			conf: func() *Config {
 				c := getValidConfig()
 				c.Pandaproxy = &Pandaproxy{
-					PandaproxyAPI: &SocketAddress{
-						Address: "1.2.3.4",
-						Port:    1234,
-					},
-					PandaproxyAPITLS: &ServerTLS{
+					PandaproxyAPI: []NamedSocketAddress{{
+						SocketAddress: SocketAddress{
+							Address: "1.2.3.4",
+							Port:    1234,
+						},
+						Name: "first",
+					}},
+					PandaproxyAPITLS: []ServerTLS{{
+						Name:              "first",
 						KeyFile:           "/etc/certs/cert.key",
 						TruststoreFile:    "/etc/certs/ca.crt",
 						CertFile:          "/etc/certs/cert.crt",
 						Enabled:           true,
 						RequireClientAuth: true,
-					},
-					AdvertisedPandaproxyAPI: &SocketAddress{
-						Address: "2.3.4.1",
-						Port:    2341,
-					},
+					}},
+					AdvertisedPandaproxyAPI: []NamedSocketAddress{{
+						SocketAddress: SocketAddress{
+							Address: "2.3.4.1",
+							Port:    2341,
+						},
+						Name: "first",
+					}},
 				}
 				return c
 			},
@@ -894,15 +901,18 @@ rpk:
 			expected: `config_file: /etc/redpanda/redpanda.yaml
 pandaproxy:
   advertised_pandaproxy_api:
-    address: 2.3.4.1
+  - address: 2.3.4.1
+    name: first
     port: 2341
   pandaproxy_api:
-    address: 1.2.3.4
+  - address: 1.2.3.4
+    name: first
     port: 1234
   pandaproxy_api_tls:
-    cert_file: /etc/certs/cert.crt
+  - cert_file: /etc/certs/cert.crt
     enabled: true
     key_file: /etc/certs/cert.key
+    name: first
     require_client_auth: true
     truststore_file: /etc/certs/ca.crt
 redpanda:
@@ -950,10 +960,12 @@ rpk:
 			conf: func() *Config {
 				c := getValidConfig()
 				c.Pandaproxy = &Pandaproxy{
-					PandaproxyAPI: &SocketAddress{
-						Address: "1.2.3.4",
-						Port:    1234,
-					},
+					PandaproxyAPI: []NamedSocketAddress{{
+						SocketAddress: SocketAddress{
+							Address: "1.2.3.4",
+							Port:    1234,
+						},
+					}},
 				}
 				return c
 			},
@@ -961,7 +973,7 @@ rpk:
 			expected: `config_file: /etc/redpanda/redpanda.yaml
 pandaproxy:
   pandaproxy_api:
-    address: 1.2.3.4
+  - address: 1.2.3.4
     port: 1234
 redpanda:
   admin:
@@ -1008,7 +1020,7 @@ rpk:
 			conf: func() *Config {
 				c := getValidConfig()
 				c.PandaproxyClient = &PandaproxyClient{
-					Broker: []SocketAddress{
+					Brokers: []SocketAddress{
 						{
 							Address: "1.2.3.4",
 							Port:    1234,
@@ -1028,15 +1040,15 @@ rpk:
 			expected: `config_file: /etc/redpanda/redpanda.yaml
 pandaproxy: {}
 pandaproxy_client:
-  broker:
-  - address: 1.2.3.4
-    port: 1234
   broker_tls:
     cert_file: /etc/certs/cert.crt
     enabled: true
     key_file: /etc/certs/cert.key
     require_client_auth: true
     truststore_file: /etc/certs/ca.crt
+  brokers:
+  - address: 1.2.3.4
+    port: 1234
 redpanda:
   admin:
     address: 0.0.0.0